@@ -15,7 +15,7 @@ import (
 
 var (
 	app                             = kingpin.New("generate", "Generate is used to write new features.")
-	kind                            = app.Arg("kind", "Kind of thing to generate.").Required().Enum("detector")
+	kind                            = app.Arg("kind", "Kind of thing to generate.").Required().Enum("detector", "source")
 	name                            = app.Arg("name", "Name of the Source/Detector to generate.").Required().String()
 	nameTitle, nameLower, nameUpper string
 )
@@ -43,19 +43,32 @@ func main() {
 				ReplaceString: []string{"heroku"},
 			},
 		})
-		// case "source":
-		// 	mustWriteTemplates([]templateJob{
-		// 		{
-		// 			TemplatePath:  "pkg/sources/filesystem/filesystem.go",
-		// 			WritePath:     filepath.Join(folderPath(), nameLower+".go"),
-		// 			ReplaceString: []string{"filesystem"},
-		// 		},
-		// 		{
-		// 			TemplatePath:  "pkg/sources/filesystem/filesystem_test.go",
-		// 			WritePath:     filepath.Join(folderPath(), nameLower+"_test.go"),
-		// 			ReplaceString: []string{"filesystem"},
-		// 		},
-		// 	})
+	case "source":
+		// The source is registered with the engine by a file of its own,
+		// which mustn't replace an existing one.
+		enginePath := filepath.Join("pkg/engine", nameLower+".go")
+		if _, err := os.Stat(enginePath); err == nil {
+			log.Fatal(errors.Errorf("%s already exists", enginePath))
+		}
+		mustWriteTemplates([]templateJob{
+			{
+				TemplatePath:  "pkg/sources/wayback/wayback.go",
+				WritePath:     filepath.Join(folderPath(), nameLower+".go"),
+				ReplaceString: []string{"wayback"},
+			},
+			{
+				TemplatePath:  "pkg/sources/wayback/wayback_test.go",
+				WritePath:     filepath.Join(folderPath(), nameLower+"_test.go"),
+				ReplaceString: []string{"wayback"},
+			},
+			{
+				TemplatePath:  "pkg/engine/wayback.go",
+				WritePath:     enginePath,
+				ReplaceString: []string{"wayback"},
+			},
+		})
+		log.Printf("Add SOURCE_TYPE_%s and a %s connection message to proto/sources.proto, and a %s metadata message to proto/source_metadata.proto, then run make protos\n", nameUpper, nameTitle, nameTitle)
+		log.Printf("Add a %s command to main.go that calls Scan%s\n", nameLower, nameTitle)
 	}
 }
 
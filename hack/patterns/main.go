@@ -0,0 +1,43 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors/registry"
+)
+
+var (
+	app    = kingpin.New("patterns", "Patterns writes the patterns of the detectors to the registry, for detectors export to print.")
+	dir    = app.Flag("detectors", "Directory of the detector packages.").Default("pkg/detectors").String()
+	output = app.Flag("output", "File to write the patterns to.").Default("pkg/detectors/registry/patterns.json").String()
+)
+
+func main() {
+	log.SetFlags(log.Lmsgprefix)
+	log.SetPrefix("😲 [patterns] ")
+
+	kingpin.MustParse(app.Parse(os.Args[1:]))
+
+	patterns, unresolved, err := registry.Extract(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, pos := range unresolved {
+		log.Printf("could not evaluate the pattern at %s", pos)
+	}
+	out, err := registry.MarshalPatterns(patterns)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*output, out, 0o644); err != nil {
+		log.Fatal(err)
+	}
+	count := 0
+	for _, p := range patterns {
+		count += len(p)
+	}
+	log.Printf("wrote %d patterns of %d detectors to %s, leaving out %d", count, len(patterns), *output, len(unresolved))
+}
@@ -11,14 +11,15 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/paulbellamy/ratecounter"
-	log "github.com/sirupsen/logrus"
 	"golang.org/x/sync/semaphore"
 	"gopkg.in/alecthomas/kingpin.v2"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/decoders"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/engine"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
@@ -48,6 +49,13 @@ func main() {
 
 	cmd := kingpin.MustParse(app.Parse(os.Args[1:]))
 
+	logger, err := log.New(log.Config{})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	ctx = log.IntoContext(ctx, logger)
+
 	switch cmd {
 	case scanCmd.FullCommand():
 
@@ -68,19 +76,19 @@ func main() {
 		} else {
 			_, ok := allScanners[input]
 			if !ok {
-				log.Fatal("could not find scanner by that name")
+				fatal(logger, nil, "could not find scanner by that name", "detector", input)
 			}
 			selectedScanners[input] = allScanners[input]
 		}
 		if len(selectedScanners) == 0 {
-			log.Fatal("no detectors selected")
+			fatal(logger, nil, "no detectors selected")
 		}
 
 		for _, excluded := range *scanCmdExclude {
 			delete(selectedScanners, excluded)
 		}
 
-		log.Infof("loaded %d secret detectors", len(selectedScanners)+3)
+		logger.Info("loaded secret detectors", "count", len(selectedScanners)+3)
 
 		var wgScanners sync.WaitGroup
 
@@ -92,7 +100,7 @@ func main() {
 				time.Sleep(60 * time.Second)
 				counter.Incr(int64(chunkCounter - prev))
 				prev = chunkCounter
-				log.Infof("chunk scan rate: %d/sec", counter.Rate()/60)
+				logger.Info("chunk scan rate", "per_second", counter.Rate()/60)
 			}
 		}()
 
@@ -122,7 +130,7 @@ func main() {
 
 								res, err := scanner.FromData(ctx, *scanVerify, decoded.Data)
 								if err != nil {
-									log.Fatal(err)
+									fatal(logger, err, "detector failed", "detector", name)
 								}
 								if len(res) > 0 {
 									if resCounter[name] == nil {
@@ -131,18 +139,18 @@ func main() {
 									}
 									atomic.AddUint64(resCounter[name], uint64(len(res)))
 									if *scanThreshold != 0 && int(*resCounter[name]) > *scanThreshold {
-										log.WithField("scanner", name).Errorf("exceeded result threshold of %d", *scanThreshold)
+										logger.Error(nil, "exceeded result threshold", "scanner", name, "threshold", *scanThreshold)
 										failed = true
 										os.Exit(1)
 									}
 
 									if *scanPrintRes {
 										for _, r := range res {
-											logger := log.WithField("secret", name).WithField("meta", chunk.SourceMetadata).WithField("result", string(r.Raw))
+											resultLogger := logger.WithValues("secret", name, "meta", chunk.SourceMetadata.String(), "result", string(r.Raw))
 											if *scanPrintChunkRes {
-												logger = logger.WithField("chunk", string(decoded.Data))
+												resultLogger = resultLogger.WithValues("chunk", string(decoded.Data))
 											}
-											logger.Info("result")
+											resultLogger.Info("result")
 										}
 									}
 								}
@@ -161,13 +169,13 @@ func main() {
 			go func(r string) {
 				defer sem.Release(1)
 				defer wgChunkers.Done()
-				log.Infof("cloning %s", r)
-				path, repo, err := git.CloneRepoUsingUnauthenticated(r)
+				logger.Info("cloning", "repo", r)
+				path, repo, err := git.CloneRepoUsingUnauthenticated(ctx, r)
 				if err != nil {
-					log.Fatal(err)
+					fatal(logger, err, "could not clone repo", "repo", r)
 				}
 
-				log.Infof("cloned %s", r)
+				logger.Info("cloned", "repo", r)
 
 				s := git.NewGit(sourcespb.SourceType_SOURCE_TYPE_GIT, 0, 0, "snifftest", false, runtime.NumCPU(),
 					func(file, email, commit, timestamp, repository string, line int64) *source_metadatapb.MetaData {
@@ -184,12 +192,12 @@ func main() {
 						}
 					})
 
-				log.Infof("scanning %s", r)
+				logger.Info("scanning", "repo", r)
 				err = s.ScanRepo(ctx, repo, path, git.NewScanOptions(), chunksChan)
 				if err != nil {
-					log.Fatal(err)
+					fatal(logger, err, "could not scan repo", "repo", r)
 				}
-				log.Infof("scanned %s", r)
+				logger.Info("scanned", "repo", r)
 				defer os.RemoveAll(path)
 			}(repo)
 		}
@@ -201,9 +209,9 @@ func main() {
 
 		wgScanners.Wait()
 
-		log.WithField("chunks", chunkCounter).Info("completed")
+		logger.Info("completed", "chunks", chunkCounter)
 		for scanner, resultsCount := range resCounter {
-			log.WithField("results", *resultsCount).Info(scanner)
+			logger.Info(scanner, "results", *resultsCount)
 		}
 
 		if failed {
@@ -216,6 +224,11 @@ func main() {
 	}
 }
 
+func fatal(logger logr.Logger, err error, msg string, keysAndValues ...interface{}) {
+	logger.Error(err, msg, keysAndValues...)
+	os.Exit(1)
+}
+
 func getAllScanners() map[string]detectors.Detector {
 	allScanners := map[string]detectors.Detector{}
 	for _, s := range engine.DefaultDetectors() {
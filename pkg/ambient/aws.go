@@ -0,0 +1,30 @@
+// Package ambient resolves the credentials a scan can use from the
+// environment it runs in, so sources don't need keys in their connection.
+package ambient
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+)
+
+// AWSSession returns a session using the default AWS credential chain:
+// environment variables, shared config, web identity tokens (IRSA), and ECS
+// or EC2 instance roles. If cred has a role ARN, that role is assumed with
+// the ambient credentials.
+func AWSSession(cfg *aws.Config, cred *credentialspb.Ambient) (*session.Session, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+		Config:            *cfg,
+	})
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not create AWS session", 0)
+	}
+	if cred.GetRoleArn() == "" {
+		return sess, nil
+	}
+	return sess.Copy(&aws.Config{Credentials: stscreds.NewCredentials(sess, cred.GetRoleArn())}), nil
+}
@@ -0,0 +1,180 @@
+package ambient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+	"golang.org/x/oauth2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+)
+
+const (
+	// azureIMDSEndpoint is the token endpoint of the Azure instance metadata service.
+	azureIMDSEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+	azureIMDSVersion  = "2018-02-01"
+	// azureAppServiceVersion is the version of the endpoint App Service and
+	// Functions set in IDENTITY_ENDPOINT.
+	azureAppServiceVersion = "2019-08-01"
+	azureDefaultAuthority  = "https://login.microsoftonline.com/"
+)
+
+// AzureManagedIdentity gets tokens for an Azure managed identity. Use
+// NewAzureTokenSource to configure it from the environment.
+type AzureManagedIdentity struct {
+	// Endpoint is the token endpoint, either the instance metadata service or
+	// the one App Service provides in IDENTITY_ENDPOINT.
+	Endpoint string
+	// Header is the secret App Service provides in IDENTITY_HEADER. It's empty
+	// when using the instance metadata service.
+	Header string
+	// ClientID selects a user assigned identity.
+	ClientID string
+	// Resource is the resource the token is for, such as
+	// https://storage.azure.com/.
+	Resource string
+	Client   *http.Client
+}
+
+// AzureWorkloadIdentity exchanges the service account token AKS workload
+// identity projects into the pod for an Azure token.
+type AzureWorkloadIdentity struct {
+	Authority string
+	TenantID  string
+	ClientID  string
+	TokenFile string
+	Resource  string
+	Client    *http.Client
+}
+
+// NewAzureTokenSource returns tokens for resource from AKS workload identity if
+// it's configured, or from the managed identity of the VM, App Service, or
+// Function the scan runs in.
+func NewAzureTokenSource(cred *credentialspb.Ambient, resource string) oauth2.TokenSource {
+	clientID := cred.GetClientId()
+	if tokenFile := os.Getenv("AZURE_FEDERATED_TOKEN_FILE"); tokenFile != "" {
+		if clientID == "" {
+			clientID = os.Getenv("AZURE_CLIENT_ID")
+		}
+		authority := os.Getenv("AZURE_AUTHORITY_HOST")
+		if authority == "" {
+			authority = azureDefaultAuthority
+		}
+		return oauth2.ReuseTokenSource(nil, &AzureWorkloadIdentity{
+			Authority: authority,
+			TenantID:  os.Getenv("AZURE_TENANT_ID"),
+			ClientID:  clientID,
+			TokenFile: tokenFile,
+			Resource:  resource,
+			Client:    common.SaneHttpClient(),
+		})
+	}
+
+	identity := &AzureManagedIdentity{
+		Endpoint: azureIMDSEndpoint,
+		ClientID: clientID,
+		Resource: resource,
+		Client:   common.SaneHttpClient(),
+	}
+	if endpoint := os.Getenv("IDENTITY_ENDPOINT"); endpoint != "" {
+		identity.Endpoint = endpoint
+		identity.Header = os.Getenv("IDENTITY_HEADER")
+	}
+	return oauth2.ReuseTokenSource(nil, identity)
+}
+
+// Token requests a token from the managed identity endpoint.
+func (a *AzureManagedIdentity) Token() (*oauth2.Token, error) {
+	params := url.Values{"resource": {a.Resource}}
+	if a.Header != "" {
+		params.Set("api-version", azureAppServiceVersion)
+	} else {
+		params.Set("api-version", azureIMDSVersion)
+	}
+	if a.ClientID != "" {
+		params.Set("client_id", a.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, a.Endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if a.Header != "" {
+		req.Header.Set("X-IDENTITY-HEADER", a.Header)
+	} else {
+		req.Header.Set("Metadata", "true")
+	}
+	return azureToken(a.Client, req)
+}
+
+// Token exchanges the projected service account token for an Azure token.
+func (a *AzureWorkloadIdentity) Token() (*oauth2.Token, error) {
+	assertion, err := os.ReadFile(a.TokenFile)
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not read federated token", 0)
+	}
+	form := url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_id":             {a.ClientID},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {strings.TrimSpace(string(assertion))},
+		"scope":                 {strings.TrimSuffix(a.Resource, "/") + "/.default"},
+	}
+	endpoint := strings.TrimSuffix(a.Authority, "/") + "/" + a.TenantID + "/oauth2/v2.0/token"
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return azureToken(a.Client, req)
+}
+
+// azureTokenResponse is returned by the token endpoints. The managed identity
+// endpoints give an absolute expires_on, while Azure AD gives expires_in.
+// Either may be a number or a string holding one.
+type azureTokenResponse struct {
+	AccessToken string          `json:"access_token"`
+	TokenType   string          `json:"token_type"`
+	ExpiresOn   json.RawMessage `json:"expires_on"`
+	ExpiresIn   json.RawMessage `json:"expires_in"`
+}
+
+func azureToken(client *http.Client, req *http.Request) (*oauth2.Token, error) {
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not request Azure token", 0)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1024*1024))
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not read Azure token", 0)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Azure token request failed with status %d: %s", res.StatusCode, body)
+	}
+
+	var token azureTokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, errors.WrapPrefix(err, "could not parse Azure token", 0)
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("Azure token response had no access token")
+	}
+	result := &oauth2.Token{AccessToken: token.AccessToken, TokenType: token.TokenType}
+	if on, err := strconv.ParseInt(strings.Trim(string(token.ExpiresOn), `"`), 10, 64); err == nil {
+		result.Expiry = time.Unix(on, 0)
+	} else if in, err := strconv.ParseInt(strings.Trim(string(token.ExpiresIn), `"`), 10, 64); err == nil {
+		result.Expiry = time.Now().Add(time.Duration(in) * time.Second)
+	}
+	return result, nil
+}
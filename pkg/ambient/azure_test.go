@@ -0,0 +1,90 @@
+package ambient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestAzureTokenSources(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("service-account-jwt\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		source     func(endpoint string) oauth2.TokenSource
+		check      func(r *http.Request) bool
+		response   string
+		wantExpiry time.Time
+	}{
+		{
+			name: "instance metadata service",
+			source: func(endpoint string) oauth2.TokenSource {
+				return &AzureManagedIdentity{Endpoint: endpoint, ClientID: "client", Resource: "https://storage.azure.com/", Client: http.DefaultClient}
+			},
+			check: func(r *http.Request) bool {
+				q := r.URL.Query()
+				return r.Header.Get("Metadata") == "true" && q.Get("api-version") == azureIMDSVersion &&
+					q.Get("client_id") == "client" && q.Get("resource") == "https://storage.azure.com/"
+			},
+			response:   `{"access_token":"imds-token","token_type":"Bearer","expires_on":"1654086000"}`,
+			wantExpiry: time.Unix(1654086000, 0),
+		},
+		{
+			name: "app service",
+			source: func(endpoint string) oauth2.TokenSource {
+				return &AzureManagedIdentity{Endpoint: endpoint, Header: "secret", Resource: "https://vault.azure.net", Client: http.DefaultClient}
+			},
+			check: func(r *http.Request) bool {
+				return r.Header.Get("X-IDENTITY-HEADER") == "secret" && r.URL.Query().Get("api-version") == azureAppServiceVersion
+			},
+			response:   `{"access_token":"app-service-token","token_type":"Bearer","expires_on":1654086000}`,
+			wantExpiry: time.Unix(1654086000, 0),
+		},
+		{
+			name: "workload identity",
+			source: func(endpoint string) oauth2.TokenSource {
+				return &AzureWorkloadIdentity{Authority: endpoint, TenantID: "tenant", ClientID: "client", TokenFile: tokenFile,
+					Resource: "https://storage.azure.com/", Client: http.DefaultClient}
+			},
+			check: func(r *http.Request) bool {
+				return r.Method == http.MethodPost && r.URL.Path == "/tenant/oauth2/v2.0/token" &&
+					r.FormValue("client_assertion") == "service-account-jwt" && r.FormValue("scope") == "https://storage.azure.com/.default"
+			},
+			response: `{"access_token":"workload-token","token_type":"Bearer","expires_in":3600}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !tt.check(r) {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			token, err := tt.source(server.URL).Token()
+			if err != nil {
+				t.Fatalf("Token() error = %v", err)
+			}
+			if token.AccessToken == "" {
+				t.Errorf("Token() returned no access token")
+			}
+			if !tt.wantExpiry.IsZero() && !token.Expiry.Equal(tt.wantExpiry) {
+				t.Errorf("Token() expiry = %v, want %v", token.Expiry, tt.wantExpiry)
+			}
+			if token.Expiry.IsZero() {
+				t.Errorf("Token() returned no expiry")
+			}
+		})
+	}
+}
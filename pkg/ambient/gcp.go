@@ -0,0 +1,28 @@
+package ambient
+
+import (
+	"context"
+
+	"github.com/go-errors/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+)
+
+const gcpDefaultScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// GCPTokenSource returns tokens from Google application default credentials:
+// GOOGLE_APPLICATION_CREDENTIALS, gcloud's credentials, or the metadata server
+// of a GCE instance or GKE workload identity.
+func GCPTokenSource(ctx context.Context, cred *credentialspb.Ambient) (oauth2.TokenSource, error) {
+	scopes := cred.GetScopes()
+	if len(scopes) == 0 {
+		scopes = []string{gcpDefaultScope}
+	}
+	creds, err := google.FindDefaultCredentials(ctx, scopes...)
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not find GCP default credentials", 0)
+	}
+	return creds.TokenSource, nil
+}
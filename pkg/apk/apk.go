@@ -0,0 +1,380 @@
+// Package apk decodes the compiled resources of Android application packages:
+// binary XML files, such as AndroidManifest.xml, and the resources.arsc
+// resource table.
+// https://android.googlesource.com/platform/frameworks/base/+/master/libs/androidfw/include/androidfw/ResourceTypes.h
+package apk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/go-errors/errors"
+)
+
+// Chunk types.
+const (
+	chunkStringPool   = 0x0001
+	chunkTable        = 0x0002
+	chunkXML          = 0x0003
+	chunkStartNS      = 0x0100
+	chunkEndNS        = 0x0101
+	chunkStartElement = 0x0102
+	chunkEndElement   = 0x0103
+	chunkCData        = 0x0104
+	chunkTablePackage = 0x0200
+	chunkTableType    = 0x0201
+)
+
+// Value types.
+const (
+	typeNull      = 0x00
+	typeReference = 0x01
+	typeAttribute = 0x02
+	typeString    = 0x03
+	typeFloat     = 0x04
+	typeDimension = 0x05
+	typeFraction  = 0x06
+	typeDynRef    = 0x07
+	typeIntDec    = 0x10
+	typeIntHex    = 0x11
+	typeBoolean   = 0x12
+	typeColorMin  = 0x1c
+	typeColorMax  = 0x1f
+)
+
+// noEntry marks a missing string reference or resource entry.
+const noEntry = 0xffffffff
+
+// ErrNotBinaryXML is returned for data that isn't compiled XML.
+var ErrNotBinaryXML = errors.New("not an Android binary XML file")
+
+// IsBinaryXML reports whether header, the first bytes of a file, starts a
+// compiled XML file.
+func IsBinaryXML(header []byte) bool {
+	return len(header) >= 8 && binary.LittleEndian.Uint16(header) == chunkXML
+}
+
+// chunk is a chunk of a resource file. Chunks start with their type, the size
+// of their header and their total size.
+type chunk struct {
+	typ uint16
+	// header is the chunk's header, and body what follows it.
+	header []byte
+	body   []byte
+}
+
+// readChunk reads the chunk at the start of b, and returns it with its size.
+func readChunk(b []byte) (chunk, int, error) {
+	if len(b) < 8 {
+		return chunk{}, 0, errors.New("truncated chunk header")
+	}
+	headerSize := int(binary.LittleEndian.Uint16(b[2:]))
+	size := int(binary.LittleEndian.Uint32(b[4:]))
+	if headerSize < 8 || size < headerSize || size > len(b) {
+		return chunk{}, 0, errors.Errorf("invalid chunk of type %#x", binary.LittleEndian.Uint16(b))
+	}
+	return chunk{
+		typ:    binary.LittleEndian.Uint16(b),
+		header: b[:headerSize],
+		body:   b[headerSize:size],
+	}, size, nil
+}
+
+// children calls fn with each chunk in b.
+func children(b []byte, fn func(chunk) error) error {
+	for len(b) > 0 {
+		c, size, err := readChunk(b)
+		if err != nil {
+			return err
+		}
+		if err := fn(c); err != nil {
+			return err
+		}
+		b = b[size:]
+	}
+	return nil
+}
+
+// stringPool is a decoded string pool chunk.
+type stringPool []string
+
+// get returns the string at index i, or "" if there's none.
+func (p stringPool) get(i uint32) string {
+	if int64(i) >= int64(len(p)) {
+		return ""
+	}
+	return p[i]
+}
+
+// parseStringPool decodes a string pool chunk.
+func parseStringPool(c chunk) (stringPool, error) {
+	if len(c.header) < 28 {
+		return nil, errors.New("truncated string pool header")
+	}
+	count := int(binary.LittleEndian.Uint32(c.header[8:]))
+	isUTF8 := binary.LittleEndian.Uint32(c.header[16:])&0x100 != 0
+	start := int(binary.LittleEndian.Uint32(c.header[20:])) - len(c.header)
+	if count > len(c.body)/4 || start < 4*count || start > len(c.body) {
+		return nil, errors.New("invalid string pool")
+	}
+
+	data := c.body[start:]
+	pool := make(stringPool, count)
+	for i := range pool {
+		offset := int(binary.LittleEndian.Uint32(c.body[4*i:]))
+		if offset >= len(data) {
+			continue
+		}
+		if isUTF8 {
+			pool[i] = decodeUTF8(data[offset:])
+		} else {
+			pool[i] = decodeUTF16(data[offset:])
+		}
+	}
+	return pool, nil
+}
+
+// decodeUTF8 decodes a UTF-8 pool string, which is preceded by its length in
+// characters and then in bytes.
+func decodeUTF8(b []byte) string {
+	_, b = utf8Len(b)
+	n, b := utf8Len(b)
+	if n > len(b) {
+		n = len(b)
+	}
+	return string(b[:n])
+}
+
+// utf8Len reads a one or two byte length.
+func utf8Len(b []byte) (int, []byte) {
+	switch {
+	case len(b) == 0:
+		return 0, b
+	case b[0]&0x80 == 0:
+		return int(b[0]), b[1:]
+	case len(b) < 2:
+		return 0, nil
+	default:
+		return int(b[0]&0x7f)<<8 | int(b[1]), b[2:]
+	}
+}
+
+// decodeUTF16 decodes a UTF-16 pool string, which is preceded by its length
+// in code units.
+func decodeUTF16(b []byte) string {
+	if len(b) < 2 {
+		return ""
+	}
+	n := int(binary.LittleEndian.Uint16(b))
+	b = b[2:]
+	if n&0x8000 != 0 {
+		if len(b) < 2 {
+			return ""
+		}
+		n = (n&0x7fff)<<16 | int(binary.LittleEndian.Uint16(b))
+		b = b[2:]
+	}
+	if n > len(b)/2 {
+		n = len(b) / 2
+	}
+	units := make([]uint16, n)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
+
+// formatValue renders a typed value the way aapt does.
+func formatValue(pool stringPool, dataType byte, data uint32) string {
+	switch {
+	case dataType == typeNull:
+		return ""
+	case dataType == typeReference || dataType == typeDynRef:
+		return fmt.Sprintf("@0x%08x", data)
+	case dataType == typeAttribute:
+		return fmt.Sprintf("?0x%08x", data)
+	case dataType == typeString:
+		return pool.get(data)
+	case dataType == typeFloat:
+		return fmt.Sprint(math.Float32frombits(data))
+	case dataType == typeIntDec:
+		return fmt.Sprint(int32(data))
+	case dataType == typeIntHex:
+		return fmt.Sprintf("0x%x", data)
+	case dataType == typeBoolean:
+		return fmt.Sprint(data != 0)
+	case dataType >= typeColorMin && dataType <= typeColorMax:
+		return fmt.Sprintf("#%08x", data)
+	default:
+		// Dimensions and fractions aren't useful when scanning, so they're
+		// left undecoded like other unknown types.
+		return fmt.Sprintf("0x%08x", data)
+	}
+}
+
+// Attr is an attribute of an element of a binary XML file.
+type Attr struct {
+	// Name is the attribute's name with the prefix of its namespace, if any,
+	// such as "android:versionName".
+	Name  string
+	Value string
+}
+
+// XMLHandler receives the contents of a binary XML file from WalkXML.
+type XMLHandler struct {
+	StartElement func(name string, attrs []Attr) error
+	EndElement   func(name string) error
+	CharData     func(text string) error
+}
+
+// WalkXML decodes the binary XML file data, calling the functions of h that
+// are set.
+func WalkXML(data []byte, h XMLHandler) error {
+	if !IsBinaryXML(data) {
+		return ErrNotBinaryXML
+	}
+	doc, _, err := readChunk(data)
+	if err != nil {
+		return err
+	}
+
+	var pool stringPool
+	prefixes := map[string]string{}
+	return children(doc.body, func(c chunk) error {
+		switch c.typ {
+		case chunkStringPool:
+			pool, err = parseStringPool(c)
+			return err
+		case chunkStartNS:
+			if len(c.body) >= 8 {
+				prefix := pool.get(binary.LittleEndian.Uint32(c.body))
+				prefixes[pool.get(binary.LittleEndian.Uint32(c.body[4:]))] = prefix
+			}
+		case chunkStartElement:
+			if h.StartElement == nil {
+				return nil
+			}
+			if len(c.body) < 20 {
+				return errors.New("truncated start element")
+			}
+			name := pool.get(binary.LittleEndian.Uint32(c.body[4:]))
+			attrStart := int(binary.LittleEndian.Uint16(c.body[8:]))
+			attrSize := int(binary.LittleEndian.Uint16(c.body[10:]))
+			attrCount := int(binary.LittleEndian.Uint16(c.body[12:]))
+			if attrSize < 20 {
+				return errors.New("invalid attribute size")
+			}
+			attrs := make([]Attr, 0, attrCount)
+			for i := 0; i < attrCount; i++ {
+				offset := attrStart + i*attrSize
+				if offset+20 > len(c.body) {
+					break
+				}
+				a := c.body[offset:]
+				attrName := pool.get(binary.LittleEndian.Uint32(a[4:]))
+				if prefix := prefixes[pool.get(binary.LittleEndian.Uint32(a))]; prefix != "" {
+					attrName = prefix + ":" + attrName
+				}
+				value := formatValue(pool, a[15], binary.LittleEndian.Uint32(a[16:]))
+				// Values that were written as text keep it as their raw value.
+				if raw := binary.LittleEndian.Uint32(a[8:]); raw != noEntry {
+					value = pool.get(raw)
+				}
+				attrs = append(attrs, Attr{Name: attrName, Value: value})
+			}
+			return h.StartElement(name, attrs)
+		case chunkEndElement:
+			if h.EndElement != nil && len(c.body) >= 8 {
+				return h.EndElement(pool.get(binary.LittleEndian.Uint32(c.body[4:])))
+			}
+		case chunkCData:
+			if h.CharData != nil && len(c.body) >= 4 {
+				return h.CharData(pool.get(binary.LittleEndian.Uint32(c.body)))
+			}
+		}
+		return nil
+	})
+}
+
+// DecodeXML renders the binary XML file data as text. Attribute values and
+// text aren't escaped, so that they read as they were written.
+func DecodeXML(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	depth := 0
+	err := WalkXML(data, XMLHandler{
+		StartElement: func(name string, attrs []Attr) error {
+			buf.WriteString(strings.Repeat("  ", depth))
+			buf.WriteString("<" + name)
+			for _, attr := range attrs {
+				fmt.Fprintf(&buf, " %s=%q", attr.Name, attr.Value)
+			}
+			buf.WriteString(">\n")
+			depth++
+			return nil
+		},
+		EndElement: func(name string) error {
+			// Files with more end elements than start elements are rendered
+			// flush left from then on.
+			if depth > 0 {
+				depth--
+			}
+			buf.WriteString(strings.Repeat("  ", depth))
+			buf.WriteString("</" + name + ">\n")
+			return nil
+		},
+		CharData: func(text string) error {
+			buf.WriteString(strings.Repeat("  ", depth))
+			buf.WriteString(text + "\n")
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Manifest is the identity of an app, from its AndroidManifest.xml.
+type Manifest struct {
+	Package     string
+	VersionName string
+	VersionCode string
+}
+
+// ParseManifest reads the identity of an app from its compiled
+// AndroidManifest.xml. Values that are resource references, usually a
+// versionName kept in strings.xml, are left as "@0x7f..." references to be
+// looked up with ReadResources.
+func ParseManifest(data []byte) (*Manifest, error) {
+	var m *Manifest
+	err := WalkXML(data, XMLHandler{
+		StartElement: func(name string, attrs []Attr) error {
+			if m != nil || name != "manifest" {
+				return nil
+			}
+			m = &Manifest{}
+			for _, attr := range attrs {
+				switch attr.Name {
+				case "package":
+					m.Package = attr.Value
+				case "android:versionName", "versionName":
+					m.VersionName = attr.Value
+				case "android:versionCode", "versionCode":
+					m.VersionCode = attr.Value
+				}
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, errors.New("no manifest element")
+	}
+	return m, nil
+}
@@ -0,0 +1,198 @@
+package apk
+
+import (
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+// manifestFile is a gzipped compiled AndroidManifest.xml with UTF-16 strings,
+// for the com.example.notes package with versionCode 42, and a versionName
+// referring to the version_name string resource of resourcesFile.
+const manifestFile = "" +
+	"H4sIAAAAAAACA3WSTU4CQRCF38zwjwoSFi6MByARtsadcaOJURNdmugAiujwE0Djzgu4MJ7A" +
+	"eA7P4SE8gTv9uqcJSIaePKr7vapXNT0EyuklkDxtqupLZc3Wxdy+Au7AK/gAn+ALfIMfkPGk" +
+	"LbAD2mAI3sA7yCpUX22NNFCXKNV0qwnPULtq8IzVgrlWj8yx6gv5ddQBWgN+qHviiNyxPf/3" +
+	"XXeZdfQn1B75Efs6eQP6mSozzxCthVOoDpxU1CNxhNolr699ftuJyrF1NUrOTtuHv7G+E7g0" +
+	"+qxPmt6hmpwiTgXr2NQDPTuWj1wH81Zmzi4zhVTHnaTq0rfZ06kOdakDnehM5+SmqJhOVrKO" +
+	"3YS62M9M9ggfMYvJz9u6Ccw2M4Z2Z5dX1oYJ4Jclt/fhr+Z4szJ23qoC938xnNn79qYU+C4/" +
+	"5TS4cs1x6RnnefKfjf/Rgn/B+fvO34t9+Zaxf9Zx+ZlXxcQkr1KC14r9ErFX0XFrYNVxJgYL" +
+	"9zH1SuILS3hzT96Se/0D9VJAOowDAAA="
+
+// prefsFile is a gzipped compiled XML file with UTF-8 strings, holding an
+// element with text.
+const prefsFile = "" +
+	"H4sIAAAAAAACA2Nm4GBYwcDAwMggw+ABpJkYoICRgUGFAQGEgVhAIKAoNS21KDUvOTU4uSg1" +
+	"NY+Bj88vvyS1WKE4taQkMy+9mIGJUQCsD6id4T8UgPSLACEMsADVyCCpAdEcUDlmoJwEFv0A" +
+	"RrT0XKgAAAA="
+
+// resourcesFile is a gzipped resources.arsc of the com.example.notes package,
+// with app_name, version_name and accent color resources, and a sparse type
+// chunk holding the folders string array.
+const resourcesFile = "" +
+	"H4sIAAAAAAACA91Su27CQBAcHyamoEgRKY0VWaJJgSxA+YAoVapUSNDBidgEBWxkrCipkk/I" +
+	"H8Ps+gymp2Kl9ezj7B3PnkEXHy3Ag3iIKbENZywOcLIO/ZZ+T3+Qc+23vEx2xFH8FA/h+5O8" +
+	"+CS85psEQdBL0wENMJHnc8Yv31kgxwYxEnzDMtpizThGxnrJaIfrtoiaUgqURP8oc4gxqnpt" +
+	"j424Sw8h5y01KlEwvqFSEq2o3FJ3ZpkVfP4cv/mH0wyxfiO+Q7XbF92r5R62mPFbspNEZ34R" +
+	"C05ZcTPZWS9Aytoa7+6EsLHc7IJ5RlY634zxTzTuv+b05wvoJ/cvcnfR3clWfTc9lzvURkel" +
+	"2++Fj+ghTelPL8DHKB9Psc6FhnAwFR+NhccBKLbLzGgDAAA="
+
+func TestDecodeXML(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "manifest",
+			data: manifestFile,
+			want: `<manifest package="com.example.notes" android:versionCode="42" android:versionName="@0x7f020001">
+  <application android:label="Notes" android:debuggable="true">
+    <meta-data android:name="com.example.API_HOST" android:value="api.example.com">
+    </meta-data>
+  </application>
+</manifest>
+`,
+		},
+		{
+			name: "text",
+			data: prefsFile,
+			want: `<PreferenceScreen>
+  Notes settings
+</PreferenceScreen>
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeXML(decodeTestFile(t, tt.data))
+			if err != nil {
+				t.Fatalf("DecodeXML() error = %v", err)
+			}
+			if diff := pretty.Compare(string(got), tt.want); diff != "" {
+				t.Errorf("DecodeXML() diff: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeXML_UnbalancedEnd(t *testing.T) {
+	// Two end elements without start elements, and no string pool.
+	end := make([]byte, 24)
+	binary.LittleEndian.PutUint16(end, chunkEndElement)
+	binary.LittleEndian.PutUint16(end[2:], 16)
+	binary.LittleEndian.PutUint32(end[4:], 24)
+	binary.LittleEndian.PutUint32(end[16:], noEntry)
+	binary.LittleEndian.PutUint32(end[20:], noEntry)
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint16(data, chunkXML)
+	binary.LittleEndian.PutUint16(data[2:], 8)
+	binary.LittleEndian.PutUint32(data[4:], uint32(8+2*len(end)))
+	data = append(append(data, end...), end...)
+
+	got, err := DecodeXML(data)
+	if err != nil {
+		t.Fatalf("DecodeXML() error = %v", err)
+	}
+	if want := "</>\n</>\n"; string(got) != want {
+		t.Errorf("DecodeXML() = %q, want %q", got, want)
+	}
+}
+
+func TestParseManifest(t *testing.T) {
+	got, err := ParseManifest(decodeTestFile(t, manifestFile))
+	if err != nil {
+		t.Fatalf("ParseManifest() error = %v", err)
+	}
+	want := &Manifest{
+		Package:     "com.example.notes",
+		VersionName: "@0x7f020001",
+		VersionCode: "42",
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("ParseManifest() diff: (-got +want)\n%s", diff)
+	}
+
+	if _, err := ParseManifest(decodeTestFile(t, prefsFile)); err == nil {
+		t.Error("ParseManifest() of a file without a manifest succeeded")
+	}
+}
+
+func TestReadResources(t *testing.T) {
+	got, err := ReadResources(decodeTestFile(t, resourcesFile))
+	if err != nil {
+		t.Fatalf("ReadResources() error = %v", err)
+	}
+	want := []Resource{
+		{ID: 0x7f020000, Type: "string", Name: "app_name", Value: "Notes"},
+		{ID: 0x7f020001, Type: "string", Name: "version_name", Value: "2.4.1"},
+		{ID: 0x7f030002, Type: "array", Name: "folders", Value: "Work"},
+		{ID: 0x7f030002, Type: "array", Name: "folders", Value: "Home"},
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("ReadResources() diff: (-got +want)\n%s", diff)
+	}
+}
+
+func TestReadResources_EntryCountPastTable(t *testing.T) {
+	data := decodeTestFile(t, resourcesFile)
+	// Claim that each type has far more entries than its offset table holds,
+	// by changing the headers of the type chunks in place.
+	table, _, err := readChunk(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = children(table.body, func(c chunk) error {
+		if c.typ != chunkTablePackage {
+			return nil
+		}
+		return children(c.body, func(c chunk) error {
+			if c.typ == chunkTableType {
+				binary.LittleEndian.PutUint32(c.header[12:], 0xffffffff)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadResources(data)
+	if err != nil {
+		t.Fatalf("ReadResources() error = %v", err)
+	}
+	if len(got) != 4 {
+		t.Errorf("ReadResources() = %d resources, want 4", len(got))
+	}
+}
+
+func TestInvalidFiles(t *testing.T) {
+	manifest := decodeTestFile(t, manifestFile)
+	if _, err := DecodeXML(manifest[:len(manifest)-10]); err == nil {
+		t.Error("DecodeXML() of a truncated file succeeded")
+	}
+	if _, err := DecodeXML([]byte("<manifest/>")); err != ErrNotBinaryXML {
+		t.Errorf("DecodeXML() of a text file error = %v, want %v", err, ErrNotBinaryXML)
+	}
+	if _, err := ReadResources(manifest); err == nil {
+		t.Error("ReadResources() of a binary XML file succeeded")
+	}
+}
+
+func decodeTestFile(t *testing.T, data string) []byte {
+	t.Helper()
+	zr, err := gzip.NewReader(base64.NewDecoder(base64.StdEncoding, strings.NewReader(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
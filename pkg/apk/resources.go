@@ -0,0 +1,215 @@
+package apk
+
+import (
+	"encoding/binary"
+
+	"github.com/go-errors/errors"
+)
+
+// Type chunk flags.
+const (
+	typeFlagSparse   = 0x01
+	typeFlagOffset16 = 0x02
+)
+
+// Entry flags.
+const (
+	entryFlagComplex = 0x0001
+	entryFlagCompact = 0x0008
+)
+
+// Resource is a string value of a resource table. Resources with several
+// values, such as string arrays or strings translated to other languages, are
+// read as several Resources with the same ID.
+type Resource struct {
+	// ID is the resource's 0xPPTTEEEE package, type and entry ID, which binary
+	// XML files refer to it by.
+	ID uint32
+	// Type is the type of the resource, such as "string", and Name its name.
+	Type  string
+	Name  string
+	Value string
+}
+
+// ReadResources returns the string resources of the resources.arsc resource
+// table data.
+func ReadResources(data []byte) ([]Resource, error) {
+	table, _, err := readChunk(data)
+	if err != nil {
+		return nil, err
+	}
+	if table.typ != chunkTable {
+		return nil, errors.New("not an Android resource table")
+	}
+
+	var (
+		pool      stringPool
+		resources []Resource
+	)
+	err = children(table.body, func(c chunk) error {
+		switch c.typ {
+		case chunkStringPool:
+			pool, err = parseStringPool(c)
+			return err
+		case chunkTablePackage:
+			pkgResources, err := readPackage(c, pool)
+			resources = append(resources, pkgResources...)
+			return err
+		}
+		return nil
+	})
+	return resources, err
+}
+
+// readPackage returns the string resources of a package chunk.
+func readPackage(pkg chunk, pool stringPool) ([]Resource, error) {
+	if len(pkg.header) < 284 {
+		return nil, errors.New("truncated package header")
+	}
+	id := binary.LittleEndian.Uint32(pkg.header[8:])
+	// The type and key pools are the first of the package's chunks, but are
+	// found by their offsets.
+	typeNames, err := poolAt(pkg, binary.LittleEndian.Uint32(pkg.header[268:]))
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not read type names", 0)
+	}
+	keyNames, err := poolAt(pkg, binary.LittleEndian.Uint32(pkg.header[276:]))
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not read key names", 0)
+	}
+
+	var resources []Resource
+	err = children(pkg.body, func(c chunk) error {
+		if c.typ != chunkTableType {
+			return nil
+		}
+		if len(c.header) < 20 {
+			return errors.New("truncated type header")
+		}
+		typeID := uint32(c.header[8])
+		flags := c.header[9]
+		count := int(binary.LittleEndian.Uint32(c.header[12:]))
+		entriesStart := int(binary.LittleEndian.Uint32(c.header[16:])) - len(c.header)
+		if entriesStart < 0 || entriesStart > len(c.body) {
+			return errors.New("invalid type entries offset")
+		}
+		entries := c.body[entriesStart:]
+		typeName := typeNames.get(typeID - 1)
+		// The count can't be trusted to fit the offset table.
+		if n := entriesStart / entryOffsetSize(flags); count > n {
+			count = n
+		}
+
+		for i := 0; i < count; i++ {
+			index, offset, ok := entryOffset(c.body[:entriesStart], flags, i)
+			if !ok || offset+8 > len(entries) {
+				continue
+			}
+			e := entries[offset:]
+			resID := id<<24 | typeID<<16 | index
+			for _, value := range entryValues(e, pool) {
+				resources = append(resources, Resource{
+					ID:    resID,
+					Type:  typeName,
+					Name:  keyNames.get(entryKey(e)),
+					Value: value,
+				})
+			}
+		}
+		return nil
+	})
+	return resources, err
+}
+
+// poolAt decodes the string pool at offset in a chunk.
+func poolAt(c chunk, offset uint32) (stringPool, error) {
+	start := int(offset) - len(c.header)
+	if start < 0 || start > len(c.body) {
+		return nil, errors.New("invalid string pool offset")
+	}
+	pool, _, err := readChunk(c.body[start:])
+	if err != nil {
+		return nil, err
+	}
+	return parseStringPool(pool)
+}
+
+// entryOffsetSize returns the size of the items of a type chunk's offset
+// table.
+func entryOffsetSize(flags byte) int {
+	if flags&typeFlagOffset16 != 0 && flags&typeFlagSparse == 0 {
+		return 2
+	}
+	return 4
+}
+
+// entryOffset returns the index and offset of the i-th entry of a type chunk,
+// given its offset table. ok is false if the type has no such entry.
+func entryOffset(offsets []byte, flags byte, i int) (index uint32, offset int, ok bool) {
+	switch {
+	case flags&typeFlagSparse != 0:
+		if 4*i+4 > len(offsets) {
+			return 0, 0, false
+		}
+		index = uint32(binary.LittleEndian.Uint16(offsets[4*i:]))
+		return index, 4 * int(binary.LittleEndian.Uint16(offsets[4*i+2:])), true
+	case flags&typeFlagOffset16 != 0:
+		if 2*i+2 > len(offsets) {
+			return 0, 0, false
+		}
+		off := binary.LittleEndian.Uint16(offsets[2*i:])
+		return uint32(i), 4 * int(off), off != 0xffff
+	default:
+		if 4*i+4 > len(offsets) {
+			return 0, 0, false
+		}
+		off := binary.LittleEndian.Uint32(offsets[4*i:])
+		return uint32(i), int(off), off != noEntry
+	}
+}
+
+// entryKey returns the key name index of an entry.
+func entryKey(e []byte) uint32 {
+	if binary.LittleEndian.Uint16(e[2:])&entryFlagCompact != 0 {
+		return uint32(binary.LittleEndian.Uint16(e))
+	}
+	return binary.LittleEndian.Uint32(e[4:])
+}
+
+// entryValues returns the string values of an entry: its value, or the values
+// of its map if it's a complex entry like a style or an array.
+func entryValues(e []byte, pool stringPool) []string {
+	size := int(binary.LittleEndian.Uint16(e))
+	flags := binary.LittleEndian.Uint16(e[2:])
+	switch {
+	case flags&entryFlagCompact != 0:
+		// Compact entries hold their value in place of their size and key.
+		if byte(flags>>8) == typeString {
+			return []string{pool.get(binary.LittleEndian.Uint32(e[4:]))}
+		}
+	case flags&entryFlagComplex != 0:
+		if size < 16 || len(e) < 16 {
+			return nil
+		}
+		var values []string
+		count := int(binary.LittleEndian.Uint32(e[12:]))
+		if n := (len(e) - size) / 12; count > n {
+			count = n
+		}
+		for i := 0; i < count; i++ {
+			m := size + 12*i
+			if e[m+7] == typeString {
+				values = append(values, pool.get(binary.LittleEndian.Uint32(e[m+8:])))
+			}
+		}
+		return values
+	default:
+		if size+8 > len(e) {
+			return nil
+		}
+		if e[size+3] == typeString {
+			return []string{pool.get(binary.LittleEndian.Uint32(e[size+4:]))}
+		}
+	}
+	return nil
+}
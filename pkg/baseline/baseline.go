@@ -0,0 +1,237 @@
+// Package baseline records findings that have been reviewed, so later scans
+// can leave them out of their results, or report them with their triage
+// status and assignee.
+//
+// A baseline file holds one JSON entry per line. Entries are only ever
+// appended, and the last entry for a finding decides its status, which keeps
+// the file easy to review and merge in version control.
+package baseline
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/findings"
+)
+
+// Status is the outcome of reviewing a finding.
+type Status string
+
+const (
+	// StatusIgnored marks a false positive or an accepted risk.
+	StatusIgnored Status = "ignored"
+	// StatusTriaged marks a real finding that's being dealt with.
+	StatusTriaged Status = "triaged"
+	// StatusOpen undoes an earlier status, so the finding is reported again.
+	StatusOpen Status = "open"
+	// StatusAcknowledged marks a real finding that someone has taken on.
+	StatusAcknowledged Status = "acknowledged"
+	// StatusFalsePositive marks a finding that isn't a secret.
+	StatusFalsePositive Status = "false_positive"
+	// StatusRotated marks a secret that has been rotated. The finding is
+	// reported again if it's verified, since the secret still works.
+	StatusRotated Status = "rotated"
+)
+
+// Statuses are the statuses a finding can be marked with.
+var Statuses = []Status{StatusOpen, StatusAcknowledged, StatusFalsePositive, StatusRotated, StatusIgnored, StatusTriaged}
+
+// ParseStatus returns the status named s. False positive can also be written
+// as false-positive.
+func ParseStatus(s string) (Status, error) {
+	status := Status(strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), "-", "_"))
+	for _, known := range Statuses {
+		if status == known {
+			return status, nil
+		}
+	}
+	return "", errors.Errorf("unknown status %q, want open, acknowledged, false_positive, rotated, ignored or triaged", s)
+}
+
+// Extra data keys of the annotations Tag adds to results.
+const (
+	StatusKey   = "triage_status"
+	AssigneeKey = "assignee"
+)
+
+// Entry is a line of a baseline file.
+type Entry struct {
+	// ID is the finding ID, from findings.ID.
+	ID       string    `json:"id"`
+	Status   Status    `json:"status"`
+	Assignee string    `json:"assignee,omitempty"`
+	Detector string    `json:"detector,omitempty"`
+	Redacted string    `json:"redacted,omitempty"`
+	Source   string    `json:"source,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// Baseline is the set of reviewed findings in a baseline file.
+type Baseline struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]Entry
+}
+
+// Load reads the baseline file at path. A file that doesn't exist yet is an
+// empty baseline, and is created when the first finding is marked.
+func Load(path string) (*Baseline, error) {
+	b := &Baseline{path: path, entries: map[string]Entry{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return b, nil
+	}
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not read baseline", 0)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 || text[0] == '#' {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(text, &entry); err != nil {
+			return nil, errors.WrapPrefix(err, "could not parse baseline line "+strconv.Itoa(line), 0)
+		}
+		b.entries[entry.ID] = entry
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.WrapPrefix(err, "could not read baseline", 0)
+	}
+	return b, nil
+}
+
+// Get returns the latest entry for the finding with id, or false if it
+// hasn't been reviewed.
+func (b *Baseline) Get(id string) (Entry, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entry, ok := b.entries[id]
+	return entry, ok
+}
+
+// Entries returns the latest entry for each reviewed finding, oldest first.
+func (b *Baseline) Entries() []Entry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entries := make([]Entry, 0, len(b.entries))
+	for _, entry := range b.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].Time.Equal(entries[j].Time) {
+			return entries[i].Time.Before(entries[j].Time)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	return entries
+}
+
+// Status returns the status of the finding with id, or StatusOpen if it hasn't
+// been reviewed.
+func (b *Baseline) Status(id string) Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if entry, ok := b.entries[id]; ok {
+		return entry.Status
+	}
+	return StatusOpen
+}
+
+// Contains reports whether r has been reviewed, and should be left out of
+// results. Rotated findings are left out unless they're verified.
+func (b *Baseline) Contains(r *detectors.ResultWithMetadata) bool {
+	switch b.Status(findings.ID(r)) {
+	case StatusOpen:
+		return false
+	case StatusRotated:
+		return !r.Verified
+	}
+	return true
+}
+
+// Tag adds the status and assignee of r, if it's been reviewed or assigned,
+// to its extra data, so they're carried forward to the scan's results.
+func (b *Baseline) Tag(r *detectors.ResultWithMetadata) {
+	entry, ok := b.Get(findings.ID(r))
+	if !ok || (entry.Status == StatusOpen && entry.Assignee == "") {
+		return
+	}
+	if r.ExtraData == nil {
+		r.ExtraData = map[string]string{}
+	}
+	r.ExtraData[StatusKey] = string(entry.Status)
+	if entry.Assignee != "" {
+		r.ExtraData[AssigneeKey] = entry.Assignee
+	}
+}
+
+// Mark sets the status of r and appends it to the baseline file. Its
+// assignee, if it has one, is kept.
+func (b *Baseline) Mark(r *detectors.ResultWithMetadata, status Status) error {
+	id := findings.ID(r)
+	entry, _ := b.Get(id)
+	entry.ID = id
+	entry.Status = status
+	entry.Detector = r.DetectorType.String()
+	entry.Redacted = r.Redacted
+	entry.Source = r.SourceName
+	return b.Annotate(entry)
+}
+
+// Annotate appends entry to the baseline file, replacing the finding's
+// earlier entry. Its time is set to now. Findings can be annotated by ID
+// alone, such as from the output of an earlier scan, in which case the
+// detector, redacted secret and source of their earlier entry are kept.
+func (b *Baseline) Annotate(entry Entry) error {
+	if entry.ID == "" {
+		return errors.New("an annotation needs a finding ID")
+	}
+	if entry.Status == "" {
+		entry.Status = StatusOpen
+	}
+	if previous, ok := b.Get(entry.ID); ok {
+		if entry.Detector == "" {
+			entry.Detector = previous.Detector
+		}
+		if entry.Redacted == "" {
+			entry.Redacted = previous.Redacted
+		}
+		if entry.Source == "" {
+			entry.Source = previous.Source
+		}
+	}
+	entry.Time = time.Now().UTC()
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	f, err := os.OpenFile(b.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not open baseline", 0)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return errors.WrapPrefix(err, "could not write baseline", 0)
+	}
+	if err := f.Close(); err != nil {
+		return errors.WrapPrefix(err, "could not write baseline", 0)
+	}
+	b.entries[entry.ID] = entry
+	return nil
+}
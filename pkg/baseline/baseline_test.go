@@ -0,0 +1,157 @@
+package baseline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/findings"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+func result(raw string) *detectors.ResultWithMetadata {
+	return &detectors.ResultWithMetadata{
+		SourceName: "fs",
+		Result: detectors.Result{
+			DetectorType: detectorspb.DetectorType_AWS,
+			Raw:          []byte(raw),
+			Redacted:     raw[:4],
+		},
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.jsonl")
+
+	b, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ignored, triaged, reopened := result("AKIAIGNORED"), result("AKIATRIAGED"), result("AKIAREOPENED")
+	for _, mark := range []struct {
+		r      *detectors.ResultWithMetadata
+		status Status
+	}{
+		{ignored, StatusIgnored},
+		{triaged, StatusTriaged},
+		{reopened, StatusIgnored},
+		{reopened, StatusOpen},
+	} {
+		if err := b.Mark(mark.r, mark.status); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Reload to check the statuses were written to the file.
+	b, err = Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		r    *detectors.ResultWithMetadata
+		want bool
+	}{
+		{name: "ignored", r: ignored, want: true},
+		{name: "triaged", r: triaged, want: true},
+		{name: "reopened", r: reopened, want: false},
+		{name: "unreviewed", r: result("AKIAUNREVIEWED"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.Contains(tt.r); got != tt.want {
+				t.Errorf("Contains() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.jsonl")
+	if err := os.WriteFile(path, []byte("# reviewed findings\n\n{\"id\": \"abc\", \"status\": \"ignored\"}\nnot json\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load() should fail on a line that isn't JSON")
+	}
+}
+
+func TestBaseline_Annotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.jsonl")
+	b, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acked, rotated, assigned := result("AKIAACKED"), result("AKIAROTATED"), result("AKIAASSIGNED")
+	if err := b.Mark(acked, StatusOpen); err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range []Entry{
+		{ID: findings.ID(acked), Status: StatusAcknowledged, Assignee: "alice"},
+		{ID: findings.ID(rotated), Status: StatusRotated},
+		{ID: findings.ID(assigned), Assignee: "bob"},
+	} {
+		if err := b.Annotate(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Annotate(Entry{Status: StatusRotated}); err == nil {
+		t.Error("Annotate() accepted an entry without an ID")
+	}
+	// Marking a finding keeps its assignee.
+	if err := b.Mark(acked, StatusFalsePositive); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err = Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, ok := b.Get(findings.ID(acked))
+	if !ok || entry.Status != StatusFalsePositive || entry.Assignee != "alice" || entry.Detector != "AWS" || entry.Redacted != "AKIA" {
+		t.Errorf("Get() = %+v, %v", entry, ok)
+	}
+	if entries := b.Entries(); len(entries) != 3 {
+		t.Errorf("Entries() = %+v, want 3 entries", entries)
+	}
+
+	verifiedRotated := result("AKIAROTATED")
+	verifiedRotated.Verified = true
+	tests := []struct {
+		name         string
+		r            *detectors.ResultWithMetadata
+		contains     bool
+		wantStatus   string
+		wantAssignee string
+	}{
+		{name: "false positive", r: acked, contains: true, wantStatus: "false_positive", wantAssignee: "alice"},
+		{name: "rotated", r: rotated, contains: true, wantStatus: "rotated"},
+		{name: "rotated but still live", r: verifiedRotated, contains: false, wantStatus: "rotated"},
+		{name: "assigned", r: assigned, contains: false, wantStatus: "open", wantAssignee: "bob"},
+		{name: "unreviewed", r: result("AKIAUNREVIEWED"), contains: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.Contains(tt.r); got != tt.contains {
+				t.Errorf("Contains() = %v, want %v", got, tt.contains)
+			}
+			r := *tt.r
+			b.Tag(&r)
+			if r.ExtraData[StatusKey] != tt.wantStatus || r.ExtraData[AssigneeKey] != tt.wantAssignee {
+				t.Errorf("Tag() extra data = %v", r.ExtraData)
+			}
+		})
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	for s, want := range map[string]Status{"acknowledged": StatusAcknowledged, "False-Positive": StatusFalsePositive, "rotated": StatusRotated, "open": StatusOpen} {
+		if got, err := ParseStatus(s); err != nil || got != want {
+			t.Errorf("ParseStatus(%q) = %q, %v, want %q", s, got, err, want)
+		}
+	}
+	if _, err := ParseStatus("fixed"); err == nil {
+		t.Error("ParseStatus() accepted an unknown status")
+	}
+}
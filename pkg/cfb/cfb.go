@@ -0,0 +1,295 @@
+// Package cfb reads Compound File Binary files, the OLE container format of
+// Outlook .msg files and legacy Office documents. Files are read as a tree of
+// storages and streams, like directories and files.
+// https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-cfb
+package cfb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"unicode/utf16"
+
+	"github.com/go-errors/errors"
+)
+
+const (
+	headerSize   = 512
+	dirEntrySize = 128
+
+	// Special sector numbers.
+	maxRegSect = 0xfffffffa
+	endOfChain = 0xfffffffe
+	freeSect   = 0xffffffff
+	noStream   = 0xffffffff
+
+	typeStorage = 1
+	typeStream  = 2
+	typeRoot    = 5
+)
+
+var fileMagic = []byte{0xd0, 0xcf, 0x11, 0xe0, 0xa1, 0xb1, 0x1a, 0xe1}
+
+// ErrNotCFB is returned by Open for files that aren't compound files.
+var ErrNotCFB = errors.New("not a compound file")
+
+// IsCFB reports whether header, the first bytes of a file, starts a compound
+// file.
+func IsCFB(header []byte) bool {
+	return bytes.HasPrefix(header, fileMagic)
+}
+
+// File is an open compound file.
+type File struct {
+	r          io.ReaderAt
+	sectorSize int64
+	miniSize   int64
+	miniCutoff int64
+	fat        []uint32
+	miniFAT    []uint32
+	entries    []*Entry
+	// miniStream holds the streams smaller than miniCutoff.
+	miniStream []byte
+}
+
+// Entry is a storage or stream of a compound file.
+type Entry struct {
+	Name string
+	// Size is the size of a stream.
+	Size int64
+
+	typ         byte
+	left, right uint32
+	child       uint32
+	start       uint32
+	children    []*Entry
+}
+
+// IsStorage reports whether the entry is a storage, which holds other
+// entries.
+func (e *Entry) IsStorage() bool {
+	return e.typ == typeStorage || e.typ == typeRoot
+}
+
+// Children returns the entries of a storage.
+func (e *Entry) Children() []*Entry {
+	return e.children
+}
+
+// Child returns the entry of a storage with the given name, or nil.
+func (e *Entry) Child(name string) *Entry {
+	for _, child := range e.children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// Open reads the structure of the compound file r.
+func Open(r io.ReaderAt) (*File, error) {
+	header := make([]byte, headerSize)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrNotCFB
+		}
+		return nil, errors.WrapPrefix(err, "could not read compound file header", 0)
+	}
+	if !IsCFB(header) || binary.LittleEndian.Uint16(header[0x1c:]) != 0xfffe {
+		return nil, ErrNotCFB
+	}
+	sectorShift := binary.LittleEndian.Uint16(header[0x1e:])
+	miniShift := binary.LittleEndian.Uint16(header[0x20:])
+	if (sectorShift != 9 && sectorShift != 12) || miniShift != 6 {
+		return nil, errors.WrapPrefix(ErrNotCFB, "invalid sector size", 0)
+	}
+	f := &File{
+		r:          r,
+		sectorSize: 1 << sectorShift,
+		miniSize:   1 << miniShift,
+		miniCutoff: int64(binary.LittleEndian.Uint32(header[0x38:])),
+	}
+
+	// The sectors of the FAT are listed by the DIFAT, the first 109 entries of
+	// which are in the header, and the rest in a chain of DIFAT sectors.
+	numFAT := binary.LittleEndian.Uint32(header[0x2c:])
+	var fatSectors []uint32
+	for i := 0; i < 109 && uint32(len(fatSectors)) < numFAT; i++ {
+		fatSectors = append(fatSectors, binary.LittleEndian.Uint32(header[0x4c+4*i:]))
+	}
+	difatSector := binary.LittleEndian.Uint32(header[0x44:])
+	for seen := 0; difatSector <= maxRegSect && uint32(len(fatSectors)) < numFAT; seen++ {
+		if seen > int(numFAT) {
+			return nil, errors.New("DIFAT chain is too long")
+		}
+		sector, err := f.readSector(difatSector)
+		if err != nil {
+			return nil, err
+		}
+		last := len(sector) - 4
+		for i := 0; i < last && uint32(len(fatSectors)) < numFAT; i += 4 {
+			fatSectors = append(fatSectors, binary.LittleEndian.Uint32(sector[i:]))
+		}
+		difatSector = binary.LittleEndian.Uint32(sector[last:])
+	}
+	for _, sectorNum := range fatSectors {
+		sector, err := f.readSector(sectorNum)
+		if err != nil {
+			return nil, err
+		}
+		f.fat = append(f.fat, sectorEntries(sector)...)
+	}
+
+	dir, err := f.readChain(binary.LittleEndian.Uint32(header[0x30:]), -1)
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not read directory", 0)
+	}
+	for i := 0; i+dirEntrySize <= len(dir); i += dirEntrySize {
+		f.entries = append(f.entries, parseEntry(dir[i:i+dirEntrySize]))
+	}
+	if len(f.entries) == 0 || f.entries[0].typ != typeRoot {
+		return nil, errors.New("compound file has no root storage")
+	}
+	f.linkChildren(f.entries[0], map[uint32]bool{0: true})
+
+	miniFAT, err := f.readChain(binary.LittleEndian.Uint32(header[0x3c:]), -1)
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not read mini FAT", 0)
+	}
+	f.miniFAT = sectorEntries(miniFAT)
+	root := f.entries[0]
+	f.miniStream, err = f.readChain(root.start, root.Size)
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not read mini stream", 0)
+	}
+	return f, nil
+}
+
+// Root returns the root storage.
+func (f *File) Root() *Entry {
+	return f.entries[0]
+}
+
+// ReadStream returns the contents of a stream.
+func (f *File) ReadStream(e *Entry) ([]byte, error) {
+	if e.typ != typeStream {
+		return nil, errors.Errorf("%s is not a stream", e.Name)
+	}
+	if e.Size < f.miniCutoff {
+		return f.readMiniChain(e.start, e.Size)
+	}
+	return f.readChain(e.start, e.Size)
+}
+
+// readSector returns the contents of a sector. The header takes up the first
+// sector of the file, so sector n starts after n+1 sectors.
+func (f *File) readSector(n uint32) ([]byte, error) {
+	sector := make([]byte, f.sectorSize)
+	if _, err := f.r.ReadAt(sector, (int64(n)+1)*f.sectorSize); err != nil {
+		return nil, errors.WrapPrefix(err, "could not read sector", 0)
+	}
+	return sector, nil
+}
+
+// readChain reads the chain of sectors starting at start, up to size bytes if
+// size isn't negative.
+func (f *File) readChain(start uint32, size int64) ([]byte, error) {
+	var data []byte
+	for sector := start; sector != endOfChain && (size < 0 || int64(len(data)) < size); {
+		if sector > maxRegSect || int(sector) >= len(f.fat) {
+			if size < 0 && sector == freeSect {
+				break
+			}
+			return nil, errors.Errorf("invalid sector %d in chain", sector)
+		}
+		if len(data) > len(f.fat)*int(f.sectorSize) {
+			return nil, errors.New("sector chain has a cycle")
+		}
+		buf, err := f.readSector(sector)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, buf...)
+		sector = f.fat[sector]
+	}
+	if size >= 0 {
+		if int64(len(data)) < size {
+			return nil, errors.New("sector chain is shorter than its stream")
+		}
+		data = data[:size]
+	}
+	return data, nil
+}
+
+// readMiniChain reads size bytes from the chain of mini sectors starting at
+// start.
+func (f *File) readMiniChain(start uint32, size int64) ([]byte, error) {
+	var data []byte
+	for sector := start; int64(len(data)) < size; {
+		if int(sector) >= len(f.miniFAT) || int64(sector+1)*f.miniSize > int64(len(f.miniStream)) {
+			return nil, errors.Errorf("invalid mini sector %d in chain", sector)
+		}
+		if len(data) > len(f.miniStream) {
+			return nil, errors.New("mini sector chain has a cycle")
+		}
+		offset := int64(sector) * f.miniSize
+		data = append(data, f.miniStream[offset:offset+f.miniSize]...)
+		sector = f.miniFAT[sector]
+	}
+	return data[:size], nil
+}
+
+// linkChildren sets the children of a storage, which are stored as a
+// red-black tree of siblings under its child.
+func (f *File) linkChildren(e *Entry, seen map[uint32]bool) {
+	var walk func(id uint32)
+	walk = func(id uint32) {
+		if id == noStream || int(id) >= len(f.entries) || seen[id] {
+			return
+		}
+		seen[id] = true
+		child := f.entries[id]
+		walk(child.left)
+		e.children = append(e.children, child)
+		walk(child.right)
+		if child.IsStorage() {
+			f.linkChildren(child, seen)
+		}
+	}
+	walk(e.child)
+}
+
+// parseEntry parses a directory entry.
+func parseEntry(b []byte) *Entry {
+	nameLen := int(binary.LittleEndian.Uint16(b[64:]))
+	if nameLen > 64 {
+		nameLen = 64
+	}
+	name := make([]uint16, 0, nameLen/2)
+	for i := 0; i+1 < nameLen; i += 2 {
+		c := binary.LittleEndian.Uint16(b[i:])
+		if c == 0 {
+			break
+		}
+		name = append(name, c)
+	}
+	return &Entry{
+		Name:  string(utf16.Decode(name)),
+		typ:   b[66],
+		left:  binary.LittleEndian.Uint32(b[68:]),
+		right: binary.LittleEndian.Uint32(b[72:]),
+		child: binary.LittleEndian.Uint32(b[76:]),
+		start: binary.LittleEndian.Uint32(b[116:]),
+		// Version 3 files may have garbage in the high bits of the size.
+		Size: int64(binary.LittleEndian.Uint32(b[120:])),
+	}
+}
+
+// sectorEntries returns a FAT sector's entries.
+func sectorEntries(sector []byte) []uint32 {
+	entries := make([]uint32, len(sector)/4)
+	for i := range entries {
+		entries[i] = binary.LittleEndian.Uint32(sector[4*i:])
+	}
+	return entries
+}
@@ -0,0 +1,114 @@
+package cfb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+// testFile is a gzipped compound file with 512 byte sectors, holding:
+//
+//	Notes: "hello, world"
+//	Docs/
+//		small: "compound file test stream\n" x 3, in the mini stream
+//		large: 5000 bytes of (i*7)%251
+//	Empty: an empty stream
+const testFile = "" +
+	"H4sIAAAAAAACA7twXvDBwo1SDxnQgB0DM8O//5wMbEhijEDMA+MIMDBwQ8X+/f//HyTEB8T/" +
+	"R8GQAgzsfKIyylqGFvZuviHRSZkF5XWtPZNnLVyxftveI6cv3Xzw/N1XRg5+MVkVbSNLB3e/" +
+	"0JjkrMKK+rbeKbMXrdywfd/RM5dvPXzx/hsTp4C4nKqOsZWjh39YbEp2UWVDe9/UOYtXbdyx" +
+	"/9jZK7cfvfzwnZlLUEJeTdfE2skzIDwuNae4qrGjf9rcJas37Txw/NzVO49fffzBwi0kqaCu" +
+	"Z2rj7BUYEZ+WW1Ld1Dlh+rylazbvOnji/LW7T15/+snKIyylqKFvZuviHRSZkJ5XWtPcNXHG" +
+	"/GVrt+w+dPLC9XtP33z+xcYrIq2kaWBu5+oTHJWYkV9W29I9aeaC5eu27jl86uKN+8/efhn1" +
+	"+qjXR70+6vVRr496fdTro14f9fqo10e9Pur1Ua+Pen3U66NeH/X6qNfp5nWGAQIZqTk5+ToK" +
+	"5flFOSnk6E/Ozy3IL81LUUjLzElVKEktLlEoLilKTczlIkeGYRTQGYDG7pmg9CgYeSCIIR8I" +
+	"SxgUGFwZ8oB0EUMlSelHjIGVEWYWI5F6QNn8AJTtB7Y9laGYzPTLw8AEtp8JOvdEvD4IcAHa" +
+	"n0y27SC/MILtZwWymUnIczB2MUMuQyJDDhBS5n8WEvzPCA53CMgB2l7EkA6MAcrsh88nEQk6" +
+	"hCG0K9D/BcAUUElH+/+R4E5iADn+Hyz2g9ICEzTtgtIQKB2D5nzZgZgDiDmh4QXDvFD+39Gi" +
+	"c1gAAF1zCPwAIAAA"
+
+func openTestFile(t *testing.T) *bytes.Reader {
+	t.Helper()
+	zr, err := gzip.NewReader(base64.NewDecoder(base64.StdEncoding, strings.NewReader(testFile)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(data)
+}
+
+func TestOpen(t *testing.T) {
+	f, err := Open(openTestFile(t))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	var names []string
+	var walk func(prefix string, e *Entry)
+	walk = func(prefix string, e *Entry) {
+		for _, child := range e.Children() {
+			names = append(names, prefix+child.Name)
+			if child.IsStorage() {
+				walk(prefix+child.Name+"/", child)
+			}
+		}
+	}
+	walk("", f.Root())
+	if diff := pretty.Compare(names, []string{"Notes", "Docs", "Docs/small", "Docs/large", "Empty"}); diff != "" {
+		t.Errorf("Open() entries diff: (-got +want)\n%s", diff)
+	}
+
+	large := make([]byte, 5000)
+	for i := range large {
+		large[i] = byte((i * 7) % 251)
+	}
+	tests := []struct {
+		entry *Entry
+		want  []byte
+	}{
+		{entry: f.Root().Child("Notes"), want: []byte("hello, world")},
+		{entry: f.Root().Child("Docs").Child("small"), want: []byte(strings.Repeat("compound file test stream\n", 3))},
+		{entry: f.Root().Child("Docs").Child("large"), want: large},
+		{entry: f.Root().Child("Empty"), want: []byte{}},
+	}
+	for _, tt := range tests {
+		got, err := f.ReadStream(tt.entry)
+		if err != nil {
+			t.Errorf("ReadStream(%s) error = %v", tt.entry.Name, err)
+			continue
+		}
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("ReadStream(%s) = %q, want %q", tt.entry.Name, got, tt.want)
+		}
+	}
+
+	if _, err := f.ReadStream(f.Root().Child("Docs")); err == nil {
+		t.Error("ReadStream() of a storage succeeded")
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	if _, err := Open(strings.NewReader("not a compound file")); !errors.Is(err, ErrNotCFB) {
+		t.Errorf("Open() of a text file error = %v, want ErrNotCFB", err)
+	}
+
+	// A FAT that chains a directory sector to itself.
+	data, _ := io.ReadAll(openTestFile(t))
+	header := data[:512]
+	fatSector := int(header[0x4c]) | int(header[0x4d])<<8
+	dirSector := int(header[0x30]) | int(header[0x31])<<8
+	fat := data[(fatSector+1)*512:]
+	copy(fat[4*dirSector:], []byte{byte(dirSector), byte(dirSector >> 8), 0, 0})
+	if _, err := Open(bytes.NewReader(data)); err == nil {
+		t.Error("Open() of a file with a sector cycle succeeded")
+	}
+}
@@ -0,0 +1,121 @@
+package common
+
+import (
+	"context"
+	"io"
+
+	"golang.org/x/time/rate"
+)
+
+// maxBandwidthBurst is the most a BandwidthLimiter lets through at once, so
+// that downloads are spread evenly over each second rather than sent in bursts
+// that saturate the link.
+const maxBandwidthBurst = 64 * 1024
+
+// BandwidthLimiter limits how fast sources download the objects they scan,
+// such as the objects of S3 buckets, so that scanning them doesn't saturate
+// the network. It's shared by every download it limits. A nil limiter doesn't
+// limit anything.
+type BandwidthLimiter struct {
+	limiter *rate.Limiter
+	// parent also limits the downloads this limits, when it's set.
+	parent *BandwidthLimiter
+}
+
+// NewBandwidthLimiter returns a limiter of downloads to bytesPerSecond, or nil
+// if bytesPerSecond isn't positive.
+func NewBandwidthLimiter(bytesPerSecond int64) *BandwidthLimiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	burst := maxBandwidthBurst
+	if bytesPerSecond < int64(burst) {
+		burst = int(bytesPerSecond)
+	}
+	return &BandwidthLimiter{limiter: rate.NewLimiter(rate.Limit(bytesPerSecond), burst)}
+}
+
+// Within returns a limiter of downloads to both l and parent, either of which
+// may be nil, such as the limit of one tenant within the limit of all.
+func (l *BandwidthLimiter) Within(parent *BandwidthLimiter) *BandwidthLimiter {
+	if l == nil {
+		return parent
+	}
+	if parent == nil {
+		return l
+	}
+	return &BandwidthLimiter{limiter: l.limiter, parent: parent}
+}
+
+// wait blocks until n more bytes may be downloaded, or ctx is done.
+func (l *BandwidthLimiter) wait(ctx context.Context, n int) error {
+	if l == nil {
+		return nil
+	}
+	if err := l.parent.wait(ctx, n); err != nil {
+		return err
+	}
+	for n > 0 {
+		burst := n
+		if burst > l.limiter.Burst() {
+			burst = l.limiter.Burst()
+		}
+		if err := l.limiter.WaitN(ctx, burst); err != nil {
+			return err
+		}
+		n -= burst
+	}
+	return nil
+}
+
+// LimitReader returns a reader of r that reads no faster than each of
+// limiters allows, any of which may be nil. Reads wait for the limiters, or
+// until ctx is done, after reading, so time spent waiting isn't time spent
+// with a read outstanding.
+func LimitReader(ctx context.Context, r io.Reader, limiters ...*BandwidthLimiter) io.Reader {
+	var active []*BandwidthLimiter
+	for _, l := range limiters {
+		if l != nil {
+			active = append(active, l)
+		}
+	}
+	if len(active) == 0 {
+		return r
+	}
+	return &limitedReader{ctx: ctx, r: r, limiters: active}
+}
+
+type limitedReader struct {
+	ctx      context.Context
+	r        io.Reader
+	limiters []*BandwidthLimiter
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if len(p) > maxBandwidthBurst {
+		p = p[:maxBandwidthBurst]
+	}
+	n, err := r.r.Read(p)
+	for _, l := range r.limiters {
+		if waitErr := l.wait(r.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+type bandwidthLimiterKey struct{}
+
+// WithBandwidthLimiter returns a context that carries limiter. Sources scanned
+// with the context limit their downloads to it, along with any limit of their
+// own.
+func WithBandwidthLimiter(ctx context.Context, limiter *BandwidthLimiter) context.Context {
+	return context.WithValue(ctx, bandwidthLimiterKey{}, limiter)
+}
+
+// BandwidthLimiterFromContext returns the limiter stored in ctx, or nil if
+// there is none.
+func BandwidthLimiterFromContext(ctx context.Context) *BandwidthLimiter {
+	limiter, _ := ctx.Value(bandwidthLimiterKey{}).(*BandwidthLimiter)
+	return limiter
+}
@@ -0,0 +1,85 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestNewBandwidthLimiter(t *testing.T) {
+	for _, bytesPerSecond := range []int64{0, -1} {
+		if l := NewBandwidthLimiter(bytesPerSecond); l != nil {
+			t.Errorf("NewBandwidthLimiter(%d) = %v, want nil", bytesPerSecond, l)
+		}
+	}
+	if burst := NewBandwidthLimiter(100).limiter.Burst(); burst != 100 {
+		t.Errorf("burst below the largest = %d, want 100", burst)
+	}
+	if burst := NewBandwidthLimiter(10 << 20).limiter.Burst(); burst != maxBandwidthBurst {
+		t.Errorf("burst above the largest = %d, want %d", burst, maxBandwidthBurst)
+	}
+}
+
+func TestLimitReader(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), 300*1024)
+
+	r := bytes.NewReader(data)
+	if got := LimitReader(context.Background(), r, nil, nil); got != io.Reader(r) {
+		t.Errorf("LimitReader() without limiters = %T, want the reader", got)
+	}
+
+	// The limiters start with a full burst, so reading takes the time of the
+	// rest at the slowest limit.
+	slow, fast := NewBandwidthLimiter(1<<20), NewBandwidthLimiter(10<<20)
+	start := time.Now()
+	got, err := io.ReadAll(LimitReader(context.Background(), bytes.NewReader(data), fast, nil, slow))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("read %d bytes, want %d", len(got), len(data))
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("reading 300KiB at 1MiB/s took %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := io.ReadAll(LimitReader(ctx, bytes.NewReader(data), NewBandwidthLimiter(1024))); err == nil {
+		t.Error("reading with a cancelled context succeeded")
+	}
+}
+
+func TestBandwidthLimiter_Within(t *testing.T) {
+	all := NewBandwidthLimiter(1024)
+	if l := (*BandwidthLimiter)(nil).Within(all); l != all {
+		t.Errorf("Within() of no limit = %v, want the parent", l)
+	}
+	tenant := NewBandwidthLimiter(1024)
+	if l := tenant.Within(nil); l != tenant {
+		t.Errorf("Within(nil) = %v, want the limiter", l)
+	}
+
+	// Reading the burst of both limiters leaves none for the other limiter
+	// of the parent.
+	ctx := context.Background()
+	within := tenant.Within(all)
+	if err := within.wait(ctx, 1024); err != nil {
+		t.Fatal(err)
+	}
+	if all.limiter.AllowN(time.Now(), 512) {
+		t.Error("reading within the parent didn't count against it")
+	}
+}
+
+func TestBandwidthLimiterFromContext(t *testing.T) {
+	if l := BandwidthLimiterFromContext(context.Background()); l != nil {
+		t.Errorf("BandwidthLimiterFromContext() = %v, want nil", l)
+	}
+	limiter := NewBandwidthLimiter(1024)
+	if l := BandwidthLimiterFromContext(WithBandwidthLimiter(context.Background(), limiter)); l != limiter {
+		t.Errorf("BandwidthLimiterFromContext() = %v, want %v", l, limiter)
+	}
+}
@@ -0,0 +1,112 @@
+package common
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// dnsCacheTTL is how long resolved addresses are reused. Verification sends
+// many requests to the same few API hosts, so even a short TTL saves most
+// lookups.
+const dnsCacheTTL = 1 * time.Minute
+
+// dnsCacheSize is the most hosts cached at once. Detectors that verify
+// against hosts found in the scanned data can contact any number of them.
+const dnsCacheSize = 1024
+
+// dnsCache remembers the addresses hosts resolved to so that connections to
+// the same host don't each wait on a lookup.
+type dnsCache struct {
+	mu      sync.Mutex
+	entries map[string]dnsCacheEntry
+	ttl     time.Duration
+	size    int
+	lookup  func(ctx context.Context, host string) ([]string, error)
+	now     func() time.Time
+}
+
+type dnsCacheEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+func newDNSCache(ttl time.Duration) *dnsCache {
+	return &dnsCache{
+		entries: map[string]dnsCacheEntry{},
+		ttl:     ttl,
+		size:    dnsCacheSize,
+		lookup:  net.DefaultResolver.LookupHost,
+		now:     time.Now,
+	}
+}
+
+// LookupHost returns the addresses of host, resolving it if it isn't cached
+// or its entry has expired. Failed lookups aren't cached.
+func (c *dnsCache) LookupHost(ctx context.Context, host string) ([]string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[host]
+	if ok && !c.now().Before(entry.expires) {
+		delete(c.entries, host)
+		ok = false
+	}
+	c.mu.Unlock()
+	if ok {
+		return entry.addrs, nil
+	}
+
+	addrs, err := c.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[host]; !ok && len(c.entries) >= c.size {
+		c.evict()
+	}
+	c.entries[host] = dnsCacheEntry{addrs: addrs, expires: c.now().Add(c.ttl)}
+	return addrs, nil
+}
+
+// evict makes room for an entry by dropping the expired ones, or if none
+// have, the one that expires first. c.mu must be held.
+func (c *dnsCache) evict() {
+	now := c.now()
+	var oldest string
+	for host, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, host)
+			continue
+		}
+		if oldest == "" || entry.expires.Before(c.entries[oldest].expires) {
+			oldest = host
+		}
+	}
+	if len(c.entries) >= c.size {
+		delete(c.entries, oldest)
+	}
+}
+
+// DialContext dials addr using the cached addresses of its host, trying each
+// in turn until one connects.
+func (c *dnsCache) DialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		addrs, err := c.LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range addrs {
+			var conn net.Conn
+			conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+		}
+		return nil, err
+	}
+}
@@ -0,0 +1,96 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDNSCache_LookupHost(t *testing.T) {
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	lookups := 0
+	fail := false
+	cache := newDNSCache(time.Minute)
+	cache.now = func() time.Time { return now }
+	cache.lookup = func(ctx context.Context, host string) ([]string, error) {
+		lookups++
+		if fail {
+			return nil, errors.New("no such host")
+		}
+		return []string{"192.0.2.1"}, nil
+	}
+
+	steps := []struct {
+		name        string
+		advance     time.Duration
+		fail        bool
+		wantErr     bool
+		wantLookups int
+	}{
+		{name: "first lookup resolves", wantLookups: 1},
+		{name: "cached within ttl", advance: 30 * time.Second, wantLookups: 1},
+		{name: "expired entry resolves again", advance: 31 * time.Second, wantLookups: 2},
+		{name: "failure after expiry", advance: 2 * time.Minute, fail: true, wantErr: true, wantLookups: 3},
+		{name: "failure isn't cached", fail: true, wantErr: true, wantLookups: 4},
+	}
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		fail = step.fail
+		addrs, err := cache.LookupHost(context.Background(), "api.example.com")
+		if (err != nil) != step.wantErr {
+			t.Fatalf("%s: LookupHost() error = %v, wantErr %v", step.name, err, step.wantErr)
+		}
+		if !step.wantErr && (len(addrs) != 1 || addrs[0] != "192.0.2.1") {
+			t.Errorf("%s: LookupHost() = %v", step.name, addrs)
+		}
+		if lookups != step.wantLookups {
+			t.Errorf("%s: lookups = %d, want %d", step.name, lookups, step.wantLookups)
+		}
+	}
+}
+
+func TestDNSCache_Eviction(t *testing.T) {
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	cache := newDNSCache(time.Minute)
+	cache.size = 2
+	cache.now = func() time.Time { return now }
+	cache.lookup = func(ctx context.Context, host string) ([]string, error) {
+		return []string{"192.0.2.1"}, nil
+	}
+	lookup := func(host string) {
+		t.Helper()
+		if _, err := cache.LookupHost(context.Background(), host); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lookup("a.example.com")
+	now = now.Add(2 * time.Minute)
+	lookup("b.example.com")
+	now = now.Add(10 * time.Second)
+	lookup("a.example.com")
+	if len(cache.entries) != 2 {
+		t.Fatalf("entries = %d, want 2", len(cache.entries))
+	}
+
+	// a.example.com was looked up again after it expired, so b.example.com
+	// expires first and makes room.
+	now = now.Add(time.Second)
+	lookup("c.example.com")
+	if _, ok := cache.entries["b.example.com"]; ok || len(cache.entries) != 2 {
+		t.Errorf("entries = %v, want a.example.com and c.example.com", cache.entries)
+	}
+
+	// Expired entries are dropped when they're looked up.
+	now = now.Add(2 * time.Minute)
+	cache.lookup = func(ctx context.Context, host string) ([]string, error) {
+		return nil, errors.New("no such host")
+	}
+	if _, err := cache.LookupHost(context.Background(), "a.example.com"); err == nil {
+		t.Fatal("LookupHost() of an expired entry didn't resolve again")
+	}
+	if _, ok := cache.entries["a.example.com"]; ok {
+		t.Errorf("expired entry for a.example.com kept")
+	}
+}
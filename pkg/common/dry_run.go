@@ -0,0 +1,138 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrDryRun is returned by HTTP clients from this package, in place of sending
+// a request, when the request's context carries a DryRun.
+var ErrDryRun = errors.New("request not sent in verification dry run")
+
+// redacted replaces the secrets in the requests a DryRun records.
+const redacted = "REDACTED"
+
+// plainHeaders are the headers whose values are recorded as they are. The
+// values of other headers, and of query parameters, are redacted, since
+// they're likely to carry credentials, such as signatures or the secret half
+// of a key pair, that detectors don't report as results.
+var plainHeaders = map[string]bool{
+	"Accept":          true,
+	"Accept-Encoding": true,
+	"Accept-Language": true,
+	"Cache-Control":   true,
+	"Connection":      true,
+	"Content-Length":  true,
+	"Content-Type":    true,
+	"User-Agent":      true,
+}
+
+// DryRunRequest is a request verification would send, with its secrets
+// redacted. Its body is left out.
+type DryRunRequest struct {
+	Method string
+	URL    string
+	Header http.Header
+}
+
+// DryRun stops verification requests from being sent, recording them instead,
+// so the network behavior of verification can be reviewed before it's
+// enabled.
+type DryRun struct {
+	// Record is called with each request verification would send.
+	Record func(req DryRunRequest)
+	// Secrets are redacted wherever they appear in the requests recorded,
+	// such as in their paths.
+	Secrets []string
+}
+
+type dryRunKey struct{}
+
+// WithDryRun returns a context that carries dryRun. Requests made with the
+// context through this package's HTTP clients are recorded instead of sent.
+func WithDryRun(ctx context.Context, dryRun *DryRun) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, dryRun)
+}
+
+// DryRunFromContext returns the dry run stored in ctx, or nil if there is none.
+func DryRunFromContext(ctx context.Context) *DryRun {
+	dryRun, _ := ctx.Value(dryRunKey{}).(*DryRun)
+	return dryRun
+}
+
+// Intercept records req and reports true if d is set, in which case req must
+// not be sent. This package's HTTP clients intercept requests themselves;
+// detectors that verify with SDKs, whose requests don't carry the detector's
+// context, call it with the request the SDK would send.
+func (d *DryRun) Intercept(req *http.Request) bool {
+	if d == nil {
+		return false
+	}
+	if d.Record != nil {
+		d.Record(d.Redact(req))
+	}
+	return true
+}
+
+// interceptDial records a connection to addr, for protocols other than HTTP,
+// and reports true if d is set.
+func (d *DryRun) interceptDial(addr string, useTLS bool) bool {
+	scheme := "tcp"
+	if useTLS {
+		scheme = "tls"
+	}
+	return d.Intercept(&http.Request{Method: "DIAL", URL: &url.URL{Scheme: scheme, Host: addr}})
+}
+
+// Redact returns req as it's recorded, with its secrets redacted.
+func (d *DryRun) Redact(req *http.Request) DryRunRequest {
+	u := *req.URL
+	if u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(redacted, redacted)
+		} else {
+			u.User = url.User(redacted)
+		}
+	}
+	if u.RawQuery != "" {
+		query := u.Query()
+		for _, values := range query {
+			for i := range values {
+				values[i] = redacted
+			}
+		}
+		u.RawQuery = query.Encode()
+	}
+
+	header := http.Header{}
+	for name, values := range req.Header {
+		for _, value := range values {
+			if !plainHeaders[http.CanonicalHeaderKey(name)] {
+				value = redacted
+			}
+			header.Add(name, d.redactSecrets(value))
+		}
+	}
+	return DryRunRequest{
+		Method: req.Method,
+		URL:    d.redactSecrets(u.String()),
+		Header: header,
+	}
+}
+
+// redactSecrets replaces the secrets in s, as they are and as they're escaped
+// in URLs.
+func (d *DryRun) redactSecrets(s string) string {
+	for _, secret := range d.Secrets {
+		if secret == "" {
+			continue
+		}
+		for _, form := range []string{secret, url.QueryEscape(secret), url.PathEscape(secret)} {
+			s = strings.ReplaceAll(s, form, redacted)
+		}
+	}
+	return s
+}
@@ -5,8 +5,6 @@ import (
 	"fmt"
 	"os"
 	"regexp"
-
-	log "github.com/sirupsen/logrus"
 )
 
 type Filter struct {
@@ -20,7 +18,8 @@ type FilterRuleSet []regexp.Regexp
 func FilterEmpty() *Filter {
 	filter, err := FilterFromFiles("", "")
 	if err != nil {
-		log.WithError(err).Fatalf("could not create empty filter")
+		// No files are read without paths, so this can't happen.
+		panic(fmt.Sprintf("could not create empty filter: %s", err))
 	}
 	return filter
 }
@@ -60,14 +59,9 @@ func FilterRulesFromFile(source string) (*FilterRuleSet, error) {
 
 	file, err := os.Open(source)
 	if err != nil {
-		log.WithError(err).Fatalf("unable to open filter file: %s", source)
+		return nil, fmt.Errorf("unable to open filter file %s: %s", source, err)
 	}
-	defer func(file *os.File) {
-		err := file.Close()
-		if err != nil {
-			log.WithError(err).Fatalf("unable to close filter file: %s", source)
-		}
-	}(file)
+	defer file.Close()
 
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
@@ -91,6 +85,26 @@ func (filter *Filter) Pass(object string) bool {
 	return !excluded && included
 }
 
+// PassesAll reports whether filter passes every object, as the filter
+// FilterEmpty returns does.
+func (filter *Filter) PassesAll() bool {
+	if filter == nil {
+		return true
+	}
+	if filter.exclude != nil && len(*filter.exclude) > 0 {
+		return false
+	}
+	if filter.include == nil {
+		return false
+	}
+	for _, rule := range *filter.include {
+		if rule.String() == "" {
+			return true
+		}
+	}
+	return false
+}
+
 // Matches will return true if any of the regular expressions in the FilterRuleSet match the pattern.
 func (rules *FilterRuleSet) Matches(object string) bool {
 	if rules == nil {
@@ -3,6 +3,7 @@ package common
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"fmt"
 	"net"
 	"net/http"
 	"strings"
@@ -80,7 +81,13 @@ type CustomTransport struct {
 }
 
 func (t *CustomTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if policy := NetworkPolicyFromContext(req.Context()); !policy.Permits(req.URL.Hostname()) {
+		return nil, fmt.Errorf("%w: %s", ErrHostNotPermitted, req.URL.Hostname())
+	}
 	req.Header.Add("User-Agent", "TruffleHog")
+	if DryRunFromContext(req.Context()).Intercept(req) {
+		return nil, ErrDryRun
+	}
 	return t.T.RoundTrip(req)
 }
 
@@ -117,35 +124,49 @@ func RetryableHttpClient() *http.Client {
 	httpClient.RetryMax = 3
 	httpClient.Logger = nil
 	httpClient.HTTPClient.Timeout = 3 * time.Second
-	httpClient.HTTPClient.Transport = NewCustomTransport(nil)
+	httpClient.HTTPClient.Transport = NewCustomTransport(saneTransport)
 	return httpClient.StandardClient()
 }
 
 const DefaultResponseTimeout = 5 * time.Second
 
+// saneTransport is shared by the clients detectors verify with, so that
+// connections to the same API are pooled and reused across findings instead
+// of each verification paying for a new TLS handshake.
 var saneTransport = &http.Transport{
 	Proxy: http.ProxyFromEnvironment,
-	DialContext: (&net.Dialer{
+	DialContext: newDNSCache(dnsCacheTTL).DialContext(&net.Dialer{
 		Timeout:   2 * time.Second,
-		KeepAlive: 5 * time.Second,
-	}).DialContext,
-	MaxIdleConns:          5,
-	IdleConnTimeout:       5 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}),
+	ForceAttemptHTTP2:     true,
+	MaxIdleConns:          100,
+	MaxIdleConnsPerHost:   10,
+	IdleConnTimeout:       90 * time.Second,
 	TLSHandshakeTimeout:   3 * time.Second,
 	ExpectContinueTimeout: 1 * time.Second,
 }
 
-func SaneHttpClient() *http.Client {
+func SaneHttpClient(options ...HttpClientOption) *http.Client {
 	httpClient := &http.Client{}
 	httpClient.Timeout = DefaultResponseTimeout
-	httpClient.Transport = NewCustomTransport(saneTransport)
+	httpClient.Transport = NewCustomTransport(transportWith(options))
 	return httpClient
 }
 
+// RateLimitedHttpClient returns a client for forge APIs that waits out their
+// rate limits. It has no overall timeout, since a pause can last an hour, so
+// requests are bound by their contexts instead.
+func RateLimitedHttpClient(options ...HttpClientOption) *http.Client {
+	return &http.Client{
+		Transport: NewRateLimitTransport(NewCustomTransport(transportWith(options))),
+	}
+}
+
 //custom timeout for some scanners
-func SaneHttpClientTimeOut(timeOutSeconds int64) *http.Client {
+func SaneHttpClientTimeOut(timeOutSeconds int64, options ...HttpClientOption) *http.Client {
 	httpClient := &http.Client{}
 	httpClient.Timeout = time.Second * time.Duration(timeOutSeconds)
-	httpClient.Transport = NewCustomTransport(nil)
+	httpClient.Transport = NewCustomTransport(transportWith(options))
 	return httpClient
 }
@@ -0,0 +1,119 @@
+package common
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// ErrHostNotPermitted is returned by HTTP clients from this package when a
+// request's destination is blocked by the NetworkPolicy in its context.
+var ErrHostNotPermitted = errors.New("host not permitted by network policy")
+
+// NetworkPolicy restricts which hosts verification requests may be sent to.
+type NetworkPolicy struct {
+	// Offline disables verification entirely.
+	Offline bool
+	// Allow lists the only hosts requests may be sent to. An empty list allows all hosts.
+	Allow []string
+	// Deny lists hosts requests may never be sent to. It takes precedence over Allow.
+	Deny []string
+}
+
+// Permits reports whether a request to host is allowed. Rules match the host
+// itself and any of its subdomains, and may be written as "*.example.com".
+func (p *NetworkPolicy) Permits(host string) bool {
+	if p == nil {
+		return true
+	}
+	if p.Offline {
+		return false
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, rule := range p.Deny {
+		if hostMatches(host, rule) {
+			return false
+		}
+	}
+	if len(p.Allow) == 0 {
+		return true
+	}
+	for _, rule := range p.Allow {
+		if hostMatches(host, rule) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsExplicitly reports whether a request to host is allowed and host is
+// matched by an Allow rule. Detectors that log in to servers found in the
+// scanned data, which are usually internal and may lock accounts after failed
+// logins, only contact hosts that were allowed this way.
+func (p *NetworkPolicy) AllowsExplicitly(host string) bool {
+	if p == nil || len(p.Allow) == 0 {
+		return false
+	}
+	return p.Permits(host)
+}
+
+func hostMatches(host, rule string) bool {
+	rule = strings.ToLower(strings.TrimPrefix(rule, "*."))
+	return host == rule || strings.HasSuffix(host, "."+rule)
+}
+
+type networkPolicyKey struct{}
+
+// WithNetworkPolicy returns a context that carries policy. Requests made with
+// the context through this package's HTTP clients are checked against it.
+func WithNetworkPolicy(ctx context.Context, policy *NetworkPolicy) context.Context {
+	return context.WithValue(ctx, networkPolicyKey{}, policy)
+}
+
+// NetworkPolicyFromContext returns the policy stored in ctx, or nil if there is none.
+func NetworkPolicyFromContext(ctx context.Context) *NetworkPolicy {
+	policy, _ := ctx.Value(networkPolicyKey{}).(*NetworkPolicy)
+	return policy
+}
+
+// DialExplicitlyAllowed opens a TCP connection to addr, over TLS if useTLS is
+// set, for detectors that verify with protocols other than HTTP. The host of
+// addr must be explicitly allowed by the network policy in ctx, and no
+// connection is made if ctx carries a DryRun. The connection times out after
+// 10 seconds.
+func DialExplicitlyAllowed(ctx context.Context, addr string, useTLS bool) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if !NetworkPolicyFromContext(ctx).AllowsExplicitly(host) {
+		return nil, fmt.Errorf("%w: %s", ErrHostNotPermitted, host)
+	}
+	if DryRunFromContext(ctx).interceptDial(addr, useTLS) {
+		return nil, ErrDryRun
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	if useTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
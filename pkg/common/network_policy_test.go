@@ -0,0 +1,154 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNetworkPolicyPermits(t *testing.T) {
+	type policyTest struct {
+		policy *NetworkPolicy
+		host   string
+		permit bool
+	}
+	tests := map[string]policyTest{
+		"NilPolicy": {
+			policy: nil,
+			host:   "api.github.com",
+			permit: true,
+		},
+		"Offline": {
+			policy: &NetworkPolicy{Offline: true},
+			host:   "api.github.com",
+			permit: false,
+		},
+		"EmptyAllowsAll": {
+			policy: &NetworkPolicy{},
+			host:   "api.github.com",
+			permit: true,
+		},
+		"AllowSubdomain": {
+			policy: &NetworkPolicy{Allow: []string{"github.com"}},
+			host:   "api.github.com",
+			permit: true,
+		},
+		"AllowWildcard": {
+			policy: &NetworkPolicy{Allow: []string{"*.github.com"}},
+			host:   "API.GitHub.com",
+			permit: true,
+		},
+		"AllowSuffixIsNotSubdomain": {
+			policy: &NetworkPolicy{Allow: []string{"github.com"}},
+			host:   "evilgithub.com",
+			permit: false,
+		},
+		"DenyTakesPrecedence": {
+			policy: &NetworkPolicy{Allow: []string{"slack.com"}, Deny: []string{"hooks.slack.com"}},
+			host:   "hooks.slack.com",
+			permit: false,
+		},
+		"DenyOnly": {
+			policy: &NetworkPolicy{Deny: []string{"slack.com"}},
+			host:   "api.github.com",
+			permit: true,
+		},
+	}
+
+	for name, test := range tests {
+		if got := test.policy.Permits(test.host); got != test.permit {
+			t.Errorf("%s: Permits(%q) = %t, want %t", name, test.host, got, test.permit)
+		}
+	}
+}
+
+func TestNetworkPolicyAllowsExplicitly(t *testing.T) {
+	type policyTest struct {
+		policy *NetworkPolicy
+		host   string
+		allow  bool
+	}
+	tests := map[string]policyTest{
+		"NilPolicy": {
+			policy: nil,
+			host:   "ldap.corp.example",
+			allow:  false,
+		},
+		"EmptyAllowList": {
+			policy: &NetworkPolicy{},
+			host:   "ldap.corp.example",
+			allow:  false,
+		},
+		"Allowed": {
+			policy: &NetworkPolicy{Allow: []string{"corp.example"}},
+			host:   "ldap.corp.example",
+			allow:  true,
+		},
+		"NotAllowed": {
+			policy: &NetworkPolicy{Allow: []string{"corp.example"}},
+			host:   "ldap.example",
+			allow:  false,
+		},
+		"Denied": {
+			policy: &NetworkPolicy{Allow: []string{"corp.example"}, Deny: []string{"ldap.corp.example"}},
+			host:   "ldap.corp.example",
+			allow:  false,
+		},
+		"Offline": {
+			policy: &NetworkPolicy{Offline: true, Allow: []string{"corp.example"}},
+			host:   "ldap.corp.example",
+			allow:  false,
+		},
+	}
+
+	for name, test := range tests {
+		if got := test.policy.AllowsExplicitly(test.host); got != test.allow {
+			t.Errorf("%s: AllowsExplicitly(%q) = %t, want %t", name, test.host, got, test.allow)
+		}
+	}
+}
+
+func TestCustomTransportEnforcesNetworkPolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := SaneHttpClient()
+
+	ctx := WithNetworkPolicy(context.Background(), &NetworkPolicy{Deny: []string{"127.0.0.1"}})
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Do(req); !errors.Is(err, ErrHostNotPermitted) {
+		t.Errorf("expected ErrHostNotPermitted, got %v", err)
+	}
+
+	req, err = http.NewRequestWithContext(context.Background(), "GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request without a policy failed: %v", err)
+	}
+	res.Body.Close()
+}
+
+func TestDialExplicitlyAllowed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	addr := server.Listener.Addr().String()
+
+	if _, err := DialExplicitlyAllowed(context.Background(), addr, false); !errors.Is(err, ErrHostNotPermitted) {
+		t.Errorf("expected ErrHostNotPermitted without a policy, got %v", err)
+	}
+
+	ctx := WithNetworkPolicy(context.Background(), &NetworkPolicy{Allow: []string{"127.0.0.1"}})
+	conn, err := DialExplicitlyAllowed(ctx, addr, false)
+	if err != nil {
+		t.Fatalf("dialing an allowed host failed: %v", err)
+	}
+	conn.Close()
+}
@@ -0,0 +1,70 @@
+package common
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+)
+
+// NewProxyURL returns the URL of the proxy c describes, with its credentials.
+// HTTP, HTTPS and SOCKS5 proxies are supported. It returns nil if c is nil or
+// has no URL.
+func NewProxyURL(c *credentialspb.Proxy) (*url.URL, error) {
+	if c == nil || c.Url == "" {
+		if c != nil && (c.Username != "" || c.Password != "") {
+			return nil, errors.New("proxy credentials were given without a proxy URL")
+		}
+		return nil, nil
+	}
+	u, err := url.Parse(c.Url)
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "invalid proxy URL", 0)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, errors.Errorf("proxy URL %s must be http://, https://, or socks5://", u.Redacted())
+	}
+	if u.Host == "" {
+		return nil, errors.Errorf("proxy URL %s has no host", u.Redacted())
+	}
+	if c.Username != "" || c.Password != "" {
+		u.User = url.UserPassword(c.Username, c.Password)
+	}
+	return u, nil
+}
+
+// WithProxy makes a client connect through the proxy at proxyURL, as returned
+// by NewProxyURL, instead of the one set in the environment. A nil URL leaves
+// the environment's.
+func WithProxy(proxyURL *url.URL) HttpClientOption {
+	return func(t *http.Transport) {
+		if proxyURL != nil {
+			t.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+}
+
+// NewHttpClientOptions returns the options for a client that connects with
+// tlsConfig through proxy, either of which may be nil.
+func NewHttpClientOptions(tlsConfig *credentialspb.TLSConfig, proxy *credentialspb.Proxy) ([]HttpClientOption, error) {
+	config, err := NewTLSConfig(tlsConfig)
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "invalid TLS configuration", 0)
+	}
+	proxyURL, err := NewProxyURL(proxy)
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "invalid proxy", 0)
+	}
+	var options []HttpClientOption
+	if config != nil {
+		options = append(options, WithTLSConfig(config))
+	}
+	if proxyURL != nil {
+		options = append(options, WithProxy(proxyURL))
+	}
+	return options, nil
+}
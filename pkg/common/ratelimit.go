@@ -0,0 +1,158 @@
+package common
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
+)
+
+const (
+	// maxRateLimitPause caps how long a request waits for a rate limit to
+	// reset. The primary limits of GitHub and GitLab reset at least hourly,
+	// so longer waits are left to the caller.
+	maxRateLimitPause = time.Hour + time.Minute
+	// defaultRateLimitPause is how long a request rejected for exceeding a
+	// limit waits when the response doesn't say when to retry. GitHub asks
+	// for at least a minute for its secondary limits.
+	defaultRateLimitPause = time.Minute
+	// maxRateLimitRetries limits how many times a request is retried.
+	maxRateLimitRetries = 10
+)
+
+// RateLimitTransport pauses the requests of a forge API client, such as
+// GitHub's or GitLab's, while the API's rate limit is exhausted, and retries
+// requests rejected for exceeding it, so that enumerating thousands of repos
+// completes rather than fails. Paginated listings resume from the page that
+// was rejected. Limits are read from GitHub's X-RateLimit-Remaining and
+// X-RateLimit-Reset headers, GitLab's RateLimit-Remaining and RateLimit-Reset
+// headers, and the Retry-After header sent with secondary limits.
+type RateLimitTransport struct {
+	T http.RoundTripper
+
+	mu sync.Mutex
+	// resume is when requests may be sent again.
+	resume time.Time
+}
+
+func NewRateLimitTransport(T http.RoundTripper) *RateLimitTransport {
+	if T == nil {
+		T = http.DefaultTransport
+	}
+	return &RateLimitTransport{T: T}
+}
+
+func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for retries := 0; ; retries++ {
+		if err := t.wait(req.Context()); err != nil {
+			return nil, err
+		}
+		res, err := t.T.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		resume, limited := rateLimitResume(res, now)
+		if resume.Sub(now) > maxRateLimitPause {
+			return res, nil
+		}
+		if !resume.IsZero() {
+			t.pauseUntil(req, resume)
+		}
+		if !limited || retries == maxRateLimitRetries {
+			return res, nil
+		}
+
+		// Requests with bodies can only be retried with a new copy of it.
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return res, nil
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return res, nil
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		_, _ = io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+	}
+}
+
+// wait blocks until requests may be sent, or ctx is done.
+func (t *RateLimitTransport) wait(ctx context.Context) error {
+	t.mu.Lock()
+	pause := time.Until(t.resume)
+	t.mu.Unlock()
+	if pause <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(pause)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// pauseUntil holds requests until resume, unless they're already held longer.
+func (t *RateLimitTransport) pauseUntil(req *http.Request, resume time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !resume.After(t.resume) {
+		return
+	}
+	t.resume = resume
+	log.FromContext(req.Context()).Info("rate limited, pausing requests",
+		"host", req.URL.Hostname(), "resume_time", resume.String())
+}
+
+// rateLimitResume returns when requests may be sent again after res, or the
+// zero time if they needn't wait, and whether res rejected its request for
+// exceeding a rate limit. A response that uses up the limit isn't itself
+// rejected, but the requests after it have to wait.
+func rateLimitResume(res *http.Response, now time.Time) (resume time.Time, limited bool) {
+	remaining := res.Header.Get("X-RateLimit-Remaining")
+	reset := res.Header.Get("X-RateLimit-Reset")
+	if remaining == "" {
+		remaining = res.Header.Get("RateLimit-Remaining")
+		reset = res.Header.Get("RateLimit-Reset")
+	}
+	retryAfter := res.Header.Get("Retry-After")
+
+	switch res.StatusCode {
+	case http.StatusTooManyRequests:
+		limited = true
+	case http.StatusForbidden:
+		// GitHub rejects requests over its limits as forbidden.
+		limited = remaining == "0" || retryAfter != ""
+	}
+
+	if retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return now.Add(time.Duration(seconds) * time.Second), limited
+		}
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			return date, limited
+		}
+	}
+	if remaining == "0" {
+		if epoch, err := strconv.ParseInt(reset, 10, 64); err == nil {
+			// A second is added for the skew between our clock and theirs.
+			return time.Unix(epoch+1, 0), limited
+		}
+	}
+	if limited {
+		return now.Add(defaultRateLimitPause), true
+	}
+	return time.Time{}, false
+}
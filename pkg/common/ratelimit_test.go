@@ -0,0 +1,119 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimitResume(t *testing.T) {
+	now := time.Unix(1660000000, 0)
+	reset := strconv.FormatInt(now.Unix()+60, 10)
+	tests := map[string]struct {
+		status  int
+		header  map[string]string
+		resume  time.Time
+		limited bool
+	}{
+		"NotLimited": {
+			status: http.StatusOK,
+			header: map[string]string{"X-RateLimit-Remaining": "10", "X-RateLimit-Reset": reset},
+		},
+		"GitHubLimitUsedUp": {
+			status: http.StatusOK,
+			header: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset},
+			resume: now.Add(61 * time.Second),
+		},
+		"GitHubPrimary": {
+			status:  http.StatusForbidden,
+			header:  map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset},
+			resume:  now.Add(61 * time.Second),
+			limited: true,
+		},
+		"GitHubSecondary": {
+			status:  http.StatusForbidden,
+			header:  map[string]string{"X-RateLimit-Remaining": "4000", "Retry-After": "30"},
+			resume:  now.Add(30 * time.Second),
+			limited: true,
+		},
+		"GitHubSecondaryWithoutRetryAfter": {
+			status: http.StatusForbidden,
+			header: map[string]string{"X-RateLimit-Remaining": "4000"},
+		},
+		"GitLab": {
+			status:  http.StatusTooManyRequests,
+			header:  map[string]string{"RateLimit-Remaining": "0", "RateLimit-Reset": reset},
+			resume:  now.Add(61 * time.Second),
+			limited: true,
+		},
+		"RetryAfterDate": {
+			status:  http.StatusTooManyRequests,
+			header:  map[string]string{"Retry-After": now.Add(time.Minute).UTC().Format(http.TimeFormat)},
+			resume:  now.Add(time.Minute),
+			limited: true,
+		},
+		"TooManyRequestsWithoutHeaders": {
+			status:  http.StatusTooManyRequests,
+			resume:  now.Add(defaultRateLimitPause),
+			limited: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			res := &http.Response{StatusCode: test.status, Header: http.Header{}}
+			for k, v := range test.header {
+				res.Header.Set(k, v)
+			}
+			resume, limited := rateLimitResume(res, now)
+			if !resume.Equal(test.resume) || limited != test.limited {
+				t.Errorf("rateLimitResume() = %v, %v; want %v, %v", resume, limited, test.resume, test.limited)
+			}
+		})
+	}
+}
+
+func TestRateLimitTransportRetries(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		body := make([]byte, r.ContentLength)
+		_, _ = r.Body.Read(body)
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewRateLimitTransport(nil)}
+	res, err := client.Post(server.URL, "text/plain", strings.NewReader("page=2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK || requests != 2 {
+		t.Errorf("got status %d after %d requests, want 200 after 2", res.StatusCode, requests)
+	}
+}
+
+func TestRateLimitTransportWaitCanceled(t *testing.T) {
+	transport := NewRateLimitTransport(nil)
+	transport.resume = time.Now().Add(time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := transport.RoundTrip(req); !errors.Is(err, context.Canceled) {
+		t.Errorf("RoundTrip() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,120 @@
+package common
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"os"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// NewTLSConfig returns the client TLS configuration c describes. CAs are
+// trusted in addition to the system's. It returns nil, Go's defaults, if c is
+// nil or empty.
+func NewTLSConfig(c *credentialspb.TLSConfig) (*tls.Config, error) {
+	if c == nil || (c.CaPath == "" && c.CaPem == "" && c.ClientCertPath == "" && c.ClientCertPem == "" &&
+		c.ClientKeyPath == "" && c.ClientKeyPem == "" && c.MinVersion == "" && !c.InsecureSkipVerify) {
+		return nil, nil
+	}
+	config := &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify}
+
+	if c.MinVersion != "" {
+		version, ok := tlsVersions[c.MinVersion]
+		if !ok {
+			return nil, errors.Errorf("unknown TLS version %q, it must be one of 1.0, 1.1, 1.2 or 1.3", c.MinVersion)
+		}
+		config.MinVersion = version
+	}
+
+	if c.CaPath != "" || c.CaPem != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if c.CaPath != "" {
+			pem, err := os.ReadFile(c.CaPath)
+			if err != nil {
+				return nil, errors.WrapPrefix(err, "could not read CA bundle", 0)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, errors.Errorf("no certificates found in CA bundle %s", c.CaPath)
+			}
+		}
+		if c.CaPem != "" && !pool.AppendCertsFromPEM([]byte(c.CaPem)) {
+			return nil, errors.New("no certificates found in CA PEM")
+		}
+		config.RootCAs = pool
+	}
+
+	certPEM, err := pemFrom(c.ClientCertPem, c.ClientCertPath, "client certificate")
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := pemFrom(c.ClientKeyPem, c.ClientKeyPath, "client key")
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case certPEM != nil && keyPEM != nil:
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, errors.WrapPrefix(err, "could not load client certificate", 0)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	case certPEM != nil || keyPEM != nil:
+		return nil, errors.New("a client certificate and key must be given together")
+	}
+	return config, nil
+}
+
+// pemFrom returns inline PEM if it's set, or else the contents of path.
+func pemFrom(inline, path, what string) ([]byte, error) {
+	if inline != "" {
+		return []byte(inline), nil
+	}
+	if path == "" {
+		return nil, nil
+	}
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not read "+what, 0)
+	}
+	return pem, nil
+}
+
+// HttpClientOption configures the clients returned by SaneHttpClient,
+// SaneHttpClientTimeOut, and RateLimitedHttpClient.
+type HttpClientOption func(*http.Transport)
+
+// WithTLSConfig makes a client use config, as returned by NewTLSConfig. A nil
+// config leaves Go's defaults.
+func WithTLSConfig(config *tls.Config) HttpClientOption {
+	return func(t *http.Transport) {
+		if config != nil {
+			t.TLSClientConfig = config
+		}
+	}
+}
+
+// transportWith returns saneTransport, or a copy of it configured with
+// options. The copy pools its connections separately.
+func transportWith(options []HttpClientOption) *http.Transport {
+	if len(options) == 0 {
+		return saneTransport
+	}
+	t := saneTransport.Clone()
+	for _, option := range options {
+		option(t)
+	}
+	return t
+}
@@ -0,0 +1,146 @@
+package common
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+)
+
+// newClientCert returns a self-signed client certificate and its key, as PEM.
+func newClientCert(t *testing.T) (certPEM, keyPEM []byte, cert *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "scanner"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert, err = x509.ParseCertificate(der); err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, cert
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	certPEM, keyPEM, _ := newClientCert(t)
+	tests := []struct {
+		name    string
+		config  *credentialspb.TLSConfig
+		wantNil bool
+		wantErr string
+	}{
+		{name: "nil", wantNil: true},
+		{name: "empty", config: &credentialspb.TLSConfig{}, wantNil: true},
+		{name: "min version", config: &credentialspb.TLSConfig{MinVersion: "1.3"}},
+		{name: "unknown min version", config: &credentialspb.TLSConfig{MinVersion: "1.4"}, wantErr: "unknown TLS version"},
+		{name: "missing CA bundle", config: &credentialspb.TLSConfig{CaPath: filepath.Join(t.TempDir(), "ca.pem")}, wantErr: "could not read CA bundle"},
+		{name: "CA bundle without certificates", config: &credentialspb.TLSConfig{CaPath: writeFile(t, "ca.pem", []byte("not a certificate"))}, wantErr: "no certificates found"},
+		{name: "CA PEM without certificates", config: &credentialspb.TLSConfig{CaPem: "not a certificate"}, wantErr: "no certificates found"},
+		{name: "client certificate without key", config: &credentialspb.TLSConfig{ClientCertPem: string(certPEM)}, wantErr: "must be given together"},
+		{name: "mismatched client key", config: &credentialspb.TLSConfig{ClientCertPem: string(certPEM), ClientKeyPem: string(certPEM)}, wantErr: "could not load client certificate"},
+		{name: "client certificate files", config: &credentialspb.TLSConfig{ClientCertPath: writeFile(t, "cert.pem", certPEM), ClientKeyPath: writeFile(t, "key.pem", keyPEM)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewTLSConfig(tt.config)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NewTLSConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewTLSConfig() error = %v", err)
+			}
+			if (got == nil) != tt.wantNil {
+				t.Errorf("NewTLSConfig() = %v, want nil %t", got, tt.wantNil)
+			}
+		})
+	}
+}
+
+func TestWithTLSConfig(t *testing.T) {
+	certPEM, keyPEM, cert := newClientCert(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(cert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+		MaxVersion: tls.VersionTLS12,
+	}
+	server.StartTLS()
+	defer server.Close()
+	caPath := writeFile(t, "ca.pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	tests := []struct {
+		name    string
+		config  *credentialspb.TLSConfig
+		wantErr bool
+	}{
+		{name: "default", wantErr: true},
+		{name: "CA without client certificate", config: &credentialspb.TLSConfig{CaPath: caPath}, wantErr: true},
+		{name: "CA and client certificate", config: &credentialspb.TLSConfig{CaPath: caPath, ClientCertPem: string(certPEM), ClientKeyPem: string(keyPEM)}},
+		{name: "insecure with client certificate", config: &credentialspb.TLSConfig{InsecureSkipVerify: true, ClientCertPem: string(certPEM), ClientKeyPem: string(keyPEM)}},
+		{name: "min version above the server's", config: &credentialspb.TLSConfig{CaPath: caPath, ClientCertPem: string(certPEM), ClientKeyPem: string(keyPEM), MinVersion: "1.3"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := NewTLSConfig(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := SaneHttpClient(WithTLSConfig(tlsConfig)).Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Get() error = %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
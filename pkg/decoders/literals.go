@@ -0,0 +1,299 @@
+package decoders
+
+import (
+	"bytes"
+	"path"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// LiteralDecoders returns the default decoders limited to the string literals
+// of source files, for scanning code without matching identifiers, hashes and
+// other tokens outside of strings.
+func LiteralDecoders() []Decoder {
+	var limited []Decoder
+	for _, d := range DefaultDecoders() {
+		limited = append(limited, &Literals{Decoder: d})
+	}
+	return limited
+}
+
+// Ensure the Decoder satisfies the interface at compile time
+var _ Decoder = (*Literals)(nil)
+
+// Literals limits a decoder to the string literals of Go, JavaScript,
+// TypeScript, Python, Java, and YAML files, which are recognized by their
+// extensions. Everything outside of literals is blanked out, keeping line
+// breaks so findings keep their line numbers. Chunks of other files are
+// decoded in full.
+type Literals struct {
+	Decoder Decoder
+}
+
+func (d *Literals) FromChunk(chunk *sources.Chunk) *sources.Chunk {
+	lang := languageOf(sources.MetadataFile(chunk.SourceMetadata))
+	if lang == nil {
+		return d.Decoder.FromChunk(chunk)
+	}
+	literals := *chunk
+	literals.Data = lang.literals(chunk.Data)
+	return d.Decoder.FromChunk(&literals)
+}
+
+// delimiter is how a kind of string literal starts and ends.
+type delimiter struct {
+	open, close string
+	// multiline literals can span lines, and others end at the end of the
+	// line if they aren't closed.
+	multiline bool
+	// raw literals have no escape sequences.
+	raw bool
+}
+
+// language is the lexical structure of a language needed to find its string
+// literals.
+type language struct {
+	lineComments  []string
+	blockComments [][2]string
+	// strings are tried in order, so longer delimiters come first.
+	strings []delimiter
+	// yaml languages find literals with yamlLiterals instead.
+	yaml bool
+}
+
+var (
+	goLanguage = &language{
+		lineComments:  []string{"//"},
+		blockComments: [][2]string{{"/*", "*/"}},
+		strings: []delimiter{
+			{open: "`", close: "`", multiline: true, raw: true},
+			{open: `"`, close: `"`},
+			{open: "'", close: "'"},
+		},
+	}
+	jsLanguage = &language{
+		lineComments:  []string{"//"},
+		blockComments: [][2]string{{"/*", "*/"}},
+		strings: []delimiter{
+			{open: "`", close: "`", multiline: true},
+			{open: `"`, close: `"`},
+			{open: "'", close: "'"},
+		},
+	}
+	pythonLanguage = &language{
+		lineComments: []string{"#"},
+		strings: []delimiter{
+			{open: `"""`, close: `"""`, multiline: true},
+			{open: "'''", close: "'''", multiline: true},
+			{open: `"`, close: `"`},
+			{open: "'", close: "'"},
+		},
+	}
+	javaLanguage = &language{
+		lineComments:  []string{"//"},
+		blockComments: [][2]string{{"/*", "*/"}},
+		strings: []delimiter{
+			{open: `"""`, close: `"""`, multiline: true},
+			{open: `"`, close: `"`},
+			{open: "'", close: "'"},
+		},
+	}
+	yamlLanguage = &language{yaml: true}
+)
+
+var languages = map[string]*language{
+	".go":   goLanguage,
+	".js":   jsLanguage,
+	".jsx":  jsLanguage,
+	".mjs":  jsLanguage,
+	".cjs":  jsLanguage,
+	".ts":   jsLanguage,
+	".tsx":  jsLanguage,
+	".py":   pythonLanguage,
+	".java": javaLanguage,
+	".yaml": yamlLanguage,
+	".yml":  yamlLanguage,
+}
+
+// languageOf returns the language of file from its extension, or nil if it
+// isn't one whose literals can be found.
+func languageOf(file string) *language {
+	return languages[strings.ToLower(path.Ext(file))]
+}
+
+// literals returns data with everything outside of string literals replaced
+// with spaces, except for line breaks.
+func (l *language) literals(data []byte) []byte {
+	if l.yaml {
+		return yamlLiterals(data)
+	}
+	out := blank(data)
+	for i := 0; i < len(data); {
+		if end, ok := l.comment(data, i); ok {
+			i = end
+			continue
+		}
+		d, ok := l.stringAt(data, i)
+		if !ok {
+			i++
+			continue
+		}
+		i += len(d.open)
+		for i < len(data) {
+			if data[i] == '\n' && !d.multiline {
+				break
+			}
+			if bytes.HasPrefix(data[i:], []byte(d.close)) {
+				i += len(d.close)
+				break
+			}
+			if data[i] == '\\' && !d.raw && i+1 < len(data) && data[i+1] != '\n' {
+				out[i], out[i+1] = data[i], data[i+1]
+				i += 2
+				continue
+			}
+			out[i] = data[i]
+			i++
+		}
+	}
+	return out
+}
+
+// comment returns the end of the comment at data[i:], if there is one.
+func (l *language) comment(data []byte, i int) (int, bool) {
+	for _, c := range l.lineComments {
+		if bytes.HasPrefix(data[i:], []byte(c)) {
+			if end := bytes.IndexByte(data[i:], '\n'); end >= 0 {
+				return i + end, true
+			}
+			return len(data), true
+		}
+	}
+	for _, c := range l.blockComments {
+		if bytes.HasPrefix(data[i:], []byte(c[0])) {
+			if end := bytes.Index(data[i+len(c[0]):], []byte(c[1])); end >= 0 {
+				return i + len(c[0]) + end + len(c[1]), true
+			}
+			return len(data), true
+		}
+	}
+	return 0, false
+}
+
+func (l *language) stringAt(data []byte, i int) (delimiter, bool) {
+	for _, d := range l.strings {
+		if bytes.HasPrefix(data[i:], []byte(d.open)) {
+			return d, true
+		}
+	}
+	return delimiter{}, false
+}
+
+// yamlLiterals returns data with everything but scalar values replaced with
+// spaces, except for line breaks. Mapping keys and comments are blanked, and
+// block scalars are kept whole.
+func yamlLiterals(data []byte) []byte {
+	out := blank(data)
+	blockIndent := -1
+	offset := 0
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		start := offset
+		offset += len(line)
+		content := bytes.TrimRight(line, "\r\n")
+		trimmed := bytes.TrimLeft(content, " ")
+		indent := len(content) - len(trimmed)
+
+		if blockIndent >= 0 {
+			if len(trimmed) == 0 || indent > blockIndent {
+				copy(out[start:], content)
+				continue
+			}
+			blockIndent = -1
+		}
+		if len(trimmed) == 0 || trimmed[0] == '#' {
+			continue
+		}
+
+		value := indent
+		for bytes.HasPrefix(content[value:], []byte("- ")) {
+			value += 2
+			for value < len(content) && content[value] == ' ' {
+				value++
+			}
+		}
+		if colon := yamlKeyEnd(content[value:]); colon >= 0 {
+			value += colon + 1
+		}
+		scalar := bytes.TrimSpace(stripYAMLComment(content[value:]))
+		if len(scalar) == 0 {
+			continue
+		}
+		if scalar[0] == '|' || scalar[0] == '>' {
+			blockIndent = indent
+			continue
+		}
+		if n := len(scalar); n >= 2 && (scalar[0] == '"' || scalar[0] == '\'') && scalar[n-1] == scalar[0] {
+			scalar = scalar[1 : n-1]
+		}
+		at := value + bytes.Index(content[value:], scalar)
+		copy(out[start+at:], scalar)
+	}
+	return out
+}
+
+// yamlKeyEnd returns the index of the colon ending the mapping key at the
+// start of line, or -1 if the line doesn't start with one.
+func yamlKeyEnd(line []byte) int {
+	if len(line) > 0 && (line[0] == '"' || line[0] == '\'') {
+		end := bytes.IndexByte(line[1:], line[0])
+		if end < 0 {
+			return -1
+		}
+		if rest := line[end+2:]; len(rest) > 0 && rest[0] == ':' {
+			return end + 2
+		}
+		return -1
+	}
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"', '\'', '#', '{', '[':
+			return -1
+		case ':':
+			if i+1 == len(line) || line[i+1] == ' ' {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// stripYAMLComment removes a comment from the end of a value. A # in a
+// quoted value isn't a comment.
+func stripYAMLComment(value []byte) []byte {
+	trimmed := bytes.TrimLeft(value, " ")
+	if len(trimmed) > 0 && (trimmed[0] == '"' || trimmed[0] == '\'') {
+		if end := bytes.IndexByte(trimmed[1:], trimmed[0]); end >= 0 {
+			return trimmed[:end+2]
+		}
+		return value
+	}
+	if i := bytes.Index(value, []byte(" #")); i >= 0 {
+		return value[:i]
+	}
+	return value
+}
+
+// blank returns a copy of data with everything but line breaks replaced with
+// spaces.
+func blank(data []byte) []byte {
+	out := make([]byte, len(data))
+	for i, c := range data {
+		if c == '\n' || c == '\r' {
+			out[i] = c
+		} else {
+			out[i] = ' '
+		}
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package decoders
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestLanguage_literals(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		data string
+		want string
+	}{
+		{
+			name: "go",
+			file: "main.go",
+			data: "key := \"AKIA\\\"X\" // \"comment\"\nsha := `raw\nlines`",
+			want: "        AKIA\\\"X              \n        raw\nlines ",
+		},
+		{
+			name: "javascript",
+			file: "app.ts",
+			data: "const url = `https://${host}/` /* 'c' */ + 'x';",
+			want: "             https://${host}/               x  ",
+		},
+		{
+			name: "python",
+			file: "settings.py",
+			data: "TOKEN = r'abc' # 'not'\nDOC = \"\"\"multi\n'line'\"\"\"",
+			want: "          abc         \n         multi\n'line'   ",
+		},
+		{
+			name: "unterminated string ends at the line",
+			file: "Main.java",
+			data: "String s = \"open\nint hash = 0xdeadbeef;",
+			want: "            open\n                      ",
+		},
+		{
+			name: "yaml",
+			file: "config.yml",
+			data: "db:\n  password: \"hunter2\" # comment\n  - token: abc\nkey: |\n  -----BEGIN-----\nnext: 1",
+			want: "   \n             hunter2           \n           abc\n      \n  -----BEGIN-----\n      1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(languageOf(tt.file).literals([]byte(tt.data)))
+			if got != tt.want {
+				t.Errorf("literals() = %q, want %q", got, tt.want)
+			}
+			if strings.Count(got, "\n") != strings.Count(tt.data, "\n") {
+				t.Errorf("literals() changed the number of lines")
+			}
+		})
+	}
+}
+
+func TestLiterals_FromChunk(t *testing.T) {
+	d := &Literals{Decoder: &Plain{}}
+	chunk := func(file string) *sources.Chunk {
+		return &sources.Chunk{
+			SourceMetadata: &source_metadatapb.MetaData{
+				Data: &source_metadatapb.MetaData_Filesystem{Filesystem: &source_metadatapb.Filesystem{File: file}},
+			},
+			Data: []byte(`hash = deadbeef; key = "secret"`),
+		}
+	}
+	if got, want := string(d.FromChunk(chunk("app.js")).Data), `                        secret `; got != want {
+		t.Errorf("FromChunk(app.js) = %q, want %q", got, want)
+	}
+	if got, want := string(d.FromChunk(chunk("notes.txt")).Data), `hash = deadbeef; key = "secret"`; got != want {
+		t.Errorf("FromChunk(notes.txt) = %q, want %q", got, want)
+	}
+}
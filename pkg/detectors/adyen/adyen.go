@@ -0,0 +1,119 @@
+package adyen
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+type Scanner struct {
+	// endpoints overrides the Management API address of each environment in
+	// tests.
+	endpoints map[string]string
+}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+var (
+	client = common.SaneHttpClient()
+
+	// API keys of web service users are three dash separated parts, the last
+	// of which may contain any printable character.
+	keyPat = regexp.MustCompile(`\b(AQE[0-9A-Za-z+/=]{40,}-[0-9A-Za-z+/=]{20,}-[^\s"'\x60]{8,})`)
+
+	// API keys are issued for one environment, so both are tried. Live keys
+	// can move real money.
+	environments = []struct {
+		name     string
+		endpoint string
+		severity string
+	}{
+		{name: "test", endpoint: "https://management-test.adyen.com", severity: "low"},
+		{name: "live", endpoint: "https://management-live.adyen.com", severity: "critical"},
+	}
+)
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"AQE"}
+}
+
+// FromData will find and optionally verify Adyen secrets in a given set of bytes.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	for _, match := range keyPat.FindAllStringSubmatch(dataStr, -1) {
+		resMatch := strings.TrimSpace(match[1])
+
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_Adyen,
+			Raw:          []byte(resMatch),
+		}
+
+		if verify {
+			for _, env := range environments {
+				endpoint := env.endpoint
+				if override, ok := s.endpoints[env.name]; ok {
+					endpoint = override
+				}
+				user, verified, err := verifyKey(ctx, endpoint, resMatch)
+				if err != nil || !verified {
+					continue
+				}
+				s1.Verified = true
+				s1.ExtraData = map[string]string{
+					"environment": env.name,
+					"severity":    env.severity,
+					"username":    user.Username,
+					"company":     user.CompanyName,
+				}
+				break
+			}
+		}
+
+		if !s1.Verified && detectors.IsKnownFalsePositive(resMatch, detectors.DefaultFalsePositives, false) {
+			continue
+		}
+
+		results = append(results, s1)
+	}
+
+	return results, nil
+}
+
+type apiCredential struct {
+	Username    string `json:"username"`
+	CompanyName string `json:"companyName"`
+}
+
+// verifyKey reads the API credential the key belongs to, which every key may
+// do regardless of its roles.
+func verifyKey(ctx context.Context, endpoint, key string) (*apiCredential, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(endpoint, "/")+"/v3/me", nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Add("X-API-Key", key)
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, false, nil
+	}
+
+	var cred apiCredential
+	if err := json.NewDecoder(res.Body).Decode(&cred); err != nil {
+		return nil, false, err
+	}
+	return &cred, true, nil
+}
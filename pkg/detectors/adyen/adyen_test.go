@@ -0,0 +1,155 @@
+package adyen
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+const (
+	testKey     = "AQEC3J27XDCG2LmlZGEONYlgCtjfIZ4SOcMz9CPVNPkNa1Hedcm4pMbXDuCL1mH-oOsFaQfDPrAJ71fTquWoGsbeKXgzg2sye9b2Rann76d=-8WY1jzIRlNQb0prF"
+	liveKey     = "AQETgadDZFlRJmCGmUXiAPyhzAnar3ZLt4bnlz2MPKgcjnCqaXNv1syeefnLOpa-MxxNDi9LE1Ki3ylOjt6o0NpUmkVO8JmR8y4EMfAdggc=-)GEYG1<L!wiqD9jJ"
+	inactiveKey = "AQEn66nVberACpdclsxHKifxi5CvQUSHL8iLc7bE6wSt9cbMOeEeUtuieeCIxVc-57VVTiY96vwfRE5e32A8Yb3FKaNQyyLaMeffOhq4AUv=-X647k!)dNl9cDo><"
+)
+
+func TestAdyen_FromChunk(t *testing.T) {
+	newServer := func(key, username string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v3/me" || r.Header.Get("X-API-Key") != key {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, `{"id":"S2-6A6C2E3F","username":"%s","companyName":"Acme","roles":["Management API - Accounts read"]}`, username)
+		}))
+	}
+	testServer := newServer(testKey, "ws_123456@Company.Acme")
+	defer testServer.Close()
+	liveServer := newServer(liveKey, "ws_654321@Company.Acme")
+	defer liveServer.Close()
+	endpoints := map[string]string{"test": testServer.URL, "live": liveServer.URL}
+
+	type args struct {
+		ctx    context.Context
+		data   []byte
+		verify bool
+	}
+	tests := []struct {
+		name    string
+		s       Scanner
+		args    args
+		want    []detectors.Result
+		wantErr bool
+	}{
+		{
+			name: "found, verified test key",
+			s:    Scanner{endpoints: endpoints},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte(fmt.Sprintf("ADYEN_API_KEY=%s\nADYEN_MERCHANT_ACCOUNT=AcmeECOM", testKey)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_Adyen,
+					Verified:     true,
+					ExtraData: map[string]string{
+						"environment": "test",
+						"severity":    "low",
+						"username":    "ws_123456@Company.Acme",
+						"company":     "Acme",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "found, verified live key",
+			s:    Scanner{endpoints: endpoints},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte(fmt.Sprintf(`{"adyen": {"apiKey": "%s"}}`, liveKey)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_Adyen,
+					Verified:     true,
+					ExtraData: map[string]string{
+						"environment": "live",
+						"severity":    "critical",
+						"username":    "ws_654321@Company.Acme",
+						"company":     "Acme",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "found, unverified",
+			s:    Scanner{endpoints: endpoints},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte(fmt.Sprintf("ADYEN_API_KEY=%s\n", inactiveKey)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_Adyen,
+					Verified:     false,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "not found",
+			s:    Scanner{endpoints: endpoints},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte("You cannot find the secret within"),
+				verify: true,
+			},
+			want:    nil,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.s.FromData(tt.args.ctx, tt.args.verify, tt.args.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Adyen.FromData() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			for i := range got {
+				if len(got[i].Raw) == 0 {
+					t.Fatalf("no raw secret present: \n %+v", got[i])
+				}
+				got[i].Raw = nil
+			}
+			if diff := pretty.Compare(got, tt.want); diff != "" {
+				t.Errorf("Adyen.FromData() %s diff: (-got +want)\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func BenchmarkFromData(benchmark *testing.B) {
+	ctx := context.Background()
+	s := Scanner{}
+	for name, data := range detectors.MustGetBenchmarkData() {
+		benchmark.Run(name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				_, err := s.FromData(ctx, false, data)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
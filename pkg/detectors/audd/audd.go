@@ -31,6 +31,11 @@ func (s Scanner) Keywords() []string {
 	return []string{"audd"}
 }
 
+// SafeVerification returns false because verifying registers a callback URL on the account.
+func (s Scanner) SafeVerification() bool {
+	return false
+}
+
 // FromData will find and optionally verify Audd secrets in a given set of bytes.
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
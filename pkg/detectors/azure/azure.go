@@ -2,12 +2,16 @@ package azure
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
 	"regexp"
+	"sort"
 	"strings"
 
-	"github.com/Azure/go-autorest/autorest/azure/auth"
-
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 )
@@ -23,6 +27,11 @@ func mustFmtPat(id, pat string) *regexp.Regexp {
 }
 
 var (
+	client = common.SaneHttpClient()
+
+	// tokenURL is a variable so tests can point verification at a local server.
+	tokenURL = "https://login.microsoftonline.com/%s/oauth2/v2.0/token"
+
 	// TODO: Azure storage access keys and investigate other types of creds.
 
 	// Azure App Oauth
@@ -35,6 +44,24 @@ var (
 	clientSecretPat = mustFmtPat("client_secret", secretPatFmt)
 )
 
+// maxTripletDistance is the furthest apart, in bytes, a tenant ID or client ID
+// may be from a client secret and still be considered part of the same credential.
+const maxTripletDistance = 1024
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+}
+
+type accessTokenClaims struct {
+	Roles []string `json:"roles"`
+	Scope string   `json:"scp"`
+}
+
+type match struct {
+	value string
+	pos   int
+}
+
 // Keywords are used for efficiently pre-filtering chunks.
 // Use identifiers in the secret preferably, or the provider name.
 func (s Scanner) Keywords() []string {
@@ -45,27 +72,27 @@ func (s Scanner) Keywords() []string {
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
 
-	clientSecretMatches := clientSecretPat.FindAllStringSubmatch(dataStr, -1)
+	clientSecretMatches := findMatches(clientSecretPat, dataStr)
+	tenantIDMatches := findMatches(tenantIDPat, dataStr)
+	clientIDMatches := findMatches(clientIDPat, dataStr)
+
 	for _, clientSecret := range clientSecretMatches {
-		tenantIDMatches := tenantIDPat.FindAllStringSubmatch(dataStr, -1)
-		for _, tenantID := range tenantIDMatches {
-			clientIDMatches := clientIDPat.FindAllStringSubmatch(dataStr, -1)
-			for _, clientID := range clientIDMatches {
+		for _, tenantID := range nearby(tenantIDMatches, clientSecret.pos) {
+			for _, clientID := range nearby(clientIDMatches, clientSecret.pos) {
 				s := detectors.Result{
 					DetectorType: detectorspb.DetectorType_Azure,
-					Raw:          []byte(clientSecret[2]),
-					Redacted:     clientID[2],
+					Raw:          []byte(clientSecret.value),
+					Redacted:     clientID.value,
 				}
 
 				if verify {
-					cred := auth.NewClientCredentialsConfig(clientID[2], clientSecret[2], tenantID[2])
-					token, err := cred.ServicePrincipalToken()
-					if err != nil {
-						continue
-					}
-					err = token.Refresh()
-					if err == nil {
+					scopes, verified, err := verifyClientCredentials(ctx, tenantID.value, clientID.value, clientSecret.value)
+					if err == nil && verified {
 						s.Verified = true
+						s.ExtraData = map[string]string{
+							"tenant": tenantID.value,
+							"scopes": strings.Join(scopes, ","),
+						}
 					}
 				}
 
@@ -85,3 +112,80 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 
 	return detectors.CleanResults(results), nil
 }
+
+func findMatches(pat *regexp.Regexp, data string) []match {
+	var matches []match
+	for _, idx := range pat.FindAllStringSubmatchIndex(data, -1) {
+		matches = append(matches, match{value: data[idx[4]:idx[5]], pos: idx[0]})
+	}
+	return matches
+}
+
+// nearby returns the matches within maxTripletDistance of pos.
+func nearby(matches []match, pos int) []match {
+	var found []match
+	for _, m := range matches {
+		distance := m.pos - pos
+		if distance < 0 {
+			distance = -distance
+		}
+		if distance <= maxTripletDistance {
+			found = append(found, m)
+		}
+	}
+	return found
+}
+
+// verifyClientCredentials requests a Microsoft Graph token using the OAuth2
+// client-credentials flow and returns the application roles it was granted.
+func verifyClientCredentials(ctx context.Context, tenantID, clientID, clientSecret string) ([]string, bool, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", clientID)
+	form.Set("client_secret", clientSecret)
+	form.Set("scope", "https://graph.microsoft.com/.default")
+
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf(tokenURL, tenantID), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, false, nil
+	}
+
+	var token tokenResponse
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return nil, false, err
+	}
+	return grantedScopes(token.AccessToken), true, nil
+}
+
+// grantedScopes reads the roles and delegated scopes out of an access token
+// without validating its signature, since it came directly from the issuer.
+func grantedScopes(accessToken string) []string {
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil
+	}
+	var claims accessTokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil
+	}
+	scopes := append([]string{}, claims.Roles...)
+	if claims.Scope != "" {
+		scopes = append(scopes, strings.Fields(claims.Scope)...)
+	}
+	sort.Strings(scopes)
+	return scopes
+}
@@ -2,7 +2,11 @@ package azure
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -54,6 +58,7 @@ func TestAzure_FromChunk(t *testing.T) {
 					DetectorType: detectorspb.DetectorType_Azure,
 					Redacted:     id,
 					Verified:     true,
+					ExtraData:    map[string]string{"tenant": tenantId},
 				},
 			},
 			wantErr: false,
@@ -101,6 +106,8 @@ func TestAzure_FromChunk(t *testing.T) {
 			}
 			for i := range got {
 				got[i].Raw = nil
+				// Granted scopes depend on the test app registration.
+				delete(got[i].ExtraData, "scopes")
 			}
 			if diff := pretty.Compare(got, tt.want); diff != "" {
 				t.Errorf("Azure.FromData() %s diff: (-got +want)\n%s", tt.name, diff)
@@ -108,3 +115,63 @@ func TestAzure_FromChunk(t *testing.T) {
 		})
 	}
 }
+
+func TestAzure_VerifyReportsScopes(t *testing.T) {
+	const (
+		tenantID = "72f988bf-86f1-41af-91ab-2d7cd011db47"
+		clientID = "3d8c1a7e-5b2f-4e90-a6d4-9f1c2b3e4d5a"
+		secret   = "Qx7~Wp2.Lk9_Rt4-Vb8nMc3Jh6Fd1Sg5Za"
+	)
+	claims := base64.RawURLEncoding.EncodeToString([]byte(`{"roles":["User.Read.All","Mail.Send"]}`))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/"+tenantID+"/oauth2/v2.0/token" || r.FormValue("client_secret") != secret {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"access_token":"e30.%s.sig"}`, claims)
+	}))
+	defer server.Close()
+
+	defaultTokenURL := tokenURL
+	tokenURL = server.URL + "/%s/oauth2/v2.0/token"
+	defer func() { tokenURL = defaultTokenURL }()
+
+	data := fmt.Sprintf("azure\ntenant_id=%s\nclient_id=%s\nclient_secret=%s\n", tenantID, clientID, secret)
+	got, err := Scanner{}.FromData(context.Background(), true, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range got {
+		got[i].Raw = nil
+	}
+	want := []detectors.Result{
+		{
+			DetectorType: detectorspb.DetectorType_Azure,
+			Redacted:     clientID,
+			Verified:     true,
+			ExtraData: map[string]string{
+				"tenant": tenantID,
+				"scopes": "Mail.Send,User.Read.All",
+			},
+		},
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("Azure.FromData() diff: (-got +want)\n%s", diff)
+	}
+}
+
+func TestAzure_DistantIDsNotPaired(t *testing.T) {
+	data := fmt.Sprintf("azure\ntenant_id=%s\nclient_id=%s\n%s\nclient_secret=%s\n",
+		"72f988bf-86f1-41af-91ab-2d7cd011db47",
+		"3d8c1a7e-5b2f-4e90-a6d4-9f1c2b3e4d5a",
+		strings.Repeat("filler ", maxTripletDistance),
+		"Qx7~Wp2.Lk9_Rt4-Vb8nMc3Jh6Fd1Sg5Za",
+	)
+	got, err := Scanner{}.FromData(context.Background(), false, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected no results for distant IDs, got %d", len(got))
+	}
+}
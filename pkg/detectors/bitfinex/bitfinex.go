@@ -69,6 +69,18 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 				// thankfully official golang examples exist but you just need to dig their many repos https://github.com/bitfinexcom/bitfinex-api-go/blob/master/examples/v2/rest-orders/main.go
 				key := apiKeyRes
 				secret := apiSecretRes
+				// The SDK's requests don't carry ctx, so a verification dry
+				// run has to intercept them here.
+				req, err := http.NewRequestWithContext(ctx, http.MethodPost, *api+"auth/r/orders/hist", nil)
+				if err != nil {
+					continue
+				}
+				req.Header.Set("bfx-apikey", key)
+				req.Header.Set("bfx-nonce", "")
+				req.Header.Set("bfx-signature", "")
+				if common.DryRunFromContext(ctx).Intercept(req) {
+					continue
+				}
 				http.DefaultClient = client // filed https://github.com/bitfinexcom/bitfinex-api-go/issues/238 to improve this
 				c := rest.NewClientWithURL(*api).Credentials(key, secret)
 
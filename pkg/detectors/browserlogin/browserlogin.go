@@ -0,0 +1,73 @@
+package browserlogin
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Scanner reports the logins saved in browser profiles. Browsers encrypt
+// saved passwords with keys held by the OS or the user's primary password, so
+// they can't be read from a profile alone. Handlers of browser credential
+// stores send each login to detectors as a line made by Format instead, which
+// names the site and user it's for.
+type Scanner struct{}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+const marker = "browser saved login:"
+
+var loginPat = regexp.MustCompile(marker + ` browser=(\w+) origin=("(?:[^"\\]|\\.)*") username=("(?:[^"\\]|\\.)*")`)
+
+// Format returns the line detectors find a saved login in. username is empty
+// for browsers that encrypt it too.
+func Format(browser, origin, username string) string {
+	return fmt.Sprintf("%s browser=%s origin=%s username=%s", marker, browser, strconv.Quote(origin), strconv.Quote(username))
+}
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{marker}
+}
+
+// FromData will find browser saved logins in a given set of bytes. They can't
+// be verified, since their passwords are encrypted.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	for _, match := range loginPat.FindAllStringSubmatch(string(data), -1) {
+		browser := match[1]
+		origin, err := strconv.Unquote(match[2])
+		if err != nil {
+			continue
+		}
+		username, err := strconv.Unquote(match[3])
+		if err != nil {
+			continue
+		}
+
+		redacted := origin
+		if username != "" {
+			redacted = username + " at " + origin
+		}
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_BrowserLogin,
+			Raw:          []byte(fmt.Sprintf("%s\t%s\t%s", browser, origin, username)),
+			Redacted:     redacted,
+			ExtraData: map[string]string{
+				"browser": browser,
+				"origin":  origin,
+			},
+		}
+		if username != "" {
+			s1.ExtraData["username"] = username
+		}
+		results = append(results, s1)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,112 @@
+package browserlogin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+func TestBrowserLogin_FromChunk(t *testing.T) {
+	type args struct {
+		ctx    context.Context
+		data   []byte
+		verify bool
+	}
+	tests := []struct {
+		name    string
+		s       Scanner
+		args    args
+		want    []detectors.Result
+		wantErr bool
+	}{
+		{
+			name: "found login",
+			s:    Scanner{},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte(Format("chrome", "https://github.com/login", `ops "admin"@example.com`)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_BrowserLogin,
+					Redacted:     `ops "admin"@example.com at https://github.com/login`,
+					ExtraData: map[string]string{
+						"browser":  "chrome",
+						"origin":   "https://github.com/login",
+						"username": `ops "admin"@example.com`,
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "found login without username",
+			s:    Scanner{},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte(Format("firefox", "https://accounts.example.com", "")),
+				verify: false,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_BrowserLogin,
+					Redacted:     "https://accounts.example.com",
+					ExtraData: map[string]string{
+						"browser": "firefox",
+						"origin":  "https://accounts.example.com",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "not found",
+			s:    Scanner{},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte(`browser saved login: browser=chrome origin=https://github.com username=ops`),
+				verify: false,
+			},
+			want:    nil,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.s.FromData(tt.args.ctx, tt.args.verify, tt.args.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BrowserLogin.FromData() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			for i := range got {
+				if len(got[i].Raw) == 0 {
+					t.Fatalf("no raw secret present: \n %+v", got[i])
+				}
+				got[i].Raw = nil
+			}
+			if diff := pretty.Compare(got, tt.want); diff != "" {
+				t.Errorf("BrowserLogin.FromData() %s diff: (-got +want)\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func BenchmarkFromData(benchmark *testing.B) {
+	ctx := context.Background()
+	s := Scanner{}
+	for name, data := range detectors.MustGetBenchmarkData() {
+		benchmark.Run(name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				_, err := s.FromData(ctx, false, data)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
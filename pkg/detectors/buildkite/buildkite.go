@@ -2,6 +2,7 @@ package buildkite
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -12,29 +13,43 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 )
 
-type Scanner struct{}
+type Scanner struct {
+	// Endpoint is the API tokens are verified against. When empty, it's
+	// api.buildkite.com's.
+	Endpoint string
+}
 
 // Ensure the Scanner satisfies the interface at compile time.
 var _ detectors.Detector = (*Scanner)(nil)
 
+const defaultEndpoint = "https://api.buildkite.com/v2"
+
 var (
 	client = common.SaneHttpClient()
 
 	// Make sure that your group is surrounded in boundary characters such as below to reduce false positives.
 	keyPat = regexp.MustCompile(detectors.PrefixRegex([]string{"buildkite"}) + `\b([a-z0-9]{40})\b`)
+	// User access tokens have been prefixed since 2023.
+	userKeyPat = regexp.MustCompile(`\b(bkua_[a-f0-9]{40})\b`)
 )
 
 // Keywords are used for efficiently pre-filtering chunks.
 // Use identifiers in the secret preferably, or the provider name.
 func (s Scanner) Keywords() []string {
-	return []string{"buildkite"}
+	return []string{"buildkite", "bkua_"}
 }
 
 // FromData will find and optionally verify Buildkite secrets in a given set of bytes.
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
 
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+
 	matches := keyPat.FindAllStringSubmatch(dataStr, -1)
+	matches = append(matches, userKeyPat.FindAllStringSubmatch(dataStr, -1)...)
 
 	for _, match := range matches {
 		if len(match) != 2 {
@@ -48,16 +63,15 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 		}
 
 		if verify {
-			req, err := http.NewRequestWithContext(ctx, "GET", "https://api.buildkite.com/v2/user", nil)
-			if err != nil {
-				continue
+			// https://buildkite.com/docs/apis/rest-api/access-token
+			var token struct {
+				Scopes []string `json:"scopes"`
 			}
-			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", resMatch))
-			res, err := client.Do(req)
+			verified, err := get(ctx, endpoint+"/access-token", resMatch, &token)
 			if err == nil {
-				defer res.Body.Close()
-				if res.StatusCode >= 200 && res.StatusCode < 300 {
+				if verified {
 					s1.Verified = true
+					s1.ExtraData = accessDetails(ctx, endpoint, resMatch, token.Scopes)
 				} else {
 					// This function will check false positives for common test words, but also it will make sure the key appears 'random' enough to be a real key.
 					if detectors.IsKnownFalsePositive(resMatch, detectors.DefaultFalsePositives, true) {
@@ -72,3 +86,51 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 
 	return detectors.CleanResults(results), nil
 }
+
+// accessDetails returns the scopes of a token and, if the scopes allow
+// reading them, its user and organizations.
+func accessDetails(ctx context.Context, endpoint, token string, scopes []string) map[string]string {
+	extraData := map[string]string{"scopes": strings.Join(scopes, ",")}
+
+	var user struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	if ok, err := get(ctx, endpoint+"/user", token, &user); err == nil && ok {
+		extraData["user"] = user.Name
+		extraData["email"] = user.Email
+	}
+
+	var organizations []struct {
+		Slug string `json:"slug"`
+	}
+	if ok, err := get(ctx, endpoint+"/organizations", token, &organizations); err == nil && ok && len(organizations) > 0 {
+		var slugs []string
+		for _, org := range organizations {
+			slugs = append(slugs, org.Slug)
+		}
+		extraData["organizations"] = strings.Join(slugs, ",")
+	}
+	return extraData
+}
+
+// get decodes the response of an authenticated GET request into v.
+func get(ctx context.Context, url, token string, v interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	res, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return false, nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
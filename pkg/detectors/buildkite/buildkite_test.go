@@ -3,6 +3,8 @@ package buildkite
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -92,6 +94,9 @@ func TestBuildkite_FromChunk(t *testing.T) {
 					t.Fatalf("no raw secret present: \n %+v", got[i])
 				}
 				got[i].Raw = nil
+				// ExtraData depends on the test account, and is covered by
+				// TestBuildkite_ExtraData.
+				got[i].ExtraData = nil
 			}
 			if diff := pretty.Compare(got, tt.want); diff != "" {
 				t.Errorf("Buildkite.FromData() %s diff: (-got +want)\n%s", tt.name, diff)
@@ -100,6 +105,49 @@ func TestBuildkite_FromChunk(t *testing.T) {
 	}
 }
 
+func TestBuildkite_ExtraData(t *testing.T) {
+	const token = "bkua_7c4e1a9b3f0d2e8a6b5c9d1f0e3a7b2c4d6e8f01"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/access-token":
+			fmt.Fprint(w, `{"uuid":"b63254c0","scopes":["read_builds","write_builds","read_user"]}`)
+		case "/user":
+			fmt.Fprint(w, `{"id":"abc","name":"Release Bot","email":"release-bot@acme.io"}`)
+		default:
+			// The token may not read organizations.
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	s := Scanner{Endpoint: server.URL}
+	got, err := s.FromData(context.Background(), true, []byte(fmt.Sprintf("BUILDKITE_API_TOKEN=%s", token)))
+	if err != nil {
+		t.Fatalf("Buildkite.FromData() error = %v", err)
+	}
+	for i := range got {
+		got[i].Raw = nil
+	}
+	want := []detectors.Result{
+		{
+			DetectorType: detectorspb.DetectorType_Buildkite,
+			Verified:     true,
+			ExtraData: map[string]string{
+				"scopes": "read_builds,write_builds,read_user",
+				"user":   "Release Bot",
+				"email":  "release-bot@acme.io",
+			},
+		},
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("Buildkite.FromData() diff: (-got +want)\n%s", diff)
+	}
+}
+
 func BenchmarkFromData(benchmark *testing.B) {
 	ctx := context.Background()
 	s := Scanner{}
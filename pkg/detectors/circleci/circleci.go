@@ -2,8 +2,10 @@ package circleci
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"regexp"
+	"strings"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
@@ -11,30 +13,50 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 )
 
-type Scanner struct{}
+type Scanner struct {
+	// Endpoint is the API tokens are verified against. When empty, it's
+	// circleci.com's.
+	Endpoint string
+}
 
 // Ensure the Scanner satisfies the interface at compile time.
 var _ detectors.Detector = (*Scanner)(nil)
 
+const defaultEndpoint = "https://circleci.com/api/v2"
+
 var (
+	client = common.SaneHttpClient()
+
 	keyPat = regexp.MustCompile(detectors.PrefixRegex([]string{"circle"}) + `([a-fA-F0-9]{40})`)
+	// Personal API tokens have been prefixed since 2023.
+	// https://circleci.com/docs/managing-api-tokens/
+	personalKeyPat = regexp.MustCompile(`\b(CCIPAT_[A-Za-z0-9]{22}_[a-f0-9]{40})\b`)
 )
 
 // Keywords are used for efficiently pre-filtering chunks.
 // Use identifiers in the secret preferably, or the provider name.
 func (s Scanner) Keywords() []string {
-	return []string{"circle"}
+	return []string{"circle", "CCIPAT_"}
 }
 
 // FromData will find and optionally verify Circle secrets in a given set of bytes.
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
 
-	matches := keyPat.FindAllStringSubmatch(dataStr, -1)
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+
+	personalMatches := personalKeyPat.FindAllStringSubmatch(dataStr, -1)
+	matches := append(personalMatches, keyPat.FindAllStringSubmatch(dataStr, -1)...)
 
-	for _, match := range matches {
+	for i, match := range matches {
 
 		token := match[1]
+		if i >= len(personalMatches) && isPartOfPersonalKey(token, personalMatches) {
+			continue
+		}
 
 		s := detectors.Result{
 			DetectorType: detectorspb.DetectorType_Circle,
@@ -42,20 +64,17 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 		}
 
 		if verify {
-			client := common.SaneHttpClient()
 			// https://circleci.com/docs/api/#authentication
-			req, err := http.NewRequestWithContext(ctx, "GET", "https://circleci.com/api/v2/me", nil)
-			if err != nil {
-				continue
-			}
-			req.Header.Add("Accept", "application/json;")
-			req.Header.Add("Circle-Token", token)
-			res, err := client.Do(req)
-			if err == nil {
-				defer res.Body.Close()
+			var user struct {
+				Login string `json:"login"`
 			}
-			if res != nil && res.StatusCode >= 200 && res.StatusCode < 300 {
+			verified, err := get(ctx, endpoint+"/me", token, &user)
+			if err == nil && verified {
 				s.Verified = true
+				s.ExtraData = map[string]string{"username": user.Login}
+				if orgs := collaborations(ctx, endpoint, token); len(orgs) > 0 {
+					s.ExtraData["organizations"] = strings.Join(orgs, ",")
+				}
 			}
 		}
 
@@ -70,3 +89,53 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 
 	return
 }
+
+// isPartOfPersonalKey reports whether token is part of a personal API
+// token, which would otherwise also be reported as a legacy token.
+func isPartOfPersonalKey(token string, personalMatches [][]string) bool {
+	for _, match := range personalMatches {
+		if strings.Contains(match[1], token) {
+			return true
+		}
+	}
+	return false
+}
+
+// collaborations returns the organizations the token's user belongs to.
+// Tokens have no scopes, so these are the organizations it has access to.
+func collaborations(ctx context.Context, endpoint, token string) []string {
+	var collaborations []struct {
+		Name    string `json:"name"`
+		VCSType string `json:"vcs-type"`
+	}
+	if ok, err := get(ctx, endpoint+"/me/collaborations", token, &collaborations); err != nil || !ok {
+		return nil
+	}
+	var orgs []string
+	for _, c := range collaborations {
+		orgs = append(orgs, c.VCSType+"/"+c.Name)
+	}
+	return orgs
+}
+
+// get decodes the response of an authenticated GET request into v.
+func get(ctx context.Context, url, token string, v interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Add("Accept", "application/json;")
+	req.Header.Add("Circle-Token", token)
+	res, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return false, nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -3,6 +3,8 @@ package circleci
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
@@ -94,6 +96,9 @@ func TestCircleCI_FromChunk(t *testing.T) {
 					t.Fatal("no raw secret present")
 				}
 				got[i].Raw = nil
+				// ExtraData depends on the test account, and is covered by
+				// TestCircleCI_ExtraData.
+				got[i].ExtraData = nil
 			}
 			if diff := pretty.Compare(got, tt.want); diff != "" {
 				t.Errorf("CircleCI.FromData() %s diff: (-got +want)\n%s", tt.name, diff)
@@ -102,6 +107,47 @@ func TestCircleCI_FromChunk(t *testing.T) {
 	}
 }
 
+func TestCircleCI_ExtraData(t *testing.T) {
+	const token = "CCIPAT_4Rk9sUq2ZbWx7Tn1Lm8Pc3_0f3a9c27d41b6e58a0c9f7b3e2d14a6c58b9e0f7"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Circle-Token") != token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/me":
+			fmt.Fprint(w, `{"id":"5b1e1f9c","login":"release-bot","name":"Release Bot"}`)
+		case "/me/collaborations":
+			fmt.Fprint(w, `[{"name":"acme","vcs-type":"github"},{"name":"acme-labs","vcs-type":"bitbucket"}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	s := Scanner{Endpoint: server.URL}
+	got, err := s.FromData(context.Background(), true, []byte(fmt.Sprintf("CIRCLE_TOKEN=%s", token)))
+	if err != nil {
+		t.Fatalf("CircleCI.FromData() error = %v", err)
+	}
+	for i := range got {
+		got[i].Raw = nil
+	}
+	want := []detectors.Result{
+		{
+			DetectorType: detectorspb.DetectorType_Circle,
+			Verified:     true,
+			ExtraData: map[string]string{
+				"username":      "release-bot",
+				"organizations": "github/acme,bitbucket/acme-labs",
+			},
+		},
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("CircleCI.FromData() diff: (-got +want)\n%s", diff)
+	}
+}
+
 func BenchmarkFromData(benchmark *testing.B) {
 	ctx := context.Background()
 	s := Scanner{}
@@ -30,6 +30,11 @@ func (s Scanner) Keywords() []string {
 	return []string{"cloudimage"}
 }
 
+// SafeVerification returns false because verifying invalidates cached images.
+func (s Scanner) SafeVerification() bool {
+	return false
+}
+
 // FromData will find and optionally verify CloudImage secrets in a given set of bytes.
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
@@ -52,7 +57,7 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 			`)
 			timeout := 10 * time.Second
 			client.Timeout = timeout
-			req, err := http.NewRequest("POST", "https://api.cloudimage.com/invalidate", payload)
+			req, err := http.NewRequestWithContext(ctx, "POST", "https://api.cloudimage.com/invalidate", payload)
 			if err != nil {
 				continue
 			}
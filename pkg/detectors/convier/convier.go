@@ -32,6 +32,11 @@ func (s Scanner) Keywords() []string {
 	return []string{"convier"}
 }
 
+// SafeVerification returns false because verifying records an event.
+func (s Scanner) SafeVerification() bool {
+	return false
+}
+
 // FromData will find and optionally verify Convier secrets in a given set of bytes.
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
@@ -52,7 +57,7 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 		if verify {
 			timeout := 10 * time.Second
 			client.Timeout = timeout
-			req, err := http.NewRequest("POST", "https://convier.me/api/event", nil)
+			req, err := http.NewRequestWithContext(ctx, "POST", "https://convier.me/api/event", nil)
 			if err != nil {
 				continue
 			}
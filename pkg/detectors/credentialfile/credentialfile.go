@@ -0,0 +1,109 @@
+package credentialfile
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Scanner finds credentials in the dotfiles tools keep them in: .netrc,
+// .npmrc, .pypirc and git's credential store. Each format is parsed by its
+// structure rather than matched with a pattern, so credentials are paired with
+// the host they're for, even when they're split across lines.
+type Scanner struct {
+	// endpoints overrides the API address of verified hosts in tests.
+	endpoints map[string]string
+}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+var client = common.SaneHttpClient()
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"machine", "_auth", "password", "http"}
+}
+
+// credential is a secret for a host, as recorded in a credential file.
+type credential struct {
+	// format is the file format the credential was parsed from.
+	format   string
+	host     string
+	username string
+	// password is the password or token. Tokens have no username.
+	password string
+	// url is the address the credential is for, when the format records one.
+	url string
+}
+
+// parsers parse the credentials of each format from a chunk. Parsers only
+// return credentials from data that's shaped like their format.
+var parsers = []func(string) []credential{
+	parseNetrc,
+	parseNpmrc,
+	parsePypirc,
+	parseGitCredentials,
+}
+
+// FromData will find and optionally verify credential file secrets in a given set of bytes.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	seen := map[string]struct{}{}
+	for _, parse := range parsers {
+		for _, cred := range parse(dataStr) {
+			if isPlaceholder(cred.password) {
+				continue
+			}
+			raw := fmt.Sprintf("%s:%s@%s", cred.username, cred.password, cred.host)
+			if _, ok := seen[raw]; ok {
+				continue
+			}
+			seen[raw] = struct{}{}
+
+			redacted := cred.host
+			if cred.username != "" {
+				redacted = cred.username + "@" + cred.host
+			}
+			s1 := detectors.Result{
+				DetectorType: detectorspb.DetectorType_CredentialFile,
+				Raw:          []byte(raw),
+				Redacted:     redacted,
+				ExtraData: map[string]string{
+					"format": cred.format,
+					"host":   cred.host,
+				},
+			}
+			if cred.username != "" {
+				s1.ExtraData["username"] = cred.username
+			}
+
+			if verify {
+				verified, err := s.verify(ctx, cred)
+				if err == nil {
+					s1.Verified = verified
+				}
+			}
+
+			if !s1.Verified && detectors.IsKnownFalsePositive(cred.password, detectors.DefaultFalsePositives, false) {
+				continue
+			}
+
+			results = append(results, s1)
+		}
+	}
+
+	return results, nil
+}
+
+// isPlaceholder reports whether a value is read from the environment or
+// templated in, rather than being a secret itself.
+func isPlaceholder(value string) bool {
+	return value == "" || strings.HasPrefix(value, "$") || strings.HasPrefix(value, "{{") || strings.HasPrefix(value, "%")
+}
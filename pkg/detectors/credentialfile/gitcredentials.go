@@ -0,0 +1,34 @@
+package credentialfile
+
+import (
+	"net/url"
+	"regexp"
+)
+
+// gitCredentialPat matches the lines of git's credential store, each of which
+// is a URL with the username and password of a host, and nothing else.
+var gitCredentialPat = regexp.MustCompile(`(?m)^\s*(https?://[^:@/\s]+:[^@\s]+@[^/\s@]+)/?\s*$`)
+
+// parseGitCredentials parses the .git-credentials files of git's "store"
+// credential helper.
+func parseGitCredentials(data string) []credential {
+	var creds []credential
+	for _, match := range gitCredentialPat.FindAllStringSubmatch(data, -1) {
+		u, err := url.Parse(match[1])
+		if err != nil || u.User == nil {
+			continue
+		}
+		password, ok := u.User.Password()
+		if !ok || !hostPat.MatchString(u.Host) {
+			continue
+		}
+		creds = append(creds, credential{
+			format:   "git-credentials",
+			host:     u.Host,
+			username: u.User.Username(),
+			password: password,
+			url:      u.Scheme + "://" + u.Host + "/",
+		})
+	}
+	return creds
+}
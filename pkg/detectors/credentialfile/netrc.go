@@ -0,0 +1,79 @@
+package credentialfile
+
+import (
+	"regexp"
+	"strings"
+)
+
+// hostPat matches host names, which must be qualified to rule out prose that
+// happens to contain netrc's keywords.
+var hostPat = regexp.MustCompile(`^(?:[A-Za-z0-9\-]+\.)+[A-Za-z0-9\-]+(?::\d{1,5})?$`)
+
+// parseNetrc parses .netrc files, which are whitespace separated tokens.
+// "machine host" starts an entry, followed by "login", "password", "account"
+// and "port" pairs, in any order and across any number of lines.
+func parseNetrc(data string) []credential {
+	var creds []credential
+	var entry *credential
+	flush := func() {
+		if entry != nil && entry.username != "" && entry.password != "" && hostPat.MatchString(entry.host) {
+			creds = append(creds, *entry)
+		}
+		entry = nil
+	}
+
+	tokens := netrcTokens(data)
+	for i := 0; i < len(tokens); i++ {
+		switch tokens[i] {
+		case "machine":
+			flush()
+			if i+1 < len(tokens) {
+				i++
+				entry = &credential{format: "netrc", host: tokens[i]}
+			}
+		case "login", "password", "account", "port":
+			if entry == nil || i+1 >= len(tokens) {
+				flush()
+				continue
+			}
+			i++
+			switch tokens[i-1] {
+			case "login":
+				entry.username = tokens[i]
+			case "password":
+				entry.password = tokens[i]
+			}
+		default:
+			// Anything else, like a "default" entry, which is for any host, or
+			// text that isn't netrc, ends the entry.
+			flush()
+		}
+	}
+	flush()
+	return creds
+}
+
+// netrcTokens splits a .netrc file into tokens, leaving out comments and the
+// bodies of macros, which run from "macdef" to the next blank line.
+func netrcTokens(data string) []string {
+	var tokens []string
+	inMacro := false
+	for _, line := range strings.Split(data, "\n") {
+		if inMacro {
+			inMacro = strings.TrimSpace(line) != ""
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		for _, field := range strings.Fields(line) {
+			if field == "macdef" {
+				tokens = append(tokens, field)
+				inMacro = true
+				break
+			}
+			tokens = append(tokens, strings.Trim(field, `"`))
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,99 @@
+package credentialfile
+
+import (
+	"encoding/base64"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const defaultNpmRegistry = "https://registry.npmjs.org/"
+
+// npmrcPat matches the .npmrc settings that hold credentials, either scoped
+// to a registry, as in "//registry.npmjs.org/:_authToken=token", or for the
+// default registry.
+var npmrcPat = regexp.MustCompile(`(?m)^\s*(?://([^\s:/]+(?::\d{1,5})?(?:/[^\s:]*)?):)?(_authToken|_auth|_password|username|registry)\s*=\s*(.+?)\s*$`)
+
+// parseNpmrc parses .npmrc files. Registries are authenticated by a token, a
+// base64 encoded "username:password" pair, or a username with a base64
+// encoded password.
+func parseNpmrc(data string) []credential {
+	type registryAuth struct {
+		token, auth, username, password string
+	}
+	registries := map[string]*registryAuth{}
+	var order []string
+	defaultRegistry := defaultNpmRegistry
+
+	for _, match := range npmrcPat.FindAllStringSubmatch(data, -1) {
+		scope, key, value := match[1], match[2], strings.Trim(match[3], `"'`)
+		if key == "registry" {
+			if scope == "" {
+				defaultRegistry = value
+			}
+			continue
+		}
+		// Settings without a scope are for the default registry, which may be
+		// set further down.
+		registry := ""
+		if scope != "" {
+			registry = "https://" + strings.TrimSuffix(scope, "/") + "/"
+		} else if key == "username" {
+			// A username by itself is too common a setting to be npm's.
+			continue
+		}
+		auth, ok := registries[registry]
+		if !ok {
+			auth = &registryAuth{}
+			registries[registry] = auth
+			order = append(order, registry)
+		}
+		switch key {
+		case "_authToken":
+			auth.token = value
+		case "_auth":
+			auth.auth = value
+		case "_password":
+			auth.password = value
+		case "username":
+			auth.username = value
+		}
+	}
+
+	var creds []credential
+	for _, registry := range order {
+		auth := registries[registry]
+		if registry == "" {
+			registry = defaultRegistry
+		}
+		u, err := url.Parse(registry)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		cred := credential{format: "npmrc", host: u.Host, url: registry}
+		switch {
+		case auth.token != "":
+			cred.password = auth.token
+		case auth.auth != "":
+			decoded, err := base64.StdEncoding.DecodeString(auth.auth)
+			if err != nil {
+				continue
+			}
+			username, password, ok := strings.Cut(string(decoded), ":")
+			if !ok {
+				continue
+			}
+			cred.username, cred.password = username, password
+		case auth.username != "" && auth.password != "":
+			decoded, err := base64.StdEncoding.DecodeString(auth.password)
+			if err != nil {
+				continue
+			}
+			cred.username, cred.password = auth.username, string(decoded)
+		default:
+			continue
+		}
+		creds = append(creds, cred)
+	}
+	return creds
+}
@@ -0,0 +1,70 @@
+package credentialfile
+
+import (
+	"net/url"
+	"strings"
+)
+
+// pypiRepositories are the upload URLs of the repositories .pypirc sections
+// default to.
+var pypiRepositories = map[string]string{
+	"pypi":     "https://upload.pypi.org/legacy/",
+	"testpypi": "https://test.pypi.org/legacy/",
+}
+
+// parsePypirc parses .pypirc files, which are INI files with a section for
+// each repository. Sections need a username and password, and a repository
+// URL unless they're for PyPI or TestPyPI, so other INI files aren't
+// mistaken for one.
+func parsePypirc(data string) []credential {
+	type section struct {
+		name   string
+		values map[string]string
+	}
+	var sections []*section
+	var current *section
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";"):
+		case strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]"):
+			current = &section{name: strings.TrimSpace(trimmed[1 : len(trimmed)-1]), values: map[string]string{}}
+			sections = append(sections, current)
+		case current != nil && trimmed == line:
+			// Indented lines continue the previous value, like the list of
+			// index-servers, and don't hold credentials.
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				key, value, ok = strings.Cut(line, ":")
+			}
+			if ok {
+				current.values[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+			}
+		}
+	}
+
+	var creds []credential
+	for _, s := range sections {
+		username, password := s.values["username"], s.values["password"]
+		if username == "" || password == "" {
+			continue
+		}
+		repository := s.values["repository"]
+		if repository == "" {
+			repository = pypiRepositories[s.name]
+		}
+		u, err := url.Parse(repository)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		creds = append(creds, credential{
+			format:   "pypirc",
+			host:     u.Host,
+			username: username,
+			password: password,
+			url:      repository,
+		})
+	}
+	return creds
+}
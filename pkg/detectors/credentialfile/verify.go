@@ -0,0 +1,152 @@
+package credentialfile
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+)
+
+// hostVerifier checks credentials for a host with a read-only request to its
+// API at baseURL.
+type hostVerifier struct {
+	baseURL string
+	verify  func(ctx context.Context, baseURL string, cred credential) (bool, error)
+}
+
+// verifiers are the hosts whose credentials are verified. Credentials for
+// other hosts are only verified when they're npm registries the network policy
+// explicitly allows, since sending them to arbitrary hosts found in the
+// scanned data isn't safe, and most hosts have no way to check them.
+var verifiers = map[string]hostVerifier{
+	"github.com":         {baseURL: "https://api.github.com", verify: verifyGitHub},
+	"gitlab.com":         {baseURL: "https://gitlab.com", verify: verifyGitLab},
+	"bitbucket.org":      {baseURL: "https://api.bitbucket.org", verify: verifyBitbucket},
+	"api.heroku.com":     {baseURL: "https://api.heroku.com", verify: verifyHeroku},
+	"git.heroku.com":     {baseURL: "https://api.heroku.com", verify: verifyHeroku},
+	"registry.npmjs.org": {baseURL: "https://registry.npmjs.org/", verify: verifyNpm},
+	"upload.pypi.org":    {baseURL: "https://upload.pypi.org/legacy/", verify: verifyPyPI},
+	"test.pypi.org":      {baseURL: "https://test.pypi.org/legacy/", verify: verifyPyPI},
+}
+
+// verify checks cred against the host it was recorded for, when that's safe.
+func (s Scanner) verify(ctx context.Context, cred credential) (bool, error) {
+	v, ok := verifiers[cred.host]
+	if !ok {
+		if cred.format != "npmrc" || !common.NetworkPolicyFromContext(ctx).AllowsExplicitly(hostname(cred.host)) {
+			return false, nil
+		}
+		v = hostVerifier{baseURL: cred.url, verify: verifyNpm}
+	}
+	baseURL := v.baseURL
+	if override, ok := s.endpoints[cred.host]; ok {
+		baseURL = override
+	}
+	return v.verify(ctx, baseURL, cred)
+}
+
+// hostname returns host without its port.
+func hostname(host string) string {
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		return host[:i]
+	}
+	return host
+}
+
+func verifyGitHub(ctx context.Context, baseURL string, cred credential) (bool, error) {
+	return get(ctx, baseURL+"/user", func(req *http.Request) {
+		req.SetBasicAuth(cred.username, cred.password)
+	})
+}
+
+func verifyGitLab(ctx context.Context, baseURL string, cred credential) (bool, error) {
+	return get(ctx, baseURL+"/api/v4/user", func(req *http.Request) {
+		// OAuth tokens are stored with the username "oauth2".
+		if cred.username == "oauth2" {
+			req.Header.Set("Authorization", "Bearer "+cred.password)
+		} else {
+			req.Header.Set("PRIVATE-TOKEN", cred.password)
+		}
+	})
+}
+
+func verifyBitbucket(ctx context.Context, baseURL string, cred credential) (bool, error) {
+	return get(ctx, baseURL+"/2.0/user", func(req *http.Request) {
+		req.SetBasicAuth(cred.username, cred.password)
+	})
+}
+
+// verifyHeroku checks the API key the Heroku CLI stores as the password.
+func verifyHeroku(ctx context.Context, baseURL string, cred credential) (bool, error) {
+	return get(ctx, baseURL+"/account", func(req *http.Request) {
+		req.Header.Set("Accept", "application/vnd.heroku+json; version=3")
+		req.Header.Set("Authorization", "Bearer "+cred.password)
+	})
+}
+
+// verifyNpm asks the registry who the credential belongs to, which every npm
+// registry supports.
+func verifyNpm(ctx context.Context, baseURL string, cred credential) (bool, error) {
+	return get(ctx, strings.TrimSuffix(baseURL, "/")+"/-/whoami", func(req *http.Request) {
+		if cred.username == "" {
+			req.Header.Set("Authorization", "Bearer "+cred.password)
+		} else {
+			req.SetBasicAuth(cred.username, cred.password)
+		}
+	})
+}
+
+// verifyPyPI sends an upload request without a distribution file, which is
+// rejected before anything is uploaded: with 403 Forbidden for invalid
+// credentials, and 400 Bad Request for the missing file otherwise.
+func verifyPyPI(ctx context.Context, baseURL string, cred credential) (bool, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for field, value := range map[string]string{":action": "file_upload", "protocol_version": "1"} {
+		if err := form.WriteField(field, value); err != nil {
+			return false, err
+		}
+	}
+	if err := form.Close(); err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL, &body)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.SetBasicAuth(cred.username, cred.password)
+	res, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	res.Body.Close()
+	return res.StatusCode == http.StatusBadRequest, nil
+}
+
+// get reports whether a GET request to url, authorized by authorize, succeeds.
+func get(ctx context.Context, url string, authorize func(*http.Request)) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return false, err
+	}
+	authorize(req)
+	res, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+	}
+}
@@ -32,6 +32,11 @@ func (s Scanner) Keywords() []string {
 	return []string{"customerio"}
 }
 
+// SafeVerification returns false because verifying tracks an event for a customer.
+func (s Scanner) SafeVerification() bool {
+	return false
+}
+
 // FromData will find and optionally verify CustomerIO secrets in a given set of bytes.
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
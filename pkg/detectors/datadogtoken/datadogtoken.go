@@ -2,6 +2,7 @@ package datadogtoken
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"regexp"
 	"strings"
@@ -11,7 +12,11 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 )
 
-type Scanner struct{}
+type Scanner struct {
+	// BaseURL is the API keys are verified against. When empty, it's the
+	// API of the site the keys belong to.
+	BaseURL string
+}
 
 // Ensure the Scanner satisfies the interface at compile time.
 var _ detectors.Detector = (*Scanner)(nil)
@@ -22,6 +27,14 @@ var (
 	// Make sure that your group is surrounded in boundary characters such as below to reduce false positives.
 	keyPat = regexp.MustCompile(detectors.PrefixRegex([]string{"datadog"}) + `\b([a-zA-Z-0-9]{40})\b`)
 	apiPat = regexp.MustCompile(detectors.PrefixRegex([]string{"datadog"}) + `\b([a-zA-Z-0-9]{32})\b`)
+
+	// Keys only work on the site their organization is hosted on, which is
+	// usually configured next to them with DD_SITE or an API URL.
+	sitePat = regexp.MustCompile(`(?i)\b((?:us3\.|us5\.|ap1\.)?datadoghq\.(?:com|eu)|ddog-gov\.com)\b`)
+
+	// sites are all Datadog sites, in the order they're tried when keys
+	// aren't found next to one.
+	sites = []string{"datadoghq.com", "datadoghq.eu", "us3.datadoghq.com", "us5.datadoghq.com", "ap1.datadoghq.com", "ddog-gov.com"}
 )
 
 // Keywords are used for efficiently pre-filtering chunks.
@@ -37,6 +50,8 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 	matches := keyPat.FindAllStringSubmatch(dataStr, -1)
 	apiMatches := apiPat.FindAllStringSubmatch(dataStr, -1)
 
+	candidateSites := siteCandidates(dataStr)
+
 	for _, match := range matches {
 		if len(match) != 2 {
 			continue
@@ -54,24 +69,26 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 			}
 
 			if verify {
-
-				req, err := http.NewRequestWithContext(ctx, "GET", "https://api.datadoghq.com/api/v2/users", nil)
-				if err != nil {
-					continue
+				for _, site := range candidateSites {
+					baseURL := s.BaseURL
+					if baseURL == "" {
+						baseURL = "https://api." + site
+					}
+					verified, err := verifySite(ctx, baseURL, resApiMatch, resMatch)
+					if err != nil || !verified {
+						continue
+					}
+					s1.Verified = true
+					s1.ExtraData = map[string]string{"site": site}
+					if org := orgName(ctx, baseURL, resApiMatch, resMatch); org != "" {
+						s1.ExtraData["org"] = org
+					}
+					break
 				}
-				req.Header.Add("Content-Type", "application/json")
-				req.Header.Add("DD-API-KEY", resApiMatch)
-				req.Header.Add("DD-APPLICATION-KEY", resMatch)
-				res, err := client.Do(req)
-				if err == nil {
-					defer res.Body.Close()
-					if res.StatusCode >= 200 && res.StatusCode < 300 {
-						s1.Verified = true
-					} else {
-						// This function will check false positives for common test words, but also it will make sure the key appears 'random' enough to be a real key.
-						if detectors.IsKnownFalsePositive(resMatch, detectors.DefaultFalsePositives, true) {
-							continue
-						}
+				if !s1.Verified {
+					// This function will check false positives for common test words, but also it will make sure the key appears 'random' enough to be a real key.
+					if detectors.IsKnownFalsePositive(resMatch, detectors.DefaultFalsePositives, true) {
+						continue
 					}
 				}
 			}
@@ -83,3 +100,63 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 
 	return detectors.CleanResults(results), nil
 }
+
+// siteCandidates returns the sites mentioned in data, or all sites if there
+// are none.
+func siteCandidates(data string) []string {
+	var candidates []string
+	seen := map[string]struct{}{}
+	for _, match := range sitePat.FindAllStringSubmatch(data, -1) {
+		site := strings.ToLower(match[1])
+		if _, ok := seen[site]; ok {
+			continue
+		}
+		seen[site] = struct{}{}
+		candidates = append(candidates, site)
+	}
+	if len(candidates) == 0 {
+		return sites
+	}
+	return candidates
+}
+
+func verifySite(ctx context.Context, baseURL, apiKey, appKey string) (bool, error) {
+	res, err := get(ctx, baseURL+"/api/v2/users", apiKey, appKey)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	return res.StatusCode >= 200 && res.StatusCode < 300, nil
+}
+
+// orgName returns the name of the organization the keys belong to.
+func orgName(ctx context.Context, baseURL, apiKey, appKey string) string {
+	res, err := get(ctx, baseURL+"/api/v1/org", apiKey, appKey)
+	if err != nil {
+		return ""
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return ""
+	}
+	var body struct {
+		Orgs []struct {
+			Name string `json:"name"`
+		} `json:"orgs"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil || len(body.Orgs) == 0 {
+		return ""
+	}
+	return body.Orgs[0].Name
+}
+
+func get(ctx context.Context, url, apiKey, appKey string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("DD-API-KEY", apiKey)
+	req.Header.Add("DD-APPLICATION-KEY", appKey)
+	return client.Do(req)
+}
@@ -3,6 +3,8 @@ package datadogtoken
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -93,6 +95,9 @@ func TestDatadogToken_FromChunk(t *testing.T) {
 					t.Fatalf("no raw secret present: \n %+v", got[i])
 				}
 				got[i].Raw = nil
+				// ExtraData depends on the test account, and is covered by
+				// TestDatadogToken_ExtraData.
+				got[i].ExtraData = nil
 			}
 			if diff := pretty.Compare(got, tt.want); diff != "" {
 				t.Errorf("DatadogToken.FromData() %s diff: (-got +want)\n%s", tt.name, diff)
@@ -101,6 +106,64 @@ func TestDatadogToken_FromChunk(t *testing.T) {
 	}
 }
 
+func TestDatadogToken_ExtraData(t *testing.T) {
+	const (
+		apiKey = "3f9b2c7d1e4a8f6b0c5d9e2a7b1f4c8d"
+		appKey = "9a4c7e1b3d8f2a6c0e5b9d3f7a1c4e8b2d6f0a3c"
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("DD-API-KEY") != apiKey || r.Header.Get("DD-APPLICATION-KEY") != appKey {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v2/users":
+			fmt.Fprint(w, `{"data":[]}`)
+		case "/api/v1/org":
+			fmt.Fprint(w, `{"orgs":[{"name":"Acme EU","public_id":"abc123"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	s := Scanner{BaseURL: server.URL}
+	data := fmt.Sprintf("DD_SITE=datadoghq.eu\nDATADOG_API_KEY=%s\nDATADOG_APP_KEY=%s\n", apiKey, appKey)
+	got, err := s.FromData(context.Background(), true, []byte(data))
+	if err != nil {
+		t.Fatalf("DatadogToken.FromData() error = %v", err)
+	}
+	for i := range got {
+		got[i].Raw = nil
+	}
+	want := []detectors.Result{
+		{
+			DetectorType: detectorspb.DetectorType_DatadogToken,
+			Verified:     true,
+			ExtraData:    map[string]string{"site": "datadoghq.eu", "org": "Acme EU"},
+		},
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("DatadogToken.FromData() diff: (-got +want)\n%s", diff)
+	}
+}
+
+func Test_siteCandidates(t *testing.T) {
+	tests := []struct {
+		data string
+		want []string
+	}{
+		{data: "DD_SITE=us5.datadoghq.com", want: []string{"us5.datadoghq.com"}},
+		{data: "url: https://api.datadoghq.eu/api/v1/series", want: []string{"datadoghq.eu"}},
+		{data: "DATADOG_API_KEY=...", want: sites},
+	}
+	for _, tt := range tests {
+		if diff := pretty.Compare(siteCandidates(tt.data), tt.want); diff != "" {
+			t.Errorf("siteCandidates(%q) diff: (-got +want)\n%s", tt.data, diff)
+		}
+	}
+}
+
 func BenchmarkFromData(benchmark *testing.B) {
 	ctx := context.Background()
 	s := Scanner{}
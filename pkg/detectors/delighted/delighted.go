@@ -31,6 +31,11 @@ func (s Scanner) Keywords() []string {
 	return []string{"delighted"}
 }
 
+// SafeVerification returns false because verifying creates a person in the account.
+func (s Scanner) SafeVerification() bool {
+	return false
+}
+
 // FromData will find and optionally verify Delighted secrets in a given set of bytes.
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
@@ -8,6 +8,8 @@ import (
 	"strings"
 	"unicode"
 
+	"github.com/go-errors/errors"
+
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
@@ -23,6 +25,22 @@ type Detector interface {
 	Keywords() []string
 }
 
+// SafeVerifier is an optional interface for detectors to declare whether their
+// verification request is free of side effects on the secret owner's account,
+// like sending a message or creating a resource. Detectors that don't
+// implement it are assumed to verify safely.
+type SafeVerifier interface {
+	SafeVerification() bool
+}
+
+// HasSafeVerification reports whether verifying secrets with d has no side effects.
+func HasSafeVerification(d Detector) bool {
+	if v, ok := d.(SafeVerifier); ok {
+		return v.SafeVerification()
+	}
+	return true
+}
+
 type Result struct {
 	// DetectorType is the type of Detector.
 	DetectorType detectorspb.DetectorType
@@ -45,9 +63,25 @@ type ResultWithMetadata struct {
 	SourceType sourcespb.SourceType
 	// SourceName is the name of the Source.
 	SourceName string
+	// Provenance is the chain of containers, such as archives, the secret
+	// was unpacked from in the source's file or object, outermost first.
+	Provenance []sources.ContainerRef
+	// Tenant is the customer or organization whose assets the secret was
+	// found in, when the source was scanned for one.
+	Tenant string
 	Result
 }
 
+// ParseDetectorType returns the detector type with name, ignoring case.
+func ParseDetectorType(name string) (detectorspb.DetectorType, error) {
+	for typeName, value := range detectorspb.DetectorType_value {
+		if strings.EqualFold(typeName, name) {
+			return detectorspb.DetectorType(value), nil
+		}
+	}
+	return 0, errors.Errorf("unknown detector %q", name)
+}
+
 // CopyMetadata returns a detector result with included metadata from the source chunk.
 func CopyMetadata(chunk *sources.Chunk, result Result) ResultWithMetadata {
 	return ResultWithMetadata{
@@ -55,6 +89,8 @@ func CopyMetadata(chunk *sources.Chunk, result Result) ResultWithMetadata {
 		SourceID:       chunk.SourceID,
 		SourceType:     chunk.SourceType,
 		SourceName:     chunk.SourceName,
+		Provenance:     chunk.Provenance,
+		Tenant:         chunk.Tenant,
 		Result:         result,
 	}
 }
@@ -1,6 +1,9 @@
 package detectors
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func TestPrefixRegex(t *testing.T) {
 	tests := []struct {
@@ -34,3 +37,21 @@ func BenchmarkPrefixRegex(b *testing.B) {
 		PrefixRegex(kws)
 	}
 }
+
+type safeDetector struct{}
+
+func (safeDetector) FromData(context.Context, bool, []byte) ([]Result, error) { return nil, nil }
+func (safeDetector) Keywords() []string                                       { return nil }
+
+type unsafeDetector struct{ safeDetector }
+
+func (unsafeDetector) SafeVerification() bool { return false }
+
+func TestHasSafeVerification(t *testing.T) {
+	if !HasSafeVerification(safeDetector{}) {
+		t.Error("detectors without SafeVerification should default to safe")
+	}
+	if HasSafeVerification(unsafeDetector{}) {
+		t.Error("detectors declaring unsafe verification should not be safe")
+	}
+}
@@ -20,7 +20,7 @@ var (
 	client = common.SaneHttpClient()
 
 	// Make sure that your group is surrounded in boundary characters such as below to reduce false positives.
-	keyPat = regexp.MustCompile(`(https:\/\/discord\.com\/api\/webhooks\/[0-9]{18}\/[0-9a-zA-Z-]{68})`)
+	keyPat = regexp.MustCompile(`(https:\/\/(?:(?:canary|ptb)\.)?discord(?:app)?\.com\/api\/(?:v[0-9]+\/)?webhooks\/[0-9]{17,20}\/[0-9a-zA-Z_-]{68})`)
 )
 
 // Keywords are used for efficiently pre-filtering chunks.
@@ -46,6 +46,7 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 		}
 
 		if verify {
+			// Fetching a webhook returns its metadata and never executes it.
 			req, err := http.NewRequestWithContext(ctx, "GET", resMatch, nil)
 			if err != nil {
 				continue
@@ -0,0 +1,289 @@
+package docker
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+type Scanner struct {
+	// Registry is the base URL credentials are verified against. When empty,
+	// it's the registry the credentials were found for, if that's a known
+	// registry or one the network policy explicitly allows.
+	Registry string
+}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+const (
+	dockerHub = "registry-1.docker.io"
+	ghcr      = "ghcr.io"
+	quay      = "quay.io"
+)
+
+var (
+	client = common.SaneHttpClient()
+
+	// Auth blobs of docker's config.json and the legacy .dockercfg, which are
+	// base64 encoded "username:password" pairs keyed by registry.
+	authPat = regexp.MustCompile(`"([^"\s]+)"\s*:\s*\{[^{}]*?"auth"\s*:\s*"([A-Za-z0-9+/]{8,}={0,2})"`)
+
+	// Docker Hub personal access tokens, which are used with the username
+	// of their owner.
+	hubTokenPat    = regexp.MustCompile(`\b(dckr_pat_[A-Za-z0-9_-]{27})(?:[^A-Za-z0-9_-]|$)`)
+	hubUsernamePat = regexp.MustCompile(`(?i)(?:--username|-u|user(?:name)?)\b["']?\s*[:=\s]\s*["']?([a-z0-9]{4,30})\b`)
+
+	// Quay robot accounts are named "namespace+robot" and have 64 character
+	// tokens.
+	quayRobotPat = regexp.MustCompile(`\b([a-z0-9][a-z0-9_]{1,254}\+[a-z0-9_]{1,254})\b`)
+	quayTokenPat = regexp.MustCompile(`\b([A-Z0-9]{64})\b`)
+
+	// ECR passwords, as printed by "aws ecr get-login-password", are base64
+	// encoded JSON documents and are used with the username "AWS".
+	ecrPasswordPat = regexp.MustCompile(`\b(eyJwYXlsb2FkIjoi[A-Za-z0-9+/]{100,}={0,2})`)
+	ecrHostPat     = regexp.MustCompile(`\b(\d{12}\.dkr\.ecr\.[a-z0-9-]+\.amazonaws\.com(?:\.cn)?)\b`)
+	ecrRegistryPat = regexp.MustCompile(`^\d{12}\.dkr\.ecr\.[a-z0-9-]+\.amazonaws\.com(?:\.cn)?$`)
+)
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{`"auth"`, "dckr_pat_", "quay", "eyJwYXlsb2FkIjoi"}
+}
+
+// credential is a username and password for a registry.
+type credential struct {
+	registry string
+	username string
+	password string
+	// source is what the credential was found as.
+	source string
+}
+
+// FromData will find and optionally verify Docker registry secrets in a given set of bytes.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	var creds []credential
+	creds = append(creds, authBlobs(dataStr)...)
+	creds = append(creds, hubTokens(dataStr)...)
+	creds = append(creds, quayRobots(dataStr)...)
+	creds = append(creds, ecrPasswords(dataStr)...)
+
+	seen := map[string]struct{}{}
+	for _, cred := range creds {
+		raw := fmt.Sprintf("%s:%s@%s", cred.username, cred.password, cred.registry)
+		if _, ok := seen[raw]; ok {
+			continue
+		}
+		seen[raw] = struct{}{}
+
+		redacted := cred.registry
+		if cred.username != "" {
+			redacted = cred.username + "@" + cred.registry
+		}
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_Docker,
+			Raw:          []byte(raw),
+			Redacted:     redacted,
+			ExtraData: map[string]string{
+				"registry": cred.registry,
+				"username": cred.username,
+				"source":   cred.source,
+			},
+		}
+
+		expired := false
+		if expiresAt, ok := ecrExpiration(cred.password); ok {
+			s1.ExtraData["expires_at"] = expiresAt.UTC().Format(time.RFC3339)
+			expired = time.Now().After(expiresAt)
+		}
+
+		if verify && !expired && cred.username != "" {
+			registry := s.Registry
+			if registry == "" && allowedRegistry(ctx, cred.registry) {
+				registry = "https://" + cred.registry
+			}
+			if registry != "" {
+				verified, repositories, err := verifyRegistry(ctx, registry, cred.username, cred.password)
+				if err == nil && verified {
+					s1.Verified = true
+					if len(repositories) > 0 {
+						s1.ExtraData["repositories"] = strings.Join(repositories, ",")
+					}
+				}
+			}
+		}
+
+		if !s1.Verified && detectors.IsKnownFalsePositive(cred.password, detectors.DefaultFalsePositives, false) {
+			continue
+		}
+
+		results = append(results, s1)
+	}
+
+	return results, nil
+}
+
+// SafeVerification returns false because verifying sends credentials to a
+// registry that may have been found in the scanned data.
+func (s Scanner) SafeVerification() bool { return false }
+
+// allowedRegistry reports whether credentials may be sent to the registry at
+// host. Docker Hub, GHCR, Quay and ECR are always allowed; any other registry
+// was named by whoever wrote the scanned data, so only hosts the network
+// policy in ctx explicitly allows are.
+func allowedRegistry(ctx context.Context, host string) bool {
+	u, err := url.Parse("https://" + host)
+	if err != nil || u.Host != host {
+		return false
+	}
+	hostname := strings.ToLower(u.Hostname())
+	switch {
+	case hostname == dockerHub, hostname == ghcr, hostname == quay, ecrRegistryPat.MatchString(hostname):
+		return true
+	}
+	return common.NetworkPolicyFromContext(ctx).AllowsExplicitly(hostname)
+}
+
+// authBlobs returns the credentials of config.json auth blobs.
+func authBlobs(data string) []credential {
+	var creds []credential
+	for _, match := range authPat.FindAllStringSubmatch(data, -1) {
+		decoded, err := base64.StdEncoding.DecodeString(match[2])
+		if err != nil {
+			continue
+		}
+		username, password, ok := strings.Cut(string(decoded), ":")
+		if !ok || username == "" || password == "" {
+			continue
+		}
+		creds = append(creds, credential{
+			registry: registryHost(match[1]),
+			username: username,
+			password: password,
+			source:   "auth",
+		})
+	}
+	return creds
+}
+
+// hubTokens returns Docker Hub access tokens with the usernames found near
+// them. Tokens without one can't be verified and are reported by themselves.
+func hubTokens(data string) []credential {
+	tokens := hubTokenPat.FindAllStringSubmatch(data, -1)
+	if len(tokens) == 0 {
+		return nil
+	}
+	usernames := uniqueMatches(hubUsernamePat, data)
+	if len(usernames) == 0 {
+		usernames = []string{""}
+	}
+
+	var creds []credential
+	for _, token := range tokens {
+		for _, username := range usernames {
+			creds = append(creds, credential{
+				registry: dockerHub,
+				username: strings.ToLower(username),
+				password: token[1],
+				source:   "token",
+			})
+		}
+	}
+	return creds
+}
+
+// quayRobots returns Quay robot account tokens paired with robot names found
+// near them.
+func quayRobots(data string) []credential {
+	var creds []credential
+	tokens := uniqueMatches(quayTokenPat, data)
+	for _, robot := range uniqueMatches(quayRobotPat, data) {
+		for _, token := range tokens {
+			creds = append(creds, credential{
+				registry: quay,
+				username: robot,
+				password: token,
+				source:   "robot",
+			})
+		}
+	}
+	return creds
+}
+
+// ecrPasswords returns ECR passwords paired with the registries found near
+// them.
+func ecrPasswords(data string) []credential {
+	var creds []credential
+	hosts := uniqueMatches(ecrHostPat, data)
+	for _, password := range uniqueMatches(ecrPasswordPat, data) {
+		for _, host := range hosts {
+			creds = append(creds, credential{
+				registry: host,
+				username: "AWS",
+				password: password,
+				source:   "ecr",
+			})
+		}
+	}
+	return creds
+}
+
+// ecrExpiration returns when an ECR password expires. They're valid for 12
+// hours.
+func ecrExpiration(password string) (time.Time, bool) {
+	if !strings.HasPrefix(password, "eyJwYXlsb2FkIjoi") {
+		return time.Time{}, false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(password)
+	if err != nil {
+		return time.Time{}, false
+	}
+	var token struct {
+		Expiration int64 `json:"expiration"`
+	}
+	if err := json.Unmarshal(decoded, &token); err != nil || token.Expiration == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(token.Expiration, 0), true
+}
+
+// registryHost returns the host of a config.json registry key, which may be
+// a URL. Docker Hub is keyed by its legacy index URL.
+func registryHost(key string) string {
+	host := key
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	host, _, _ = strings.Cut(host, "/")
+	switch host {
+	case "docker.io", "index.docker.io", "registry.hub.docker.com":
+		return dockerHub
+	}
+	return host
+}
+
+// uniqueMatches returns the distinct first groups of the pattern's matches.
+func uniqueMatches(pat *regexp.Regexp, data string) []string {
+	var matches []string
+	seen := map[string]struct{}{}
+	for _, match := range pat.FindAllStringSubmatch(data, -1) {
+		if _, ok := seen[match[1]]; ok {
+			continue
+		}
+		seen[match[1]] = struct{}{}
+		matches = append(matches, match[1])
+	}
+	return matches
+}
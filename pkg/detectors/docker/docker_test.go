@@ -0,0 +1,322 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+const (
+	// ci-bot:Xk82mQp4Lr7vTz
+	activeAuth = "Y2ktYm90OlhrODJtUXA0THI3dlR6"
+	// ci-bot:Wn39hRt5Js1cUy
+	inactiveAuth  = "Y2ktYm90OlduMzloUnQ1SnMxY1V5"
+	hubToken      = "dckr_pat_Hq2Vb8Nc4Xm6Lk0Pj9Ow3Zr5Ty1"
+	expiredECR    = "eyJwYXlsb2FkIjoiYzJWaGJHVmtJSEJoZVd4dllXUWdabTl5SUhSbGMzUnoiLCJkYXRha2V5IjoiWkdGMFlTQnJaWGtnWm05eUlIUmxjM1J6IiwidmVyc2lvbiI6IjIiLCJ0eXBlIjoiREFUQV9LRVkiLCJleHBpcmF0aW9uIjoxNjAwMDAwMDAwfQ=="
+	registryToken = "registry-token"
+)
+
+// newRegistry returns a TLS registry that accepts ci-bot's active
+// credentials, and whose challenges name the realm realm returns.
+func newRegistry(t *testing.T, realm func(server *httptest.Server) string) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s",service="registry.test"`, realm(server)))
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/token":
+			username, password, _ := r.BasicAuth()
+			if r.URL.Query().Get("service") != "registry.test" || username != "ci-bot" || password != "Xk82mQp4Lr7vTz" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, `{"token":"%s"}`, registryToken)
+		case "/v2/_catalog":
+			if r.Header.Get("Authorization") != "Bearer "+registryToken {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"repositories":["acme/api","acme/web"]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDocker_FromChunk(t *testing.T) {
+	server := newRegistry(t, func(server *httptest.Server) string { return server.URL + "/token" })
+	defer func(c *http.Client) { client = c }(client)
+	client = server.Client()
+
+	registryHost := strings.TrimPrefix(server.URL, "https://")
+	allowLocal := common.WithNetworkPolicy(context.Background(), &common.NetworkPolicy{Allow: []string{"127.0.0.1"}})
+
+	type args struct {
+		ctx    context.Context
+		data   []byte
+		verify bool
+	}
+	tests := []struct {
+		name    string
+		s       Scanner
+		args    args
+		want    []detectors.Result
+		wantErr bool
+	}{
+		{
+			name: "found auth blob, verified",
+			s:    Scanner{Registry: server.URL},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte(fmt.Sprintf(`{"auths": {"ghcr.io": {"auth": "%s"}}}`, activeAuth)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_Docker,
+					Verified:     true,
+					Redacted:     "ci-bot@ghcr.io",
+					ExtraData: map[string]string{
+						"registry":     "ghcr.io",
+						"username":     "ci-bot",
+						"source":       "auth",
+						"repositories": "acme/api,acme/web",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "found auth blob, unverified",
+			s:    Scanner{Registry: server.URL},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte(fmt.Sprintf(`{"https://index.docker.io/v1/": {"email": "ci@acme.io", "auth": "%s"}}`, inactiveAuth)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_Docker,
+					Verified:     false,
+					Redacted:     "ci-bot@registry-1.docker.io",
+					ExtraData: map[string]string{
+						"registry": "registry-1.docker.io",
+						"username": "ci-bot",
+						"source":   "auth",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "found auth blob, allowed registry",
+			s:    Scanner{},
+			args: args{
+				ctx:    allowLocal,
+				data:   []byte(fmt.Sprintf(`{"auths": {"%s": {"auth": "%s"}}}`, registryHost, activeAuth)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_Docker,
+					Verified:     true,
+					Redacted:     "ci-bot@" + registryHost,
+					ExtraData: map[string]string{
+						"registry":     registryHost,
+						"username":     "ci-bot",
+						"source":       "auth",
+						"repositories": "acme/api,acme/web",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "found auth blob, registry not allowed",
+			s:    Scanner{},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte(fmt.Sprintf(`{"auths": {"%s": {"auth": "%s"}}}`, registryHost, activeAuth)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_Docker,
+					Verified:     false,
+					Redacted:     "ci-bot@" + registryHost,
+					ExtraData: map[string]string{
+						"registry": registryHost,
+						"username": "ci-bot",
+						"source":   "auth",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "found Docker Hub token, unverified",
+			s:    Scanner{Registry: server.URL},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte(fmt.Sprintf("docker login -u acmebot -p %s", hubToken)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_Docker,
+					Verified:     false,
+					Redacted:     "acmebot@registry-1.docker.io",
+					ExtraData: map[string]string{
+						"registry": "registry-1.docker.io",
+						"username": "acmebot",
+						"source":   "token",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "found expired ECR password",
+			s:    Scanner{Registry: server.URL},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte(fmt.Sprintf("docker login --username AWS --password %s 123456789012.dkr.ecr.us-east-1.amazonaws.com", expiredECR)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_Docker,
+					Verified:     false,
+					Redacted:     "AWS@123456789012.dkr.ecr.us-east-1.amazonaws.com",
+					ExtraData: map[string]string{
+						"registry":   "123456789012.dkr.ecr.us-east-1.amazonaws.com",
+						"username":   "AWS",
+						"source":     "ecr",
+						"expires_at": "2020-09-13T12:26:40Z",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "not found",
+			s:    Scanner{Registry: server.URL},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte(`{"auths": {"ghcr.io": {"auth": ""}}, "credsStore": "desktop"}`),
+				verify: true,
+			},
+			want:    nil,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.s.FromData(tt.args.ctx, tt.args.verify, tt.args.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Docker.FromData() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			for i := range got {
+				if len(got[i].Raw) == 0 {
+					t.Fatalf("no raw secret present: \n %+v", got[i])
+				}
+				got[i].Raw = nil
+			}
+			if diff := pretty.Compare(got, tt.want); diff != "" {
+				t.Errorf("Docker.FromData() %s diff: (-got +want)\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestDocker_TokenRealm(t *testing.T) {
+	httpRealm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("credentials sent to the http realm")
+	}))
+	defer httpRealm.Close()
+
+	tests := []struct {
+		name  string
+		realm func(server *httptest.Server) string
+		want  bool
+	}{
+		{
+			name:  "realm on the registry",
+			realm: func(server *httptest.Server) string { return server.URL + "/token" },
+			want:  true,
+		},
+		{
+			name:  "http realm",
+			realm: func(*httptest.Server) string { return httpRealm.URL + "/token" },
+			want:  false,
+		},
+		{
+			name:  "realm on another host",
+			realm: func(*httptest.Server) string { return "https://auth.example.com/token" },
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newRegistry(t, tt.realm)
+			defer func(c *http.Client) { client = c }(client)
+			client = server.Client()
+
+			data := fmt.Sprintf(`{"auths": {"ghcr.io": {"auth": "%s"}}}`, activeAuth)
+			got, err := Scanner{Registry: server.URL}.FromData(context.Background(), true, []byte(data))
+			if err != nil {
+				t.Fatalf("Docker.FromData() error = %v", err)
+			}
+			if len(got) != 1 || got[0].Verified != tt.want {
+				t.Errorf("Docker.FromData() = %+v, want one result with Verified %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowedRealm(t *testing.T) {
+	tests := []struct {
+		realm, registry string
+		want            bool
+	}{
+		{realm: "auth.docker.io", registry: "registry-1.docker.io", want: true},
+		{realm: "ghcr.io", registry: "ghcr.io", want: true},
+		{realm: "gitlab.com", registry: "registry.gitlab.com", want: true},
+		{realm: "attacker.io", registry: "ghcr.io", want: false},
+		{realm: "auth.docker.io", registry: "quay.io", want: false},
+		{realm: "127.0.0.2", registry: "127.0.0.1", want: false},
+	}
+	for _, tt := range tests {
+		if got := allowedRealm(context.Background(), tt.realm, tt.registry); got != tt.want {
+			t.Errorf("allowedRealm(%q, %q) = %v, want %v", tt.realm, tt.registry, got, tt.want)
+		}
+	}
+}
+
+func BenchmarkFromData(benchmark *testing.B) {
+	ctx := context.Background()
+	s := Scanner{}
+	for name, data := range detectors.MustGetBenchmarkData() {
+		benchmark.Run(name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				_, err := s.FromData(ctx, false, data)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+)
+
+// challengeParamPat matches the key="value" parameters of a WWW-Authenticate
+// challenge.
+var challengeParamPat = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// verifyRegistry logs in to the registry at baseURL using the Docker
+// Registry HTTP API V2 handshake: an unauthenticated request to /v2/ is
+// answered with the challenge to use, which is either Basic authentication
+// against /v2/ itself, or a token from the challenge's realm. Repositories are
+// listed from the catalog if the registry has one and the credentials may
+// read it.
+func verifyRegistry(ctx context.Context, baseURL, username, password string) (bool, []string, error) {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/v2/", nil)
+	if err != nil {
+		return false, nil, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return false, nil, err
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusUnauthorized {
+		// The registry allows anonymous access, so there's nothing to verify.
+		return false, nil, nil
+	}
+
+	scheme, params := parseChallenge(res.Header.Get("WWW-Authenticate"))
+	switch scheme {
+	case "basic":
+		req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/v2/", nil)
+		if err != nil {
+			return false, nil, err
+		}
+		req.SetBasicAuth(username, password)
+		res, err := client.Do(req)
+		if err != nil {
+			return false, nil, err
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return false, nil, nil
+		}
+		return true, listCatalog(ctx, baseURL, func(r *http.Request) { r.SetBasicAuth(username, password) }), nil
+	case "bearer":
+		token, ok, err := fetchToken(ctx, baseURL, params, username, password)
+		if err != nil || !ok {
+			return false, nil, err
+		}
+		return true, listCatalog(ctx, baseURL, func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) }), nil
+	default:
+		return false, nil, fmt.Errorf("unsupported registry challenge %q", scheme)
+	}
+}
+
+// parseChallenge returns the lowercased scheme and parameters of a
+// WWW-Authenticate header.
+func parseChallenge(header string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	params := map[string]string{}
+	for _, match := range challengeParamPat.FindAllStringSubmatch(rest, -1) {
+		params[strings.ToLower(match[1])] = match[2]
+	}
+	return strings.ToLower(scheme), params
+}
+
+// fetchToken requests a catalog token from the realm of a Bearer challenge.
+// Token servers reject invalid credentials outright, and hand out tokens
+// with fewer scopes than requested to valid ones.
+func fetchToken(ctx context.Context, baseURL string, params map[string]string, username, password string) (string, bool, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Scheme != "https" || realm.Host == "" {
+		return "", false, fmt.Errorf("invalid token realm %q", params["realm"])
+	}
+	registry, err := url.Parse(baseURL)
+	if err != nil {
+		return "", false, err
+	}
+	if !allowedRealm(ctx, realm.Hostname(), registry.Hostname()) {
+		return "", false, fmt.Errorf("token realm %q is not on the registry's domain", params["realm"])
+	}
+	query := realm.Query()
+	if service, ok := params["service"]; ok {
+		query.Set("service", service)
+	}
+	query.Set("scope", "registry:catalog:*")
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", realm.String(), nil)
+	if err != nil {
+		return "", false, err
+	}
+	req.SetBasicAuth(username, password)
+	res, err := client.Do(req)
+	if err != nil {
+		return "", false, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", false, nil
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", false, err
+	}
+	if body.Token == "" {
+		body.Token = body.AccessToken
+	}
+	return body.Token, body.Token != "", nil
+}
+
+// allowedRealm reports whether credentials for the registry at registryHost
+// may be sent to a token realm at realmHost. The challenge comes from the
+// registry, so the realm must be the registry itself, on the domain the
+// registry is a subdomain of (such as auth.docker.io for
+// registry-1.docker.io), or explicitly allowed by the network policy in ctx.
+func allowedRealm(ctx context.Context, realmHost, registryHost string) bool {
+	realmHost, registryHost = strings.ToLower(realmHost), strings.ToLower(registryHost)
+	if realmHost == registryHost {
+		return true
+	}
+	if net.ParseIP(registryHost) == nil {
+		if _, domain, ok := strings.Cut(registryHost, "."); ok && strings.Contains(domain, ".") {
+			if realmHost == domain || strings.HasSuffix(realmHost, "."+domain) {
+				return true
+			}
+		}
+	}
+	return common.NetworkPolicyFromContext(ctx).AllowsExplicitly(realmHost)
+}
+
+// listCatalog returns the first page of the registry's repositories. Docker
+// Hub and GHCR have no catalog, and other registries only show it to
+// credentials that may read it, so failures aren't errors.
+func listCatalog(ctx context.Context, baseURL string, authorize func(*http.Request)) []string {
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/v2/_catalog?n=100", nil)
+	if err != nil {
+		return nil
+	}
+	authorize(req)
+	res, err := client.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil
+	}
+
+	var body struct {
+		Repositories []string `json:"repositories"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil
+	}
+	return body.Repositories
+}
@@ -2,9 +2,12 @@ package droneci
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
@@ -17,23 +20,39 @@ type Scanner struct{}
 // Ensure the Scanner satisfies the interface at compile time.
 var _ detectors.Detector = (*Scanner)(nil)
 
+const defaultServer = "https://cloud.drone.io"
+
 var (
 	client = common.SaneHttpClient()
 
 	// Make sure that your group is surrounded in boundary characters such as below to reduce false positives.
-	keyPat = regexp.MustCompile(detectors.PrefixRegex([]string{"droneci"}) + `\b([a-zA-Z0-9]{32})\b`)
+	keyPat = regexp.MustCompile(detectors.PrefixRegex([]string{"drone"}) + `\b([a-zA-Z0-9]{32})\b`)
+	// Drone is mostly self-hosted, and the CLI is pointed at the server with
+	// DRONE_SERVER.
+	serverPat = regexp.MustCompile(`(?i)DRONE_SERVER["']?\s*[:=]\s*["']?(https?://[^\s"'/]+)`)
 )
 
 // Keywords are used for efficiently pre-filtering chunks.
 // Use identifiers in the secret preferably, or the provider name.
 func (s Scanner) Keywords() []string {
-	return []string{"droneci"}
+	return []string{"drone"}
 }
 
 // FromData will find and optionally verify DroneCI secrets in a given set of bytes.
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
 
+	// A token next to a DRONE_SERVER belongs to that server, so if the server
+	// isn't allowed the token isn't verified at all, rather than against Drone
+	// Cloud.
+	server := defaultServer
+	if match := serverPat.FindStringSubmatch(dataStr); match != nil {
+		server = match[1]
+		if !allowedServer(ctx, server) {
+			server = ""
+		}
+	}
+
 	matches := keyPat.FindAllStringSubmatch(dataStr, -1)
 
 	for _, match := range matches {
@@ -47,8 +66,8 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 			Raw:          []byte(resMatch),
 		}
 
-		if verify {
-			req, err := http.NewRequestWithContext(ctx, "GET", "https://cloud.drone.io/api/user", nil)
+		if verify && server != "" {
+			req, err := http.NewRequestWithContext(ctx, "GET", server+"/api/user", nil)
 			if err != nil {
 				continue
 			}
@@ -58,6 +77,17 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 				defer res.Body.Close()
 				if res.StatusCode >= 200 && res.StatusCode < 300 {
 					s1.Verified = true
+					var user struct {
+						Login string `json:"login"`
+						Admin bool   `json:"admin"`
+					}
+					if err := json.NewDecoder(res.Body).Decode(&user); err == nil {
+						s1.ExtraData = map[string]string{
+							"server":   server,
+							"username": user.Login,
+							"admin":    strconv.FormatBool(user.Admin),
+						}
+					}
 				} else {
 					// This function will check false positives for common test words, but also it will make sure the key appears 'random' enough to be a real key.
 					if detectors.IsKnownFalsePositive(resMatch, detectors.DefaultFalsePositives, true) {
@@ -72,3 +102,23 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 
 	return detectors.CleanResults(results), nil
 }
+
+// SafeVerification returns false because verifying sends the token to a Drone
+// server that may have been found in the scanned data.
+func (s Scanner) SafeVerification() bool { return false }
+
+// allowedServer reports whether tokens may be sent to the Drone server at
+// rawURL. Drone Cloud is always allowed; any other server was named by
+// whoever wrote the scanned data, so only HTTPS hosts the network policy in
+// ctx explicitly allows are.
+func allowedServer(ctx context.Context, rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "cloud.drone.io" {
+		return true
+	}
+	return common.NetworkPolicyFromContext(ctx).AllowsExplicitly(host)
+}
@@ -3,6 +3,8 @@ package droneci
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -92,6 +94,90 @@ func TestDroneCI_FromChunk(t *testing.T) {
 					t.Fatalf("no raw secret present: \n %+v", got[i])
 				}
 				got[i].Raw = nil
+				// ExtraData depends on the test account, and is covered by
+				// TestDroneCI_ExtraData.
+				got[i].ExtraData = nil
+			}
+			if diff := pretty.Compare(got, tt.want); diff != "" {
+				t.Errorf("DroneCI.FromData() %s diff: (-got +want)\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestDroneCI_ExtraData(t *testing.T) {
+	const token = "Kq7Zt2Vn9Xb4Lm1Rc8Wd3Hs6Pf0Gj5Ya"
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/user" || r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"id":1,"login":"octocat","admin":true}`)
+	})
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+
+	defer func(c *http.Client) { client = c }(client)
+	client = server.Client()
+
+	allowLocal := common.WithNetworkPolicy(context.Background(), &common.NetworkPolicy{Allow: []string{"127.0.0.1"}})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		url  string
+		want []detectors.Result
+	}{
+		{
+			name: "allowed server",
+			ctx:  allowLocal,
+			url:  server.URL,
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_DroneCI,
+					Verified:     true,
+					ExtraData: map[string]string{
+						"server":   server.URL,
+						"username": "octocat",
+						"admin":    "true",
+					},
+				},
+			},
+		},
+		{
+			name: "http server",
+			ctx:  allowLocal,
+			url:  httpServer.URL,
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_DroneCI,
+					Verified:     false,
+				},
+			},
+		},
+		{
+			name: "server not allowed",
+			ctx:  context.Background(),
+			url:  server.URL,
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_DroneCI,
+					Verified:     false,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := fmt.Sprintf("export DRONE_SERVER=%s\nexport DRONE_TOKEN=%s\n", tt.url, token)
+			got, err := Scanner{}.FromData(tt.ctx, true, []byte(data))
+			if err != nil {
+				t.Fatalf("DroneCI.FromData() error = %v", err)
+			}
+			for i := range got {
+				got[i].Raw = nil
 			}
 			if diff := pretty.Compare(got, tt.want); diff != "" {
 				t.Errorf("DroneCI.FromData() %s diff: (-got +want)\n%s", tt.name, diff)
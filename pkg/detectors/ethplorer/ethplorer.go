@@ -29,6 +29,11 @@ func (s Scanner) Keywords() []string {
 	return []string{"ethplorer"}
 }
 
+// SafeVerification returns false because verifying creates a monitoring pool.
+func (s Scanner) SafeVerification() bool {
+	return false
+}
+
 // FromData will find and optionally verify Ethplorer secrets in a given set of bytes.
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
@@ -1,9 +1,15 @@
 package detectors
 
 import (
+	"bufio"
 	_ "embed"
+	"os"
 	"strings"
 	"unicode"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 )
 
 var DefaultFalsePositives = []FalsePositive{"example", "xxxxxx", "aaaaaa", "abcde", "00000", "sample"}
@@ -41,6 +47,9 @@ func IsKnownFalsePositive(match string, falsePositives []FalsePositive, wordChec
 			return true
 		}
 	}
+	if hasDictWord(customFalsePositives.all, match) {
+		return true
+	}
 
 	if wordCheck {
 		// check against common substring badlist
@@ -61,6 +70,73 @@ func IsKnownFalsePositive(match string, falsePositives []FalsePositive, wordChec
 	return false
 }
 
+// customFalsePositives are the tokens added with AddFalsePositives and
+// AddDetectorFalsePositives, in lower case.
+var customFalsePositives = struct {
+	all       []string
+	detectors map[detectorspb.DetectorType][]string
+}{detectors: map[detectorspb.DetectorType][]string{}}
+
+// AddFalsePositives adds tokens that are false positives for every detector,
+// like an organization's internal sample keys or an SDK's placeholders, to the
+// ones IsKnownFalsePositive checks. They must be added before scanning.
+func AddFalsePositives(fps ...FalsePositive) {
+	customFalsePositives.all = appendFalsePositives(customFalsePositives.all, fps)
+}
+
+// AddDetectorFalsePositives adds tokens that are false positives for results
+// of one type of detector. They must be added before scanning.
+func AddDetectorFalsePositives(detector detectorspb.DetectorType, fps ...FalsePositive) {
+	customFalsePositives.detectors[detector] = appendFalsePositives(customFalsePositives.detectors[detector], fps)
+}
+
+func appendFalsePositives(list []string, fps []FalsePositive) []string {
+	for _, fp := range fps {
+		if word := strings.TrimSpace(strings.ToLower(string(fp))); word != "" {
+			list = append(list, word)
+		}
+	}
+	return list
+}
+
+// IsCustomFalsePositive reports whether r's secret contains a token added
+// with AddFalsePositives, or with AddDetectorFalsePositives for its detector.
+// The engine leaves these results out, including those of detectors that don't
+// check IsKnownFalsePositive.
+func IsCustomFalsePositive(r Result) bool {
+	if len(customFalsePositives.all) == 0 && len(customFalsePositives.detectors) == 0 {
+		return false
+	}
+	secret := string(r.Raw)
+	return hasDictWord(customFalsePositives.all, secret) ||
+		hasDictWord(customFalsePositives.detectors[r.DetectorType], secret)
+}
+
+// LoadFalsePositiveWordlist reads false positive tokens from a wordlist file
+// with one token on each line. Blank lines and lines starting with # are
+// skipped.
+func LoadFalsePositiveWordlist(path string) ([]FalsePositive, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not open wordlist", 0)
+	}
+	defer file.Close()
+
+	var fps []FalsePositive
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fps = append(fps, FalsePositive(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.WrapPrefix(err, "could not read wordlist "+path, 0)
+	}
+	return fps, nil
+}
+
 func hasDictWord(wordList []string, token string) bool {
 	lower := strings.ToLower(token)
 	for _, word := range wordList {
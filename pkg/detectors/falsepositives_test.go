@@ -1,6 +1,12 @@
 package detectors
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
 
 func TestIsFalsePositive(t *testing.T) {
 	type args struct {
@@ -37,3 +43,67 @@ func TestIsFalsePositive(t *testing.T) {
 		})
 	}
 }
+
+func TestCustomFalsePositives(t *testing.T) {
+	t.Cleanup(func() {
+		customFalsePositives.all = nil
+		customFalsePositives.detectors = map[detectorspb.DetectorType][]string{}
+	})
+
+	path := filepath.Join(t.TempDir(), "wordlist.txt")
+	wordlist := "# Internal sample keys\nACME-SAMPLE\n\n  sdk_placeholder  \n"
+	if err := os.WriteFile(path, []byte(wordlist), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fps, err := LoadFalsePositiveWordlist(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fps) != 2 || fps[0] != "ACME-SAMPLE" || fps[1] != "sdk_placeholder" {
+		t.Fatalf("LoadFalsePositiveWordlist() = %q", fps)
+	}
+	AddFalsePositives(fps...)
+	AddDetectorFalsePositives(detectorspb.DetectorType_Stripe, "sk_live_internal")
+
+	if !IsKnownFalsePositive("key-acme-sample-123", nil, false) {
+		t.Error("IsKnownFalsePositive() = false for a wordlist token")
+	}
+	tests := []struct {
+		name   string
+		result Result
+		want   bool
+	}{
+		{
+			name:   "every detector",
+			result: Result{DetectorType: detectorspb.DetectorType_AWS, Raw: []byte("SDK_PLACEHOLDER1")},
+			want:   true,
+		},
+		{
+			name:   "detector",
+			result: Result{DetectorType: detectorspb.DetectorType_Stripe, Raw: []byte("sk_live_internal123")},
+			want:   true,
+		},
+		{
+			name:   "other detector",
+			result: Result{DetectorType: detectorspb.DetectorType_AWS, Raw: []byte("sk_live_internal123")},
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsCustomFalsePositive(tt.result); got != tt.want {
+				t.Errorf("IsCustomFalsePositive() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDetectorType(t *testing.T) {
+	got, err := ParseDetectorType("stripe")
+	if err != nil || got != detectorspb.DetectorType_Stripe {
+		t.Errorf("ParseDetectorType() = %v, %v", got, err)
+	}
+	if _, err := ParseDetectorType("nope"); err == nil {
+		t.Error("ParseDetectorType() of an unknown detector succeeded")
+	}
+}
@@ -0,0 +1,141 @@
+package ftp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/textproto"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+type Scanner struct{}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+var (
+	keyPat = regexp.MustCompile(`(?i)\b(ftps?)://([^:@/\s"'<>]+):([^@/\s"'<>]+)@([a-z0-9.\-]+(?::\d{1,5})?)`)
+
+	// Usernames of anonymous logins, whose passwords are conventionally an
+	// email address.
+	anonymousUsers = map[string]struct{}{
+		"anonymous": {},
+		"ftp":       {},
+	}
+)
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"ftp://", "ftps://"}
+}
+
+// SafeVerification returns false because failed logins may count towards the
+// account's lockout threshold.
+func (s Scanner) SafeVerification() bool {
+	return false
+}
+
+// FromData will find and optionally verify FTP secrets in a given set of bytes.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	for _, match := range keyPat.FindAllStringSubmatch(dataStr, -1) {
+		parsedURL, err := url.Parse(match[0])
+		if err != nil {
+			continue
+		}
+		username := parsedURL.User.Username()
+		password, ok := parsedURL.User.Password()
+		if !ok || password == "" {
+			continue
+		}
+		if _, ok := anonymousUsers[strings.ToLower(username)]; ok {
+			continue
+		}
+		// Skip passwords that are templated or read from elsewhere.
+		if strings.HasPrefix(password, "$") || strings.HasPrefix(password, "{") {
+			continue
+		}
+
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_FTP,
+			Raw:          []byte(match[0]),
+			Redacted:     fmt.Sprintf("%s://%s:********@%s", match[1], match[2], match[4]),
+			ExtraData: map[string]string{
+				"host":     parsedURL.Hostname(),
+				"username": username,
+			},
+		}
+
+		if verify {
+			verified, err := verifyLogin(ctx, parsedURL, username, password)
+			if err == nil {
+				s1.Verified = verified
+			}
+		}
+
+		if !s1.Verified && detectors.IsKnownFalsePositive(password, detectors.DefaultFalsePositives, false) {
+			continue
+		}
+
+		results = append(results, s1)
+	}
+
+	return results, nil
+}
+
+// verifyLogin logs in to the FTP server. ftps:// URLs use implicit TLS. The
+// server must be explicitly allowed by the network policy.
+func verifyLogin(ctx context.Context, u *url.URL, username, password string) (bool, error) {
+	// Credentials are sent as command arguments, which must not span lines.
+	if strings.ContainsAny(username+password, "\r\n") {
+		return false, nil
+	}
+
+	useTLS := strings.EqualFold(u.Scheme, "ftps")
+	port := u.Port()
+	if port == "" {
+		port = "21"
+		if useTLS {
+			port = "990"
+		}
+	}
+	conn, err := common.DialExplicitlyAllowed(ctx, net.JoinHostPort(u.Hostname(), port), useTLS)
+	if err != nil {
+		return false, err
+	}
+	c := textproto.NewConn(conn)
+	defer c.Close()
+
+	if _, _, err := c.ReadResponse(220); err != nil {
+		return false, err
+	}
+	if err := c.PrintfLine("USER %s", username); err != nil {
+		return false, err
+	}
+	code, _, err := c.ReadResponse(0)
+	if err != nil {
+		return false, err
+	}
+	// 230 means the user doesn't need a password, so the one found isn't
+	// what logged in.
+	if code != 331 {
+		return false, nil
+	}
+	if err := c.PrintfLine("PASS %s", password); err != nil {
+		return false, err
+	}
+	code, _, err = c.ReadResponse(0)
+	if err != nil {
+		return false, err
+	}
+	_ = c.PrintfLine("QUIT")
+	return code == 230, nil
+}
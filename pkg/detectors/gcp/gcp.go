@@ -2,13 +2,25 @@ package gcp
 
 import (
 	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
 	"regexp"
 	"strings"
+	"time"
 
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
-	"golang.org/x/oauth2/google"
 )
 
 type Scanner struct{}
@@ -17,7 +29,16 @@ type Scanner struct{}
 var _ detectors.Detector = (*Scanner)(nil)
 
 var (
+	client = common.SaneHttpClient()
+
 	keyPat = regexp.MustCompile(`\{[^{]+auth_provider_x509_cert_url[^}]+\}`)
+	// Keys exported without the cert URLs are still recognizable by their type and key material.
+	serviceAccountPat = regexp.MustCompile(`\{[^{}]*"type"\s*:\s*"service_account"[^{}]*\}`)
+
+	// The token endpoint is fixed rather than read from token_uri so that a
+	// scanned key can't direct a signed assertion to an arbitrary host.
+	tokenURL     = "https://oauth2.googleapis.com/token"
+	tokenInfoURL = "https://oauth2.googleapis.com/tokeninfo"
 )
 
 type gcpKey struct {
@@ -42,7 +63,7 @@ func trimCarrots(s string) string {
 // Keywords are used for efficiently pre-filtering chunks.
 // Use identifiers in the secret preferably, or the provider name.
 func (s Scanner) Keywords() []string {
-	return []string{"provider_x509"}
+	return []string{"provider_x509", "service_account"}
 }
 
 // FromData will find and optionally verify GCP secrets in a given set of bytes.
@@ -50,6 +71,11 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 	dataStr := string(data)
 
 	matches := keyPat.FindAllString(dataStr, -1)
+	for _, match := range serviceAccountPat.FindAllString(dataStr, -1) {
+		if strings.Contains(match, `"private_key"`) && !strings.Contains(match, "auth_provider_x509_cert_url") {
+			matches = append(matches, match)
+		}
+	}
 
 	for _, match := range matches {
 		key := match
@@ -86,21 +112,109 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 		}
 
 		if verify {
-			credBytes, _ := json.Marshal(creds)
-			credentials, err := google.CredentialsFromJSON(ctx, credBytes, "https://www.googleapis.com/auth/cloud-platform")
-			if err != nil {
-				continue
-			}
-			if credentials != nil {
-				_, err = credentials.TokenSource.Token()
-				if err == nil {
+			privateKey, err := parsePrivateKey(creds.PrivateKey)
+			if err == nil {
+				verified, err := verifyServiceAccount(ctx, creds, privateKey)
+				if err == nil && verified {
 					s.Verified = true
 				}
 			}
 		}
 
+		if creds.ProjectID != "" || creds.ClientEmail != "" {
+			s.ExtraData = map[string]string{
+				"project_id":   creds.ProjectID,
+				"client_email": creds.ClientEmail,
+			}
+		}
+
 		results = append(results, s)
 	}
 
 	return
 }
+
+func parsePrivateKey(key string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(key))
+	if block == nil {
+		return nil, errors.New("private_key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	rsaKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private_key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// signedAssertion builds the RS256 JWT a service account presents to exchange for an access token.
+func signedAssertion(creds gcpKey, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": creds.PrivateKeyID})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   creds.ClientEmail,
+		"scope": "https://www.googleapis.com/auth/cloud-platform",
+		"aud":   tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// verifyServiceAccount exchanges a signed assertion for an access token and
+// confirms the token with the tokeninfo endpoint.
+func verifyServiceAccount(ctx context.Context, creds gcpKey, key *rsa.PrivateKey) (bool, error) {
+	assertion, err := signedAssertion(creds, key, time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	form.Set("assertion", assertion)
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	res, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return false, nil
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return false, err
+	}
+
+	req, err = http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s?access_token=%s", tokenInfoURL, url.QueryEscape(token.AccessToken)), nil)
+	if err != nil {
+		return false, err
+	}
+	res, err = client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	return res.StatusCode >= 200 && res.StatusCode < 300, nil
+}
@@ -2,7 +2,14 @@ package gcp
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -92,6 +99,7 @@ func TestGCP_FromChunk(t *testing.T) {
 					t.Fatal("no raw secret present")
 				}
 				got[i].Raw = nil
+				got[i].ExtraData = nil
 			}
 			if diff := pretty.Compare(got, tt.want); diff != "" {
 				t.Errorf("GCP.FromData() %s diff: (-got +want)\n%s", tt.name, diff)
@@ -100,6 +108,77 @@ func TestGCP_FromChunk(t *testing.T) {
 	}
 }
 
+func TestGCP_ServiceAccountWithoutCertURLs(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: mustMarshalPKCS8(t, key)})
+	blob, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "thog-sandbox",
+		"private_key_id": "4f1c9b2e",
+		"private_key":    string(keyPEM),
+		"client_email":   "scanner@thog-sandbox.iam.gserviceaccount.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || r.FormValue("assertion") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"access_token":"ya29.test"}`)
+		case "/tokeninfo":
+			if r.URL.Query().Get("access_token") != "ya29.test" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"scope":"https://www.googleapis.com/auth/cloud-platform"}`)
+		}
+	}))
+	defer server.Close()
+
+	defaultTokenURL, defaultTokenInfoURL := tokenURL, tokenInfoURL
+	tokenURL, tokenInfoURL = server.URL+"/token", server.URL+"/tokeninfo"
+	defer func() { tokenURL, tokenInfoURL = defaultTokenURL, defaultTokenInfoURL }()
+
+	got, err := Scanner{}.FromData(context.Background(), true, blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range got {
+		got[i].Raw = nil
+	}
+	want := []detectors.Result{
+		{
+			DetectorType: detectorspb.DetectorType_GCP,
+			Verified:     true,
+			Redacted:     "scanner@thog-sandbox.iam.gserviceaccount.com",
+			ExtraData: map[string]string{
+				"project_id":   "thog-sandbox",
+				"client_email": "scanner@thog-sandbox.iam.gserviceaccount.com",
+			},
+		},
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("GCP.FromData() diff: (-got +want)\n%s", diff)
+	}
+}
+
+func mustMarshalPKCS8(t *testing.T, key *rsa.PrivateKey) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
 func BenchmarkFromData(benchmark *testing.B) {
 	ctx := context.Background()
 	s := Scanner{}
@@ -0,0 +1,175 @@
+package googleapikey
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+type Scanner struct {
+	// baseURL replaces the scheme and host of the APIs keys are probed
+	// against, in tests. When empty, Google's are used.
+	baseURL string
+}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+// SafeVerification returns false because verifying makes Maps requests that
+// are billed to the key's project.
+func (s Scanner) SafeVerification() bool { return false }
+
+var (
+	client = common.SaneHttpClient()
+
+	keyPat = regexp.MustCompile(`\b(AIza[0-9A-Za-z_-]{35})(?:[^0-9A-Za-z_-]|$)`)
+)
+
+// probe is a read-only request to one of the APIs keys may be restricted
+// to. Keys are project-wide and can be restricted to any set of APIs, so
+// what a leaked key is worth depends on which of these it can call. The
+// geocoding and places probes are billable requests.
+type probe struct {
+	api  string
+	host string
+	path string
+}
+
+var probes = []probe{
+	{api: "geocoding", host: "maps.googleapis.com", path: "/maps/api/geocode/json?address=Mountain+View&key=%s"},
+	{api: "places", host: "maps.googleapis.com", path: "/maps/api/place/findplacefromtext/json?input=Museum&inputtype=textquery&key=%s"},
+	{api: "firebase", host: "www.googleapis.com", path: "/identitytoolkit/v3/relyingparty/getProjectConfig?key=%s"},
+	{api: "youtube", host: "www.googleapis.com", path: "/youtube/v3/videos?part=id&id=jNQXAC9IVRw&key=%s"},
+}
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"AIza"}
+}
+
+// FromData will find and optionally verify GoogleApiKey secrets in a given set of bytes.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	seen := map[string]struct{}{}
+	for _, match := range keyPat.FindAllStringSubmatch(dataStr, -1) {
+		resMatch := strings.TrimSpace(match[1])
+		if _, ok := seen[resMatch]; ok {
+			continue
+		}
+		seen[resMatch] = struct{}{}
+
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_GoogleApiKey,
+			Raw:          []byte(resMatch),
+		}
+
+		if verify {
+			verified, extraData := s.probeAPIs(ctx, resMatch)
+			s1.Verified = verified
+			s1.ExtraData = extraData
+		}
+
+		if !s1.Verified && detectors.IsKnownFalsePositive(resMatch, detectors.DefaultFalsePositives, false) {
+			continue
+		}
+
+		results = append(results, s1)
+	}
+
+	return results, nil
+}
+
+// probeAPIs calls each probe with the key. Keys are valid if any API knows
+// them, even if they aren't allowed to call it.
+func (s Scanner) probeAPIs(ctx context.Context, key string) (bool, map[string]string) {
+	var (
+		valid   bool
+		allowed []string
+		project string
+	)
+	for _, p := range probes {
+		res, err := s.call(ctx, p, key)
+		if err != nil {
+			continue
+		}
+		if res.invalidKey() {
+			// The key is unknown to Google, so the other probes would say the
+			// same.
+			return false, nil
+		}
+		valid = true
+		if res.allowed() {
+			allowed = append(allowed, p.api)
+		}
+		if res.ProjectID != "" {
+			project = res.ProjectID
+		}
+	}
+	if !valid {
+		return false, nil
+	}
+
+	extraData := map[string]string{"unrestricted_apis": strings.Join(allowed, ",")}
+	if project != "" {
+		extraData["project_id"] = project
+	}
+	return true, extraData
+}
+
+// probeResponse covers the responses of both the Maps APIs, which report
+// errors in a status field, and the other Google APIs, which use status
+// codes and an error object.
+type probeResponse struct {
+	statusCode   int
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message"`
+	Error        struct {
+		Message string `json:"message"`
+	} `json:"error"`
+	ProjectID string `json:"projectId"`
+}
+
+func (r probeResponse) allowed() bool {
+	return r.statusCode == http.StatusOK && r.Status != "REQUEST_DENIED"
+}
+
+func (r probeResponse) invalidKey() bool {
+	for _, msg := range []string{r.ErrorMessage, r.Error.Message} {
+		if strings.Contains(msg, "API key not valid") || strings.Contains(msg, "provided API key is invalid") {
+			return true
+		}
+	}
+	return false
+}
+
+func (s Scanner) call(ctx context.Context, p probe, key string) (probeResponse, error) {
+	base := s.baseURL
+	if base == "" {
+		base = "https://" + p.host
+	}
+	var res probeResponse
+	req, err := http.NewRequestWithContext(ctx, "GET", base+fmt.Sprintf(p.path, key), nil)
+	if err != nil {
+		return res, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return res, err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return res, err
+	}
+	res.statusCode = resp.StatusCode
+	return res, nil
+}
@@ -0,0 +1,161 @@
+package hashicorpvault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+type Scanner struct {
+	// Endpoint is the Vault address tokens are verified against. When empty,
+	// a VAULT_ADDR found in the same chunk is used instead, if it's an HTTPS
+	// address the network policy explicitly allows.
+	Endpoint string
+}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+var (
+	client = common.SaneHttpClient()
+
+	// Vault 1.10+ tokens carry a type prefix: hvs (service), hvb (batch), hvr (recovery).
+	tokenPat = regexp.MustCompile(`\b(hv[sbr]\.[A-Za-z0-9_-]{24,})\b`)
+	// Legacy tokens are too generic to match without the provider name nearby.
+	legacyTokenPat = regexp.MustCompile(detectors.PrefixRegex([]string{"vault"}) + `\b([sbr]\.[A-Za-z0-9]{24})\b`)
+	// Shamir shares are 33 bytes, printed by `vault operator init` as base64 or hex.
+	keySharePat = regexp.MustCompile(`(?i)\b(unseal|recovery)[ _-]?key[ _-]?[0-9]{0,2}["']?\s*[:=]\s*["']?([A-Za-z0-9+/]{44}|[a-f0-9]{66})\b`)
+	addrPat     = regexp.MustCompile(`(?i)vault_addr["']?\s*[:=]\s*["']?(https?://[A-Za-z0-9.\-]+(?::[0-9]{1,5})?)`)
+)
+
+type lookupSelfResponse struct {
+	Data struct {
+		DisplayName string   `json:"display_name"`
+		Policies    []string `json:"policies"`
+		TTL         int64    `json:"ttl"`
+		Type        string   `json:"type"`
+	} `json:"data"`
+}
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"hvs.", "hvb.", "hvr.", "vault", "unseal key", "recovery key"}
+}
+
+// FromData will find and optionally verify HashiCorpVault secrets in a given set of bytes.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		if addr := addrPat.FindStringSubmatch(dataStr); len(addr) == 2 && allowedAddress(ctx, addr[1]) {
+			endpoint = addr[1]
+		}
+	}
+
+	var tokens []string
+	for _, match := range tokenPat.FindAllStringSubmatch(dataStr, -1) {
+		tokens = append(tokens, match[1])
+	}
+	for _, match := range legacyTokenPat.FindAllStringSubmatch(dataStr, -1) {
+		tokens = append(tokens, match[1])
+	}
+
+	for _, token := range tokens {
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_HashiCorpVault,
+			Raw:          []byte(token),
+			ExtraData: map[string]string{
+				"kind": "token",
+			},
+		}
+
+		if verify && endpoint != "" {
+			info, verified, err := lookupSelf(ctx, endpoint, token)
+			if err == nil && verified {
+				s1.Verified = true
+				s1.ExtraData["address"] = endpoint
+				s1.ExtraData["policies"] = strings.Join(info.Data.Policies, ",")
+				s1.ExtraData["ttl"] = strconv.FormatInt(info.Data.TTL, 10)
+				s1.ExtraData["token_type"] = info.Data.Type
+				if info.Data.DisplayName != "" {
+					s1.ExtraData["display_name"] = info.Data.DisplayName
+				}
+			}
+		}
+
+		if !s1.Verified && detectors.IsKnownFalsePositive(token, detectors.DefaultFalsePositives, false) {
+			continue
+		}
+
+		results = append(results, s1)
+	}
+
+	// Key shares cannot be verified without unsealing, so they are always reported unverified.
+	for _, match := range keySharePat.FindAllStringSubmatch(dataStr, -1) {
+		share := match[2]
+		if detectors.IsKnownFalsePositive(share, detectors.DefaultFalsePositives, false) {
+			continue
+		}
+		results = append(results, detectors.Result{
+			DetectorType: detectorspb.DetectorType_HashiCorpVault,
+			Raw:          []byte(share),
+			ExtraData: map[string]string{
+				"kind": strings.ToLower(match[1]) + "_key",
+			},
+		})
+	}
+
+	return results, nil
+}
+
+// SafeVerification returns false because verifying sends the token to a Vault
+// address that may have been found in the scanned data.
+func (s Scanner) SafeVerification() bool { return false }
+
+// allowedAddress reports whether tokens may be sent to the Vault at addr.
+// Since the address is found in the scanned data, which whoever wrote it
+// controls, only HTTPS hosts the network policy in ctx explicitly allows are.
+func allowedAddress(ctx context.Context, addr string) bool {
+	u, err := url.Parse(addr)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return false
+	}
+	return common.NetworkPolicyFromContext(ctx).AllowsExplicitly(strings.ToLower(u.Hostname()))
+}
+
+// lookupSelf calls the token lookup-self endpoint, which is read-only and does
+// not renew or otherwise modify the token.
+func lookupSelf(ctx context.Context, endpoint, token string) (*lookupSelfResponse, bool, error) {
+	lookupURL := fmt.Sprintf("%s/v1/auth/token/lookup-self", strings.TrimSuffix(endpoint, "/"))
+	req, err := http.NewRequestWithContext(ctx, "GET", lookupURL, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Add("X-Vault-Token", token)
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, false, nil
+	}
+
+	info := &lookupSelfResponse{}
+	if err := json.NewDecoder(res.Body).Decode(info); err != nil {
+		return nil, false, err
+	}
+	return info, true, nil
+}
@@ -0,0 +1,227 @@
+package hashicorpvault
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+const (
+	activeToken   = "hvs.CAESIJ1k9VvT3q2lXJm7Yb8Rk4PzW0nQ6uHcTg5fLsEaD2xKGh4KHGh2cy5QZ3RjN0V2cTJ1eVo4N2tSbU1LcHZwT2Y"
+	inactiveToken = "hvs.CAESIK8p2WmQ7r4nYLz6Xc1Vb3HtJ5dFgS9aKeU0oPiMwN7yGh4KHGh2cy5yM2tXcDhOcTVmYjFMejdWbUhzQTNnR1Q"
+)
+
+func TestHashiCorpVault_FromChunk(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/auth/token/lookup-self" || r.Header.Get("X-Vault-Token") != activeToken {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"display_name":"token-ci","policies":["default","deploy"],"ttl":2764800,"type":"service"}}`)
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	tlsServer := httptest.NewTLSServer(handler)
+	defer tlsServer.Close()
+	defer func(c *http.Client) { client = c }(client)
+	client = tlsServer.Client()
+
+	allowLocal := common.WithNetworkPolicy(context.Background(), &common.NetworkPolicy{Allow: []string{"127.0.0.1"}})
+
+	type args struct {
+		ctx    context.Context
+		data   []byte
+		verify bool
+	}
+	tests := []struct {
+		name    string
+		s       Scanner
+		args    args
+		want    []detectors.Result
+		wantErr bool
+	}{
+		{
+			name: "found, verified",
+			s:    Scanner{Endpoint: server.URL},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte(fmt.Sprintf("export VAULT_TOKEN=%s", activeToken)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_HashiCorpVault,
+					Verified:     true,
+					ExtraData: map[string]string{
+						"kind":         "token",
+						"address":      server.URL,
+						"policies":     "default,deploy",
+						"ttl":          "2764800",
+						"token_type":   "service",
+						"display_name": "token-ci",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "found, unverified",
+			s:    Scanner{Endpoint: server.URL},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte(fmt.Sprintf("export VAULT_TOKEN=%s", inactiveToken)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_HashiCorpVault,
+					Verified:     false,
+					ExtraData:    map[string]string{"kind": "token"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "found, address from chunk",
+			s:    Scanner{},
+			args: args{
+				ctx:    allowLocal,
+				data:   []byte(fmt.Sprintf("VAULT_ADDR=%s\nVAULT_TOKEN=%s", tlsServer.URL, activeToken)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_HashiCorpVault,
+					Verified:     true,
+					ExtraData: map[string]string{
+						"kind":         "token",
+						"address":      tlsServer.URL,
+						"policies":     "default,deploy",
+						"ttl":          "2764800",
+						"token_type":   "service",
+						"display_name": "token-ci",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "found, http address from chunk",
+			s:    Scanner{},
+			args: args{
+				ctx:    allowLocal,
+				data:   []byte(fmt.Sprintf("VAULT_ADDR=%s\nVAULT_TOKEN=%s", server.URL, activeToken)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_HashiCorpVault,
+					Verified:     false,
+					ExtraData:    map[string]string{"kind": "token"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "found, address from chunk not allowed",
+			s:    Scanner{},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte(fmt.Sprintf("VAULT_ADDR=%s\nVAULT_TOKEN=%s", tlsServer.URL, activeToken)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_HashiCorpVault,
+					Verified:     false,
+					ExtraData:    map[string]string{"kind": "token"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "found legacy token, unverified",
+			s:    Scanner{},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte("vault token: s.Q7hJ2kLm9Np4Rt6Vx8Zb1Cd3"),
+				verify: false,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_HashiCorpVault,
+					ExtraData:    map[string]string{"kind": "token"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "found unseal key share",
+			s:    Scanner{},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte("Unseal Key 1: 4R6kS0xZp2bJ9mQ1vN7tWc3yHf5uLg8eDa0iKo2rTs4P"),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_HashiCorpVault,
+					ExtraData:    map[string]string{"kind": "unseal_key"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "not found",
+			s:    Scanner{},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte("You cannot find the secret within"),
+				verify: true,
+			},
+			want:    nil,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.s.FromData(tt.args.ctx, tt.args.verify, tt.args.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HashiCorpVault.FromData() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			for i := range got {
+				if len(got[i].Raw) == 0 {
+					t.Fatalf("no raw secret present: \n %+v", got[i])
+				}
+				got[i].Raw = nil
+			}
+			if diff := pretty.Compare(got, tt.want); diff != "" {
+				t.Errorf("HashiCorpVault.FromData() %s diff: (-got +want)\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func BenchmarkFromData(benchmark *testing.B) {
+	ctx := context.Background()
+	s := Scanner{}
+	for name, data := range detectors.MustGetBenchmarkData() {
+		benchmark.Run(name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				_, err := s.FromData(ctx, false, data)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package honeycomb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+type Scanner struct {
+	// BaseURL is the API keys are verified against. When empty, it's the
+	// API of the region the keys belong to.
+	BaseURL string
+}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+var (
+	client = common.SaneHttpClient()
+
+	// Configuration keys are 22 base62 characters, and classic keys 32 hex
+	// characters.
+	keyPat = regexp.MustCompile(detectors.PrefixRegex([]string{"honeycomb"}) + `\b([a-zA-Z0-9]{22}|[a-f0-9]{32})\b`)
+	// Ingest keys are prefixed, so they don't need the keyword.
+	ingestKeyPat = regexp.MustCompile(`\b(hc[a-z]ik_[0-9a-z]{58})\b`)
+
+	// Keys only work in the region of their team. EU teams send data to
+	// api.eu1.honeycomb.io.
+	euRegionPat = regexp.MustCompile(`(?i)\beu1\.honeycomb\.io\b`)
+
+	regionURLs = map[string]string{
+		"us": "https://api.honeycomb.io",
+		"eu": "https://api.eu1.honeycomb.io",
+	}
+)
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"honeycomb", "ik_"}
+}
+
+// FromData will find and optionally verify Honeycomb secrets in a given set of bytes.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	// Keys found without a region are tried in both.
+	regions := []string{"us", "eu"}
+	if euRegionPat.MatchString(dataStr) {
+		regions = []string{"eu"}
+	}
+
+	matches := keyPat.FindAllStringSubmatch(dataStr, -1)
+	matches = append(matches, ingestKeyPat.FindAllStringSubmatch(dataStr, -1)...)
+
+	for _, match := range matches {
+		resMatch := strings.TrimSpace(match[1])
+
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_Honeycomb,
+			Raw:          []byte(resMatch),
+		}
+
+		if verify {
+			for _, region := range regions {
+				baseURL := s.BaseURL
+				if baseURL == "" {
+					baseURL = regionURLs[region]
+				}
+				info, verified, err := auth(ctx, baseURL, resMatch)
+				if err != nil || !verified {
+					continue
+				}
+				s1.Verified = true
+				s1.ExtraData = map[string]string{
+					"region":      region,
+					"team":        info.Team.Slug,
+					"environment": info.Environment.Slug,
+					"permissions": strings.Join(info.permissions(), ","),
+				}
+				break
+			}
+		}
+
+		if !s1.Verified && detectors.IsKnownFalsePositive(resMatch, detectors.DefaultFalsePositives, true) {
+			continue
+		}
+
+		results = append(results, s1)
+	}
+
+	return results, nil
+}
+
+type authInfo struct {
+	APIKeyAccess map[string]bool `json:"api_key_access"`
+	Environment  struct {
+		Slug string `json:"slug"`
+	} `json:"environment"`
+	Team struct {
+		Slug string `json:"slug"`
+	} `json:"team"`
+}
+
+// permissions returns the sorted names of the permissions the key has.
+func (a authInfo) permissions() []string {
+	var permissions []string
+	for name, granted := range a.APIKeyAccess {
+		if granted {
+			permissions = append(permissions, name)
+		}
+	}
+	sort.Strings(permissions)
+	return permissions
+}
+
+// auth returns the team, environment and permissions of a key.
+// https://docs.honeycomb.io/api/tag/Auth
+func auth(ctx context.Context, baseURL, key string) (authInfo, bool, error) {
+	var info authInfo
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/1/auth", nil)
+	if err != nil {
+		return info, false, err
+	}
+	req.Header.Add("X-Honeycomb-Team", key)
+	res, err := client.Do(req)
+	if err != nil {
+		return info, false, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return info, false, nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(&info); err != nil {
+		return info, false, err
+	}
+	return info, true, nil
+}
@@ -0,0 +1,143 @@
+package honeycomb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+const (
+	activeKey   = "Xk7Rq2Zt9Wm4Lp1Vn8Bc3H"
+	inactiveKey = "9d2f7b1c4e8a3f6b0d5c9e2a7f1b4c8d"
+	ingestKey   = "hcaik_01hq7z3k9x2m4v8n1b5c6d7f9g0h2j4k6m8n0p2q4r6s8t1v3w5x7y9z4k"
+)
+
+func TestHoneycomb_FromChunk(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/1/auth" || r.Header.Get("X-Honeycomb-Team") != activeKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"id":"a1b2c3","type":"configuration","api_key_access":{"events":true,"markers":true,"triggers":false,"createDatasets":true},"environment":{"name":"Production","slug":"production"},"team":{"name":"Acme","slug":"acme"}}`)
+	}))
+	defer server.Close()
+
+	type args struct {
+		ctx    context.Context
+		data   []byte
+		verify bool
+	}
+	tests := []struct {
+		name    string
+		s       Scanner
+		args    args
+		want    []detectors.Result
+		wantErr bool
+	}{
+		{
+			name: "found, verified",
+			s:    Scanner{BaseURL: server.URL},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte(fmt.Sprintf("OTEL_EXPORTER_OTLP_ENDPOINT=https://api.eu1.honeycomb.io\nHONEYCOMB_API_KEY=%s", activeKey)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_Honeycomb,
+					Verified:     true,
+					ExtraData: map[string]string{
+						"region":      "eu",
+						"team":        "acme",
+						"environment": "production",
+						"permissions": "createDatasets,events,markers",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "found, unverified",
+			s:    Scanner{BaseURL: server.URL},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte(fmt.Sprintf("honeycomb:\n  writekey: %s", inactiveKey)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_Honeycomb,
+					Verified:     false,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "found ingest key, unverified",
+			s:    Scanner{BaseURL: server.URL},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte(fmt.Sprintf("x-honeycomb-team: %s", ingestKey)),
+				verify: false,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_Honeycomb,
+					Verified:     false,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "not found",
+			s:    Scanner{BaseURL: server.URL},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte("HONEYCOMB_API_KEY=${HONEYCOMB_API_KEY}"),
+				verify: true,
+			},
+			want:    nil,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.s.FromData(tt.args.ctx, tt.args.verify, tt.args.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Honeycomb.FromData() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			for i := range got {
+				if len(got[i].Raw) == 0 {
+					t.Fatalf("no raw secret present: \n %+v", got[i])
+				}
+				got[i].Raw = nil
+			}
+			if diff := pretty.Compare(got, tt.want); diff != "" {
+				t.Errorf("Honeycomb.FromData() %s diff: (-got +want)\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func BenchmarkFromData(benchmark *testing.B) {
+	ctx := context.Background()
+	s := Scanner{}
+	for name, data := range detectors.MustGetBenchmarkData() {
+		benchmark.Run(name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				_, err := s.FromData(ctx, false, data)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
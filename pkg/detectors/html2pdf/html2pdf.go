@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 )
@@ -23,6 +24,8 @@ type html2pdfRequest struct {
 var _ detectors.Detector = (*Scanner)(nil)
 
 var (
+	client = common.SaneHttpClient()
+
 	// Make sure that your group is surrounded in boundary characters such as below to reduce false positives.
 	keyPat = regexp.MustCompile(detectors.PrefixRegex([]string{"html2pdf"}) + `\b([a-zA-Z0-9]{64})\b`)
 )
@@ -57,8 +60,12 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 			}
 			reqJson, _ := json.Marshal(&req)
 			reqBuf := bytes.NewReader(reqJson)
-			res, err := http.Post("https://api.html2pdf.app/v1/generate", "application/json", reqBuf)
-
+			httpReq, err := http.NewRequestWithContext(ctx, "POST", "https://api.html2pdf.app/v1/generate", reqBuf)
+			if err != nil {
+				continue
+			}
+			httpReq.Header.Add("Content-Type", "application/json")
+			res, err := client.Do(httpReq)
 			if err == nil {
 				defer res.Body.Close()
 				if res.StatusCode >= 200 && res.StatusCode < 300 {
@@ -0,0 +1,173 @@
+package ldap
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+)
+
+// LDAP result codes of bind responses.
+// https://www.rfc-editor.org/rfc/rfc4511#section-4.1.9
+const (
+	resultSuccess            = 0
+	resultInvalidCredentials = 49
+)
+
+var errMalformedResponse = errors.New("malformed LDAP response")
+
+// bindRequest is an LDAP simple bind request.
+// https://www.rfc-editor.org/rfc/rfc4511#section-4.2
+type bindRequest struct {
+	MessageID int
+	Bind      struct {
+		Version  int
+		Name     []byte
+		Password []byte `asn1:"tag:0"`
+	} `asn1:"application,tag:0"`
+}
+
+// simpleBind sends a simple bind request to addr and reports whether the
+// server accepted the credentials.
+func simpleBind(ctx context.Context, addr string, useTLS bool, bindDN, password string) (bool, error) {
+	var req bindRequest
+	req.MessageID = 1
+	req.Bind.Version = 3
+	req.Bind.Name = []byte(bindDN)
+	req.Bind.Password = []byte(password)
+	msg, err := asn1.Marshal(req)
+	if err != nil {
+		return false, err
+	}
+
+	conn, err := common.DialExplicitlyAllowed(ctx, addr, useTLS)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write(msg); err != nil {
+		return false, err
+	}
+
+	code, err := readBindResult(bufio.NewReader(conn))
+	if err != nil {
+		return false, err
+	}
+	switch code {
+	case resultSuccess:
+		return true, nil
+	case resultInvalidCredentials:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected LDAP bind result %d", code)
+	}
+}
+
+// readBindResult reads a bind response and returns its result code. Servers
+// encode responses with BER, which encoding/asn1 can't parse because Active
+// Directory uses long form lengths.
+func readBindResult(r *bufio.Reader) (int, error) {
+	tag, message, err := readElement(r)
+	if err != nil {
+		return 0, err
+	}
+	if tag != 0x30 {
+		return 0, errMalformedResponse
+	}
+
+	// Skip the message ID.
+	rest := message
+	if _, _, rest, err = nextElement(rest); err != nil {
+		return 0, err
+	}
+	tag, response, _, err := nextElement(rest)
+	if err != nil {
+		return 0, err
+	}
+	// [APPLICATION 1] BindResponse
+	if tag != 0x61 {
+		return 0, errMalformedResponse
+	}
+	tag, resultCode, _, err := nextElement(response)
+	if err != nil {
+		return 0, err
+	}
+	if tag != 0x0a || len(resultCode) == 0 || len(resultCode) > 4 {
+		return 0, errMalformedResponse
+	}
+	code := 0
+	for _, b := range resultCode {
+		code = code<<8 | int(b)
+	}
+	return code, nil
+}
+
+// byteReader is a reader of BER elements.
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// maxElementLength limits the size of elements read, as a bind response is
+// never longer than its diagnostic message.
+const maxElementLength = 1 << 16
+
+// readElement reads the tag and contents of a BER element.
+func readElement(r byteReader) (byte, []byte, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, err := readLength(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	contents := make([]byte, length)
+	if _, err := io.ReadFull(r, contents); err != nil {
+		return 0, nil, err
+	}
+	return tag, contents, nil
+}
+
+// nextElement returns the tag and contents of the first BER element of data,
+// and the data following it.
+func nextElement(data []byte) (byte, []byte, []byte, error) {
+	r := bytes.NewReader(data)
+	tag, contents, err := readElement(r)
+	if err != nil {
+		return 0, nil, nil, errMalformedResponse
+	}
+	return tag, contents, data[len(data)-r.Len():], nil
+}
+
+// readLength reads a BER length in short or long form.
+func readLength(r io.ByteReader) (int, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if b&0x80 == 0 {
+		return int(b), nil
+	}
+	n := int(b & 0x7f)
+	if n == 0 || n > 4 {
+		return 0, errMalformedResponse
+	}
+	length := 0
+	for i := 0; i < n; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		length = length<<8 | int(b)
+	}
+	if length > maxElementLength {
+		return 0, errMalformedResponse
+	}
+	return length, nil
+}
@@ -0,0 +1,129 @@
+package ldap
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+type Scanner struct{}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+var (
+	serverPat = regexp.MustCompile(`(?i)\b(ldaps?://[a-z0-9.\-]+(?::\d{1,5})?)`)
+
+	// Bind DNs, as found in configuration files and ldapsearch command lines.
+	bindDNPat = regexp.MustCompile(`(?i)\b((?:cn|uid)=[^,=\s"']+(?:\s*,\s*(?:cn|uid|ou|dc|o|l|c)=[^,=\s"']+)+)`)
+
+	// Bind passwords, by their configuration key or as the -w argument of
+	// ldapsearch and friends.
+	bindPasswordPat = regexp.MustCompile(`(?:(?i:bind_?pw|bind_?password|bind_?credentials?|ldap_?(?:bind_?)?password|security\.credentials|ldap_default_authtok|managerPassword)["']?(?:\s*[:=]\s*|\s+)["']?|\s-w\s+["']?)([^\s"']{4,})`)
+)
+
+// redactedPassword replaces passwords in redacted results.
+const redactedPassword = "********"
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"ldap"}
+}
+
+// SafeVerification returns false because failed binds count towards the
+// account's lockout threshold.
+func (s Scanner) SafeVerification() bool {
+	return false
+}
+
+// FromData will find and optionally verify LDAP secrets in a given set of bytes.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	servers := uniqueMatches(serverPat, dataStr)
+	if len(servers) == 0 {
+		// Credentials without a server are still reported, but can't be
+		// verified.
+		servers = []string{""}
+	}
+	bindDNs := uniqueMatches(bindDNPat, dataStr)
+	passwords := uniqueMatches(bindPasswordPat, dataStr)
+
+	for _, server := range servers {
+		for _, bindDN := range bindDNs {
+			for _, password := range passwords {
+				// Skip passwords that are templated or read from elsewhere.
+				if strings.HasPrefix(password, "$") || strings.HasPrefix(password, "{") || strings.HasPrefix(password, "%") {
+					continue
+				}
+
+				s1 := detectors.Result{
+					DetectorType: detectorspb.DetectorType_LDAP,
+					Raw:          []byte(fmt.Sprintf("%s\t%s\t%s", server, bindDN, password)),
+					Redacted:     fmt.Sprintf("%s:%s", bindDN, redactedPassword),
+					ExtraData: map[string]string{
+						"bind_dn": bindDN,
+					},
+				}
+				if server != "" {
+					s1.Redacted += "@" + server
+					s1.ExtraData["server"] = server
+				}
+
+				if verify && server != "" {
+					verified, err := verifyBind(ctx, server, bindDN, password)
+					if err == nil {
+						s1.Verified = verified
+					}
+				}
+
+				if !s1.Verified && detectors.IsKnownFalsePositive(password, detectors.DefaultFalsePositives, false) {
+					continue
+				}
+
+				results = append(results, s1)
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// verifyBind binds to the server with the credentials. The server must be
+// explicitly allowed by the network policy.
+func verifyBind(ctx context.Context, server, bindDN, password string) (bool, error) {
+	u, err := url.Parse(server)
+	if err != nil {
+		return false, err
+	}
+	useTLS := strings.EqualFold(u.Scheme, "ldaps")
+	port := u.Port()
+	if port == "" {
+		port = "389"
+		if useTLS {
+			port = "636"
+		}
+	}
+	return simpleBind(ctx, net.JoinHostPort(u.Hostname(), port), useTLS, bindDN, password)
+}
+
+// uniqueMatches returns the distinct first groups of the pattern's matches.
+func uniqueMatches(pat *regexp.Regexp, data string) []string {
+	var matches []string
+	seen := map[string]struct{}{}
+	for _, match := range pat.FindAllStringSubmatch(data, -1) {
+		if _, ok := seen[match[1]]; ok {
+			continue
+		}
+		seen[match[1]] = struct{}{}
+		matches = append(matches, match[1])
+	}
+	return matches
+}
@@ -0,0 +1,212 @@
+package ldap
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// newTestServer starts an LDAP server that accepts binds of cn=admin with
+// password Tr0ub4dor3x, and returns its URL.
+func newTestServer(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, message, err := readElement(bufio.NewReader(conn))
+				if err != nil {
+					return
+				}
+				_, _, rest, _ := nextElement(message)
+				_, bind, _, _ := nextElement(rest)
+				_, _, rest, _ = nextElement(bind)
+				_, name, rest, _ := nextElement(rest)
+				_, password, _, _ := nextElement(rest)
+
+				code := byte(resultInvalidCredentials)
+				if bytes.Equal(name, []byte("cn=admin,dc=corp,dc=example")) && bytes.Equal(password, []byte("Tr0ub4dor3x")) {
+					code = resultSuccess
+				}
+				// Long form length, as sent by Active Directory.
+				conn.Write([]byte{0x30, 0x84, 0x00, 0x00, 0x00, 0x0c, 0x02, 0x01, 0x01, 0x61, 0x07, 0x0a, 0x01, code, 0x04, 0x00, 0x04, 0x00})
+			}()
+		}
+	}()
+	return "ldap://" + listener.Addr().String()
+}
+
+func TestLDAP_FromChunk(t *testing.T) {
+	server := newTestServer(t)
+	allowed := common.WithNetworkPolicy(context.Background(), &common.NetworkPolicy{Allow: []string{"127.0.0.1"}})
+
+	type args struct {
+		ctx    context.Context
+		data   []byte
+		verify bool
+	}
+	tests := []struct {
+		name    string
+		s       Scanner
+		args    args
+		want    []detectors.Result
+		wantErr bool
+	}{
+		{
+			name: "found ldapsearch command line, verified",
+			s:    Scanner{},
+			args: args{
+				ctx:    allowed,
+				data:   []byte(fmt.Sprintf(`ldapsearch -x -H %s -D "cn=admin,dc=corp,dc=example" -w Tr0ub4dor3x -b dc=corp,dc=example`, server)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_LDAP,
+					Verified:     true,
+					Redacted:     fmt.Sprintf("cn=admin,dc=corp,dc=example:********@%s", server),
+					ExtraData: map[string]string{
+						"bind_dn": "cn=admin,dc=corp,dc=example",
+						"server":  server,
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "found config, unverified",
+			s:    Scanner{},
+			args: args{
+				ctx:    allowed,
+				data:   []byte(fmt.Sprintf("ldap_uri = %s\nldap_default_bind_dn = cn=reader,ou=services,dc=corp,dc=example\nldap_default_authtok = Qv7mRt2xLp9s\n", server)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_LDAP,
+					Verified:     false,
+					Redacted:     fmt.Sprintf("cn=reader,ou=services,dc=corp,dc=example:********@%s", server),
+					ExtraData: map[string]string{
+						"bind_dn": "cn=reader,ou=services,dc=corp,dc=example",
+						"server":  server,
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "found, server not allowed by network policy",
+			s:    Scanner{},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte(fmt.Sprintf(`ldapsearch -x -H %s -D "cn=admin,dc=corp,dc=example" -w Tr0ub4dor3x`, server)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_LDAP,
+					Verified:     false,
+					Redacted:     fmt.Sprintf("cn=admin,dc=corp,dc=example:********@%s", server),
+					ExtraData: map[string]string{
+						"bind_dn": "cn=admin,dc=corp,dc=example",
+						"server":  server,
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "found without server",
+			s:    Scanner{},
+			args: args{
+				ctx:    allowed,
+				data:   []byte("ldap.bindDN=uid=jenkins,ou=people,dc=corp,dc=example\nldap.bindPassword=Hx4nWq8zKe2j"),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_LDAP,
+					Verified:     false,
+					Redacted:     "uid=jenkins,ou=people,dc=corp,dc=example:********",
+					ExtraData: map[string]string{
+						"bind_dn": "uid=jenkins,ou=people,dc=corp,dc=example",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "templated password",
+			s:    Scanner{},
+			args: args{
+				ctx:    allowed,
+				data:   []byte(fmt.Sprintf("url: %s\nbind_dn: cn=admin,dc=corp,dc=example\nbind_password: ${LDAP_PASSWORD}", server)),
+				verify: true,
+			},
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name: "not found",
+			s:    Scanner{},
+			args: args{
+				ctx:    allowed,
+				data:   []byte("ldap://ldap.corp.example"),
+				verify: true,
+			},
+			want:    nil,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.s.FromData(tt.args.ctx, tt.args.verify, tt.args.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LDAP.FromData() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			for i := range got {
+				if len(got[i].Raw) == 0 {
+					t.Fatalf("no raw secret present: \n %+v", got[i])
+				}
+				got[i].Raw = nil
+			}
+			if diff := pretty.Compare(got, tt.want); diff != "" {
+				t.Errorf("LDAP.FromData() %s diff: (-got +want)\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func BenchmarkFromData(benchmark *testing.B) {
+	ctx := context.Background()
+	s := Scanner{}
+	for name, data := range detectors.MustGetBenchmarkData() {
+		benchmark.Run(name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				_, err := s.FromData(ctx, false, data)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -2,9 +2,12 @@ package mailgun
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
@@ -12,11 +15,16 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 )
 
-type Scanner struct{}
+type Scanner struct {
+	// endpoint overrides the Mailgun API address in tests.
+	endpoint string
+}
 
 // Ensure the Scanner satisfies the interface at compile time.
 var _ detectors.Detector = (*Scanner)(nil)
 
+const defaultEndpoint = "https://api.mailgun.net"
+
 var (
 	client = common.SaneHttpClient()
 
@@ -46,23 +54,13 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 		}
 
 		if verify {
-			req, err := http.NewRequestWithContext(ctx, "GET", "https://api.mailgun.net/v3/domains", nil)
-			if err != nil {
+			domains, verified, err := s.listDomains(ctx, resMatch)
+			if err == nil && verified {
+				s1.Verified = true
+				s1.ExtraData = domainInfo(domains)
+			} else if detectors.IsKnownFalsePositive(resMatch, detectors.DefaultFalsePositives, true) {
 				continue
 			}
-			req.Header.Add("Authorization", fmt.Sprintf("Basic %s", resMatch))
-			res, err := client.Do(req)
-			if err == nil {
-				defer res.Body.Close()
-				if res.StatusCode >= 200 && res.StatusCode < 300 {
-					s1.Verified = true
-				} else {
-					if detectors.IsKnownFalsePositive(resMatch, detectors.DefaultFalsePositives, true) {
-						continue
-					}
-				}
-			}
-
 		}
 
 		results = append(results, s1)
@@ -70,3 +68,66 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 
 	return detectors.CleanResults(results), nil
 }
+
+type domainsResponse struct {
+	TotalCount int `json:"total_count"`
+	Items      []struct {
+		Name  string `json:"name"`
+		State string `json:"state"`
+		Type  string `json:"type"`
+	} `json:"items"`
+}
+
+// listDomains lists the account's domains, which only private API keys can
+// do. It reports false if the key is rejected.
+func (s Scanner) listDomains(ctx context.Context, key string) (*domainsResponse, bool, error) {
+	endpoint := s.endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint+"/v3/domains", nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Basic %s", key))
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, false, nil
+	}
+	domains := &domainsResponse{}
+	if err := json.NewDecoder(res.Body).Decode(domains); err != nil {
+		return nil, false, err
+	}
+	return domains, true, nil
+}
+
+// domainInfo reports the domains a key can send from. Accounts with only a
+// sandbox domain are on the free trial and can only mail authorized
+// recipients.
+func domainInfo(domains *domainsResponse) map[string]string {
+	var active []string
+	sandboxOnly := true
+	for _, domain := range domains.Items {
+		if domain.Type != "sandbox" {
+			sandboxOnly = false
+		}
+		if domain.State == "active" {
+			active = append(active, domain.Name)
+		}
+	}
+	sort.Strings(active)
+	extra := map[string]string{
+		"key_type":     "private",
+		"domain_count": strconv.Itoa(domains.TotalCount),
+		"sandbox_only": strconv.FormatBool(sandboxOnly),
+	}
+	if len(active) > 0 {
+		extra["sending_domains"] = strings.Join(active, ",")
+	}
+	return extra
+}
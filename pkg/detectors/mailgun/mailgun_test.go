@@ -3,6 +3,8 @@ package mailgun
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -93,6 +95,9 @@ func TestMailgun_FromChunk(t *testing.T) {
 					t.Fatalf("no raw secret present: \n %+v", got[i])
 				}
 				got[i].Raw = nil
+				// The account details depend on the test account; they're
+				// covered by TestMailgun_Verify.
+				got[i].ExtraData = nil
 			}
 			if diff := pretty.Compare(got, tt.want); diff != "" {
 				t.Errorf("Mailgun.FromData() %s  diff: (-got +want)\n%s", tt.name, diff)
@@ -101,6 +106,71 @@ func TestMailgun_FromChunk(t *testing.T) {
 	}
 }
 
+func TestMailgun_Verify(t *testing.T) {
+	const (
+		key        = "YXBpOjNmOWMyZTdhNDFmMDhiNWQzNmUxYTRjN2YyYjhkMDVlLTlhYjJjZDRlLTdmMWUyYTNi"
+		revokedKey = "YXBpOjBkNGI4ZTYyYTE3ZjNjOTViMmU4ZDFhNmY0YzA3YjM5LTJmOWU4YjFjLTRhNmQwZTNj"
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3/domains" || r.Header.Get("Authorization") != "Basic "+key {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"total_count":3,"items":[
+			{"name":"sandbox123.mailgun.org","state":"active","type":"sandbox"},
+			{"name":"mg.example.com","state":"active","type":"custom"},
+			{"name":"new.example.com","state":"unverified","type":"custom"}]}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		key  string
+		want []detectors.Result
+	}{
+		{
+			name: "verified",
+			key:  key,
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_Mailgun,
+					Verified:     true,
+					ExtraData: map[string]string{
+						"key_type":        "private",
+						"domain_count":    "3",
+						"sandbox_only":    "false",
+						"sending_domains": "mg.example.com,sandbox123.mailgun.org",
+					},
+				},
+			},
+		},
+		{
+			name: "revoked",
+			key:  revokedKey,
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_Mailgun,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Scanner{endpoint: server.URL}
+			got, err := s.FromData(context.Background(), true, []byte("mailgun auth: "+tt.key))
+			if err != nil {
+				t.Fatalf("Mailgun.FromData() error = %v", err)
+			}
+			for i := range got {
+				got[i].Raw = nil
+			}
+			if diff := pretty.Compare(got, tt.want); diff != "" {
+				t.Errorf("Mailgun.FromData() %s diff: (-got +want)\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
 func BenchmarkFromData(benchmark *testing.B) {
 	ctx := context.Background()
 	s := Scanner{}
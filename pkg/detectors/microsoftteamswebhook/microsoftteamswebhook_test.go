@@ -3,6 +3,11 @@ package microsoftteamswebhook
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -100,6 +105,43 @@ func TestMicrosoftTeamsWebhook_FromChunk(t *testing.T) {
 	}
 }
 
+func TestMicrosoftTeamsWebhook_VerifyDoesNotPost(t *testing.T) {
+	webhook := "https://contoso.webhook.office.com/webhookb2/1a2b3c4d-1a2b-1a2b-1a2b-1a2b3c4d5e6f@1a2b3c4d-1a2b-1a2b-1a2b-1a2b3c4d5e6f/IncomingWebhook/0123456789abcdef0123456789abcdef/1a2b3c4d-1a2b-1a2b-1a2b-1a2b3c4d5e6f"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.TrimSpace(string(body)) != `{"text":""}` {
+			t.Errorf("verification sent a message body: %s", body)
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "Bad payload received by generic incoming webhook. Text is required.")
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	defaultTransport := client.Transport
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		req.URL.Scheme = serverURL.Scheme
+		req.URL.Host = serverURL.Host
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	defer func() { client.Transport = defaultTransport }()
+
+	got, err := Scanner{}.FromData(context.Background(), true, []byte(webhook))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || !got[0].Verified {
+		t.Errorf("expected one verified result, got %+v", got)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
 func BenchmarkFromData(benchmark *testing.B) {
 	ctx := context.Background()
 	s := Scanner{}
@@ -1,7 +1,9 @@
 package newrelicpersonalapikey
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -12,7 +14,11 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 )
 
-type Scanner struct{}
+type Scanner struct {
+	// BaseURL is the API keys are verified against. When empty, it's the
+	// API of the region the keys belong to.
+	BaseURL string
+}
 
 // Ensure the Scanner satisfies the interface at compile time.
 var _ detectors.Detector = (*Scanner)(nil)
@@ -22,19 +28,37 @@ var (
 
 	// Make sure that your group is surrounded in boundary characters such as below to reduce false positives.
 	keyPat = regexp.MustCompile(detectors.PrefixRegex([]string{"newrelic"}) + `\b([A-Za-z0-9_\.]{4}-[A-Za-z0-9_\.]{42})\b`)
+	// User keys are prefixed, so they don't need the keyword.
+	userKeyPat = regexp.MustCompile(`\b(NRAK-[A-Z0-9]{27})\b`)
+
+	// Keys only work in the region of their account. EU accounts send data to
+	// eu01.nr-data.net and use the api.eu.newrelic.com API.
+	euRegionPat = regexp.MustCompile(`(?i)\beu01\.nr-data\.net\b|\bapi\.eu\.newrelic\.com\b|NEW_RELIC_REGION["']?\s*[:=]\s*["']?eu\b`)
+
+	regionURLs = map[string]string{
+		"us": "https://api.newrelic.com",
+		"eu": "https://api.eu.newrelic.com",
+	}
 )
 
 // Keywords are used for efficiently pre-filtering chunks.
 // Use identifiers in the secret preferably, or the provider name.
 func (s Scanner) Keywords() []string {
-	return []string{"newrelic"}
+	return []string{"newrelic", "NRAK-"}
 }
 
 // FromData will find and optionally verify NewRelicPersonalApiKey secrets in a given set of bytes.
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
 
+	// Keys found without a region are tried in both.
+	regions := []string{"us", "eu"}
+	if euRegionPat.MatchString(dataStr) {
+		regions = []string{"eu"}
+	}
+
 	matches := keyPat.FindAllStringSubmatch(dataStr, -1)
+	matches = append(matches, userKeyPat.FindAllStringSubmatch(dataStr, -1)...)
 
 	for _, match := range matches {
 		if len(match) != 2 {
@@ -48,21 +72,27 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 		}
 
 		if verify {
-			req, err := http.NewRequestWithContext(ctx, "GET", "https://api.newrelic.com/v2/users.json", nil)
-			if err != nil {
-				continue
+			for _, region := range regions {
+				baseURL := s.BaseURL
+				if baseURL == "" {
+					baseURL = regionURLs[region]
+				}
+				verified, err := verifyRegion(ctx, baseURL, resMatch)
+				if err != nil || !verified {
+					continue
+				}
+				s1.Verified = true
+				s1.ExtraData = map[string]string{"region": region}
+				if email, accounts, err := actor(ctx, baseURL, resMatch); err == nil {
+					s1.ExtraData["user"] = email
+					s1.ExtraData["accounts"] = strings.Join(accounts, ",")
+				}
+				break
 			}
-			req.Header.Add("X-Api-Key", fmt.Sprintf("%s", resMatch))
-			res, err := client.Do(req)
-			if err == nil {
-				defer res.Body.Close()
-				if res.StatusCode >= 200 && res.StatusCode < 300 {
-					s1.Verified = true
-				} else {
-					// This function will check false positives for common test words, but also it will make sure the key appears 'random' enough to be a real key.
-					if detectors.IsKnownFalsePositive(resMatch, detectors.DefaultFalsePositives, false) {
-						continue
-					}
+			if !s1.Verified {
+				// This function will check false positives for common test words, but also it will make sure the key appears 'random' enough to be a real key.
+				if detectors.IsKnownFalsePositive(resMatch, detectors.DefaultFalsePositives, false) {
+					continue
 				}
 			}
 		}
@@ -72,3 +102,62 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 
 	return detectors.CleanResults(results), nil
 }
+
+func verifyRegion(ctx context.Context, baseURL, key string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/v2/users.json", nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Add("X-Api-Key", key)
+	res, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	return res.StatusCode >= 200 && res.StatusCode < 300, nil
+}
+
+// actor returns the email of the key's user and the names of the accounts
+// it can access, from NerdGraph.
+func actor(ctx context.Context, baseURL, key string) (string, []string, error) {
+	query, err := json.Marshal(map[string]string{"query": "{ actor { user { email } accounts { id name } } }"})
+	if err != nil {
+		return "", nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/graphql", bytes.NewReader(query))
+	if err != nil {
+		return "", nil, err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("API-Key", key)
+	res, err := client.Do(req)
+	if err != nil {
+		return "", nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	var body struct {
+		Data struct {
+			Actor struct {
+				User struct {
+					Email string `json:"email"`
+				} `json:"user"`
+				Accounts []struct {
+					ID   int    `json:"id"`
+					Name string `json:"name"`
+				} `json:"accounts"`
+			} `json:"actor"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", nil, err
+	}
+	var accounts []string
+	for _, account := range body.Data.Actor.Accounts {
+		accounts = append(accounts, fmt.Sprintf("%s (%d)", account.Name, account.ID))
+	}
+	return body.Data.Actor.User.Email, accounts, nil
+}
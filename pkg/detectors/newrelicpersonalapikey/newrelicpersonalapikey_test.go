@@ -3,6 +3,8 @@ package newrelicpersonalapikey
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -92,6 +94,9 @@ func TestNewRelicPersonalApiKey_FromChunk(t *testing.T) {
 					t.Fatalf("no raw secret present: \n %+v", got[i])
 				}
 				got[i].Raw = nil
+				// ExtraData depends on the test account, and is covered by
+				// TestNewRelicPersonalApiKey_ExtraData.
+				got[i].ExtraData = nil
 			}
 			if diff := pretty.Compare(got, tt.want); diff != "" {
 				t.Errorf("NewRelicPersonalApiKey.FromData() %s diff: (-got +want)\n%s", tt.name, diff)
@@ -100,6 +105,49 @@ func TestNewRelicPersonalApiKey_FromChunk(t *testing.T) {
 	}
 }
 
+func TestNewRelicPersonalApiKey_ExtraData(t *testing.T) {
+	const key = "NRAK-7QX2M9KD4TB1ZC8W5NF3HJ6LRPA"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != key && r.Header.Get("API-Key") != key {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/users.json":
+			fmt.Fprint(w, `{"users":[]}`)
+		case "/graphql":
+			fmt.Fprint(w, `{"data":{"actor":{"user":{"email":"ops@acme.io"},"accounts":[{"id":3141592,"name":"Acme EU"}]}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	s := Scanner{BaseURL: server.URL}
+	data := fmt.Sprintf("NEW_RELIC_REGION=eu\nNEW_RELIC_API_KEY=%s\n", key)
+	got, err := s.FromData(context.Background(), true, []byte(data))
+	if err != nil {
+		t.Fatalf("NewRelicPersonalApiKey.FromData() error = %v", err)
+	}
+	for i := range got {
+		got[i].Raw = nil
+	}
+	want := []detectors.Result{
+		{
+			DetectorType: detectorspb.DetectorType_NewRelicPersonalApiKey,
+			Verified:     true,
+			ExtraData: map[string]string{
+				"region":   "eu",
+				"user":     "ops@acme.io",
+				"accounts": "Acme EU (3141592)",
+			},
+		},
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("NewRelicPersonalApiKey.FromData() diff: (-got +want)\n%s", diff)
+	}
+}
+
 func BenchmarkFromData(benchmark *testing.B) {
 	ctx := context.Background()
 	s := Scanner{}
@@ -0,0 +1,101 @@
+package npmtoken
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+type Scanner struct {
+	// Registry is the registry tokens are verified against. When empty,
+	// the public npm registry is used.
+	Registry string
+}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+const defaultRegistry = "https://registry.npmjs.org"
+
+var (
+	client = common.SaneHttpClient()
+
+	// Granular and automation tokens are "npm_" followed by 36 base62 characters.
+	keyPat = regexp.MustCompile(`\b(npm_[A-Za-z0-9]{36})\b`)
+)
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"npm_"}
+}
+
+// FromData will find and optionally verify NpmToken secrets in a given set of bytes.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	registry := s.Registry
+	if registry == "" {
+		registry = defaultRegistry
+	}
+
+	for _, match := range keyPat.FindAllStringSubmatch(dataStr, -1) {
+		resMatch := strings.TrimSpace(match[1])
+
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_NpmToken,
+			Raw:          []byte(resMatch),
+		}
+
+		if verify {
+			username, verified, err := whoami(ctx, registry, resMatch)
+			if err == nil && verified {
+				s1.Verified = true
+				s1.ExtraData = map[string]string{"username": username}
+			}
+		}
+
+		if !s1.Verified && detectors.IsKnownFalsePositive(resMatch, detectors.DefaultFalsePositives, false) {
+			continue
+		}
+
+		results = append(results, s1)
+	}
+
+	return results, nil
+}
+
+// whoami returns the user a token belongs to. The endpoint is read-only.
+func whoami(ctx context.Context, registry, token string) (string, bool, error) {
+	url := fmt.Sprintf("%s/-/whoami", strings.TrimSuffix(registry, "/"))
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", false, err
+	}
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	res, err := client.Do(req)
+	if err != nil {
+		return "", false, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", false, nil
+	}
+
+	var body struct {
+		Username string `json:"username"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", false, err
+	}
+	return body.Username, true, nil
+}
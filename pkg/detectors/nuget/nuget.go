@@ -0,0 +1,48 @@
+package nuget
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+type Scanner struct{}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+var (
+	// nuget.org API keys are "oy2" followed by 43 lowercase base32 characters.
+	keyPat = regexp.MustCompile(`\b(oy2[a-z0-9]{43})\b`)
+)
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"nuget"}
+}
+
+// FromData will find NuGet secrets in a given set of bytes. They're never
+// verified: nuget.org has no read-only endpoint that authenticates API keys,
+// and the push and unlist endpoints that do would change packages of keys
+// that are valid.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	for _, match := range keyPat.FindAllStringSubmatch(dataStr, -1) {
+		resMatch := strings.TrimSpace(match[1])
+		if detectors.IsKnownFalsePositive(resMatch, detectors.DefaultFalsePositives, false) {
+			continue
+		}
+
+		results = append(results, detectors.Result{
+			DetectorType: detectorspb.DetectorType_NuGet,
+			Raw:          []byte(resMatch),
+		})
+	}
+
+	return results, nil
+}
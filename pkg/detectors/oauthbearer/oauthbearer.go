@@ -0,0 +1,375 @@
+package oauthbearer
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Scanner finds OAuth 2.0 bearer tokens from any provider. Tokens are verified
+// against the issuer found in the token itself, when it's a JWT, or in URLs
+// near it, using the endpoints from the issuer's OpenID Connect discovery
+// document: userinfo with the token itself, or token introspection when a
+// client ID and secret are found too. Issuers found in chunks are only
+// contacted if they're well-known providers or the network policy explicitly
+// allows them, since whoever wrote the chunk chose them.
+type Scanner struct {
+	// Issuer is the issuer tokens are verified against. When empty, issuers
+	// are discovered from the chunk.
+	Issuer string
+}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+// SafeVerification returns false because verifying sends tokens to issuers
+// named in the scanned data.
+func (s Scanner) SafeVerification() bool { return false }
+
+var (
+	client = common.SaneHttpClient()
+
+	bearerPat      = regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]{20,}=*)`)
+	accessTokenPat = regexp.MustCompile(`(?i)access[_-]?token["']?\s*[:=]\s*["']?([A-Za-z0-9\-._~+/]{20,}=*)`)
+	clientIDPat    = regexp.MustCompile(`(?i)client[_-]?id["']?\s*[:=]\s*["']?([A-Za-z0-9\-._~]{8,})`)
+	clientSecPat   = regexp.MustCompile(`(?i)client[_-]?secret["']?\s*[:=]\s*["']?([A-Za-z0-9\-._~]{16,})`)
+	// Only HTTPS URLs are candidate issuers, so tokens aren't sent in the clear.
+	urlPat = regexp.MustCompile(`https://[A-Za-z0-9.\-]+(?::[0-9]{1,5})?(?:/[A-Za-z0-9._~\-/]*)?`)
+	// endpointPat matches the OAuth endpoints issuer URLs are often followed by.
+	endpointPat = regexp.MustCompile(`(?i)/(?:\.well-known/.*|(?:v1/|protocol/openid-connect/)?(?:authorize|token|userinfo|introspect|keys|certs|logout)/?)$`)
+)
+
+// knownIssuers are the domains of the OAuth providers that issuers found in
+// chunks may be on. Their subdomains are allowed too.
+var knownIssuers = []string{
+	"accounts.google.com",
+	"login.microsoftonline.com",
+	"okta.com",
+	"oktapreview.com",
+	"okta-emea.com",
+	"auth0.com",
+	"onelogin.com",
+	"gitlab.com",
+	"login.salesforce.com",
+}
+
+// maxIssuers is the most issuers a token is verified against, so chunks full
+// of URLs don't make it send a request to each of them.
+const maxIssuers = 4
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"bearer", "access_token", "accesstoken", "access-token"}
+}
+
+// FromData will find and optionally verify OAuth bearer tokens in a given set of bytes.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	seen := map[string]bool{}
+	var tokens []string
+	for _, pat := range []*regexp.Regexp{bearerPat, accessTokenPat} {
+		for _, match := range pat.FindAllStringSubmatch(dataStr, -1) {
+			token := match[1]
+			if seen[token] || !detectors.HasDigit(token) {
+				continue
+			}
+			seen[token] = true
+			tokens = append(tokens, token)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	var clientID, clientSecret string
+	if match := clientIDPat.FindStringSubmatch(dataStr); match != nil {
+		clientID = match[1]
+	}
+	if match := clientSecPat.FindStringSubmatch(dataStr); match != nil {
+		clientSecret = match[1]
+	}
+	nearby := s.issuers(ctx, dataStr)
+	discovered := map[string]*discovery{}
+
+	for _, token := range tokens {
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_OAuthBearerToken,
+			Raw:          []byte(token),
+		}
+
+		jwt := jwtClaims(token)
+		if jwt != nil && jwt.Expiry > 0 {
+			s1.ExtraData = map[string]string{
+				"expires_at": time.Unix(jwt.Expiry, 0).UTC().Format(time.RFC3339),
+			}
+		}
+		expired := jwt != nil && jwt.Expiry > 0 && time.Unix(jwt.Expiry, 0).Before(time.Now())
+
+		if verify && !expired {
+			issuers := nearby
+			if jwt != nil && s.Issuer == "" && allowedIssuer(ctx, jwt.Issuer) {
+				issuers = append([]string{strings.TrimSuffix(jwt.Issuer, "/")}, nearby...)
+			}
+			for _, issuer := range limit(issuers, maxIssuers) {
+				doc, ok := discovered[issuer]
+				if !ok {
+					doc, _ = discover(ctx, issuer)
+					discovered[issuer] = doc
+				}
+				if doc == nil {
+					continue
+				}
+				extra, verified := doc.verify(ctx, token, clientID, clientSecret)
+				if verified {
+					s1.Verified = true
+					if s1.ExtraData == nil {
+						s1.ExtraData = map[string]string{}
+					}
+					s1.ExtraData["issuer"] = issuer
+					for k, v := range extra {
+						s1.ExtraData[k] = v
+					}
+					break
+				}
+			}
+		}
+
+		if !s1.Verified && detectors.IsKnownFalsePositive(token, detectors.DefaultFalsePositives, false) {
+			continue
+		}
+
+		results = append(results, s1)
+	}
+
+	return results, nil
+}
+
+// issuers returns the candidate issuers for tokens in data: the Scanner's
+// Issuer if it's set, and otherwise the allowed HTTPS URLs in data, without
+// any OAuth endpoint they end with, followed by their origins.
+func (s Scanner) issuers(ctx context.Context, data string) []string {
+	if s.Issuer != "" {
+		return []string{strings.TrimSuffix(s.Issuer, "/")}
+	}
+	var issuers, origins []string
+	seen := map[string]bool{}
+	add := func(list *[]string, issuer string) {
+		if !seen[issuer] {
+			seen[issuer] = true
+			*list = append(*list, issuer)
+		}
+	}
+	for _, match := range urlPat.FindAllString(data, -1) {
+		u, err := url.Parse(match)
+		if err != nil || u.Host == "" || !allowedIssuer(ctx, match) {
+			continue
+		}
+		path := strings.TrimSuffix(endpointPat.ReplaceAllString(u.Path, ""), "/")
+		if path != "" {
+			add(&issuers, "https://"+u.Host+path)
+		}
+		add(&origins, "https://"+u.Host)
+		// Okta's default authorization server isn't at the origin.
+		if strings.Contains(u.Host, ".okta") && path == "" {
+			add(&origins, "https://"+u.Host+"/oauth2/default")
+		}
+	}
+	return append(issuers, origins...)
+}
+
+// allowedIssuer reports whether issuer, found in a chunk, may be contacted:
+// it must be an HTTPS URL on a known provider's domain, or on a host the
+// network policy in ctx explicitly allows.
+func allowedIssuer(ctx context.Context, issuer string) bool {
+	u, err := url.Parse(issuer)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, domain := range knownIssuers {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return common.NetworkPolicyFromContext(ctx).AllowsExplicitly(host)
+}
+
+func limit(list []string, n int) []string {
+	if len(list) > n {
+		return list[:n]
+	}
+	return list
+}
+
+type claims struct {
+	Issuer string `json:"iss"`
+	Expiry int64  `json:"exp"`
+}
+
+// jwtClaims returns the claims of token if it's a JWT, or nil otherwise. The
+// signature isn't checked, since the issuer is only used to find where to
+// verify the token.
+func jwtClaims(token string) *claims {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil
+	}
+	c := &claims{}
+	if err := json.Unmarshal(payload, c); err != nil {
+		return nil
+	}
+	return c
+}
+
+// discovery is the part of an OpenID Connect discovery document needed to
+// verify tokens.
+type discovery struct {
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	IntrospectionEndpoint string `json:"introspection_endpoint"`
+}
+
+// discover fetches the discovery document of issuer, returning nil if it
+// doesn't have one. Endpoints that aren't HTTPS on the issuer's own origin are
+// dropped, so a discovery document can't send tokens elsewhere.
+func discover(ctx context.Context, issuer string) (*discovery, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, nil
+	}
+	doc := &discovery{}
+	if err := json.NewDecoder(res.Body).Decode(doc); err != nil {
+		return nil, err
+	}
+	if !sameOrigin(issuer, doc.UserinfoEndpoint) {
+		doc.UserinfoEndpoint = ""
+	}
+	if !sameOrigin(issuer, doc.IntrospectionEndpoint) {
+		doc.IntrospectionEndpoint = ""
+	}
+	if doc.UserinfoEndpoint == "" && doc.IntrospectionEndpoint == "" {
+		return nil, nil
+	}
+	return doc, nil
+}
+
+// sameOrigin reports whether endpoint is an HTTPS URL on issuer's origin.
+func sameOrigin(issuer, endpoint string) bool {
+	i, err := url.Parse(issuer)
+	if err != nil {
+		return false
+	}
+	e, err := url.Parse(endpoint)
+	if err != nil {
+		return false
+	}
+	return e.Scheme == "https" && i.Scheme == "https" && strings.EqualFold(e.Host, i.Host)
+}
+
+// verify checks whether token is active, with the userinfo endpoint or, when
+// there are client credentials, the introspection endpoint. Neither changes
+// the token. It returns what the issuer says about the token's owner.
+func (d *discovery) verify(ctx context.Context, token, clientID, clientSecret string) (map[string]string, bool) {
+	if d.UserinfoEndpoint != "" {
+		if extra, ok := userinfo(ctx, d.UserinfoEndpoint, token); ok {
+			return extra, true
+		}
+	}
+	if d.IntrospectionEndpoint != "" && clientID != "" && clientSecret != "" {
+		return introspect(ctx, d.IntrospectionEndpoint, token, clientID, clientSecret)
+	}
+	return nil, false
+}
+
+func userinfo(ctx context.Context, endpoint, token string) (map[string]string, bool) {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, false
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, false
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, false
+	}
+
+	var info struct {
+		Subject string `json:"sub"`
+		Email   string `json:"email"`
+	}
+	extra := map[string]string{"verified_with": "userinfo"}
+	if err := json.NewDecoder(res.Body).Decode(&info); err == nil {
+		if info.Subject != "" {
+			extra["subject"] = info.Subject
+		}
+		if info.Email != "" {
+			extra["email"] = info.Email
+		}
+	}
+	return extra, true
+}
+
+func introspect(ctx context.Context, endpoint, token, clientID, clientSecret string) (map[string]string, bool) {
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, false
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(clientID, clientSecret)
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, false
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, false
+	}
+
+	var info struct {
+		Active   bool   `json:"active"`
+		Scope    string `json:"scope"`
+		ClientID string `json:"client_id"`
+		Username string `json:"username"`
+		Subject  string `json:"sub"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&info); err != nil || !info.Active {
+		return nil, false
+	}
+	extra := map[string]string{"verified_with": "introspection"}
+	for k, v := range map[string]string{"scope": info.Scope, "client_id": info.ClientID, "username": info.Username, "subject": info.Subject} {
+		if v != "" {
+			extra[k] = v
+		}
+	}
+	return extra, true
+}
@@ -0,0 +1,149 @@
+package passwordmanagerexport
+
+import (
+	"context"
+	"encoding/csv"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Scanner finds exports of password manager vaults. An export holds every
+// credential in the vault, so findings are always critical. They can't be
+// verified.
+type Scanner struct{}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+// format is a kind of export, recognized by its header.
+type format struct {
+	name    string
+	manager string
+	header  *regexp.Regexp
+	// count returns the number of items following the header. It's nil for
+	// formats whose items can't be counted.
+	count func(data string) int
+}
+
+var (
+	onePIFSeparator = regexp.MustCompile(`\*\*\*5642bee8-a5ff-11dc-8314-0800200c9a66\*\*\*`)
+	bitwardenItem   = regexp.MustCompile(`"favorite"\s*:`)
+
+	formats = []format{
+		{
+			name:    "bitwarden_csv",
+			manager: "Bitwarden",
+			header:  regexp.MustCompile(`(?m)^(?:folder|collections),favorite,type,name,notes,fields,(?:reprompt,)?login_uri,login_username,login_password,login_totp\r?$`),
+			count:   countCSV,
+		},
+		{
+			name:    "bitwarden_json",
+			manager: "Bitwarden",
+			header:  regexp.MustCompile(`\{\s*"encrypted"\s*:\s*(?:true|false)\s*,`),
+			count:   countBitwardenJSON,
+		},
+		{
+			name:    "lastpass_csv",
+			manager: "LastPass",
+			header:  regexp.MustCompile(`(?m)^url,username,password,(?:totp,)?extra,name,grouping,fav\r?$`),
+			count:   countCSV,
+		},
+		{
+			name:    "1password_csv",
+			manager: "1Password",
+			header:  regexp.MustCompile(`(?m)^"?Title"?,"?Url"?,"?Username"?,"?Password"?,"?OTPAuth"?,"?Favorite"?,"?Archived"?,"?Tags"?,"?Notes"?\r?$`),
+			count:   countCSV,
+		},
+		{
+			name:    "1password_1pif",
+			manager: "1Password",
+			header:  onePIFSeparator,
+			count: func(data string) int {
+				// Each item is followed by the separator, including the one matched.
+				return len(onePIFSeparator.FindAllStringIndex(data, -1)) + 1
+			},
+		},
+		{
+			// 1PUX exports are zip files; the names of their entries are stored uncompressed.
+			name:    "1password_1pux",
+			manager: "1Password",
+			header:  regexp.MustCompile(`export\.attributes`),
+		},
+	}
+)
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"login_password", `"encrypted"`, "grouping,fav", "otpauth", "5642bee8-a5ff-11dc-8314-0800200c9a66", "export.attributes"}
+}
+
+// FromData will find password manager exports in a given set of bytes.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	for _, f := range formats {
+		loc := f.header.FindStringIndex(dataStr)
+		if loc == nil {
+			continue
+		}
+		header := dataStr[loc[0]:loc[1]]
+		if f.name == "1password_1pux" && !strings.Contains(dataStr, "export.data") {
+			continue
+		}
+
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_PasswordManagerExport,
+			Raw:          []byte(f.name + ":" + strings.TrimSpace(header)),
+			Redacted:     f.manager + " export",
+			ExtraData: map[string]string{
+				"manager":  f.manager,
+				"format":   f.name,
+				"severity": "critical",
+			},
+		}
+		count := f.count
+		if f.name == "bitwarden_json" && strings.Contains(header, "true") {
+			// Encrypted exports still need the export password to be read, and
+			// their items can't be counted.
+			s1.ExtraData["encrypted"] = "true"
+			s1.ExtraData["severity"] = "high"
+			count = nil
+		}
+		// Large exports span several chunks, so this counts the items in the
+		// chunk with the header.
+		if count != nil {
+			s1.ExtraData["items"] = strconv.Itoa(count(dataStr[loc[1]:]))
+		}
+
+		results = append(results, s1)
+	}
+
+	return results, nil
+}
+
+// countCSV counts the records after a CSV header.
+func countCSV(data string) int {
+	reader := csv.NewReader(strings.NewReader(strings.TrimLeft(data, "\r\n")))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = true
+	count := 0
+	for {
+		record, err := reader.Read()
+		if err != nil {
+			return count
+		}
+		if len(record) > 1 {
+			count++
+		}
+	}
+}
+
+func countBitwardenJSON(data string) int {
+	return len(bitwardenItem.FindAllStringIndex(data, -1))
+}
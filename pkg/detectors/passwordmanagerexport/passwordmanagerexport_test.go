@@ -0,0 +1,144 @@
+package passwordmanagerexport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+func TestPasswordManagerExport_FromChunk(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []detectors.Result
+	}{
+		{
+			name: "bitwarden csv",
+			data: "folder,favorite,type,name,notes,fields,reprompt,login_uri,login_username,login_password,login_totp\n" +
+				",,login,GitHub,,,0,https://github.com,octocat,hunter2,\n" +
+				"Work,1,login,Jira,\"multi\nline note\",,0,https://jira.example.com,admin,s3cr3t,\n",
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_PasswordManagerExport,
+					ExtraData:    map[string]string{"manager": "Bitwarden", "format": "bitwarden_csv", "severity": "critical", "items": "2"},
+				},
+			},
+		},
+		{
+			name: "bitwarden json",
+			data: `{
+  "encrypted": false,
+  "folders": [],
+  "items": [
+    {"id": "1", "type": 1, "name": "GitHub", "favorite": false, "login": {"username": "octocat", "password": "hunter2"}},
+    {"id": "2", "type": 2, "name": "Note", "favorite": true, "notes": "recovery codes"}
+  ]
+}`,
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_PasswordManagerExport,
+					ExtraData:    map[string]string{"manager": "Bitwarden", "format": "bitwarden_json", "severity": "critical", "items": "2"},
+				},
+			},
+		},
+		{
+			name: "encrypted bitwarden json",
+			data: `{"encrypted": true, "passwordProtected": true, "salt": "c2FsdA==", "data": "2.abc|def|ghi"}`,
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_PasswordManagerExport,
+					ExtraData:    map[string]string{"manager": "Bitwarden", "format": "bitwarden_json", "severity": "high", "encrypted": "true"},
+				},
+			},
+		},
+		{
+			name: "lastpass csv",
+			data: "url,username,password,totp,extra,name,grouping,fav\r\n" +
+				"https://example.com,alice,hunter2,,,Example,Personal,0\r\n",
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_PasswordManagerExport,
+					ExtraData:    map[string]string{"manager": "LastPass", "format": "lastpass_csv", "severity": "critical", "items": "1"},
+				},
+			},
+		},
+		{
+			name: "1password csv",
+			data: `"Title","Url","Username","Password","OTPAuth","Favorite","Archived","Tags","Notes"
+"AWS","https://console.aws.amazon.com","root","hunter2","","false","false","",""
+`,
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_PasswordManagerExport,
+					ExtraData:    map[string]string{"manager": "1Password", "format": "1password_csv", "severity": "critical", "items": "1"},
+				},
+			},
+		},
+		{
+			name: "1password 1pif",
+			data: `{"uuid":"a","title":"GitHub"}
+***5642bee8-a5ff-11dc-8314-0800200c9a66***
+{"uuid":"b","title":"AWS"}
+***5642bee8-a5ff-11dc-8314-0800200c9a66***
+`,
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_PasswordManagerExport,
+					ExtraData:    map[string]string{"manager": "1Password", "format": "1password_1pif", "severity": "critical", "items": "2"},
+				},
+			},
+		},
+		{
+			name: "1password 1pux",
+			data: "PK\x03\x04\x14\x00\x00\x00export.attributes\x00\x00PK\x03\x04export.data\x00",
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_PasswordManagerExport,
+					ExtraData:    map[string]string{"manager": "1Password", "format": "1password_1pux", "severity": "critical"},
+				},
+			},
+		},
+		{
+			name: "not an export",
+			data: "username,password\nalice,hunter2\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Scanner{}
+			got, err := s.FromData(context.Background(), false, []byte(tt.data))
+			if err != nil {
+				t.Fatalf("PasswordManagerExport.FromData() error = %v", err)
+			}
+			for i := range got {
+				if len(got[i].Raw) == 0 {
+					t.Fatalf("no raw secret present: \n %+v", got[i])
+				}
+				got[i].Raw = nil
+				got[i].Redacted = ""
+			}
+			if diff := pretty.Compare(got, tt.want); diff != "" {
+				t.Errorf("PasswordManagerExport.FromData() %s diff: (-got +want)\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func BenchmarkFromData(benchmark *testing.B) {
+	ctx := context.Background()
+	s := Scanner{}
+	for name, data := range detectors.MustGetBenchmarkData() {
+		benchmark.Run(name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				_, err := s.FromData(ctx, false, data)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
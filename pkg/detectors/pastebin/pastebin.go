@@ -32,6 +32,11 @@ func (s Scanner) Keywords() []string {
 	return []string{"pastebin"}
 }
 
+// SafeVerification returns false because verifying creates a paste.
+func (s Scanner) SafeVerification() bool {
+	return false
+}
+
 // FromData will find and optionally verify Pastebin secrets in a given set of bytes.
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
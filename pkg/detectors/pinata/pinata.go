@@ -31,6 +31,11 @@ func (s Scanner) Keywords() []string {
 	return []string{"pinata"}
 }
 
+// SafeVerification returns false because verifying pins a JSON object to IPFS.
+func (s Scanner) SafeVerification() bool {
+	return false
+}
+
 // FromData will find and optionally verify Pinata secrets in a given set of bytes.
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
@@ -51,7 +51,7 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 		if verify {
 			timeout := 15 * time.Second
 			client.Timeout = timeout
-			req, err := http.NewRequest("GET", "https://api.pipedream.com/v1/users/me", nil)
+			req, err := http.NewRequestWithContext(ctx, "GET", "https://api.pipedream.com/v1/users/me", nil)
 			if err != nil {
 				continue
 			}
@@ -44,6 +44,12 @@ func FingerprintPEMKey(in []byte) (string, error) {
 		return "", ErrNotSupported
 	}
 
+	return fingerprintPublicKey(pubKey)
+}
+
+// fingerprintPublicKey returns the hex encoded SHA-1 hash of the PKIX
+// encoding of pubKey, which is what fingerprints are looked up by.
+func fingerprintPublicKey(pubKey interface{}) (string, error) {
 	publickeyBytes, err := x509.MarshalPKIXPublicKey(pubKey)
 	if err != nil {
 		return "", err
@@ -0,0 +1,95 @@
+package privatekey
+
+import (
+	"crypto/dsa"
+	"encoding/base64"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	ErrInvalidPPK = errors.New("invalid PuTTY private key file")
+
+	// PuTTY private key files are "Header: value" lines, with the base64 key
+	// blobs following the Public-Lines and Private-Lines headers, and end
+	// with the MAC of the whole file.
+	ppkPat = regexp.MustCompile(`PuTTY-User-Key-File-[23]:[\s\S]*?Private-MAC:\s*[0-9a-fA-F]{40,64}`)
+)
+
+// ppkKey is a parsed PuTTY private key file.
+// https://the.earth.li/~sgtatham/putty/0.78/htmldoc/AppendixC.html
+type ppkKey struct {
+	// version is the file format version, "2" or "3".
+	version   string
+	algorithm string
+	// encryption is "none" or the cipher the private blob is encrypted with.
+	encryption string
+	comment    string
+	// publicBlob is the public key in SSH wire format. It's never encrypted.
+	publicBlob []byte
+}
+
+// encrypted reports whether the private key blob is passphrase protected.
+func (k *ppkKey) encrypted() bool {
+	return k.encryption != "none"
+}
+
+// parsePPK parses a normalized PuTTY private key file.
+func parsePPK(in string) (*ppkKey, error) {
+	key := &ppkKey{}
+	lines := strings.Split(in, "\n")
+	for i := 0; i < len(lines); i++ {
+		name, value, ok := strings.Cut(lines[i], ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch {
+		case strings.HasPrefix(name, "PuTTY-User-Key-File-"):
+			key.version = strings.TrimPrefix(name, "PuTTY-User-Key-File-")
+			key.algorithm = value
+		case name == "Encryption":
+			key.encryption = value
+		case name == "Comment":
+			key.comment = value
+		case name == "Public-Lines":
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 || i+n >= len(lines) {
+				return nil, ErrInvalidPPK
+			}
+			blob, err := base64.StdEncoding.DecodeString(strings.Join(lines[i+1:i+1+n], ""))
+			if err != nil {
+				return nil, ErrInvalidPPK
+			}
+			key.publicBlob = blob
+			i += n
+		}
+	}
+	if key.algorithm == "" || key.encryption == "" || len(key.publicBlob) == 0 {
+		return nil, ErrInvalidPPK
+	}
+	return key, nil
+}
+
+// fingerprintPPKKey fingerprints the public key of a PuTTY private key file.
+// Unlike PEM keys, encrypted keys don't need to be cracked first, because
+// their public key is stored in plaintext.
+func fingerprintPPKKey(key *ppkKey) (string, error) {
+	pub, err := ssh.ParsePublicKey(key.publicBlob)
+	if err != nil {
+		return "", err
+	}
+	cryptoPub, ok := pub.(ssh.CryptoPublicKey)
+	if !ok {
+		return "", ErrNotSupported
+	}
+	// No fingerprinting support for DSA, as with PEM keys.
+	if _, ok := cryptoPub.CryptoPublicKey().(*dsa.PublicKey); ok {
+		return "", ErrNotSupported
+	}
+	return fingerprintPublicKey(cryptoPub.CryptoPublicKey())
+}
@@ -0,0 +1,112 @@
+package privatekey
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+const (
+	ppkV3 = `PuTTY-User-Key-File-3: ssh-ed25519
+Encryption: none
+Comment: deploy@build-01
+Public-Lines: 2
+AAAAC3NzaC1lZDI1NTE5AAAAIDtqJ7zOtqQtYqOo0CpvDXNlMhV3HeJDpjrASKGL
+Wdop
+Private-Lines: 1
+AAAAIAcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8gISIjJCUm
+Private-MAC: 6f2b0c8a4d1e9f3a7b5c2d8e0f1a3b4c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a
+`
+	ppkV2Encrypted = `PuTTY-User-Key-File-2: ssh-ed25519
+Encryption: aes256-cbc
+Comment: eddsa-key-20230412
+Public-Lines: 2
+AAAAC3NzaC1lZDI1NTE5AAAAIDtqJ7zOtqQtYqOo0CpvDXNlMhV3HeJDpjrASKGL
+Wdop
+Private-Lines: 1
+ZGVmZ2hpamtsbW5vcHFyc3R1dnd4eXp7fH1+f4CBgoOEhYaHiImKi4yNjo+QkZKT
+Private-MAC: 9c1d3e5f7a2b4c6d8e0f1a2b3c4d5e6f7a8b9c0d
+`
+)
+
+func TestPrivatekey_FromChunk_PPK(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []detectors.Result
+	}{
+		{
+			name: "unencrypted v3 key",
+			data: ppkV3,
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_PrivateKey,
+					Redacted:     ppkV3[0:64],
+					ExtraData: map[string]string{
+						"format":    "ppk3",
+						"algorithm": "ssh-ed25519",
+						"encrypted": "false",
+						"comment":   "deploy@build-01",
+					},
+				},
+			},
+		},
+		{
+			name: "encrypted v2 key with Windows line endings",
+			data: "key = \"" + strings.ReplaceAll(ppkV2Encrypted, "\n", "\r\n") + "\"",
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_PrivateKey,
+					Redacted:     ppkV2Encrypted[0:64],
+					ExtraData: map[string]string{
+						"format":    "ppk2",
+						"algorithm": "ssh-ed25519",
+						"encrypted": "true",
+						"comment":   "eddsa-key-20230412",
+					},
+				},
+			},
+		},
+		{
+			name: "truncated key",
+			data: "PuTTY-User-Key-File-3: ssh-ed25519\nEncryption: none\nPrivate-MAC: 6f2b0c8a4d1e9f3a7b5c2d8e0f1a3b4c6d7e8f9a",
+			want: []detectors.Result{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Scanner{}.FromData(context.Background(), false, []byte(tt.data))
+			if err != nil {
+				t.Fatalf("Privatekey.FromData() error = %v", err)
+			}
+			for i := range got {
+				if len(got[i].Raw) == 0 {
+					t.Fatal("no raw secret present")
+				}
+				got[i].Raw = nil
+			}
+			if diff := pretty.Compare(got, tt.want); diff != "" {
+				t.Errorf("Privatekey.FromData() %s diff: (-got +want)\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func Test_fingerprintPPKKey(t *testing.T) {
+	key, err := parsePPK(normalize(ppkV2Encrypted))
+	if err != nil {
+		t.Fatalf("parsePPK() error = %v", err)
+	}
+	got, err := fingerprintPPKKey(key)
+	if err != nil {
+		t.Fatalf("fingerprintPPKKey() error = %v", err)
+	}
+	if want := "fc907b627d84b3e73585dc214f0db6152afd1f85"; got != want {
+		t.Errorf("fingerprintPPKKey() = %s, want %s", got, want)
+	}
+}
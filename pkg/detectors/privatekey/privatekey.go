@@ -6,11 +6,12 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"strconv"
 	"time"
 
-	log "github.com/sirupsen/logrus"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 )
 
@@ -30,7 +31,7 @@ var (
 // Keywords are used for efficiently pre-filtering chunks.
 // Use identifiers in the secret preferably, or the provider name.
 func (s Scanner) Keywords() []string {
-	return []string{"private key"}
+	return []string{"private key", "PuTTY-User-Key-File"}
 }
 
 // FromData will find and optionally verify Privatekey secrets in a given set of bytes.
@@ -60,15 +61,36 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) ([]dete
 		}
 
 		if verify {
-			data, err := lookupFingerprint(fingerprint, s.IncludeExpired)
-			if err == nil {
-				secret.StructuredData = data
-				if data != nil {
-					secret.Verified = true
-				}
-			} else {
-				log.Warn(err)
-			}
+			s.verify(ctx, &secret, fingerprint)
+		}
+
+		results = append(results, secret)
+	}
+
+	for _, match := range ppkPat.FindAllString(dataStr, -1) {
+
+		token := normalize(match)
+
+		key, err := parsePPK(token)
+		if err != nil {
+			continue
+		}
+
+		secret := detectors.Result{
+			DetectorType: detectorspb.DetectorType_PrivateKey,
+			Raw:          []byte(token),
+			Redacted:     token[0:64],
+			ExtraData: map[string]string{
+				"format":    "ppk" + key.version,
+				"algorithm": key.algorithm,
+				"encrypted": strconv.FormatBool(key.encrypted()),
+				"comment":   key.comment,
+			},
+		}
+
+		fingerprint, err := fingerprintPPKKey(key)
+		if err == nil && verify {
+			s.verify(ctx, &secret, fingerprint)
 		}
 
 		results = append(results, secret)
@@ -77,8 +99,21 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) ([]dete
 	return results, nil
 }
 
-func lookupFingerprint(publicKeyFingerprintInHex string, includeExpired bool) (data *detectorspb.StructuredData, err error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("https://keychecker.trufflesecurity.com/fingerprint/%s", publicKeyFingerprintInHex), nil)
+// verify looks up where the key with the fingerprint is used.
+func (s Scanner) verify(ctx context.Context, secret *detectors.Result, fingerprint string) {
+	data, err := lookupFingerprint(ctx, fingerprint, s.IncludeExpired)
+	if err == nil {
+		secret.StructuredData = data
+		if data != nil {
+			secret.Verified = true
+		}
+	} else {
+		log.FromContext(ctx).Error(err, "could not look up private key fingerprint")
+	}
+}
+
+func lookupFingerprint(ctx context.Context, publicKeyFingerprintInHex string, includeExpired bool) (data *detectorspb.StructuredData, err error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("https://keychecker.trufflesecurity.com/fingerprint/%s", publicKeyFingerprintInHex), nil)
 	if err != nil {
 		return
 	}
@@ -39,6 +39,11 @@ func (s Scanner) Keywords() []string {
 	return []string{"pusher"}
 }
 
+// SafeVerification returns false because verifying triggers an event on a channel.
+func (s Scanner) SafeVerification() bool {
+	return false
+}
+
 const (
 	auth_version = "1.0"
 )
@@ -0,0 +1,165 @@
+package pypi
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+type Scanner struct {
+	// UploadURL is the upload endpoint tokens are verified against. When
+	// empty, it's PyPI's or TestPyPI's, depending on the token.
+	UploadURL string
+}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+var (
+	client = common.SaneHttpClient()
+
+	// Tokens are macaroons whose location, pypi.org or test.pypi.org, makes
+	// up the start of their base64 encoding.
+	keyPat = regexp.MustCompile(`\b(pypi-AgE(?:IcHlwaS5vcmc|NdGVzdC5weXBpLm9yZw)[A-Za-z0-9\-_]{50,})`)
+
+	// Caveats restricting tokens to projects, in the original JSON format and
+	// the newer list format.
+	permissionsCaveatPat = regexp.MustCompile(`\{"permissions":\s*("user"|\{"projects":\s*\[[^\]]*\]\})`)
+	projectsCaveatPat    = regexp.MustCompile(`\[1,\s*(\[[^\]]*\])\]`)
+)
+
+var repositories = map[string]struct{ name, uploadURL string }{
+	"pypi-AgEIcHlwaS5vcmc":        {"pypi.org", "https://upload.pypi.org/legacy/"},
+	"pypi-AgENdGVzdC5weXBpLm9yZw": {"test.pypi.org", "https://test.pypi.org/legacy/"},
+}
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"pypi-AgE"}
+}
+
+// FromData will find and optionally verify PyPI secrets in a given set of bytes.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	for _, match := range keyPat.FindAllStringSubmatch(dataStr, -1) {
+		resMatch := strings.TrimSpace(match[1])
+
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_PyPI,
+			Raw:          []byte(resMatch),
+			ExtraData:    map[string]string{},
+		}
+		var uploadURL string
+		for prefix, repo := range repositories {
+			if strings.HasPrefix(resMatch, prefix) {
+				s1.ExtraData["repository"] = repo.name
+				uploadURL = repo.uploadURL
+			}
+		}
+		if s.UploadURL != "" {
+			uploadURL = s.UploadURL
+		}
+		if projects, ok := tokenProjects(resMatch); ok {
+			s1.ExtraData["scope"] = "projects"
+			s1.ExtraData["projects"] = strings.Join(projects, ",")
+		} else {
+			s1.ExtraData["scope"] = "user"
+		}
+
+		if verify {
+			verified, err := verifyToken(ctx, uploadURL, resMatch)
+			if err == nil && verified {
+				s1.Verified = true
+			}
+		}
+
+		if !s1.Verified && detectors.IsKnownFalsePositive(resMatch, detectors.DefaultFalsePositives, false) {
+			continue
+		}
+
+		results = append(results, s1)
+	}
+
+	return results, nil
+}
+
+// tokenProjects returns the projects token is restricted to, if it's
+// restricted to any. Tokens without a restriction can upload to every project
+// of their user.
+func tokenProjects(token string) ([]string, bool) {
+	macaroon, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(strings.TrimPrefix(token, "pypi-"), "="))
+	if err != nil {
+		return nil, false
+	}
+	if match := permissionsCaveatPat.FindSubmatch(macaroon); match != nil {
+		var permissions struct {
+			Projects []string `json:"projects"`
+		}
+		if string(match[1]) == `"user"` || json.Unmarshal(match[1], &permissions) != nil {
+			return nil, false
+		}
+		return permissions.Projects, true
+	}
+	if match := projectsCaveatPat.FindSubmatch(macaroon); match != nil {
+		var projects []string
+		if json.Unmarshal(match[1], &projects) != nil {
+			return nil, false
+		}
+		return projects, true
+	}
+	return nil, false
+}
+
+// verifyToken sends an upload request without a distribution file, which is
+// rejected before anything is uploaded. Invalid tokens are rejected with
+// 403 Forbidden first, and valid ones get 400 Bad Request for the missing
+// file, or 403 Forbidden if they can't upload to the project.
+func verifyToken(ctx context.Context, uploadURL, token string) (bool, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for field, value := range map[string]string{":action": "file_upload", "protocol_version": "1"} {
+		if err := form.WriteField(field, value); err != nil {
+			return false, err
+		}
+	}
+	if err := form.Close(); err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", uploadURL, &body)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.SetBasicAuth("__token__", token)
+	res, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusBadRequest:
+		return true, nil
+	case http.StatusForbidden:
+		msg, err := io.ReadAll(io.LimitReader(res.Body, 4096))
+		if err != nil {
+			return false, err
+		}
+		return strings.Contains(string(msg), "isn't allowed to upload"), nil
+	default:
+		return false, nil
+	}
+}
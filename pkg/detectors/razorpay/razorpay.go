@@ -2,10 +2,12 @@ package razorpay
 
 import (
 	"context"
+	"net/http"
 	"regexp"
 
-	log "github.com/sirupsen/logrus"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 
 	"github.com/razorpay/razorpay-go"
@@ -55,6 +57,16 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 			}
 			//we only want the secret, not its surrounding info - grabbing capture groups
 			for _, secMatch := range secMatches {
+				// The SDK's requests don't carry ctx, so a verification dry
+				// run has to intercept them here.
+				req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.razorpay.com/v1/orders", nil)
+				if err != nil {
+					continue
+				}
+				req.SetBasicAuth(token, secMatch[1])
+				if common.DryRunFromContext(ctx).Intercept(req) {
+					continue
+				}
 				client := razorpay.NewClient(token, secMatch[1])
 				resp, err := client.Order.All(nil, nil)
 				//TODO Error handling is broken in SDK, fixed by https://github.com/razorpay/razorpay-go/pull/23
@@ -63,7 +75,7 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 					continue
 				}
 				if err != nil {
-					log.Debugf("Error verifying likely razorpay key/secret combo: %v", err)
+					log.FromContext(ctx).V(1).Info("error verifying likely razorpay key/secret combo", "error", err)
 					continue
 				}
 				//TODO debug with responses. could still be invalid at this stage
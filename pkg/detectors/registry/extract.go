@@ -0,0 +1,258 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// Extract returns the patterns of the detector packages in dir, by package
+// directory, from the regexp.MustCompile calls that package-level variables
+// are set with. The patterns are evaluated from the source, so they must be
+// built from string literals, constants, concatenation, fmt.Sprintf,
+// strings.Join and detectors.PrefixRegex. The positions of patterns built
+// otherwise are returned as unresolved.
+func Extract(dir string) (patterns map[string][]Pattern, unresolved []string, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, errors.WrapPrefix(err, "could not read detectors", 0)
+	}
+	patterns = map[string][]Pattern{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		pkgPatterns, pkgUnresolved, err := extractPackage(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(pkgPatterns) > 0 {
+			patterns[entry.Name()] = pkgPatterns
+		}
+		unresolved = append(unresolved, pkgUnresolved...)
+	}
+	return patterns, unresolved, nil
+}
+
+// MarshalPatterns returns patterns as they're kept in the registry.
+func MarshalPatterns(patterns map[string][]Pattern) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	// Patterns are kept as they're written, with their < and > unescaped.
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(patterns); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// extractPackage returns the patterns of the package in dir.
+func extractPackage(dir string) ([]Pattern, []string, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, nil, errors.WrapPrefix(err, fmt.Sprintf("could not parse %s", dir), 0)
+	}
+
+	var patterns []Pattern
+	var unresolved []string
+	for _, pkg := range pkgs {
+		var files []*ast.File
+		names := make([]string, 0, len(pkg.Files))
+		for name := range pkg.Files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			files = append(files, pkg.Files[name])
+		}
+
+		e := &evaluator{values: map[string]ast.Expr{}}
+		forEachValue(files, func(name string, value ast.Expr) {
+			e.values[name] = value
+		})
+		forEachValue(files, func(name string, value ast.Expr) {
+			var calls []*ast.CallExpr
+			ast.Inspect(value, func(n ast.Node) bool {
+				if call, ok := n.(*ast.CallExpr); ok && isMustCompile(call) {
+					calls = append(calls, call)
+					return false
+				}
+				return true
+			})
+			for i, call := range calls {
+				patternName := name
+				if len(calls) > 1 {
+					patternName = fmt.Sprintf("%s[%d]", name, i)
+				}
+				regex, ok := e.eval(call.Args[0], 0)
+				if ok {
+					_, err := regexp.Compile(regex)
+					ok = err == nil
+				}
+				if !ok {
+					unresolved = append(unresolved, fset.Position(call.Pos()).String())
+					continue
+				}
+				patterns = append(patterns, Pattern{Name: patternName, Regex: regex})
+			}
+		})
+	}
+	return patterns, unresolved, nil
+}
+
+// forEachValue calls f with each package-level constant and variable in
+// files that's given a value.
+func forEachValue(files []*ast.File, f func(name string, value ast.Expr)) {
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || (gen.Tok != token.VAR && gen.Tok != token.CONST) {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				value, ok := spec.(*ast.ValueSpec)
+				if !ok {
+					continue
+				}
+				for i, name := range value.Names {
+					if i < len(value.Values) && name.Name != "_" {
+						f(name.Name, value.Values[i])
+					}
+				}
+			}
+		}
+	}
+}
+
+// isMustCompile reports whether call is regexp.MustCompile with an argument.
+func isMustCompile(call *ast.CallExpr) bool {
+	return isCall(call, "regexp", "MustCompile") && len(call.Args) == 1
+}
+
+// isCall reports whether call calls pkg.name.
+func isCall(call *ast.CallExpr, pkg, name string) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	return ok && x.Name == pkg && sel.Sel.Name == name
+}
+
+// maxEvalDepth is how many constants an evaluator follows to the value of an
+// expression, so that one set from itself can't loop forever.
+const maxEvalDepth = 16
+
+// evaluator evaluates the strings that a package's patterns are built from.
+type evaluator struct {
+	// values are the expressions the package-level constants and variables
+	// are set to.
+	values map[string]ast.Expr
+}
+
+// eval returns the string that expr evaluates to, and whether it could be
+// evaluated.
+func (e *evaluator) eval(expr ast.Expr, depth int) (string, bool) {
+	if depth > maxEvalDepth {
+		return "", false
+	}
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		if expr.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(expr.Value)
+		return s, err == nil
+	case *ast.ParenExpr:
+		return e.eval(expr.X, depth)
+	case *ast.BinaryExpr:
+		if expr.Op != token.ADD {
+			return "", false
+		}
+		x, ok := e.eval(expr.X, depth)
+		if !ok {
+			return "", false
+		}
+		y, ok := e.eval(expr.Y, depth)
+		return x + y, ok
+	case *ast.Ident:
+		value, ok := e.values[expr.Name]
+		if !ok {
+			return "", false
+		}
+		return e.eval(value, depth+1)
+	case *ast.CallExpr:
+		switch {
+		case isCall(expr, "detectors", "PrefixRegex") && len(expr.Args) == 1:
+			keywords, ok := e.evalStrings(expr.Args[0], depth)
+			if !ok {
+				return "", false
+			}
+			return detectors.PrefixRegex(keywords), true
+		case isCall(expr, "strings", "Join") && len(expr.Args) == 2:
+			elems, ok := e.evalStrings(expr.Args[0], depth)
+			if !ok {
+				return "", false
+			}
+			sep, ok := e.eval(expr.Args[1], depth)
+			return strings.Join(elems, sep), ok
+		case isCall(expr, "fmt", "Sprintf") && len(expr.Args) > 0 && !expr.Ellipsis.IsValid():
+			args, ok := e.evalAll(expr.Args, depth)
+			if !ok {
+				return "", false
+			}
+			format := args[0]
+			values := make([]interface{}, 0, len(args)-1)
+			for _, arg := range args[1:] {
+				values = append(values, arg)
+			}
+			return fmt.Sprintf(format, values...), true
+		}
+	}
+	return "", false
+}
+
+// evalStrings returns the strings in the []string that expr evaluates to.
+func (e *evaluator) evalStrings(expr ast.Expr, depth int) ([]string, bool) {
+	switch expr := expr.(type) {
+	case *ast.CompositeLit:
+		return e.evalAll(expr.Elts, depth)
+	case *ast.Ident:
+		value, ok := e.values[expr.Name]
+		if !ok || depth > maxEvalDepth {
+			return nil, false
+		}
+		return e.evalStrings(value, depth+1)
+	}
+	return nil, false
+}
+
+// evalAll returns the strings that exprs evaluate to.
+func (e *evaluator) evalAll(exprs []ast.Expr, depth int) ([]string, bool) {
+	values := make([]string, 0, len(exprs))
+	for _, expr := range exprs {
+		value, ok := e.eval(expr, depth)
+		if !ok {
+			return nil, false
+		}
+		values = append(values, value)
+	}
+	return values, true
+}
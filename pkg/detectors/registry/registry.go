@@ -0,0 +1,95 @@
+// Package registry describes the detectors' patterns, keywords and metadata
+// in a machine-readable form, so other tools, like IDE plugins and gateway
+// filters, can match secrets the way TruffleHog does without its code.
+package registry
+
+import (
+	_ "embed"
+	"encoding/json"
+	"path"
+	"reflect"
+	"sort"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// patternsJSON holds the patterns of each detector package, as Extract
+// returns them. Run make patterns to update it after changing detectors.
+//
+//go:embed patterns.json
+var patternsJSON []byte
+
+// Pattern is a regular expression a detector matches secrets with.
+type Pattern struct {
+	// Name is the name of the variable the detector keeps the pattern in.
+	Name string `json:"name"`
+	// Regex is the pattern, in the RE2 syntax of Go's regexp package. Most
+	// detectors capture the secret in the pattern's first group.
+	Regex string `json:"regex"`
+}
+
+// Detector describes a detector.
+type Detector struct {
+	// Name is the name of the detector's package, which its logs use.
+	Name string `json:"name"`
+	// Type is the detector type of the results it finds, if it's known.
+	Type string `json:"type,omitempty"`
+	// Keywords are the words, ignoring case, that data must contain for the
+	// detector to scan it.
+	Keywords []string `json:"keywords"`
+	// Patterns are the patterns the detector matches secrets with. Detectors
+	// that match secrets without regular expressions, or build their
+	// patterns at run time, have none.
+	Patterns []Pattern `json:"patterns"`
+	// SafeVerification is whether verifying the detector's results has no
+	// side effects on the secret owner's account.
+	SafeVerification bool `json:"safe_verification"`
+}
+
+// Registry describes every detector of a version of TruffleHog.
+type Registry struct {
+	Version   string     `json:"version"`
+	Detectors []Detector `json:"detectors"`
+}
+
+// Export describes ds, sorted by name.
+func Export(version string, ds []detectors.Detector) (*Registry, error) {
+	var patterns map[string][]Pattern
+	if err := json.Unmarshal(patternsJSON, &patterns); err != nil {
+		return nil, errors.WrapPrefix(err, "could not read detector patterns", 0)
+	}
+	r := &Registry{Version: version, Detectors: make([]Detector, 0, len(ds))}
+	for _, d := range ds {
+		name := packageName(d)
+		described := Detector{
+			Name:             name,
+			Keywords:         d.Keywords(),
+			Patterns:         patterns[name],
+			SafeVerification: detectors.HasSafeVerification(d),
+		}
+		if detectorType, err := detectors.ParseDetectorType(name); err == nil {
+			described.Type = detectorType.String()
+		}
+		if described.Keywords == nil {
+			described.Keywords = []string{}
+		}
+		if described.Patterns == nil {
+			described.Patterns = []Pattern{}
+		}
+		r.Detectors = append(r.Detectors, described)
+	}
+	sort.SliceStable(r.Detectors, func(i, j int) bool { return r.Detectors[i].Name < r.Detectors[j].Name })
+	return r, nil
+}
+
+// packageName returns the name of the directory of the package that
+// implements d.
+func packageName(d detectors.Detector) string {
+	t := reflect.TypeOf(d)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return path.Base(t.PkgPath())
+}
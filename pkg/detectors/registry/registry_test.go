@@ -0,0 +1,58 @@
+package registry
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors/github"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors/mailgun"
+)
+
+func TestPatternsUpToDate(t *testing.T) {
+	patterns, unresolved, err := Extract("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pos := range unresolved {
+		t.Errorf("could not evaluate the pattern at %s", pos)
+	}
+	got, err := MarshalPatterns(patterns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, patternsJSON) {
+		t.Error("patterns.json is out of date with the detectors, run make patterns")
+	}
+}
+
+func TestExport(t *testing.T) {
+	r, err := Export("v3.0.0", []detectors.Detector{mailgun.Scanner{}, github.Scanner{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Registry{
+		Version: "v3.0.0",
+		Detectors: []Detector{
+			{
+				Name:             "github",
+				Type:             "Github",
+				Keywords:         []string{"ghp_", "gho_", "ghu_", "ghs_", "ghr_"},
+				Patterns:         []Pattern{{Name: "keyPat", Regex: `\b((?:ghp|gho|ghu|ghs|ghr)_[a-zA-Z0-9]{36,255})\b`}},
+				SafeVerification: true,
+			},
+			{
+				Name:             "mailgun",
+				Type:             "Mailgun",
+				Keywords:         mailgun.Scanner{}.Keywords(),
+				Patterns:         []Pattern{{Name: "keyPat", Regex: detectors.PrefixRegex([]string{"mailgun"}) + `\b([a-zA-Z-0-9]{72})\b`}},
+				SafeVerification: true,
+			},
+		},
+	}
+	if diff := pretty.Compare(r, want); diff != "" {
+		t.Errorf("Export() diff: (-got +want)\n%s", diff)
+	}
+}
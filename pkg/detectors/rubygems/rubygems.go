@@ -2,6 +2,7 @@ package rubygems
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"regexp"
 	"strings"
@@ -20,7 +21,7 @@ var (
 	client = common.SaneHttpClient()
 
 	// Make sure that your group is surrounded in boundary characters such as below to reduce false positives.
-	keyPat = regexp.MustCompile(`\b(rubygems_[a-zA0-9]{48})\b`)
+	keyPat = regexp.MustCompile(`\b(rubygems_[a-f0-9]{48})\b`)
 )
 
 // Keywords are used for efficiently pre-filtering chunks.
@@ -58,6 +59,17 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 				defer res.Body.Close()
 				if res.StatusCode >= 200 && res.StatusCode < 300 {
 					s1.Verified = true
+					// The gems the key's owner can push are the ones a leak exposes.
+					var gems []struct {
+						Name string `json:"name"`
+					}
+					if err := json.NewDecoder(res.Body).Decode(&gems); err == nil && len(gems) > 0 {
+						names := make([]string, 0, len(gems))
+						for _, gem := range gems {
+							names = append(names, gem.Name)
+						}
+						s1.ExtraData = map[string]string{"gems": strings.Join(names, ",")}
+					}
 				} else {
 					// This function will check false positives for common test words, but also it will make sure the key appears 'random' enough to be a real key.
 					if detectors.IsKnownFalsePositive(resMatch, detectors.DefaultFalsePositives, true) {
@@ -29,6 +29,11 @@ func (s Scanner) Keywords() []string {
 	return []string{"satismeter"}
 }
 
+// SafeVerification returns false because verifying tracks a user.
+func (s Scanner) SafeVerification() bool {
+	return false
+}
+
 // FromData will find and optionally verify SatismeterWritekey secrets in a given set of bytes.
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
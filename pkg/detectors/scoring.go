@@ -0,0 +1,69 @@
+package detectors
+
+import (
+	"math"
+	"strconv"
+)
+
+// FalsePositiveScoreKey is the ExtraData key results' false positive scores are
+// recorded under.
+const FalsePositiveScoreKey = "false_positive_score"
+
+// Scorer estimates how likely results are to be false positives, from a model
+// or heuristics, so they can be filtered or sorted by how much attention they
+// deserve.
+type Scorer interface {
+	// Score returns the likelihood, from 0 to 1, that r is a false positive.
+	// data is the decoded chunk r was found in.
+	Score(r *ResultWithMetadata, data []byte) float64
+}
+
+// SetFalsePositiveScore records score in r's ExtraData, clamped to [0, 1].
+func SetFalsePositiveScore(r *ResultWithMetadata, score float64) {
+	switch {
+	case score < 0 || math.IsNaN(score):
+		score = 0
+	case score > 1:
+		score = 1
+	}
+	if r.ExtraData == nil {
+		r.ExtraData = map[string]string{}
+	}
+	r.ExtraData[FalsePositiveScoreKey] = strconv.FormatFloat(score, 'f', 2, 64)
+}
+
+// FalsePositiveScore returns r's false positive score, and whether it was
+// scored.
+func FalsePositiveScore(r *ResultWithMetadata) (float64, bool) {
+	s, ok := r.ExtraData[FalsePositiveScoreKey]
+	if !ok {
+		return 0, false
+	}
+	score, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return score, true
+}
+
+// PathTagKey is the ExtraData key results' path tags are recorded under.
+const PathTagKey = "path_tag"
+
+// PathTagger is an optional interface for Scorers to tag results by the kind
+// of file they're in, like "test" or "docs", so they can be told apart from
+// results in code that runs.
+type PathTagger interface {
+	// PathTag returns the tag of the file r is in, or "" if it has none.
+	PathTag(r *ResultWithMetadata) string
+}
+
+// SetPathTag records tag in r's ExtraData, if it isn't empty.
+func SetPathTag(r *ResultWithMetadata, tag string) {
+	if tag == "" {
+		return
+	}
+	if r.ExtraData == nil {
+		r.ExtraData = map[string]string{}
+	}
+	r.ExtraData[PathTagKey] = tag
+}
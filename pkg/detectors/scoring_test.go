@@ -0,0 +1,46 @@
+package detectors
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSetFalsePositiveScore(t *testing.T) {
+	tests := []struct {
+		score float64
+		want  string
+	}{
+		{score: 0.256, want: "0.26"},
+		{score: 1.5, want: "1.00"},
+		{score: -1, want: "0.00"},
+		{score: math.NaN(), want: "0.00"},
+	}
+	for _, tt := range tests {
+		r := &ResultWithMetadata{}
+		SetFalsePositiveScore(r, tt.score)
+		if got := r.ExtraData[FalsePositiveScoreKey]; got != tt.want {
+			t.Errorf("SetFalsePositiveScore(%v) recorded %q, want %q", tt.score, got, tt.want)
+		}
+	}
+
+	r := &ResultWithMetadata{}
+	if _, ok := FalsePositiveScore(r); ok {
+		t.Error("FalsePositiveScore() of an unscored result is ok")
+	}
+	SetFalsePositiveScore(r, 0.5)
+	if got, ok := FalsePositiveScore(r); !ok || got != 0.5 {
+		t.Errorf("FalsePositiveScore() = %v, %v, want 0.5, true", got, ok)
+	}
+}
+
+func TestSetPathTag(t *testing.T) {
+	r := &ResultWithMetadata{}
+	SetPathTag(r, "")
+	if r.ExtraData != nil {
+		t.Errorf("SetPathTag() with no tag recorded %v", r.ExtraData)
+	}
+	SetPathTag(r, "test")
+	if got := r.ExtraData[PathTagKey]; got != "test" {
+		t.Errorf("SetPathTag() recorded %q, want %q", got, "test")
+	}
+}
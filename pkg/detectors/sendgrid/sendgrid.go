@@ -2,9 +2,12 @@ package sendgrid
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
@@ -13,17 +16,35 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 )
 
-type Scanner struct{}
+type Scanner struct {
+	// endpoint overrides the SendGrid API address in tests.
+	endpoint string
+}
 
 // Ensure the Scanner satisfies the interface at compile time.
 var _ detectors.Detector = (*Scanner)(nil)
 
+const defaultEndpoint = "https://api.sendgrid.com"
+
 var (
 	client = common.SaneHttpClient()
 
 	keyPat = regexp.MustCompile(detectors.PrefixRegex([]string{"sendgrid"}) + `(SG\.[\w\-_]{20,24}\.[\w\-_]{39,50})\b`)
 )
 
+type scopesResponse struct {
+	Scopes []string `json:"scopes"`
+}
+
+type accountResponse struct {
+	Type string `json:"type"`
+}
+
+type domainResponse struct {
+	Domain string `json:"domain"`
+	Valid  bool   `json:"valid"`
+}
+
 // Keywords are used for efficiently pre-filtering chunks.
 // Use identifiers in the secret preferably, or the provider name.
 func (s Scanner) Keywords() []string {
@@ -42,42 +63,99 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 		}
 		res := strings.TrimSpace(match[1])
 
-		s := detectors.Result{
+		s1 := detectors.Result{
 			DetectorType: detectorspb.DetectorType_SendGrid,
 			Raw:          []byte(res),
 		}
 
 		if verify {
-			// there are a few endpoints we can check, but templates seems the least sensitive.
-			// 403 will be issued if the scope is wrong but the key is correct
-			baseURL := "https://api.sendgrid.com/v3/templates"
-
-			// test `read_user` scope
-			req, err := http.NewRequestWithContext(ctx, "GET", baseURL, nil)
-			if err != nil {
-				continue
-			}
-			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", res))
-			req.Header.Add("Content-Type", "application/json")
-			res, err := client.Do(req)
-			if err == nil {
-				res.Body.Close() // The request body is unused.
-
-				// 200 means good key and has `templates` scope
-				// 403 means good key but not the right scope
-				// 401 is bad key
-				if res.StatusCode == http.StatusOK || res.StatusCode == http.StatusForbidden {
-					s.Verified = true
-				}
+			// Every key can list its own scopes, so this verifies keys
+			// regardless of what they're allowed to do.
+			var scopes scopesResponse
+			verified, err := s.get(ctx, res, "/v3/scopes", &scopes)
+			if err == nil && verified {
+				s1.Verified = true
+				s1.ExtraData = s.enrich(ctx, res, scopes.Scopes)
 			}
 		}
 
-		if !s.Verified && detectors.IsKnownFalsePositive(string(s.Raw), detectors.DefaultFalsePositives, true) {
+		if !s1.Verified && detectors.IsKnownFalsePositive(string(s1.Raw), detectors.DefaultFalsePositives, true) {
 			continue
 		}
 
-		results = append(results, s)
+		results = append(results, s1)
 	}
 
 	return
 }
+
+// enrich describes what a verified key can do, and reads the plan and sending
+// domains if the key is allowed to.
+func (s Scanner) enrich(ctx context.Context, key string, scopes []string) map[string]string {
+	has := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		has[scope] = true
+	}
+
+	// Full access keys can create more keys; restricted keys are limited to
+	// the scopes they were given.
+	access := "restricted"
+	if has["api_keys.create"] {
+		access = "full"
+	}
+	extra := map[string]string{
+		"access":   access,
+		"scopes":   strconv.Itoa(len(scopes)),
+		"can_send": strconv.FormatBool(has["mail.send"]),
+	}
+
+	if has["user.account.read"] {
+		var account accountResponse
+		if ok, err := s.get(ctx, key, "/v3/user/account", &account); err == nil && ok && account.Type != "" {
+			extra["plan"] = account.Type
+		}
+	}
+	if has["whitelabel.read"] {
+		var domains []domainResponse
+		if ok, err := s.get(ctx, key, "/v3/whitelabel/domains", &domains); err == nil && ok {
+			var verified []string
+			for _, domain := range domains {
+				if domain.Valid {
+					verified = append(verified, domain.Domain)
+				}
+			}
+			sort.Strings(verified)
+			if len(verified) > 0 {
+				extra["sending_domains"] = strings.Join(verified, ",")
+			}
+		}
+	}
+	return extra
+}
+
+// get reads a resource into v. It reports false if the key is rejected.
+func (s Scanner) get(ctx context.Context, key, path string, v interface{}) (bool, error) {
+	endpoint := s.endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint+path, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", key))
+	req.Header.Add("Content-Type", "application/json")
+	res, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return false, nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
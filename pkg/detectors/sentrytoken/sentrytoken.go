@@ -2,8 +2,11 @@ package sentrytoken
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strings"
 
@@ -17,24 +20,42 @@ type Scanner struct{}
 // Ensure the Scanner satisfies the interface at compile time.
 var _ detectors.Detector = (*Scanner)(nil)
 
+const defaultURL = "https://sentry.io"
+
 var (
 	client = common.SaneHttpClient()
 
 	// Make sure that your group is surrounded in boundary characters such as below to reduce false positives.
 	keyPat = regexp.MustCompile(detectors.PrefixRegex([]string{"sentry"}) + `\b([a-f0-9]{64})\b`)
+	// User auth tokens have been prefixed since Sentry 23.x.
+	userTokenPat = regexp.MustCompile(`\b(sntryu_[a-f0-9]{64})\b`)
+	// Organization auth tokens embed the URL and organization they're for in
+	// a base64 encoded JSON payload.
+	orgTokenPat = regexp.MustCompile(`\b(sntrys_(eyJ[A-Za-z0-9+/]+={0,2})_[A-Za-z0-9+/]{43})`)
+
+	// Tokens are verified against the Sentry instance found next to them,
+	// configured for sentry-cli with SENTRY_URL or in a DSN.
+	urlPat = regexp.MustCompile(`(?i)SENTRY_URL["']?\s*[:=]\s*["']?(https?://[^\s"'/]+)`)
+	dsnPat = regexp.MustCompile(`\b(https?)://[a-f0-9]{32}(?::[a-f0-9]{32})?@([a-z0-9.-]+(?::\d+)?)/\d+`)
+	// sentry.io DSNs are for the ingest subdomains of a region.
+	saasIngestPat = regexp.MustCompile(`^o\d+\.ingest\.(?:([a-z]{2})\.)?sentry\.io$`)
 )
 
 // Keywords are used for efficiently pre-filtering chunks.
 // Use identifiers in the secret preferably, or the provider name.
 func (s Scanner) Keywords() []string {
-	return []string{"sentry"}
+	return []string{"sentry", "sntryu_", "sntrys_"}
 }
 
 // FromData will find and optionally verify SentryToken secrets in a given set of bytes.
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
 
+	urls := instanceURLs(ctx, dataStr)
+
 	matches := keyPat.FindAllStringSubmatch(dataStr, -1)
+	matches = append(matches, userTokenPat.FindAllStringSubmatch(dataStr, -1)...)
+	orgMatches := orgTokenPat.FindAllStringSubmatch(dataStr, -1)
 
 	for _, match := range matches {
 		if len(match) != 2 {
@@ -47,22 +68,22 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 		}
 
 		if verify {
-			req, err := http.NewRequestWithContext(ctx, "GET", "https://sentry.io/api/0/projects/", nil)
-			if err != nil {
-				continue
+			for _, instance := range urls {
+				orgs, verified, err := organizations(ctx, instance, resMatch)
+				if err != nil || !verified {
+					continue
+				}
+				s1.Verified = true
+				s1.ExtraData = map[string]string{
+					"url":           instance,
+					"organizations": strings.Join(orgs, ","),
+				}
+				break
 			}
-			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", resMatch))
-			// req.Header.Add("Cookie", "sc=g2ceDPcsBwPLGXugHUv0coGBi09TQYKqmtp0u4JYdIvOTFd28yVvn1yiuXgmJJa8; session=.eJwNyE0OwiAQQGGsPwtP4kqoGQa4gUdgZ2CGiaZJCUqjGxOPbnfve7_h29QpHpVSvbw61To9StvEwzre9TkVbkPcr7jN5dPbNl7X1jUt_a4vLpEHgUycQSgFZ0YBRkGxnthxwIyBAgdngcF4YxBssiMjekGv2245_wHyySMG:1m0J5S:FjpRlBVuFx577UQp6GcSGWTq0uk")
-			res, err := client.Do(req)
-			if err == nil {
-				defer res.Body.Close()
-				if res.StatusCode >= 200 && res.StatusCode < 300 {
-					s1.Verified = true
-				} else {
-					// This function will check false positives for common test words, but also it will make sure the key appears 'random' enough to be a real key.
-					if detectors.IsKnownFalsePositive(resMatch, detectors.DefaultFalsePositives, true) {
-						continue
-					}
+			if !s1.Verified {
+				// This function will check false positives for common test words, but also it will make sure the key appears 'random' enough to be a real key.
+				if detectors.IsKnownFalsePositive(resMatch, detectors.DefaultFalsePositives, true) {
+					continue
 				}
 			}
 		}
@@ -70,5 +91,151 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 		results = append(results, s1)
 	}
 
+	for _, match := range orgMatches {
+		payload, err := decodeOrgTokenPayload(match[2])
+		if err != nil {
+			continue
+		}
+		instance := payload.RegionURL
+		if instance == "" {
+			instance = payload.URL
+		}
+
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_SentryToken,
+			Raw:          []byte(match[1]),
+			ExtraData: map[string]string{
+				"url":           instance,
+				"organizations": payload.Org,
+			},
+		}
+
+		// The URL is whatever the token's payload says, so it's held to the
+		// same rule as the instances found next to tokens.
+		if verify && allowedInstance(ctx, instance) {
+			verified, err := verifyOrgToken(ctx, instance, payload.Org, match[1])
+			if err == nil && verified {
+				s1.Verified = true
+			}
+		}
+
+		results = append(results, s1)
+	}
+
 	return detectors.CleanResults(results), nil
 }
+
+// instanceURLs returns the Sentry instances tokens in data may be for,
+// ending with sentry.io. Instances that aren't allowed are left out.
+func instanceURLs(ctx context.Context, data string) []string {
+	var urls []string
+	seen := map[string]struct{}{}
+	add := func(instance string) {
+		if _, ok := seen[instance]; ok || !allowedInstance(ctx, instance) {
+			return
+		}
+		seen[instance] = struct{}{}
+		urls = append(urls, instance)
+	}
+
+	for _, match := range urlPat.FindAllStringSubmatch(data, -1) {
+		add(match[1])
+	}
+	for _, match := range dsnPat.FindAllStringSubmatch(data, -1) {
+		scheme, host := match[1], strings.ToLower(match[2])
+		if ingest := saasIngestPat.FindStringSubmatch(host); ingest != nil {
+			if ingest[1] != "" {
+				add(fmt.Sprintf("https://%s.sentry.io", ingest[1]))
+			}
+			continue
+		}
+		add(scheme + "://" + host)
+	}
+	add(defaultURL)
+	return urls
+}
+
+// allowedInstance reports whether tokens may be sent to the Sentry instance at
+// rawURL. Since the instance is found in the scanned data, only sentry.io and
+// HTTPS hosts the network policy in ctx explicitly allows are.
+func allowedInstance(ctx context.Context, rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "sentry.io" || strings.HasSuffix(host, ".sentry.io") {
+		return true
+	}
+	return common.NetworkPolicyFromContext(ctx).AllowsExplicitly(host)
+}
+
+// organizations returns the slugs of the organizations a user token can
+// access.
+func organizations(ctx context.Context, instance, token string) ([]string, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", instance+"/api/0/organizations/", nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, false, nil
+	}
+
+	var orgs []struct {
+		Slug string `json:"slug"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&orgs); err != nil {
+		return nil, false, err
+	}
+	var slugs []string
+	for _, org := range orgs {
+		slugs = append(slugs, org.Slug)
+	}
+	return slugs, true, nil
+}
+
+type orgTokenPayload struct {
+	URL       string `json:"url"`
+	RegionURL string `json:"region_url"`
+	Org       string `json:"org"`
+}
+
+func decodeOrgTokenPayload(encoded string) (orgTokenPayload, error) {
+	var payload orgTokenPayload
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		decoded, err = base64.RawStdEncoding.DecodeString(encoded)
+		if err != nil {
+			return payload, err
+		}
+	}
+	if err := json.Unmarshal(decoded, &payload); err != nil {
+		return payload, err
+	}
+	if payload.URL == "" || payload.Org == "" {
+		return payload, fmt.Errorf("incomplete organization token payload")
+	}
+	return payload, nil
+}
+
+// verifyOrgToken lists the releases of the token's organization, which is
+// what organization tokens are meant for.
+func verifyOrgToken(ctx context.Context, instance, org, token string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/api/0/organizations/%s/releases/?per_page=1", instance, url.PathEscape(org)), nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	res, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	return res.StatusCode >= 200 && res.StatusCode < 300, nil
+}
@@ -0,0 +1,149 @@
+package shopify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+type Scanner struct {
+	// endpoint overrides the address of shops in tests.
+	endpoint string
+}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+var (
+	client = common.SaneHttpClient()
+
+	// Admin API access tokens of custom apps (shpat), apps created in the
+	// Partner Dashboard (shpca) and legacy private apps (shppa), and app
+	// shared secrets (shpss).
+	keyPat = regexp.MustCompile(`\b(shp(at|ca|pa|ss)_[a-fA-F0-9]{32})\b`)
+
+	// Tokens are only valid for the shop that installed the app.
+	shopPat = regexp.MustCompile(`\b([a-z0-9][a-z0-9-]*\.myshopify\.com)\b`)
+
+	// Plans of development stores, which can't take real payments.
+	developmentPlans = map[string]struct{}{
+		"affiliate":            {},
+		"partner_test":         {},
+		"plus_partner_sandbox": {},
+		"staff":                {},
+		"staff_business":       {},
+	}
+)
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"shpat_", "shpca_", "shppa_", "shpss_"}
+}
+
+// FromData will find and optionally verify Shopify secrets in a given set of bytes.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	var shops []string
+	seen := map[string]struct{}{}
+	for _, match := range shopPat.FindAllStringSubmatch(dataStr, -1) {
+		if _, ok := seen[match[1]]; ok {
+			continue
+		}
+		seen[match[1]] = struct{}{}
+		shops = append(shops, match[1])
+	}
+
+	for _, match := range keyPat.FindAllStringSubmatch(dataStr, -1) {
+		resMatch, kind := match[1], match[2]
+
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_Shopify,
+			Raw:          []byte(resMatch),
+			ExtraData:    map[string]string{"token_type": tokenType(kind)},
+		}
+
+		// Shared secrets only sign requests, and can't be used to call the
+		// API.
+		if verify && kind != "ss" {
+			for _, shop := range shops {
+				plan, verified, err := s.shopPlan(ctx, shop, resMatch)
+				if err != nil || !verified {
+					continue
+				}
+				s1.Verified = true
+				s1.ExtraData["shop"] = shop
+				s1.ExtraData["plan"] = plan
+				if _, ok := developmentPlans[plan]; ok {
+					s1.ExtraData["environment"] = "development"
+					s1.ExtraData["severity"] = "low"
+				} else {
+					s1.ExtraData["environment"] = "production"
+					s1.ExtraData["severity"] = "critical"
+				}
+				break
+			}
+		}
+
+		if !s1.Verified && detectors.IsKnownFalsePositive(resMatch, detectors.DefaultFalsePositives, false) {
+			continue
+		}
+
+		results = append(results, s1)
+	}
+
+	return results, nil
+}
+
+func tokenType(kind string) string {
+	switch kind {
+	case "at":
+		return "custom_app"
+	case "ca":
+		return "partner_app"
+	case "pa":
+		return "private_app"
+	default:
+		return "shared_secret"
+	}
+}
+
+// shopPlan returns the plan of the shop the token was issued for. Reading the
+// shop is allowed for every access token, regardless of its scopes.
+func (s Scanner) shopPlan(ctx context.Context, shop, token string) (string, bool, error) {
+	endpoint := s.endpoint
+	if endpoint == "" {
+		endpoint = "https://" + shop
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/admin/api/2023-10/shop.json", strings.TrimSuffix(endpoint, "/")), nil)
+	if err != nil {
+		return "", false, err
+	}
+	req.Header.Add("X-Shopify-Access-Token", token)
+	res, err := client.Do(req)
+	if err != nil {
+		return "", false, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", false, nil
+	}
+
+	var body struct {
+		Shop struct {
+			PlanName string `json:"plan_name"`
+		} `json:"shop"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", false, err
+	}
+	return body.Shop.PlanName, true, nil
+}
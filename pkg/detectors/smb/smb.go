@@ -0,0 +1,226 @@
+package smb
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/share"
+)
+
+type Scanner struct{}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+var (
+	// smb:// and cifs:// URLs, whose user may be prefixed by a domain and a
+	// semicolon.
+	urlPat = regexp.MustCompile(`(?i)\b(?:smb|cifs)://(?:([^;:@/\s"'<>]+);)?([^:@/\s"'<>]+):([^@/\s"'<>]+)@([a-z0-9.\-]+)(?::(\d{1,5}))?(?:/([^/\s"'<>]+))?`)
+
+	// Windows "net use" command lines.
+	netUsePat = regexp.MustCompile(`(?i)\bnet\s+use\b[^\r\n]*`)
+
+	// CIFS mounts on Linux, as mount command lines and fstab entries, with
+	// the credentials in the mount options.
+	cifsPat = regexp.MustCompile(`(?im)(?:^|[\s"'])//([a-z0-9.\-]+)/([^\s/"']+)[^\r\n]*?\s(?:-o\s+)?(\S*\bpass(?:word)?=\S+)`)
+
+	uncPat    = regexp.MustCompile(`^\\\\([^\\]+)\\([^\\]+)`)
+	devicePat = regexp.MustCompile(`^(?:[A-Za-z]:|\*)$`)
+)
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"smb://", "cifs", "net use"}
+}
+
+// SafeVerification returns false because failed logins may count towards the
+// account's lockout threshold.
+func (s Scanner) SafeVerification() bool {
+	return false
+}
+
+// credential is a login to an SMB share.
+type credential struct {
+	host     string
+	port     string
+	share    string
+	domain   string
+	username string
+	password string
+	// source is what the credential was found as.
+	source string
+}
+
+// FromData will find and optionally verify SMB secrets in a given set of
+// bytes. Servers are only contacted if the network policy explicitly allows
+// them, since they're usually internal.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	var creds []credential
+	creds = append(creds, smbURLs(dataStr)...)
+	creds = append(creds, netUses(dataStr)...)
+	creds = append(creds, cifsMounts(dataStr)...)
+
+	seen := map[string]struct{}{}
+	for _, cred := range creds {
+		// Skip passwords that are templated or read from elsewhere.
+		if strings.HasPrefix(cred.password, "$") || strings.HasPrefix(cred.password, "%") || strings.HasPrefix(cred.password, "{") {
+			continue
+		}
+
+		user := cred.username
+		if cred.domain != "" {
+			user = cred.domain + ";" + user
+		}
+		raw := fmt.Sprintf("smb://%s:%s@%s/%s", user, cred.password, cred.host, cred.share)
+		if _, ok := seen[raw]; ok {
+			continue
+		}
+		seen[raw] = struct{}{}
+
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_SMB,
+			Raw:          []byte(raw),
+			Redacted:     fmt.Sprintf("smb://%s:********@%s/%s", user, cred.host, cred.share),
+			ExtraData: map[string]string{
+				"host":     cred.host,
+				"share":    cred.share,
+				"username": cred.username,
+				"source":   cred.source,
+			},
+		}
+		if cred.domain != "" {
+			s1.ExtraData["domain"] = cred.domain
+		}
+
+		if verify {
+			verified, err := verifyLogin(ctx, cred)
+			if err == nil {
+				s1.Verified = verified
+			}
+		}
+
+		if !s1.Verified && detectors.IsKnownFalsePositive(cred.password, detectors.DefaultFalsePositives, false) {
+			continue
+		}
+
+		results = append(results, s1)
+	}
+
+	return results, nil
+}
+
+// verifyLogin logs in to the SMB server with NTLM, without connecting to the
+// share.
+func verifyLogin(ctx context.Context, cred credential) (bool, error) {
+	port := cred.port
+	if port == "" {
+		port = "445"
+	}
+	conn, err := common.DialExplicitlyAllowed(ctx, net.JoinHostPort(cred.host, port), false)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		return false, err
+	}
+	return share.LoginSMB(conn, cred.username, cred.password, cred.domain)
+}
+
+// smbURLs returns the credentials of smb:// URLs.
+func smbURLs(data string) []credential {
+	var creds []credential
+	for _, match := range urlPat.FindAllStringSubmatch(data, -1) {
+		username, err := url.PathUnescape(match[2])
+		if err != nil {
+			continue
+		}
+		password, err := url.PathUnescape(match[3])
+		if err != nil {
+			continue
+		}
+		creds = append(creds, credential{
+			host:     match[4],
+			port:     match[5],
+			share:    match[6],
+			domain:   match[1],
+			username: username,
+			password: password,
+			source:   "url",
+		})
+	}
+	return creds
+}
+
+// netUses returns the credentials of "net use" command lines, which are
+// written as:
+//
+//	net use [device] \\host\share [password] /user:[domain\]username
+func netUses(data string) []credential {
+	var creds []credential
+	for _, line := range netUsePat.FindAllString(data, -1) {
+		var cred credential
+		var positional []string
+		for _, arg := range strings.Fields(line)[2:] {
+			arg = strings.Trim(arg, `"'`)
+			lower := strings.ToLower(arg)
+			switch {
+			case strings.HasPrefix(lower, "/user:"), strings.HasPrefix(lower, "/u:"):
+				_, cred.username, _ = strings.Cut(arg, ":")
+			case strings.HasPrefix(arg, "/"):
+				// Other options, like /persistent:yes.
+			case uncPat.MatchString(arg):
+				unc := uncPat.FindStringSubmatch(arg)
+				cred.host, cred.share = unc[1], unc[2]
+			case devicePat.MatchString(arg):
+			default:
+				positional = append(positional, arg)
+			}
+		}
+		if cred.host == "" || cred.username == "" || len(positional) == 0 {
+			continue
+		}
+		cred.password = positional[0]
+		if domain, username, ok := strings.Cut(cred.username, `\`); ok {
+			cred.domain, cred.username = domain, username
+		}
+		cred.source = "net use"
+		creds = append(creds, cred)
+	}
+	return creds
+}
+
+// cifsMounts returns the credentials in the options of CIFS mounts.
+func cifsMounts(data string) []credential {
+	var creds []credential
+	for _, match := range cifsPat.FindAllStringSubmatch(data, -1) {
+		cred := credential{host: match[1], share: match[2], source: "mount"}
+		for _, option := range strings.Split(match[3], ",") {
+			key, value, _ := strings.Cut(option, "=")
+			switch strings.ToLower(key) {
+			case "user", "username":
+				cred.username = value
+			case "pass", "password":
+				cred.password = value
+			case "dom", "domain", "workgroup":
+				cred.domain = value
+			}
+		}
+		if cred.username == "" || cred.password == "" {
+			continue
+		}
+		creds = append(creds, cred)
+	}
+	return creds
+}
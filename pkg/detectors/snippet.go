@@ -0,0 +1,127 @@
+package detectors
+
+import (
+	"bytes"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+)
+
+// SnippetKey is the ExtraData key the data around results is recorded under.
+const SnippetKey = "context"
+
+// snippetRedaction replaces secrets in snippets.
+const snippetRedaction = "REDACTED"
+
+// maxSnippetBytes is the most bytes a snippet has on either side of its
+// secret when it's taken by lines, so a long line, like that of minified
+// code, isn't recorded whole.
+const maxSnippetBytes = 512
+
+// keyLike matches words that look like keys, which are redacted from snippets
+// as well as results' secrets, since the secret an ID is found with is often
+// next to it.
+var keyLike = regexp.MustCompile(`[A-Za-z0-9+/_\-]{16,}=*`)
+
+// SnippetOptions sets how much of the data around results is recorded with
+// them, so they can be triaged without opening where they were found.
+type SnippetOptions struct {
+	// Lines is how many lines before and after the line of a secret are
+	// recorded.
+	Lines int
+	// Bytes is how many bytes before and after a secret are recorded. With
+	// Lines, it limits the lines recorded.
+	Bytes int
+}
+
+// Enabled reports whether snippets are recorded.
+func (o SnippetOptions) Enabled() bool {
+	return o.Lines > 0 || o.Bytes > 0
+}
+
+// SetSnippet records in r's ExtraData the data around r's secret in data,
+// with the secret and words that look like keys redacted. Nothing is
+// recorded if the secret isn't in data, as when a detector reports an ID
+// derived from it.
+func SetSnippet(r *ResultWithMetadata, data []byte, opts SnippetOptions) {
+	if !opts.Enabled() || len(r.Raw) == 0 {
+		return
+	}
+	i := bytes.Index(data, r.Raw)
+	if i < 0 {
+		return
+	}
+	start, end := snippetBounds(data, i, i+len(r.Raw), opts)
+	snippet := bytes.ReplaceAll(data[start:end], r.Raw, []byte(snippetRedaction))
+	snippet = keyLike.ReplaceAllFunc(snippet, func(word []byte) []byte {
+		// Words of letters alone, like long identifiers, are kept.
+		if KeyIsRandom(string(word)) {
+			return []byte(snippetRedaction)
+		}
+		return word
+	})
+	if r.ExtraData == nil {
+		r.ExtraData = map[string]string{}
+	}
+	r.ExtraData[SnippetKey] = sanitizer.UTF8(string(snippet))
+}
+
+// snippetBounds returns the bounds of the snippet around the secret at
+// data[secretStart:secretEnd].
+func snippetBounds(data []byte, secretStart, secretEnd int, opts SnippetOptions) (int, int) {
+	limit := opts.Bytes
+	start, end := secretStart-limit, secretEnd+limit
+	if opts.Lines > 0 {
+		start, end = secretStart, secretEnd
+		// The snippet starts at the line Lines before the secret's.
+		for n := 0; n <= opts.Lines; n++ {
+			newline := bytes.LastIndexByte(data[:start], '\n')
+			if newline < 0 {
+				start = 0
+				break
+			}
+			if n == opts.Lines {
+				start = newline + 1
+				break
+			}
+			start = newline
+		}
+		// And ends with the line Lines after it.
+		for n := 0; n <= opts.Lines; n++ {
+			newline := bytes.IndexByte(data[end:], '\n')
+			if newline < 0 {
+				end = len(data)
+				break
+			}
+			if n == opts.Lines {
+				end += newline
+				break
+			}
+			end += newline + 1
+		}
+		if limit == 0 {
+			limit = maxSnippetBytes
+		}
+		if start < secretStart-limit {
+			start = secretStart - limit
+		}
+		if end > secretEnd+limit {
+			end = secretEnd + limit
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end > len(data) {
+		end = len(data)
+	}
+	// Snippets don't start or end in the middle of a character.
+	for start < secretStart && !utf8.RuneStart(data[start]) {
+		start++
+	}
+	for end > secretEnd && end < len(data) && !utf8.RuneStart(data[end]) {
+		end--
+	}
+	return start, end
+}
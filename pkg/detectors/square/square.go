@@ -13,77 +13,120 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 )
 
-type Scanner struct{}
+type Scanner struct {
+	// endpoints override the API addresses of environments in tests.
+	endpoints map[string]string
+}
 
 // Ensure the Scanner satisfies the interface at compile time.
 var _ detectors.Detector = (*Scanner)(nil)
 
 var (
+	client = common.SaneHttpClient()
+
 	// more context to be added if this is too generic
 	secretPat = regexp.MustCompile(detectors.PrefixRegex([]string{"square"}) + `(EAAA[a-zA-Z0-9\-\+\=]{60})`)
+	// Legacy personal access tokens are prefixed, so they don't need the
+	// context.
+	legacyPat = regexp.MustCompile(`\b(sq0atp-[0-9A-Za-z\-_]{22})\b`)
 )
 
+// environments are where Square access tokens can be valid. Tokens of both
+// look the same, so they're told apart by which one accepts them.
+var environments = []struct {
+	name     string
+	endpoint string
+	severity string
+}{
+	{name: "production", endpoint: "https://connect.squareup.com", severity: "critical"},
+	{name: "sandbox", endpoint: "https://connect.squareupsandbox.com", severity: "low"},
+}
+
 // Keywords are used for efficiently pre-filtering chunks.
 // Use identifiers in the secret preferably, or the provider name.
 func (s Scanner) Keywords() []string {
-	return []string{"EAAA"}
+	return []string{"EAAA", "sq0atp-"}
 }
 
 // FromData will find and optionally verify Square secrets in a given set of bytes.
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
+	lower := strings.ToLower(dataStr)
 
+	secMatches := legacyPat.FindAllStringSubmatch(dataStr, -1)
 	// Surprisingly there are still a lot of false positives! So, also doing substring check for square.
-	if !strings.Contains(strings.ToLower(dataStr), "square") {
-		return
+	if strings.Contains(lower, "square") {
+		secMatches = append(secMatches, secretPat.FindAllStringSubmatch(dataStr, -1)...)
 	}
 
-	secMatches := secretPat.FindAllStringSubmatch(dataStr, -1)
 	for _, secMatch := range secMatches {
 		if len(secMatch) != 2 {
 			continue
 		}
 		res := strings.TrimSpace(secMatch[1])
 
-		s := detectors.Result{
+		s1 := detectors.Result{
 			DetectorType: detectorspb.DetectorType_Square,
 			Raw:          []byte(res),
 		}
 
 		if verify {
-			// there are a few endpoints we can check, but templates seems the least sensitive.
-			// 403 will be issued if the scope is wrong but the key is correct
-			baseURL := "https://connect.squareupsandbox.com/v2/merchants"
-
-			client := common.SaneHttpClient()
-
-			// test `merchants` scope - its commonly allowed and low sensitivity
-			req, err := http.NewRequestWithContext(ctx, "GET", baseURL, nil)
-			if err != nil {
-				continue
-			}
-			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", res))
-			req.Header.Add("Content-Type", "application/json")
-			// unclear if this version needs to be set or matters, seems to work without, but docs want it
-			//req.Header.Add("Square-Version", "2020-08-12")
-			res, err := client.Do(req)
-			if err == nil {
-				res.Body.Close() // The request body is unused.
-
-				// 200 means good key and has `merchants` scope - default allowed by square
-				// 401 is bad key
-				if res.StatusCode == http.StatusOK || res.StatusCode == http.StatusForbidden {
-					s.Verified = true
+			for _, env := range environments {
+				endpoint := env.endpoint
+				if override, ok := s.endpoints[env.name]; ok {
+					endpoint = override
+				}
+				verified, err := verifyToken(ctx, endpoint, res)
+				if err != nil || !verified {
+					continue
+				}
+				s1.Verified = true
+				s1.ExtraData = map[string]string{
+					"environment": env.name,
+					"severity":    env.severity,
 				}
+				break
 			}
 		}
 
-		if !s.Verified && detectors.IsKnownFalsePositive(string(s.Raw), detectors.DefaultFalsePositives, true) {
+		// Unverified tokens next to the sandbox API are most likely sandbox
+		// tokens.
+		if !s1.Verified && strings.Contains(lower, "squareupsandbox.com") {
+			s1.ExtraData = map[string]string{
+				"environment": "sandbox",
+				"severity":    "low",
+			}
+		}
+
+		if !s1.Verified && detectors.IsKnownFalsePositive(string(s1.Raw), detectors.DefaultFalsePositives, true) {
 			continue
 		}
 
-		results = append(results, s)
+		results = append(results, s1)
 	}
 
 	return
 }
+
+// verifyToken lists the token's merchants, which is read-only.
+func verifyToken(ctx context.Context, endpoint, token string) (bool, error) {
+	// test `merchants` scope - its commonly allowed and low sensitivity
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint+"/v2/merchants", nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	req.Header.Add("Content-Type", "application/json")
+	// unclear if this version needs to be set or matters, seems to work without, but docs want it
+	//req.Header.Add("Square-Version", "2020-08-12")
+	res, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	res.Body.Close() // The request body is unused.
+
+	// 200 means good key and has `merchants` scope - default allowed by square
+	// 403 will be issued if the scope is wrong but the key is correct
+	// 401 is bad key
+	return res.StatusCode == http.StatusOK || res.StatusCode == http.StatusForbidden, nil
+}
@@ -3,6 +3,8 @@ package square
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -46,6 +48,10 @@ func TestSquare_FromChunk(t *testing.T) {
 				{
 					DetectorType: detectorspb.DetectorType_Square,
 					Verified:     true,
+					ExtraData: map[string]string{
+						"environment": "sandbox",
+						"severity":    "low",
+					},
 				},
 			},
 			wantErr: false,
@@ -99,6 +105,83 @@ func TestSquare_FromChunk(t *testing.T) {
 	}
 }
 
+func TestSquare_Environment(t *testing.T) {
+	const (
+		productionToken = "EAAAl7Vq2Xb9Nc4Mz1Kd8Rf3Tg6Hw0Jy5Lp2Qs7Ua9Ve4Wi1Xo6Yr3Zt8Ab5Cd2E"
+		sandboxToken    = "EAAAE3Kq8Wz1Xn5Bv7Cm2Lr9Ts4Yd6Hf0Gj3Pk8Ua1Ve5Wi7Xo2Yr4Zt9Ab6Cd3F"
+	)
+	newServer := func(token string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v2/merchants" || r.Header.Get("Authorization") != "Bearer "+token {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"merchant":[]}`)
+		}))
+	}
+	production, sandbox := newServer(productionToken), newServer(sandboxToken)
+	defer production.Close()
+	defer sandbox.Close()
+	s := Scanner{endpoints: map[string]string{"production": production.URL, "sandbox": sandbox.URL}}
+
+	tests := []struct {
+		name   string
+		data   string
+		verify bool
+		want   []detectors.Result
+	}{
+		{
+			name:   "production token",
+			data:   fmt.Sprintf("SQUARE_ACCESS_TOKEN=%s", productionToken),
+			verify: true,
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_Square,
+					Verified:     true,
+					ExtraData:    map[string]string{"environment": "production", "severity": "critical"},
+				},
+			},
+		},
+		{
+			name:   "sandbox token",
+			data:   fmt.Sprintf("SQUARE_ACCESS_TOKEN=%s", sandboxToken),
+			verify: true,
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_Square,
+					Verified:     true,
+					ExtraData:    map[string]string{"environment": "sandbox", "severity": "low"},
+				},
+			},
+		},
+		{
+			name:   "unverified token next to the sandbox API",
+			data:   fmt.Sprintf("SQUARE_URL=https://connect.squareupsandbox.com\nSQUARE_ACCESS_TOKEN=%s", sandboxToken),
+			verify: false,
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_Square,
+					ExtraData:    map[string]string{"environment": "sandbox", "severity": "low"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.FromData(context.Background(), tt.verify, []byte(tt.data))
+			if err != nil {
+				t.Fatalf("Square.FromData() error = %v", err)
+			}
+			for i := range got {
+				got[i].Raw = nil
+			}
+			if diff := pretty.Compare(got, tt.want); diff != "" {
+				t.Errorf("Square.FromData() %s diff: (-got +want)\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
 func BenchmarkFromData(benchmark *testing.B) {
 	ctx := context.Background()
 	s := Scanner{}
@@ -32,6 +32,11 @@ func (s Scanner) Keywords() []string {
 	return []string{"sq0i"}
 }
 
+// SafeVerification returns false because verifying revokes the OAuth token being verified.
+func (s Scanner) SafeVerification() bool {
+	return false
+}
+
 // FromData will find and optionally verify SquareApp secrets in a given set of bytes.
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
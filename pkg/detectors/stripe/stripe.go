@@ -2,29 +2,45 @@ package stripe
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
 	"net/http"
 	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 )
 
-type Scanner struct{}
+type Scanner struct {
+	// endpoint overrides the Stripe API address in tests.
+	endpoint string
+}
 
 // Ensure the Scanner satisfies the interface at compile time.
 var _ detectors.Detector = (*Scanner)(nil)
 
+const defaultEndpoint = "https://api.stripe.com"
+
 var (
-	//doesnt include test keys with "sk_test"
-	secretKey = regexp.MustCompile(`[rs]k_live_[a-zA-Z0-9]{20,30}`)
+	client = common.SaneHttpClient()
+
+	// Secret (sk) and restricted (rk) keys, in live or test mode.
+	secretKey = regexp.MustCompile(`\b([rs]k)_(live|test)_[a-zA-Z0-9]{20,247}\b`)
 )
 
+// accountResponse is the part of the account object that's reported.
+type accountResponse struct {
+	ID      string `json:"id"`
+	Country string `json:"country"`
+	Email   string `json:"email"`
+}
+
 // Keywords are used for efficiently pre-filtering chunks.
 // Use identifiers in the secret preferably, or the provider name.
 func (s Scanner) Keywords() []string {
-	return []string{"k_live"}
+	return []string{"k_live", "k_test"}
 }
 
 // FromData will find and optionally verify Stripe secrets in a given set of bytes.
@@ -32,44 +48,101 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 
 	dataStr := string(data)
 
-	matches := secretKey.FindAllString(dataStr, -1)
+	matches := secretKey.FindAllStringSubmatch(dataStr, -1)
 
 	for _, match := range matches {
+		key, kind, mode := match[0], match[1], match[2]
+		livemode := mode == "live"
 
-		s := detectors.Result{
+		s1 := detectors.Result{
 			DetectorType: detectorspb.DetectorType_Stripe,
-			Raw:          []byte(match),
+			Raw:          []byte(key),
+			ExtraData: map[string]string{
+				"version":  kind + "_" + mode,
+				"key_type": keyType(kind),
+				"livemode": strconv.FormatBool(livemode),
+				"severity": severity(kind, livemode),
+			},
 		}
 
 		if verify {
-
-			baseURL := "https://api.stripe.com/v1/charges"
-
-			client := common.SaneHttpClient()
-
-			// test `read_user` scope
-			req, err := http.NewRequestWithContext(ctx, "GET", baseURL, nil)
-			if err != nil {
-				continue
-			}
-			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", match))
-			req.Header.Add("Content-Type", "application/json")
-			res, err := client.Do(req)
-			if err == nil {
-				res.Body.Close() // The request body is unused.
-
-				if res.StatusCode == http.StatusOK || res.StatusCode == http.StatusForbidden {
-					s.Verified = true
+			account, verified, err := s.retrieveAccount(ctx, key)
+			if err == nil && verified {
+				s1.Verified = true
+				if account != nil {
+					s1.ExtraData["account_id"] = account.ID
+					s1.ExtraData["country"] = account.Country
+					if account.Email != "" {
+						s1.ExtraData["email"] = account.Email
+					}
+				} else {
+					// Restricted keys without the account read permission
+					// are rejected with a permission error.
+					s1.ExtraData["account_id"] = "unknown"
 				}
 			}
 		}
 
-		if !s.Verified && detectors.IsKnownFalsePositive(string(s.Raw), detectors.DefaultFalsePositives, true) {
+		if !s1.Verified && detectors.IsKnownFalsePositive(key, detectors.DefaultFalsePositives, true) {
 			continue
 		}
 
-		results = append(results, s)
+		results = append(results, s1)
 	}
 
 	return
 }
+
+// retrieveAccount reads the key's account, which has no side effects. It
+// reports a verified key with a nil account when the key lacks permission to
+// read it.
+func (s Scanner) retrieveAccount(ctx context.Context, key string) (*accountResponse, bool, error) {
+	endpoint := s.endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(endpoint, "/")+"/v1/account", nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req.SetBasicAuth(key, "")
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		account := &accountResponse{}
+		if err := json.NewDecoder(res.Body).Decode(account); err != nil {
+			return nil, false, err
+		}
+		return account, true, nil
+	case http.StatusForbidden:
+		return nil, true, nil
+	default:
+		return nil, false, nil
+	}
+}
+
+func keyType(kind string) string {
+	if kind == "rk" {
+		return "restricted"
+	}
+	return "secret"
+}
+
+// severity scales with what a key can do: live secret keys have full access
+// to real payments, restricted keys only what they were granted, and test
+// keys can't move money.
+func severity(kind string, livemode bool) string {
+	switch {
+	case !livemode:
+		return "low"
+	case kind == "rk":
+		return "high"
+	default:
+		return "critical"
+	}
+}
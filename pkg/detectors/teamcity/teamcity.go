@@ -0,0 +1,155 @@
+package teamcity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+type Scanner struct{}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+var (
+	client = common.SaneHttpClient()
+
+	// Access tokens are JWT-like, with the constant header {"typ": "TCV2"}.
+	keyPat = regexp.MustCompile(`\b(eyJ0eXAiOiAiVENWMiJ9\.[A-Za-z0-9_-]{20,}\.[A-Za-z0-9_-]{20,})`)
+
+	// TeamCity is self-hosted, so tokens can only be verified against a
+	// server found near them.
+	serverURLPat  = regexp.MustCompile(`(?i)TEAMCITY_(?:SERVER_)?(?:URL|HOST)["']?\s*[:=]\s*["']?(https?://[^\s"'/]+)`)
+	serverHostPat = regexp.MustCompile(`(?i)\b(https?://[a-z0-9.-]*teamcity[a-z0-9.-]*(?::\d+)?)`)
+)
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"eyJ0eXAiOiAiVENWMiJ9"}
+}
+
+// FromData will find and optionally verify TeamCity secrets in a given set of bytes.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	var servers []string
+	seen := map[string]struct{}{}
+	for _, pat := range []*regexp.Regexp{serverURLPat, serverHostPat} {
+		for _, match := range pat.FindAllStringSubmatch(dataStr, -1) {
+			server := strings.TrimSuffix(match[1], "/")
+			if _, ok := seen[server]; ok || !allowedServer(ctx, server) {
+				continue
+			}
+			seen[server] = struct{}{}
+			servers = append(servers, server)
+		}
+	}
+
+	for _, match := range keyPat.FindAllStringSubmatch(dataStr, -1) {
+		resMatch := strings.TrimSpace(match[1])
+
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_TeamCity,
+			Raw:          []byte(resMatch),
+		}
+
+		if verify {
+			for _, server := range servers {
+				user, verified, err := currentUser(ctx, server, resMatch)
+				if err != nil || !verified {
+					continue
+				}
+				s1.Verified = true
+				s1.ExtraData = map[string]string{
+					"server":   server,
+					"username": user.Username,
+				}
+				if roles := user.roleIDs(); len(roles) > 0 {
+					s1.ExtraData["roles"] = strings.Join(roles, ",")
+				}
+				break
+			}
+		}
+
+		if !s1.Verified && detectors.IsKnownFalsePositive(resMatch, detectors.DefaultFalsePositives, false) {
+			continue
+		}
+
+		results = append(results, s1)
+	}
+
+	return results, nil
+}
+
+// SafeVerification returns false because verifying sends the token to a
+// TeamCity server found in the scanned data.
+func (s Scanner) SafeVerification() bool { return false }
+
+// allowedServer reports whether tokens may be sent to the TeamCity server at
+// rawURL. TeamCity Cloud instances are always allowed; any other server was
+// named by whoever wrote the scanned data, so only HTTPS hosts the network
+// policy in ctx explicitly allows are.
+func allowedServer(ctx context.Context, rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if strings.HasSuffix(host, ".teamcity.com") {
+		return true
+	}
+	return common.NetworkPolicyFromContext(ctx).AllowsExplicitly(host)
+}
+
+type user struct {
+	Username string `json:"username"`
+	Roles    struct {
+		Role []struct {
+			RoleID string `json:"roleId"`
+			Scope  string `json:"scope"`
+		} `json:"role"`
+	} `json:"roles"`
+}
+
+// roleIDs returns the user's roles with their scope, such as
+// "SYSTEM_ADMIN@g" for a global system administrator.
+func (u user) roleIDs() []string {
+	var roles []string
+	for _, role := range u.Roles.Role {
+		roles = append(roles, fmt.Sprintf("%s@%s", role.RoleID, role.Scope))
+	}
+	return roles
+}
+
+// currentUser returns the user a token belongs to.
+// https://www.jetbrains.com/help/teamcity/rest/manage-users.html
+func currentUser(ctx context.Context, server, token string) (user, bool, error) {
+	var u user
+	req, err := http.NewRequestWithContext(ctx, "GET", server+"/app/rest/users/current", nil)
+	if err != nil {
+		return u, false, err
+	}
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	res, err := client.Do(req)
+	if err != nil {
+		return u, false, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return u, false, nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(&u); err != nil {
+		return u, false, err
+	}
+	return u, true, nil
+}
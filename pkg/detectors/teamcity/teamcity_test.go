@@ -0,0 +1,166 @@
+package teamcity
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+const (
+	activeToken   = "eyJ0eXAiOiAiVENWMiJ9.Z3BmUHJ3U0VmaGxPeFpYa1hVTnBrT3VqSmNN.NTE4ZWI2YjUtNjQ2ZC00ZjNjLWI0NWEtYzVjNjJmZjQ1ZmZk"
+	inactiveToken = "eyJ0eXAiOiAiVENWMiJ9.cnRCdUxwWVNpc0Z4a0JnV2dPR3hXR0Z6eGVr.YTZiN2MzZDEtMmQ0Ni00ZTkxLTg4YzAtNzc0ZjE5ZTNhYjA1"
+)
+
+func TestTeamCity_FromChunk(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/app/rest/users/current" || r.Header.Get("Authorization") != "Bearer "+activeToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"username":"deployer","name":"Deployer","roles":{"role":[{"roleId":"PROJECT_DEVELOPER","scope":"p:Acme"},{"roleId":"SYSTEM_ADMIN","scope":"g"}]}}`)
+	})
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+
+	defer func(c *http.Client) { client = c }(client)
+	client = server.Client()
+
+	allowLocal := common.WithNetworkPolicy(context.Background(), &common.NetworkPolicy{Allow: []string{"127.0.0.1"}})
+
+	type args struct {
+		ctx    context.Context
+		data   []byte
+		verify bool
+	}
+	tests := []struct {
+		name    string
+		s       Scanner
+		args    args
+		want    []detectors.Result
+		wantErr bool
+	}{
+		{
+			name: "found, verified",
+			s:    Scanner{},
+			args: args{
+				ctx:    allowLocal,
+				data:   []byte(fmt.Sprintf("TEAMCITY_URL=%s\nTEAMCITY_TOKEN=%s\n", server.URL, activeToken)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_TeamCity,
+					Verified:     true,
+					ExtraData: map[string]string{
+						"server":   server.URL,
+						"username": "deployer",
+						"roles":    "PROJECT_DEVELOPER@p:Acme,SYSTEM_ADMIN@g",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "found, unverified",
+			s:    Scanner{},
+			args: args{
+				ctx:    allowLocal,
+				data:   []byte(fmt.Sprintf("TEAMCITY_URL=%s\nTEAMCITY_TOKEN=%s\n", server.URL, inactiveToken)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_TeamCity,
+					Verified:     false,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "found, http server",
+			s:    Scanner{},
+			args: args{
+				ctx:    allowLocal,
+				data:   []byte(fmt.Sprintf("TEAMCITY_URL=%s\nTEAMCITY_TOKEN=%s\n", httpServer.URL, activeToken)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_TeamCity,
+					Verified:     false,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "found, server not allowed",
+			s:    Scanner{},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte(fmt.Sprintf("TEAMCITY_URL=%s\nTEAMCITY_TOKEN=%s\n", server.URL, activeToken)),
+				verify: true,
+			},
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_TeamCity,
+					Verified:     false,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "not found",
+			s:    Scanner{},
+			args: args{
+				ctx:    context.Background(),
+				data:   []byte("TEAMCITY_TOKEN=eyJ0eXAiOiAiVENWMiJ9"),
+				verify: true,
+			},
+			want:    nil,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.s.FromData(tt.args.ctx, tt.args.verify, tt.args.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TeamCity.FromData() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			for i := range got {
+				if len(got[i].Raw) == 0 {
+					t.Fatalf("no raw secret present: \n %+v", got[i])
+				}
+				got[i].Raw = nil
+			}
+			if diff := pretty.Compare(got, tt.want); diff != "" {
+				t.Errorf("TeamCity.FromData() %s diff: (-got +want)\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func BenchmarkFromData(benchmark *testing.B) {
+	ctx := context.Background()
+	s := Scanner{}
+	for name, data := range detectors.MustGetBenchmarkData() {
+		benchmark.Run(name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				_, err := s.FromData(ctx, false, data)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -2,22 +2,29 @@ package twilio
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
-	"net/url"
 	"regexp"
-	"strings"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 )
 
-type Scanner struct{}
+type Scanner struct {
+	// endpoint overrides the Twilio API address in tests.
+	endpoint string
+}
 
 // Ensure the Scanner satisfies the interface at compile time.
 var _ detectors.Detector = (*Scanner)(nil)
 
+const defaultEndpoint = "https://api.twilio.com"
+
 var (
+	client = common.SaneHttpClient()
+
 	identifierPat = regexp.MustCompile(`(?i)sid.{0,20}AC[0-9a-f]{32}`) // Should we have this? Seems restrictive.
 	sidPat        = regexp.MustCompile(`\bAC[0-9a-f]{32}\b`)
 	keyPat        = regexp.MustCompile(`\b[0-9a-f]{32}\b`)
@@ -44,48 +51,74 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 
 	for _, sid := range sidMatches {
 
-		s := detectors.Result{
+		s1 := detectors.Result{
 			DetectorType: detectorspb.DetectorType_Twilio,
 			Raw:          []byte(sid),
 			Redacted:     sid,
 		}
 
 		if verify {
-			client := common.SaneHttpClient()
 			for _, key := range keyMatches {
-
-				form := url.Values{}
-				form.Add("FriendlyName", "MyServiceName")
-				req, err := http.NewRequestWithContext(
-					ctx, "POST", "https://verify.twilio.com/v2/Services",
-
-					strings.NewReader(form.Encode()),
-				)
-				if err != nil {
+				account, err := s.fetchAccount(ctx, sid, key)
+				if err != nil || account == nil {
 					continue
 				}
-				req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-				req.Header.Add("Accept", "*/*")
-				req.SetBasicAuth(sid, key)
-				res, err := client.Do(req)
-				if err == nil {
-					res.Body.Close() // The request body is unused.
-
-					if res.StatusCode >= 200 && res.StatusCode < 300 {
-						s.Verified = true
-					}
+				s1.Verified = true
+				s1.ExtraData = map[string]string{
+					"account_sid":   account.SID,
+					"friendly_name": account.FriendlyName,
+					"status":        account.Status,
+					// Trial or Full.
+					"plan": account.Type,
 				}
+				break
 			}
 		}
 
-		if !s.Verified && detectors.IsKnownFalsePositive(string(s.Raw), detectors.DefaultFalsePositives, true) {
+		if !s1.Verified && detectors.IsKnownFalsePositive(string(s1.Raw), detectors.DefaultFalsePositives, true) {
 			continue
 		}
 
 		if len(keyMatches) > 0 {
-			results = append(results, s)
+			results = append(results, s1)
 		}
 	}
 
 	return
 }
+
+// accountResponse is the part of the account resource that's reported.
+type accountResponse struct {
+	SID          string `json:"sid"`
+	FriendlyName string `json:"friendly_name"`
+	Status       string `json:"status"`
+	Type         string `json:"type"`
+}
+
+// fetchAccount reads the account the credentials belong to, which has no side
+// effects. It returns nil if the credentials are rejected.
+func (s Scanner) fetchAccount(ctx context.Context, sid, key string) (*accountResponse, error) {
+	endpoint := s.endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/2010-04-01/Accounts/%s.json", endpoint, sid), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(sid, key)
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, nil
+	}
+	account := &accountResponse{}
+	if err := json.NewDecoder(res.Body).Decode(account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
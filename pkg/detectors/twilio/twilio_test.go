@@ -3,6 +3,8 @@ package twilio
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -94,6 +96,75 @@ func TestTwilio_FromChunk(t *testing.T) {
 					t.Fatal("no raw secret present")
 				}
 				got[i].Raw = nil
+				// The account details depend on the test account; they're
+				// covered by TestTwilio_Verify.
+				got[i].ExtraData = nil
+			}
+			if diff := pretty.Compare(got, tt.want); diff != "" {
+				t.Errorf("Twilio.FromData() %s diff: (-got +want)\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestTwilio_Verify(t *testing.T) {
+	const (
+		sid    = "AC3f1c9a7e52b84d06a8e2f7c1b9d4e063"
+		key    = "9c2e7a41f08b5d36e1a4c7f2b8d05e93"
+		badKey = "0d4b8e62a17f3c95b2e8d1a6f4c07b39"
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		if r.Method != http.MethodGet || r.URL.Path != "/2010-04-01/Accounts/"+sid+".json" || user != sid || pass != key {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"sid":"%s","friendly_name":"Acme Notifications","status":"active","type":"Full"}`, sid)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		data string
+		want []detectors.Result
+	}{
+		{
+			name: "verified",
+			data: fmt.Sprintf("TWILIO_ACCOUNT_SID=%s\nTWILIO_AUTH_TOKEN=%s", sid, key),
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_Twilio,
+					Verified:     true,
+					Redacted:     sid,
+					ExtraData: map[string]string{
+						"account_sid":   sid,
+						"friendly_name": "Acme Notifications",
+						"status":        "active",
+						"plan":          "Full",
+					},
+				},
+			},
+		},
+		{
+			name: "unverified",
+			data: fmt.Sprintf("TWILIO_ACCOUNT_SID=%s\nTWILIO_AUTH_TOKEN=%s", sid, badKey),
+			want: []detectors.Result{
+				{
+					DetectorType: detectorspb.DetectorType_Twilio,
+					Redacted:     sid,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Scanner{endpoint: server.URL}
+			got, err := s.FromData(context.Background(), true, []byte(tt.data))
+			if err != nil {
+				t.Fatalf("Twilio.FromData() error = %v", err)
+			}
+			for i := range got {
+				got[i].Raw = nil
 			}
 			if diff := pretty.Compare(got, tt.want); diff != "" {
 				t.Errorf("Twilio.FromData() %s diff: (-got +want)\n%s", tt.name, diff)
@@ -55,7 +55,7 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 			}
 
 			if verify {
-				req, err := http.NewRequest("GET", "https://api.uploadcare.com/files/", nil)
+				req, err := http.NewRequestWithContext(ctx, "GET", "https://api.uploadcare.com/files/", nil)
 				if err != nil {
 					continue
 				}
@@ -37,7 +37,7 @@ func (s Scanner) Keywords() []string {
 }
 
 func allowlistedProtos(scheme string) bool {
-	allowlisted := []string{"http", "https", "mongodb", "redis", "ftp"}
+	allowlisted := []string{"http", "https", "mongodb", "redis"}
 	for _, s := range allowlisted {
 		if s == scheme {
 			return true
@@ -32,6 +32,11 @@ func (s Scanner) Keywords() []string {
 	return []string{"virustotal"}
 }
 
+// SafeVerification returns false because verifying submits a URL for analysis.
+func (s Scanner) SafeVerification() bool {
+	return false
+}
+
 // FromData will find and optionally verify VirusTotal secrets in a given set of bytes.
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
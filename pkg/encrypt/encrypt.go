@@ -0,0 +1,92 @@
+// Package encrypt encrypts scan output to age or OpenPGP recipients as it's
+// written, so findings can be stored where others can read the files, such as
+// shared CI artifact storage.
+package encrypt
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-errors/errors"
+)
+
+// Recipients are the keys output is encrypted to. A file is either in the age
+// format or the OpenPGP one, so the recipients are all age recipients or all
+// OpenPGP keys.
+type Recipients struct {
+	age []age.Recipient
+	pgp openpgp.EntityList
+}
+
+// ParseRecipients parses recipients given as age X25519 recipients, such as
+// age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p, or as paths
+// to OpenPGP public key files, armored or binary.
+func ParseRecipients(specs []string) (*Recipients, error) {
+	r := &Recipients{}
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if strings.HasPrefix(strings.ToLower(spec), "age1") {
+			recipient, err := age.ParseX25519Recipient(spec)
+			if err != nil {
+				return nil, errors.WrapPrefix(err, "invalid age recipient "+spec, 0)
+			}
+			r.age = append(r.age, recipient)
+			continue
+		}
+		keys, err := readPGPKeys(spec)
+		if err != nil {
+			return nil, err
+		}
+		r.pgp = append(r.pgp, keys...)
+	}
+	if len(r.age) > 0 && len(r.pgp) > 0 {
+		return nil, errors.New("recipients must all be age recipients or all be OpenPGP keys")
+	}
+	if len(r.age) == 0 && len(r.pgp) == 0 {
+		return nil, errors.New("no recipients")
+	}
+	return r, nil
+}
+
+// readPGPKeys reads the OpenPGP public keys in the file at path.
+func readPGPKeys(path string) (openpgp.EntityList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not read OpenPGP key", 0)
+	}
+	var keys openpgp.EntityList
+	if bytes.Contains(data, []byte("-----BEGIN PGP")) {
+		keys, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	} else {
+		keys, err = openpgp.ReadKeyRing(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not parse OpenPGP key "+path, 0)
+	}
+	if len(keys) == 0 {
+		return nil, errors.Errorf("no OpenPGP keys in %s", path)
+	}
+	return keys, nil
+}
+
+// Encrypt returns a writer that encrypts what's written to it to the
+// recipients, writing the ciphertext to w as it goes. It must be closed to
+// finish the file. Closing it doesn't close w.
+func (r *Recipients) Encrypt(w io.Writer) (io.WriteCloser, error) {
+	if len(r.pgp) > 0 {
+		plaintext, err := openpgp.Encrypt(w, r.pgp, nil, &openpgp.FileHints{IsBinary: true}, nil)
+		if err != nil {
+			return nil, errors.WrapPrefix(err, "could not encrypt to OpenPGP keys", 0)
+		}
+		return plaintext, nil
+	}
+	plaintext, err := age.Encrypt(w, r.age...)
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not encrypt to age recipients", 0)
+	}
+	return plaintext, nil
+}
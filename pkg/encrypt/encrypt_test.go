@@ -0,0 +1,169 @@
+package encrypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+func TestParseRecipients(t *testing.T) {
+	// The example recipient from the age README.
+	if _, err := ParseRecipients([]string{"age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p"}); err != nil {
+		t.Errorf("ParseRecipients() error = %v", err)
+	}
+
+	keyFile := writePGPKey(t, newPGPEntity(t), true)
+	tests := []struct {
+		name    string
+		specs   []string
+		wantErr string
+	}{
+		{name: "bad checksum", specs: []string{"age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8q"}, wantErr: "invalid checksum"},
+		{name: "mixed case", specs: []string{"age1Ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p"}, wantErr: "mixed case"},
+		{name: "mixed formats", specs: []string{"age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p", keyFile}, wantErr: "all be age recipients or all be OpenPGP keys"},
+		{name: "missing key file", specs: []string{filepath.Join(t.TempDir(), "missing.asc")}, wantErr: "could not read OpenPGP key"},
+		{name: "none", wantErr: "no recipients"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRecipients(tt.specs)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseRecipients() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func newAgeIdentity(t *testing.T) *age.X25519Identity {
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestEncrypt_Age(t *testing.T) {
+	alice, bob := newAgeIdentity(t), newAgeIdentity(t)
+	recipients, err := ParseRecipients([]string{alice.Recipient().String(), bob.Recipient().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// age encrypts in 64 KiB chunks.
+	const chunkSize = 64 * 1024
+	for _, size := range []int{0, 100, chunkSize, chunkSize + 1, 3*chunkSize - 7} {
+		plaintext := make([]byte, size)
+		if _, err := rand.Read(plaintext); err != nil {
+			t.Fatal(err)
+		}
+		var file bytes.Buffer
+		w, err := recipients.Encrypt(&file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Write in uneven pieces, as output is written.
+		for rest := plaintext; len(rest) > 0; {
+			n := 1000
+			if n > len(rest) {
+				n = len(rest)
+			}
+			if _, err := w.Write(rest[:n]); err != nil {
+				t.Fatal(err)
+			}
+			rest = rest[n:]
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if size >= 32 && bytes.Contains(file.Bytes(), plaintext[:32]) {
+			t.Errorf("%d bytes: ciphertext contains the plaintext", size)
+		}
+		for _, id := range []*age.X25519Identity{alice, bob} {
+			r, err := age.Decrypt(bytes.NewReader(file.Bytes()), id)
+			if err != nil {
+				t.Fatalf("%d bytes: %v", size, err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("%d bytes: %v", size, err)
+			}
+			if !bytes.Equal(got, plaintext) {
+				t.Errorf("%d bytes: decrypted %d bytes that don't match", size, len(got))
+			}
+		}
+	}
+}
+
+func newPGPEntity(t *testing.T) *openpgp.Entity {
+	entity, err := openpgp.NewEntity("Security Team", "", "security@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entity
+}
+
+func writePGPKey(t *testing.T, entity *openpgp.Entity, armored bool) string {
+	var buf bytes.Buffer
+	w := io.WriteCloser(nopCloser{&buf})
+	if armored {
+		var err error
+		if w, err = armor.Encode(&buf, openpgp.PublicKeyType, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+func TestEncrypt_PGP(t *testing.T) {
+	armored, binary := newPGPEntity(t), newPGPEntity(t)
+	recipients, err := ParseRecipients([]string{writePGPKey(t, armored, true), writePGPKey(t, binary, false)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := []byte(`{"DetectorName":"AWS","Raw":"AKIAEXAMPLE"}` + "\n")
+	var file bytes.Buffer
+	w, err := recipients.Encrypt(&file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, entity := range []*openpgp.Entity{armored, binary} {
+		md, err := openpgp.ReadMessage(bytes.NewReader(file.Bytes()), openpgp.EntityList{entity}, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(md.UnverifiedBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Errorf("decrypted %q, want %q", got, plaintext)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/banners"
+)
+
+// ScanServiceBanners scans the banners and HTTP bodies of internet-facing
+// services, from Shodan and Censys exports and from searching the networks
+// with their APIs.
+func (e *Engine) ScanServiceBanners(ctx context.Context, exports, networks []string, shodanKey, censysID, censysSecret string) error {
+	ctx = e.sourceContext(ctx, sourcespb.SourceType_SOURCE_TYPE_SERVICE_BANNERS)
+	connection := &sourcespb.ServiceBanners{
+		Exports:      exports,
+		Networks:     networks,
+		ShodanKey:    shodanKey,
+		CensysId:     censysID,
+		CensysSecret: censysSecret,
+		Proxy:        e.proxy,
+	}
+
+	var conn anypb.Any
+	err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{})
+	if err != nil {
+		return errors.WrapPrefix(err, "could not marshal service banners connection", 0)
+	}
+
+	bannersSource := banners.Source{}
+	err = bannersSource.Init(ctx, "trufflehog - service banners", 0, int64(sourcespb.SourceType_SOURCE_TYPE_SERVICE_BANNERS), true, &conn, 1)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not init service banners source", 0)
+	}
+	return e.AddSource(ctx, &bannersSource)
+}
@@ -0,0 +1,37 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/dns"
+)
+
+// ScanDNS scans the TXT and SPF records of DNS zones, transferring the zones
+// where their nameservers allow it if zoneTransfer is set. Lookups go to the
+// nameserver if one is given.
+func (e *Engine) ScanDNS(ctx context.Context, zones []string, zoneTransfer bool, nameserver string) error {
+	ctx = e.sourceContext(ctx, sourcespb.SourceType_SOURCE_TYPE_DNS)
+	connection := &sourcespb.DNS{
+		Zones:        zones,
+		ZoneTransfer: zoneTransfer,
+		Nameserver:   nameserver,
+	}
+
+	var conn anypb.Any
+	err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{})
+	if err != nil {
+		return errors.WrapPrefix(err, "could not marshal dns connection", 0)
+	}
+
+	dnsSource := dns.Source{}
+	err = dnsSource.Init(ctx, "trufflehog - dns", 0, int64(sourcespb.SourceType_SOURCE_TYPE_DNS), true, &conn, 1)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not init dns source", 0)
+	}
+	return e.AddSource(ctx, &dnsSource)
+}
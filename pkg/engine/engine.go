@@ -1,18 +1,37 @@
+// Package engine scans sources for secrets. It can be embedded in other Go
+// programs:
+//
+//	e := engine.NewEngine(ctx, engine.WithConcurrency(8), engine.WithLogger(logger))
+//	if err := e.AddSource(ctx, source); err != nil {
+//		return err
+//	}
+//	go e.Finish()
+//	for finding := range e.Results() {
+//		...
+//	}
 package engine
 
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"runtime"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"github.com/sirupsen/logrus"
+	"github.com/go-errors/errors"
+	"github.com/go-logr/logr"
 
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/decoders"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/findings"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/findingspb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
@@ -25,9 +44,62 @@ type Engine struct {
 	decoders        []decoders.Decoder
 	detectors       map[bool][]detectors.Detector
 	chunksScanned   uint64
+	chunksSkipped   uint64
 	detectorAvgTime sync.Map
+	detectorStats   sync.Map
 	sourcesWg       sync.WaitGroup
 	workersWg       sync.WaitGroup
+
+	// safeVerificationOnly disables verification for detectors whose
+	// verification requests have side effects.
+	safeVerificationOnly bool
+	networkPolicy        *common.NetworkPolicy
+	// limiter limits verification when a budget or sample is set.
+	limiter *verificationLimiter
+	// dryRun records verification requests instead of sending them when it's
+	// set.
+	dryRun          func(detector string, req common.DryRunRequest)
+	budgetSpentOnce sync.Once
+	// scorer scores results' false positive likelihood when it's set.
+	scorer detectors.Scorer
+	// snippets sets how much of the data around results is recorded with
+	// them.
+	snippets detectors.SnippetOptions
+	// ocr reads the text of images when it's set. Otherwise they're skipped.
+	ocr *handlers.OCR
+	// archiveLimits limit how archives are unpacked.
+	archiveLimits handlers.Limits
+	// itemRetries are how sources retry the items that fail with errors
+	// that may not recur.
+	itemRetries sources.Retries
+	// tlsConfig configures the TLS connections of HTTP based sources.
+	tlsConfig *credentialspb.TLSConfig
+	// proxy is the proxy HTTP based sources connect through, instead of the
+	// one set in the environment, when it's set.
+	proxy *credentialspb.Proxy
+	// bandwidth limits the downloads of all the sources together when it's
+	// set.
+	bandwidth *common.BandwidthLimiter
+	// tenants are the tenants whose sources have limits, by name.
+	tenants map[string]*tenant
+
+	logger       logr.Logger
+	log          logr.Logger
+	findings     chan *findingspb.Finding
+	findingsOnce sync.Once
+	sourceErrMu  sync.Mutex
+	sourceErrs   []error
+
+	listenersMu sync.Mutex
+	listeners   map[string]sources.Listener
+	sourcesMu   sync.Mutex
+	sources     []*sourceState
+	// lastVerified is the Unix time in nanoseconds a result was last verified.
+	lastVerified int64
+	// started and finished are when the engine was started and when Finish
+	// found it had scanned every chunk.
+	started  time.Time
+	finished time.Time
 }
 
 type EngineOption func(*Engine)
@@ -51,17 +123,129 @@ func WithDetectors(verify bool, d ...detectors.Detector) EngineOption {
 	}
 }
 
+// WithSafeVerificationOnly skips verification for detectors that don't
+// declare their verification request free of side effects.
+func WithSafeVerificationOnly(safeOnly bool) EngineOption {
+	return func(e *Engine) {
+		e.safeVerificationOnly = safeOnly
+	}
+}
+
+// WithNetworkPolicy restricts the hosts detectors may contact when verifying
+// results. An offline policy disables verification for every detector.
+func WithNetworkPolicy(policy *common.NetworkPolicy) EngineOption {
+	return func(e *Engine) {
+		e.networkPolicy = policy
+	}
+}
+
+// WithVerificationDryRun makes verification a dry run. The requests detectors
+// would verify results with aren't sent, and record is called with each of
+// them instead, with the secrets they carry redacted, so they can be reviewed
+// before verification is enabled. Results are reported unverified.
+func WithVerificationDryRun(record func(detector string, req common.DryRunRequest)) EngineOption {
+	return func(e *Engine) {
+		e.dryRun = record
+	}
+}
+
+// WithLogger sets the root logger for the engine and the sources and detectors
+// it runs, which log to loggers named after them. Nothing is logged by default.
+func WithLogger(logger logr.Logger) EngineOption {
+	return func(e *Engine) {
+		e.logger = logger
+	}
+}
+
+// WithScorer records each result's false positive likelihood, as scored by
+// scorer, in its ExtraData under detectors.FalsePositiveScoreKey, along with
+// the tag of its file under detectors.PathTagKey if scorer tags paths.
+func WithScorer(scorer detectors.Scorer) EngineOption {
+	return func(e *Engine) {
+		e.scorer = scorer
+	}
+}
+
+// WithSnippets records the data around each result, with its secret
+// redacted, in its ExtraData under detectors.SnippetKey.
+func WithSnippets(opts detectors.SnippetOptions) EngineOption {
+	return func(e *Engine) {
+		e.snippets = opts
+	}
+}
+
+// WithOCR reads the text of images with ocr, both the image files sources
+// scan and chunks that are images, besides their metadata.
+func WithOCR(ocr *handlers.OCR) EngineOption {
+	return func(e *Engine) {
+		e.ocr = ocr
+	}
+}
+
+// WithArchiveLimits limits how deeply archives are unpacked, and how much is
+// unpacked from them, to protect scans from decompression bombs. Limits that
+// are zero are the default ones.
+func WithArchiveLimits(limits handlers.Limits) EngineOption {
+	return func(e *Engine) {
+		e.archiveLimits = limits
+	}
+}
+
+// WithItemRetries sets how sources retry the items, such as objects and
+// repos, that fail with errors that may not recur, such as throttling and
+// connection resets. Retries that are zero are the default ones.
+func WithItemRetries(retries sources.Retries) EngineOption {
+	return func(e *Engine) {
+		e.itemRetries = retries
+	}
+}
+
+// WithTLSConfig configures the TLS connections of the HTTP based sources the
+// engine scans, such as a self-hosted GitLab behind a private CA.
+func WithTLSConfig(config *credentialspb.TLSConfig) EngineOption {
+	return func(e *Engine) {
+		e.tlsConfig = config
+	}
+}
+
+// WithProxy makes the HTTP based sources the engine scans connect through
+// proxy, such as to route scans of internet targets through an egress proxy
+// separate from internal traffic. Verification requests aren't affected.
+func WithProxy(proxy *credentialspb.Proxy) EngineOption {
+	return func(e *Engine) {
+		e.proxy = proxy
+	}
+}
+
+// WithBandwidthLimit limits the downloads of the sources the engine scans,
+// such as S3, to bytesPerSecond altogether. Sources may limit their own
+// downloads further.
+func WithBandwidthLimit(bytesPerSecond int64) EngineOption {
+	return func(e *Engine) {
+		e.bandwidth = common.NewBandwidthLimiter(bytesPerSecond)
+	}
+}
+
 func WithDecoders(decoders ...decoders.Decoder) EngineOption {
 	return func(e *Engine) {
 		e.decoders = decoders
 	}
 }
 
+// Start is an alias of NewEngine.
 func Start(ctx context.Context, options ...EngineOption) *Engine {
+	return NewEngine(ctx, options...)
+}
+
+// NewEngine returns an engine configured with options whose workers are already
+// waiting for chunks from sources added with AddSource.
+func NewEngine(ctx context.Context, options ...EngineOption) *Engine {
 	e := &Engine{
 		chunks:          make(chan *sources.Chunk),
 		results:         make(chan detectors.ResultWithMetadata),
 		detectorAvgTime: sync.Map{},
+		logger:          logr.Discard(),
+		started:         time.Now(),
 	}
 
 	for _, option := range options {
@@ -70,12 +254,14 @@ func Start(ctx context.Context, options ...EngineOption) *Engine {
 
 	// Set defaults.
 
+	e.log = e.logger.WithName("engine")
+
 	if e.concurrency == 0 {
 		numCPU := runtime.NumCPU()
-		logrus.Warn("No concurrency specified, defaulting to ", numCPU)
+		e.log.Info("no concurrency specified, defaulting to the number of CPUs", "concurrency", numCPU)
 		e.concurrency = numCPU
 	}
-	logrus.Debugf("running with up to %d workers", e.concurrency)
+	e.log.V(1).Info("running workers", "concurrency", e.concurrency)
 
 	if len(e.decoders) == 0 {
 		e.decoders = decoders.DefaultDecoders()
@@ -87,11 +273,29 @@ func Start(ctx context.Context, options ...EngineOption) *Engine {
 		e.detectors[false] = []detectors.Detector{}
 	}
 
-	logrus.Debugf("loaded %d decoders", len(e.decoders))
-	logrus.Debugf("loaded %d detectors total, %d with verification enabled. %d with verification disabled",
-		len(e.detectors[true])+len(e.detectors[false]),
-		len(e.detectors[true]),
-		len(e.detectors[false]))
+	if e.networkPolicy != nil && e.networkPolicy.Offline {
+		e.log.V(1).Info("offline mode enabled, skipping verification")
+		e.detectors[false] = append(e.detectors[false], e.detectors[true]...)
+		e.detectors[true] = []detectors.Detector{}
+	}
+
+	if e.safeVerificationOnly {
+		var safe []detectors.Detector
+		for _, d := range e.detectors[true] {
+			if detectors.HasSafeVerification(d) {
+				safe = append(safe, d)
+				continue
+			}
+			e.detectors[false] = append(e.detectors[false], d)
+		}
+		e.detectors[true] = safe
+	}
+
+	e.log.V(1).Info("loaded decoders", "count", len(e.decoders))
+	e.log.V(1).Info("loaded detectors",
+		"total", len(e.detectors[true])+len(e.detectors[false]),
+		"verification_enabled", len(e.detectors[true]),
+		"verification_disabled", len(e.detectors[false]))
 
 	// start the workers
 	for i := 0; i < e.concurrency; i++ {
@@ -115,6 +319,7 @@ func (e *Engine) Finish() {
 	// wait for the workers to finish processing all of the chunks and putting
 	// results onto the results channel
 	e.workersWg.Wait()
+	e.finished = time.Now()
 
 	// TODO: re-evaluate whether this is needed and investigate why if so
 	//
@@ -124,6 +329,145 @@ func (e *Engine) Finish() {
 	close(e.results)
 }
 
+// AddSource scans an initialized source in the background. Errors returned by
+// the source are logged and reported by SourceErrors.
+func (e *Engine) AddSource(ctx context.Context, source sources.Source) error {
+	if source == nil {
+		return errors.New("source is nil")
+	}
+	if listener, ok := source.(sources.Listener); ok {
+		e.addListener(sourceTypeName(source.Type()), listener)
+	}
+	e.runSource(e.sourceContext(ctx, source.Type()), source.Type(), source.GetProgress(), source.Chunks)
+	return nil
+}
+
+// runSource calls scan in the background, logging and recording any error it
+// returns so it's reported by SourceErrors. The chunks scan sends are counted
+// for SourceProgress, along with the source's progress if it reports any, and
+// tagged with the tenant in ctx. Sources of a tenant with limited concurrency
+// wait for their turn.
+func (e *Engine) runSource(ctx context.Context, sourceType sourcespb.SourceType, progress *sources.Progress, scan func(context.Context, chan *sources.Chunk) error) {
+	tenantName := sources.TenantFromContext(ctx)
+	state := e.addSourceState(sourceType, tenantName, progress)
+	chunksChan := make(chan *sources.Chunk)
+	e.sourcesWg.Add(1)
+	go func() {
+		defer e.sourcesWg.Done()
+		for chunk := range chunksChan {
+			if chunk.Tenant == "" {
+				chunk.Tenant = tenantName
+			}
+			state.record(chunk)
+			e.chunks <- chunk
+		}
+	}()
+	go func() {
+		// Closing chunksChan lets Finish proceed, so the error is recorded first.
+		defer close(chunksChan)
+		release, err := e.tenants[tenantName].acquire(ctx)
+		if err == nil {
+			err = scan(ctx, chunksChan)
+			release()
+		}
+		if err != nil {
+			log.FromContext(ctx).Error(err, "error scanning source")
+			e.sourceErrMu.Lock()
+			e.sourceErrs = append(e.sourceErrs, errors.WrapPrefix(err, sourceType.String(), 0))
+			e.sourceErrMu.Unlock()
+		}
+		state.finish(err)
+	}()
+}
+
+// sourceContext returns a context carrying the logger for sources of sourceType,
+// the archive limits, and the OCR and bandwidth limit if they're set, within
+// the bandwidth limit of the tenant in ctx. Sources read it when they are
+// initialized and while they are scanned.
+func (e *Engine) sourceContext(ctx context.Context, sourceType sourcespb.SourceType) context.Context {
+	ctx = handlers.WithLimits(ctx, e.archiveLimits)
+	ctx = sources.WithRetries(ctx, e.itemRetries)
+	if e.ocr != nil {
+		ctx = handlers.WithOCR(ctx, e.ocr)
+	}
+	bandwidth := e.bandwidth
+	if t := e.tenants[sources.TenantFromContext(ctx)]; t != nil {
+		bandwidth = t.bandwidth.Within(e.bandwidth)
+	}
+	if bandwidth != nil {
+		ctx = common.WithBandwidthLimiter(ctx, bandwidth)
+	}
+	logger := e.logger.WithName("source").WithName(sourceTypeName(sourceType))
+	if tenant := sources.TenantFromContext(ctx); tenant != "" {
+		logger = logger.WithValues("tenant", tenant)
+	}
+	return log.IntoContext(ctx, logger)
+}
+
+// sourceTypeName returns the short name of a source type, such as "git".
+func sourceTypeName(sourceType sourcespb.SourceType) string {
+	return strings.ToLower(strings.TrimPrefix(sourceType.String(), "SOURCE_TYPE_"))
+}
+
+func (e *Engine) addListener(name string, listener sources.Listener) {
+	e.listenersMu.Lock()
+	defer e.listenersMu.Unlock()
+	if e.listeners == nil {
+		e.listeners = make(map[string]sources.Listener)
+	}
+	key := name
+	for i := 2; e.listeners[key] != nil; i++ {
+		key = fmt.Sprintf("%s-%d", name, i)
+	}
+	e.listeners[key] = listener
+}
+
+// Listeners reports whether each source added that receives data pushed to it,
+// such as syslog, is listening. Sources are keyed by their type.
+func (e *Engine) Listeners() map[string]bool {
+	e.listenersMu.Lock()
+	defer e.listenersMu.Unlock()
+	listening := make(map[string]bool, len(e.listeners))
+	for name, listener := range e.listeners {
+		listening[name] = listener.Listening()
+	}
+	return listening
+}
+
+// LastVerified returns when a result was last verified, or the zero time if
+// none has been.
+func (e *Engine) LastVerified() time.Time {
+	nanos := atomic.LoadInt64(&e.lastVerified)
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// SourceErrors returns the errors returned by sources added with AddSource so far.
+func (e *Engine) SourceErrors() []error {
+	e.sourceErrMu.Lock()
+	defer e.sourceErrMu.Unlock()
+	return append([]error{}, e.sourceErrs...)
+}
+
+// Results returns the findings of the scan. The channel is closed once Finish
+// returns. Results and ResultsChan consume the same results, so only one of
+// them should be used.
+func (e *Engine) Results() <-chan *findingspb.Finding {
+	e.findingsOnce.Do(func() {
+		e.findings = make(chan *findingspb.Finding)
+		go func() {
+			defer close(e.findings)
+			for r := range e.results {
+				r := r
+				e.findings <- findings.FromResult(&r)
+			}
+		}()
+	})
+	return e.findings
+}
+
 func (e *Engine) ChunksChan() chan *sources.Chunk {
 	return e.chunks
 }
@@ -136,18 +480,24 @@ func (e *Engine) ChunksScanned() uint64 {
 	return e.chunksScanned
 }
 
+// ChunksSkipped returns the number of chunks that weren't scanned because
+// their content, such as images and video, can't hold secrets.
+func (e *Engine) ChunksSkipped() uint64 {
+	return atomic.LoadUint64(&e.chunksSkipped)
+}
+
 func (e *Engine) DetectorAvgTime() map[string][]time.Duration {
 	avgTime := map[string][]time.Duration{}
 	e.detectorAvgTime.Range(func(k, v interface{}) bool {
 		key, ok := k.(string)
 		if !ok {
-			logrus.Warnf("expected DetectorAvgTime key to be a string")
+			e.log.Info("expected DetectorAvgTime key to be a string")
 			return true
 		}
 
 		value, ok := v.([]time.Duration)
 		if !ok {
-			logrus.Warnf("expected DetectorAvgTime value to be []time.Duration")
+			e.log.Info("expected DetectorAvgTime value to be []time.Duration")
 			return true
 		}
 		avgTime[key] = value
@@ -157,66 +507,225 @@ func (e *Engine) DetectorAvgTime() map[string][]time.Duration {
 }
 
 func (e *Engine) detectorWorker(ctx context.Context) {
+	ctx = e.workerContext(ctx)
 	for chunk := range e.chunks {
-		fragStart, mdLine := fragmentFirstLine(chunk)
-		for _, decoder := range e.decoders {
-			decoded := decoder.FromChunk(chunk)
-			if decoded == nil {
-				continue
-			}
-			dataLower := strings.ToLower(string(decoded.Data))
-			for verify, detectorsSet := range e.detectors {
-				for _, detector := range detectorsSet {
-					start := time.Now()
-					foundKeyword := false
-					for _, kw := range detector.Keywords() {
-						if strings.Contains(dataLower, strings.ToLower(kw)) {
-							foundKeyword = true
-							break
-						}
+		e.routeChunk(ctx, chunk)
+		atomic.AddUint64(&e.chunksScanned, 1)
+	}
+}
+
+// workerContext returns a copy of ctx with the network policy, archive
+// limits and OCR chunks are scanned with, and the logger the handlers warn of
+// archives that reach the limits with.
+func (e *Engine) workerContext(ctx context.Context) context.Context {
+	if e.networkPolicy != nil {
+		ctx = common.WithNetworkPolicy(ctx, e.networkPolicy)
+	}
+	ctx = handlers.WithLimits(ctx, e.archiveLimits)
+	if e.ocr != nil {
+		ctx = handlers.WithOCR(ctx, e.ocr)
+	}
+	return log.IntoContext(ctx, e.logger.WithName("handlers"))
+}
+
+// ScanChunk scans a chunk of text and returns its results, instead of sending
+// them to the results channel, for callers that need to know which results a
+// chunk has, like editors asking about a document.
+func (e *Engine) ScanChunk(ctx context.Context, chunk *sources.Chunk) []detectors.ResultWithMetadata {
+	var results []detectors.ResultWithMetadata
+	e.scanChunk(e.workerContext(ctx), chunk, func(r detectors.ResultWithMetadata) {
+		results = append(results, r)
+	})
+	atomic.AddUint64(&e.chunksScanned, 1)
+	return results
+}
+
+// sendResult sends a result to the results channel.
+func (e *Engine) sendResult(r detectors.ResultWithMetadata) {
+	e.results <- r
+}
+
+// routeChunk scans a chunk by the kind of its content, sniffing its content
+// type if the source didn't set it. Text is scanned as is, and fonts and
+// compressed formats that can't be unpacked are skipped. The text held by
+// archives, documents and binaries, and the metadata of media, along with the
+// text of images when OCR is set, is extracted and scanned in their place, so
+// secrets in them are found and their raw bytes aren't scanned for nothing.
+func (e *Engine) routeChunk(ctx context.Context, chunk *sources.Chunk) {
+	if chunk.ContentType == "" {
+		chunk.ContentType = handlers.ContentType(chunk.Data)
+	}
+	switch handlers.KindOf(chunk.ContentType) {
+	case handlers.TextContent:
+		e.scanChunk(ctx, chunk, e.sendResult)
+		return
+	case handlers.IgnoredContent:
+		atomic.AddUint64(&e.chunksSkipped, 1)
+		return
+	}
+
+	extracted := make(chan *sources.Chunk)
+	go func() {
+		defer close(extracted)
+		if err := handlers.HandleChunk(ctx, chunk, extracted); err != nil {
+			e.log.V(2).Info("could not extract the text of chunk", "content_type", chunk.ContentType, "error", err.Error())
+		}
+	}()
+	for textChunk := range extracted {
+		e.scanChunk(ctx, textChunk, e.sendResult)
+	}
+}
+
+// scanChunk decodes a chunk and scans it with each detector whose keywords it
+// contains, passing its results to emit.
+func (e *Engine) scanChunk(ctx context.Context, chunk *sources.Chunk, emit func(detectors.ResultWithMetadata)) {
+	fragStart, mdLine := fragmentFirstLine(chunk)
+	for _, decoder := range e.decoders {
+		decoded := decoder.FromChunk(chunk)
+		if decoded == nil {
+			continue
+		}
+		dataLower := strings.ToLower(string(decoded.Data))
+		for verify, detectorsSet := range e.detectors {
+			for _, detector := range detectorsSet {
+				start := time.Now()
+				foundKeyword := false
+				for _, kw := range detector.Keywords() {
+					if strings.Contains(dataLower, strings.ToLower(kw)) {
+						foundKeyword = true
+						break
 					}
-					if !foundKeyword {
+				}
+				if !foundKeyword {
+					continue
+				}
+				name := detectorName(detector)
+				stats := e.detectorCounters(name)
+				atomic.AddUint64(&stats.chunks, 1)
+				detectorLog := e.logger.WithName("detector").WithName(name).WithValues(
+					"source_type", decoded.SourceType.String(),
+					"source_name", decoded.SourceName,
+				)
+				ctx, cancel := context.WithTimeout(log.IntoContext(ctx, detectorLog), time.Second*10)
+				defer cancel()
+				scanStart := time.Now()
+				results, err := e.fromData(ctx, detector, verify, decoded.Data)
+				if err != nil {
+					atomic.AddUint64(&stats.errors, 1)
+					detectorLog.Error(err, "could not scan chunk", "metadata", decoded.SourceMetadata.String())
+					continue
+				}
+				if verify && len(results) > 0 {
+					atomic.AddUint64(&stats.verifications, 1)
+					atomic.AddUint64(&stats.verificationNanos, uint64(time.Since(scanStart)))
+				}
+				for _, result := range results {
+					if detectors.IsCustomFalsePositive(result) {
 						continue
 					}
-					ctx, cancel := context.WithTimeout(ctx, time.Second*10)
-					defer cancel()
-					results, err := detector.FromData(ctx, verify, decoded.Data)
-					if err != nil {
-						logrus.WithFields(logrus.Fields{
-							"source_type": decoded.SourceType.String(),
-							"metadata":    decoded.SourceMetadata,
-						}).WithError(err).Error("could not scan chunk")
-						continue
+					atomic.AddUint64(&stats.matches, 1)
+					if result.Verified {
+						atomic.AddUint64(&stats.verified, 1)
+						atomic.StoreInt64(&e.lastVerified, time.Now().UnixNano())
 					}
-					for _, result := range results {
-						if isGitSource(chunk.SourceType) {
-							offset := FragmentLineOffset(chunk, &result)
-							*mdLine = fragStart + offset
+					// Chunks of patches are fragments of diffs too.
+					if isGitSource(chunk.SourceType) || chunk.SourceType == sourcespb.SourceType_SOURCE_TYPE_PATCH {
+						offset := FragmentLineOffset(chunk, &result)
+						*mdLine = fragStart + offset
+					}
+					r := detectors.CopyMetadata(chunk, result)
+					if e.scorer != nil {
+						detectors.SetFalsePositiveScore(&r, e.scorer.Score(&r, decoded.Data))
+						if tagger, ok := e.scorer.(detectors.PathTagger); ok {
+							detectors.SetPathTag(&r, tagger.PathTag(&r))
 						}
-						e.results <- detectors.CopyMetadata(chunk, result)
-
 					}
-					if len(results) > 0 {
-						elapsed := time.Since(start)
-						detectorName := results[0].DetectorType.String()
-						avgTimeI, ok := e.detectorAvgTime.Load(detectorName)
-						var avgTime []time.Duration
-						if ok {
-							avgTime, ok = avgTimeI.([]time.Duration)
-							if !ok {
-								continue
-							}
+					detectors.SetSnippet(&r, decoded.Data, e.snippets)
+					emit(r)
+				}
+				if len(results) > 0 {
+					elapsed := time.Since(start)
+					detectorName := results[0].DetectorType.String()
+					avgTimeI, ok := e.detectorAvgTime.Load(detectorName)
+					var avgTime []time.Duration
+					if ok {
+						avgTime, ok = avgTimeI.([]time.Duration)
+						if !ok {
+							continue
 						}
-						avgTime = append(avgTime, elapsed)
-						e.detectorAvgTime.Store(detectorName, avgTime)
 					}
+					avgTime = append(avgTime, elapsed)
+					e.detectorAvgTime.Store(detectorName, avgTime)
 				}
 			}
 		}
-		atomic.AddUint64(&e.chunksScanned, 1)
 	}
 }
 
+// fromData runs a detector on data. When verification is limited or a dry
+// run, the detector first runs without verifying, and runs again verifying
+// only if the limiter allows it, with the secrets it found redacted from the
+// requests a dry run records.
+func (e *Engine) fromData(ctx context.Context, detector detectors.Detector, verify bool, data []byte) ([]detectors.Result, error) {
+	if !verify || (e.limiter == nil && e.dryRun == nil) {
+		return detector.FromData(ctx, verify, data)
+	}
+
+	results, err := detector.FromData(ctx, false, data)
+	if err != nil || len(results) == 0 {
+		return results, err
+	}
+	if e.limiter != nil {
+		ok, exhausted := e.limiter.allow(results)
+		if exhausted {
+			e.budgetSpentOnce.Do(func() {
+				e.log.Info("verification budget spent, remaining results won't be verified", "budget", e.limiter.budget)
+			})
+		}
+		if !ok {
+			e.limiter.apply(results)
+			return results, nil
+		}
+	}
+	if e.dryRun != nil {
+		ctx = e.dryRunContext(ctx, detectorName(detector), results)
+	}
+
+	results, err = detector.FromData(ctx, true, data)
+	if err != nil {
+		return nil, err
+	}
+	if e.limiter != nil {
+		e.limiter.record(results)
+	}
+	return results, nil
+}
+
+// dryRunContext returns a copy of ctx in which the requests a detector sends
+// to verify results are recorded instead, with the results' secrets redacted.
+func (e *Engine) dryRunContext(ctx context.Context, detector string, results []detectors.Result) context.Context {
+	var secrets []string
+	for _, result := range results {
+		secrets = append(secrets, string(result.Raw))
+	}
+	return common.WithDryRun(ctx, &common.DryRun{
+		Record: func(req common.DryRunRequest) {
+			e.dryRun(detector, req)
+		},
+		Secrets: secrets,
+	})
+}
+
+// detectorName returns the name of the package that implements d, which is
+// the name its logger is given.
+func detectorName(d detectors.Detector) string {
+	name := strings.TrimPrefix(fmt.Sprintf("%T", d), "*")
+	if i := strings.Index(name, "."); i > 0 {
+		name = name[:i]
+	}
+	return name
+}
+
 // gitSources is a list of sources that utilize the Git source. It is stored this way because slice consts are not
 // supported.
 func gitSources() []sourcespb.SourceType {
@@ -266,10 +775,10 @@ func fragmentFirstLine(chunk *sources.Chunk) (int64, *int64) {
 		fragmentStart = &metadata.Bitbucket.Line
 	case *source_metadatapb.MetaData_Gerrit:
 		fragmentStart = &metadata.Gerrit.Line
+	case *source_metadatapb.MetaData_Patch:
+		fragmentStart = &metadata.Patch.Line
 	default:
 		return 0, nil
 	}
 	return *fragmentStart, fragmentStart
 }
-
-#test
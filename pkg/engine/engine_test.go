@@ -0,0 +1,428 @@
+package engine
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-errors/errors"
+	"github.com/go-logr/logr"
+	"github.com/kylelemons/godebug/pretty"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// fakeDetector reports every "fake_" prefixed token it sees.
+type fakeDetector struct{}
+
+var fakeTokenPat = regexp.MustCompile(`fake_[a-z0-9]+`)
+
+func (fakeDetector) Keywords() []string { return []string{"fake_"} }
+
+func (fakeDetector) FromData(_ context.Context, _ bool, data []byte) ([]detectors.Result, error) {
+	var results []detectors.Result
+	for _, match := range fakeTokenPat.FindAll(data, -1) {
+		results = append(results, detectors.Result{DetectorType: detectorspb.DetectorType_Generic, Raw: match})
+	}
+	return results, nil
+}
+
+// fakeSource emits each of its chunks, recording them as downloaded, and
+// then returns err.
+type fakeSource struct {
+	sources.Progress
+	chunks []string
+	err    error
+}
+
+func (s *fakeSource) Type() sourcespb.SourceType { return sourcespb.SourceType_SOURCE_TYPE_TEST }
+func (s *fakeSource) SourceID() int64            { return 0 }
+func (s *fakeSource) JobID() int64               { return 0 }
+
+func (s *fakeSource) Init(context.Context, string, int64, int64, bool, *anypb.Any, int) error {
+	return nil
+}
+
+func (s *fakeSource) Chunks(_ context.Context, chunksChan chan *sources.Chunk) error {
+	for _, data := range s.chunks {
+		s.AddBytesDownloaded(int64(len(data)))
+		chunksChan <- &sources.Chunk{SourceType: s.Type(), SourceName: "fake", Data: []byte(data)}
+	}
+	return s.err
+}
+
+func TestEngine_AddSource(t *testing.T) {
+	ctx := context.Background()
+	var logs bytes.Buffer
+	logger, err := log.New(log.Config{Format: log.FormatJSON, Level: log.DebugLevel, Output: &logs})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewEngine(ctx, WithConcurrency(2), WithDetectors(false, fakeDetector{}), WithLogger(logger))
+	if err := e.AddSource(ctx, &fakeSource{chunks: []string{"token fake_abc123", "nothing here"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.AddSource(ctx, &fakeSource{err: errors.New("listing failed")}); err != nil {
+		t.Fatal(err)
+	}
+	go e.Finish()
+
+	var got []string
+	for finding := range e.Results() {
+		got = append(got, string(finding.Raw))
+		if finding.Id == "" || finding.SourceName != "fake" {
+			t.Errorf("unexpected finding: %v", finding)
+		}
+	}
+	if len(got) != 1 || got[0] != "fake_abc123" {
+		t.Errorf("unexpected findings: %v", got)
+	}
+
+	if errs := e.SourceErrors(); len(errs) != 1 {
+		t.Errorf("expected 1 source error, got %v", errs)
+	}
+	if !strings.Contains(logs.String(), "listing failed") {
+		t.Errorf("expected the source error to be logged to the injected logger, got %q", logs.String())
+	}
+
+	progress := e.SourceProgress()
+	if len(progress) != 2 {
+		t.Fatalf("expected progress for 2 sources, got %v", progress)
+	}
+	if p := progress[0]; p.Name != "test" || p.Chunks != 2 || !p.Done || p.Err != nil || p.BytesDownloaded != 29 || p.BytesPerSecond <= 0 {
+		t.Errorf("unexpected progress for the first source: %+v", p)
+	}
+	if p := progress[1]; p.Name != "test-2" || p.Chunks != 0 || !p.Done || p.Err == nil {
+		t.Errorf("unexpected progress for the failed source: %+v", p)
+	}
+}
+
+// verifyingDetector reports fake tokens, counting how often it verifies them.
+// Tokens ending in "live" verify.
+type verifyingDetector struct {
+	fakeDetector
+	verifications int
+}
+
+func (d *verifyingDetector) FromData(ctx context.Context, verify bool, data []byte) ([]detectors.Result, error) {
+	results, err := d.fakeDetector.FromData(ctx, verify, data)
+	if verify {
+		for i := range results {
+			d.verifications++
+			results[i].Verified = strings.HasSuffix(string(results[i].Raw), "live")
+		}
+	}
+	return results, err
+}
+
+func TestEngine_verifying(t *testing.T) {
+	for _, verify := range []bool{true, false} {
+		e := &Engine{}
+		WithDetectors(verify, fakeDetector{})(e)
+		if got := e.verifying(); got != verify {
+			t.Errorf("verifying() with WithDetectors(%v) = %v", verify, got)
+		}
+	}
+}
+
+func TestEngine_fromData_LimitedVerification(t *testing.T) {
+	chunks := []string{"fake_alive", "fake_alive", "fake_alive fake_dead", "fake_dead", "fake_newlive", "fake_alive"}
+	tests := []struct {
+		name              string
+		options           []EngineOption
+		chunks            []string
+		wantVerified      []bool
+		wantVerifications int
+	}{
+		{
+			name:              "unlimited",
+			wantVerified:      []bool{true, true, true, false, false, true, true},
+			wantVerifications: 7,
+		},
+		{
+			name:              "sample",
+			options:           []EngineOption{WithVerificationSample(1)},
+			wantVerified:      []bool{true, true, true, false, false, true, true},
+			wantVerifications: 4,
+		},
+		{
+			name:              "budget",
+			options:           []EngineOption{WithVerificationBudget(3)},
+			wantVerified:      []bool{true, true, true, false, false, false, true},
+			wantVerifications: 3,
+		},
+		{
+			name:              "budget crossed by one chunk",
+			options:           []EngineOption{WithVerificationBudget(1)},
+			chunks:            []string{"fake_alive fake_dead", "fake_alive"},
+			wantVerified:      []bool{false, false, true},
+			wantVerifications: 1,
+		},
+		{
+			name:              "budget and sample",
+			options:           []EngineOption{WithVerificationBudget(1), WithVerificationSample(1)},
+			wantVerified:      []bool{true, true, true, false, false, false, true},
+			wantVerifications: 1,
+		},
+		{
+			name:              "sampled results don't spend the budget",
+			options:           []EngineOption{WithVerificationBudget(2), WithVerificationSample(1)},
+			chunks:            []string{"fake_alive", "fake_alive fake_dead", "fake_newlive"},
+			wantVerified:      []bool{true, true, false, false},
+			wantVerifications: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Engine{log: logr.Discard()}
+			for _, option := range tt.options {
+				option(e)
+			}
+			d := &verifyingDetector{}
+			if tt.chunks == nil {
+				tt.chunks = chunks
+			}
+
+			var verified []bool
+			for _, chunk := range tt.chunks {
+				results, err := e.fromData(context.Background(), d, true, []byte(chunk))
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, result := range results {
+					verified = append(verified, result.Verified)
+				}
+			}
+			if diff := pretty.Compare(verified, tt.wantVerified); diff != "" {
+				t.Errorf("fromData() verified diff: (-got +want)\n%s", diff)
+			}
+			if d.verifications != tt.wantVerifications {
+				t.Errorf("verifications = %d, want %d", d.verifications, tt.wantVerifications)
+			}
+		})
+	}
+}
+
+func TestVerificationLimiter(t *testing.T) {
+	result := func(raw string) detectors.Result {
+		return detectors.Result{DetectorType: detectorspb.DetectorType_AWS, Raw: []byte(raw)}
+	}
+
+	t.Run("exhausted only once the budget is spent", func(t *testing.T) {
+		l := &verificationLimiter{budget: 2, secrets: map[secretKey]*secretVerification{}}
+		steps := []struct {
+			results       []detectors.Result
+			ok, exhausted bool
+		}{
+			{results: []detectors.Result{result("a"), result("b"), result("c")}, ok: false, exhausted: false},
+			{results: []detectors.Result{result("a")}, ok: true, exhausted: false},
+			{results: []detectors.Result{result("b")}, ok: true, exhausted: true},
+			{results: []detectors.Result{result("c")}, ok: false, exhausted: true},
+		}
+		for i, step := range steps {
+			ok, exhausted := l.allow(step.results)
+			if ok != step.ok || exhausted != step.exhausted {
+				t.Errorf("step %d: allow() = %v, %v, want %v, %v", i, ok, exhausted, step.ok, step.exhausted)
+			}
+		}
+	})
+
+	t.Run("sampled results keep their first outcome", func(t *testing.T) {
+		l := &verificationLimiter{sample: 1, secrets: map[secretKey]*secretVerification{}}
+		first := []detectors.Result{result("a")}
+		first[0].Verified = true
+		if ok, _ := l.allow(first); !ok {
+			t.Fatal("allow() = false for a new secret")
+		}
+		l.record(first)
+
+		second := []detectors.Result{result("a"), result("b")}
+		if ok, _ := l.allow(second); !ok {
+			t.Fatal("allow() = false for a chunk with a new secret")
+		}
+		l.record(second)
+		if !second[0].Verified {
+			t.Errorf("sampled secret's re-verification replaced its first outcome")
+		}
+		if got := l.secrets[keyOf(result("a"))].verifications; got != 1 {
+			t.Errorf("sampled secret recorded %d verifications, want 1", got)
+		}
+		if got := l.spent; got != 2 {
+			t.Errorf("spent = %d, want 2", got)
+		}
+	})
+}
+
+// requestingDetector reports fake tokens, verifying them with a request to
+// endpoint that has the token in its path.
+type requestingDetector struct {
+	fakeDetector
+	endpoint string
+}
+
+func (d requestingDetector) FromData(ctx context.Context, verify bool, data []byte) ([]detectors.Result, error) {
+	results, err := d.fakeDetector.FromData(ctx, verify, data)
+	if verify {
+		for i := range results {
+			req, err := http.NewRequestWithContext(ctx, "GET", d.endpoint+"/tokens/"+string(results[i].Raw), nil)
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Authorization", "Bearer "+string(results[i].Raw))
+			res, err := common.SaneHttpClient().Do(req)
+			if err != nil {
+				continue
+			}
+			res.Body.Close()
+			results[i].Verified = res.StatusCode == http.StatusOK
+		}
+	}
+	return results, err
+}
+
+func TestEngine_fromData_VerificationDryRun(t *testing.T) {
+	sent := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { sent = true }))
+	defer server.Close()
+
+	type request struct {
+		detector string
+		req      common.DryRunRequest
+	}
+	var recorded []request
+	e := &Engine{log: logr.Discard()}
+	WithVerificationDryRun(func(detector string, req common.DryRunRequest) {
+		recorded = append(recorded, request{detector, req})
+	})(e)
+
+	results, err := e.fromData(context.Background(), requestingDetector{endpoint: server.URL}, true, []byte("fake_abc123"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent {
+		t.Error("a verification request was sent in a dry run")
+	}
+	if len(results) != 1 || results[0].Verified {
+		t.Errorf("fromData() = %+v, want one unverified result", results)
+	}
+	want := []request{{
+		detector: "engine",
+		req: common.DryRunRequest{
+			Method: "GET",
+			URL:    server.URL + "/tokens/REDACTED",
+			Header: http.Header{"Authorization": {"REDACTED"}, "User-Agent": {"TruffleHog"}},
+		},
+	}}
+	if diff := pretty.Compare(recorded, want); diff != "" {
+		t.Errorf("recorded requests diff: (-got +want)\n%s", diff)
+	}
+}
+
+func TestEngine_RoutesChunksByContent(t *testing.T) {
+	ctx := context.Background()
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	w, err := zw.Create("config/.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("TOKEN=fake_zipped\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewEngine(ctx, WithConcurrency(1), WithDetectors(false, fakeDetector{}))
+	chunks := []string{
+		"token fake_text",
+		archive.String(),
+		"\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR fake_image",
+		"\x00\x01fake_binary\x00",
+		"wOFF\x00\x01\x00\x00 fake_font",
+		// Text that starts with the magic bytes of media formats.
+		"BMW_KEY=fake_bmp",
+		"ID3_TOKEN=fake_mp3",
+		"GIF87a password=fake_gif",
+		"II*\x00 fake_tiff",
+	}
+	if err := e.AddSource(ctx, &fakeSource{chunks: chunks}); err != nil {
+		t.Fatal(err)
+	}
+	go e.Finish()
+
+	var got []string
+	for finding := range e.Results() {
+		got = append(got, string(finding.Raw))
+	}
+	sort.Strings(got)
+	want := []string{"fake_binary", "fake_bmp", "fake_gif", "fake_mp3", "fake_text", "fake_tiff", "fake_zipped"}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("findings diff: (-got +want)\n%s", diff)
+	}
+	if skipped := e.ChunksSkipped(); skipped != 1 {
+		t.Errorf("ChunksSkipped() = %d, want 1", skipped)
+	}
+}
+
+func TestEngine_OCR(t *testing.T) {
+	ctx := context.Background()
+
+	ocr := &handlers.OCR{Command: []string{"sh", "-c", "cat >/dev/null; echo token fake_screenshot"}}
+	e := NewEngine(ctx, WithConcurrency(1), WithDetectors(false, fakeDetector{}), WithOCR(ocr))
+	chunks := []string{
+		"\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR",
+		"wOFF\x00\x01\x00\x00 fake_font",
+	}
+	if err := e.AddSource(ctx, &fakeSource{chunks: chunks}); err != nil {
+		t.Fatal(err)
+	}
+	go e.Finish()
+
+	var got []string
+	for finding := range e.Results() {
+		got = append(got, string(finding.Raw))
+	}
+	if diff := pretty.Compare(got, []string{"fake_screenshot"}); diff != "" {
+		t.Errorf("findings diff: (-got +want)\n%s", diff)
+	}
+	if skipped := e.ChunksSkipped(); skipped != 1 {
+		t.Errorf("ChunksSkipped() = %d, want 1", skipped)
+	}
+}
+
+func TestEngine_ScanChunk(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(ctx, WithConcurrency(1), WithDetectors(false, fakeDetector{}))
+	defer e.Finish()
+
+	results := e.ScanChunk(ctx, &sources.Chunk{
+		SourceType: sourcespb.SourceType_SOURCE_TYPE_TEST,
+		Data:       []byte("one fake_one\ntwo fake_two"),
+	})
+	var got []string
+	for _, r := range results {
+		got = append(got, string(r.Raw))
+	}
+	if diff := pretty.Compare(got, []string{"fake_one", "fake_two"}); diff != "" {
+		t.Errorf("ScanChunk() results diff: (-got +want)\n%s", diff)
+	}
+	if scanned := e.ChunksScanned(); scanned != 1 {
+		t.Errorf("ChunksScanned() = %d, want 1", scanned)
+	}
+}
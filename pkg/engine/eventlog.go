@@ -0,0 +1,37 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/eventlog"
+)
+
+// ScanWindowsEventLog scans the events in the given channels of the local
+// Windows Event Log that match query. When follow is set it keeps scanning new
+// events until the context is cancelled.
+func (e *Engine) ScanWindowsEventLog(ctx context.Context, channels []string, query string, follow bool) error {
+	ctx = e.sourceContext(ctx, sourcespb.SourceType_SOURCE_TYPE_WINDOWS_EVENT_LOG)
+	connection := &sourcespb.WindowsEventLog{
+		Channels: channels,
+		Query:    query,
+		Follow:   follow,
+	}
+
+	var conn anypb.Any
+	err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{})
+	if err != nil {
+		return errors.WrapPrefix(err, "could not marshal event log connection", 0)
+	}
+
+	eventLogSource := eventlog.Source{}
+	err = eventLogSource.Init(ctx, "trufflehog - windows event log", 0, int64(sourcespb.SourceType_SOURCE_TYPE_WINDOWS_EVENT_LOG), e.verifying(), &conn, 1)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not init event log source", 0)
+	}
+	return e.AddSource(ctx, &eventLogSource)
+}
@@ -5,7 +5,6 @@ import (
 	"runtime"
 
 	"github.com/go-errors/errors"
-	"github.com/sirupsen/logrus"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/filesystem"
 	"google.golang.org/protobuf/proto"
@@ -13,13 +12,14 @@ import (
 )
 
 func (e *Engine) ScanFileSystem(ctx context.Context, directories []string) error {
+	ctx = e.sourceContext(ctx, sourcespb.SourceType_SOURCE_TYPE_FILESYSTEM)
 	connection := &sourcespb.Filesystem{
 		Directories: directories,
 	}
 	var conn anypb.Any
 	err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{})
 	if err != nil {
-		logrus.WithError(err).Error("failed to marshal filesystem connection")
+		e.log.Error(err, "failed to marshal filesystem connection")
 		return err
 	}
 
@@ -28,13 +28,5 @@ func (e *Engine) ScanFileSystem(ctx context.Context, directories []string) error
 	if err != nil {
 		return errors.WrapPrefix(err, "could not init filesystem source", 0)
 	}
-	e.sourcesWg.Add(1)
-	go func() {
-		defer e.sourcesWg.Done()
-		err := fileSystemSource.Chunks(ctx, e.ChunksChan())
-		if err != nil {
-			logrus.WithError(err).Error("error scanning filesystem")
-		}
-	}()
-	return nil
+	return e.AddSource(ctx, &fileSystemSource)
 }
@@ -0,0 +1,38 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/fswatch"
+)
+
+// ScanFilesystemWatcher watches directories and scans the files created or
+// changed in them once they've gone unchanged for debounce, until ctx is
+// cancelled. Files and directories matching an ignore pattern aren't scanned.
+func (e *Engine) ScanFilesystemWatcher(ctx context.Context, directories, ignore []string, debounce time.Duration) error {
+	ctx = e.sourceContext(ctx, sourcespb.SourceType_SOURCE_TYPE_FILESYSTEM_WATCHER)
+	connection := &sourcespb.FilesystemWatcher{
+		Directories: directories,
+		Ignore:      ignore,
+		DebounceMs:  debounce.Milliseconds(),
+	}
+
+	var conn anypb.Any
+	err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{})
+	if err != nil {
+		return errors.WrapPrefix(err, "could not marshal filesystem watcher connection", 0)
+	}
+
+	watcherSource := fswatch.Source{}
+	err = watcherSource.Init(ctx, "trufflehog - filesystem watcher", 0, int64(sourcespb.SourceType_SOURCE_TYPE_FILESYSTEM_WATCHER), true, &conn, 1)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not init filesystem watcher source", 0)
+	}
+	return e.AddSource(ctx, &watcherSource)
+}
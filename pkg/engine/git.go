@@ -10,14 +10,16 @@ import (
 
 	gogit "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
-	"github.com/sirupsen/logrus"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
 )
 
-func (e *Engine) ScanGit(ctx context.Context, repoPath, headRef, baseRef string, maxDepth int, filter *common.Filter) error {
+// ScanGit scans the repo at repoPath. Options, such as a CommitFilter, are
+// applied after those of the other arguments.
+func (e *Engine) ScanGit(ctx context.Context, repoPath, headRef, baseRef string, maxDepth int, filter *common.Filter, options ...git.ScanOption) error {
 	logOptions := &gogit.LogOptions{}
 	opts := []git.ScanOption{
 		git.ScanOptionFilter(filter),
@@ -85,31 +87,48 @@ func (e *Engine) ScanGit(ctx context.Context, repoPath, headRef, baseRef string,
 	if headRef != "" {
 		opts = append(opts, git.ScanOptionHeadCommit(headRef))
 	}
+	opts = append(opts, options...)
 	scanOptions := git.NewScanOptions(opts...)
 
-	gitSource := git.NewGit(sourcespb.SourceType_SOURCE_TYPE_GIT, 0, 0, "trufflehog - git", true, runtime.NumCPU(),
-		func(file, email, commit, timestamp, repository string, line int64) *source_metadatapb.MetaData {
-			return &source_metadatapb.MetaData{
-				Data: &source_metadatapb.MetaData_Git{
-					Git: &source_metadatapb.Git{
-						Commit:     commit,
-						File:       file,
-						Email:      email,
-						Repository: repository,
-						Timestamp:  timestamp,
-						Line:       line,
-					},
-				},
-			}
-		})
+	gitSource := git.NewGit(sourcespb.SourceType_SOURCE_TYPE_GIT, 0, 0, "trufflehog - git", true, runtime.NumCPU(), gitMetadata)
 
-	e.sourcesWg.Add(1)
-	go func() {
-		defer e.sourcesWg.Done()
-		err := gitSource.ScanRepo(ctx, repo, repoPath, scanOptions, e.ChunksChan())
-		if err != nil {
-			logrus.WithError(err).Fatal("could not scan repo")
-		}
-	}()
+	ctx = e.sourceContext(ctx, sourcespb.SourceType_SOURCE_TYPE_GIT)
+	e.runSource(ctx, sourcespb.SourceType_SOURCE_TYPE_GIT, nil, func(ctx context.Context, chunksChan chan *sources.Chunk) error {
+		return gitSource.ScanRepo(ctx, repo, repoPath, scanOptions, chunksChan)
+	})
 	return nil
 }
+
+// ScanGitObjects scans the objects of a git bundle file, or of a directory of
+// packfiles, without cloning a repository from them.
+func (e *Engine) ScanGitObjects(ctx context.Context, path string, filter *common.Filter) error {
+	store, err := git.OpenObjects(path)
+	if err != nil {
+		return err
+	}
+	scanOptions := git.NewScanOptions(git.ScanOptionFilter(filter))
+	gitSource := git.NewGit(sourcespb.SourceType_SOURCE_TYPE_GIT, 0, 0, "trufflehog - git", true, runtime.NumCPU(), gitMetadata)
+
+	ctx = e.sourceContext(ctx, sourcespb.SourceType_SOURCE_TYPE_GIT)
+	e.runSource(ctx, sourcespb.SourceType_SOURCE_TYPE_GIT, nil, func(ctx context.Context, chunksChan chan *sources.Chunk) error {
+		defer store.Close()
+		return gitSource.ScanObjects(ctx, store, path, scanOptions, chunksChan)
+	})
+	return nil
+}
+
+// gitMetadata is the source metadata of chunks from git.
+func gitMetadata(file, email, commit, timestamp, repository string, line int64) *source_metadatapb.MetaData {
+	return &source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_Git{
+			Git: &source_metadatapb.Git{
+				Commit:     commit,
+				File:       file,
+				Email:      email,
+				Repository: repository,
+				Timestamp:  timestamp,
+				Line:       line,
+			},
+		},
+	}
+}
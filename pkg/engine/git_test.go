@@ -18,7 +18,7 @@ type expResult struct {
 
 func TestGitEngine(t *testing.T) {
 	repoUrl := "https://github.com/dustin-decker/secretsandstuff.git"
-	path, _, err := git.PrepareRepo(repoUrl)
+	path, _, err := git.PrepareRepo(context.Background(), repoUrl)
 	if err != nil {
 		t.Error(err)
 	}
@@ -80,7 +80,7 @@ func TestGitEngine(t *testing.T) {
 
 func BenchmarkGitEngine(b *testing.B) {
 	repoUrl := "https://github.com/dustin-decker/secretsandstuff.git"
-	path, _, err := git.PrepareRepo(repoUrl)
+	path, _, err := git.PrepareRepo(context.Background(), repoUrl)
 	if err != nil {
 		b.Error(err)
 	}
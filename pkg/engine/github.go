@@ -3,7 +3,6 @@ package engine
 import (
 	"context"
 
-	"github.com/sirupsen/logrus"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 
@@ -12,13 +11,21 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/github"
 )
 
-func (e *Engine) ScanGitHub(ctx context.Context, endpoint string, repos, orgs []string, token string, includeForks bool, filter *common.Filter, concurrency int, includeMembers bool) error {
+func (e *Engine) ScanGitHub(ctx context.Context, endpoint string, repos, orgs []string, token string, includeForks bool, filter *common.Filter, concurrency int, includeMembers, includeGists, includeAuditLog, includeComments, includeWikis, scanForkNetwork bool) error {
+	ctx = e.sourceContext(ctx, sourcespb.SourceType_SOURCE_TYPE_GITHUB)
 	source := github.Source{}
 	connection := sourcespb.GitHub{
-		Endpoint:      endpoint,
-		Organizations: orgs,
-		Repositories:  repos,
-		ScanUsers:     includeMembers,
+		Endpoint:        endpoint,
+		Organizations:   orgs,
+		Repositories:    repos,
+		ScanUsers:       includeMembers,
+		IncludeGists:    includeGists,
+		IncludeAuditLog: includeAuditLog,
+		IncludeComments: includeComments,
+		IncludeWikis:    includeWikis,
+		ScanForkNetwork: scanForkNetwork,
+		Tls:             e.tlsConfig,
+		Proxy:           e.proxy,
 	}
 	if len(token) > 0 {
 		connection.Credential = &sourcespb.GitHub_Token{
@@ -31,22 +38,14 @@ func (e *Engine) ScanGitHub(ctx context.Context, endpoint string, repos, orgs []
 	var conn anypb.Any
 	err := anypb.MarshalFrom(&conn, &connection, proto.MarshalOptions{})
 	if err != nil {
-		logrus.WithError(err).Error("failed to marshal github connection")
+		e.log.Error(err, "failed to marshal github connection")
 		return err
 	}
 	err = source.Init(ctx, "trufflehog - github", 0, 0, false, &conn, concurrency)
 	if err != nil {
-		logrus.WithError(err).Error("failed to initialize github source")
+		e.log.Error(err, "failed to initialize github source")
 		return err
 	}
 
-	e.sourcesWg.Add(1)
-	go func() {
-		defer e.sourcesWg.Done()
-		err := source.Chunks(ctx, e.ChunksChan())
-		if err != nil {
-			logrus.WithError(err).Fatal("could not scan github")
-		}
-	}()
-	return nil
+	return e.AddSource(ctx, &source)
 }
@@ -5,7 +5,6 @@ import (
 	"runtime"
 
 	"github.com/go-errors/errors"
-	"github.com/sirupsen/logrus"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/gitlab"
 	"golang.org/x/net/context"
@@ -14,7 +13,8 @@ import (
 )
 
 func (e *Engine) ScanGitLab(ctx context.Context, endpoint, token string, repositories []string) error {
-	connection := &sourcespb.GitLab{}
+	ctx = e.sourceContext(ctx, sourcespb.SourceType_SOURCE_TYPE_GITLAB)
+	connection := &sourcespb.GitLab{Tls: e.tlsConfig, Proxy: e.proxy}
 
 	switch {
 	case len(token) > 0:
@@ -36,7 +36,7 @@ func (e *Engine) ScanGitLab(ctx context.Context, endpoint, token string, reposit
 	var conn anypb.Any
 	err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{})
 	if err != nil {
-		logrus.WithError(err).Error("failed to marshal gitlab connection")
+		e.log.Error(err, "failed to marshal gitlab connection")
 		return err
 	}
 
@@ -46,13 +46,5 @@ func (e *Engine) ScanGitLab(ctx context.Context, endpoint, token string, reposit
 		return errors.WrapPrefix(err, "could not init GitLab source", 0)
 	}
 
-	e.sourcesWg.Add(1)
-	go func() {
-		defer e.sourcesWg.Done()
-		err := gitlabSource.Chunks(ctx, e.ChunksChan())
-		if err != nil {
-			logrus.WithError(err).Error("error scanning GitLab")
-		}
-	}()
-	return nil
+	return e.AddSource(ctx, &gitlabSource)
 }
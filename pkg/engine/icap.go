@@ -0,0 +1,41 @@
+package engine
+
+import (
+	"context"
+	"net"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/icap"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/version"
+)
+
+// ScanICAP serves ICAP on address, so that proxies can have the bodies of the
+// requests passing through them scanned. Requests are blocked by the block
+// policy, and up to maxBodyBytes of each body is scanned. The results of every
+// request are sent to the results channel, whether or not it's blocked.
+func (e *Engine) ScanICAP(ctx context.Context, address, block string, maxBodyBytes int64) error {
+	ctx = e.sourceContext(ctx, sourcespb.SourceType_SOURCE_TYPE_ICAP)
+	server, err := icap.NewServer(e, icap.Options{
+		Block:        block,
+		MaxBodyBytes: maxBodyBytes,
+		Version:      version.BuildVersion,
+		Report:       e.sendResult,
+	})
+	if err != nil {
+		return err
+	}
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not listen for ICAP", 0)
+	}
+	e.addListener(sourceTypeName(sourcespb.SourceType_SOURCE_TYPE_ICAP), server)
+	// Requests are scanned as they arrive, rather than by the workers, since
+	// proxies wait on their verdicts.
+	e.runSource(ctx, sourcespb.SourceType_SOURCE_TYPE_ICAP, nil, func(ctx context.Context, _ chan *sources.Chunk) error {
+		return server.Serve(ctx, lis)
+	})
+	return nil
+}
@@ -0,0 +1,35 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/mobileapp"
+)
+
+// ScanMobileApps scans the APK and IPA artifacts given by path or URL.
+func (e *Engine) ScanMobileApps(ctx context.Context, artifacts []string) error {
+	ctx = e.sourceContext(ctx, sourcespb.SourceType_SOURCE_TYPE_MOBILE_APP)
+	connection := &sourcespb.MobileApp{
+		Artifacts: artifacts,
+		Tls:       e.tlsConfig,
+		Proxy:     e.proxy,
+	}
+
+	var conn anypb.Any
+	err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{})
+	if err != nil {
+		return errors.WrapPrefix(err, "could not marshal mobile app connection", 0)
+	}
+
+	mobileAppSource := mobileapp.Source{}
+	err = mobileAppSource.Init(ctx, "trufflehog - mobile app", 0, int64(sourcespb.SourceType_SOURCE_TYPE_MOBILE_APP), true, &conn, 1)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not init mobile app source", 0)
+	}
+	return e.AddSource(ctx, &mobileAppSource)
+}
@@ -0,0 +1,52 @@
+package engine
+
+import (
+	"context"
+	"io"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/patch"
+)
+
+// ScanPatch scans the lines added by patch files, or by a patch read from
+// standard input for the path "-".
+func (e *Engine) ScanPatch(ctx context.Context, paths []string) error {
+	return e.scanPatch(ctx, paths, nil)
+}
+
+// ScanPatchReader scans the lines added by the patch read from r, which is
+// reported as name.
+func (e *Engine) ScanPatchReader(ctx context.Context, name string, r io.Reader) error {
+	return e.scanPatch(ctx, []string{"-"}, func(s *patch.Source) {
+		s.SetStdin(name, r)
+	})
+}
+
+// scanPatch scans the patches at paths with a patch source set up by setup,
+// if it's set.
+func (e *Engine) scanPatch(ctx context.Context, paths []string, setup func(*patch.Source)) error {
+	ctx = e.sourceContext(ctx, sourcespb.SourceType_SOURCE_TYPE_PATCH)
+	connection := &sourcespb.Patch{
+		Paths: paths,
+	}
+
+	var conn anypb.Any
+	err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{})
+	if err != nil {
+		return errors.WrapPrefix(err, "could not marshal patch connection", 0)
+	}
+
+	patchSource := patch.Source{}
+	err = patchSource.Init(ctx, "trufflehog - patch", 0, int64(sourcespb.SourceType_SOURCE_TYPE_PATCH), true, &conn, 1)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not init patch source", 0)
+	}
+	if setup != nil {
+		setup(&patchSource)
+	}
+	return e.AddSource(ctx, &patchSource)
+}
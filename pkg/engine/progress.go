@@ -0,0 +1,150 @@
+package engine
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// SourceProgress is the progress of a source the engine is scanning.
+type SourceProgress struct {
+	// Name is the source's type, with a number appended if several sources
+	// of the type are scanned, such as "s3" and "s3-2".
+	Name string
+	// Chunks is the number of chunks the source has sent to be scanned.
+	Chunks uint64
+	// PercentComplete and Message are reported by sources that know how much
+	// is left to scan.
+	PercentComplete int64
+	Message         string
+	// BytesDownloaded is how much the source has downloaded, for sources
+	// that download what they scan, and BytesPerSecond its throughput since
+	// the source started, until it finished.
+	BytesDownloaded int64
+	BytesPerSecond  float64
+	// Skipped counts the objects the source didn't scan by the reason they
+	// were skipped, for sources that report it.
+	Skipped map[string]int64
+	// Done is set once the source has sent its last chunk.
+	Done bool
+	// Err is the error the source stopped with, if any.
+	Err error
+}
+
+// sourceState tracks a source while it's scanned.
+type sourceState struct {
+	name       string
+	sourceType sourcespb.SourceType
+	tenant     string
+	chunks     uint64
+	bytes      uint64
+	progress   *sources.Progress
+	started    time.Time
+
+	mu       sync.Mutex
+	done     bool
+	err      error
+	finished time.Time
+	// files and commits are the hashes of the files and commits the
+	// source's chunks are from, which are counted, rather than kept, for
+	// the summary.
+	files   map[uint64]struct{}
+	commits map[uint64]struct{}
+}
+
+// record counts a chunk the source sent, along with the file and commit it's
+// from.
+func (s *sourceState) record(chunk *sources.Chunk) {
+	atomic.AddUint64(&s.chunks, 1)
+	atomic.AddUint64(&s.bytes, uint64(len(chunk.Data)))
+	file := sources.MetadataFile(chunk.SourceMetadata)
+	commit := sources.MetadataField(chunk.SourceMetadata, "commit")
+	if file == "" && commit == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if file != "" {
+		// The same path in different repositories or buckets is a
+		// different file.
+		container := sources.MetadataField(chunk.SourceMetadata, "repository") + sources.MetadataField(chunk.SourceMetadata, "bucket")
+		if s.files == nil {
+			s.files = map[uint64]struct{}{}
+		}
+		s.files[hashStrings(container, file)] = struct{}{}
+	}
+	if commit != "" {
+		if s.commits == nil {
+			s.commits = map[uint64]struct{}{}
+		}
+		s.commits[hashStrings(commit)] = struct{}{}
+	}
+}
+
+// hashStrings returns the FNV-1a hash of strs, separated by NUL bytes.
+func hashStrings(strs ...string) uint64 {
+	h := fnv.New64a()
+	for _, s := range strs {
+		_, _ = h.Write([]byte(s))
+		_, _ = h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+func (s *sourceState) finish(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = true
+	s.err = err
+	s.finished = time.Now()
+}
+
+func (e *Engine) addSourceState(sourceType sourcespb.SourceType, tenant string, progress *sources.Progress) *sourceState {
+	e.sourcesMu.Lock()
+	defer e.sourcesMu.Unlock()
+	count := 1
+	for _, s := range e.sources {
+		if s.sourceType == sourceType {
+			count++
+		}
+	}
+	state := &sourceState{name: sourceTypeName(sourceType), sourceType: sourceType, tenant: tenant, progress: progress, started: time.Now()}
+	if count > 1 {
+		state.name = fmt.Sprintf("%s-%d", state.name, count)
+	}
+	e.sources = append(e.sources, state)
+	return state
+}
+
+// SourceProgress returns the progress of each source added to the engine, in
+// the order they were added.
+func (e *Engine) SourceProgress() []SourceProgress {
+	e.sourcesMu.Lock()
+	defer e.sourcesMu.Unlock()
+	progress := make([]SourceProgress, 0, len(e.sources))
+	for _, s := range e.sources {
+		p := SourceProgress{Name: s.name, Chunks: atomic.LoadUint64(&s.chunks)}
+		if s.progress != nil {
+			p.PercentComplete, p.Message = s.progress.Status()
+			p.BytesDownloaded = s.progress.Downloaded()
+			p.Skipped = s.progress.SkippedCounts()
+		}
+		s.mu.Lock()
+		p.Done, p.Err = s.done, s.err
+		end := s.finished
+		s.mu.Unlock()
+		if end.IsZero() {
+			end = time.Now()
+		}
+		if elapsed := end.Sub(s.started).Seconds(); elapsed > 0 {
+			p.BytesPerSecond = float64(p.BytesDownloaded) / elapsed
+		}
+		progress = append(progress, p)
+	}
+	return progress
+}
@@ -6,7 +6,6 @@ import (
 	"runtime"
 
 	"github.com/go-errors/errors"
-	"github.com/sirupsen/logrus"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/s3"
@@ -14,17 +13,73 @@ import (
 	"google.golang.org/protobuf/types/known/anypb"
 )
 
-func (e *Engine) ScanS3(ctx context.Context, key, secret string, cloudCred bool, buckets []string) error {
+// ScanS3 scans S3 buckets. With cloudCred, the credentials of the environment
+// are used, assuming roleArn if it's set. Objects are downloaded at no more
+// than maxBytesPerSecond if it's positive, with at most maxGetRequests GET
+// requests if it's positive, skipping those of skipStorageClasses. With
+// newestFirst, each bucket's most recently modified objects are downloaded
+// first. Each bucket is listed in listingConcurrency shards at once, if it's
+// above 1.
+func (e *Engine) ScanS3(ctx context.Context, key, secret string, cloudCred bool, roleArn string, buckets []string, maxBytesPerSecond int64, skipStorageClasses []string, maxGetRequests int64, newestFirst bool, listingConcurrency int) error {
+	ctx = e.sourceContext(ctx, sourcespb.SourceType_SOURCE_TYPE_S3)
+	connection, err := s3Connection(key, secret, cloudCred, roleArn, buckets)
+	if err != nil {
+		return err
+	}
+	connection.MaxBytesPerSecond = maxBytesPerSecond
+	connection.SkipStorageClasses = skipStorageClasses
+	connection.MaxGetRequests = maxGetRequests
+	connection.NewestFirst = newestFirst
+	connection.ListingConcurrency = int32(listingConcurrency)
+	var conn anypb.Any
+	err = anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{})
+	if err != nil {
+		e.log.Error(err, "failed to marshal github connection")
+		return err
+	}
+
+	s3Source := s3.Source{}
+	err = s3Source.Init(ctx, "trufflehog - s3", 0, int64(sourcespb.SourceType_SOURCE_TYPE_S3), true, &conn, runtime.NumCPU())
+	if err != nil {
+		return errors.WrapPrefix(err, "failed to init S3 source", 0)
+	}
+
+	return e.AddSource(ctx, &s3Source)
+}
+
+// NewS3Remediator returns a remediator of the objects verified secrets are
+// found in by an S3 scan with the same credentials and buckets. It fails if the
+// IAM policies of the credentials don't allow the remediation.
+func (e *Engine) NewS3Remediator(ctx context.Context, key, secret string, cloudCred bool, roleArn string, buckets []string, opts s3.RemediationOptions) (*s3.Remediator, error) {
+	connection, err := s3Connection(key, secret, cloudCred, roleArn, buckets)
+	if err != nil {
+		return nil, err
+	}
+	return s3.NewRemediator(e.sourceContext(ctx, sourcespb.SourceType_SOURCE_TYPE_S3), connection, opts)
+}
+
+// s3Connection returns the connection of an S3 scan with credentials, as
+// ScanS3 describes.
+func s3Connection(key, secret string, cloudCred bool, roleArn string, buckets []string) (*sourcespb.S3, error) {
 	connection := &sourcespb.S3{
 		Credential: &sourcespb.S3_Unauthenticated{},
 	}
 	if cloudCred {
 		if len(key) > 0 || len(secret) > 0 {
-			return fmt.Errorf("cannot use cloud credentials and basic auth together")
+			return nil, fmt.Errorf("cannot use cloud credentials and basic auth together")
 		}
 		connection.Credential = &sourcespb.S3_CloudEnvironment{}
+		if roleArn != "" {
+			connection.Credential = &sourcespb.S3_Ambient{
+				Ambient: &credentialspb.Ambient{RoleArn: roleArn},
+			}
+		}
+	} else if roleArn != "" {
+		return nil, fmt.Errorf("a role can only be assumed with cloud credentials")
 	}
-	if len(key) > 0 && len(secret) > 0 {
+	// A key without a secret, or a secret without a key, fails the source's
+	// Init rather than scanning unauthenticated.
+	if len(key) > 0 || len(secret) > 0 {
 		connection.Credential = &sourcespb.S3_AccessKey{
 			AccessKey: &credentialspb.KeySecret{
 				Key:    key,
@@ -35,26 +90,5 @@ func (e *Engine) ScanS3(ctx context.Context, key, secret string, cloudCred bool,
 	if len(buckets) > 0 {
 		connection.Buckets = buckets
 	}
-	var conn anypb.Any
-	err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{})
-	if err != nil {
-		logrus.WithError(err).Error("failed to marshal github connection")
-		return err
-	}
-
-	s3Source := s3.Source{}
-	err = s3Source.Init(ctx, "trufflehog - s3", 0, int64(sourcespb.SourceType_SOURCE_TYPE_S3), true, &conn, runtime.NumCPU())
-	if err != nil {
-		return errors.WrapPrefix(err, "failed to init S3 source", 0)
-	}
-
-	e.sourcesWg.Add(1)
-	go func() {
-		defer e.sourcesWg.Done()
-		err := s3Source.Chunks(ctx, e.ChunksChan())
-		if err != nil {
-			logrus.WithError(err).Error("error scanning s3")
-		}
-	}()
-	return nil
+	return connection, nil
 }
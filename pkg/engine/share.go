@@ -0,0 +1,48 @@
+package engine
+
+import (
+	"context"
+	"os"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/share"
+)
+
+// ScanFileShares scans the files of SFTP, FTP(S) and SMB shares, given by URL.
+// Credentials in a URL take precedence over username and password.
+func (e *Engine) ScanFileShares(ctx context.Context, urls []string, username, password, privateKeyPath, domain, knownHosts string, maxFileSize int64, insecureSkipVerify bool, concurrency int) error {
+	ctx = e.sourceContext(ctx, sourcespb.SourceType_SOURCE_TYPE_FILE_SHARE)
+	connection := &sourcespb.FileShare{
+		Urls:               urls,
+		Username:           username,
+		Password:           password,
+		Domain:             domain,
+		MaxFileSize:        maxFileSize,
+		KnownHosts:         knownHosts,
+		InsecureSkipVerify: insecureSkipVerify,
+	}
+	if privateKeyPath != "" {
+		key, err := os.ReadFile(privateKeyPath)
+		if err != nil {
+			return errors.WrapPrefix(err, "could not open private key file", 0)
+		}
+		connection.PrivateKey = string(key)
+	}
+
+	var conn anypb.Any
+	err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{})
+	if err != nil {
+		return errors.WrapPrefix(err, "could not marshal file share connection", 0)
+	}
+
+	shareSource := share.Source{}
+	err = shareSource.Init(ctx, "trufflehog - file share", 0, int64(sourcespb.SourceType_SOURCE_TYPE_FILE_SHARE), true, &conn, concurrency)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not init file share source", 0)
+	}
+	return e.AddSource(ctx, &shareSource)
+}
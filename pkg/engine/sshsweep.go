@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/sshsweep"
+)
+
+// ScanSSHSweep sweeps hosts over SSH, scanning the files under paths as tar
+// streams them. Hosts are given as [user@]host[:port], and may also be read
+// from hostsPath, one per line.
+func (e *Engine) ScanSSHSweep(ctx context.Context, hosts []string, hostsPath, username, password, privateKeyPath, knownHosts string, paths, excludes []string, maxFileSize int64, sudo, insecureSkipVerify bool, concurrency int) error {
+	ctx = e.sourceContext(ctx, sourcespb.SourceType_SOURCE_TYPE_SSH_SWEEP)
+	connection := &sourcespb.SSHSweep{
+		Hosts:              hosts,
+		Username:           username,
+		Password:           password,
+		Paths:              paths,
+		Excludes:           excludes,
+		KnownHosts:         knownHosts,
+		InsecureSkipVerify: insecureSkipVerify,
+		Sudo:               sudo,
+		MaxFileSize:        maxFileSize,
+	}
+	if hostsPath != "" {
+		fileHosts, err := readHosts(hostsPath)
+		if err != nil {
+			return errors.WrapPrefix(err, "could not read hosts file", 0)
+		}
+		connection.Hosts = append(connection.Hosts, fileHosts...)
+	}
+	if privateKeyPath != "" {
+		key, err := os.ReadFile(privateKeyPath)
+		if err != nil {
+			return errors.WrapPrefix(err, "could not open private key file", 0)
+		}
+		connection.PrivateKey = string(key)
+	}
+
+	var conn anypb.Any
+	err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{})
+	if err != nil {
+		return errors.WrapPrefix(err, "could not marshal SSH sweep connection", 0)
+	}
+
+	sweepSource := sshsweep.Source{}
+	err = sweepSource.Init(ctx, "trufflehog - ssh sweep", 0, int64(sourcespb.SourceType_SOURCE_TYPE_SSH_SWEEP), true, &conn, concurrency)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not init SSH sweep source", 0)
+	}
+	return e.AddSource(ctx, &sweepSource)
+}
+
+// readHosts reads the hosts in a file, one per line, skipping blank lines and
+// comments.
+func readHosts(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var hosts []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hosts = append(hosts, line)
+	}
+	return hosts, scanner.Err()
+}
@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// DetectorStats are counts of what a detector did during a scan, for finding
+// noisy or broken detectors.
+type DetectorStats struct {
+	// Chunks is the number of chunks the detector scanned, which are the ones
+	// containing one of its keywords.
+	Chunks uint64
+	// Matches is the number of results it found.
+	Matches uint64
+	// Verified is the number of results it verified.
+	Verified uint64
+	// Errors is the number of chunks it failed to scan, usually because
+	// verification requests failed or timed out.
+	Errors uint64
+	// Verifications is the number of chunks whose results it verified, and
+	// VerificationTime is the total time it spent on them.
+	Verifications    uint64
+	VerificationTime time.Duration
+}
+
+// AverageVerificationTime returns the average time the detector spent
+// verifying the results of a chunk.
+func (s DetectorStats) AverageVerificationTime() time.Duration {
+	if s.Verifications == 0 {
+		return 0
+	}
+	return s.VerificationTime / time.Duration(s.Verifications)
+}
+
+// detectorCounters are the DetectorStats of a detector while it's running.
+type detectorCounters struct {
+	chunks, matches, verified, errors uint64
+	verifications, verificationNanos  uint64
+}
+
+// detectorCounters returns the counters of the detector named name.
+func (e *Engine) detectorCounters(name string) *detectorCounters {
+	counters, ok := e.detectorStats.Load(name)
+	if !ok {
+		counters, _ = e.detectorStats.LoadOrStore(name, &detectorCounters{})
+	}
+	return counters.(*detectorCounters)
+}
+
+// DetectorStats returns the stats of each detector that has scanned a chunk so
+// far, by the name of its package.
+func (e *Engine) DetectorStats() map[string]DetectorStats {
+	stats := map[string]DetectorStats{}
+	e.detectorStats.Range(func(k, v interface{}) bool {
+		c := v.(*detectorCounters)
+		stats[k.(string)] = DetectorStats{
+			Chunks:           atomic.LoadUint64(&c.chunks),
+			Matches:          atomic.LoadUint64(&c.matches),
+			Verified:         atomic.LoadUint64(&c.verified),
+			Errors:           atomic.LoadUint64(&c.errors),
+			Verifications:    atomic.LoadUint64(&c.verifications),
+			VerificationTime: time.Duration(atomic.LoadUint64(&c.verificationNanos)),
+		}
+		return true
+	})
+	return stats
+}
+
+// WriteMetrics writes the detector stats to w in the Prometheus text format.
+func (e *Engine) WriteMetrics(w io.Writer) error {
+	stats := e.DetectorStats()
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	metrics := []struct {
+		name, help, kind string
+		value            func(DetectorStats) string
+	}{
+		{"trufflehog_detector_chunks_total", "Chunks scanned by the detector.", "counter",
+			func(s DetectorStats) string { return fmt.Sprint(s.Chunks) }},
+		{"trufflehog_detector_matches_total", "Results found by the detector.", "counter",
+			func(s DetectorStats) string { return fmt.Sprint(s.Matches) }},
+		{"trufflehog_detector_verified_total", "Results verified by the detector.", "counter",
+			func(s DetectorStats) string { return fmt.Sprint(s.Verified) }},
+		{"trufflehog_detector_errors_total", "Chunks the detector failed to scan.", "counter",
+			func(s DetectorStats) string { return fmt.Sprint(s.Errors) }},
+		{"trufflehog_detector_verifications_total", "Chunks whose results the detector verified.", "counter",
+			func(s DetectorStats) string { return fmt.Sprint(s.Verifications) }},
+		{"trufflehog_detector_verification_seconds_total", "Time the detector spent verifying results.", "counter",
+			func(s DetectorStats) string { return fmt.Sprint(s.VerificationTime.Seconds()) }},
+	}
+	for _, m := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind); err != nil {
+			return err
+		}
+		for _, name := range names {
+			if _, err := fmt.Fprintf(w, "%s{detector=%q} %s\n", m.name, name, m.value(stats[name])); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
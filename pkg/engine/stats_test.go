@@ -0,0 +1,51 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEngine_DetectorStats(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(ctx, WithConcurrency(2), WithDetectors(false, fakeDetector{}))
+	chunks := []string{"fake_abc123 and fake_def456", "nothing here", "fake_ghi789"}
+	if err := e.AddSource(ctx, &fakeSource{chunks: chunks}); err != nil {
+		t.Fatal(err)
+	}
+	go e.Finish()
+	for range e.ResultsChan() {
+	}
+
+	want := DetectorStats{Chunks: 2, Matches: 3}
+	if got := e.DetectorStats()["engine"]; got != want {
+		t.Errorf("DetectorStats() = %+v, want %+v", got, want)
+	}
+
+	var metrics bytes.Buffer
+	if err := e.WriteMetrics(&metrics); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"# TYPE trufflehog_detector_chunks_total counter",
+		`trufflehog_detector_chunks_total{detector="engine"} 2`,
+		`trufflehog_detector_matches_total{detector="engine"} 3`,
+		`trufflehog_detector_verified_total{detector="engine"} 0`,
+	} {
+		if !strings.Contains(metrics.String(), line+"\n") {
+			t.Errorf("metrics are missing %q:\n%s", line, metrics.String())
+		}
+	}
+}
+
+func TestDetectorStats_AverageVerificationTime(t *testing.T) {
+	if got := (DetectorStats{}).AverageVerificationTime(); got != 0 {
+		t.Errorf("AverageVerificationTime() = %v, want 0", got)
+	}
+	s := DetectorStats{Verifications: 4, VerificationTime: 2 * time.Second}
+	if got := s.AverageVerificationTime(); got != 500*time.Millisecond {
+		t.Errorf("AverageVerificationTime() = %v, want 500ms", got)
+	}
+}
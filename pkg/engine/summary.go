@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"encoding/json"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// skippedIgnoredContent is the reason chunks of content that isn't scanned,
+// such as fonts and compressed formats that can't be unpacked, are counted
+// as skipped.
+const skippedIgnoredContent = "ignored_content"
+
+// Summary describes what a scan covered, so that it can be shown to have
+// covered what it was meant to, and what it skipped isn't missed.
+type Summary struct {
+	// Started and Finished are when the engine was started and finished
+	// scanning, and Duration is the time between them in seconds.
+	Started  time.Time
+	Finished time.Time
+	Duration float64
+	// Chunks and Bytes are the chunks scanned and the bytes they held.
+	// Consecutive chunks of a file overlap, so a little more is counted
+	// than the files hold.
+	Chunks uint64
+	Bytes  uint64
+	// Files and Commits are how many files and commits the chunks were
+	// from, for sources that have them.
+	Files   uint64
+	Commits uint64
+	// Skipped counts what wasn't scanned, by the reason it was skipped, such
+	// as "too_large" or "error".
+	Skipped map[string]int64
+	// Sources is the summary of each source, in the order they were added.
+	Sources []SourceSummary
+	// Detectors are the stats of each detector that scanned a chunk, by the
+	// name of its package.
+	Detectors map[string]DetectorStats
+}
+
+// SourceSummary describes what a source covered.
+type SourceSummary struct {
+	Name string
+	// Tenant is the tenant the source was scanned for, if any.
+	Tenant          string `json:",omitempty"`
+	Chunks          uint64
+	Bytes           uint64
+	Files           uint64
+	Commits         uint64
+	BytesDownloaded int64
+	// Skipped counts the objects the source didn't scan by the reason they
+	// were skipped, for sources that report it.
+	Skipped map[string]int64
+	// Duration is how long the source took to scan in seconds.
+	Duration float64
+	// Error is the error the source stopped with, if any.
+	Error string
+	// Failed are the items the source failed to scan without stopping, such
+	// as objects or repos, for sources that report them.
+	Failed []sources.ItemError `json:",omitempty"`
+	// Recovered counts the items that failed and then were scanned when
+	// retried.
+	Recovered int64
+}
+
+// Summary returns the summary of the scan, which is complete once Finish
+// has returned.
+func (e *Engine) Summary() Summary {
+	summary := Summary{
+		Started:   e.started,
+		Finished:  e.finished,
+		Skipped:   map[string]int64{},
+		Detectors: e.DetectorStats(),
+	}
+	if summary.Finished.IsZero() {
+		summary.Finished = time.Now()
+	}
+	summary.Duration = summary.Finished.Sub(summary.Started).Seconds()
+	if skipped := atomic.LoadUint64(&e.chunksSkipped); skipped > 0 {
+		summary.Skipped[skippedIgnoredContent] = int64(skipped)
+	}
+
+	e.sourcesMu.Lock()
+	defer e.sourcesMu.Unlock()
+	for _, s := range e.sources {
+		source := SourceSummary{
+			Name:   s.name,
+			Tenant: s.tenant,
+			Chunks: atomic.LoadUint64(&s.chunks),
+			Bytes:  atomic.LoadUint64(&s.bytes),
+		}
+		if s.progress != nil {
+			source.BytesDownloaded = s.progress.Downloaded()
+			source.Skipped = s.progress.SkippedCounts()
+			source.Failed = s.progress.FailedItems()
+			source.Recovered = s.progress.RecoveredItems()
+		}
+		s.mu.Lock()
+		source.Files, source.Commits = uint64(len(s.files)), uint64(len(s.commits))
+		end := s.finished
+		if s.err != nil {
+			source.Error = s.err.Error()
+		}
+		s.mu.Unlock()
+		if end.IsZero() {
+			end = summary.Finished
+		}
+		source.Duration = end.Sub(s.started).Seconds()
+
+		summary.Chunks += source.Chunks
+		summary.Bytes += source.Bytes
+		summary.Files += source.Files
+		summary.Commits += source.Commits
+		for reason, n := range source.Skipped {
+			summary.Skipped[reason] += n
+		}
+		summary.Sources = append(summary.Sources, source)
+	}
+	return summary
+}
+
+// WriteSummary writes the summary of the scan to w as JSON.
+func (e *Engine) WriteSummary(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(e.Summary())
+}
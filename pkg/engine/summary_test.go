@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestEngine_Summary(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(ctx, WithConcurrency(2), WithDetectors(false, fakeDetector{}))
+
+	gitChunk := func(commit, file, data string) *sources.Chunk {
+		return &sources.Chunk{
+			SourceType:     sourcespb.SourceType_SOURCE_TYPE_GIT,
+			SourceMetadata: &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Git{Git: &source_metadatapb.Git{Commit: commit, File: file}}},
+			Data:           []byte(data),
+		}
+	}
+	e.runSource(ctx, sourcespb.SourceType_SOURCE_TYPE_GIT, nil, func(ctx context.Context, chunksChan chan *sources.Chunk) error {
+		chunksChan <- gitChunk("abc", "main.go", "token fake_abc123")
+		chunksChan <- gitChunk("abc", "main.go", "more")
+		chunksChan <- gitChunk("def", "main.go", "changed")
+		font := gitChunk("def", "font.woff2", "wOF2")
+		font.ContentType = "font/woff2"
+		chunksChan <- font
+		return nil
+	})
+	failing := &fakeSource{chunks: []string{"nothing here"}, err: errors.New("listing failed")}
+	failing.AddItemError("s3://bucket/key", errors.New("GET s3://bucket/key: 503 Slow Down"))
+	if err := e.AddSource(ctx, failing); err != nil {
+		t.Fatal(err)
+	}
+	go e.Finish()
+	for range e.ResultsChan() {
+	}
+
+	summary := e.Summary()
+	if summary.Chunks != 5 || summary.Bytes != 17+4+7+4+12 || summary.Files != 2 || summary.Commits != 2 {
+		t.Errorf("Summary() = %+v, want 5 chunks of 44 bytes from 2 files in 2 commits", summary)
+	}
+	if len(summary.Sources) != 2 || summary.Sources[0].Commits != 2 || summary.Sources[1].Error == "" {
+		t.Errorf("Summary() sources = %+v", summary.Sources)
+	}
+	if failed := summary.Sources[1].Failed; len(failed) != 1 || failed[0].Item != "s3://bucket/key" || !failed[0].Retriable {
+		t.Errorf("Summary() failed items = %+v, want the retriable key", failed)
+	}
+	if summary.Skipped[skippedIgnoredContent] != 1 {
+		t.Errorf("Summary() skipped = %v, want the font", summary.Skipped)
+	}
+	if got := summary.Detectors["engine"]; got.Matches != 1 {
+		t.Errorf("Summary() detector stats = %+v, want 1 match", got)
+	}
+	if summary.Finished.Before(summary.Started) || summary.Duration < 0 {
+		t.Errorf("Summary() ran from %v to %v", summary.Started, summary.Finished)
+	}
+
+	var out bytes.Buffer
+	if err := e.WriteSummary(&out); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Summary
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || decoded.Chunks != summary.Chunks {
+		t.Errorf("WriteSummary() wrote %s, error = %v", out.String(), err)
+	}
+}
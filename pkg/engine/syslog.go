@@ -5,7 +5,6 @@ import (
 	"os"
 
 	"github.com/go-errors/errors"
-	"github.com/sirupsen/logrus"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 
@@ -13,27 +12,39 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/syslog"
 )
 
-func (e *Engine) ScanSyslog(ctx context.Context, address, protocol, certPath, keyPath, format string, concurrency int) error {
+// ScanSyslog listens for syslog messages on address. Messages are only
+// accepted from clients in allowedCIDRs, if there are any, and the hostnames
+// of clients are added to findings if reverseDNS is set. UDP datagrams that
+// continue a message are joined to it if reassembleUDP is set. Messages that
+// arrive faster than they're scanned are spooled to spoolDir, up to
+// spoolMaxBytes, if it's set.
+func (e *Engine) ScanSyslog(ctx context.Context, address, protocol, certPath, keyPath, format string, allowedCIDRs []string, reverseDNS, reassembleUDP bool, spoolDir string, spoolMaxBytes int64, concurrency int) error {
+	ctx = e.sourceContext(ctx, sourcespb.SourceType_SOURCE_TYPE_SYSLOG)
 	connection := &sourcespb.Syslog{
 		Protocol:      protocol,
 		ListenAddress: address,
 		Format:        format,
+		AllowedCidrs:  allowedCIDRs,
+		ReverseDns:    reverseDNS,
+		ReassembleUdp: reassembleUDP,
+		SpoolDir:      spoolDir,
+		SpoolMaxBytes: spoolMaxBytes,
 	}
 
-	if certPath != "" && keyPath != "" {
+	if certPath != "" {
 		cert, err := os.ReadFile(certPath)
 		if err != nil {
 			return errors.WrapPrefix(err, "could not open TLS cert file", 0)
 		}
 		connection.TlsCert = string(cert)
-
+	}
+	if keyPath != "" {
 		key, err := os.ReadFile(keyPath)
 		if err != nil {
 			return errors.WrapPrefix(err, "could not open TLS key file", 0)
 		}
 		connection.TlsKey = string(key)
 	}
-
 	var conn anypb.Any
 	err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{})
 	if err != nil {
@@ -41,19 +52,10 @@ func (e *Engine) ScanSyslog(ctx context.Context, address, protocol, certPath, ke
 	}
 	source := syslog.Source{}
 	err = source.Init(ctx, "trufflehog - syslog", 0, 0, false, &conn, concurrency)
-	source.InjectConnection(connection)
 	if err != nil {
-		logrus.WithError(err).Error("failed to initialize syslog source")
+		e.log.Error(err, "failed to initialize syslog source")
 		return err
 	}
 
-	e.sourcesWg.Add(1)
-	go func() {
-		defer e.sourcesWg.Done()
-		err := source.Chunks(ctx, e.ChunksChan())
-		if err != nil {
-			logrus.WithError(err).Fatal("could not scan syslog")
-		}
-	}()
-	return nil
+	return e.AddSource(ctx, &source)
 }
@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+)
+
+// TenantLimits limit the scans of a tenant's sources, so that an engine
+// scanning the assets of many tenants, such as the customers of a service,
+// shares its capacity between them rather than letting one starve the
+// others.
+type TenantLimits struct {
+	// Concurrency is how many of the tenant's sources are scanned at once.
+	// Others wait for one to finish. Zero doesn't limit them.
+	Concurrency int
+	// Bandwidth is the most bytes a second the tenant's sources download
+	// together, within the engine's own limit. Zero doesn't limit them.
+	Bandwidth int64
+}
+
+// WithTenantLimits limits the scans of the sources of each tenant in limits.
+// Sources are scanned for a tenant with the context sources.WithTenant
+// returns, and those of tenants without limits are only limited by the
+// engine's.
+func WithTenantLimits(limits map[string]TenantLimits) EngineOption {
+	return func(e *Engine) {
+		e.tenants = make(map[string]*tenant, len(limits))
+		for name, l := range limits {
+			t := &tenant{bandwidth: common.NewBandwidthLimiter(l.Bandwidth)}
+			if l.Concurrency > 0 {
+				t.sources = make(chan struct{}, l.Concurrency)
+			}
+			e.tenants[name] = t
+		}
+	}
+}
+
+// tenant holds what the sources of a tenant with limits share.
+type tenant struct {
+	// sources holds a slot for each of the tenant's sources being scanned,
+	// when their concurrency is limited.
+	sources chan struct{}
+	// bandwidth limits the tenant's downloads when it's set.
+	bandwidth *common.BandwidthLimiter
+}
+
+// acquire waits for a slot to scan a source of t in, returning the function
+// that releases it, or an error if ctx is done first.
+func (t *tenant) acquire(ctx context.Context) (func(), error) {
+	if t == nil || t.sources == nil {
+		return func() {}, nil
+	}
+	release := func() { <-t.sources }
+	select {
+	case t.sources <- struct{}{}:
+		return release, nil
+	default:
+	}
+	select {
+	case t.sources <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
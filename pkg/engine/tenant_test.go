@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestEngine_tenants(t *testing.T) {
+	ctx := context.Background()
+	e := NewEngine(ctx, WithConcurrency(2), WithDetectors(false, fakeDetector{}), WithTenantLimits(map[string]TenantLimits{
+		"acme": {Concurrency: 1},
+	}))
+
+	var mu sync.Mutex
+	active, maxActive := 0, 0
+	acmeScan := func(data string) func(context.Context, chan *sources.Chunk) error {
+		return func(ctx context.Context, chunksChan chan *sources.Chunk) error {
+			mu.Lock()
+			if active++; active > maxActive {
+				maxActive = active
+			}
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			chunksChan <- &sources.Chunk{SourceType: sourcespb.SourceType_SOURCE_TYPE_TEST, Data: []byte(data)}
+			mu.Lock()
+			active--
+			mu.Unlock()
+			return nil
+		}
+	}
+	acme := sources.WithTenant(ctx, "acme")
+	e.runSource(acme, sourcespb.SourceType_SOURCE_TYPE_TEST, nil, acmeScan("token fake_acme1"))
+	e.runSource(acme, sourcespb.SourceType_SOURCE_TYPE_TEST, nil, acmeScan("token fake_acme2"))
+	if err := e.AddSource(sources.WithTenant(ctx, "globex"), &fakeSource{chunks: []string{"token fake_globex"}}); err != nil {
+		t.Fatal(err)
+	}
+	go e.Finish()
+
+	var got []string
+	for r := range e.ResultsChan() {
+		got = append(got, r.Tenant+" "+string(r.Raw))
+	}
+	sort.Strings(got)
+	want := []string{"acme fake_acme1", "acme fake_acme2", "globex fake_globex"}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("results diff: (-got +want)\n%s", diff)
+	}
+	if maxActive != 1 {
+		t.Errorf("%d of acme's sources were scanned at once, want 1", maxActive)
+	}
+	for _, s := range e.Summary().Sources {
+		if s.Tenant == "" {
+			t.Errorf("Summary() source %s has no tenant", s.Name)
+		}
+	}
+}
+
+func TestTenant_acquire(t *testing.T) {
+	var unlimited *tenant
+	if _, err := unlimited.acquire(context.Background()); err != nil {
+		t.Errorf("acquire() without limits error = %v", err)
+	}
+
+	limited := &tenant{sources: make(chan struct{}, 1)}
+	release, err := limited.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := limited.acquire(cancelled); err == nil {
+		t.Error("acquire() of a full tenant with a cancelled context succeeded")
+	}
+	release()
+	if _, err := limited.acquire(cancelled); err != nil {
+		t.Errorf("acquire() after release error = %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/vault"
+)
+
+// ScanVault scans the KV version 2 mounts of a Vault server and any file audit
+// device logs given.
+func (e *Engine) ScanVault(ctx context.Context, endpoint, token string, mounts, auditLogs []string) error {
+	ctx = e.sourceContext(ctx, sourcespb.SourceType_SOURCE_TYPE_VAULT)
+	connection := &sourcespb.Vault{
+		Endpoint:  endpoint,
+		Mounts:    mounts,
+		AuditLogs: auditLogs,
+		Tls:       e.tlsConfig,
+		Proxy:     e.proxy,
+	}
+	if token != "" {
+		connection.Credential = &sourcespb.Vault_Token{Token: token}
+	}
+
+	var conn anypb.Any
+	err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{})
+	if err != nil {
+		return errors.WrapPrefix(err, "could not marshal vault connection", 0)
+	}
+
+	vaultSource := vault.Source{}
+	err = vaultSource.Init(ctx, "trufflehog - vault", 0, int64(sourcespb.SourceType_SOURCE_TYPE_VAULT), true, &conn, 1)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not init vault source", 0)
+	}
+	return e.AddSource(ctx, &vaultSource)
+}
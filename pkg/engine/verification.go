@@ -0,0 +1,145 @@
+package engine
+
+import (
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// WithVerificationBudget caps the number of results detectors verify during
+// the scan. Once it's spent, results are reported unverified. A budget of 0
+// is unlimited.
+func WithVerificationBudget(budget int) EngineOption {
+	return func(e *Engine) {
+		if budget > 0 {
+			e.verificationLimiter().budget = budget
+		}
+	}
+}
+
+// WithVerificationSample verifies only the first n occurrences of each unique
+// secret. Later occurrences are reported with the outcome of the last
+// verification instead of being verified again. A sample of 0 verifies every
+// occurrence.
+func WithVerificationSample(n int) EngineOption {
+	return func(e *Engine) {
+		if n > 0 {
+			e.verificationLimiter().sample = n
+		}
+	}
+}
+
+// verifying reports whether any of the engine's detectors verify their
+// results, which --no-verification turns off.
+func (e *Engine) verifying() bool {
+	return len(e.detectors[true]) > 0
+}
+
+func (e *Engine) verificationLimiter() *verificationLimiter {
+	if e.limiter == nil {
+		e.limiter = &verificationLimiter{secrets: map[secretKey]*secretVerification{}}
+	}
+	return e.limiter
+}
+
+// verificationLimiter decides which results are worth verifying under the
+// scan's verification budget and sampling.
+type verificationLimiter struct {
+	mu sync.Mutex
+	// budget is the number of verifications allowed, or 0 for no limit.
+	budget int
+	// spent is the number of verifications made so far.
+	spent int
+	// sample is how many occurrences of each secret are verified, or 0 for all.
+	sample  int
+	secrets map[secretKey]*secretVerification
+}
+
+type secretKey struct {
+	detector detectorspb.DetectorType
+	raw      string
+}
+
+// secretVerification is what's known about verifying a unique secret.
+type secretVerification struct {
+	verifications int
+	verified      bool
+}
+
+func keyOf(result detectors.Result) secretKey {
+	return secretKey{detector: result.DetectorType, raw: string(result.Raw)}
+}
+
+// allow reports whether the unverified results a detector found in a chunk
+// should be verified, and spends the budget for the ones that need it if so.
+// It returns false if every result has already been verified as often as the
+// sample allows, or if what's left of the budget can't cover the ones that
+// haven't. exhausted is true once nothing is left of the budget.
+func (l *verificationLimiter) allow(results []detectors.Result) (ok, exhausted bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	needed := 0
+	pending := map[secretKey]int{}
+	for _, result := range results {
+		key := keyOf(result)
+		if l.needsVerification(key, pending[key]) {
+			needed++
+			pending[key]++
+		}
+	}
+	if needed == 0 {
+		return false, false
+	}
+	if l.budget > 0 && l.spent+needed > l.budget {
+		return false, l.spent >= l.budget
+	}
+	l.spent += needed
+	return true, l.budget > 0 && l.spent >= l.budget
+}
+
+// needsVerification reports whether a secret should be verified again, given
+// how many of its occurrences are already being verified. l.mu must be held.
+func (l *verificationLimiter) needsVerification(key secretKey, pending int) bool {
+	if l.sample == 0 {
+		return true
+	}
+	s := l.secrets[key]
+	return s == nil || s.verifications+pending < l.sample
+}
+
+// record remembers the outcome of verifying the results allow spent the
+// budget for. Detectors verify every result they find in a chunk, so the
+// others are set to what earlier verifications of the same secret found, as
+// if they hadn't been verified again.
+func (l *verificationLimiter) record(results []detectors.Result) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := range results {
+		key := keyOf(results[i])
+		s := l.secrets[key]
+		if !l.needsVerification(key, 0) {
+			results[i].Verified = s.verified
+			continue
+		}
+		if s == nil {
+			s = &secretVerification{}
+			l.secrets[key] = s
+		}
+		s.verifications++
+		s.verified = results[i].Verified
+	}
+}
+
+// apply sets the verification status of results that weren't verified to
+// what earlier verifications of the same secret found.
+func (l *verificationLimiter) apply(results []detectors.Result) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := range results {
+		if s := l.secrets[keyOf(results[i])]; s != nil && s.verifications > 0 {
+			results[i].Verified = s.verified
+		}
+	}
+}
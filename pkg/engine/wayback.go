@@ -0,0 +1,37 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/wayback"
+)
+
+// ScanWayback scans the snapshots the Wayback Machine archived of the domains'
+// scripts and configuration files.
+func (e *Engine) ScanWayback(ctx context.Context, domains []string, limit int64) error {
+	ctx = e.sourceContext(ctx, sourcespb.SourceType_SOURCE_TYPE_WAYBACK)
+	connection := &sourcespb.Wayback{
+		Domains: domains,
+		Limit:   limit,
+		Tls:     e.tlsConfig,
+		Proxy:   e.proxy,
+	}
+
+	var conn anypb.Any
+	err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{})
+	if err != nil {
+		return errors.WrapPrefix(err, "could not marshal wayback connection", 0)
+	}
+
+	waybackSource := wayback.Source{}
+	err = waybackSource.Init(ctx, "trufflehog - wayback", 0, int64(sourcespb.SourceType_SOURCE_TYPE_WAYBACK), true, &conn, 1)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not init wayback source", 0)
+	}
+	return e.AddSource(ctx, &waybackSource)
+}
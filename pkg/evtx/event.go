@@ -0,0 +1,179 @@
+// Package evtx reads Windows event logs, either from .evtx files or from the
+// XML the Windows Event Log API renders events as.
+package evtx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+)
+
+// Event is a single event log record.
+type Event struct {
+	Channel  string
+	EventID  uint32
+	RecordID uint64
+	Provider string
+	Computer string
+	Time     time.Time
+	// Data holds the named values of the event's EventData or UserData, and
+	// its rendered message if there is one, in the order they appear.
+	Data []Field
+}
+
+// Field is a named value of an event.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Text returns the event's fields as "name: value" lines, which is the part
+// of an event that's scanned for secrets.
+func (e *Event) Text() []byte {
+	var buf bytes.Buffer
+	for _, field := range e.Data {
+		if field.Value == "" {
+			continue
+		}
+		buf.WriteString(field.Name)
+		buf.WriteString(": ")
+		buf.WriteString(field.Value)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// node is an XML element of an event.
+type node struct {
+	name     string
+	attrs    []Field
+	children []*node
+	text     strings.Builder
+}
+
+func (n *node) attr(name string) string {
+	for _, attr := range n.attrs {
+		if attr.Name == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+func (n *node) child(name string) *node {
+	for _, child := range n.children {
+		if child.name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+func (n *node) childText(name string) string {
+	if child := n.child(name); child != nil {
+		return strings.TrimSpace(child.text.String())
+	}
+	return ""
+}
+
+// ParseXML parses an event rendered as XML by the Windows Event Log API.
+func ParseXML(data []byte) (*Event, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var stack []*node
+	var root *node
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.WrapPrefix(err, "could not parse event XML", 0)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name.Local}
+			for _, attr := range t.Attr {
+				n.attrs = append(n.attrs, Field{Name: attr.Name.Local, Value: attr.Value})
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, errors.New("event XML has no root element")
+	}
+	return eventFromNode(root), nil
+}
+
+// eventFromNode reads an event from its Event element.
+func eventFromNode(root *node) *Event {
+	event := &Event{}
+	if system := root.child("System"); system != nil {
+		event.Channel = system.childText("Channel")
+		event.Computer = system.childText("Computer")
+		if provider := system.child("Provider"); provider != nil {
+			event.Provider = provider.attr("Name")
+		}
+		if id, err := strconv.ParseUint(system.childText("EventID"), 10, 32); err == nil {
+			event.EventID = uint32(id)
+		}
+		if id, err := strconv.ParseUint(system.childText("EventRecordID"), 10, 64); err == nil {
+			event.RecordID = id
+		}
+		if created := system.child("TimeCreated"); created != nil {
+			if t, err := time.Parse(time.RFC3339Nano, created.attr("SystemTime")); err == nil {
+				event.Time = t
+			}
+		}
+	}
+
+	if eventData := root.child("EventData"); eventData != nil {
+		for _, data := range eventData.children {
+			name := data.attr("Name")
+			if name == "" {
+				name = data.name
+			}
+			event.Data = append(event.Data, Field{Name: name, Value: strings.TrimSpace(data.text.String())})
+		}
+	}
+	if userData := root.child("UserData"); userData != nil {
+		event.Data = append(event.Data, leaves(userData)...)
+	}
+	if info := root.child("RenderingInfo"); info != nil {
+		if message := info.childText("Message"); message != "" {
+			event.Data = append(event.Data, Field{Name: "Message", Value: message})
+		}
+	}
+	return event
+}
+
+// leaves returns the text of the elements below n that have no children.
+func leaves(n *node) []Field {
+	var fields []Field
+	for _, child := range n.children {
+		if len(child.children) == 0 {
+			fields = append(fields, Field{Name: child.name, Value: strings.TrimSpace(child.text.String())})
+			continue
+		}
+		fields = append(fields, leaves(child)...)
+	}
+	return fields
+}
@@ -0,0 +1,382 @@
+package evtx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+// chunkWriter encodes records the way the Windows Event Log service does:
+// names and the template are written inline the first time they're used and
+// referenced by offset after that.
+type chunkWriter struct {
+	buf      []byte
+	names    map[string]int
+	template int
+}
+
+func newChunkWriter() *chunkWriter {
+	w := &chunkWriter{buf: make([]byte, chunkHeaderSize), names: map[string]int{}, template: -1}
+	copy(w.buf, chunkMagic)
+	return w
+}
+
+func (w *chunkWriter) u8(v byte) { w.buf = append(w.buf, v) }
+
+func (w *chunkWriter) u16(v int) {
+	w.buf = append(w.buf, le16(uint16(v))...)
+}
+
+func (w *chunkWriter) u32(v int) {
+	w.buf = append(w.buf, le32(uint32(v))...)
+}
+
+func (w *chunkWriter) u64(v uint64) {
+	w.buf = append(w.buf, le64(v)...)
+}
+
+func le16(v uint16) []byte {
+	b := make([]byte, 2)
+	binary.LittleEndian.PutUint16(b, v)
+	return b
+}
+
+func le32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return b
+}
+
+func le64(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, v)
+	return b
+}
+
+func (w *chunkWriter) text(s string) {
+	for _, c := range utf16.Encode([]rune(s)) {
+		w.u16(int(c))
+	}
+}
+
+// name writes the offset of a name after skip more bytes, followed by the
+// name itself if it hasn't been written before.
+func (w *chunkWriter) name(s string, skip int) {
+	at := len(w.buf)
+	w.u32(0)
+	w.buf = append(w.buf, make([]byte, skip)...)
+	offset, ok := w.names[s]
+	if !ok {
+		offset = len(w.buf)
+		w.names[s] = offset
+		w.u32(0)
+		w.u16(0)
+		w.u16(len(utf16.Encode([]rune(s))))
+		w.text(s)
+		w.u16(0)
+	}
+	binary.LittleEndian.PutUint32(w.buf[at:], uint32(offset))
+}
+
+// element writes an element whose attributes and content are substitutions.
+func (w *chunkWriter) element(name string, attrs map[string]int, content func()) {
+	token := byte(tokenOpenStartElement)
+	skip := 0
+	if len(attrs) > 0 {
+		// The attribute list size comes between the name offset and the name.
+		token |= tokenFlag
+		skip = 4
+	}
+	w.u8(token)
+	w.u16(0xffff)
+	w.u32(0)
+	w.name(name, skip)
+	i := 0
+	for attr, index := range attrs {
+		i++
+		token := byte(tokenAttribute)
+		if i < len(attrs) {
+			token |= tokenFlag
+		}
+		w.u8(token)
+		w.name(attr, 0)
+		w.substitution(index)
+	}
+	if content == nil {
+		w.u8(tokenCloseEmptyElement)
+		return
+	}
+	w.u8(tokenCloseStartElement)
+	content()
+	w.u8(tokenEndElement)
+}
+
+func (w *chunkWriter) substitution(index int) {
+	w.u8(tokenOptionalSubstitution)
+	w.u16(index)
+	w.u8(0)
+}
+
+func (w *chunkWriter) eventTemplate() {
+	w.u8(tokenFragmentHeader)
+	w.u8(1)
+	w.u8(1)
+	w.u8(0)
+	w.element("Event", nil, func() {
+		w.element("System", nil, func() {
+			w.element("Provider", map[string]int{"Name": 0}, nil)
+			w.element("EventID", nil, func() { w.substitution(1) })
+			w.element("TimeCreated", map[string]int{"SystemTime": 2}, nil)
+			w.element("EventRecordID", nil, func() { w.substitution(3) })
+			w.element("Channel", nil, func() { w.substitution(4) })
+			w.element("Computer", nil, func() { w.substitution(5) })
+		})
+		w.element("EventData", nil, func() {
+			w.element("Data", map[string]int{"Name": 6}, func() { w.substitution(7) })
+		})
+	})
+	w.u8(tokenEOF)
+}
+
+type value struct {
+	typ  byte
+	data []byte
+}
+
+func utf16Value(s string) value {
+	var data []byte
+	for _, c := range utf16.Encode([]rune(s)) {
+		data = append(data, le16(c)...)
+	}
+	return value{typ: typeString, data: data}
+}
+
+func (w *chunkWriter) record(id uint64, values []value) {
+	start := len(w.buf)
+	w.buf = append(w.buf, recordMagic...)
+	w.u32(0)
+	w.u64(id)
+	w.u64(0)
+
+	w.u8(tokenFragmentHeader)
+	w.u8(1)
+	w.u8(1)
+	w.u8(0)
+	w.u8(tokenTemplateInstance)
+	w.u8(1)
+	w.u32(1)
+	if w.template >= 0 {
+		w.u32(w.template)
+	} else {
+		w.template = len(w.buf) + 4
+		w.u32(w.template)
+		w.u32(0)
+		w.buf = append(w.buf, make([]byte, 16)...)
+		w.u32(0)
+		body := len(w.buf)
+		w.eventTemplate()
+		binary.LittleEndian.PutUint32(w.buf[w.template+20:], uint32(len(w.buf)-body))
+	}
+
+	w.u32(len(values))
+	for _, v := range values {
+		w.u16(len(v.data))
+		w.u8(v.typ)
+		w.u8(0)
+	}
+	for _, v := range values {
+		w.buf = append(w.buf, v.data...)
+	}
+
+	size := len(w.buf) - start + 4
+	w.u32(size)
+	binary.LittleEndian.PutUint32(w.buf[start+4:], uint32(size))
+	binary.LittleEndian.PutUint32(w.buf[48:], uint32(len(w.buf)))
+}
+
+func eventValues(provider string, eventID int, created time.Time, recordID uint64, data string) []value {
+	ft := uint64(created.UnixNano()/100) + 116444736000000000
+	return []value{
+		utf16Value(provider),
+		{typ: typeUint16, data: le16(uint16(eventID))},
+		{typ: typeFiletime, data: le64(ft)},
+		{typ: typeUint64, data: le64(recordID)},
+		utf16Value("Security"),
+		utf16Value("WIN-HOST"),
+		utf16Value("CommandLine"),
+		utf16Value(data),
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	created := time.Date(2022, 6, 1, 12, 30, 0, 0, time.UTC)
+	w := newChunkWriter()
+	w.record(1, eventValues("Microsoft-Windows-Security-Auditing", 4688, created, 1, `net use \\fs01 /user:admin hunter2`))
+	firstEnd := len(w.buf)
+	w.record(2, eventValues("PowerShell", 4104, created.Add(time.Second), 2, "$key = 'AKIAEXAMPLE'"))
+
+	file := make([]byte, fileHeaderSize)
+	copy(file, fileMagic)
+	file = append(file, w.buf...)
+
+	want := []*Event{
+		{
+			Channel:  "Security",
+			EventID:  4688,
+			RecordID: 1,
+			Provider: "Microsoft-Windows-Security-Auditing",
+			Computer: "WIN-HOST",
+			Time:     created,
+			Data:     []Field{{Name: "CommandLine", Value: `net use \\fs01 /user:admin hunter2`}},
+		},
+		{
+			Channel:  "Security",
+			EventID:  4104,
+			RecordID: 2,
+			Provider: "PowerShell",
+			Computer: "WIN-HOST",
+			Time:     created.Add(time.Second),
+			Data:     []Field{{Name: "CommandLine", Value: "$key = 'AKIAEXAMPLE'"}},
+		},
+	}
+
+	tests := []struct {
+		name string
+		file []byte
+		want []*Event
+	}{
+		{
+			name: "complete",
+			file: file,
+			want: want,
+		},
+		{
+			name: "truncated",
+			file: file[:fileHeaderSize+firstEnd+10],
+			want: want[:1],
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []*Event
+			err := ReadFile(bytes.NewReader(tt.file), func(event *Event) error {
+				got = append(got, event)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if diff := pretty.Compare(got, tt.want); diff != "" {
+				t.Errorf("ReadFile() diff: (-got +want)\n%s", diff)
+			}
+			for i := range got {
+				if i < len(tt.want) && !got[i].Time.Equal(tt.want[i].Time) {
+					t.Errorf("ReadFile() event %d time = %v, want %v", i, got[i].Time, tt.want[i].Time)
+				}
+			}
+		})
+	}
+}
+
+func TestReadFile_NotEVTX(t *testing.T) {
+	err := ReadFile(bytes.NewReader([]byte("just some text")), func(*Event) error { return nil })
+	if !errors.Is(err, ErrNotEVTX) {
+		t.Errorf("ReadFile() error = %v, want %v", err, ErrNotEVTX)
+	}
+}
+
+func TestParseXML(t *testing.T) {
+	data := []byte(`<Event xmlns='http://schemas.microsoft.com/win/2004/08/events/event'>
+  <System>
+    <Provider Name='Microsoft-Windows-PowerShell' Guid='{a0c1853b-5c40-4b15-8766-3cf1c58f985a}'/>
+    <EventID>4104</EventID>
+    <TimeCreated SystemTime='2022-06-01T12:30:00.1234567Z'/>
+    <EventRecordID>9182</EventRecordID>
+    <Channel>Microsoft-Windows-PowerShell/Operational</Channel>
+    <Computer>WIN-HOST</Computer>
+  </System>
+  <EventData>
+    <Data Name='ScriptBlockText'>$token = "ghp_example"</Data>
+    <Data Name='Path'></Data>
+  </EventData>
+  <RenderingInfo Culture='en-US'>
+    <Message>Creating Scriptblock text (1 of 1)</Message>
+  </RenderingInfo>
+</Event>`)
+
+	got, err := ParseXML(data)
+	if err != nil {
+		t.Fatalf("ParseXML() error = %v", err)
+	}
+	want := &Event{
+		Channel:  "Microsoft-Windows-PowerShell/Operational",
+		EventID:  4104,
+		RecordID: 9182,
+		Provider: "Microsoft-Windows-PowerShell",
+		Computer: "WIN-HOST",
+		Time:     time.Date(2022, 6, 1, 12, 30, 0, 123456700, time.UTC),
+		Data: []Field{
+			{Name: "ScriptBlockText", Value: `$token = "ghp_example"`},
+			{Name: "Path"},
+			{Name: "Message", Value: "Creating Scriptblock text (1 of 1)"},
+		},
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("ParseXML() diff: (-got +want)\n%s", diff)
+	}
+	if !got.Time.Equal(want.Time) {
+		t.Errorf("ParseXML() time = %v, want %v", got.Time, want.Time)
+	}
+
+	wantText := "ScriptBlockText: $token = \"ghp_example\"\nMessage: Creating Scriptblock text (1 of 1)\n"
+	if text := string(got.Text()); text != wantText {
+		t.Errorf("Text() = %q, want %q", text, wantText)
+	}
+}
+
+func TestParser_SelfReferencingTemplate(t *testing.T) {
+	// A template at offset 0 whose body is an instance of itself.
+	instance := append(append([]byte{tokenTemplateInstance, 1}, le32(1)...), le32(0)...)
+	instance = append(instance, le32(0)...)
+	chunk := make([]byte, 20)
+	chunk = append(chunk, le32(uint32(len(instance)))...)
+	chunk = append(chunk, instance...)
+	record := len(chunk)
+	chunk = append(chunk, instance...)
+
+	p := &parser{chunk: chunk}
+	if _, err := p.root(record, len(chunk), 0); !errors.Is(err, errTooDeep) {
+		t.Errorf("root() error = %v, want %v", err, errTooDeep)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  byte
+		data []byte
+		want string
+	}{
+		{name: "uint16", typ: typeUint16, data: le16(4688), want: "4688"},
+		{name: "4 byte size", typ: typeSize, data: le32(0x1000), want: "0x1000"},
+		{name: "8 byte size", typ: typeSize, data: le64(0x100000000), want: "0x100000000"},
+		{name: "5 byte size", typ: typeSize, data: []byte{1, 2, 3, 4, 5}, want: "0102030405"},
+		{name: "7 byte size", typ: typeSize, data: []byte{1, 2, 3, 4, 5, 6, 7}, want: "01020304050607"},
+		{name: "short uint64", typ: typeUint64, data: []byte{1, 2, 3}, want: "010203"},
+		{name: "short GUID", typ: typeGUID, data: []byte{1, 2}, want: "0102"},
+		{name: "empty int8", typ: typeInt8, data: nil, want: ""},
+		{name: "uint32 array with a short value", typ: typeArray | typeUint32, data: append(le32(7), 1, 2), want: "7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatValue(tt.typ, tt.data); got != tt.want {
+				t.Errorf("formatValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,632 @@
+package evtx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/go-errors/errors"
+)
+
+const (
+	fileHeaderSize   = 4096
+	chunkSize        = 64 * 1024
+	chunkHeaderSize  = 512
+	recordHeaderSize = 24
+
+	// maxDepth limits how deeply BinXML values and template instances can
+	// embed more BinXML, so templates that reference themselves end.
+	maxDepth = 8
+)
+
+var (
+	fileMagic   = []byte("ElfFile\x00")
+	chunkMagic  = []byte("ElfChnk\x00")
+	recordMagic = []byte{0x2a, 0x2a, 0x00, 0x00}
+)
+
+// ErrNotEVTX is returned by ReadFile for files that aren't event logs.
+var ErrNotEVTX = errors.New("not an evtx file")
+
+// IsEVTX reports whether header, the first bytes of a file, starts an event log.
+func IsEVTX(header []byte) bool {
+	return bytes.HasPrefix(header, fileMagic)
+}
+
+// ReadFile calls fn with each event in an .evtx file. Records that can't be
+// parsed are skipped, so a truncated or partly corrupt log yields the events
+// that can still be read.
+func ReadFile(r io.ReaderAt, fn func(*Event) error) error {
+	header := make([]byte, len(fileMagic))
+	if _, err := r.ReadAt(header, 0); err != nil {
+		if errors.Is(err, io.EOF) {
+			return ErrNotEVTX
+		}
+		return errors.WrapPrefix(err, "could not read evtx header", 0)
+	}
+	if !IsEVTX(header) {
+		return ErrNotEVTX
+	}
+
+	buf := make([]byte, chunkSize)
+	for offset := int64(fileHeaderSize); ; offset += chunkSize {
+		n, err := r.ReadAt(buf, offset)
+		if n >= chunkHeaderSize && bytes.HasPrefix(buf, chunkMagic) {
+			if err := readChunk(buf[:n], fn); err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return errors.WrapPrefix(err, "could not read evtx chunk", 0)
+		}
+	}
+}
+
+// readChunk calls fn with each event in a chunk. Offsets in a chunk's records
+// are relative to the start of the chunk.
+func readChunk(chunk []byte, fn func(*Event) error) error {
+	end := int(binary.LittleEndian.Uint32(chunk[48:]))
+	if end < chunkHeaderSize || end > len(chunk) {
+		end = len(chunk)
+	}
+	p := &parser{chunk: chunk}
+	for offset := chunkHeaderSize; offset+recordHeaderSize <= end; {
+		if !bytes.Equal(chunk[offset:offset+4], recordMagic) {
+			break
+		}
+		size := int(binary.LittleEndian.Uint32(chunk[offset+4:]))
+		if size < recordHeaderSize+4 || offset+size > end {
+			break
+		}
+		nodes, err := p.root(offset+recordHeaderSize, offset+size-4, 0)
+		if err == nil && len(nodes) > 0 {
+			event := eventFromNode(nodes[0])
+			if event.RecordID == 0 {
+				event.RecordID = binary.LittleEndian.Uint64(chunk[offset+8:])
+			}
+			if event.Time.IsZero() {
+				event.Time = filetime(binary.LittleEndian.Uint64(chunk[offset+16:]))
+			}
+			if err := fn(event); err != nil {
+				return err
+			}
+		}
+		offset += size
+	}
+	return nil
+}
+
+// BinXML tokens. The 0x40 bit of a token is a flag, such as "has attributes"
+// for element starts or "more attributes follow" for attributes.
+const (
+	tokenEOF                  = 0x00
+	tokenOpenStartElement     = 0x01
+	tokenCloseStartElement    = 0x02
+	tokenCloseEmptyElement    = 0x03
+	tokenEndElement           = 0x04
+	tokenValue                = 0x05
+	tokenAttribute            = 0x06
+	tokenCDATA                = 0x07
+	tokenCharRef              = 0x08
+	tokenEntityRef            = 0x09
+	tokenTemplateInstance     = 0x0c
+	tokenNormalSubstitution   = 0x0d
+	tokenOptionalSubstitution = 0x0e
+	tokenFragmentHeader       = 0x0f
+
+	tokenFlag = 0x40
+)
+
+// BinXML value types.
+const (
+	typeNull       = 0x00
+	typeString     = 0x01
+	typeAnsiString = 0x02
+	typeInt8       = 0x03
+	typeUint8      = 0x04
+	typeInt16      = 0x05
+	typeUint16     = 0x06
+	typeInt32      = 0x07
+	typeUint32     = 0x08
+	typeInt64      = 0x09
+	typeUint64     = 0x0a
+	typeFloat32    = 0x0b
+	typeFloat64    = 0x0c
+	typeBool       = 0x0d
+	typeBinary     = 0x0e
+	typeGUID       = 0x0f
+	typeSize       = 0x10
+	typeFiletime   = 0x11
+	typeSystemtime = 0x12
+	typeSID        = 0x13
+	typeHex32      = 0x14
+	typeHex64      = 0x15
+	typeBinXML     = 0x21
+	typeArray      = 0x80
+)
+
+// errTooDeep is the error of BinXML nested deeper than maxDepth.
+var errTooDeep = errors.New("evtx values nested too deeply")
+
+// parser renders the BinXML in a chunk into nodes.
+type parser struct {
+	chunk []byte
+}
+
+// substitution is a value substituted into a template.
+type substitution struct {
+	typ    byte
+	offset int
+	size   int
+}
+
+func (p *parser) need(pos, n, end int) error {
+	if pos < 0 || n < 0 || pos+n > end || pos+n > len(p.chunk) {
+		return errors.Errorf("evtx record truncated at offset %d", pos)
+	}
+	return nil
+}
+
+func (p *parser) u16(pos int) int {
+	return int(binary.LittleEndian.Uint16(p.chunk[pos:]))
+}
+
+func (p *parser) u32(pos int) int {
+	return int(binary.LittleEndian.Uint32(p.chunk[pos:]))
+}
+
+// root renders a BinXML document: a fragment header followed by a template
+// instance and the values substituted into it.
+func (p *parser) root(pos, end, depth int) ([]*node, error) {
+	if depth > maxDepth {
+		return nil, errTooDeep
+	}
+	for {
+		if err := p.need(pos, 1, end); err != nil {
+			return nil, err
+		}
+		switch p.chunk[pos] & 0x0f {
+		case tokenFragmentHeader:
+			pos += 4
+		case tokenTemplateInstance:
+			return p.templateInstance(pos, end, depth)
+		default:
+			return nil, errors.Errorf("unexpected evtx token %#x at offset %d", p.chunk[pos], pos)
+		}
+	}
+}
+
+// templateInstance renders a template with the substitution values that
+// follow it.
+func (p *parser) templateInstance(pos, end, depth int) ([]*node, error) {
+	if depth > maxDepth {
+		return nil, errTooDeep
+	}
+	if err := p.need(pos, 10, end); err != nil {
+		return nil, err
+	}
+	templateOffset := p.u32(pos + 6)
+	pos += 10
+
+	// Templates are defined the first time they're used in a chunk and
+	// referenced by offset after that.
+	if err := p.need(templateOffset, 24, len(p.chunk)); err != nil {
+		return nil, err
+	}
+	templateSize := p.u32(templateOffset + 20)
+	templateStart := templateOffset + 24
+	templateEnd := templateStart + templateSize
+	if templateEnd > len(p.chunk) {
+		return nil, errors.Errorf("evtx template at offset %d is truncated", templateOffset)
+	}
+	if templateOffset == pos {
+		pos = templateEnd
+	}
+
+	if err := p.need(pos, 4, end); err != nil {
+		return nil, err
+	}
+	count := p.u32(pos)
+	pos += 4
+	if err := p.need(pos, count*4, end); err != nil {
+		return nil, err
+	}
+	subs := make([]substitution, count)
+	valueOffset := pos + count*4
+	for i := range subs {
+		subs[i] = substitution{
+			size:   p.u16(pos + i*4),
+			typ:    p.chunk[pos+i*4+2],
+			offset: valueOffset,
+		}
+		valueOffset += subs[i].size
+	}
+	if valueOffset > end {
+		return nil, errors.Errorf("evtx substitution values at offset %d are truncated", pos)
+	}
+
+	doc := &node{}
+	if _, err := p.content(templateStart, templateEnd, doc, subs, depth); err != nil {
+		return nil, err
+	}
+	return doc.children, nil
+}
+
+// content renders tokens into parent until the end of its element or of the
+// stream, returning the position after them.
+func (p *parser) content(pos, end int, parent *node, subs []substitution, depth int) (int, error) {
+	if depth > maxDepth {
+		return 0, errTooDeep
+	}
+	for pos < end {
+		switch token := p.chunk[pos] & 0x0f; token {
+		case tokenEOF, tokenEndElement:
+			return pos + 1, nil
+		case tokenFragmentHeader:
+			pos += 4
+		case tokenOpenStartElement:
+			child, next, err := p.element(pos, end, subs, depth)
+			if err != nil {
+				return 0, err
+			}
+			parent.children = append(parent.children, child)
+			pos = next
+		case tokenTemplateInstance:
+			nodes, err := p.templateInstance(pos, end, depth+1)
+			if err != nil {
+				return 0, err
+			}
+			parent.children = append(parent.children, nodes...)
+			// A nested instance runs to the end of what contains it.
+			return end, nil
+		default:
+			next, ok, err := p.value(pos, end, parent, subs, depth)
+			if err != nil {
+				return 0, err
+			}
+			if !ok {
+				return 0, errors.Errorf("unexpected evtx token %#x at offset %d", p.chunk[pos], pos)
+			}
+			pos = next
+		}
+	}
+	return pos, nil
+}
+
+// element renders an element, its attributes, and its content.
+func (p *parser) element(pos, end int, subs []substitution, depth int) (*node, int, error) {
+	if err := p.need(pos, 11, end); err != nil {
+		return nil, 0, err
+	}
+	hasAttributes := p.chunk[pos]&tokenFlag != 0
+	nameOffset := p.u32(pos + 7)
+	pos += 11
+	if hasAttributes {
+		pos += 4
+	}
+	name, pos, err := p.name(nameOffset, pos, end)
+	if err != nil {
+		return nil, 0, err
+	}
+	n := &node{name: name}
+
+	for hasAttributes {
+		if err := p.need(pos, 5, end); err != nil {
+			return nil, 0, err
+		}
+		if p.chunk[pos]&0x0f != tokenAttribute {
+			break
+		}
+		more := p.chunk[pos]&tokenFlag != 0
+		var attrName string
+		attrName, pos, err = p.name(p.u32(pos+1), pos+5, end)
+		if err != nil {
+			return nil, 0, err
+		}
+		attr := &node{}
+		for {
+			next, ok, err := p.value(pos, end, attr, subs, depth)
+			if err != nil {
+				return nil, 0, err
+			}
+			if !ok {
+				break
+			}
+			pos = next
+		}
+		n.attrs = append(n.attrs, Field{Name: attrName, Value: attr.text.String()})
+		hasAttributes = more
+	}
+
+	if err := p.need(pos, 1, end); err != nil {
+		return nil, 0, err
+	}
+	switch p.chunk[pos] {
+	case tokenCloseEmptyElement:
+		return n, pos + 1, nil
+	case tokenCloseStartElement:
+		pos, err = p.content(pos+1, end, n, subs, depth)
+		return n, pos, err
+	default:
+		return nil, 0, errors.Errorf("unexpected evtx token %#x at offset %d", p.chunk[pos], pos)
+	}
+}
+
+// value renders a token holding text into n. It reports false if the token at
+// pos doesn't hold text.
+func (p *parser) value(pos, end int, n *node, subs []substitution, depth int) (int, bool, error) {
+	if err := p.need(pos, 1, end); err != nil {
+		return 0, false, err
+	}
+	switch p.chunk[pos] & 0x0f {
+	case tokenValue:
+		if err := p.need(pos, 4, end); err != nil {
+			return 0, false, err
+		}
+		size := p.u16(pos+2) * 2
+		if err := p.need(pos+4, size, end); err != nil {
+			return 0, false, err
+		}
+		n.text.WriteString(utf16String(p.chunk[pos+4 : pos+4+size]))
+		return pos + 4 + size, true, nil
+	case tokenCDATA:
+		if err := p.need(pos, 3, end); err != nil {
+			return 0, false, err
+		}
+		size := p.u16(pos+1) * 2
+		if err := p.need(pos+3, size, end); err != nil {
+			return 0, false, err
+		}
+		n.text.WriteString(utf16String(p.chunk[pos+3 : pos+3+size]))
+		return pos + 3 + size, true, nil
+	case tokenCharRef:
+		if err := p.need(pos, 3, end); err != nil {
+			return 0, false, err
+		}
+		n.text.WriteRune(rune(p.u16(pos + 1)))
+		return pos + 3, true, nil
+	case tokenEntityRef:
+		if err := p.need(pos, 5, end); err != nil {
+			return 0, false, err
+		}
+		name, next, err := p.name(p.u32(pos+1), pos+5, end)
+		if err != nil {
+			return 0, false, err
+		}
+		n.text.WriteString(entity(name))
+		return next, true, nil
+	case tokenNormalSubstitution, tokenOptionalSubstitution:
+		if err := p.need(pos, 4, end); err != nil {
+			return 0, false, err
+		}
+		index := p.u16(pos + 1)
+		if index < len(subs) {
+			if err := p.substitute(n, subs[index], depth); err != nil {
+				return 0, false, err
+			}
+		}
+		return pos + 4, true, nil
+	default:
+		return pos, false, nil
+	}
+}
+
+// substitute renders a substitution value into n.
+func (p *parser) substitute(n *node, sub substitution, depth int) error {
+	if sub.size == 0 {
+		return nil
+	}
+	if sub.typ == typeBinXML {
+		nodes, err := p.root(sub.offset, sub.offset+sub.size, depth+1)
+		if err != nil {
+			return err
+		}
+		n.children = append(n.children, nodes...)
+		return nil
+	}
+	n.text.WriteString(formatValue(sub.typ, p.chunk[sub.offset:sub.offset+sub.size]))
+	return nil
+}
+
+// name reads the name at offset. Names are stored inline the first time
+// they're used in a chunk, in which case the position after them is returned.
+func (p *parser) name(offset, pos, end int) (string, int, error) {
+	if err := p.need(offset, 8, len(p.chunk)); err != nil {
+		return "", 0, err
+	}
+	size := p.u16(offset+6) * 2
+	if err := p.need(offset+8, size, len(p.chunk)); err != nil {
+		return "", 0, err
+	}
+	name := utf16String(p.chunk[offset+8 : offset+8+size])
+	if offset == pos {
+		pos = offset + 8 + size + 2
+		if pos > end {
+			return "", 0, errors.Errorf("evtx name at offset %d is truncated", offset)
+		}
+	}
+	return name, pos, nil
+}
+
+func entity(name string) string {
+	switch name {
+	case "amp":
+		return "&"
+	case "lt":
+		return "<"
+	case "gt":
+		return ">"
+	case "quot":
+		return `"`
+	case "apos":
+		return "'"
+	default:
+		return "&" + name + ";"
+	}
+}
+
+// formatValue renders a substitution value as text. Values too short for
+// their type are rendered as hex.
+func formatValue(typ byte, data []byte) string {
+	if typ&typeArray != 0 {
+		return formatArray(typ&^typeArray, data)
+	}
+	le := binary.LittleEndian
+	switch typ {
+	case typeNull:
+		return ""
+	case typeString:
+		return utf16String(data)
+	case typeAnsiString:
+		return strings.TrimRight(string(data), "\x00")
+	}
+
+	size := fixedSize(typ)
+	if size > 0 && len(data) < size {
+		return hex.EncodeToString(data)
+	}
+	switch typ {
+	case typeInt8:
+		return strconv.Itoa(int(int8(data[0])))
+	case typeUint8:
+		return strconv.Itoa(int(data[0]))
+	case typeInt16:
+		return strconv.Itoa(int(int16(le.Uint16(data))))
+	case typeUint16:
+		return strconv.Itoa(int(le.Uint16(data)))
+	case typeInt32:
+		return strconv.Itoa(int(int32(le.Uint32(data))))
+	case typeUint32:
+		return strconv.FormatUint(uint64(le.Uint32(data)), 10)
+	case typeInt64:
+		return strconv.FormatInt(int64(le.Uint64(data)), 10)
+	case typeUint64:
+		return strconv.FormatUint(le.Uint64(data), 10)
+	case typeFloat32:
+		return strconv.FormatFloat(float64(math.Float32frombits(le.Uint32(data))), 'g', -1, 32)
+	case typeFloat64:
+		return strconv.FormatFloat(math.Float64frombits(le.Uint64(data)), 'g', -1, 64)
+	case typeBool:
+		return strconv.FormatBool(le.Uint32(data) != 0)
+	case typeGUID:
+		return fmt.Sprintf("{%08X-%04X-%04X-%X-%X}", le.Uint32(data), le.Uint16(data[4:]), le.Uint16(data[6:]), data[8:10], data[10:16])
+	case typeFiletime:
+		return filetime(le.Uint64(data)).Format(time.RFC3339Nano)
+	case typeSystemtime:
+		return time.Date(int(le.Uint16(data)), time.Month(le.Uint16(data[2:])), int(le.Uint16(data[6:])),
+			int(le.Uint16(data[8:])), int(le.Uint16(data[10:])), int(le.Uint16(data[12:])),
+			int(le.Uint16(data[14:]))*int(time.Millisecond), time.UTC).Format(time.RFC3339Nano)
+	case typeSID:
+		return formatSID(data)
+	case typeHex32:
+		return fmt.Sprintf("0x%x", le.Uint32(data))
+	case typeHex64:
+		return fmt.Sprintf("0x%x", le.Uint64(data))
+	case typeSize:
+		// Sizes are 4 or 8 bytes, depending on the platform that logged them.
+		switch {
+		case len(data) >= 8:
+			return fmt.Sprintf("0x%x", le.Uint64(data))
+		case len(data) == 4:
+			return fmt.Sprintf("0x%x", le.Uint32(data))
+		default:
+			return hex.EncodeToString(data)
+		}
+	default:
+		return hex.EncodeToString(data)
+	}
+}
+
+// fixedSize returns the size of values of typ, or 0 if it varies.
+func fixedSize(typ byte) int {
+	switch typ {
+	case typeInt8, typeUint8:
+		return 1
+	case typeInt16, typeUint16:
+		return 2
+	case typeInt32, typeUint32, typeFloat32, typeBool, typeHex32, typeSize:
+		return 4
+	case typeInt64, typeUint64, typeFloat64, typeFiletime, typeHex64:
+		return 8
+	case typeGUID, typeSystemtime:
+		return 16
+	case typeSID:
+		return 8
+	default:
+		return 0
+	}
+}
+
+// formatArray renders an array of values, one per line.
+func formatArray(typ byte, data []byte) string {
+	var values []string
+	switch typ {
+	case typeString:
+		for _, s := range strings.Split(utf16String(data), "\x00") {
+			if s != "" {
+				values = append(values, s)
+			}
+		}
+	case typeAnsiString:
+		for _, s := range strings.Split(string(data), "\x00") {
+			if s != "" {
+				values = append(values, s)
+			}
+		}
+	default:
+		size := fixedSize(typ)
+		if size == 0 {
+			return hex.EncodeToString(data)
+		}
+		for i := 0; i+size <= len(data); i += size {
+			values = append(values, formatValue(typ, data[i:i+size]))
+		}
+	}
+	return strings.Join(values, "\n")
+}
+
+func formatSID(data []byte) string {
+	if len(data) < 8 {
+		return hex.EncodeToString(data)
+	}
+	var authority uint64
+	for _, b := range data[2:8] {
+		authority = authority<<8 | uint64(b)
+	}
+	sid := fmt.Sprintf("S-%d-%d", data[0], authority)
+	for i := 0; i < int(data[1]) && 8+i*4+4 <= len(data); i++ {
+		sid += "-" + strconv.FormatUint(uint64(binary.LittleEndian.Uint32(data[8+i*4:])), 10)
+	}
+	return sid
+}
+
+// utf16String decodes UTF-16LE text, dropping any trailing NULs.
+func utf16String(data []byte) string {
+	chars := make([]uint16, len(data)/2)
+	for i := range chars {
+		chars[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+	for len(chars) > 0 && chars[len(chars)-1] == 0 {
+		chars = chars[:len(chars)-1]
+	}
+	return string(utf16.Decode(chars))
+}
+
+// filetime converts a Windows FILETIME, in 100ns intervals since 1601, to a time.
+func filetime(ft uint64) time.Time {
+	if ft == 0 {
+		return time.Time{}
+	}
+	const unixEpoch = 116444736000000000
+	return time.Unix(0, (int64(ft)-unixEpoch)*100).UTC()
+}
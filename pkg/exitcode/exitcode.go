@@ -0,0 +1,139 @@
+// Package exitcode decides the code a scan exits with from the findings it
+// reported, so CI pipelines can fail on exactly the risk they care about.
+//
+// Rules are given as kind[:severity]=code, such as verified=183 or
+// unverified:high=2. The kind is verified, unverified or any, and the
+// optional severity is the least severe finding the rule matches. The scan
+// exits with the code of the first rule, in the order given, that matched a
+// finding, or 0 if none did.
+package exitcode
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sinks"
+)
+
+// Kinds of findings a rule matches.
+const (
+	KindVerified   = "verified"
+	KindUnverified = "unverified"
+	KindAny        = "any"
+)
+
+// Rule exits with Code if a finding of its kind, at least as severe as
+// Severity, was reported.
+type Rule struct {
+	Kind string
+	// Severity is the least severe finding the rule matches. Empty matches
+	// every finding.
+	Severity string
+	Code     int
+}
+
+// String returns the rule as it's written on the command line.
+func (r Rule) String() string {
+	kind := r.Kind
+	if r.Severity != "" {
+		kind += ":" + r.Severity
+	}
+	return kind + "=" + strconv.Itoa(r.Code)
+}
+
+// ParseRule parses a rule written as kind[:severity]=code.
+func ParseRule(s string) (Rule, error) {
+	kind, code, ok := strings.Cut(s, "=")
+	if !ok {
+		return Rule{}, errors.Errorf("exit code rule %q must be kind=code", s)
+	}
+	var r Rule
+	r.Kind, r.Severity, _ = strings.Cut(strings.TrimSpace(kind), ":")
+	switch r.Kind {
+	case KindVerified, KindUnverified, KindAny:
+	default:
+		return Rule{}, errors.Errorf("exit code rule %q has unknown kind %q, want verified, unverified or any", s, r.Kind)
+	}
+	switch r.Severity {
+	case "", sinks.SeverityCritical, sinks.SeverityHigh, sinks.SeverityMedium, sinks.SeverityLow:
+	default:
+		return Rule{}, errors.Errorf("exit code rule %q has unknown severity %q, want critical, high, medium or low", s, r.Severity)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(code))
+	if err != nil || n < 0 || n > 255 {
+		return Rule{}, errors.Errorf("exit code rule %q must have a code from 0 to 255", s)
+	}
+	r.Code = n
+	return r, nil
+}
+
+// Policy tracks which rules the findings of a scan matched. It's not safe
+// for concurrent use.
+type Policy struct {
+	rules []Rule
+	// minConfidence is how confident, from 0 to 1, that an unverified
+	// finding isn't a false positive a rule needs to be to match it.
+	minConfidence float64
+	matched       []bool
+}
+
+// New returns a policy applying rules in order. Unverified findings only
+// match a rule if they're scored at least minConfidence, from 0 to 1, where
+// confidence is one minus their false positive score. Findings that weren't
+// scored are matched regardless.
+func New(rules []Rule, minConfidence float64) (*Policy, error) {
+	if minConfidence < 0 || minConfidence > 1 {
+		return nil, errors.Errorf("minimum confidence %v must be from 0 to 1", minConfidence)
+	}
+	return &Policy{rules: rules, minConfidence: minConfidence, matched: make([]bool, len(rules))}, nil
+}
+
+// Parse parses rules written as kind[:severity]=code and returns a policy
+// applying them.
+func Parse(rules []string, minConfidence float64) (*Policy, error) {
+	parsed := make([]Rule, 0, len(rules))
+	for _, s := range rules {
+		r, err := ParseRule(s)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, r)
+	}
+	return New(parsed, minConfidence)
+}
+
+// Observe records a reported finding.
+func (p *Policy) Observe(r *detectors.ResultWithMetadata) {
+	if !r.Verified {
+		if score, ok := detectors.FalsePositiveScore(r); ok && 1-score < p.minConfidence {
+			return
+		}
+	}
+	severity := sinks.Severity(r)
+	for i, rule := range p.rules {
+		if p.matched[i] {
+			continue
+		}
+		switch {
+		case rule.Kind == KindVerified && !r.Verified, rule.Kind == KindUnverified && r.Verified:
+			continue
+		case rule.Severity != "" && !sinks.AtLeast(severity, rule.Severity):
+			continue
+		}
+		p.matched[i] = true
+	}
+}
+
+// Match returns the first rule a finding matched, whose code the scan should
+// exit with, or false if none did.
+func (p *Policy) Match() (Rule, bool) {
+	for i, rule := range p.rules {
+		if p.matched[i] {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
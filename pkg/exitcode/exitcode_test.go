@@ -0,0 +1,121 @@
+package exitcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		rule    string
+		want    Rule
+		wantErr string
+	}{
+		{rule: "verified=183", want: Rule{Kind: KindVerified, Code: 183}},
+		{rule: "unverified:high = 2", want: Rule{Kind: KindUnverified, Severity: "high", Code: 2}},
+		{rule: "any=0", want: Rule{Kind: KindAny, Code: 0}},
+		{rule: "verified", wantErr: "must be kind=code"},
+		{rule: "secret=1", wantErr: `unknown kind "secret"`},
+		{rule: "verified:urgent=1", wantErr: `unknown severity "urgent"`},
+		{rule: "verified=256", wantErr: "code from 0 to 255"},
+		{rule: "verified=yes", wantErr: "code from 0 to 255"},
+	}
+	for _, tt := range tests {
+		got, err := ParseRule(tt.rule)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseRule(%q) error = %v, want %q", tt.rule, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseRule(%q) = %v, %v, want %v", tt.rule, got, err, tt.want)
+		}
+	}
+}
+
+func result(verified bool, extra map[string]string) *detectors.ResultWithMetadata {
+	return &detectors.ResultWithMetadata{Result: detectors.Result{Verified: verified, ExtraData: extra}}
+}
+
+func TestPolicy(t *testing.T) {
+	rules := []string{"verified=183", "unverified=2"}
+	tests := []struct {
+		name          string
+		rules         []string
+		minConfidence float64
+		results       []*detectors.ResultWithMetadata
+		want          int
+	}{
+		{
+			name:  "no findings",
+			rules: rules,
+		},
+		{
+			name:    "verified outranks unverified",
+			rules:   rules,
+			results: []*detectors.ResultWithMetadata{result(false, nil), result(true, nil)},
+			want:    183,
+		},
+		{
+			name:    "unverified",
+			rules:   rules,
+			results: []*detectors.ResultWithMetadata{result(false, nil)},
+			want:    2,
+		},
+		{
+			name:          "unverified below confidence",
+			rules:         rules,
+			minConfidence: 0.7,
+			results:       []*detectors.ResultWithMetadata{result(false, map[string]string{detectors.FalsePositiveScoreKey: "0.5"})},
+		},
+		{
+			name:          "unverified above confidence",
+			rules:         rules,
+			minConfidence: 0.7,
+			results:       []*detectors.ResultWithMetadata{result(false, map[string]string{detectors.FalsePositiveScoreKey: "0.2"})},
+			want:          2,
+		},
+		{
+			name:          "verified ignores confidence",
+			rules:         rules,
+			minConfidence: 0.7,
+			results:       []*detectors.ResultWithMetadata{result(true, map[string]string{detectors.FalsePositiveScoreKey: "0.9"})},
+			want:          183,
+		},
+		{
+			name:    "severity",
+			rules:   []string{"any:critical=3", "any:high=4"},
+			results: []*detectors.ResultWithMetadata{result(false, map[string]string{"severity": "high"}), result(false, nil)},
+			want:    4,
+		},
+		{
+			name:    "below severity",
+			rules:   []string{"unverified:high=2"},
+			results: []*detectors.ResultWithMetadata{result(false, nil)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parse(tt.rules, tt.minConfidence)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range tt.results {
+				p.Observe(r)
+			}
+			rule, ok := p.Match()
+			if ok != (tt.want != 0) || rule.Code != tt.want {
+				t.Errorf("Match() = %v, %v, want code %d", rule, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_confidence(t *testing.T) {
+	if _, err := New(nil, 1.5); err == nil {
+		t.Error("New() accepted a confidence above 1")
+	}
+}
@@ -0,0 +1,74 @@
+// Package findings converts results into the versioned findings schema used by
+// integrations.
+package findings
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/findingspb"
+)
+
+// SchemaVersion is the version of findingspb.Finding produced by FromResult.
+const SchemaVersion = 1
+
+// ID returns a deterministic identifier for r derived from its detector, raw
+// secret, and source location. Scanning the same secret in the same place
+// always produces the same ID, which lets other systems deduplicate findings.
+func ID(r *detectors.ResultWithMetadata) string {
+	h := sha256.New()
+	writeField(h, []byte(r.DetectorType.String()))
+	writeField(h, r.Raw)
+	writeField(h, []byte(r.SourceType.String()))
+	// Deterministic marshaling keeps map fields in a stable order. If the
+	// metadata can't be marshaled the ID still covers the detector and secret.
+	location, _ := proto.MarshalOptions{Deterministic: true}.Marshal(r.SourceMetadata)
+	writeField(h, location)
+	// Secrets in different files of the same archive are in different places.
+	// Results that weren't unpacked keep the IDs they had before provenance
+	// was tracked.
+	for _, c := range r.Provenance {
+		writeField(h, []byte(c.Type))
+		writeField(h, []byte(c.Name))
+	}
+	// The same secret in the assets of two tenants is a finding of each, so
+	// that one tenant's findings never stand for another's.
+	if r.Tenant != "" {
+		writeField(h, []byte("tenant"))
+		writeField(h, []byte(r.Tenant))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeField writes a length-prefixed field so adjacent fields can't be
+// shifted into each other to produce the same hash.
+func writeField(h interface{ Write([]byte) (int, error) }, field []byte) {
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(field)))
+	_, _ = h.Write(length[:])
+	_, _ = h.Write(field)
+}
+
+// FromResult returns the finding for r.
+func FromResult(r *detectors.ResultWithMetadata) *findingspb.Finding {
+	return &findingspb.Finding{
+		SchemaVersion:  SchemaVersion,
+		Id:             ID(r),
+		DetectorType:   r.DetectorType,
+		DetectorName:   r.DetectorType.String(),
+		Verified:       r.Verified,
+		Raw:            r.Raw,
+		Redacted:       r.Redacted,
+		ExtraData:      r.ExtraData,
+		StructuredData: r.StructuredData,
+		SourceType:     r.SourceType,
+		SourceName:     r.SourceName,
+		SourceId:       r.SourceID,
+		SourceMetadata: r.SourceMetadata,
+		Tenant:         r.Tenant,
+	}
+}
@@ -0,0 +1,96 @@
+package findings
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/findingspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func gitResult(file string, raw string) *detectors.ResultWithMetadata {
+	return &detectors.ResultWithMetadata{
+		SourceType: sourcespb.SourceType_SOURCE_TYPE_GIT,
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Git{
+				Git: &source_metadatapb.Git{Commit: "abc123", File: file, Line: 4},
+			},
+		},
+		Result: detectors.Result{
+			DetectorType: detectorspb.DetectorType_AWS,
+			Raw:          []byte(raw),
+		},
+	}
+}
+
+func TestID(t *testing.T) {
+	base := gitResult("main.go", "AKIAEXAMPLE")
+
+	same := gitResult("main.go", "AKIAEXAMPLE")
+	same.Verified = true
+	same.ExtraData = map[string]string{"account": "1234"}
+	if ID(base) != ID(same) {
+		t.Error("verification status and extra data should not change the ID")
+	}
+
+	if ID(base) == ID(gitResult("other.go", "AKIAEXAMPLE")) {
+		t.Error("findings in different files should have different IDs")
+	}
+	if ID(base) == ID(gitResult("main.go", "AKIAOTHER")) {
+		t.Error("different secrets should have different IDs")
+	}
+
+	other := gitResult("main.go", "AKIAEXAMPLE")
+	other.DetectorType = detectorspb.DetectorType_Generic
+	if ID(base) == ID(other) {
+		t.Error("different detectors should have different IDs")
+	}
+
+	unpacked := gitResult("main.go", "AKIAEXAMPLE")
+	unpacked.Provenance = []sources.ContainerRef{{Type: "zip", Name: "a.env"}}
+	otherFile := gitResult("main.go", "AKIAEXAMPLE")
+	otherFile.Provenance = []sources.ContainerRef{{Type: "zip", Name: "b.env"}}
+	if ID(base) == ID(unpacked) || ID(unpacked) == ID(otherFile) {
+		t.Error("findings in different files of an archive should have different IDs")
+	}
+
+	acme := gitResult("main.go", "AKIAEXAMPLE")
+	acme.Tenant = "acme"
+	globex := gitResult("main.go", "AKIAEXAMPLE")
+	globex.Tenant = "globex"
+	if ID(base) == ID(acme) || ID(acme) == ID(globex) {
+		t.Error("findings of different tenants should have different IDs")
+	}
+}
+
+func TestFromResultRoundTrip(t *testing.T) {
+	r := gitResult("main.go", "AKIAEXAMPLE")
+	out, err := proto.Marshal(FromResult(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var finding findingspb.Finding
+	if err := proto.Unmarshal(out, &finding); err != nil {
+		t.Fatal(err)
+	}
+	if finding.SchemaVersion != SchemaVersion || finding.Id != ID(r) || finding.DetectorName != "AWS" {
+		t.Errorf("unexpected finding: %v", &finding)
+	}
+	if finding.SourceMetadata.GetGit().GetFile() != "main.go" {
+		t.Errorf("source metadata was not preserved: %v", finding.SourceMetadata)
+	}
+
+	r.Tenant = "acme"
+	out, err = proto.Marshal(FromResult(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := proto.Unmarshal(out, &finding); err != nil || finding.Tenant != "acme" {
+		t.Errorf("tenant was not preserved: %v, error = %v", &finding, err)
+	}
+}
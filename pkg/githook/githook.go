@@ -0,0 +1,228 @@
+// Package githook runs scans from git hooks: pre-commit and pre-push hooks
+// installed in developers' clones, and pre-receive hooks on git servers. Each
+// reads the changes its hook checks from git as a patch, so only the lines
+// being committed or pushed are scanned.
+package githook
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+// Hooks a scan can run from.
+const (
+	PreCommit  = "pre-commit"
+	PrePush    = "pre-push"
+	PreReceive = "pre-receive"
+)
+
+// marker is in the hooks Install writes, so they can be told apart from
+// hooks it mustn't overwrite.
+const marker = "# Installed by trufflehog hook install."
+
+// Install writes a hook to the hooks directory of the repo at dir, running
+// the trufflehog on the PATH with flags. An existing hook is only replaced if
+// Install wrote it, or force is set. It returns the path of the hook.
+func Install(ctx context.Context, dir, hook string, flags []string, force bool) (string, error) {
+	if hook != PreCommit && hook != PrePush {
+		return "", errors.Errorf("can't install a %s hook, only %s and %s hooks", hook, PreCommit, PrePush)
+	}
+	out, err := gitOutput(ctx, dir, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", errors.WrapPrefix(err, "could not find the repo's hooks", 0)
+	}
+	hooksDir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(hooksDir) {
+		hooksDir = filepath.Join(dir, hooksDir)
+	}
+	if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+		return "", errors.WrapPrefix(err, "could not create the hooks directory", 0)
+	}
+
+	path := filepath.Join(hooksDir, hook)
+	existing, err := os.ReadFile(path)
+	switch {
+	case err == nil && !force && !bytes.Contains(existing, []byte(marker)):
+		return "", errors.Errorf("%s already exists, set force to replace it", path)
+	case err != nil && !os.IsNotExist(err):
+		return "", errors.WrapPrefix(err, "could not read the existing hook", 0)
+	}
+	if err := os.WriteFile(path, []byte(script(hook, flags)), 0o755); err != nil {
+		return "", errors.WrapPrefix(err, "could not write the hook", 0)
+	}
+	return path, nil
+}
+
+// script returns a hook running a scan of the hook's changes with flags.
+func script(hook string, flags []string) string {
+	args := []string{"trufflehog", "--no-update"}
+	for _, flag := range flags {
+		args = append(args, shellQuote(flag))
+	}
+	args = append(args, "hook", hook, `"$@"`)
+	return fmt.Sprintf("#!/bin/sh\n%s\n# It fails if secrets are found in the changes being checked.\nexec %s\n", marker, strings.Join(args, " "))
+}
+
+// shellQuote quotes s for sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Changes starts git printing the changes a hook checks as a patch, in the
+// repo at dir. Pre-commit hooks check the staged changes. Pre-push and
+// pre-receive hooks check the commits pushed that the remote, or the server,
+// doesn't already have, read from what git passes them on stdin. Pre-push
+// hooks are also given the remote in args.
+//
+// The returned function waits for git once the patch has been read, and
+// returns the error it failed with, so that a hook can fail rather than let
+// unscanned changes through.
+func Changes(ctx context.Context, dir, hook string, args []string, stdin io.Reader) (io.Reader, func() error, error) {
+	var gitArgs []string
+	switch hook {
+	case PreCommit:
+		gitArgs = []string{"diff", "--cached", "-U0", "--no-color", "--no-ext-diff"}
+	case PrePush, PreReceive:
+		var revisions []string
+		var err error
+		if hook == PrePush {
+			if len(args) == 0 {
+				return nil, nil, errors.New("pre-push hooks must be given the remote")
+			}
+			revisions, err = pushRevisions(ctx, dir, args[0], stdin)
+		} else {
+			revisions, err = receiveRevisions(stdin)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(revisions) == 0 {
+			// Only refs are deleted.
+			return strings.NewReader(""), func() error { return nil }, nil
+		}
+		// Merges have no diff without -m, and the fuller format has the
+		// commits' authors.
+		gitArgs = append([]string{"log", "-p", "-U0", "--no-merges", "--pretty=fuller", "--no-color", "--no-ext-diff"}, revisions...)
+	default:
+		return nil, nil, errors.Errorf("unknown hook %s", hook)
+	}
+
+	// git is run with the hook's environment, which has the quarantine
+	// directory pushed objects are in before pre-receive hooks accept them.
+	cmd := exec.CommandContext(ctx, "git", gitArgs...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, errors.WrapPrefix(err, "could not run git", 0)
+	}
+	wait := func() error {
+		// The patch parser stops reading at the first error in the patch.
+		_, _ = io.Copy(ioutil.Discard, stdout)
+		if err := cmd.Wait(); err != nil {
+			return fmt.Errorf("'git %s' failed: %w: %s", gitArgs[0], err, strings.TrimSpace(stderr.String()))
+		}
+		return nil
+	}
+	return stdout, wait, nil
+}
+
+// receiveRevisions returns the revisions git log reads the commits pushed to
+// a server from, given the old-rev new-rev refname lines pre-receive hooks
+// are passed. Commits any ref already has aren't read, since they were
+// checked when they were pushed, or were there before the hook.
+func receiveRevisions(stdin io.Reader) ([]string, error) {
+	var revisions []string
+	scanner := bufio.NewScanner(stdin)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, errors.Errorf("pre-receive line %q isn't old-rev new-rev refname", line)
+		}
+		if newRev := fields[1]; !isZeroOID(newRev) {
+			revisions = append(revisions, newRev)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, nil
+	}
+	return append(revisions, "--not", "--all"), nil
+}
+
+// pushRevisions returns the revisions git log reads the commits pushed to
+// remote from, given the local-ref local-sha remote-ref remote-sha lines
+// pre-push hooks are passed. Commits the remote's branches had when they were
+// last fetched, or the refs being pushed to have, aren't read.
+func pushRevisions(ctx context.Context, dir, remote string, stdin io.Reader) ([]string, error) {
+	var revisions, exclude []string
+	scanner := bufio.NewScanner(stdin)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 4 {
+			return nil, errors.Errorf("pre-push line %q isn't local-ref local-sha remote-ref remote-sha", line)
+		}
+		localSHA, remoteSHA := fields[1], fields[3]
+		if isZeroOID(localSHA) {
+			continue
+		}
+		revisions = append(revisions, localSHA)
+		// The remote's ref can be at a commit that wasn't fetched.
+		if !isZeroOID(remoteSHA) {
+			if _, err := gitOutput(ctx, dir, "cat-file", "-e", remoteSHA+"^{commit}"); err == nil {
+				exclude = append(exclude, remoteSHA)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, nil
+	}
+	revisions = append(revisions, "--not", "--remotes="+remote)
+	return append(revisions, exclude...), nil
+}
+
+// gitOutput runs git with args in the repo at dir and returns its output.
+func gitOutput(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("'git %s' failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// isZeroOID reports whether oid is the object name of zeros git gives refs
+// that are created or deleted, which is longer in SHA-256 repos.
+func isZeroOID(oid string) bool {
+	return strings.Trim(oid, "0") == ""
+}
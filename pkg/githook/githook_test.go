@@ -0,0 +1,191 @@
+package githook
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+// testRepo returns a repo with a commit adding a file.
+func testRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	git(t, dir, "init", "-q")
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git(t, dir, "add", "README")
+	git(t, dir, "commit", "-q", "-m", "init")
+	return dir
+}
+
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestInstall(t *testing.T) {
+	ctx := context.Background()
+	dir := testRepo(t)
+
+	path, err := Install(ctx, dir, PrePush, []string{"--no-verification", "--exit-code=any=1", "it's"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, ".git", "hooks", "pre-push"); path != want {
+		t.Errorf("Install() path = %q, want %q", path, want)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `exec trufflehog --no-update '--no-verification' '--exit-code=any=1' 'it'\''s' hook pre-push "$@"`; !strings.Contains(string(got), want) {
+		t.Errorf("hook = %q, want it to run %q", got, want)
+	}
+
+	// Hooks it installed are replaced, and others only if forced.
+	if _, err := Install(ctx, dir, PrePush, nil, false); err != nil {
+		t.Errorf("Install() over its own hook error = %v", err)
+	}
+	other := filepath.Join(dir, ".git", "hooks", "pre-commit")
+	if err := os.WriteFile(other, []byte("#!/bin/sh\nmake lint\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Install(ctx, dir, PreCommit, nil, false); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Install() over another hook error = %v, want it to exist already", err)
+	}
+	if _, err := Install(ctx, dir, PreCommit, nil, true); err != nil {
+		t.Errorf("Install() forced error = %v", err)
+	}
+
+	if _, err := Install(ctx, dir, PreReceive, nil, false); err == nil {
+		t.Error("Install() installed a pre-receive hook")
+	}
+}
+
+func TestChanges_preCommit(t *testing.T) {
+	dir := testRepo(t)
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("hello\ntoken\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "unstaged"), []byte("unstaged\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git(t, dir, "add", "README")
+
+	patch := readChanges(t, dir, PreCommit, nil, "")
+	if !strings.Contains(patch, "+token") || strings.Contains(patch, "unstaged") {
+		t.Errorf("pre-commit changes = %q, want the staged changes alone", patch)
+	}
+}
+
+func TestChanges_preReceive(t *testing.T) {
+	dir := testRepo(t)
+	old := git(t, dir, "rev-parse", "HEAD")
+	if err := os.WriteFile(filepath.Join(dir, "config"), []byte("token\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git(t, dir, "add", "config")
+	git(t, dir, "commit", "-q", "-m", "add config")
+	pushed := git(t, dir, "rev-parse", "HEAD")
+	// Refs are updated after pre-receive hooks accept them.
+	git(t, dir, "update-ref", "refs/heads/master", old)
+	git(t, dir, "update-ref", "refs/heads/main", old)
+	zero := strings.Repeat("0", 40)
+
+	tests := []struct {
+		name  string
+		stdin string
+		want  []string
+	}{
+		{
+			name:  "update",
+			stdin: old + " " + pushed + " refs/heads/main\n",
+			want:  []string{"commit " + pushed, "+token"},
+		},
+		{
+			name:  "new branch",
+			stdin: zero + " " + pushed + " refs/heads/feature\n",
+			want:  []string{"commit " + pushed, "+token"},
+		},
+		{
+			name:  "new branch of existing commits",
+			stdin: zero + " " + old + " refs/heads/feature\n",
+		},
+		{
+			name:  "delete",
+			stdin: old + " " + zero + " refs/heads/feature\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch := readChanges(t, dir, PreReceive, nil, tt.stdin)
+			for _, want := range tt.want {
+				if !strings.Contains(patch, want) {
+					t.Errorf("pre-receive changes = %q, want them to have %q", patch, want)
+				}
+			}
+			if len(tt.want) == 0 && patch != "" {
+				t.Errorf("pre-receive changes = %q, want none", patch)
+			}
+			if strings.Contains(patch, "+hello") {
+				t.Errorf("pre-receive changes = %q, want them not to have the commits already received", patch)
+			}
+		})
+	}
+
+	if _, _, err := Changes(context.Background(), dir, PreReceive, nil, strings.NewReader("main\n")); err == nil {
+		t.Error("Changes() read malformed pre-receive lines")
+	}
+}
+
+func TestPushRevisions(t *testing.T) {
+	ctx := context.Background()
+	dir := testRepo(t)
+	head := git(t, dir, "rev-parse", "HEAD")
+	unknown := strings.Repeat("a", 40)
+	stdin := strings.Join([]string{
+		"refs/heads/main " + head + " refs/heads/main " + head,
+		"refs/heads/new " + head + " refs/heads/new " + strings.Repeat("0", 40),
+		"refs/heads/fetched " + head + " refs/heads/fetched " + unknown,
+		"(delete) " + strings.Repeat("0", 40) + " refs/heads/old " + head,
+	}, "\n")
+
+	got, err := pushRevisions(ctx, dir, "origin", strings.NewReader(stdin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{head, head, head, "--not", "--remotes=origin", head}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("pushRevisions() diff: (-got +want)\n%s", diff)
+	}
+}
+
+// readChanges returns the changes a hook checks in the repo at dir.
+func readChanges(t *testing.T, dir, hook string, args []string, stdin string) string {
+	t.Helper()
+	r, wait, err := Changes(context.Background(), dir, hook, args, strings.NewReader(stdin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wait(); err != nil {
+		t.Fatal(err)
+	}
+	return string(patch)
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// maxAuditdLineSize is the longest auditd record that will be parsed.
+const maxAuditdLineSize = 1024 * 1024 // 1MB
+
+// Auditd parses Linux audit logs. auditd hex encodes strings it doesn't
+// trust, such as command line arguments containing spaces or quotes, which
+// hides credentials passed on the command line. The handler decodes them and
+// sends a chunk for each audit event.
+type Auditd struct{}
+
+// Ensure the Auditd handler satisfies the interface at compile time.
+var _ Handler = (*Auditd)(nil)
+
+var (
+	auditdHeader = regexp.MustCompile(`^(node=\S+ )?type=\S+ msg=audit\(`)
+	auditdStamp  = regexp.MustCompile(`msg=audit\((\d+)(?:\.(\d+))?:(\d+)\):`)
+	execveArg    = regexp.MustCompile(`^a(\d+)(?:\[(\d+)\])?$`)
+)
+
+// auditdEncodedFields are the fields, besides execve arguments, that auditd
+// may hex encode.
+var auditdEncodedFields = map[string]bool{
+	"proctitle": true,
+	"cmd":       true,
+	"comm":      true,
+	"exe":       true,
+	"cwd":       true,
+	"name":      true,
+	"path":      true,
+	"data":      true,
+}
+
+func (h *Auditd) Accepts(path string, header []byte) bool {
+	return auditdHeader.Match(header)
+}
+
+func (h *Auditd) Handle(ctx context.Context, path string, file io.ReaderAt, chunkSkel *sources.Chunk, chunksChan chan *sources.Chunk) error {
+	scanner := bufio.NewScanner(io.NewSectionReader(file, 0, math.MaxInt64))
+	scanner.Buffer(make([]byte, 64*1024), maxAuditdLineSize)
+
+	var event *auditdEvent
+	flush := func() error {
+		if event == nil || event.text.Len() == 0 {
+			return nil
+		}
+		chunk := *chunkSkel
+		chunk.Data = event.text.Bytes()
+		chunk.SourceMetadata = &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Auditd{
+				Auditd: &source_metadatapb.Auditd{
+					File:      sanitizer.UTF8(path),
+					Serial:    event.serial,
+					Timestamp: event.timestamp,
+				},
+			},
+		}
+		select {
+		case chunksChan <- &chunk:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		match := auditdStamp.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		serial, _ := strconv.ParseUint(match[3], 10, 64)
+
+		// The records of an event are written together, ending with EOE.
+		if event == nil || event.serial != serial {
+			if err := flush(); err != nil {
+				return err
+			}
+			event = &auditdEvent{serial: serial}
+			if seconds, err := strconv.ParseInt(match[1], 10, 64); err == nil {
+				event.timestamp = time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+			}
+		}
+		event.add(line)
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.WrapPrefix(err, "could not read audit log", 0)
+	}
+	return flush()
+}
+
+// auditdEvent collects the decoded records of an audit event.
+type auditdEvent struct {
+	serial    uint64
+	timestamp string
+	text      bytes.Buffer
+	hasExecve bool
+}
+
+// add decodes a record and adds the fields that may hold credentials to the
+// event's text.
+func (e *auditdEvent) add(line string) {
+	fields := parseAuditdFields(line)
+	recordType := ""
+	for _, field := range fields {
+		if field.key == "type" {
+			recordType = field.value
+			break
+		}
+	}
+
+	if recordType == "EXECVE" {
+		e.hasExecve = true
+		if args := execveArgs(fields); args != "" {
+			e.text.WriteString("EXECVE command=" + args + "\n")
+		}
+		return
+	}
+	// PROCTITLE repeats the arguments of EXECVE, truncated.
+	if recordType == "PROCTITLE" && e.hasExecve {
+		return
+	}
+
+	var decoded []string
+	for _, field := range fields {
+		if auditdEncodedFields[field.key] {
+			decoded = append(decoded, field.key+"="+decodeAuditdValue(field.value, field.quoted))
+		}
+	}
+	if len(decoded) > 0 {
+		e.text.WriteString(recordType + " " + strings.Join(decoded, " ") + "\n")
+	}
+}
+
+type auditdField struct {
+	key    string
+	value  string
+	quoted bool
+}
+
+// parseAuditdFields splits a record into its key=value fields. Userspace
+// records nest their fields in a single quoted msg field, which is expanded.
+func parseAuditdFields(line string) []auditdField {
+	var fields []auditdField
+	for len(line) > 0 {
+		line = strings.TrimLeft(line, " ")
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			break
+		}
+		key := line[:eq]
+		line = line[eq+1:]
+
+		var value string
+		quoted := false
+		if len(line) > 0 && (line[0] == '"' || line[0] == '\'') {
+			end := strings.IndexByte(line[1:], line[0])
+			if end < 0 {
+				end = len(line) - 1
+			}
+			value, quoted = line[1:end+1], true
+			if end+2 <= len(line) {
+				line = line[end+2:]
+			} else {
+				line = ""
+			}
+		} else {
+			end := strings.IndexByte(line, ' ')
+			if end < 0 {
+				end = len(line)
+			}
+			value, line = line[:end], line[end:]
+		}
+
+		if key == "msg" && quoted && strings.Contains(value, "=") {
+			fields = append(fields, parseAuditdFields(value)...)
+			continue
+		}
+		fields = append(fields, auditdField{key: key, value: value, quoted: quoted})
+	}
+	return fields
+}
+
+// execveArgs joins the arguments of an EXECVE record. Long arguments are
+// split into numbered parts, such as a1[0] and a1[1].
+func execveArgs(fields []auditdField) string {
+	type part struct {
+		arg, index int
+		value      string
+	}
+	var parts []part
+	for _, field := range fields {
+		match := execveArg.FindStringSubmatch(field.key)
+		if match == nil {
+			continue
+		}
+		arg, _ := strconv.Atoi(match[1])
+		index := -1
+		if match[2] != "" {
+			index, _ = strconv.Atoi(match[2])
+		}
+		parts = append(parts, part{arg: arg, index: index, value: decodeAuditdValue(field.value, field.quoted)})
+	}
+	sort.SliceStable(parts, func(i, j int) bool {
+		if parts[i].arg != parts[j].arg {
+			return parts[i].arg < parts[j].arg
+		}
+		return parts[i].index < parts[j].index
+	})
+
+	var args []string
+	for i, p := range parts {
+		if i > 0 && parts[i-1].arg == p.arg {
+			args[len(args)-1] += p.value
+			continue
+		}
+		args = append(args, p.value)
+	}
+	return strings.Join(args, " ")
+}
+
+// decodeAuditdValue returns the value of a field. Unquoted values that are hex
+// are decoded, with the NULs separating arguments replaced by spaces.
+func decodeAuditdValue(value string, quoted bool) string {
+	if quoted || len(value)%2 != 0 || value == "" {
+		return value
+	}
+	decoded, err := hex.DecodeString(value)
+	if err != nil {
+		return value
+	}
+	return strings.TrimSpace(strings.ReplaceAll(string(decoded), "\x00", " "))
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"path"
+	"strings"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors/browserlogin"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sqlite"
+)
+
+// BrowserLogins parses the credential stores of browser profiles: the "Login
+// Data" database of Chrome and other Chromium based browsers, and Firefox's
+// logins.json. Saved passwords are encrypted with keys that aren't in the
+// profile, so they aren't decrypted. Instead, the handler sends a chunk for
+// each saved login naming its site and user, so that profiles copied off a
+// machine are flagged.
+type BrowserLogins struct{}
+
+// Ensure the BrowserLogins handler satisfies the interface at compile time.
+var _ Handler = (*BrowserLogins)(nil)
+
+// firefoxLogins holds the fields of logins.json that are kept. Firefox
+// encrypts usernames as well as passwords.
+type firefoxLogins struct {
+	Logins []struct {
+		Hostname          string `json:"hostname"`
+		EncryptedPassword string `json:"encryptedPassword"`
+	} `json:"logins"`
+}
+
+func (h *BrowserLogins) Accepts(path string, header []byte) bool {
+	switch fileName(path) {
+	case "Login Data", "Login Data For Account":
+		return sqlite.IsSQLite(header)
+	case "logins.json":
+		return bytes.Contains(header, []byte(`"nextId"`)) || bytes.Contains(header, []byte(`"logins"`))
+	default:
+		return false
+	}
+}
+
+func (h *BrowserLogins) Handle(ctx context.Context, path string, file io.ReaderAt, chunkSkel *sources.Chunk, chunksChan chan *sources.Chunk) error {
+	send := func(browser, origin, username string) error {
+		chunk := *chunkSkel
+		chunk.Data = []byte(browserlogin.Format(browser, origin, username))
+		chunk.SourceMetadata = &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Filesystem{
+				Filesystem: &source_metadatapb.Filesystem{
+					File: sanitizer.UTF8(path),
+				},
+			},
+		}
+		select {
+		case chunksChan <- &chunk:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if fileName(path) == "logins.json" {
+		var logins firefoxLogins
+		if err := json.NewDecoder(io.NewSectionReader(file, 0, math.MaxInt64)).Decode(&logins); err != nil {
+			return errors.WrapPrefix(err, "could not parse logins.json", 0)
+		}
+		browser := mozillaBrowser(path)
+		for _, login := range logins.Logins {
+			if login.Hostname == "" || login.EncryptedPassword == "" {
+				continue
+			}
+			if err := send(browser, login.Hostname, ""); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	browser := chromiumBrowser(path)
+	err := sqlite.ReadTable(file, "logins", func(row sqlite.Row) error {
+		// Sites the user chose to never save a password for are stored
+		// without one.
+		if row.Int("blacklisted_by_user") != 0 || row.String("password_value") == "" {
+			return nil
+		}
+		origin := row.String("origin_url")
+		if origin == "" {
+			origin = row.String("signon_realm")
+		}
+		return send(browser, origin, row.String("username_value"))
+	})
+	if err != nil {
+		return errors.WrapPrefix(err, "could not read Login Data", 0)
+	}
+	return nil
+}
+
+// fileName returns the last element of a Unix or Windows path.
+func fileName(p string) string {
+	return path.Base(strings.ReplaceAll(p, `\`, "/"))
+}
+
+// chromiumBrowser returns the Chromium based browser a profile belongs to, by
+// its directory.
+func chromiumBrowser(p string) string {
+	p = strings.ToLower(strings.ReplaceAll(p, `\`, "/"))
+	switch {
+	case strings.Contains(p, "microsoft/edge") || strings.Contains(p, "microsoft edge"):
+		return "edge"
+	case strings.Contains(p, "bravesoftware"):
+		return "brave"
+	case strings.Contains(p, "opera"):
+		return "opera"
+	case strings.Contains(p, "vivaldi"):
+		return "vivaldi"
+	case strings.Contains(p, "chromium"):
+		return "chromium"
+	default:
+		return "chrome"
+	}
+}
+
+// mozillaBrowser returns the Mozilla application a logins.json belongs to, by
+// its directory.
+func mozillaBrowser(p string) string {
+	if strings.Contains(strings.ToLower(p), "thunderbird") {
+		return "thunderbird"
+	}
+	return "firefox"
+}
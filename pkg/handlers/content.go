@@ -0,0 +1,435 @@
+package handlers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-errors/errors"
+	"github.com/h2non/filetype"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	// textSniffLen is how much of data is checked for NUL bytes to tell text
+	// from binary, as git does.
+	textSniffLen = 8000
+
+	textContentType   = "text/plain"
+	binaryContentType = "application/octet-stream"
+)
+
+// ContentKind is the kind of content a chunk holds, which decides how it's
+// scanned.
+type ContentKind int
+
+const (
+	// TextContent is scanned as is.
+	TextContent ContentKind = iota
+	// ArchiveContent is unpacked, and its files are scanned.
+	ArchiveContent
+	// DocumentContent has its text extracted.
+	DocumentContent
+	// BinaryContent has its strings extracted.
+	BinaryContent
+	// MediaContent, images, audio and video, has its embedded metadata
+	// extracted.
+	MediaContent
+	// IgnoredContent, such as fonts and compressed formats that can't be
+	// unpacked, isn't scanned.
+	IgnoredContent
+)
+
+// contentKinds are the kinds of the content types that aren't told by their
+// prefix.
+var contentKinds = map[string]ContentKind{
+	"application/zip":      ArchiveContent,
+	"application/epub+zip": ArchiveContent,
+	"application/x-tar":    ArchiveContent,
+	"application/gzip":     ArchiveContent,
+	"application/x-bzip2":  ArchiveContent,
+
+	"application/pdf":               DocumentContent,
+	"application/msword":            DocumentContent,
+	"application/vnd.ms-excel":      DocumentContent,
+	"application/vnd.ms-powerpoint": DocumentContent,
+	"application/rtf":               TextContent,
+
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   DocumentContent,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         DocumentContent,
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": DocumentContent,
+
+	"application/font-woff":             IgnoredContent,
+	"application/font-sfnt":             IgnoredContent,
+	"application/vnd.ms-fontobject":     IgnoredContent,
+	"application/vnd.rar":               IgnoredContent,
+	"application/x-7z-compressed":       IgnoredContent,
+	"application/x-xz":                  IgnoredContent,
+	"application/zstd":                  IgnoredContent,
+	"application/x-compress":            IgnoredContent,
+	"application/x-lzip":                IgnoredContent,
+	"application/vnd.ms-cab-compressed": IgnoredContent,
+}
+
+// containerTypes are the names of the types of the archives and documents
+// that are unpacked, as given in provenance chains.
+var containerTypes = map[string]string{
+	"application/zip":      "zip",
+	"application/epub+zip": "epub",
+	"application/x-tar":    "tar",
+	"application/gzip":     "gzip",
+	"application/x-bzip2":  "bzip2",
+
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   "docx",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         "xlsx",
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": "pptx",
+}
+
+// ContentType returns the MIME type of data, sniffed from its first bytes.
+// Data of no known type is text/plain if it has no NUL bytes, and
+// application/octet-stream otherwise.
+func ContentType(data []byte) string {
+	if kind, err := filetype.Match(data); err == nil && kind != filetype.Unknown {
+		return kind.MIME.Value
+	}
+	head := data
+	if len(head) > textSniffLen {
+		head = head[:textSniffLen]
+	}
+	if bytes.IndexByte(head, 0) < 0 {
+		return textContentType
+	}
+	return binaryContentType
+}
+
+// KindOf returns the kind of content of a MIME type.
+func KindOf(contentType string) ContentKind {
+	if kind, ok := contentKinds[contentType]; ok {
+		return kind
+	}
+	for _, prefix := range []string{"image/", "video/", "audio/"} {
+		if strings.HasPrefix(contentType, prefix) {
+			return MediaContent
+		}
+	}
+	switch {
+	case strings.HasPrefix(contentType, "font/"):
+		return IgnoredContent
+	case strings.HasPrefix(contentType, "text/"):
+		return TextContent
+	}
+	return BinaryContent
+}
+
+// HandleChunk sends the text held by a chunk that's an archive, document or
+// binary, as told by its ContentType. The files of archives are sent by their
+// own content type, the text of PDFs' streams and the XML of Office Open XML
+// documents is sent, and the strings of other documents and binaries are
+// extracted. Archives that can't be unpacked, such as the first chunk of a
+// larger file, have their strings extracted too. The EXIF, XMP and ID3
+// metadata of media is sent, along with the text of images read by the OCR in
+// ctx, if there is one. The chunks sent are text copies of chunk, with its
+// metadata, and the files of archives have the archives they're in added to
+// their provenance. Archives are unpacked within the Limits in ctx, and those
+// that reach them are logged and scanned as far as they were unpacked.
+func HandleChunk(ctx context.Context, chunk *sources.Chunk, chunksChan chan *sources.Chunk) error {
+	skel := *chunk
+	skel.ContentType = textContentType
+	w := &contentWalker{ctx: ctx, chunkSkel: &skel, chunksChan: chunksChan, budget: newUnpackBudget(ctx)}
+	err := w.content(chunk.Data, chunk.ContentType, chunk.Provenance)
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		return nil
+	}
+	return err
+}
+
+// contentWalker sends the text held by a chunk's data.
+type contentWalker struct {
+	ctx        context.Context
+	chunkSkel  *sources.Chunk
+	chunksChan chan *sources.Chunk
+	budget     *unpackBudget
+	// sent is the number of times text was sent.
+	sent int
+}
+
+// file returns the file the chunk is from, to name in warnings.
+func (w *contentWalker) file() string {
+	return sources.MetadataFile(w.chunkSkel.SourceMetadata)
+}
+
+// send sends text as chunks, from the containers in chain.
+func (w *contentWalker) send(data []byte, chain []sources.ContainerRef) error {
+	if len(data) == 0 {
+		return nil
+	}
+	w.sent++
+	skel := *w.chunkSkel
+	skel.Provenance = chain
+	return sendChunks(w.ctx, data, skel.SourceMetadata, &skel, w.chunksChan)
+}
+
+// content sends the text held by data of the given content type, which is
+// in the containers in chain.
+func (w *contentWalker) content(data []byte, contentType string, chain []sources.ContainerRef) error {
+	switch KindOf(contentType) {
+	case IgnoredContent:
+		return nil
+	case MediaContent:
+		metadata := mediaMetadata(data, contentType)
+		// Text that merely starts like a media file, such as "BMW_KEY=" or
+		// "ID3_TOKEN=", has no metadata to parse, and is scanned as text.
+		if len(metadata) == 0 && utf8.Valid(data) {
+			return w.send(data, chain)
+		}
+		if err := w.send(metadata, chain); err != nil {
+			return err
+		}
+		if ocr := OCRFromContext(w.ctx); ocr != nil && ocr.Reads(contentType) {
+			text, err := ocr.Text(w.ctx, data)
+			if err != nil {
+				return err
+			}
+			return w.send(text, chain)
+		}
+		return nil
+	case TextContent:
+		return w.send(data, chain)
+	case ArchiveContent:
+		return w.unpack(data, contentType, chain)
+	case DocumentContent:
+		switch contentType {
+		case "application/pdf":
+			return w.send(w.pdfText(data, chain), chain)
+		case "application/msword", "application/vnd.ms-excel", "application/vnd.ms-powerpoint":
+			return w.send(extractStrings(data), chain)
+		default:
+			// Office Open XML documents are zip archives of XML, which
+			// archive unpacks as it does other zip archives.
+			return w.unpack(data, contentType, chain)
+		}
+	default:
+		return w.send(extractStrings(data), chain)
+	}
+}
+
+// unpack sends the text held by the files of an archive, which is in the
+// containers in chain. If it can't be unpacked before any text is sent, or
+// it's nested deeper than the depth limit, its strings are sent instead.
+func (w *contentWalker) unpack(data []byte, contentType string, chain []sources.ContainerRef) error {
+	if len(chain) >= w.budget.limits.MaxDepth {
+		w.budget.warn(&LimitError{Limit: LimitDepth}, w.file(), chain)
+		return w.send(extractStrings(data), chain)
+	}
+	sent := w.sent
+	err := w.archive(data, contentType, chain)
+	var limitErr *LimitError
+	if err == nil || w.ctx.Err() != nil || errors.As(err, &limitErr) {
+		return err
+	}
+	if w.sent == sent {
+		return w.send(extractStrings(data), chain)
+	}
+	return nil
+}
+
+// archive sends the text held by each file of an archive, which is in the
+// containers in chain.
+func (w *contentWalker) archive(data []byte, contentType string, chain []sources.ContainerRef) error {
+	containerType, ok := containerTypes[contentType]
+	if !ok {
+		containerType = contentType
+	}
+	// What's read of a file before an error, as when a compressed chunk is
+	// cut short, or before a limit, is still sent. Only the total size limit
+	// stops the walk, since the archive's other files may be small.
+	file := func(name string, r io.Reader, compressed func() int64) error {
+		// The chain is copied, so that the chains of files in the same
+		// container don't share what's appended to them.
+		fileChain := make([]sources.ContainerRef, len(chain), len(chain)+1)
+		copy(fileChain, chain)
+		fileChain = append(fileChain, sources.ContainerRef{Type: containerType, Name: sanitizer.UTF8(name)})
+
+		data, err := w.budget.read(r, compressed)
+		if len(data) > 0 {
+			if err := w.content(data, ContentType(data), fileChain); err != nil {
+				return err
+			}
+		}
+		var limitErr *LimitError
+		if errors.As(err, &limitErr) {
+			w.budget.warn(limitErr, w.file(), fileChain)
+			if limitErr.Limit != LimitTotalSize {
+				return nil
+			}
+		}
+		return err
+	}
+
+	switch contentType {
+	case "application/gzip":
+		compressed := &countingReader{r: bytes.NewReader(data)}
+		gz, err := gzip.NewReader(compressed)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		return file(gz.Name, gz, func() int64 { return compressed.n })
+	case "application/x-bzip2":
+		compressed := &countingReader{r: bytes.NewReader(data)}
+		return file("", bzip2.NewReader(compressed), func() int64 { return compressed.n })
+	case "application/x-tar":
+		tr := tar.NewReader(bytes.NewReader(data))
+		for {
+			header, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if header.Typeflag != tar.TypeReg || mediaExts[fileExt(header.Name)] {
+				continue
+			}
+			if err := file(header.Name, tr, nil); err != nil {
+				return err
+			}
+		}
+	default:
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			// The directory at the end of the archive is missing when the
+			// chunk is the start of a larger one.
+			return localZipFiles(data, file)
+		}
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() || mediaExts[fileExt(f.Name)] {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return err
+			}
+			err = file(f.Name, rc, func() int64 { return int64(f.CompressedSize64) })
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// localZipFiles calls fn with the name and contents of each file of a zip
+// archive that's cut short, found by the local header that precedes it,
+// rather than the archive's directory, along with how much of the archive
+// has been read for them. Only stored and deflated files are read, and the
+// last one may be incomplete.
+func localZipFiles(data []byte, fn func(name string, r io.Reader, compressed func() int64) error) error {
+	const (
+		headerLen      = 30
+		dataDescriptor = 0x8
+	)
+	found := false
+	for {
+		start := bytes.Index(data, zipMagic)
+		if start < 0 || len(data)-start < headerLen {
+			break
+		}
+		header := data[start:]
+		flags := binary.LittleEndian.Uint16(header[6:])
+		method := binary.LittleEndian.Uint16(header[8:])
+		size := int(binary.LittleEndian.Uint32(header[18:]))
+		nameLen := int(binary.LittleEndian.Uint16(header[26:]))
+		extraLen := int(binary.LittleEndian.Uint16(header[28:]))
+		if headerLen+nameLen+extraLen > len(header) {
+			break
+		}
+		name := string(header[headerLen : headerLen+nameLen])
+		contents := header[headerLen+nameLen+extraLen:]
+		// Sizes follow the contents of files written as a stream.
+		if flags&dataDescriptor == 0 && size < len(contents) {
+			contents = contents[:size]
+		}
+
+		if !strings.HasSuffix(name, "/") && !mediaExts[fileExt(name)] {
+			compressed := &countingReader{r: bytes.NewReader(contents)}
+			var r io.Reader
+			switch method {
+			case zip.Store:
+				r = compressed
+			case zip.Deflate:
+				r = flate.NewReader(compressed)
+			}
+			if r != nil {
+				found = true
+				if err := fn(name, r, func() int64 { return compressed.n }); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+					return err
+				}
+			}
+		}
+		data = header[headerLen:]
+	}
+	if !found {
+		return zip.ErrFormat
+	}
+	return nil
+}
+
+// pdfText returns the strings of a PDF, which is in the containers in chain,
+// including those of its streams, which are inflated within the limits if
+// they're compressed with the Flate filter, as most are.
+func (w *contentWalker) pdfText(data []byte, chain []sources.ContainerRef) []byte {
+	var text bytes.Buffer
+	text.Write(extractStrings(data))
+
+	rest := data
+	for {
+		start := bytes.Index(rest, []byte("stream"))
+		if start < 0 {
+			break
+		}
+		rest = rest[start+len("stream"):]
+		rest = bytes.TrimPrefix(rest, []byte("\r"))
+		rest = bytes.TrimPrefix(rest, []byte("\n"))
+		end := bytes.Index(rest, []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		compressed := &countingReader{r: bytes.NewReader(rest[:end])}
+		if zr, err := zlib.NewReader(compressed); err == nil {
+			// Streams are often followed by padding, which fails reading
+			// after the stream has been inflated.
+			inflated, err := w.budget.read(zr, func() int64 { return compressed.n })
+			var limitErr *LimitError
+			if errors.As(err, &limitErr) {
+				w.budget.warn(limitErr, w.file(), chain)
+			}
+			text.Write(extractStrings(inflated))
+		}
+		rest = rest[end+len("endstream"):]
+	}
+	return text.Bytes()
+}
+
+// extractStrings returns the strings of binary data, one per line.
+func extractStrings(data []byte) []byte {
+	extractor := &stringExtractor{}
+	for _, b := range data {
+		extractor.add(b)
+	}
+	extractor.flush()
+	return extractor.out.Bytes()
+}
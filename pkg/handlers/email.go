@@ -0,0 +1,391 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/cfb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	// maxEmailDepth limits how deeply messages attached to messages are
+	// parsed.
+	maxEmailDepth = 4
+)
+
+var (
+	// mboxFromPat matches the "From " line that starts each message of an
+	// mbox archive.
+	mboxFromPat = regexp.MustCompile(`^From \S+ +(Mon|Tue|Wed|Thu|Fri|Sat|Sun) `)
+	// emailHeaderPat matches the header fields exported messages usually
+	// start with.
+	emailHeaderPat = regexp.MustCompile(`(?i)^(Return-Path|Received|Delivered-To|X-Original-To|Message-ID|MIME-Version):`)
+	// mboxEscapePat matches lines of mbox messages that start with "From ",
+	// which are escaped with a ">".
+	mboxEscapePat = regexp.MustCompile(`^>+From `)
+)
+
+// Email parses exported mail: .eml files, mbox archives and Outlook .msg
+// files. Headers and bodies are decoded from their transfer encodings and sent
+// as chunks, and attachments are passed to the other handlers, or sent as is
+// if none accepts them. Attachments are named in metadata by the message's
+// path followed by their file name, and added to the chunks' provenance.
+type Email struct{}
+
+// Ensure the Email handler satisfies the interface at compile time.
+var _ Handler = (*Email)(nil)
+
+// emailDepthKey is the context key of the depth of messages attached to
+// messages, which are parsed by nested calls to HandleFile.
+type emailDepthKey struct{}
+
+func (h *Email) Accepts(path string, header []byte) bool {
+	switch fileExt(path) {
+	case ".msg":
+		return cfb.IsCFB(header)
+	case ".eml", ".mbox":
+		return true
+	default:
+		return mboxFromPat.Match(header) || emailHeaderPat.Match(header)
+	}
+}
+
+func (h *Email) Handle(ctx context.Context, path string, file io.ReaderAt, chunkSkel *sources.Chunk, chunksChan chan *sources.Chunk) error {
+	depth, _ := ctx.Value(emailDepthKey{}).(int)
+	w := &emailWalker{ctx: ctx, chunkSkel: chunkSkel, chunksChan: chunksChan}
+	w.setDepth(depth)
+
+	header := make([]byte, 8)
+	n, _ := file.ReadAt(header, 0)
+	header = header[:n]
+	switch {
+	case cfb.IsCFB(header):
+		f, err := cfb.Open(file)
+		if err != nil {
+			return errors.WrapPrefix(err, "could not open .msg file", 0)
+		}
+		return w.msg(f, f.Root(), path)
+	case bytes.HasPrefix(header, []byte("From ")):
+		return w.mbox(io.NewSectionReader(file, 0, math.MaxInt64), path)
+	default:
+		msg, err := mail.ReadMessage(io.NewSectionReader(file, 0, math.MaxInt64))
+		if err != nil {
+			return errors.WrapPrefix(err, "could not parse message", 0)
+		}
+		return w.message(msg, path)
+	}
+}
+
+// emailWalker sends the chunks of a mail file.
+type emailWalker struct {
+	ctx context.Context
+	// depth is the depth of the file in attachments of messages.
+	depth      int
+	chunkSkel  *sources.Chunk
+	chunksChan chan *sources.Chunk
+}
+
+// setDepth sets the depth of the file being parsed. Attachments passed to
+// HandleFile are one level deeper.
+func (w *emailWalker) setDepth(depth int) {
+	w.depth = depth
+	w.ctx = context.WithValue(w.ctx, emailDepthKey{}, depth+1)
+}
+
+// send sends data as chunks from file.
+func (w *emailWalker) send(data []byte, file string) error {
+	metadata := &source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_Filesystem{
+			Filesystem: &source_metadatapb.Filesystem{
+				File: sanitizer.UTF8(file),
+			},
+		},
+	}
+	return sendChunks(w.ctx, data, metadata, w.chunkSkel, w.chunksChan)
+}
+
+// mbox sends the messages of an mbox archive.
+func (w *emailWalker) mbox(r io.Reader, path string) error {
+	var buf bytes.Buffer
+	flush := func() error {
+		if buf.Len() == 0 {
+			return nil
+		}
+		defer buf.Reset()
+		msg, err := mail.ReadMessage(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			// Send what can't be parsed as is, rather than skip the rest of
+			// the archive.
+			return w.send(append([]byte(nil), buf.Bytes()...), path)
+		}
+		return w.message(msg, path)
+	}
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			switch {
+			case mboxFromPat.Match(line):
+				if err := flush(); err != nil {
+					return err
+				}
+			case mboxEscapePat.Match(line):
+				buf.Write(line[1:])
+			default:
+				buf.Write(line)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return flush()
+		}
+		if err != nil {
+			return errors.WrapPrefix(err, "could not read mbox", 0)
+		}
+	}
+}
+
+// message sends the header and parts of a parsed message.
+func (w *emailWalker) message(msg *mail.Message, path string) error {
+	keys := make([]string, 0, len(msg.Header))
+	for key := range msg.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var header bytes.Buffer
+	for _, key := range keys {
+		for _, value := range msg.Header[key] {
+			fmt.Fprintf(&header, "%s: %s\n", key, decodeHeader(value))
+		}
+	}
+	if err := w.send(header.Bytes(), path); err != nil {
+		return err
+	}
+	return w.part(textproto.MIMEHeader(msg.Header), msg.Body, path)
+}
+
+// part sends a part of a message, and the parts of multipart parts.
+func (w *emailWalker) part(header textproto.MIMEHeader, body io.Reader, path string) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain"
+	}
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return errors.WrapPrefix(err, "could not read multipart message", 0)
+			}
+			if err := w.part(part.Header, part, path); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not read message part", 0)
+	}
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	name := decodeHeader(dispositionParams["filename"])
+	if name == "" {
+		name = decodeHeader(params["name"])
+	}
+	if disposition != "attachment" && name == "" && strings.HasPrefix(mediaType, "text/") {
+		return w.send(data, path)
+	}
+	if mediaType == "message/rfc822" {
+		if name == "" {
+			name = "message.eml"
+		}
+		if !strings.HasSuffix(strings.ToLower(name), ".eml") {
+			name += ".eml"
+		}
+	}
+	return w.attachment(data, mediaType, name, path)
+}
+
+// attachment passes an attachment to the handler that accepts it, or sends
+// it as is. Media files aren't sent.
+func (w *emailWalker) attachment(data []byte, mediaType, name, path string) error {
+	for _, prefix := range []string{"image/", "audio/", "video/"} {
+		if strings.HasPrefix(mediaType, prefix) {
+			return nil
+		}
+	}
+	if name == "" {
+		name = "attachment"
+	}
+	attachmentPath := path + "/" + strings.ReplaceAll(name, "/", "_")
+	attached := *w
+	attached.chunkSkel = inContainer(w.chunkSkel, "email", name)
+
+	if w.depth < maxEmailDepth {
+		handled, err := HandleFile(w.ctx, attachmentPath, bytes.NewReader(data), attached.chunkSkel, w.chunksChan)
+		if err != nil {
+			return err
+		}
+		if handled {
+			return nil
+		}
+	}
+	return attached.send(data, attachmentPath)
+}
+
+// msg sends the header fields, bodies and attachments of an Outlook message,
+// which is a storage of a .msg file. Message properties are streams named by
+// their ID and type.
+// https://learn.microsoft.com/en-us/openspecs/exchange_server_protocols/ms-oxmsg
+func (w *emailWalker) msg(f *cfb.File, storage *cfb.Entry, path string) error {
+	var header bytes.Buffer
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&header, "%s: %s\n", name, value)
+		}
+	}
+	field("From", msgAddress(msgString(f, storage, "0C1A"), msgString(f, storage, "0C1F")))
+	field("To", msgString(f, storage, "0E04"))
+	field("Cc", msgString(f, storage, "0E03"))
+	field("Subject", msgString(f, storage, "0037"))
+	for _, child := range storage.Children() {
+		if strings.HasPrefix(child.Name, "__recip_version1.0_") {
+			field("Recipient", msgAddress(msgString(f, child, "3001"), msgString(f, child, "3003")))
+		}
+	}
+	// The headers of messages that were received over SMTP.
+	header.WriteString(msgString(f, storage, "007D"))
+	if err := w.send(header.Bytes(), path); err != nil {
+		return err
+	}
+
+	if err := w.send([]byte(msgString(f, storage, "1000")), path); err != nil {
+		return err
+	}
+	if err := w.send(msgBinary(f, storage, "1013"), path); err != nil {
+		return err
+	}
+
+	for _, child := range storage.Children() {
+		if !strings.HasPrefix(child.Name, "__attach_version1.0_") {
+			continue
+		}
+		name := msgString(f, child, "3707")
+		if name == "" {
+			name = msgString(f, child, "3704")
+		}
+		// Attached messages are storages rather than data.
+		if embedded := child.Child("__substg1.0_3701000D"); embedded != nil && embedded.IsStorage() {
+			if name == "" {
+				name = "message.msg"
+			}
+			if w.depth >= maxEmailDepth {
+				continue
+			}
+			nested := *w
+			nested.setDepth(w.depth + 1)
+			nested.chunkSkel = inContainer(w.chunkSkel, "email", name)
+			if err := nested.msg(f, embedded, path+"/"+strings.ReplaceAll(name, "/", "_")); err != nil {
+				return err
+			}
+			continue
+		}
+		data := msgBinary(f, child, "3701")
+		if len(data) == 0 {
+			continue
+		}
+		if err := w.attachment(data, msgString(f, child, "370E"), name, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// msgString returns a string property of an Outlook message, which is
+// stored as UTF-16LE or, in older files, 8-bit text.
+func msgString(f *cfb.File, storage *cfb.Entry, id string) string {
+	if data := msgStream(f, storage, id+"001F"); len(data) > 0 {
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			units[i] = uint16(data[2*i]) | uint16(data[2*i+1])<<8
+		}
+		return strings.TrimRight(string(utf16.Decode(units)), "\x00")
+	}
+	return strings.TrimRight(string(msgStream(f, storage, id+"001E")), "\x00")
+}
+
+// msgBinary returns a binary property of an Outlook message.
+func msgBinary(f *cfb.File, storage *cfb.Entry, id string) []byte {
+	return msgStream(f, storage, id+"0102")
+}
+
+// msgStream returns the stream of a property, or nil if the message doesn't
+// have it.
+func msgStream(f *cfb.File, storage *cfb.Entry, tag string) []byte {
+	entry := storage.Child("__substg1.0_" + tag)
+	if entry == nil || entry.IsStorage() {
+		return nil
+	}
+	data, err := f.ReadStream(entry)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// msgAddress formats a name and email address.
+func msgAddress(name, address string) string {
+	switch {
+	case address == "":
+		return name
+	case name == "" || name == address:
+		return address
+	default:
+		return fmt.Sprintf("%s <%s>", name, address)
+	}
+}
+
+// decodeHeader decodes the MIME encoded-words of a header value.
+func decodeHeader(value string) string {
+	decoded, err := new(mime.WordDecoder).DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+// fileExt returns the lowercased extension of a Unix or Windows path.
+func fileExt(p string) string {
+	return strings.ToLower(path.Ext(fileName(p)))
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/evtx"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// EVTX parses Windows event log files, sending a chunk for each event with
+// its data and message.
+type EVTX struct{}
+
+// Ensure the EVTX handler satisfies the interface at compile time.
+var _ Handler = (*EVTX)(nil)
+
+func (h *EVTX) Accepts(path string, header []byte) bool {
+	return evtx.IsEVTX(header)
+}
+
+func (h *EVTX) Handle(ctx context.Context, path string, file io.ReaderAt, chunkSkel *sources.Chunk, chunksChan chan *sources.Chunk) error {
+	return evtx.ReadFile(file, func(event *evtx.Event) error {
+		data := event.Text()
+		if len(data) == 0 {
+			return nil
+		}
+		chunk := *chunkSkel
+		chunk.Data = data
+		chunk.SourceMetadata = EventMetadata(event, path)
+		select {
+		case chunksChan <- &chunk:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// EventMetadata returns the metadata for an event, read from file if it came
+// from an .evtx file.
+func EventMetadata(event *evtx.Event, file string) *source_metadatapb.MetaData {
+	var timestamp string
+	if !event.Time.IsZero() {
+		timestamp = event.Time.Format(time.RFC3339)
+	}
+	return &source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_WindowsEventLog{
+			WindowsEventLog: &source_metadatapb.WindowsEventLog{
+				Channel:   sanitizer.UTF8(event.Channel),
+				EventId:   event.EventID,
+				RecordId:  event.RecordID,
+				Provider:  sanitizer.UTF8(event.Provider),
+				Computer:  sanitizer.UTF8(event.Computer),
+				Timestamp: timestamp,
+				File:      sanitizer.UTF8(file),
+			},
+		},
+	}
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"strings"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+var crxMagic = []byte("Cr24")
+
+// BrowserExtension unpacks Chrome .crx and Firefox .xpi extension packages,
+// and sends the files they hold, such as scripts and manifest.json, as
+// chunks. An XPI is a zip archive, and a CRX is one preceded by a header
+// holding its signature. Files are named in metadata by the package's path
+// followed by their path in it, and added to the chunks' provenance.
+type BrowserExtension struct{}
+
+// Ensure the BrowserExtension handler satisfies the interface at compile
+// time.
+var _ Handler = (*BrowserExtension)(nil)
+
+func (h *BrowserExtension) Accepts(path string, header []byte) bool {
+	switch fileExt(path) {
+	case ".crx":
+		return bytes.HasPrefix(header, crxMagic)
+	case ".xpi":
+		return bytes.HasPrefix(header, zipMagic)
+	default:
+		return false
+	}
+}
+
+func (h *BrowserExtension) Handle(ctx context.Context, path string, file io.ReaderAt, chunkSkel *sources.Chunk, chunksChan chan *sources.Chunk) error {
+	size, err := readerSize(file)
+	if err != nil {
+		return err
+	}
+	offset, err := crxZipOffset(file)
+	if err != nil {
+		return err
+	}
+	if offset > size {
+		return errors.New("CRX header is longer than the file")
+	}
+	zr, err := zip.NewReader(io.NewSectionReader(file, offset, size-offset), size-offset)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not open extension package", 0)
+	}
+
+	containerType := strings.TrimPrefix(fileExt(path), ".")
+	budget := newUnpackBudget(ctx)
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || mediaExts[fileExt(f.Name)] || extensionSignature(f.Name) {
+			continue
+		}
+		data, err := budget.readZipFile(f, path)
+		if err != nil {
+			return err
+		}
+		metadata := &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Filesystem{
+				Filesystem: &source_metadatapb.Filesystem{
+					File: sanitizer.UTF8(path + "/" + f.Name),
+				},
+			},
+		}
+		if err := sendChunks(ctx, bundleFileText(data), metadata, inContainer(chunkSkel, containerType, f.Name), chunksChan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// crxZipOffset returns the offset of the zip archive in an extension package:
+// past the header of a CRX, and 0 for an XPI.
+// https://source.chromium.org/chromium/chromium/src/+/main:components/crx_file/crx3.proto
+func crxZipOffset(file io.ReaderAt) (int64, error) {
+	header := make([]byte, 16)
+	n, err := file.ReadAt(header, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, errors.WrapPrefix(err, "could not read CRX header", 0)
+	}
+	header = header[:n]
+	if !bytes.HasPrefix(header, crxMagic) {
+		return 0, nil
+	}
+	if len(header) < 12 {
+		return 0, errors.New("truncated CRX header")
+	}
+
+	switch version := binary.LittleEndian.Uint32(header[4:]); version {
+	case 2:
+		// Version 2 headers hold the lengths of the public key and signature
+		// that follow them.
+		if len(header) < 16 {
+			return 0, errors.New("truncated CRX header")
+		}
+		keyLen := int64(binary.LittleEndian.Uint32(header[8:]))
+		sigLen := int64(binary.LittleEndian.Uint32(header[12:]))
+		return 16 + keyLen + sigLen, nil
+	case 3:
+		// Version 3 headers hold the length of the signed header protobuf
+		// that follows them.
+		return 12 + int64(binary.LittleEndian.Uint32(header[8:])), nil
+	default:
+		return 0, errors.Errorf("unsupported CRX version %d", version)
+	}
+}
+
+// extensionSignature reports whether the file of an extension package is part
+// of its signature, which only holds hashes of the other files.
+func extensionSignature(name string) bool {
+	return strings.HasPrefix(name, "META-INF/") || strings.HasPrefix(name, "_metadata/")
+}
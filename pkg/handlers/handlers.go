@@ -0,0 +1,107 @@
+// Package handlers parses files that can't be scanned as raw bytes, such as
+// binary logs, into chunks that can.
+package handlers
+
+import (
+	"context"
+	"io"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	// headerSize is the number of bytes handlers are given to recognize a
+	// file.
+	headerSize = 512
+
+	// chunkSize is the size of the chunks sendChunks splits data into, and
+	// chunkOverlap is how much consecutive chunks overlap, so that secrets on
+	// a boundary are found.
+	chunkSize    = 10 * 1024
+	chunkOverlap = 3 * 1024
+)
+
+// Handler parses a kind of file into chunks.
+type Handler interface {
+	// Accepts reports whether the handler parses the file at path, given its
+	// first bytes.
+	Accepts(path string, header []byte) bool
+	// Handle sends the file's contents to chunksChan. Each chunk is a copy of
+	// chunkSkel with its Data and SourceMetadata set.
+	Handle(ctx context.Context, path string, file io.ReaderAt, chunkSkel *sources.Chunk, chunksChan chan *sources.Chunk) error
+}
+
+// DefaultHandlers returns the handlers sources use for the files they scan.
+func DefaultHandlers() []Handler {
+	return []Handler{
+		&EVTX{},
+		&Auditd{},
+		&UnifiedLog{},
+		&BrowserLogins{},
+		&MemoryDump{},
+		&Email{},
+		&MobileApp{},
+		&BrowserExtension{},
+	}
+}
+
+// HandleFile passes the file to the first of the default handlers that accepts
+// it, or to the OCR in ctx if it's an image. It reports false if none did, in
+// which case the file should be scanned as is.
+func HandleFile(ctx context.Context, path string, file io.ReaderAt, chunkSkel *sources.Chunk, chunksChan chan *sources.Chunk) (bool, error) {
+	header := make([]byte, headerSize)
+	n, err := file.ReadAt(header, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, errors.WrapPrefix(err, "could not read file header", 0)
+	}
+	header = header[:n]
+
+	handlers := DefaultHandlers()
+	if ocr := OCRFromContext(ctx); ocr != nil {
+		handlers = append(handlers, ocr)
+	}
+	for _, handler := range handlers {
+		if handler.Accepts(path, header) {
+			return true, handler.Handle(ctx, path, file, chunkSkel, chunksChan)
+		}
+	}
+	return false, nil
+}
+
+// sendChunks sends data to chunksChan as overlapping chunks with the given
+// metadata.
+func sendChunks(ctx context.Context, data []byte, metadata *source_metadatapb.MetaData, chunkSkel *sources.Chunk, chunksChan chan *sources.Chunk) error {
+	for start := 0; start < len(data); start += chunkSize {
+		end := start + chunkSize + chunkOverlap
+		if end > len(data) {
+			end = len(data)
+		}
+		chunk := *chunkSkel
+		chunk.Data = data[start:end]
+		chunk.SourceMetadata = metadata
+		select {
+		case chunksChan <- &chunk:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if end == len(data) {
+			break
+		}
+	}
+	return nil
+}
+
+// inContainer returns a copy of chunkSkel for the chunks of a file in a
+// container, such as an email attachment, with the file appended to its
+// provenance.
+func inContainer(chunkSkel *sources.Chunk, containerType, name string) *sources.Chunk {
+	skel := *chunkSkel
+	skel.Provenance = make([]sources.ContainerRef, len(chunkSkel.Provenance), len(chunkSkel.Provenance)+1)
+	copy(skel.Provenance, chunkSkel.Provenance)
+	skel.Provenance = append(skel.Provenance, sources.ContainerRef{Type: containerType, Name: sanitizer.UTF8(name)})
+	return &skel
+}
@@ -0,0 +1,1003 @@
+package handlers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// loginDataDB is a gzipped Chrome "Login Data" database with 512 byte pages,
+// holding a login to github.com, a site the user chose to never save a
+// password for, and a login to mail.example.org.
+const loginDataDB = "" +
+	"H4sIAAAAAAACA+1Vy27TQBS9Y7dpQHjBQ4rCasSqVaKYqjsk1DiVFSJcp7guUmBhjZ0hGXVs" +
+	"p55xaVlSsWHDT/CJfADjRGlertgitUf2te+ce85czYzs0w8OkxR/SbOYSHwAGiAEbYwBQFf3" +
+	"FiygreUI/g0dWhHanqtX9Q/4D2AUm4QqgH6jz+pxT3GqG4cANXgJh5VqFcZSTsQb04wJ4y16" +
+	"ReIJp600G5mEs4he7r9++uz5izuLwGx3f920oasVpgY8UaaPFqYhSc5v66M0Nu8k5kafkPEW" +
+	"oAGv4GC5uxGT4zycVvJ0xJJ0ItpLctXmT6TpW9uVnRLF3LvYf4T+gLoecE/wGOl1ND0yQjem" +
+	"n+Z9gJsiLKNahO/f0E6tXkc/+pKEnCrRiVapNRpoMM3FBVd/j0DQi5wm0XqqH3m25dvYtzqO" +
+	"jdfI3YTEtKmyvc05VGOzqK04zMbwbpox9RLkGccfLe/oneVht+9j98xxmphEkqUrZBPngmbF" +
+	"dAHlNKaJLGEuCc/pYnxChPiaZsNNxS0zU3ScfqeJRR7GTG4WCzZKVDMZJTwu6XVI1IJEipV0" +
+	"iHuub3ftZTrkJDrnTCg2CK+DoteSKhGN1awlBFt4nni9Y8sb4Pf2AFtnfr/nqoU9tl1/7y8o" +
+	"OVT+AAgAAA=="
+
+// msgFile is a gzipped Outlook message from ops@example.com to
+// dev@example.com, with a 5002 byte deploy.env attachment that ends with a
+// DEPLOY_TOKEN line.
+const msgFile = "" +
+	"H4sIAAAAAAACA+1ZzW7TQBAeJ22alvJTgTgghCz1htQqaZCQEEX81OVARBBESJys0LhJRBpb" +
+	"sSntjTNvwSPwJPAI8CDEfLNZixC1ym6Ti9F8q3X2W3n2mx3vjlfxj+8bP79+u/WLpvCIijRK" +
+	"V6k00eegXs7INaJ13TdK05S7NlBTQa6w6baDqB+eunGQJL1BJ16rN577de+tV9/tDQ5DoUKF" +
+	"ChUqVKhQoUKFChUqVKhQoUKFzqZ73qt6453fbLzwXu4GJ62jqB9sjf9620rCD8FgjXKJN5RQ" +
+	"izrUowGuLtoHKAHFKCZoUIQ7XWrCpkVH1vqhsn8M6xNlH1Ef7W34EBqNtoe7j+fQb1IXli58" +
+	"+DcOERjH4BP8GFIbPT01zy59xB0JbIa0g7I9Z/zn9b+t7C8eP7Znm5BOYRVgbsckyA/42810" +
+	"LZ3RL/g/8Ro7N0Q+cslTeWmIfWyDm7TsZGM5hjb8qvtcGLd9lAiqnMc5JybIk/z28JFHmMfg" +
+	"ITyrIrtUzhirQgWlX9DfHk39dumvfoyc/F5l8I7W8VErVKP76rdK++eOc1frFy30OU63Z+o/" +
+	"Q/uJsf6ShX5h/PnWQH/fWH/ZQp9jdWemvod6z1i/ZKG/ZDD/qlKuGOuvWOhzrJ5O6A+x0g+w" +
+	"zqNzVr1PmxPejEuGB+Qofd5TZYucTzPmX9MzN53/qsX8VwziX9M70FQ/g4l+eWr9taCcqJNr" +
+	"1/oJPNTxZ1xaaPw595jnn3WL+POzumGkX9V1Z6HxZ3y5vrgzzEX0F4k86zv6fVDUeZFzU0nv" +
+	"0bJeKyO9trOz4BW0r+o9zPy3HKNyiz8WI4AhACYAAA=="
+
+// apkFile is a gzipped APK of the com.example.notes app, version 2.4.1 (42),
+// whose manifest refers to its version_name string resource. It holds a
+// compiled manifest and preferences screen, a classes.dex with a couple of
+// strings, a JSON asset, an icon and a resource table.
+const apkFile = "" +
+	"H4sIAAAAAAACAwvwZmYRYWBg4GBgSFgYdumBYlQFIwNDCjMDgzBQ1DEvpSg/M8U3MS8zLbW4" +
+	"RK8iN6d00kk/JkcR1/zyZh++FToxOwp8mY4kJt6RfHxI8djUi1O3PWjoYVTZZfSR+wfb/sPv" +
+	"Pyj/YSqvWqXBVLbAanp46f3a73vXqQUETXX4tEXv56VJx14XJe1nmneAoabB/wPrvoP8Mz4v" +
+	"4S3g0TtgLdHQ4mQaGuiuG9oZFNf/6uOWrcuF/MNTZ1ibBpSF9rzoTol+4vUh92qH7JbY6n/v" +
+	"d7+Z4vpJP0X6xes33S+ivhiHps0z22nkt1DuwZKeX0f4bmrtWsb3Y63P2TmR4ZM9b2XO/vD9" +
+	"2nZ2cXX7d8LLy1dE+5W+Tllm/jnkpewiKSuv1cbL713f2VeyVlj2FVcqz98V9XqL9CL+a208" +
+	"9nLqjpW6udnH/S6pR1/fvNYnsl3Op3E7z9tzevqrTYUP6HvnvT983+dD2tmbnEVcd+oPF6S9" +
+	"vz078IF17ZmnDzZVHj96fM7zk/XG/3YL/p/y+fuc/eZfnt7m/Suct+6yx91Fqz+rvzJSePvq" +
+	"Ul80w+c+Y8OOPb9PZEvNn3VwtvXHtDsP5QNQIspUaYN8BJCXB8TcQJyck1hcnFqsl5Ja4e25" +
+	"9oGRgVlqQkpSWmJq8vMHTx49e/j0sbiAiJCYoKjw9AlTJk2bOHXyeQ1NTY1tl67765y44r3W" +
+	"44zuCc+r3ufOX9U3TklI0vHz1fPS3dja9XrZkrAXTi0SFxgZUB0g97BHVA3IUwFiUSAuSi3W" +
+	"TylKLE9MyknVz0zOz9MryEt/zfOh+PnTSY9Onda/6HtF18v3zMWzZ3x9/K/6aule1D13TkfD" +
+	"wxfd4GadV6s2AHkywGQoCDUYmOz0C4pS04pBCTDW35CvyUFAxoV546INGxYoTBOZM/dY+IwL" +
+	"t2L6TAVsFcolnvr/C/zOxPI4Rm37zLs3z/ziEKxuaJfIZ9DuScjtl8x+1liZGDfTUeH1IZPQ" +
+	"RhPd3etsORNDPaNu5eSkrLrotM088URXZsX207+jVM+v0hZO33zuuJeb/srb1pbC77fxr136" +
+	"UtA13TncKET71FTFVe05gtucXWokkmN0Iuz/veDzXmf+Z89PnR950ySLXtb9z7//5DcTqvce" +
+	"bzlxQR7IUwRiISAGRVtJsT4wwNIy0/WyivPzVodpnTzvdzZ4U1CAFzRefHX0/HQvtnJcL8KI" +
+	"Bt6yHz+AvBNMDAx8kNDKLy1KBqaExKLi5LtBhnmHgEF1W0DRxXHipEMeIibbJnWIdYovWeSy" +
+	"qddS0fuY3d2ZDqtqFM+f2jxjqz7jr5A1prN2bv1+bVasvPKfHw3sB1mkixjEfc4V9jIaqv36" +
+	"+31X4LQ/YvqPwn2Wf9t8SnhuZPWqs3krz910nK5ldOqa++TVHvMZ7L8EffuZVNsnxX4kwEJ5" +
+	"8SWNlckhZ8xDxPplFWuXinrsXX390V+u2H/maeZVLl9ehB0+HmB/UTWQNfb27qre2Tcf1pWd" +
+	"P8Kd0ia5/7JHhPq91d/e8W962MuqHnB3r3SS+71HJ/RO3m+5cT7+8e2/67/4dF61r1D+Lbro" +
+	"tmmJjEVeeci5vtv3isT2AcOIkUmEGXexBgMNjAzYCzl0A9CzG8KAlYwomQ9dI3o2QWjUYsKR" +
+	"adCNQM8QCCOambBkD3Tt6AkOoT2JGVvyw/ABWgpD6N/IjJ7eArxZ2UBybEAICu+rLCAeABdU" +
+	"rGtjBgAA"
+
+// ipaFile is a gzipped IPA of the same app, with a binary Info.plist, an
+// executable, an XML GoogleService-Info.plist, a strings file, the bundle's
+// code signature and an icon.
+const ipaFile = "" +
+	"H4sIAAAAAAACAwvwZmYRYWBg4GBgSFgYBmQwMDFAAFCEISCxMic/MUWfmSEARd3VR/mT5wN5" +
+	"V4FYBkmdX35JarFeYkGBvmdeWr5eQU5mcYm31omTOnqGBjsOnThy5tCpY+eE9RWevHkWMmv6" +
+	"pMmvZk6aFT5p5rSZUSL6CqzCPOdO6GtxeutqnTtznltP89QZ735xgQbPNrafLSFiS8SOc384" +
+	"3LLA6zEry+TPxV+DtIWKuYuCvn765B3U7dz7xecLj3hRzJMnTptEM9wKityy8rI+Or0L2LAr" +
+	"QVnYmc/R9PmGnlnLpn2z/DzNoIFRWbkl7UGqA6s2mseYLik36wF5PkAsjtVjYJb1/9dv7eXY" +
+	"2NzYZBQs1Y1N16vsszDdqbH1iNlWJcPtCuqh5prG+htXBIkrcYVzo1nBlqYbdgPI02NkYNDC" +
+	"aoV7fn56TmpwalFZZnKqLiIkY/sO+h02EHA5/0S75/25S9GtMeGqnksdXHQL+G1DFSMPZAlF" +
+	"b3O9tf/4wokCMuVl9858+Lrz/Qlxq/VnNyzq/tInJCN1+dXDZNfvWaenlegK1c1+b1Q6gXXj" +
+	"3G+NJ/46h6a1GDX0bpVleqKlvkkrXrRTkuGoseoXY5ZqfoO+5Cj/dVsM/6Z8UZi78cWhpV2J" +
+	"OkyZ0lFS2ete1j9z2XrVV+Akr/1D15yUyhotPpllyll2OyxCH+x8l8xcfZ3vfla/zj7LM+cl" +
+	"p+rvPq4UfNzovz+/2oL04mP6xx7eOvMwfbrHPkbU8Hk/edpaCSBPDBRGWMMnNU8vp6AoP0vf" +
+	"Jz85MSezKjEpJ1WvuKQoMy+9ONhL38tX9+QZ7/CgDaEBnzzOnjwZvukZE1ocvCwMsTkK5Gky" +
+	"4rIj3jk/JTU4Mz0vsaS0KFUfxAtKLc4vLUpOLS7t28vXZCDill7ZdWtu3N0exp4/MyYIHOVo" +
+	"Vm5c1OsgneFy756YwbGDC19O/z13XnT291XaX5bkHq9Ifmwamegye474mp03LXz5nrG9lzkc" +
+	"9Utdf+cxRiHR1ypdweZcti2+DXsFRFk/CMsfDazwnFDEwOLe8yHWayPri9/zUmYpWa43veF4" +
+	"p8gx2tLFK20V+5IJrGHZar/Pl1/ZkrCzpKf164Lm4E1dZWIvVqgVG4cmBhcZSdntZTKa+XKD" +
+	"Pfu7+cnZfGkBrYE/97ovTN6DFuhLONmruKCZXhVrgDgWFHgm5+eZGVSYGTgYVegV5KW/5vlQ" +
+	"/PzppEeg0GVkEmHGXY7AQAMjA5Lh6LrQSxWELg0CZQy6SejZGGESIyPOTI1uCHpGRRiSwkhK" +
+	"tkU3Fz2BI8xtYSI1uaObjZ6wEWa/YCI1maObjZ5GEGb/ZCYyxQR4s7KBtLADoQbQQW4sIB4A" +
+	"45rWAoQGAAA="
+
+func TestHandleFile(t *testing.T) {
+	emptyLog := make([]byte, 4096)
+	copy(emptyLog, "ElfFile\x00")
+
+	loginData := decodeTestFile(t, loginDataDB)
+	msg := decodeTestFile(t, msgFile)
+	apk := decodeTestFile(t, apkFile)
+	ipa := decodeTestFile(t, ipaFile)
+	xpi := zipArchive(t,
+		"manifest.json", `{"name": "Notes Clipper", "version": "1.2"}`,
+		"icons/icon-48.png", "\x89PNG\r\n\x1a\n",
+		"scripts/background.js", `const apiKey = "example-extension-key";`,
+		"META-INF/mozilla.rsa", "signature",
+	)
+	// A version 3 header, with a 4 byte signed header.
+	crx := "Cr24\x03\x00\x00\x00\x04\x00\x00\x00\x0a\x02\x08\x01" + string(xpi)
+
+	elfHeader := make([]byte, 64)
+	copy(elfHeader, "\x7fELF\x02\x01\x01")
+	executable := string(elfHeader) + "GITHUB_TOKEN=ghp_example\x00"
+	elfHeader[16] = 4
+	core := string(elfHeader) + "\x00\x01GITHUB_TOKEN=ghp_example\x00\xff" + utf16le("password=hunter22") + "\x00short\x00"
+
+	tests := []struct {
+		name        string
+		path        string
+		data        string
+		wantHandled bool
+		wantData    []string
+	}{
+		{
+			name: "text file",
+			path: "notes.txt",
+			data: "aws_secret_access_key = example",
+		},
+		{
+			name: "empty file",
+			path: "empty",
+		},
+		{
+			name:        "event log",
+			path:        "Security.evtx",
+			data:        string(emptyLog),
+			wantHandled: true,
+		},
+		{
+			name: "auditd",
+			path: "audit.log",
+			data: `type=SYSCALL msg=audit(1654086000.123:42): arch=c000003e syscall=59 success=yes exit=0 comm="curl" exe="/usr/bin/curl" key=(null)
+type=EXECVE msg=audit(1654086000.123:42): argc=4 a0="curl" a1="-u" a2=61646D696E3A68756E7465722032 a3="https://example.com"
+type=PROCTITLE msg=audit(1654086000.123:42): proctitle=6375726C002D75
+type=EOE msg=audit(1654086000.123:42):
+type=EXECVE msg=audit(1654086001.000:43): argc=2 a0="mysql" a1_len=20 a1[0]=2D2D70617373776F72 a1[1]=643D73332063723374
+type=USER_CMD msg=audit(1654086002.000:44): pid=1 uid=0 auid=1000 ses=1 msg='cwd="/root" cmd=6563686F202268756E7465723222 terminal=pts/0 res=success'
+`,
+			wantHandled: true,
+			wantData: []string{
+				"SYSCALL comm=curl exe=/usr/bin/curl\nEXECVE command=curl -u admin:hunter 2 https://example.com\n",
+				"EXECVE command=mysql --password=s3 cr3t\n",
+				"USER_CMD cwd=/root cmd=echo \"hunter2\"\n",
+			},
+		},
+		{
+			name: "unified log json",
+			path: "system.json",
+			data: `[{
+  "traceID" : 1234,
+  "eventMessage" : "connecting with token \"ghp_example\/1\"",
+  "processImagePath" : "\/usr\/local\/bin\/agent",
+  "processID" : 812,
+  "subsystem" : "com.example.agent",
+  "timestamp" : "2022-06-01 12:30:00.000000+0000"
+},{
+  "traceID" : 1235,
+  "eventMessage" : ""
+}]`,
+			wantHandled: true,
+			wantData:    []string{`connecting with token "ghp_example/1"`},
+		},
+		{
+			name: "unified log ndjson",
+			path: "system.ndjson",
+			data: `{"traceID":1,"eventMessage":"password=hunter2"}
+{"traceID":2,"eventMessage":"done"}
+`,
+			wantHandled: true,
+			wantData:    []string{"password=hunter2", "done"},
+		},
+		{
+			name:        "chrome login data",
+			path:        "Users/ops/AppData/Local/Microsoft/Edge/User Data/Default/Login Data",
+			data:        string(loginData),
+			wantHandled: true,
+			wantData: []string{
+				`browser saved login: browser=edge origin="https://github.com/login" username="ops@example.com"`,
+				`browser saved login: browser=edge origin="https://mail.example.org/" username="alice"`,
+			},
+		},
+		{
+			name: "sqlite database",
+			path: "Default/History",
+			data: string(loginData),
+		},
+		{
+			name:        "firefox logins",
+			path:        `C:\Users\ops\AppData\Roaming\Mozilla\Firefox\Profiles\x1b2c3d4.default-release\logins.json`,
+			data:        `{"nextId":3,"logins":[{"id":1,"hostname":"https://accounts.example.com","httpRealm":null,"formSubmitURL":"https://accounts.example.com","usernameField":"user","passwordField":"pass","encryptedUsername":"MDoEEPgAAAAAAAAAAAAAAAAAAAEwFAYIKoZIhvcNAwcECMw0","encryptedPassword":"MDoEEPgAAAAAAAAAAAAAAAAAAAEwFAYIKoZIhvcNAwcECB1z","encType":1},{"id":2,"hostname":"https://empty.example.com","encryptedUsername":"","encryptedPassword":""}],"potentiallyVulnerablePasswords":[],"version":3}`,
+			wantHandled: true,
+			wantData: []string{
+				`browser saved login: browser=firefox origin="https://accounts.example.com" username=""`,
+			},
+		},
+		{
+			name: "eml",
+			path: "export/staging.eml",
+			data: strings.ReplaceAll(`Return-Path: <ops@example.com>
+From: =?UTF-8?Q?Ops_T=C3=A9am?= <ops@example.com>
+To: dev@example.com
+Subject: Staging access
+MIME-Version: 1.0
+Content-Type: multipart/mixed; boundary="outer"
+
+--outer
+Content-Type: multipart/alternative; boundary="inner"
+
+--inner
+Content-Type: text/plain; charset=utf-8
+Content-Transfer-Encoding: quoted-printable
+
+The staging password is hunter2=
+2, see the attached profile.
+--inner
+Content-Type: text/html; charset=utf-8
+Content-Transfer-Encoding: base64
+
+PHA+VGhlIHN0YWdpbmcgcGFzc3dvcmQgaXMgaHVudGVyMjI8L3A+
+--inner--
+--outer
+Content-Type: application/json; name="logins.json"
+Content-Disposition: attachment; filename="logins.json"
+Content-Transfer-Encoding: base64
+
+eyJuZXh0SWQiOjIsImxvZ2lucyI6W3siaWQiOjEsImhvc3RuYW1lIjoiaHR0
+cHM6Ly9jaS5leGFtcGxlLmNvbSIsImVuY3J5cHRlZFVzZXJuYW1lIjoiTURJRUVQZ0FBQUFBIiwiZW5jcnlwdGVkUGFzc3dvcmQiOiJNRElFRVBnQUFBQUIifV19
+--outer
+Content-Type: image/png; name="logo.png"
+Content-Transfer-Encoding: base64
+
+iVBORw0KGgo=
+--outer--
+`, "\n", "\r\n"),
+			wantHandled: true,
+			wantData: []string{
+				"Content-Type: multipart/mixed; boundary=\"outer\"\nFrom: Ops Téam <ops@example.com>\nMime-Version: 1.0\nReturn-Path: <ops@example.com>\nSubject: Staging access\nTo: dev@example.com\n",
+				"The staging password is hunter22, see the attached profile.",
+				"<p>The staging password is hunter22</p>",
+				`browser saved login: browser=firefox origin="https://ci.example.com" username=""`,
+			},
+		},
+		{
+			name: "mbox",
+			path: "Takeout/Mail/All mail.mbox",
+			data: `From 1234@xxx Sat Jan 01 00:00:00 +0000 2022
+From: ops@example.com
+Subject: first
+
+token=first
+>From here on
+From 1235@xxx Sun Jan 02 00:00:00 +0000 2022
+Subject: second
+
+token=second
+`,
+			wantHandled: true,
+			wantData: []string{
+				"From: ops@example.com\nSubject: first\n",
+				"token=first\nFrom here on\n",
+				"Subject: second\n",
+				"token=second\n",
+			},
+		},
+		{
+			name:        "outlook message",
+			path:        "Staging access.msg",
+			data:        string(msg),
+			wantHandled: true,
+			wantData: []string{
+				"From: Ops Team <ops@example.com>\nTo: Dev Team\nSubject: Staging access\nRecipient: Dev Team <dev@example.com>\n",
+				"The staging password is hunter22.",
+				"# deploy settings\n" + strings.Repeat("LOG_LEVEL=info\n", 330) + "DEPLOY_TOKEN=example-deploy-token\n",
+			},
+		},
+		{
+			name:        "core dump",
+			path:        "core.1234",
+			data:        core,
+			wantHandled: true,
+			wantData:    []string{"GITHUB_TOKEN=ghp_example\npassword=hunter22\n"},
+		},
+		{
+			name:        "minidump",
+			path:        "crash.dmp",
+			data:        "MDMP\x93\xa7\x00\x00" + utf16le("C:\\Program Files\\Agent\\agent.exe") + "\x00\x00api_key=example1",
+			wantHandled: true,
+			wantData:    []string{"C:\\Program Files\\Agent\\agent.exe\napi_key=example1\n"},
+		},
+		{
+			name: "executable",
+			path: "agent",
+			data: executable,
+		},
+		{
+			name:        "apk",
+			path:        "builds/notes-release.apk",
+			data:        string(apk),
+			wantHandled: true,
+			wantData: []string{
+				"<manifest package=\"com.example.notes\" android:versionCode=\"42\" android:versionName=\"@0x7f020001\">\n" +
+					"  <application android:label=\"Notes\">\n" +
+					"    <meta-data android:name=\"com.example.API_KEY\" android:value=\"example-api-key-1234\">\n" +
+					"    </meta-data>\n" +
+					"  </application>\n" +
+					"</manifest>\n",
+				"https://api.example.com/v1\nsecret=example-dex-secret\n",
+				"<PreferenceScreen android:summary=\"Sync with https://sync.example.com\">\n</PreferenceScreen>\n",
+				"{\"token\": \"example-asset-token\"}\n",
+				"string/app_name = Notes\nstring/version_name = 2.4.1\nstring/debug_note = sync_password=example-resource-pw\n",
+			},
+		},
+		{
+			name:        "ipa",
+			path:        "builds/Notes.ipa",
+			data:        string(ipa),
+			wantHandled: true,
+			wantData: []string{
+				"CFBundleIdentifier = com.example.notes\n" +
+					"CFBundleShortVersionString = 2.4.1\n" +
+					"CFBundleURLTypes[0].CFBundleURLSchemes[0] = notes\n" +
+					"CFBundleVersion = 241\n",
+				"client_secret=example-binary-secret\n",
+				"API_KEY = example-firebase-key\nPROJECT_ID = notes-example\n",
+				"\"greeting\" = \"Hello\";\n",
+			},
+		},
+		{
+			name:        "chrome extension",
+			path:        "extensions/clipper.crx",
+			data:        crx,
+			wantHandled: true,
+			wantData: []string{
+				`{"name": "Notes Clipper", "version": "1.2"}`,
+				`const apiKey = "example-extension-key";`,
+			},
+		},
+		{
+			name:        "firefox extension",
+			path:        "extensions/clipper@example.com.xpi",
+			data:        string(xpi),
+			wantHandled: true,
+			wantData: []string{
+				`{"name": "Notes Clipper", "version": "1.2"}`,
+				`const apiKey = "example-extension-key";`,
+			},
+		},
+		{
+			name: "zip archive",
+			path: "builds/notes-release.zip",
+			data: string(apk),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunksChan := make(chan *sources.Chunk, 10)
+			handled, err := HandleFile(context.Background(), tt.path, bytes.NewReader([]byte(tt.data)), &sources.Chunk{}, chunksChan)
+			if err != nil {
+				t.Fatalf("HandleFile() error = %v", err)
+			}
+			if handled != tt.wantHandled {
+				t.Errorf("HandleFile() handled = %v, want %v", handled, tt.wantHandled)
+			}
+			close(chunksChan)
+			var gotData []string
+			for chunk := range chunksChan {
+				gotData = append(gotData, string(chunk.Data))
+			}
+			if diff := pretty.Compare(gotData, tt.wantData); diff != "" {
+				t.Errorf("HandleFile() data diff: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMemoryDumpChunks(t *testing.T) {
+	var data bytes.Buffer
+	data.WriteString("MDMP")
+	for i := 0; data.Len() < 3*dumpChunkSize; i++ {
+		fmt.Fprintf(&data, "\x00\x00string-%d", i)
+	}
+	data.WriteString("\x00" + strings.Repeat("x", dumpChunkSize+10))
+
+	chunksChan := make(chan *sources.Chunk, 10)
+	if err := (&MemoryDump{}).Handle(context.Background(), "crash.dmp", bytes.NewReader(data.Bytes()), &sources.Chunk{}, chunksChan); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	close(chunksChan)
+
+	var got []byte
+	chunks := 0
+	for chunk := range chunksChan {
+		if len(chunk.Data) > 2*dumpChunkSize {
+			t.Errorf("chunk is %d bytes", len(chunk.Data))
+		}
+		got = append(got, chunk.Data...)
+		chunks++
+	}
+	if chunks < 3 {
+		t.Errorf("got %d chunks, want at least 3", chunks)
+	}
+	if !bytes.Contains(got, []byte("\nstring-1000\n")) {
+		t.Error("strings are missing")
+	}
+	if !bytes.HasSuffix(got, []byte("\n"+strings.Repeat("x", dumpChunkSize)+"\nxxxxxxxxxx\n")) {
+		t.Error("long string wasn't split")
+	}
+}
+
+func TestMobileAppMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		data    string
+		version string
+		want    []*source_metadatapb.MobileApp
+	}{
+		{
+			name:    "apk",
+			path:    "https://artifacts.example.com/notes-release.apk",
+			data:    apkFile,
+			version: "2.4.1 (42)",
+			want: []*source_metadatapb.MobileApp{
+				{Platform: "android", File: "AndroidManifest.xml"},
+				{Platform: "android", File: "classes.dex"},
+				{Platform: "android", File: "res/xml/prefs.xml"},
+				{Platform: "android", File: "assets/config.json"},
+				{Platform: "android", File: "resources.arsc"},
+			},
+		},
+		{
+			name:    "ipa",
+			path:    "Notes.ipa",
+			data:    ipaFile,
+			version: "2.4.1 (241)",
+			want: []*source_metadatapb.MobileApp{
+				{Platform: "ios", File: "Payload/Notes.app/Info.plist"},
+				{Platform: "ios", File: "Payload/Notes.app/Notes"},
+				{Platform: "ios", File: "Payload/Notes.app/GoogleService-Info.plist"},
+				{Platform: "ios", File: "Payload/Notes.app/en.lproj/Localizable.strings"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunksChan := make(chan *sources.Chunk, 10)
+			err := (&MobileApp{}).Handle(context.Background(), tt.path, bytes.NewReader(decodeTestFile(t, tt.data)), &sources.Chunk{}, chunksChan)
+			if err != nil {
+				t.Fatalf("Handle() error = %v", err)
+			}
+			close(chunksChan)
+			var got []*source_metadatapb.MobileApp
+			for chunk := range chunksChan {
+				got = append(got, chunk.SourceMetadata.GetMobileApp())
+			}
+			for _, want := range tt.want {
+				want.Artifact = tt.path
+				want.Package = "com.example.notes"
+				want.Version = tt.version
+			}
+			if diff := pretty.Compare(got, tt.want); diff != "" {
+				t.Errorf("Handle() metadata diff: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestContentType(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+		kind ContentKind
+	}{
+		{name: "text", data: []byte("token = example\n"), want: "text/plain", kind: TextContent},
+		{name: "utf-16", data: []byte(utf16le("token = example")), want: "application/octet-stream", kind: BinaryContent},
+		{name: "zip", data: zipArchive(t, "config.json", "{}"), want: "application/zip", kind: ArchiveContent},
+		{name: "gzip", data: gzipData(t, "token"), want: "application/gzip", kind: ArchiveContent},
+		{name: "pdf", data: []byte("%PDF-1.4\n"), want: "application/pdf", kind: DocumentContent},
+		{name: "png", data: []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), want: "image/png", kind: MediaContent},
+		{name: "woff", data: []byte("wOFF\x00\x01\x00\x00"), want: "application/font-woff", kind: IgnoredContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ContentType(tt.data)
+			if got != tt.want {
+				t.Errorf("ContentType() = %q, want %q", got, tt.want)
+			}
+			if kind := KindOf(got); kind != tt.kind {
+				t.Errorf("KindOf(%q) = %v, want %v", got, kind, tt.kind)
+			}
+		})
+	}
+}
+
+func TestHandleChunk(t *testing.T) {
+	var tarball bytes.Buffer
+	tw := tar.NewWriter(&tarball)
+	for name, data := range map[string][]byte{
+		"app/.env":       []byte("AWS_SECRET=tar\n"),
+		"app/nested.zip": zipArchive(t, "inner/settings.ini", "password=nested\n"),
+	} {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var stream bytes.Buffer
+	zw := zlib.NewWriter(&stream)
+	_, _ = zw.Write([]byte("BT (api_key=from_pdf_stream) Tj ET"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	pdf := "%PDF-1.4\n1 0 obj\n<< /Filter /FlateDecode >>\nstream\n" + stream.String() + "\nendstream\nendobj\n"
+
+	truncated := zipArchive(t, "big.txt", strings.Repeat("x", 100)+"password=truncated_zip")
+	truncated = truncated[:len(truncated)-30]
+
+	tests := []struct {
+		name string
+		data []byte
+		want []string
+	}{
+		{name: "tar", data: tarball.Bytes(), want: []string{"AWS_SECRET=tar", "password=nested"}},
+		{name: "gzip", data: gzipData(t, "token=gzipped\n"), want: []string{"token=gzipped"}},
+		{name: "pdf", data: []byte(pdf), want: []string{"api_key=from_pdf_stream"}},
+		{name: "binary", data: []byte("\x00\x01\x02secret=in_binary\x00\xff"), want: []string{"secret=in_binary"}},
+		{name: "truncated zip", data: truncated, want: []string{"password=truncated_zip"}},
+		{name: "image", data: []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR token=ignored")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := &source_metadatapb.MetaData{
+				Data: &source_metadatapb.MetaData_Filesystem{Filesystem: &source_metadatapb.Filesystem{File: "upload"}},
+			}
+			chunk := &sources.Chunk{Data: tt.data, ContentType: ContentType(tt.data), SourceMetadata: metadata}
+			chunksChan := make(chan *sources.Chunk, 10)
+			if err := HandleChunk(context.Background(), chunk, chunksChan); err != nil {
+				t.Fatalf("HandleChunk() error = %v", err)
+			}
+			close(chunksChan)
+
+			var got []byte
+			chunks := 0
+			for c := range chunksChan {
+				if c.ContentType != "text/plain" || c.SourceMetadata != metadata {
+					t.Errorf("chunk has content type %q and metadata %v", c.ContentType, c.SourceMetadata)
+				}
+				got = append(got, c.Data...)
+				chunks++
+			}
+			for _, want := range tt.want {
+				if !bytes.Contains(got, []byte(want)) {
+					t.Errorf("%q is missing from %q", want, got)
+				}
+			}
+			if len(tt.want) == 0 && chunks != 0 {
+				t.Errorf("got %d chunks, want none", chunks)
+			}
+		})
+	}
+}
+
+func TestHandleChunk_provenance(t *testing.T) {
+	var tarball bytes.Buffer
+	tw := tar.NewWriter(&tarball)
+	inner := []byte("password=in_tar\n")
+	if err := tw.WriteHeader(&tar.Header{Name: "etc/app.env", Mode: 0o600, Size: int64(len(inner)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(inner); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	gw.Name = "settings.ini"
+	_, _ = gw.Write([]byte("password=in_gzip\n"))
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := zipArchive(t, "top.env", "password=in_zip\n", "layers/app.tar", tarball.String(), "settings.ini.gz", gzipped.String())
+	chunk := &sources.Chunk{Data: data, ContentType: ContentType(data)}
+	chunksChan := make(chan *sources.Chunk, 10)
+	if err := HandleChunk(context.Background(), chunk, chunksChan); err != nil {
+		t.Fatalf("HandleChunk() error = %v", err)
+	}
+	close(chunksChan)
+
+	got := map[string]string{}
+	for c := range chunksChan {
+		got[string(c.Data)] = sources.ProvenancePath(c.Provenance)
+	}
+	want := map[string]string{
+		"password=in_zip\n":  "zip:top.env",
+		"password=in_tar\n":  "zip:layers/app.tar > tar:etc/app.env",
+		"password=in_gzip\n": "zip:settings.ini.gz > gzip:settings.ini",
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("provenance diff: (-got +want)\n%s", diff)
+	}
+	if chunk.Provenance != nil {
+		t.Errorf("HandleChunk() changed the chunk's provenance to %v", chunk.Provenance)
+	}
+}
+
+func TestHandleFile_provenance(t *testing.T) {
+	xpi := zipArchive(t, "scripts/background.js", `const apiKey = "example-extension-key";`)
+	crx := "Cr24\x03\x00\x00\x00\x04\x00\x00\x00\x0a\x02\x08\x01" + string(xpi)
+	deployEnv := "# deploy settings\n" + strings.Repeat("LOG_LEVEL=info\n", 330) + "DEPLOY_TOKEN=example-deploy-token\n"
+
+	tests := []struct {
+		name string
+		path string
+		data string
+		want map[string]string
+	}{
+		{
+			name: "outlook message",
+			path: "Staging access.msg",
+			data: string(decodeTestFile(t, msgFile)),
+			want: map[string]string{
+				"The staging password is hunter22.": "zip:mail.zip",
+				deployEnv:                           "zip:mail.zip > email:deploy.env",
+			},
+		},
+		{
+			name: "apk",
+			path: "builds/notes-release.apk",
+			data: string(decodeTestFile(t, apkFile)),
+			want: map[string]string{
+				"https://api.example.com/v1\nsecret=example-dex-secret\n": "zip:mail.zip > apk:classes.dex",
+			},
+		},
+		{
+			name: "chrome extension",
+			path: "extensions/clipper.crx",
+			data: crx,
+			want: map[string]string{
+				`const apiKey = "example-extension-key";`: "zip:mail.zip > crx:scripts/background.js",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunkSkel := &sources.Chunk{Provenance: []sources.ContainerRef{{Type: "zip", Name: "mail.zip"}}}
+			chunksChan := make(chan *sources.Chunk, 10)
+			if _, err := HandleFile(context.Background(), tt.path, bytes.NewReader([]byte(tt.data)), chunkSkel, chunksChan); err != nil {
+				t.Fatalf("HandleFile() error = %v", err)
+			}
+			close(chunksChan)
+
+			got := map[string]string{}
+			for c := range chunksChan {
+				if _, ok := tt.want[string(c.Data)]; ok {
+					got[string(c.Data)] = sources.ProvenancePath(c.Provenance)
+				}
+			}
+			if diff := pretty.Compare(got, tt.want); diff != "" {
+				t.Errorf("provenance diff: (-got +want)\n%s", diff)
+			}
+			if len(chunkSkel.Provenance) != 1 {
+				t.Errorf("HandleFile() changed the skeleton's provenance to %v", chunkSkel.Provenance)
+			}
+		})
+	}
+}
+
+func TestHandleChunk_limits(t *testing.T) {
+	bomb := zipArchive(t, "zeros.txt", strings.Repeat("\x00", 8*1024*1024), "after.env", "password=after_bomb\n")
+	big := zipArchive(t, "first.env", "password=first\n"+strings.Repeat("x", 100), "second.env", "password=second\n")
+	nested := zipArchive(t, "inner.zip", string(zipArchive(t, "deep.env", "password=deep\n")), "top.env", "password=top\n")
+
+	tests := []struct {
+		name      string
+		limits    Limits
+		data      []byte
+		want      []string
+		notWant   []string
+		wantLimit string
+	}{
+		{
+			name:      "ratio",
+			data:      bomb,
+			want:      []string{"password=after_bomb"},
+			wantLimit: LimitRatio,
+		},
+		{
+			name:      "entry size",
+			limits:    Limits{MaxEntrySize: 20},
+			data:      big,
+			want:      []string{"password=first", "password=second"},
+			notWant:   []string{"xxxxxxxxxx"},
+			wantLimit: LimitEntrySize,
+		},
+		{
+			name:      "total size",
+			limits:    Limits{MaxTotalSize: 50},
+			data:      big,
+			want:      []string{"password=first"},
+			notWant:   []string{"password=second"},
+			wantLimit: LimitTotalSize,
+		},
+		{
+			name:      "depth",
+			limits:    Limits{MaxDepth: 1},
+			data:      nested,
+			want:      []string{"password=top"},
+			wantLimit: LimitDepth,
+		},
+		{
+			name: "within the limits",
+			data: nested,
+			want: []string{"password=top", "password=deep"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			logger, err := log.New(log.Config{Format: log.FormatJSON, Output: &logs})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := log.IntoContext(WithLimits(context.Background(), tt.limits), logger)
+
+			metadata := &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Filesystem{Filesystem: &source_metadatapb.Filesystem{File: "upload.zip"}}}
+			chunk := &sources.Chunk{Data: tt.data, ContentType: ContentType(tt.data), SourceMetadata: metadata}
+			chunksChan := make(chan *sources.Chunk, 1000)
+			if err := HandleChunk(ctx, chunk, chunksChan); err != nil {
+				t.Fatalf("HandleChunk() error = %v", err)
+			}
+			close(chunksChan)
+
+			var got []byte
+			for c := range chunksChan {
+				got = append(got, c.Data...)
+				if depth := len(c.Provenance); depth > LimitsFromContext(ctx).MaxDepth {
+					t.Errorf("chunk is %d archives deep, past the depth limit", depth)
+				}
+			}
+			if len(got) > 2*1024*1024 {
+				t.Errorf("got %d bytes, want the bomb cut short", len(got))
+			}
+			for _, want := range tt.want {
+				if !bytes.Contains(got, []byte(want)) {
+					t.Errorf("%q is missing", want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if bytes.Contains(got, []byte(notWant)) {
+					t.Errorf("%q was sent past the limit", notWant)
+				}
+			}
+
+			warnings := strings.Count(logs.String(), "archive not fully unpacked")
+			if tt.wantLimit == "" {
+				if warnings != 0 {
+					t.Errorf("logged %s, want no warnings", logs.String())
+				}
+				return
+			}
+			if warnings != 1 || !strings.Contains(logs.String(), `"limit":"`+tt.wantLimit+`"`) || !strings.Contains(logs.String(), `"file":"upload.zip"`) {
+				t.Errorf("logged %s, want one warning of the %s limit", logs.String(), tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestLimitsFromContext(t *testing.T) {
+	if got := LimitsFromContext(context.Background()); got != DefaultLimits {
+		t.Errorf("LimitsFromContext() = %+v, want the defaults", got)
+	}
+	got := LimitsFromContext(WithLimits(context.Background(), Limits{MaxDepth: 2}))
+	if want := (Limits{MaxDepth: 2, MaxEntrySize: DefaultLimits.MaxEntrySize, MaxTotalSize: DefaultLimits.MaxTotalSize, MaxRatio: DefaultLimits.MaxRatio}); got != want {
+		t.Errorf("LimitsFromContext() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMediaMetadata(t *testing.T) {
+	// An EXIF block, in little endian TIFF layout, with an Artist tag and an
+	// Exif directory holding a user comment.
+	comment := "ASCII\x00\x00\x00aws_key=from_exif"
+	exif := []byte("II*\x00\x08\x00\x00\x00")
+	exif = append(exif, le16(2)...)
+	exif = append(exif, exifEntry(0x013B, exifASCII, 4, 0)...)
+	copy(exif[len(exif)-4:], "bob\x00")
+	exif = append(exif, exifEntry(exifIFDTag, 4, 1, 38)...)
+	exif = append(exif, 0, 0, 0, 0)
+	exif = append(exif, le16(1)...)
+	exif = append(exif, exifEntry(userCommentTag, exifUndefined, uint32(len(comment)), 56)...)
+	exif = append(exif, 0, 0, 0, 0)
+	exif = append(exif, comment...)
+	jpeg := append([]byte{0xFF, 0xD8, 0xFF, 0xE1}, be16(len(exif)+8)...)
+	jpeg = append(append(jpeg, "Exif\x00\x00"...), exif...)
+	jpeg = append(append(jpeg, 0xFF, 0xFE), be16(len("token=from_comment")+2)...)
+	jpeg = append(append(jpeg, "token=from_comment"...), 0xFF, 0xDA, 0x00, 0x02)
+
+	var ztxt bytes.Buffer
+	zw := zlib.NewWriter(&ztxt)
+	_, _ = zw.Write([]byte("password=from_ztxt"))
+	_ = zw.Close()
+	png := []byte("\x89PNG\r\n\x1a\n")
+	png = append(png, pngChunk("tEXt", "Software\x00deploy-tool token=from_text")...)
+	png = append(png, pngChunk("zTXt", "Comment\x00\x00"+ztxt.String())...)
+	png = append(png, pngChunk("iTXt", "XML:com.adobe.xmp\x00\x00\x00\x00\x00"+
+		`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF><rdf:Description xmp:CreatorTool="https://internal.example.com/?key=from_xmp"/></rdf:RDF></x:xmpmeta>`)...)
+
+	frame := "\x03secret=from_id3"
+	id3 := append([]byte("ID3\x03\x00\x00\x00\x00\x00"), byte(10+len(frame)))
+	id3 = append(append(append(id3, "TIT2"...), be32(len(frame))...), 0, 0)
+	id3 = append(id3, frame...)
+
+	data := mp4Box("data", "\x00\x00\x00\x01\x00\x00\x00\x00api_key=from_mp4")
+	mp4 := "\x00\x00\x00\x14ftypisom\x00\x00\x00\x00isom" +
+		mp4Box("moov", mp4Box("udta", mp4Box("meta", "\x00\x00\x00\x00"+mp4Box("ilst", mp4Box("\xa9cmt", data)))))
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{name: "jpeg", data: jpeg, want: "Artist: bob\nUserComment: aws_key=from_exif\nComment: token=from_comment\n"},
+		{name: "png", data: png, want: "Software: deploy-tool token=from_text\nComment: password=from_ztxt\nCreatorTool: https://internal.example.com/?key=from_xmp\n"},
+		{name: "mp3", data: id3, want: "TIT2: secret=from_id3\n"},
+		{name: "mp4", data: []byte(mp4), want: "©cmt: api_key=from_mp4\n"},
+		{name: "truncated jpeg", data: jpeg[:len(jpeg)-10], want: "Artist: bob\nUserComment: aws_key=from_exif\nComment: token=from_c\n"},
+		{name: "jpeg segment length under two", data: []byte("\xFF\xD8\xFF\xFE\x00\x01token=after_bad_length"), want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contentType := ContentType(tt.data)
+			if KindOf(contentType) != MediaContent {
+				t.Fatalf("content type %q isn't media", contentType)
+			}
+			if got := string(mediaMetadata(tt.data, contentType)); got != tt.want {
+				t.Errorf("mediaMetadata() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func exifEntry(tag, typ uint16, count, value uint32) []byte {
+	entry := make([]byte, 12)
+	binary.LittleEndian.PutUint16(entry, tag)
+	binary.LittleEndian.PutUint16(entry[2:], typ)
+	binary.LittleEndian.PutUint32(entry[4:], count)
+	binary.LittleEndian.PutUint32(entry[8:], value)
+	return entry
+}
+
+func le16(n int) []byte {
+	return []byte{byte(n), byte(n >> 8)}
+}
+
+func be16(n int) []byte {
+	return []byte{byte(n >> 8), byte(n)}
+}
+
+func be32(n int) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(n))
+	return b
+}
+
+func pngChunk(typ, data string) []byte {
+	chunk := append(be32(len(data)), typ...)
+	return append(append(chunk, data...), 0, 0, 0, 0)
+}
+
+func mp4Box(typ, data string) string {
+	return string(be32(len(data)+8)) + typ + data
+}
+
+func TestOCR(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	// The fake OCR command checks it's given the image.
+	ocr := &OCR{Command: []string{"sh", "-c", `head -c 4 | grep -q PNG && echo aws_secret=from_screenshot`}}
+	ctx := WithOCR(context.Background(), ocr)
+
+	chunksChan := make(chan *sources.Chunk, 10)
+	handled, err := HandleFile(context.Background(), "screenshot.png", bytes.NewReader(png), &sources.Chunk{}, chunksChan)
+	if err != nil || handled {
+		t.Fatalf("HandleFile() without OCR = %v, %v, want false, nil", handled, err)
+	}
+
+	handled, err = HandleFile(ctx, "screenshot.png", bytes.NewReader(png), &sources.Chunk{}, chunksChan)
+	if err != nil || !handled {
+		t.Fatalf("HandleFile() = %v, %v, want true, nil", handled, err)
+	}
+	if err := HandleChunk(ctx, &sources.Chunk{Data: png, ContentType: ContentType(png)}, chunksChan); err != nil {
+		t.Fatalf("HandleChunk() error = %v", err)
+	}
+	close(chunksChan)
+	var got []string
+	for c := range chunksChan {
+		got = append(got, string(c.Data))
+	}
+	if diff := pretty.Compare(got, []string{"aws_secret=from_screenshot\n", "aws_secret=from_screenshot\n"}); diff != "" {
+		t.Errorf("OCR text diff: (-got +want)\n%s", diff)
+	}
+
+	failing := &OCR{Command: []string{"sh", "-c", "echo unsupported image >&2; exit 1"}}
+	if _, err := failing.Text(context.Background(), png); err == nil || !strings.Contains(err.Error(), "unsupported image") {
+		t.Errorf("Text() error = %v, want the command's stderr", err)
+	}
+}
+
+// gzipData returns data gzipped.
+func gzipData(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// zipArchive returns a zip archive of files, given as pairs of names and
+// contents.
+func zipArchive(t *testing.T, files ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for i := 0; i+1 < len(files); i += 2 {
+		w, err := zw.Create(files[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(files[i+1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// decodeTestFile decodes a gzipped and base64 encoded test file.
+func decodeTestFile(t *testing.T, data string) []byte {
+	t.Helper()
+	zr, err := gzip.NewReader(base64.NewDecoder(base64.StdEncoding, strings.NewReader(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+// utf16le encodes an ASCII string as UTF-16LE.
+func utf16le(s string) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		b.WriteByte(c)
+		b.WriteByte(0)
+	}
+	return b.String()
+}
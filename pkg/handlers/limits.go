@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// Limits protect scans from decompression bombs, archives that unpack to far
+// more data than they hold, or that hold archives nested without end. When a
+// limit is reached, what was unpacked until then is scanned, and a warning
+// naming the limit and the file is logged instead of the scan failing.
+type Limits struct {
+	// MaxDepth is how many archives deep files are unpacked. Archives
+	// deeper than that have their strings scanned instead.
+	MaxDepth int
+	// MaxEntrySize is the most bytes read of each file of an archive.
+	MaxEntrySize int64
+	// MaxTotalSize is the most bytes unpacked from a file or chunk, counting
+	// the files of all of the archives in it.
+	MaxTotalSize int64
+	// MaxRatio is the most bytes a file of an archive unpacks to for each
+	// compressed byte, once it's unpacked to more than a megabyte.
+	MaxRatio float64
+}
+
+// DefaultLimits are the limits of handlers whose context has none. Text
+// rarely compresses more than tenfold, while zip bombs compress a
+// thousandfold or more.
+var DefaultLimits = Limits{
+	MaxDepth:     5,
+	MaxEntrySize: 256 * 1024 * 1024,
+	MaxTotalSize: 1024 * 1024 * 1024,
+	MaxRatio:     100,
+}
+
+// minRatioSize is how much of a file is unpacked before its compression
+// ratio is checked, since small files of repeated text compress well.
+const minRatioSize = 1024 * 1024
+
+// The limits a LimitError can name.
+const (
+	LimitDepth     = "depth"
+	LimitEntrySize = "entry size"
+	LimitTotalSize = "total size"
+	LimitRatio     = "compression ratio"
+)
+
+// LimitError is returned when unpacking an archive reaches one of the
+// Limits.
+type LimitError struct {
+	// Limit is the limit reached, such as LimitRatio.
+	Limit string
+}
+
+func (e *LimitError) Error() string {
+	return "unpacking stopped at the " + e.Limit + " limit"
+}
+
+type limitsKey struct{}
+
+// WithLimits returns a copy of ctx in which archives are unpacked within
+// limits. Limits that are zero are the default ones.
+func WithLimits(ctx context.Context, limits Limits) context.Context {
+	return context.WithValue(ctx, limitsKey{}, limits)
+}
+
+// LimitsFromContext returns the limits stored in ctx, with the default ones
+// in place of those that aren't set.
+func LimitsFromContext(ctx context.Context) Limits {
+	limits, _ := ctx.Value(limitsKey{}).(Limits)
+	if limits.MaxDepth <= 0 {
+		limits.MaxDepth = DefaultLimits.MaxDepth
+	}
+	if limits.MaxEntrySize <= 0 {
+		limits.MaxEntrySize = DefaultLimits.MaxEntrySize
+	}
+	if limits.MaxTotalSize <= 0 {
+		limits.MaxTotalSize = DefaultLimits.MaxTotalSize
+	}
+	if limits.MaxRatio <= 0 {
+		limits.MaxRatio = DefaultLimits.MaxRatio
+	}
+	return limits
+}
+
+// unpackBudget counts what's unpacked from a file or chunk against the
+// limits in its context.
+type unpackBudget struct {
+	ctx    context.Context
+	limits Limits
+	// total is the number of bytes unpacked.
+	total int64
+	// exhausted records that the total size limit was reached and logged,
+	// so that it's only logged once.
+	exhausted bool
+}
+
+func newUnpackBudget(ctx context.Context) *unpackBudget {
+	return &unpackBudget{ctx: ctx, limits: LimitsFromContext(ctx)}
+}
+
+// read reads a file being unpacked from an archive until its end or a limit.
+// compressed returns how many bytes of the archive have been read to unpack
+// it, and is nil if the file isn't compressed. What's read before a limit is
+// returned along with a *LimitError.
+func (b *unpackBudget) read(r io.Reader, compressed func() int64) ([]byte, error) {
+	return io.ReadAll(&limitedReader{r: r, budget: b, compressed: compressed})
+}
+
+// readZipFile reads a file of a zip archive that's at path. What's read
+// before a limit is returned, and the limit is logged.
+func (b *unpackBudget) readZipFile(f *zip.File, path string) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not open "+f.Name, 0)
+	}
+	defer rc.Close()
+	data, err := b.read(rc, func() int64 { return int64(f.CompressedSize64) })
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		b.warn(limitErr, path+"/"+f.Name, nil)
+		return data, nil
+	}
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not read "+f.Name, 0)
+	}
+	return data, nil
+}
+
+// warn logs that unpacking the file at path, in the containers in chain,
+// stopped at a limit.
+func (b *unpackBudget) warn(err *LimitError, path string, chain []sources.ContainerRef) {
+	if err.Limit == LimitTotalSize {
+		if b.exhausted {
+			return
+		}
+		b.exhausted = true
+	}
+	keysAndValues := []interface{}{"limit", err.Limit, "file", path}
+	if len(chain) > 0 {
+		keysAndValues = append(keysAndValues, "provenance", sources.ProvenancePath(chain))
+	}
+	log.FromContext(b.ctx).Info("archive not fully unpacked", keysAndValues...)
+}
+
+// limitedReader reads a file being unpacked, returning a *LimitError once
+// it reaches a limit.
+type limitedReader struct {
+	r          io.Reader
+	budget     *unpackBudget
+	compressed func() int64
+	// read is the number of bytes read of the file.
+	read int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	limits := l.budget.limits
+	max := limits.MaxEntrySize - l.read
+	limit := LimitEntrySize
+	if left := limits.MaxTotalSize - l.budget.total; left < max {
+		max, limit = left, LimitTotalSize
+	}
+	if max <= 0 {
+		// A file that ends right at the limit is read in full.
+		var probe [1]byte
+		if n, err := io.ReadFull(l.r, probe[:]); n == 0 && err != nil {
+			return 0, err
+		}
+		return 0, &LimitError{Limit: limit}
+	}
+	if int64(len(p)) > max {
+		p = p[:max]
+	}
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	l.budget.total += int64(n)
+	if l.compressed != nil && l.read > minRatioSize {
+		if c := l.compressed(); c > 0 && float64(l.read) > limits.MaxRatio*float64(c) {
+			return n, &LimitError{Limit: LimitRatio}
+		}
+	}
+	return n, err
+}
+
+// countingReader counts the bytes read from r, such as those of a
+// compressed stream, to tell its compression ratio.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
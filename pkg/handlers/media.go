@@ -0,0 +1,494 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/xml"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	// maxMetadataSize limits how much of a compressed metadata value is
+	// inflated.
+	maxMetadataSize = 1024 * 1024
+	// maxBoxDepth limits how deeply the boxes of MP4s are read.
+	maxBoxDepth = 8
+)
+
+// exifTags are the names of the EXIF tags whose values are text.
+var exifTags = map[uint16]string{
+	0x010D: "DocumentName",
+	0x010E: "ImageDescription",
+	0x010F: "Make",
+	0x0110: "Model",
+	0x0131: "Software",
+	0x013B: "Artist",
+	0x013C: "HostComputer",
+	0x8298: "Copyright",
+	0x9286: "UserComment",
+	0x9C9B: "XPTitle",
+	0x9C9C: "XPComment",
+	0x9C9D: "XPAuthor",
+	0x9C9E: "XPKeywords",
+	0x9C9F: "XPSubject",
+}
+
+const (
+	exifIFDTag     = 0x8769
+	userCommentTag = 0x9286
+
+	exifByte      = 1
+	exifASCII     = 2
+	exifUndefined = 7
+)
+
+// mediaMetadata returns the textual metadata embedded in an image, audio or
+// video file, one value on each line: the EXIF tags and comments of JPEGs and
+// TIFFs, the text chunks of PNGs, the ID3 frames of MP3s, the iTunes-style tags
+// of MP4s, and the XMP packets any of them can hold. Metadata that's cut
+// short, as at the end of a chunk, is read as far as it goes.
+func mediaMetadata(data []byte, contentType string) []byte {
+	m := &metadata{}
+	switch contentType {
+	case "image/jpeg":
+		m.jpeg(data)
+	case "image/tiff":
+		m.exif(data)
+	case "image/png":
+		m.png(data)
+	case "audio/mpeg":
+		m.id3(data)
+	case "video/mp4", "video/quicktime", "video/x-m4v", "video/3gpp", "audio/m4a", "image/heif":
+		m.boxes(data, 0)
+	}
+	m.xmp(data)
+	return m.out.Bytes()
+}
+
+// metadata collects the values of metadata as lines of text.
+type metadata struct {
+	out bytes.Buffer
+}
+
+// add adds a value, named by its tag or field if name is set.
+func (m *metadata) add(name, value string) {
+	value = strings.TrimSpace(strings.ToValidUTF8(strings.Trim(value, "\x00\ufeff"), ""))
+	if value == "" {
+		return
+	}
+	if name != "" {
+		m.out.WriteString(name)
+		m.out.WriteString(": ")
+	}
+	m.out.WriteString(value)
+	m.out.WriteByte('\n')
+}
+
+// jpeg adds the EXIF tags and comments of a JPEG, found in the segments that
+// precede its image data.
+func (m *metadata) jpeg(data []byte) {
+	if len(data) < 2 {
+		return
+	}
+	data = data[2:]
+	for len(data) >= 4 && data[0] == 0xFF {
+		marker := data[1]
+		// The image data follows the start of scan segment.
+		if marker == 0xDA || marker == 0xD9 {
+			return
+		}
+		end := 2 + int(binary.BigEndian.Uint16(data[2:]))
+		// A segment's length counts itself, so it can't be under two.
+		if end < 4 {
+			return
+		}
+		if end > len(data) {
+			end = len(data)
+		}
+		segment := data[4:end]
+		switch {
+		case marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")):
+			m.exif(segment[6:])
+		case marker == 0xFE:
+			m.add("Comment", string(segment))
+		}
+		data = data[end:]
+	}
+}
+
+// exif adds the text tags of EXIF data, which is laid out as a TIFF file.
+// https://www.cipa.jp/std/documents/download_e.html?DC-008-Translation-2023-E
+func (m *metadata) exif(data []byte) {
+	if len(data) < 8 {
+		return
+	}
+	var order binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return
+	}
+
+	visited := make(map[uint32]bool)
+	var ifd func(offset uint32)
+	ifd = func(offset uint32) {
+		for offset != 0 && !visited[offset] && uint64(offset)+2 <= uint64(len(data)) {
+			visited[offset] = true
+			n := int(order.Uint16(data[offset:]))
+			entries := data[offset+2:]
+			for i := 0; i < n && (i+1)*12 <= len(entries); i++ {
+				entry := entries[i*12 : (i+1)*12]
+				tag := order.Uint16(entry)
+				typ := order.Uint16(entry[2:])
+				count := uint64(order.Uint32(entry[4:]))
+				if tag == exifIFDTag {
+					ifd(order.Uint32(entry[8:]))
+					continue
+				}
+				name, ok := exifTags[tag]
+				if !ok && typ != exifASCII {
+					continue
+				}
+				// Values of up to four bytes are held by the entry itself.
+				value := entry[8:]
+				if count > 4 {
+					start := uint64(order.Uint32(entry[8:]))
+					if start >= uint64(len(data)) {
+						continue
+					}
+					value = data[start:]
+				}
+				if count < uint64(len(value)) {
+					value = value[:count]
+				}
+
+				switch {
+				case typ == exifASCII:
+					m.add(name, string(value))
+				case tag == userCommentTag && typ == exifUndefined && len(value) >= 8:
+					// Comments start with the name of their character set.
+					if bytes.HasPrefix(value, []byte("UNICODE")) {
+						m.add(name, utf16String(value[8:], order))
+					} else {
+						m.add(name, string(value[8:]))
+					}
+				case tag >= 0x9C9B && tag <= 0x9C9F && typ == exifByte:
+					m.add(name, utf16String(value, binary.LittleEndian))
+				}
+			}
+			// The offset of the next directory follows the entries.
+			if n*12+4 > len(entries) {
+				return
+			}
+			offset = order.Uint32(entries[n*12:])
+		}
+	}
+	ifd(order.Uint32(data[4:]))
+}
+
+// png adds the text chunks and EXIF tags of a PNG. The XMP packets of its
+// uncompressed international text chunks are left to xmp.
+// https://www.w3.org/TR/png/#11textinfo
+func (m *metadata) png(data []byte) {
+	if len(data) < 8 {
+		return
+	}
+	data = data[8:]
+	for len(data) >= 8 {
+		length := uint64(binary.BigEndian.Uint32(data))
+		typ := string(data[4:8])
+		chunk := data[8:]
+		if length < uint64(len(chunk)) {
+			chunk = chunk[:length]
+		}
+
+		switch typ {
+		case "tEXt":
+			if keyword, text, ok := bytes.Cut(chunk, []byte{0}); ok {
+				m.add(latin1(keyword), latin1(text))
+			}
+		case "zTXt":
+			if keyword, text, ok := bytes.Cut(chunk, []byte{0}); ok && len(text) > 0 {
+				m.add(latin1(keyword), latin1(inflate(text[1:])))
+			}
+		case "iTXt":
+			keyword, rest, ok := bytes.Cut(chunk, []byte{0})
+			if !ok || len(rest) < 2 {
+				break
+			}
+			compressed := rest[0] == 1
+			// The language tag and translated keyword precede the text.
+			fields := bytes.SplitN(rest[2:], []byte{0}, 3)
+			if len(fields) < 3 {
+				break
+			}
+			text := fields[2]
+			if compressed {
+				text = inflate(text)
+			} else if string(keyword) == "XML:com.adobe.xmp" {
+				break
+			}
+			m.add(string(keyword), string(text))
+		case "eXIf":
+			m.exif(chunk)
+		case "IEND":
+			return
+		}
+		if length+12 > uint64(len(data)) {
+			return
+		}
+		data = data[length+12:]
+	}
+}
+
+// id3 adds the text, comment and URL frames of the ID3v2 tag that starts an
+// MP3 and the ID3v1 tag that ends it.
+// https://id3.org/id3v2.4.0-structure
+func (m *metadata) id3(data []byte) {
+	if len(data) >= 128 && bytes.HasPrefix(data[len(data)-128:], []byte("TAG")) {
+		v1 := data[len(data)-128:]
+		m.add("Title", latin1(v1[3:33]))
+		m.add("Artist", latin1(v1[33:63]))
+		m.add("Album", latin1(v1[63:93]))
+		m.add("Comment", latin1(v1[97:127]))
+	}
+	if len(data) < 10 || !bytes.HasPrefix(data, []byte("ID3")) {
+		return
+	}
+	version := data[3]
+	flags := data[5]
+	end := 10 + syncsafe(data[6:10])
+	if end > len(data) {
+		end = len(data)
+	}
+	frames := data[10:end]
+	if flags&0x40 != 0 && len(frames) >= 4 {
+		// The size of an extended header excludes itself before version 4.
+		size := int(binary.BigEndian.Uint32(frames)) + 4
+		if version >= 4 {
+			size = syncsafe(frames[:4])
+		}
+		if size > len(frames) {
+			return
+		}
+		frames = frames[size:]
+	}
+
+	idLen, headerLen := 4, 10
+	if version == 2 {
+		idLen, headerLen = 3, 6
+	}
+	for len(frames) >= headerLen && frames[0] != 0 {
+		id := string(frames[:idLen])
+		var size int
+		var frameFlags uint16
+		switch version {
+		case 2:
+			size = int(frames[3])<<16 | int(frames[4])<<8 | int(frames[5])
+		case 3:
+			size = int(binary.BigEndian.Uint32(frames[4:]))
+			frameFlags = binary.BigEndian.Uint16(frames[8:])
+		default:
+			size = syncsafe(frames[4:8])
+			frameFlags = binary.BigEndian.Uint16(frames[8:])
+		}
+		frame := frames[headerLen:]
+		if size < len(frame) {
+			frame = frame[:size]
+		}
+
+		skip := false
+		if version == 3 {
+			skip = frameFlags&0x00C0 != 0
+		} else if version >= 4 {
+			skip = frameFlags&0x000C != 0
+			// The frame's size before it was unsynchronised precedes it.
+			if frameFlags&0x0001 != 0 && len(frame) >= 4 {
+				frame = frame[4:]
+			}
+		}
+		if !skip && len(frame) > 0 {
+			switch {
+			case id == "COMM" || id == "USLT" || id == "COM" || id == "ULT":
+				// The language of comments and lyrics follows their encoding.
+				if len(frame) > 4 {
+					m.id3Text(id, frame[0], frame[4:])
+				}
+			case id[0] == 'T' || id == "WXXX" || id == "WXX":
+				m.id3Text(id, frame[0], frame[1:])
+			case id[0] == 'W':
+				m.add(id, latin1(frame))
+			}
+		}
+		if headerLen+size > len(frames) {
+			return
+		}
+		frames = frames[headerLen+size:]
+	}
+}
+
+// id3Text adds the strings of an ID3 frame, which are in the given encoding
+// and separated by NUL characters.
+func (m *metadata) id3Text(id string, encoding byte, data []byte) {
+	var text string
+	switch encoding {
+	case 0:
+		text = latin1(data)
+	case 1:
+		order := binary.ByteOrder(binary.LittleEndian)
+		if bytes.HasPrefix(data, []byte{0xFE, 0xFF}) {
+			order = binary.BigEndian
+		}
+		text = utf16String(data, order)
+	case 2:
+		text = utf16String(data, binary.BigEndian)
+	default:
+		text = string(data)
+	}
+	for _, s := range strings.Split(text, "\x00") {
+		m.add(id, s)
+	}
+}
+
+// boxes adds the iTunes-style tags of an MP4 or QuickTime file, which are
+// boxes under moov/udta/meta/ilst, named after the tag, that hold data boxes.
+// https://developer.apple.com/documentation/quicktime-file-format/metadata_item_list_atom
+func (m *metadata) boxes(data []byte, depth int) {
+	if depth >= maxBoxDepth {
+		return
+	}
+	eachBox(data, func(typ string, box []byte) {
+		switch typ {
+		case "moov", "udta":
+			m.boxes(box, depth+1)
+		case "meta":
+			// ISO meta boxes have a version and flags before their boxes,
+			// unlike QuickTime's.
+			if len(box) >= 8 && binary.BigEndian.Uint32(box) == 0 {
+				box = box[4:]
+			}
+			m.boxes(box, depth+1)
+		case "ilst":
+			eachBox(box, func(tag string, item []byte) {
+				eachBox(item, func(typ string, data []byte) {
+					// Type 1 data is UTF-8 text, which follows its locale.
+					if typ == "data" && len(data) >= 8 && binary.BigEndian.Uint32(data) == 1 {
+						m.add(tag, string(data[8:]))
+					}
+				})
+			})
+		}
+	})
+}
+
+// eachBox calls fn with the type and contents of each box in data. The last
+// box may be cut short.
+func eachBox(data []byte, fn func(typ string, box []byte)) {
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data))
+		headerLen := uint64(8)
+		switch size {
+		case 0:
+			// The box extends to the end of the file.
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return
+			}
+			size = binary.BigEndian.Uint64(data[8:])
+			headerLen = 16
+		}
+		if size < headerLen {
+			return
+		}
+		end := size
+		if end > uint64(len(data)) {
+			end = uint64(len(data))
+		}
+		fn(latin1(data[4:8]), data[headerLen:end])
+		data = data[end:]
+	}
+}
+
+// xmp adds the values of the XMP packets in data, which are XML.
+// https://www.adobe.com/devnet/xmp.html
+func (m *metadata) xmp(data []byte) {
+	start := []byte("<x:xmpmeta")
+	end := []byte("</x:xmpmeta>")
+	for {
+		i := bytes.Index(data, start)
+		if i < 0 {
+			return
+		}
+		data = data[i:]
+		packet := data
+		if j := bytes.Index(data, end); j >= 0 {
+			packet = data[:j+len(end)]
+		}
+		data = data[len(packet):]
+
+		// What's read of a packet before it's cut short is kept.
+		d := xml.NewDecoder(bytes.NewReader(packet))
+		d.Strict = false
+		var element string
+		for {
+			token, err := d.Token()
+			if err != nil {
+				break
+			}
+			switch t := token.(type) {
+			case xml.StartElement:
+				element = t.Name.Local
+				for _, attr := range t.Attr {
+					if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" || attr.Name.Local == "about" {
+						continue
+					}
+					m.add(attr.Name.Local, attr.Value)
+				}
+			case xml.CharData:
+				m.add(element, string(t))
+			}
+		}
+	}
+}
+
+// syncsafe returns the value of a 4 byte ID3 integer, which has 7 bits in
+// each byte.
+func syncsafe(b []byte) int {
+	return int(b[0]&0x7F)<<21 | int(b[1]&0x7F)<<14 | int(b[2]&0x7F)<<7 | int(b[3]&0x7F)
+}
+
+// latin1 returns ISO 8859-1 text as a string.
+func latin1(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// utf16String returns UTF-16 text, which may start with a byte order mark,
+// as a string.
+func utf16String(b []byte, order binary.ByteOrder) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, order.Uint16(b[i:]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// inflate returns zlib compressed data inflated, as far as it can be.
+func inflate(data []byte) []byte {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	inflated, _ := io.ReadAll(io.LimitReader(zr, maxMetadataSize))
+	return inflated
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	// dumpChunkSize is the size of the chunks of strings a memory dump is
+	// sent as. Strings longer than it are split.
+	dumpChunkSize = 10 * 1024
+	// minStringLen is the length of the shortest string kept. Shorter runs of
+	// printable bytes are mostly noise in binary data.
+	minStringLen = 8
+)
+
+// MemoryDump extracts the strings of process memory dumps: ELF core files,
+// Windows minidumps, V8 heap snapshots and Java heap dumps. Credentials held
+// by a process end up in its dumps, but the dumps are too large to scan
+// whole, so the handler streams through them and sends chunks of their
+// printable ASCII and UTF-16LE strings, one per line.
+type MemoryDump struct{}
+
+// Ensure the MemoryDump handler satisfies the interface at compile time.
+var _ Handler = (*MemoryDump)(nil)
+
+func (h *MemoryDump) Accepts(path string, header []byte) bool {
+	switch {
+	case isELFCore(header):
+		return true
+	case bytes.HasPrefix(header, []byte("MDMP")):
+		return true
+	case bytes.HasPrefix(header, []byte("JAVA PROFILE 1.0.")):
+		return true
+	default:
+		header = bytes.TrimLeft(header, " \t\r\n")
+		return bytes.HasPrefix(header, []byte(`{"snapshot":{"meta":`))
+	}
+}
+
+// isELFCore reports whether header is that of an ELF core file.
+func isELFCore(header []byte) bool {
+	if len(header) < 18 || !bytes.HasPrefix(header, []byte("\x7fELF")) {
+		return false
+	}
+	const etCore = 4
+	switch header[5] {
+	case 1:
+		return binary.LittleEndian.Uint16(header[16:]) == etCore
+	case 2:
+		return binary.BigEndian.Uint16(header[16:]) == etCore
+	default:
+		return false
+	}
+}
+
+func (h *MemoryDump) Handle(ctx context.Context, path string, file io.ReaderAt, chunkSkel *sources.Chunk, chunksChan chan *sources.Chunk) error {
+	send := func(data []byte) error {
+		chunk := *chunkSkel
+		chunk.Data = data
+		chunk.SourceMetadata = &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Filesystem{
+				Filesystem: &source_metadatapb.Filesystem{
+					File: sanitizer.UTF8(path),
+				},
+			},
+		}
+		select {
+		case chunksChan <- &chunk:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	extractor := &stringExtractor{}
+	reader := bufio.NewReaderSize(io.NewSectionReader(file, 0, math.MaxInt64), 64*1024)
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := reader.Read(buf)
+		for _, b := range buf[:n] {
+			extractor.add(b)
+			if extractor.out.Len() >= dumpChunkSize {
+				if err := send(extractor.take()); err != nil {
+					return err
+				}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return errors.WrapPrefix(err, "could not read memory dump", 0)
+		}
+	}
+
+	extractor.flush()
+	if extractor.out.Len() > 0 {
+		return send(extractor.take())
+	}
+	return nil
+}
+
+// stringExtractor collects the strings of a stream of bytes, like strings(1)
+// does with both its ASCII and 16-bit little endian encodings.
+type stringExtractor struct {
+	// offset is the offset of the next byte.
+	offset int64
+	// ascii is the current run of printable bytes.
+	ascii []byte
+	// wide are the current runs of UTF-16LE encoded printable characters,
+	// by whether they start at an even or odd offset, and low are the last
+	// low bytes seen at those alignments.
+	wide [2][]byte
+	low  [2]byte
+	// out holds the strings found, one per line.
+	out bytes.Buffer
+}
+
+// add processes the next byte of the stream.
+func (e *stringExtractor) add(b byte) {
+	if printable(b) {
+		e.ascii = e.appendRun(e.ascii, b)
+	} else {
+		e.ascii = e.end(e.ascii)
+	}
+
+	// At a given alignment, a byte is either the low or high byte of a
+	// character. The other alignment sees it the other way around.
+	for align := range e.wide {
+		if e.offset%2 == int64(align) {
+			e.low[align] = b
+			continue
+		}
+		if b == 0 && printable(e.low[align]) {
+			e.wide[align] = e.appendRun(e.wide[align], e.low[align])
+		} else {
+			e.wide[align] = e.end(e.wide[align])
+		}
+	}
+	e.offset++
+}
+
+// appendRun appends b to run, ending the run first if it's as long as a
+// chunk.
+func (e *stringExtractor) appendRun(run []byte, b byte) []byte {
+	if len(run) >= dumpChunkSize {
+		run = e.end(run)
+	}
+	return append(run, b)
+}
+
+// end writes run to out if it's long enough, and returns it emptied.
+func (e *stringExtractor) end(run []byte) []byte {
+	if len(run) >= minStringLen {
+		e.out.Write(run)
+		e.out.WriteByte('\n')
+	}
+	return run[:0]
+}
+
+// flush ends the current runs, at the end of the stream.
+func (e *stringExtractor) flush() {
+	e.ascii = e.end(e.ascii)
+	for align := range e.wide {
+		e.wide[align] = e.end(e.wide[align])
+	}
+}
+
+// take returns the strings found since the last call.
+func (e *stringExtractor) take() []byte {
+	data := make([]byte, e.out.Len())
+	copy(data, e.out.Bytes())
+	e.out.Reset()
+	return data
+}
+
+// printable reports whether b is a printable ASCII character or a tab.
+func printable(b byte) bool {
+	return b == '\t' || (b >= 0x20 && b < 0x7f)
+}
@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/apk"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/plist"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+var (
+	zipMagic = []byte("PK\x03\x04")
+
+	// infoPlistPat matches the Info.plist of the app in an IPA. Frameworks and
+	// extensions bundled with the app have their own, deeper down.
+	infoPlistPat = regexp.MustCompile(`^Payload/[^/]+\.app/Info\.plist$`)
+
+	// mediaExts are the extensions of the media files of bundles, which
+	// aren't scanned.
+	mediaExts = map[string]bool{
+		".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true,
+		".mp3": true, ".mp4": true, ".ogg": true, ".wav": true, ".m4a": true,
+		".ttf": true, ".otf": true, ".woff": true, ".woff2": true, ".car": true,
+	}
+)
+
+// MobileApp unpacks Android APKs and iOS IPAs. Compiled XML files, such as
+// AndroidManifest.xml, are decoded to text, the string resources of
+// resources.arsc and the values of property lists are sent one per line, and
+// the strings of binaries such as classes.dex and native libraries are
+// extracted. Chunks are labeled with the app's package name and version, from
+// its manifest or Info.plist, and the bundle's files are added to their
+// provenance.
+type MobileApp struct{}
+
+// Ensure the MobileApp handler satisfies the interface at compile time.
+var _ Handler = (*MobileApp)(nil)
+
+func (h *MobileApp) Accepts(path string, header []byte) bool {
+	switch fileExt(path) {
+	case ".apk", ".ipa":
+		return bytes.HasPrefix(header, zipMagic)
+	default:
+		return false
+	}
+}
+
+// Handle sends the contents of the app bundle file. Whether it's an APK or an
+// IPA is told by its contents, not its path, so artifacts can be named by URL.
+func (h *MobileApp) Handle(ctx context.Context, path string, file io.ReaderAt, chunkSkel *sources.Chunk, chunksChan chan *sources.Chunk) error {
+	size, err := readerSize(file)
+	if err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(file, size)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not open app bundle", 0)
+	}
+
+	w := &appWalker{ctx: ctx, chunkSkel: chunkSkel, chunksChan: chunksChan, budget: newUnpackBudget(ctx), artifact: path}
+	for _, f := range zr.File {
+		if f.Name == "AndroidManifest.xml" {
+			return w.apk(zr)
+		}
+		if strings.HasPrefix(f.Name, "Payload/") {
+			return w.ipa(zr)
+		}
+	}
+	return errors.New("app bundle has neither an AndroidManifest.xml nor a Payload directory")
+}
+
+// appWalker sends the chunks of an app bundle.
+type appWalker struct {
+	ctx        context.Context
+	chunkSkel  *sources.Chunk
+	chunksChan chan *sources.Chunk
+	budget     *unpackBudget
+	artifact   string
+	// platform, pkg and version identify the app.
+	platform string
+	pkg      string
+	version  string
+}
+
+// send sends data as chunks from the bundle's file.
+func (w *appWalker) send(data []byte, file string) error {
+	metadata := &source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_MobileApp{
+			MobileApp: &source_metadatapb.MobileApp{
+				Artifact: sanitizer.UTF8(w.artifact),
+				Platform: w.platform,
+				Package:  sanitizer.UTF8(w.pkg),
+				Version:  sanitizer.UTF8(w.version),
+				File:     sanitizer.UTF8(file),
+			},
+		},
+	}
+	containerType := "apk"
+	if w.platform == "ios" {
+		containerType = "ipa"
+	}
+	return sendChunks(w.ctx, data, metadata, inContainer(w.chunkSkel, containerType, file), w.chunksChan)
+}
+
+// files calls fn with the contents of each file of the bundle that's
+// scanned.
+func (w *appWalker) files(zr *zip.Reader, fn func(name string, data []byte) error) error {
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || mediaExts[fileExt(f.Name)] {
+			continue
+		}
+		data, err := w.budget.readZipFile(f, w.artifact)
+		if err != nil {
+			return err
+		}
+		if err := fn(f.Name, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apk sends the files of an Android app.
+func (w *appWalker) apk(zr *zip.Reader) error {
+	w.platform = "android"
+
+	var resources []apk.Resource
+	if f := findZipFile(zr, "resources.arsc"); f != nil {
+		data, err := w.budget.readZipFile(f, w.artifact)
+		if err != nil {
+			return err
+		}
+		// Obfuscated resource tables are scanned for their strings instead.
+		resources, _ = apk.ReadResources(data)
+	}
+	if f := findZipFile(zr, "AndroidManifest.xml"); f != nil {
+		data, err := w.budget.readZipFile(f, w.artifact)
+		if err != nil {
+			return err
+		}
+		if manifest, err := apk.ParseManifest(data); err == nil {
+			w.pkg = manifest.Package
+			w.version = appVersion(resolveResource(manifest.VersionName, resources), manifest.VersionCode)
+		}
+	}
+
+	return w.files(zr, func(name string, data []byte) error {
+		if name == "resources.arsc" && resources != nil {
+			var text bytes.Buffer
+			for _, res := range resources {
+				fmt.Fprintf(&text, "%s/%s = %s\n", res.Type, res.Name, res.Value)
+			}
+			return w.send(text.Bytes(), name)
+		}
+		if apk.IsBinaryXML(data) {
+			if text, err := apk.DecodeXML(data); err == nil {
+				return w.send(text, name)
+			}
+		}
+		return w.send(bundleFileText(data), name)
+	})
+}
+
+// ipa sends the files of an iOS app.
+func (w *appWalker) ipa(zr *zip.Reader) error {
+	w.platform = "ios"
+
+	for _, f := range zr.File {
+		if !infoPlistPat.MatchString(f.Name) {
+			continue
+		}
+		data, err := w.budget.readZipFile(f, w.artifact)
+		if err != nil {
+			return err
+		}
+		if info, err := plist.Decode(data); err == nil {
+			dict, _ := info.(map[string]interface{})
+			w.pkg, _ = dict["CFBundleIdentifier"].(string)
+			shortVersion, _ := dict["CFBundleShortVersionString"].(string)
+			bundleVersion, _ := dict["CFBundleVersion"].(string)
+			w.version = appVersion(shortVersion, bundleVersion)
+		}
+		break
+	}
+
+	return w.files(zr, func(name string, data []byte) error {
+		// The signature holds the hashes of the bundle's files.
+		if strings.Contains(name, "/_CodeSignature/") {
+			return nil
+		}
+		switch fileExt(name) {
+		case ".plist", ".strings", ".stringsdict":
+			// Strings files may also be plain text, and are scanned as such
+			// if they aren't property lists.
+			if value, err := plist.Decode(data); err == nil {
+				var text bytes.Buffer
+				writePlist(&text, "", value)
+				return w.send(text.Bytes(), name)
+			}
+		}
+		return w.send(bundleFileText(data), name)
+	})
+}
+
+// appVersion formats the version of an app from its user visible version
+// and its build number.
+func appVersion(name, code string) string {
+	switch {
+	case name == "":
+		return code
+	case code == "" || code == name:
+		return name
+	default:
+		return name + " (" + code + ")"
+	}
+}
+
+// resolveResource returns the value of a "@0x7f..." resource reference of a
+// binary XML file, or value itself if it isn't one.
+func resolveResource(value string, resources []apk.Resource) string {
+	if !strings.HasPrefix(value, "@0x") {
+		return value
+	}
+	id, err := strconv.ParseUint(value[len("@0x"):], 16, 32)
+	if err != nil {
+		return value
+	}
+	for _, res := range resources {
+		if res.ID == uint32(id) {
+			return res.Value
+		}
+	}
+	return value
+}
+
+// writePlist writes the values of a property list as "key = value" lines,
+// with the keys of nested values joined by dots.
+func writePlist(w io.Writer, key string, value interface{}) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if key != "" {
+				writePlist(w, key+"."+k, value[k])
+			} else {
+				writePlist(w, k, value[k])
+			}
+		}
+	case []interface{}:
+		for i, v := range value {
+			writePlist(w, fmt.Sprintf("%s[%d]", key, i), v)
+		}
+	case []byte:
+		if isText(value) {
+			fmt.Fprintf(w, "%s = %s\n", key, value)
+		}
+	default:
+		fmt.Fprintf(w, "%s = %v\n", key, value)
+	}
+}
+
+// bundleFileText returns the text of a file of a bundle: the file itself if
+// it's text, and its strings otherwise.
+func bundleFileText(data []byte) []byte {
+	if isText(data) {
+		return data
+	}
+	return extractStrings(data)
+}
+
+// isText reports whether data is UTF-8 text.
+func isText(data []byte) bool {
+	return bytes.IndexByte(data, 0) < 0 && utf8.Valid(data)
+}
+
+// findZipFile returns the file of a zip archive with the given name, or nil.
+func findZipFile(zr *zip.Reader, name string) *zip.File {
+	for _, f := range zr.File {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// readerSize returns the size of file, which must be a reader with a Size
+// method, like bytes.Reader, or an os.File.
+func readerSize(file io.ReaderAt) (int64, error) {
+	switch file := file.(type) {
+	case interface{ Size() int64 }:
+		return file.Size(), nil
+	case *os.File:
+		info, err := file.Stat()
+		if err != nil {
+			return 0, errors.WrapPrefix(err, "could not stat file", 0)
+		}
+		return info.Size(), nil
+	default:
+		return 0, errors.Errorf("can't tell the size of a %T", file)
+	}
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// maxImageSize is the largest image OCR is run on.
+const maxImageSize = 20 * 1024 * 1024
+
+// ocrContentTypes are the image types OCR reads.
+var ocrContentTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/bmp":  true,
+	"image/tiff": true,
+	"image/webp": true,
+}
+
+// DefaultOCRCommand runs tesseract, which reads an image from stdin and
+// writes its text to stdout.
+// https://tesseract-ocr.github.io/tessdoc/Command-Line-Usage.html
+var DefaultOCRCommand = []string{"tesseract", "stdin", "stdout"}
+
+// OCR reads the text of images, such as screenshots of terminals and
+// configuration files, so the secrets they show are found. It's opt in, since
+// it's slow and needs an OCR engine installed: sources and the engine only run
+// it when it's in their context.
+type OCR struct {
+	// Command is run for each image, given the image on stdin, and prints
+	// the image's text to stdout. It can run an OCR engine or send the image
+	// to an OCR service. DefaultOCRCommand is run if it's empty.
+	Command []string
+}
+
+// Ensure the OCR satisfies the interface at compile time.
+var _ Handler = (*OCR)(nil)
+
+type ocrKey struct{}
+
+// WithOCR returns a copy of ctx in which images are read with ocr.
+func WithOCR(ctx context.Context, ocr *OCR) context.Context {
+	return context.WithValue(ctx, ocrKey{}, ocr)
+}
+
+// OCRFromContext returns the OCR stored in ctx, or nil if there is none.
+func OCRFromContext(ctx context.Context) *OCR {
+	ocr, _ := ctx.Value(ocrKey{}).(*OCR)
+	return ocr
+}
+
+// Reads reports whether OCR reads images of the content type.
+func (h *OCR) Reads(contentType string) bool {
+	return ocrContentTypes[contentType]
+}
+
+// Text returns the text of an image.
+func (h *OCR) Text(ctx context.Context, image []byte) ([]byte, error) {
+	command := h.Command
+	if len(command) == 0 {
+		command = DefaultOCRCommand
+	}
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdin = bytes.NewReader(image)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	text, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.WrapPrefix(err, "OCR failed: "+msg, 0)
+		}
+		return nil, errors.WrapPrefix(err, "OCR failed", 0)
+	}
+	return text, nil
+}
+
+func (h *OCR) Accepts(_ string, header []byte) bool {
+	return h.Reads(ContentType(header))
+}
+
+// Handle sends the text of an image file.
+func (h *OCR) Handle(ctx context.Context, path string, file io.ReaderAt, chunkSkel *sources.Chunk, chunksChan chan *sources.Chunk) error {
+	image, err := io.ReadAll(io.NewSectionReader(file, 0, maxImageSize))
+	if err != nil {
+		return errors.WrapPrefix(err, "could not read image", 0)
+	}
+	text, err := h.Text(ctx, image)
+	if err != nil {
+		return err
+	}
+	return sendChunks(ctx, text, chunkSkel.SourceMetadata, chunkSkel, chunksChan)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// UnifiedLog parses macOS unified logs exported with `log show --style json`
+// or `--style ndjson`. Messages are JSON escaped in the export, which can
+// break up credentials, so the handler decodes them and sends a chunk for each
+// log entry.
+type UnifiedLog struct{}
+
+// Ensure the UnifiedLog handler satisfies the interface at compile time.
+var _ Handler = (*UnifiedLog)(nil)
+
+// unifiedLogEntry holds the fields of an exported log entry that are kept.
+type unifiedLogEntry struct {
+	Timestamp        string `json:"timestamp"`
+	EventMessage     string `json:"eventMessage"`
+	ProcessImagePath string `json:"processImagePath"`
+	ProcessID        int64  `json:"processID"`
+	Subsystem        string `json:"subsystem"`
+	Category         string `json:"category"`
+}
+
+func (h *UnifiedLog) Accepts(path string, header []byte) bool {
+	header = bytes.TrimLeft(header, " \t\r\n[")
+	// Every entry starts with its trace ID.
+	return bytes.HasPrefix(header, []byte("{")) && bytes.Contains(header, []byte(`"traceID"`))
+}
+
+func (h *UnifiedLog) Handle(ctx context.Context, path string, file io.ReaderAt, chunkSkel *sources.Chunk, chunksChan chan *sources.Chunk) error {
+	decoder := json.NewDecoder(io.NewSectionReader(file, 0, math.MaxInt64))
+
+	// The json style is an array of entries and ndjson is one entry per line.
+	array := false
+	if token, err := decoder.Token(); err == nil && token == json.Delim('[') {
+		array = true
+	} else {
+		decoder = json.NewDecoder(io.NewSectionReader(file, 0, math.MaxInt64))
+	}
+
+	for {
+		if array && !decoder.More() {
+			return nil
+		}
+		var entry unifiedLogEntry
+		err := decoder.Decode(&entry)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return errors.WrapPrefix(err, "could not parse unified log entry", 0)
+		}
+		if entry.EventMessage == "" {
+			continue
+		}
+
+		chunk := *chunkSkel
+		chunk.Data = []byte(entry.EventMessage)
+		chunk.SourceMetadata = &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_UnifiedLog{
+				UnifiedLog: &source_metadatapb.UnifiedLog{
+					File:      sanitizer.UTF8(path),
+					Timestamp: sanitizer.UTF8(entry.Timestamp),
+					Process:   sanitizer.UTF8(entry.ProcessImagePath),
+					ProcessId: entry.ProcessID,
+					Subsystem: sanitizer.UTF8(entry.Subsystem),
+					Category:  sanitizer.UTF8(entry.Category),
+				},
+			},
+		}
+		select {
+		case chunksChan <- &chunk:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
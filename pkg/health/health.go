@@ -0,0 +1,134 @@
+// Package health serves the liveness and readiness endpoints used to monitor
+// long running scans, such as syslog listeners, from orchestrators like
+// Kubernetes.
+//
+// /healthz fails once a source has returned an error or the sink queue is
+// full, which means the process should be restarted. /readyz also fails while
+// any listener isn't accepting data. /metrics serves the scan's metrics, when
+// there are any, in the Prometheus text format. /summary serves the scan's
+// summary as JSON, including the items sources failed to scan, so reruns can
+// target just those.
+package health
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Checks provide the state reported by the endpoints. Checks left nil are
+// skipped.
+type Checks struct {
+	// Listeners reports whether each listening source is accepting data.
+	Listeners func() map[string]bool
+	// SourceErrors returns the errors sources have failed with.
+	SourceErrors func() []error
+	// SinkBacklog returns the number of findings waiting to be published and
+	// the number that can be queued.
+	SinkBacklog func() (backlog, capacity int)
+	// LastVerified returns when a result was last verified, or the zero time.
+	LastVerified func() time.Time
+	// Metrics writes metrics in the Prometheus text format.
+	Metrics func(w io.Writer) error
+	// Summary writes the summary of the scan as JSON.
+	Summary func(w io.Writer) error
+}
+
+// Status is the body returned by both endpoints.
+type Status struct {
+	Healthy      bool            `json:"healthy"`
+	Ready        bool            `json:"ready"`
+	Listeners    map[string]bool `json:"listeners,omitempty"`
+	SourceErrors []string        `json:"source_errors,omitempty"`
+	SinkBacklog  int             `json:"sink_backlog"`
+	SinkCapacity int             `json:"sink_capacity,omitempty"`
+	LastVerified *time.Time      `json:"last_verified,omitempty"`
+	// Problems explains why the process isn't healthy or ready.
+	Problems []string `json:"problems,omitempty"`
+}
+
+// Status runs the checks.
+func (c Checks) Status() Status {
+	status := Status{Healthy: true}
+
+	if c.SourceErrors != nil {
+		for _, err := range c.SourceErrors() {
+			status.SourceErrors = append(status.SourceErrors, err.Error())
+		}
+		if len(status.SourceErrors) > 0 {
+			status.Healthy = false
+			status.Problems = append(status.Problems, "a source failed")
+		}
+	}
+
+	if c.SinkBacklog != nil {
+		status.SinkBacklog, status.SinkCapacity = c.SinkBacklog()
+		if status.SinkCapacity > 0 && status.SinkBacklog >= status.SinkCapacity {
+			status.Healthy = false
+			status.Problems = append(status.Problems, "the sink queue is full")
+		}
+	}
+
+	status.Ready = status.Healthy
+
+	if c.Listeners != nil {
+		status.Listeners = c.Listeners()
+		var names []string
+		for name, listening := range status.Listeners {
+			if !listening {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			status.Ready = false
+			status.Problems = append(status.Problems, name+" is not listening")
+		}
+	}
+
+	if c.LastVerified != nil {
+		if last := c.LastVerified(); !last.IsZero() {
+			status.LastVerified = &last
+		}
+	}
+
+	return status
+}
+
+// Handler serves /healthz and /readyz. Both respond 200 when their check
+// passes and 503 when it doesn't, with the Status as JSON. It also serves
+// /metrics when checks has Metrics, and /summary when it has Summary.
+func Handler(checks Checks) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		status := checks.Status()
+		writeStatus(w, status, status.Healthy)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		status := checks.Status()
+		writeStatus(w, status, status.Ready)
+	})
+	if checks.Metrics != nil {
+		mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+			_ = checks.Metrics(w)
+		})
+	}
+	if checks.Summary != nil {
+		mux.HandleFunc("/summary", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_ = checks.Summary(w)
+		})
+	}
+	return mux
+}
+
+func writeStatus(w http.ResponseWriter, status Status, ok bool) {
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,118 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	verified := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		checks      Checks
+		wantHealthz int
+		wantReadyz  int
+	}{
+		{
+			name:        "no checks",
+			wantHealthz: http.StatusOK,
+			wantReadyz:  http.StatusOK,
+		},
+		{
+			name: "listening",
+			checks: Checks{
+				Listeners:    func() map[string]bool { return map[string]bool{"syslog": true} },
+				SourceErrors: func() []error { return nil },
+				SinkBacklog:  func() (int, int) { return 3, 10 },
+				LastVerified: func() time.Time { return verified },
+			},
+			wantHealthz: http.StatusOK,
+			wantReadyz:  http.StatusOK,
+		},
+		{
+			name: "not listening yet",
+			checks: Checks{
+				Listeners: func() map[string]bool { return map[string]bool{"syslog": false} },
+			},
+			wantHealthz: http.StatusOK,
+			wantReadyz:  http.StatusServiceUnavailable,
+		},
+		{
+			name: "source failed",
+			checks: Checks{
+				Listeners:    func() map[string]bool { return map[string]bool{"syslog": false} },
+				SourceErrors: func() []error { return []error{errors.New("address already in use")} },
+			},
+			wantHealthz: http.StatusServiceUnavailable,
+			wantReadyz:  http.StatusServiceUnavailable,
+		},
+		{
+			name: "sink queue full",
+			checks: Checks{
+				SinkBacklog: func() (int, int) { return 10, 10 },
+			},
+			wantHealthz: http.StatusServiceUnavailable,
+			wantReadyz:  http.StatusServiceUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Handler(tt.checks)
+			for path, want := range map[string]int{"/healthz": tt.wantHealthz, "/readyz": tt.wantReadyz} {
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+				if rec.Code != want {
+					t.Errorf("%s returned %d, want %d: %s", path, rec.Code, want, rec.Body.String())
+				}
+				var status Status
+				if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+					t.Fatalf("%s returned invalid JSON: %s", path, err)
+				}
+				if tt.checks.LastVerified != nil && (status.LastVerified == nil || !status.LastVerified.Equal(verified)) {
+					t.Errorf("%s returned last verified %v, want %v", path, status.LastVerified, verified)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_Metrics(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Handler(Checks{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("/metrics without metrics returned %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	checks := Checks{Metrics: func(w io.Writer) error {
+		_, err := io.WriteString(w, "trufflehog_detector_chunks_total{detector=\"aws\"} 1\n")
+		return err
+	}}
+	rec = httptest.NewRecorder()
+	Handler(checks).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "trufflehog_detector_chunks_total{detector=\"aws\"} 1\n" {
+		t.Errorf("/metrics returned %d: %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandler_Summary(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Handler(Checks{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/summary", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("/summary without a summary returned %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	checks := Checks{Summary: func(w io.Writer) error {
+		_, err := io.WriteString(w, `{"Chunks":1}`)
+		return err
+	}}
+	rec = httptest.NewRecorder()
+	Handler(checks).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/summary", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"Chunks":1}` || rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("/summary returned %d: %q", rec.Code, rec.Body.String())
+	}
+}
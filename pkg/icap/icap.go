@@ -0,0 +1,534 @@
+// Package icap serves the Internet Content Adaptation Protocol (RFC 3507), so
+// that proxies such as Squid can have the bodies of requests passing through
+// them scanned before they leave the network. Requests with secrets can be
+// blocked, making TruffleHog a point where credentials sent to external sites,
+// such as in uploads or prompts to LLM APIs, are stopped.
+package icap
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// DefaultAddress is the address ICAP is served on when none is given, on the
+// port registered for it.
+const DefaultAddress = ":1344"
+
+// DefaultMaxBodyBytes is how much of each request's body is scanned when the
+// options don't say.
+const DefaultMaxBodyBytes = 10 << 20
+
+// maxHeaderBytes is the most bytes the headers of an encapsulated request can
+// have.
+const maxHeaderBytes = 1 << 20
+
+// idleTimeout is how long a proxy's connection can go without a request
+// before it's closed.
+const idleTimeout = 5 * time.Minute
+
+// Policies for which requests are blocked.
+const (
+	// BlockNone blocks no requests, only reporting their secrets.
+	BlockNone = "none"
+	// BlockVerified blocks requests with verified secrets.
+	BlockVerified = "verified"
+	// BlockAny blocks requests with any secrets, verified or not.
+	BlockAny = "any"
+)
+
+// Scanner scans chunks and returns their results, as the engine does.
+type Scanner interface {
+	ScanChunk(ctx context.Context, chunk *sources.Chunk) []detectors.ResultWithMetadata
+}
+
+// Options configures a Server.
+type Options struct {
+	// Block is the policy for which requests are blocked. It defaults to
+	// BlockNone.
+	Block string
+	// MaxBodyBytes is how much of each request's body is scanned and held.
+	// It defaults to DefaultMaxBodyBytes. Requests with longer bodies that
+	// aren't blocked are answered with an error when the proxy doesn't allow
+	// 204 responses, since their bodies would have to be sent back whole, so
+	// the proxy fails open or closed as it's configured to.
+	MaxBodyBytes int64
+	// Version is the version of TruffleHog, which is in the service tag told
+	// to proxies so they don't reuse verdicts of other versions.
+	Version string
+	// Report, if set, is called with the results of every request, once it's
+	// known whether the request is blocked.
+	Report func(detectors.ResultWithMetadata)
+}
+
+// Server scans the bodies of the requests proxies pass it, answering whether
+// they can be sent on. Blocked requests are answered with a 403 response
+// naming the kinds of secrets found, but not the secrets. Only bodies are
+// scanned, since headers hold the credentials requests are meant to carry to
+// their own services.
+type Server struct {
+	scanner   Scanner
+	opts      Options
+	istag     string
+	listening int32
+}
+
+// NewServer returns a server scanning requests with scanner.
+func NewServer(scanner Scanner, opts Options) (*Server, error) {
+	switch opts.Block {
+	case "":
+		opts.Block = BlockNone
+	case BlockNone, BlockVerified, BlockAny:
+	default:
+		return nil, errors.Errorf("unknown block policy %q, must be %s, %s or %s", opts.Block, BlockNone, BlockVerified, BlockAny)
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	// Tags are quoted, and at most 32 characters.
+	tag := strings.Map(func(r rune) rune {
+		if r == '"' || r < ' ' || r > '~' {
+			return -1
+		}
+		return r
+	}, "TH-"+opts.Version+"-"+opts.Block)
+	if len(tag) > 32 {
+		tag = tag[:32]
+	}
+	return &Server{scanner: scanner, opts: opts, istag: `"` + tag + `"`}, nil
+}
+
+// Listening reports whether the server is accepting connections.
+func (s *Server) Listening() bool {
+	return atomic.LoadInt32(&s.listening) == 1
+}
+
+// Serve accepts connections from proxies on lis until ctx is done, then closes
+// them and returns once the requests being scanned are answered.
+func (s *Server) Serve(ctx context.Context, lis net.Listener) error {
+	atomic.StoreInt32(&s.listening, 1)
+	defer atomic.StoreInt32(&s.listening, 0)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-stop:
+		}
+		lis.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return errors.WrapPrefix(err, "could not accept ICAP connection", 0)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.serveConn(ctx, conn)
+		}()
+	}
+}
+
+// serveConn answers the requests on a connection until the proxy closes it, it
+// goes idle, or ctx is done.
+func (s *Server) serveConn(ctx context.Context, conn net.Conn) {
+	logger := log.FromContext(ctx).WithValues("proxy", conn.RemoteAddr().String())
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(idleTimeout))
+		req, err := readRequest(r)
+		if err != nil {
+			if err != io.EOF && ctx.Err() == nil {
+				logger.V(2).Info("could not read ICAP request", "error", err.Error())
+				writeStatus(w, 400, "Bad Request", s.istag)
+				_ = w.Flush()
+			}
+			return
+		}
+		keepAlive, err := s.serveRequest(ctx, req, r, w)
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.V(2).Info("could not answer ICAP request", "error", err.Error())
+			}
+			return
+		}
+		if !keepAlive || strings.EqualFold(req.header.Get("Connection"), "close") {
+			return
+		}
+	}
+}
+
+// request is an ICAP request, up to its encapsulated message.
+type request struct {
+	method string
+	uri    string
+	header textproto.MIMEHeader
+}
+
+// readRequest reads the request line and headers of the next request.
+func readRequest(r *bufio.Reader) (*request, error) {
+	tp := textproto.NewReader(r)
+	line, err := tp.ReadLine()
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.Fields(line)
+	if len(parts) != 3 || !strings.HasPrefix(parts[2], "ICAP/") {
+		return nil, errors.Errorf("malformed request line %q", line)
+	}
+	header, err := tp.ReadMIMEHeader()
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not read headers", 0)
+	}
+	return &request{method: parts[0], uri: parts[1], header: header}, nil
+}
+
+// serveRequest answers a request, reporting whether the connection can be used
+// for another.
+func (s *Server) serveRequest(ctx context.Context, req *request, r *bufio.Reader, w *bufio.Writer) (bool, error) {
+	switch req.method {
+	case "OPTIONS":
+		fmt.Fprintf(w, "ICAP/1.0 200 OK\r\nMethods: REQMOD\r\nService: TruffleHog %s\r\nISTag: %s\r\nAllow: 204\r\nTransfer-Preview: *\r\nOptions-TTL: 3600\r\nEncapsulated: null-body=0\r\n\r\n", s.opts.Version, s.istag)
+		return true, nil
+	case "REQMOD":
+		return true, s.reqmod(ctx, req, r, w)
+	default:
+		// The encapsulated message can't be skipped without knowing the
+		// method, so the connection is closed.
+		writeStatus(w, 405, "Method Not Allowed", s.istag)
+		return false, nil
+	}
+}
+
+// reqmod scans the body of the HTTP request encapsulated in req, answering
+// whether it can be sent on.
+func (s *Server) reqmod(ctx context.Context, req *request, r *bufio.Reader, w *bufio.Writer) error {
+	sections, err := parseEncapsulated(req.header.Get("Encapsulated"))
+	if err != nil {
+		return err
+	}
+	var rawHeader []byte
+	hasBody := false
+	for i, section := range sections {
+		switch section.name {
+		case "req-hdr":
+			if i+1 >= len(sections) {
+				return errors.New("encapsulated request headers have no end")
+			}
+			if sections[i+1].offset-section.offset > maxHeaderBytes {
+				return errors.Errorf("encapsulated request headers are over the limit of %d bytes", maxHeaderBytes)
+			}
+			rawHeader = make([]byte, sections[i+1].offset-section.offset)
+			if _, err := io.ReadFull(r, rawHeader); err != nil {
+				return errors.WrapPrefix(err, "could not read encapsulated request headers", 0)
+			}
+		case "req-body":
+			hasBody = true
+		case "null-body":
+		default:
+			return errors.Errorf("unexpected encapsulated section %s in REQMOD", section.name)
+		}
+	}
+	if rawHeader == nil {
+		return errors.New("REQMOD has no encapsulated request headers")
+	}
+	httpReq, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(rawHeader)))
+	if err != nil {
+		return errors.WrapPrefix(err, "could not parse encapsulated request", 0)
+	}
+
+	allow204 := hasAllow204(req.header)
+	var (
+		body      []byte
+		truncated bool
+	)
+	if hasBody {
+		// A byte more than is scanned is kept, to tell whether the body was
+		// cut short.
+		keep := s.opts.MaxBodyBytes + 1
+		var ieof bool
+		body, ieof, err = readChunked(r, keep)
+		if err != nil {
+			return err
+		}
+		if req.header.Get("Preview") != "" && !ieof {
+			fmt.Fprint(w, "ICAP/1.0 100 Continue\r\n\r\n")
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			more, _, err := readChunked(r, keep-int64(len(body)))
+			if err != nil {
+				return err
+			}
+			body = append(body, more...)
+		}
+		if truncated = int64(len(body)) > s.opts.MaxBodyBytes; truncated {
+			body = body[:s.opts.MaxBodyBytes]
+		}
+	}
+
+	metadata := &source_metadatapb.ICAP{
+		Url:       requestURL(httpReq),
+		Method:    httpReq.Method,
+		ClientIp:  req.header.Get("X-Client-IP"),
+		User:      req.header.Get("X-Client-Username"),
+		Timestamp: time.Now().UTC().String(),
+	}
+	results := s.scan(ctx, httpReq, body, metadata)
+	blocked := s.blocks(results)
+	for _, result := range results {
+		result.SourceMetadata.GetIcap().Blocked = blocked
+		if s.opts.Report != nil {
+			s.opts.Report(result)
+		}
+	}
+
+	switch {
+	case blocked:
+		log.FromContext(ctx).Info("blocked request with secrets", "url", metadata.Url, "client_ip", metadata.ClientIp, "detectors", detectorNames(results))
+		writeBlocked(w, s.istag, results)
+	case allow204:
+		fmt.Fprintf(w, "ICAP/1.0 204 No Content\r\nISTag: %s\r\nEncapsulated: null-body=0\r\n\r\n", s.istag)
+	case truncated:
+		// The body wasn't held whole, so it can't be sent back.
+		log.FromContext(ctx).Info("request body is over the limit and the proxy doesn't allow 204 responses", "url", metadata.Url, "max_body_bytes", s.opts.MaxBodyBytes)
+		writeStatus(w, 500, "Server Error", s.istag)
+	default:
+		// The request is sent back unmodified.
+		if hasBody {
+			fmt.Fprintf(w, "ICAP/1.0 200 OK\r\nISTag: %s\r\nEncapsulated: req-hdr=0, req-body=%d\r\n\r\n", s.istag, len(rawHeader))
+			w.Write(rawHeader)
+			writeChunked(w, body)
+		} else {
+			fmt.Fprintf(w, "ICAP/1.0 200 OK\r\nISTag: %s\r\nEncapsulated: req-hdr=0, null-body=%d\r\n\r\n", s.istag, len(rawHeader))
+			w.Write(rawHeader)
+		}
+	}
+	return nil
+}
+
+// scan scans up to the most bytes of body the options allow, decompressing it
+// first if it's gzipped.
+func (s *Server) scan(ctx context.Context, httpReq *http.Request, body []byte, metadata *source_metadatapb.ICAP) []detectors.ResultWithMetadata {
+	if strings.EqualFold(httpReq.Header.Get("Content-Encoding"), "gzip") {
+		if zr, err := gzip.NewReader(bytes.NewReader(body)); err == nil {
+			if unzipped, err := ioutil.ReadAll(io.LimitReader(zr, s.opts.MaxBodyBytes)); err == nil || len(unzipped) > 0 {
+				body = unzipped
+			}
+		}
+	}
+	if int64(len(body)) > s.opts.MaxBodyBytes {
+		body = body[:s.opts.MaxBodyBytes]
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	return s.scanner.ScanChunk(ctx, &sources.Chunk{
+		SourceName:     "trufflehog - icap",
+		SourceType:     sourcespb.SourceType_SOURCE_TYPE_ICAP,
+		SourceMetadata: &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Icap{Icap: metadata}},
+		Data:           body,
+		Verify:         true,
+	})
+}
+
+// blocks reports whether a request with results is blocked.
+func (s *Server) blocks(results []detectors.ResultWithMetadata) bool {
+	for _, result := range results {
+		if s.opts.Block == BlockAny || (s.opts.Block == BlockVerified && result.Verified) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeBlocked writes a response replacing a blocked request with a 403
+// response naming the kinds of secrets found in it.
+func writeBlocked(w *bufio.Writer, istag string, results []detectors.ResultWithMetadata) {
+	message := fmt.Sprintf("Blocked by TruffleHog: the request has %s secrets.\n", strings.Join(detectorNames(results), ", "))
+	header := fmt.Sprintf("HTTP/1.1 403 Forbidden\r\nContent-Type: text/plain; charset=utf-8\r\nContent-Length: %d\r\nCache-Control: no-store\r\n\r\n", len(message))
+	fmt.Fprintf(w, "ICAP/1.0 200 OK\r\nISTag: %s\r\nEncapsulated: res-hdr=0, res-body=%d\r\n\r\n%s", istag, len(header), header)
+	writeChunked(w, []byte(message))
+}
+
+// detectorNames returns the sorted names of the detectors that found results.
+func detectorNames(results []detectors.ResultWithMetadata) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, result := range results {
+		name := result.DetectorType.String()
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writeStatus writes a response with a status alone.
+func writeStatus(w *bufio.Writer, code int, reason, istag string) {
+	fmt.Fprintf(w, "ICAP/1.0 %d %s\r\nISTag: %s\r\nEncapsulated: null-body=0\r\n\r\n", code, reason, istag)
+}
+
+// section is an entity of an encapsulated message, at an offset from the end
+// of the ICAP headers.
+type section struct {
+	name   string
+	offset int
+}
+
+// parseEncapsulated parses an Encapsulated header, such as
+// "req-hdr=0, req-body=412".
+func parseEncapsulated(value string) ([]section, error) {
+	var sections []section
+	for _, part := range strings.Split(value, ",") {
+		name, offset, ok := strings.Cut(strings.TrimSpace(part), "=")
+		n, err := strconv.Atoi(offset)
+		if !ok || err != nil || n < 0 || (len(sections) > 0 && n < sections[len(sections)-1].offset) {
+			return nil, errors.Errorf("malformed Encapsulated header %q", value)
+		}
+		sections = append(sections, section{name: name, offset: n})
+	}
+	return sections, nil
+}
+
+// hasAllow204 reports whether the proxy accepts 204 responses for requests it
+// can send on unmodified.
+func hasAllow204(header textproto.MIMEHeader) bool {
+	for _, value := range header.Values("Allow") {
+		for _, allowed := range strings.Split(value, ",") {
+			if strings.TrimSpace(allowed) == "204" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// requestURL returns the URL of an encapsulated request, which has the host in
+// its request line or its Host header.
+func requestURL(req *http.Request) string {
+	if req.URL.Host == "" && req.Host != "" {
+		u := *req.URL
+		u.Host = req.Host
+		if u.Scheme == "" {
+			u.Scheme = "http"
+		}
+		return u.String()
+	}
+	return req.URL.String()
+}
+
+// readChunked reads a chunked body up to its last chunk, keeping at most keep
+// bytes of it, or all of it if keep is negative. It reports whether the last
+// chunk had the ieof extension, which ends a preview that is the whole body.
+func readChunked(r *bufio.Reader, keep int64) ([]byte, bool, error) {
+	var body []byte
+	for {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, false, errors.WrapPrefix(err, "could not read chunk size", 0)
+		}
+		sizeField, ext, _ := strings.Cut(line, ";")
+		size, err := strconv.ParseInt(strings.TrimSpace(sizeField), 16, 64)
+		if err != nil || size < 0 {
+			return nil, false, errors.Errorf("malformed chunk size %q", line)
+		}
+		if size == 0 {
+			// The trailer ends with an empty line.
+			for {
+				trailer, err := readLine(r)
+				if err != nil {
+					return nil, false, errors.WrapPrefix(err, "could not read chunk trailer", 0)
+				}
+				if trailer == "" {
+					break
+				}
+			}
+			return body, strings.TrimSpace(ext) == "ieof", nil
+		}
+		kept := size
+		if keep >= 0 {
+			if room := keep - int64(len(body)); room < kept {
+				kept = room
+			}
+			if kept < 0 {
+				kept = 0
+			}
+		}
+		start := len(body)
+		body = append(body, make([]byte, kept)...)
+		if _, err := io.ReadFull(r, body[start:]); err != nil {
+			return nil, false, errors.WrapPrefix(err, "could not read chunk", 0)
+		}
+		if _, err := io.CopyN(ioutil.Discard, r, size-kept); err != nil {
+			return nil, false, errors.WrapPrefix(err, "could not read chunk", 0)
+		}
+		if end, err := readLine(r); err != nil || end != "" {
+			return nil, false, errors.New("chunk isn't followed by CRLF")
+		}
+	}
+}
+
+// readLine reads a line without its line ending.
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// writeChunked writes data as a chunked body.
+func writeChunked(w *bufio.Writer, data []byte) {
+	if len(data) > 0 {
+		fmt.Fprintf(w, "%x\r\n", len(data))
+		w.Write(data)
+		fmt.Fprint(w, "\r\n")
+	}
+	fmt.Fprint(w, "0\r\n\r\n")
+}
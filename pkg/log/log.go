@@ -0,0 +1,183 @@
+// Package log builds the structured loggers used throughout TruffleHog and
+// passes them between components in contexts.
+//
+// Loggers are named after the component that logs to them, such as "engine",
+// "detector.aws", or "source.git", and a level can be set for each component.
+// A level set for a component applies to every component named below it, so
+// "source" configures all sources.
+package log
+
+import (
+	"context"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/go-logr/logr"
+	"github.com/go-logr/zapr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Output formats supported by New.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Levels accepted by Config. Info is logged with Info, debug with V(1), and
+// trace with V(2).
+const (
+	ErrorLevel = zapcore.ErrorLevel
+	InfoLevel  = zapcore.InfoLevel
+	DebugLevel = zapcore.DebugLevel
+	TraceLevel = zapcore.DebugLevel - 1
+)
+
+// Config configures the root logger returned by New.
+type Config struct {
+	// Format is FormatText or FormatJSON. It defaults to FormatText.
+	Format string
+	// Level is the minimum level logged by components without their own level.
+	Level zapcore.Level
+	// Components sets the minimum level for individual components.
+	Components map[string]zapcore.Level
+	// Output is where logs are written. It defaults to os.Stderr.
+	Output io.Writer
+}
+
+// New returns a root logger configured by cfg.
+func New(cfg Config) (logr.Logger, error) {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = "time"
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	encoderConfig.EncodeLevel = levelEncoder
+
+	var encoder zapcore.Encoder
+	switch cfg.Format {
+	case FormatText, "":
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	case FormatJSON:
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	default:
+		return logr.Discard(), errors.Errorf("unknown log format %q", cfg.Format)
+	}
+
+	output := cfg.Output
+	if output == nil {
+		output = os.Stderr
+	}
+
+	core := newComponentCore(
+		zapcore.NewCore(encoder, zapcore.Lock(zapcore.AddSync(output)), zap.LevelEnablerFunc(func(zapcore.Level) bool { return true })),
+		cfg.Level,
+		cfg.Components,
+	)
+	return zapr.NewLogger(zap.New(core)), nil
+}
+
+// ParseLevel parses "error", "info", "debug", or "trace".
+func ParseLevel(level string) (zapcore.Level, error) {
+	switch strings.ToLower(level) {
+	case "error":
+		return ErrorLevel, nil
+	case "warn", "info":
+		return InfoLevel, nil
+	case "debug":
+		return DebugLevel, nil
+	case "trace":
+		return TraceLevel, nil
+	default:
+		return 0, errors.Errorf("unknown log level %q", level)
+	}
+}
+
+// ParseComponentLevels parses "component=level" pairs, such as "source.git=debug".
+func ParseComponentLevels(pairs []string) (map[string]zapcore.Level, error) {
+	levels := make(map[string]zapcore.Level, len(pairs))
+	for _, pair := range pairs {
+		component, level, ok := strings.Cut(pair, "=")
+		if !ok || component == "" {
+			return nil, errors.Errorf("invalid component level %q, expected component=level", pair)
+		}
+		parsed, err := ParseLevel(level)
+		if err != nil {
+			return nil, err
+		}
+		levels[component] = parsed
+	}
+	return levels, nil
+}
+
+// IntoContext returns a copy of ctx that carries logger.
+func IntoContext(ctx context.Context, logger logr.Logger) context.Context {
+	return logr.NewContext(ctx, logger)
+}
+
+// FromContext returns the logger carried by ctx, or a logger that discards
+// everything if there is none.
+func FromContext(ctx context.Context) logr.Logger {
+	return logr.FromContextOrDiscard(ctx)
+}
+
+func levelEncoder(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	if level < zapcore.DebugLevel {
+		enc.AppendString("trace")
+		return
+	}
+	enc.AppendString(level.String())
+}
+
+// componentCore filters entries by the level configured for the component
+// that logged them, identified by the logger's name.
+type componentCore struct {
+	zapcore.Core
+	level      zapcore.Level
+	components map[string]zapcore.Level
+	// names holds the keys of components, longest first, so the most specific
+	// component is matched.
+	names    []string
+	minLevel zapcore.Level
+}
+
+func newComponentCore(core zapcore.Core, level zapcore.Level, components map[string]zapcore.Level) *componentCore {
+	c := &componentCore{Core: core, level: level, components: components, minLevel: level}
+	for name, componentLevel := range components {
+		c.names = append(c.names, name)
+		if componentLevel < c.minLevel {
+			c.minLevel = componentLevel
+		}
+	}
+	sort.Slice(c.names, func(i, j int) bool { return len(c.names[i]) > len(c.names[j]) })
+	return c
+}
+
+func (c *componentCore) levelFor(loggerName string) zapcore.Level {
+	for _, name := range c.names {
+		if loggerName == name || strings.HasPrefix(loggerName, name+".") {
+			return c.components[name]
+		}
+	}
+	return c.level
+}
+
+// Enabled reports whether any component logs at level. Check makes the final
+// decision once the logger's name is known.
+func (c *componentCore) Enabled(level zapcore.Level) bool {
+	return level >= c.minLevel
+}
+
+func (c *componentCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.Core = c.Core.With(fields)
+	return &clone
+}
+
+func (c *componentCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if entry.Level < c.levelFor(entry.LoggerName) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
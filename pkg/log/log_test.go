@@ -0,0 +1,68 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestComponentLevels(t *testing.T) {
+	levels, err := ParseComponentLevels([]string{"source=error", "source.git=debug"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	root, err := New(Config{Format: FormatJSON, Level: zapcore.InfoLevel, Components: levels, Output: &buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root.WithName("engine").V(1).Info("engine debug")
+	root.WithName("engine").Info("engine info")
+	root.WithName("source").WithName("s3").Info("s3 info")
+	root.WithName("source").WithName("s3").Error(nil, "s3 error")
+	root.WithName("source").WithName("git").V(1).Info("git debug", "commit", "abc123")
+	root.WithName("source").WithName("git").V(2).Info("git trace")
+
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log line is not JSON: %q", line)
+		}
+		got = append(got, entry["msg"].(string))
+		if entry["msg"] == "git debug" && (entry["commit"] != "abc123" || entry["logger"] != "source.git" || entry["level"] != "debug") {
+			t.Errorf("unexpected entry: %v", entry)
+		}
+	}
+	want := []string{"engine info", "s3 error", "git debug"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("logged %v, want %v", got, want)
+	}
+}
+
+func TestParseComponentLevelsInvalid(t *testing.T) {
+	for _, pair := range []string{"engine", "=debug", "engine=loud"} {
+		if _, err := ParseComponentLevels([]string{pair}); err == nil {
+			t.Errorf("expected an error for %q", pair)
+		}
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(Config{Output: &buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	FromContext(IntoContext(context.Background(), logger)).Info("hello")
+	FromContext(context.Background()).Info("discarded")
+	if !strings.Contains(buf.String(), "hello") || strings.Contains(buf.String(), "discarded") {
+		t.Errorf("unexpected output: %q", buf.String())
+	}
+}
@@ -0,0 +1,531 @@
+// Package lsp serves the Language Server Protocol, so editors can warn of
+// secrets in documents as they're edited. Documents are scanned by every
+// detector, and their results are published as diagnostics on the lines the
+// secrets are on.
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// DefaultDebounce is how long a document must go unchanged before it's
+// scanned again, when the options don't say.
+const DefaultDebounce = 500 * time.Millisecond
+
+// maxDocumentBytes is the most bytes a document can have to be scanned, so
+// that editing a huge file doesn't scan all of it on every pause in typing.
+const maxDocumentBytes = 4 << 20
+
+// Diagnostic severities.
+const (
+	severityError   = 1
+	severityWarning = 2
+)
+
+// Scanner scans chunks and returns their results, as the engine does.
+type Scanner interface {
+	ScanChunk(ctx context.Context, chunk *sources.Chunk) []detectors.ResultWithMetadata
+}
+
+// Options configures a Server.
+type Options struct {
+	// Debounce is how long a document must go unchanged before it's scanned
+	// again. It defaults to DefaultDebounce.
+	Debounce time.Duration
+	// Verify is whether the secrets found are verified.
+	Verify bool
+	// OnlyVerified leaves out the secrets that aren't verified.
+	OnlyVerified bool
+	// Version is the version of TruffleHog told to clients.
+	Version string
+}
+
+// Server scans the documents a client opens, and again as they're changed,
+// publishing the secrets found as diagnostics. Verified secrets are errors
+// and others are warnings.
+type Server struct {
+	scanner Scanner
+	opts    Options
+
+	outMu sync.Mutex
+	out   io.Writer
+
+	mu       sync.Mutex
+	docs     map[string]*document
+	shutdown bool
+	closed   bool
+	scans    sync.WaitGroup
+}
+
+// document is a document the client has open.
+type document struct {
+	version int
+	text    []byte
+	// timer starts the next scan once the document stops changing.
+	timer *time.Timer
+	// cancel cancels the running scan, if there is one.
+	cancel context.CancelFunc
+}
+
+// NewServer returns a server scanning documents with scanner.
+func NewServer(scanner Scanner, opts Options) *Server {
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultDebounce
+	}
+	return &Server{scanner: scanner, opts: opts, docs: make(map[string]*document)}
+}
+
+// Serve reads messages from in and writes messages to out until the client
+// exits or ctx is canceled. It returns an error if the client exits without
+// shutting down the server first, or the connection fails.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.out = out
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		s.close()
+	}()
+
+	messages := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		r := bufio.NewReader(in)
+		for {
+			content, err := readMessage(r)
+			if err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case messages <- content:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-readErr:
+			if err == io.EOF && s.isShutdown() {
+				return nil
+			}
+			if err == io.EOF {
+				return errors.New("client closed the connection without shutting down the server")
+			}
+			return err
+		case content := <-messages:
+			exit, err := s.handle(ctx, content)
+			if err != nil {
+				return err
+			}
+			if exit {
+				if !s.isShutdown() {
+					return errors.New("client exited without shutting down the server")
+				}
+				return nil
+			}
+		}
+	}
+}
+
+// handle handles a message, reporting whether the client asked to exit.
+func (s *Server) handle(ctx context.Context, content []byte) (bool, error) {
+	var req request
+	if err := json.Unmarshal(content, &req); err != nil {
+		return false, s.respond(nil, nil, &responseError{Code: codeParseError, Message: err.Error()})
+	}
+	if req.Method == "" {
+		if req.isNotification() {
+			return false, nil
+		}
+		return false, s.respond(req.ID, nil, &responseError{Code: codeInvalidRequest, Message: "request has no method"})
+	}
+
+	var result interface{}
+	var rerr *responseError
+	switch req.Method {
+	case "initialize":
+		result = s.initializeResult()
+	case "initialized":
+	case "shutdown":
+		s.mu.Lock()
+		s.shutdown = true
+		s.mu.Unlock()
+	case "exit":
+		return true, nil
+	case "textDocument/didOpen":
+		var params struct {
+			TextDocument struct {
+				URI     string `json:"uri"`
+				Version int    `json:"version"`
+				Text    string `json:"text"`
+			} `json:"textDocument"`
+		}
+		if rerr = unmarshalParams(req.Params, &params); rerr == nil {
+			doc := params.TextDocument
+			s.update(ctx, doc.URI, doc.Version, []byte(doc.Text), 0)
+		}
+	case "textDocument/didChange":
+		var params struct {
+			TextDocument struct {
+				URI     string `json:"uri"`
+				Version int    `json:"version"`
+			} `json:"textDocument"`
+			ContentChanges []struct {
+				Text string `json:"text"`
+			} `json:"contentChanges"`
+		}
+		// The server asks for whole documents, so the last change is the
+		// document's text.
+		if rerr = unmarshalParams(req.Params, &params); rerr == nil && len(params.ContentChanges) > 0 {
+			text := params.ContentChanges[len(params.ContentChanges)-1].Text
+			s.update(ctx, params.TextDocument.URI, params.TextDocument.Version, []byte(text), s.opts.Debounce)
+		}
+	case "textDocument/didClose":
+		var params struct {
+			TextDocument struct {
+				URI string `json:"uri"`
+			} `json:"textDocument"`
+		}
+		if rerr = unmarshalParams(req.Params, &params); rerr == nil {
+			if err := s.remove(params.TextDocument.URI); err != nil {
+				return false, err
+			}
+		}
+	default:
+		if req.isNotification() {
+			return false, nil
+		}
+		rerr = &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q is not supported", req.Method)}
+	}
+	if req.isNotification() {
+		if rerr != nil {
+			log.FromContext(ctx).V(2).Info("ignored invalid notification", "method", req.Method, "error", rerr.Message)
+		}
+		return false, nil
+	}
+	return false, s.respond(req.ID, result, rerr)
+}
+
+// initializeResult returns the server's capabilities. Documents are synced
+// whole, since the secrets in them are found by scanning all of them anyway.
+func (s *Server) initializeResult() interface{} {
+	type syncOptions struct {
+		OpenClose bool `json:"openClose"`
+		Change    int  `json:"change"`
+	}
+	type capabilities struct {
+		TextDocumentSync syncOptions `json:"textDocumentSync"`
+	}
+	type serverInfo struct {
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+	}
+	return struct {
+		Capabilities capabilities `json:"capabilities"`
+		ServerInfo   serverInfo   `json:"serverInfo"`
+	}{
+		Capabilities: capabilities{TextDocumentSync: syncOptions{OpenClose: true, Change: 1}},
+		ServerInfo:   serverInfo{Name: "trufflehog", Version: s.opts.Version},
+	}
+}
+
+// unmarshalParams unmarshals a request's params into v.
+func unmarshalParams(params json.RawMessage, v interface{}) *responseError {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// respond sends the response to the request with id.
+func (s *Server) respond(id json.RawMessage, result interface{}, rerr *responseError) error {
+	resp := response{JSONRPC: "2.0", ID: id, Error: rerr}
+	if id == nil {
+		resp.ID = json.RawMessage("null")
+	}
+	if rerr == nil {
+		content, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		resp.Result = content
+	}
+	return s.write(resp)
+}
+
+// write writes a message to the client.
+func (s *Server) write(v interface{}) error {
+	s.outMu.Lock()
+	defer s.outMu.Unlock()
+	if err := writeMessage(s.out, v); err != nil {
+		return errors.WrapPrefix(err, "could not write message", 0)
+	}
+	return nil
+}
+
+func (s *Server) isShutdown() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.shutdown
+}
+
+// update sets the text of the document at uri, scanning it after delay.
+func (s *Server) update(ctx context.Context, uri string, version int, text []byte, delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doc, ok := s.docs[uri]
+	if !ok {
+		doc = &document{}
+		s.docs[uri] = doc
+	}
+	doc.version, doc.text = version, text
+	if doc.timer != nil {
+		doc.timer.Stop()
+	}
+	doc.timer = time.AfterFunc(delay, func() {
+		s.scan(ctx, uri, version, text)
+	})
+}
+
+// remove forgets the document at uri, clearing its diagnostics.
+func (s *Server) remove(uri string) error {
+	s.mu.Lock()
+	if doc, ok := s.docs[uri]; ok {
+		doc.stop()
+		delete(s.docs, uri)
+	}
+	s.mu.Unlock()
+	return s.publish(uri, nil, []diagnostic{})
+}
+
+// stop stops the document's pending and running scans.
+func (doc *document) stop() {
+	if doc.timer != nil {
+		doc.timer.Stop()
+	}
+	if doc.cancel != nil {
+		doc.cancel()
+	}
+}
+
+// close stops every scan and waits for the running ones to return.
+func (s *Server) close() {
+	s.mu.Lock()
+	s.closed = true
+	for _, doc := range s.docs {
+		doc.stop()
+	}
+	s.mu.Unlock()
+	s.scans.Wait()
+}
+
+// scan scans a version of the document at uri and publishes its diagnostics,
+// unless the document changed while it was scanned, as the scan of its new
+// version will publish them.
+func (s *Server) scan(ctx context.Context, uri string, version int, text []byte) {
+	s.mu.Lock()
+	doc, ok := s.docs[uri]
+	if s.closed || !ok || doc.version != version {
+		s.mu.Unlock()
+		return
+	}
+	if doc.cancel != nil {
+		doc.cancel()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	doc.cancel = cancel
+	s.scans.Add(1)
+	s.mu.Unlock()
+	defer s.scans.Done()
+	defer cancel()
+
+	diagnostics := s.diagnose(ctx, uri, text)
+
+	s.mu.Lock()
+	current := s.docs[uri] == doc && doc.version == version && ctx.Err() == nil
+	s.mu.Unlock()
+	if !current {
+		return
+	}
+	if err := s.publish(uri, &version, diagnostics); err != nil {
+		log.FromContext(ctx).Error(err, "could not publish diagnostics", "uri", uri)
+	}
+}
+
+// publish sends the diagnostics of a version of the document at uri.
+func (s *Server) publish(uri string, version *int, diagnostics []diagnostic) error {
+	return s.write(notification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params: struct {
+			URI         string       `json:"uri"`
+			Version     *int         `json:"version,omitempty"`
+			Diagnostics []diagnostic `json:"diagnostics"`
+		}{URI: uri, Version: version, Diagnostics: diagnostics},
+	})
+}
+
+// position is a position in a document, as a line and a character offset in
+// UTF-16 code units, both from 0.
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type textRange struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+// diagnostic is a secret found in a document.
+type diagnostic struct {
+	Range    textRange      `json:"range"`
+	Severity int            `json:"severity"`
+	Code     string         `json:"code"`
+	Source   string         `json:"source"`
+	Message  string         `json:"message"`
+	Data     diagnosticData `json:"data"`
+}
+
+// diagnosticData describes the secret of a diagnostic to editor extensions.
+type diagnosticData struct {
+	Detector string `json:"detector"`
+	Verified bool   `json:"verified"`
+	Redacted string `json:"redacted,omitempty"`
+}
+
+// diagnose scans the text of the document at uri and returns a diagnostic for
+// each place a secret is found. Secrets that aren't in the text as they are,
+// like those found encoded, are reported at its start.
+func (s *Server) diagnose(ctx context.Context, uri string, text []byte) []diagnostic {
+	diagnostics := []diagnostic{}
+	if len(text) > maxDocumentBytes {
+		log.FromContext(ctx).V(2).Info("document is too large to scan", "uri", uri, "bytes", len(text))
+		return diagnostics
+	}
+	chunk := &sources.Chunk{
+		SourceName: "trufflehog - lsp",
+		SourceType: sourcespb.SourceType_SOURCE_TYPE_FILESYSTEM,
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Filesystem{
+				Filesystem: &source_metadatapb.Filesystem{File: documentPath(uri)},
+			},
+		},
+		Data:   text,
+		Verify: s.opts.Verify,
+	}
+
+	type found struct {
+		detector string
+		offset   int
+	}
+	seen := make(map[found]bool)
+	for _, result := range s.scanner.ScanChunk(ctx, chunk) {
+		if s.opts.OnlyVerified && !result.Verified {
+			continue
+		}
+		starts := occurrences(text, result.Raw)
+		if len(starts) == 0 {
+			diagnostics = append(diagnostics, newDiagnostic(result, textRange{}))
+			continue
+		}
+		for _, start := range starts {
+			key := found{detector: result.DetectorType.String(), offset: start}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			r := textRange{Start: positionOf(text, start), End: positionOf(text, start+len(result.Raw))}
+			diagnostics = append(diagnostics, newDiagnostic(result, r))
+		}
+	}
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i].Range.Start, diagnostics[j].Range.Start
+		return a.Line < b.Line || (a.Line == b.Line && a.Character < b.Character)
+	})
+	return diagnostics
+}
+
+// newDiagnostic returns the diagnostic of a result at r.
+func newDiagnostic(result detectors.ResultWithMetadata, r textRange) diagnostic {
+	detector := result.DetectorType.String()
+	d := diagnostic{
+		Range:    r,
+		Severity: severityWarning,
+		Code:     detector,
+		Source:   "trufflehog",
+		Message:  fmt.Sprintf("Possible %s secret, unverified", detector),
+		Data:     diagnosticData{Detector: detector, Verified: result.Verified, Redacted: result.Redacted},
+	}
+	if result.Verified {
+		d.Severity = severityError
+		d.Message = fmt.Sprintf("Verified %s secret", detector)
+	}
+	return d
+}
+
+// occurrences returns the offsets of each occurrence of secret in text.
+func occurrences(text, secret []byte) []int {
+	var offsets []int
+	if len(secret) == 0 {
+		return nil
+	}
+	for i := 0; i < len(text); {
+		j := bytes.Index(text[i:], secret)
+		if j < 0 {
+			break
+		}
+		offsets = append(offsets, i+j)
+		i += j + len(secret)
+	}
+	return offsets
+}
+
+// positionOf returns the position of the byte at offset in text.
+func positionOf(text []byte, offset int) position {
+	lineStart := bytes.LastIndexByte(text[:offset], '\n') + 1
+	line := bytes.Count(text[:lineStart], []byte("\n"))
+	character := 0
+	for rest := text[lineStart:offset]; len(rest) > 0; {
+		r, size := utf8.DecodeRune(rest)
+		// Characters outside the Basic Multilingual Plane take two code units.
+		character++
+		if r > 0xFFFF {
+			character++
+		}
+		rest = rest[size:]
+	}
+	return position{Line: line, Character: character}
+}
+
+// documentPath returns the path of the file at uri, or uri itself if it isn't
+// a file URI.
+func documentPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return u.Path
+}
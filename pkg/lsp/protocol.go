@@ -0,0 +1,95 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+// maxMessageBytes is the most bytes a message from the client can have, so a
+// malformed Content-Length can't make the server allocate without bound.
+const maxMessageBytes = 64 << 20
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// request is a JSON-RPC request, or a notification if it has no ID.
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// isNotification reports whether the client expects no response.
+func (r *request) isNotification() bool {
+	return len(r.ID) == 0
+}
+
+// response is a JSON-RPC response, which has a result or an error.
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *responseError  `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// notification is a JSON-RPC notification from the server.
+type notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+// readMessage reads the content of the next message, which is preceded by
+// headers giving its length.
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	headers, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+		return nil, errors.WrapPrefix(err, "could not read message headers", 0)
+	}
+	length, err := strconv.Atoi(strings.TrimSpace(headers.Get("Content-Length")))
+	if err != nil || length < 0 {
+		return nil, errors.Errorf("message has an invalid Content-Length %q", headers.Get("Content-Length"))
+	}
+	if length > maxMessageBytes {
+		return nil, errors.Errorf("message of %d bytes is over the limit of %d", length, maxMessageBytes)
+	}
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, errors.WrapPrefix(err, "could not read message", 0)
+	}
+	return content, nil
+}
+
+// writeMessage writes v as a message.
+func writeMessage(w io.Writer, v interface{}) error {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(content)); err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
@@ -0,0 +1,59 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/results"
+)
+
+// PrintDiffJSON prints each finding in d as a line of JSON with its status
+// and the finding as the scan wrote it.
+func PrintDiffJSON(d *results.Diff) error {
+	for _, group := range diffGroups(d) {
+		for _, f := range group.findings {
+			out, err := json.Marshal(struct {
+				Status  results.Status
+				Finding json.RawMessage
+			}{group.status, f.Line})
+			if err != nil {
+				return errors.WrapPrefix(err, "could not marshal diff", 0)
+			}
+			fmt.Println(string(out))
+		}
+	}
+	return nil
+}
+
+// PrintPlainDiff prints the findings in d grouped by their status.
+func PrintPlainDiff(d *results.Diff) {
+	for _, group := range diffGroups(d) {
+		yellowPrinter.Printf("%s findings: %d\n", group.title, len(group.findings))
+		for _, f := range group.findings {
+			verified := "unverified"
+			printer := whitePrinter
+			if f.Verified {
+				verified = "verified"
+				printer = greenPrinter
+			}
+			printer.Printf("  %s (%s) %s %s\n", f.DetectorName, verified, f.Redacted, f.Location())
+		}
+		fmt.Println("")
+	}
+}
+
+type diffGroup struct {
+	title    string
+	status   results.Status
+	findings []results.Finding
+}
+
+func diffGroups(d *results.Diff) []diffGroup {
+	return []diffGroup{
+		{"New", results.StatusNew, d.New},
+		{"Resolved", results.StatusResolved, d.Resolved},
+		{"Persisting", results.StatusPersisting, d.Persisting},
+	}
+}
@@ -4,15 +4,29 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/sirupsen/logrus"
+	"github.com/go-errors/errors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/findings"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
 )
 
-func PrintJSON(r *detectors.ResultWithMetadata) {
+func PrintJSON(r *detectors.ResultWithMetadata) error {
+	out, err := EncodeJSON(r)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not marshal result", 0)
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+// EncodeJSON returns the JSON representation of a result used by PrintJSON.
+func EncodeJSON(r *detectors.ResultWithMetadata) ([]byte, error) {
 	v := &struct {
+		// FindingID identifies this secret in this location across scans.
+		FindingID string
 		// SourceMetadata contains source-specific contextual information.
 		SourceMetadata *source_metadatapb.MetaData
 		// SourceID is the ID of the source that the API uses to map secrets to specific sources.
@@ -21,6 +35,11 @@ func PrintJSON(r *detectors.ResultWithMetadata) {
 		SourceType sourcespb.SourceType
 		// SourceName is the name of the Source.
 		SourceName string
+		// Provenance is the chain of containers the secret was unpacked from.
+		Provenance []sources.ContainerRef `json:",omitempty"`
+		// Tenant is the customer or organization whose assets the secret
+		// was found in.
+		Tenant string `json:",omitempty"`
 		// DetectorType is the type of Detector.
 		DetectorType detectorspb.DetectorType
 		// DetectorName is the string name of the DetectorType.
@@ -34,10 +53,13 @@ func PrintJSON(r *detectors.ResultWithMetadata) {
 		ExtraData      map[string]string
 		StructuredData *detectorspb.StructuredData
 	}{
+		FindingID:      findings.ID(r),
 		SourceMetadata: r.SourceMetadata,
 		SourceID:       r.SourceID,
 		SourceType:     r.SourceType,
 		SourceName:     r.SourceName,
+		Provenance:     r.Provenance,
+		Tenant:         r.Tenant,
 		DetectorType:   r.DetectorType,
 		DetectorName:   r.DetectorType.String(),
 		Verified:       r.Verified,
@@ -46,9 +68,5 @@ func PrintJSON(r *detectors.ResultWithMetadata) {
 		ExtraData:      r.ExtraData,
 		StructuredData: r.StructuredData,
 	}
-	out, err := json.Marshal(v)
-	if err != nil {
-		logrus.WithError(err).Fatal("could not marshal result")
-	}
-	fmt.Println(string(out))
+	return json.Marshal(v)
 }
@@ -1,40 +1,48 @@
 package output
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/url"
 	"os"
 	"strings"
 
+	"github.com/go-errors/errors"
 	gogit "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/sergi/go-diff/diffmatchpatch"
-	"github.com/sirupsen/logrus"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
 )
 
-func PrintLegacyJSON(r *detectors.ResultWithMetadata) {
-	repoPath, remote, err := git.PrepareRepo(r.SourceMetadata.GetGithub().Repository)
-	if err != nil || repoPath == "" {
-		logrus.WithError(err).Fatal("error preparing git repo for scanning")
+func PrintLegacyJSON(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	repoPath, remote, err := git.PrepareRepo(ctx, r.SourceMetadata.GetGithub().Repository)
+	if err != nil {
+		return errors.WrapPrefix(err, "error preparing git repo for scanning", 0)
+	}
+	if repoPath == "" {
+		return errors.New("error preparing git repo for scanning: no repo path")
 	}
 	if remote {
 		defer os.RemoveAll(repoPath)
 	}
-	legacy := ConvertToLegacyJSON(r, repoPath)
+	legacy, err := ConvertToLegacyJSON(ctx, r, repoPath)
+	if err != nil {
+		return err
+	}
 	out, err := json.Marshal(legacy)
 	if err != nil {
-		logrus.WithError(err).Fatal("could not marshal result")
+		return errors.WrapPrefix(err, "could not marshal result", 0)
 	}
 	fmt.Println(string(out))
+	return nil
 }
 
-func ConvertToLegacyJSON(r *detectors.ResultWithMetadata, repoPath string) *LegacyJSONOutput {
+func ConvertToLegacyJSON(ctx context.Context, r *detectors.ResultWithMetadata, repoPath string) (*LegacyJSONOutput, error) {
 	var source LegacyJSONCompatibleSource
 	switch r.SourceType {
 	case sourcespb.SourceType_SOURCE_TYPE_GIT:
@@ -44,24 +52,24 @@ func ConvertToLegacyJSON(r *detectors.ResultWithMetadata, repoPath string) *Lega
 	case sourcespb.SourceType_SOURCE_TYPE_GITLAB:
 		source = r.SourceMetadata.GetGitlab()
 	default:
-		log.Fatalf("legacy JSON output can not be used with this source: %s", r.SourceName)
+		return nil, errors.Errorf("legacy JSON output can not be used with this source: %s", r.SourceName)
 	}
 
 	// The repo will be needed to gather info needed for the legacy output that isn't included in the new
 	// output format.
 	repo, err := gogit.PlainOpenWithOptions(repoPath, &gogit.PlainOpenOptions{DetectDotGit: true})
 	if err != nil {
-		logrus.WithError(err).Fatalf("could not open repo: %s", repoPath)
+		return nil, errors.WrapPrefix(err, fmt.Sprintf("could not open repo: %s", repoPath), 0)
 	}
 
 	fileName := source.GetFile()
 	commitHash := plumbing.NewHash(source.GetCommit())
 	commit, err := repo.CommitObject(commitHash)
 	if err != nil {
-		log.Fatal(err)
+		return nil, errors.WrapPrefix(err, fmt.Sprintf("could not get commit: %s", commitHash), 0)
 	}
 
-	diff := GenerateDiff(commit, fileName)
+	diff := GenerateDiff(ctx, commit, fileName)
 
 	foundString := string(r.Result.Raw)
 
@@ -70,7 +78,7 @@ func ConvertToLegacyJSON(r *detectors.ResultWithMetadata, repoPath string) *Lega
 
 	// Load up the struct to match the old JSON format
 	output := &LegacyJSONOutput{
-		Branch:       FindBranch(commit, repo),
+		Branch:       FindBranch(ctx, commit, repo),
 		Commit:       commit.Message,
 		CommitHash:   commitHash.String(),
 		Date:         commit.Committer.When.Format("2006-01-02 15:04:05"),
@@ -80,12 +88,13 @@ func ConvertToLegacyJSON(r *detectors.ResultWithMetadata, repoPath string) *Lega
 		Reason:       r.Result.DetectorType.String(),
 		StringsFound: []string{foundString},
 	}
-	return output
+	return output, nil
 }
 
 // BranchHeads creates a map of branch names to their head commit. This can be used to find if a commit is an ancestor
 // of a branch head.
-func BranchHeads(repo *gogit.Repository) (map[string]*object.Commit, error) {
+func BranchHeads(ctx context.Context, repo *gogit.Repository) (map[string]*object.Commit, error) {
+	logger := log.FromContext(ctx)
 	branches := map[string]*object.Commit{}
 	branchIter, err := repo.Branches()
 	if err != nil {
@@ -96,12 +105,12 @@ func BranchHeads(repo *gogit.Repository) (map[string]*object.Commit, error) {
 		branchName := branchRef.Name().String()
 		headHash, err := repo.ResolveRevision(plumbing.Revision(branchName))
 		if err != nil {
-			logrus.WithError(err).Errorf("unable to resolve head of branch: %s", branchRef.Name().String())
+			logger.Error(err, "unable to resolve head of branch", "branch", branchRef.Name().String())
 			return nil
 		}
 		headCommit, err := repo.CommitObject(*headHash)
 		if err != nil {
-			logrus.WithError(err).Errorf("unable to get commit: %s", headCommit.String())
+			logger.Error(err, "unable to get commit", "commit", headHash.String())
 			return nil
 		}
 		branches[branchName] = headCommit
@@ -111,16 +120,18 @@ func BranchHeads(repo *gogit.Repository) (map[string]*object.Commit, error) {
 }
 
 // FindBranch returns the first branch a commit is a part of. Not the most accurate, but it should work similar to pre v3.0.
-func FindBranch(commit *object.Commit, repo *gogit.Repository) string {
-	branches, err := BranchHeads(repo)
+func FindBranch(ctx context.Context, commit *object.Commit, repo *gogit.Repository) string {
+	logger := log.FromContext(ctx)
+	branches, err := BranchHeads(ctx, repo)
 	if err != nil {
-		logrus.WithError(err).Fatal("could not list branches")
+		logger.Error(err, "could not list branches")
+		return ""
 	}
 
 	for name, head := range branches {
 		isAncestor, err := commit.IsAncestor(head)
 		if err != nil {
-			logrus.WithError(err).Errorf("could not determine if %s is an ancestor of %s", commit.Hash.String(), head.Hash.String())
+			logger.Error(err, "could not determine if commit is an ancestor of branch head", "commit", commit.Hash.String(), "head", head.Hash.String())
 			continue
 		}
 		if isAncestor {
@@ -131,14 +142,15 @@ func FindBranch(commit *object.Commit, repo *gogit.Repository) string {
 }
 
 // GenerateDiff will take a commit and create a string diff between the commit and its first parent.
-func GenerateDiff(commit *object.Commit, fileName string) string {
+func GenerateDiff(ctx context.Context, commit *object.Commit, fileName string) string {
+	logger := log.FromContext(ctx)
 	var diff string
 
 	// First grab the first parent of the commit. If there are none, we are at the first commit and should diff against
 	// an empty file.
 	parent, err := commit.Parent(0)
 	if err != object.ErrParentNotFound && err != nil {
-		logrus.WithError(err).Errorf("could not find parent of %s", commit.Hash.String())
+		logger.Error(err, "could not find parent of commit", "commit", commit.Hash.String())
 	}
 
 	// Now get the files from the commit and its parent.
@@ -146,13 +158,13 @@ func GenerateDiff(commit *object.Commit, fileName string) string {
 	if parent != nil {
 		parentFile, err = parent.File(fileName)
 		if err != nil && err != object.ErrFileNotFound {
-			logrus.WithError(err).Errorf("could not get previous version of file: %q", fileName)
+			logger.Error(err, "could not get previous version of file", "file", fileName)
 			return diff
 		}
 	}
 	commitFile, err := commit.File(fileName)
 	if err != nil {
-		logrus.WithError(err).Errorf("could not get current version of file: %q", fileName)
+		logger.Error(err, "could not get current version of file", "file", fileName)
 		return diff
 	}
 
@@ -163,14 +175,14 @@ func GenerateDiff(commit *object.Commit, fileName string) string {
 	if parentFile != nil {
 		oldContent, err = parentFile.Contents()
 		if err != nil {
-			logrus.WithError(err).Errorf("could not get contents of previous version of file: %q", fileName)
+			logger.Error(err, "could not get contents of previous version of file", "file", fileName)
 		}
 	}
 	// commitFile should never be nil at this point, but double-checking so we don't get a nil error.
 	if commitFile != nil {
 		newContent, _ = commitFile.Contents()
 		if err != nil {
-			logrus.WithError(err).Errorf("could not get contents of current version of file: %q", fileName)
+			logger.Error(err, "could not get contents of current version of file", "file", fileName)
 		}
 	}
 
@@ -183,7 +195,7 @@ func GenerateDiff(commit *object.Commit, fileName string) string {
 		// The String() method URL escapes the diff, so it needs to be undone.
 		patchDiff, err := url.QueryUnescape(patch.String())
 		if err != nil {
-			logrus.WithError(err).Error("unable to unescape diff")
+			logger.Error(err, "unable to unescape diff")
 		}
 		diff += patchDiff
 	}
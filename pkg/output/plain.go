@@ -6,9 +6,10 @@ import (
 	"strings"
 
 	"github.com/fatih/color"
-	"github.com/sirupsen/logrus"
+	"github.com/go-errors/errors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
 )
 
 var (
@@ -17,7 +18,7 @@ var (
 	whitePrinter  = color.New(color.FgWhite)
 )
 
-func PrintPlainOutput(r *detectors.ResultWithMetadata) {
+func PrintPlainOutput(r *detectors.ResultWithMetadata) error {
 	out := outputFormat{
 		DetectorType: r.Result.DetectorType.String(),
 		Verified:     r.Result.Verified,
@@ -27,7 +28,7 @@ func PrintPlainOutput(r *detectors.ResultWithMetadata) {
 
 	meta, err := structToMap(out.MetaData.Data)
 	if err != nil {
-		logrus.WithError(err).Fatal("could not marshal result")
+		return errors.WrapPrefix(err, "could not marshal result", 0)
 	}
 
 	printer := greenPrinter
@@ -45,7 +46,17 @@ func PrintPlainOutput(r *detectors.ResultWithMetadata) {
 			printer.Printf("%s: %v\n", strings.Title(k), v)
 		}
 	}
+	if r.Tenant != "" {
+		printer.Printf("Tenant: %s\n", r.Tenant)
+	}
+	if len(r.Provenance) > 0 {
+		printer.Printf("Provenance: %s\n", sources.ProvenancePath(r.Provenance))
+	}
+	if context, ok := r.ExtraData[detectors.SnippetKey]; ok {
+		printer.Printf("Context:\n    %s\n", strings.ReplaceAll(context, "\n", "\n    "))
+	}
 	fmt.Println("")
+	return nil
 }
 
 func structToMap(obj interface{}) (m map[string]map[string]interface{}, err error) {
@@ -235,6 +235,108 @@ var _ interface {
 	ErrorName() string
 } = CloudEnvironmentValidationError{}
 
+// Validate checks the field values on Ambient with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Ambient) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Ambient with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in AmbientMultiError, or nil if none found.
+func (m *Ambient) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Ambient) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for RoleArn
+
+	// no validation rules for ClientId
+
+	if len(errors) > 0 {
+		return AmbientMultiError(errors)
+	}
+
+	return nil
+}
+
+// AmbientMultiError is an error wrapping multiple validation errors returned
+// by Ambient.ValidateAll() if the designated constraints aren't met.
+type AmbientMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m AmbientMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m AmbientMultiError) AllErrors() []error { return m }
+
+// AmbientValidationError is the validation error returned by Ambient.Validate
+// if the designated constraints aren't met.
+type AmbientValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e AmbientValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e AmbientValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e AmbientValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e AmbientValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e AmbientValidationError) ErrorName() string { return "AmbientValidationError" }
+
+// Error satisfies the builtin error interface
+func (e AmbientValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sAmbient.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = AmbientValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = AmbientValidationError{}
+
 // Validate checks the field values on BasicAuth with the rules defined in the
 // proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
@@ -1202,3 +1304,222 @@ var _ interface {
 	Cause() error
 	ErrorName() string
 } = GitHubAppValidationError{}
+
+// Validate checks the field values on TLSConfig with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *TLSConfig) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on TLSConfig with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in TLSConfigMultiError, or nil
+// if none found.
+func (m *TLSConfig) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *TLSConfig) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for CaPath
+
+	// no validation rules for CaPem
+
+	// no validation rules for ClientCertPath
+
+	// no validation rules for ClientKeyPath
+
+	// no validation rules for ClientCertPem
+
+	// no validation rules for ClientKeyPem
+
+	// no validation rules for MinVersion
+
+	// no validation rules for InsecureSkipVerify
+
+	if len(errors) > 0 {
+		return TLSConfigMultiError(errors)
+	}
+
+	return nil
+}
+
+// TLSConfigMultiError is an error wrapping multiple validation errors returned
+// by TLSConfig.ValidateAll() if the designated constraints aren't met.
+type TLSConfigMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m TLSConfigMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m TLSConfigMultiError) AllErrors() []error { return m }
+
+// TLSConfigValidationError is the validation error returned by
+// TLSConfig.Validate if the designated constraints aren't met.
+type TLSConfigValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e TLSConfigValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e TLSConfigValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e TLSConfigValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e TLSConfigValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e TLSConfigValidationError) ErrorName() string { return "TLSConfigValidationError" }
+
+// Error satisfies the builtin error interface
+func (e TLSConfigValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sTLSConfig.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = TLSConfigValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = TLSConfigValidationError{}
+
+// Validate checks the field values on Proxy with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Proxy) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Proxy with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in ProxyMultiError, or nil if none found.
+func (m *Proxy) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Proxy) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Url
+
+	// no validation rules for Username
+
+	// no validation rules for Password
+
+	if len(errors) > 0 {
+		return ProxyMultiError(errors)
+	}
+
+	return nil
+}
+
+// ProxyMultiError is an error wrapping multiple validation errors returned by
+// Proxy.ValidateAll() if the designated constraints aren't met.
+type ProxyMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ProxyMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ProxyMultiError) AllErrors() []error { return m }
+
+// ProxyValidationError is the validation error returned by Proxy.Validate if
+// the designated constraints aren't met.
+type ProxyValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ProxyValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ProxyValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ProxyValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ProxyValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ProxyValidationError) ErrorName() string { return "ProxyValidationError" }
+
+// Error satisfies the builtin error interface
+func (e ProxyValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sProxy.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ProxyValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ProxyValidationError{}
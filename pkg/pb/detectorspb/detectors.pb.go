@@ -92,7 +92,7 @@ const (
 	DetectorType_FastlyPersonalToken           DetectorType = 68
 	DetectorType_GoogleOauth2                  DetectorType = 69
 	DetectorType_ReCAPTCHA                     DetectorType = 70 // Didn't do
-	DetectorType_GoogleApiKey                  DetectorType = 71 // Didn't do
+	DetectorType_GoogleApiKey                  DetectorType = 71
 	DetectorType_Hunter                        DetectorType = 72
 	DetectorType_IbmCloudUserKey               DetectorType = 73
 	DetectorType_Netlify                       DetectorType = 74
@@ -891,6 +891,20 @@ const (
 	DetectorType_Heatmapapi                    DetectorType = 869
 	DetectorType_Websitepulse                  DetectorType = 870
 	DetectorType_Uclassify                     DetectorType = 871
+	DetectorType_HashiCorpVault                DetectorType = 872
+	DetectorType_PasswordManagerExport         DetectorType = 873
+	DetectorType_OAuthBearerToken              DetectorType = 874
+	DetectorType_PyPI                          DetectorType = 875
+	DetectorType_NuGet                         DetectorType = 876
+	DetectorType_TeamCity                      DetectorType = 877
+	DetectorType_Honeycomb                     DetectorType = 878
+	DetectorType_Shopify                       DetectorType = 879
+	DetectorType_Adyen                         DetectorType = 880
+	DetectorType_LDAP                          DetectorType = 881
+	DetectorType_FTP                           DetectorType = 882
+	DetectorType_SMB                           DetectorType = 883
+	DetectorType_CredentialFile                DetectorType = 884
+	DetectorType_BrowserLogin                  DetectorType = 885
 )
 
 // Enum value maps for DetectorType.
@@ -1764,6 +1778,20 @@ var (
 		869: "Heatmapapi",
 		870: "Websitepulse",
 		871: "Uclassify",
+		872: "HashiCorpVault",
+		873: "PasswordManagerExport",
+		874: "OAuthBearerToken",
+		875: "PyPI",
+		876: "NuGet",
+		877: "TeamCity",
+		878: "Honeycomb",
+		879: "Shopify",
+		880: "Adyen",
+		881: "LDAP",
+		882: "FTP",
+		883: "SMB",
+		884: "CredentialFile",
+		885: "BrowserLogin",
 	}
 	DetectorType_value = map[string]int32{
 		"Alibaba":                       0,
@@ -2634,6 +2662,20 @@ var (
 		"Heatmapapi":                    869,
 		"Websitepulse":                  870,
 		"Uclassify":                     871,
+		"HashiCorpVault":                872,
+		"PasswordManagerExport":         873,
+		"OAuthBearerToken":              874,
+		"PyPI":                          875,
+		"NuGet":                         876,
+		"TeamCity":                      877,
+		"Honeycomb":                     878,
+		"Shopify":                       879,
+		"Adyen":                         880,
+		"LDAP":                          881,
+		"FTP":                           882,
+		"SMB":                           883,
+		"CredentialFile":                884,
+		"BrowserLogin":                  885,
 	}
 )
 
@@ -2973,7 +3015,7 @@ var file_detectors_proto_rawDesc = []byte{
 	0x75, 0x73, 0x65, 0x72, 0x12, 0x34, 0x0a, 0x16, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x5f, 0x6b,
 	0x65, 0x79, 0x5f, 0x66, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x18, 0x02,
 	0x20, 0x01, 0x28, 0x09, 0x52, 0x14, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x46,
-	0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x2a, 0xa2, 0x6d, 0x0a, 0x0c, 0x44,
+	0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x2a, 0x81, 0x6f, 0x0a, 0x0c, 0x44,
 	0x65, 0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x41,
 	0x6c, 0x69, 0x62, 0x61, 0x62, 0x61, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x41, 0x4d, 0x51, 0x50,
 	0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x41, 0x57, 0x53, 0x10, 0x02, 0x12, 0x09, 0x0a, 0x05, 0x41,
@@ -3847,12 +3889,26 @@ var file_detectors_proto_rawDesc = []byte{
 	0x61, 0x6c, 0x65, 0x73, 0x6d, 0x61, 0x74, 0x65, 0x10, 0xe4, 0x06, 0x12, 0x0f, 0x0a, 0x0a, 0x48,
 	0x65, 0x61, 0x74, 0x6d, 0x61, 0x70, 0x61, 0x70, 0x69, 0x10, 0xe5, 0x06, 0x12, 0x11, 0x0a, 0x0c,
 	0x57, 0x65, 0x62, 0x73, 0x69, 0x74, 0x65, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x10, 0xe6, 0x06, 0x12,
-	0x0e, 0x0a, 0x09, 0x55, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x69, 0x66, 0x79, 0x10, 0xe7, 0x06, 0x42,
-	0x3d, 0x5a, 0x3b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x74, 0x72,
-	0x75, 0x66, 0x66, 0x6c, 0x65, 0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x2f, 0x74, 0x72,
-	0x75, 0x66, 0x66, 0x6c, 0x65, 0x68, 0x6f, 0x67, 0x2f, 0x76, 0x33, 0x2f, 0x70, 0x6b, 0x67, 0x2f,
-	0x70, 0x62, 0x2f, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x70, 0x62, 0x62, 0x06,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x0e, 0x0a, 0x09, 0x55, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x69, 0x66, 0x79, 0x10, 0xe7, 0x06, 0x12,
+	0x13, 0x0a, 0x0e, 0x48, 0x61, 0x73, 0x68, 0x69, 0x43, 0x6f, 0x72, 0x70, 0x56, 0x61, 0x75, 0x6c,
+	0x74, 0x10, 0xe8, 0x06, 0x12, 0x1a, 0x0a, 0x15, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64,
+	0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x10, 0xe9, 0x06,
+	0x12, 0x15, 0x0a, 0x10, 0x4f, 0x41, 0x75, 0x74, 0x68, 0x42, 0x65, 0x61, 0x72, 0x65, 0x72, 0x54,
+	0x6f, 0x6b, 0x65, 0x6e, 0x10, 0xea, 0x06, 0x12, 0x09, 0x0a, 0x04, 0x50, 0x79, 0x50, 0x49, 0x10,
+	0xeb, 0x06, 0x12, 0x0a, 0x0a, 0x05, 0x4e, 0x75, 0x47, 0x65, 0x74, 0x10, 0xec, 0x06, 0x12, 0x0d,
+	0x0a, 0x08, 0x54, 0x65, 0x61, 0x6d, 0x43, 0x69, 0x74, 0x79, 0x10, 0xed, 0x06, 0x12, 0x0e, 0x0a,
+	0x09, 0x48, 0x6f, 0x6e, 0x65, 0x79, 0x63, 0x6f, 0x6d, 0x62, 0x10, 0xee, 0x06, 0x12, 0x0c, 0x0a,
+	0x07, 0x53, 0x68, 0x6f, 0x70, 0x69, 0x66, 0x79, 0x10, 0xef, 0x06, 0x12, 0x0a, 0x0a, 0x05, 0x41,
+	0x64, 0x79, 0x65, 0x6e, 0x10, 0xf0, 0x06, 0x12, 0x09, 0x0a, 0x04, 0x4c, 0x44, 0x41, 0x50, 0x10,
+	0xf1, 0x06, 0x12, 0x08, 0x0a, 0x03, 0x46, 0x54, 0x50, 0x10, 0xf2, 0x06, 0x12, 0x08, 0x0a, 0x03,
+	0x53, 0x4d, 0x42, 0x10, 0xf3, 0x06, 0x12, 0x13, 0x0a, 0x0e, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e,
+	0x74, 0x69, 0x61, 0x6c, 0x46, 0x69, 0x6c, 0x65, 0x10, 0xf4, 0x06, 0x12, 0x11, 0x0a, 0x0c, 0x42,
+	0x72, 0x6f, 0x77, 0x73, 0x65, 0x72, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x10, 0xf5, 0x06, 0x42, 0x3d,
+	0x5a, 0x3b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x74, 0x72, 0x75,
+	0x66, 0x66, 0x6c, 0x65, 0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x2f, 0x74, 0x72, 0x75,
+	0x66, 0x66, 0x6c, 0x65, 0x68, 0x6f, 0x67, 0x2f, 0x76, 0x33, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70,
+	0x62, 0x2f, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x70, 0x62, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -958,6 +958,8 @@ func (m *Git) validate(all bool) error {
 
 	// no validation rules for Line
 
+	// no validation rules for Tag
+
 	if len(errors) > 0 {
 		return GitMultiError(errors)
 	}
@@ -1072,6 +1074,8 @@ func (m *Github) validate(all bool) error {
 
 	// no validation rules for Line
 
+	// no validation rules for Tag
+
 	if len(errors) > 0 {
 		return GithubMultiError(errors)
 	}
@@ -1184,6 +1188,8 @@ func (m *Gitlab) validate(all bool) error {
 
 	// no validation rules for Line
 
+	// no validation rules for Tag
+
 	if len(errors) > 0 {
 		return GitlabMultiError(errors)
 	}
@@ -2485,6 +2491,8 @@ func (m *Syslog) validate(all bool) error {
 
 	// no validation rules for Facility
 
+	// no validation rules for ClientHostname
+
 	if len(errors) > 0 {
 		return SyslogMultiError(errors)
 	}
@@ -2562,286 +2570,1796 @@ var _ interface {
 	ErrorName() string
 } = SyslogValidationError{}
 
-// Validate checks the field values on MetaData with the rules defined in the
+// Validate checks the field values on Vault with the rules defined in the
 // proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
-func (m *MetaData) Validate() error {
+func (m *Vault) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on MetaData with the rules defined in
-// the proto definition for this message. If any rules are violated, the
-// result is a list of violation errors wrapped in MetaDataMultiError, or nil
-// if none found.
-func (m *MetaData) ValidateAll() error {
+// ValidateAll checks the field values on Vault with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in VaultMultiError, or nil if none found.
+func (m *Vault) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *MetaData) validate(all bool) error {
+func (m *Vault) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	switch m.Data.(type) {
+	// no validation rules for Address
 
-	case *MetaData_Azure:
+	// no validation rules for Mount
 
-		if all {
-			switch v := interface{}(m.GetAzure()).(type) {
-			case interface{ ValidateAll() error }:
-				if err := v.ValidateAll(); err != nil {
-					errors = append(errors, MetaDataValidationError{
-						field:  "Azure",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			case interface{ Validate() error }:
-				if err := v.Validate(); err != nil {
-					errors = append(errors, MetaDataValidationError{
-						field:  "Azure",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			}
-		} else if v, ok := interface{}(m.GetAzure()).(interface{ Validate() error }); ok {
-			if err := v.Validate(); err != nil {
-				return MetaDataValidationError{
-					field:  "Azure",
-					reason: "embedded message failed validation",
-					cause:  err,
-				}
-			}
-		}
+	// no validation rules for Path
 
-	case *MetaData_Bitbucket:
+	// no validation rules for File
 
-		if all {
-			switch v := interface{}(m.GetBitbucket()).(type) {
-			case interface{ ValidateAll() error }:
-				if err := v.ValidateAll(); err != nil {
-					errors = append(errors, MetaDataValidationError{
-						field:  "Bitbucket",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			case interface{ Validate() error }:
-				if err := v.Validate(); err != nil {
-					errors = append(errors, MetaDataValidationError{
-						field:  "Bitbucket",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			}
-		} else if v, ok := interface{}(m.GetBitbucket()).(interface{ Validate() error }); ok {
-			if err := v.Validate(); err != nil {
-				return MetaDataValidationError{
-					field:  "Bitbucket",
-					reason: "embedded message failed validation",
-					cause:  err,
-				}
-			}
-		}
+	// no validation rules for Line
 
-	case *MetaData_Circleci:
+	// no validation rules for Timestamp
 
-		if all {
-			switch v := interface{}(m.GetCircleci()).(type) {
-			case interface{ ValidateAll() error }:
-				if err := v.ValidateAll(); err != nil {
-					errors = append(errors, MetaDataValidationError{
-						field:  "Circleci",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			case interface{ Validate() error }:
-				if err := v.Validate(); err != nil {
-					errors = append(errors, MetaDataValidationError{
-						field:  "Circleci",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			}
-		} else if v, ok := interface{}(m.GetCircleci()).(interface{ Validate() error }); ok {
-			if err := v.Validate(); err != nil {
-				return MetaDataValidationError{
-					field:  "Circleci",
-					reason: "embedded message failed validation",
-					cause:  err,
-				}
-			}
-		}
+	if len(errors) > 0 {
+		return VaultMultiError(errors)
+	}
 
-	case *MetaData_Confluence:
+	return nil
+}
 
-		if all {
-			switch v := interface{}(m.GetConfluence()).(type) {
-			case interface{ ValidateAll() error }:
-				if err := v.ValidateAll(); err != nil {
-					errors = append(errors, MetaDataValidationError{
-						field:  "Confluence",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			case interface{ Validate() error }:
-				if err := v.Validate(); err != nil {
-					errors = append(errors, MetaDataValidationError{
-						field:  "Confluence",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			}
-		} else if v, ok := interface{}(m.GetConfluence()).(interface{ Validate() error }); ok {
-			if err := v.Validate(); err != nil {
-				return MetaDataValidationError{
-					field:  "Confluence",
-					reason: "embedded message failed validation",
-					cause:  err,
-				}
-			}
-		}
+// VaultMultiError is an error wrapping multiple validation errors returned by
+// Vault.ValidateAll() if the designated constraints aren't met.
+type VaultMultiError []error
 
-	case *MetaData_Dockerhub:
+// Error returns a concatenation of all the error messages it wraps.
+func (m VaultMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
 
-		if all {
-			switch v := interface{}(m.GetDockerhub()).(type) {
-			case interface{ ValidateAll() error }:
-				if err := v.ValidateAll(); err != nil {
-					errors = append(errors, MetaDataValidationError{
-						field:  "Dockerhub",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			case interface{ Validate() error }:
-				if err := v.Validate(); err != nil {
-					errors = append(errors, MetaDataValidationError{
-						field:  "Dockerhub",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			}
-		} else if v, ok := interface{}(m.GetDockerhub()).(interface{ Validate() error }); ok {
-			if err := v.Validate(); err != nil {
-				return MetaDataValidationError{
-					field:  "Dockerhub",
-					reason: "embedded message failed validation",
-					cause:  err,
-				}
-			}
-		}
+// AllErrors returns a list of validation violation errors.
+func (m VaultMultiError) AllErrors() []error { return m }
 
-	case *MetaData_Ecr:
+// VaultValidationError is the validation error returned by Vault.Validate if
+// the designated constraints aren't met.
+type VaultValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
 
-		if all {
-			switch v := interface{}(m.GetEcr()).(type) {
-			case interface{ ValidateAll() error }:
-				if err := v.ValidateAll(); err != nil {
-					errors = append(errors, MetaDataValidationError{
-						field:  "Ecr",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			case interface{ Validate() error }:
-				if err := v.Validate(); err != nil {
-					errors = append(errors, MetaDataValidationError{
-						field:  "Ecr",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			}
-		} else if v, ok := interface{}(m.GetEcr()).(interface{ Validate() error }); ok {
-			if err := v.Validate(); err != nil {
-				return MetaDataValidationError{
-					field:  "Ecr",
-					reason: "embedded message failed validation",
-					cause:  err,
-				}
-			}
-		}
+// Field function returns field value.
+func (e VaultValidationError) Field() string { return e.field }
 
-	case *MetaData_Gcs:
+// Reason function returns reason value.
+func (e VaultValidationError) Reason() string { return e.reason }
 
-		if all {
-			switch v := interface{}(m.GetGcs()).(type) {
-			case interface{ ValidateAll() error }:
-				if err := v.ValidateAll(); err != nil {
-					errors = append(errors, MetaDataValidationError{
-						field:  "Gcs",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			case interface{ Validate() error }:
-				if err := v.Validate(); err != nil {
-					errors = append(errors, MetaDataValidationError{
-						field:  "Gcs",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			}
-		} else if v, ok := interface{}(m.GetGcs()).(interface{ Validate() error }); ok {
-			if err := v.Validate(); err != nil {
-				return MetaDataValidationError{
-					field:  "Gcs",
-					reason: "embedded message failed validation",
-					cause:  err,
-				}
-			}
-		}
+// Cause function returns cause value.
+func (e VaultValidationError) Cause() error { return e.cause }
 
-	case *MetaData_Github:
+// Key function returns key value.
+func (e VaultValidationError) Key() bool { return e.key }
 
-		if all {
-			switch v := interface{}(m.GetGithub()).(type) {
-			case interface{ ValidateAll() error }:
-				if err := v.ValidateAll(); err != nil {
-					errors = append(errors, MetaDataValidationError{
-						field:  "Github",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
+// ErrorName returns error name.
+func (e VaultValidationError) ErrorName() string { return "VaultValidationError" }
+
+// Error satisfies the builtin error interface
+func (e VaultValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sVault.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = VaultValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = VaultValidationError{}
+
+// Validate checks the field values on WindowsEventLog with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// first error encountered is returned, or nil if there are no violations.
+func (m *WindowsEventLog) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on WindowsEventLog with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// WindowsEventLogMultiError, or nil if none found.
+func (m *WindowsEventLog) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *WindowsEventLog) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Channel
+
+	// no validation rules for EventId
+
+	// no validation rules for RecordId
+
+	// no validation rules for Provider
+
+	// no validation rules for Computer
+
+	// no validation rules for Timestamp
+
+	// no validation rules for File
+
+	if len(errors) > 0 {
+		return WindowsEventLogMultiError(errors)
+	}
+
+	return nil
+}
+
+// WindowsEventLogMultiError is an error wrapping multiple validation errors
+// returned by WindowsEventLog.ValidateAll() if the designated constraints
+// aren't met.
+type WindowsEventLogMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m WindowsEventLogMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m WindowsEventLogMultiError) AllErrors() []error { return m }
+
+// WindowsEventLogValidationError is the validation error returned by
+// WindowsEventLog.Validate if the designated constraints aren't met.
+type WindowsEventLogValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e WindowsEventLogValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e WindowsEventLogValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e WindowsEventLogValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e WindowsEventLogValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e WindowsEventLogValidationError) ErrorName() string { return "WindowsEventLogValidationError" }
+
+// Error satisfies the builtin error interface
+func (e WindowsEventLogValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sWindowsEventLog.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = WindowsEventLogValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = WindowsEventLogValidationError{}
+
+// Validate checks the field values on Auditd with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Auditd) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Auditd with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in AuditdMultiError, or nil if none found.
+func (m *Auditd) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Auditd) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for File
+
+	// no validation rules for Serial
+
+	// no validation rules for Timestamp
+
+	if len(errors) > 0 {
+		return AuditdMultiError(errors)
+	}
+
+	return nil
+}
+
+// AuditdMultiError is an error wrapping multiple validation errors returned by
+// Auditd.ValidateAll() if the designated constraints aren't met.
+type AuditdMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m AuditdMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m AuditdMultiError) AllErrors() []error { return m }
+
+// AuditdValidationError is the validation error returned by Auditd.Validate if
+// the designated constraints aren't met.
+type AuditdValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e AuditdValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e AuditdValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e AuditdValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e AuditdValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e AuditdValidationError) ErrorName() string { return "AuditdValidationError" }
+
+// Error satisfies the builtin error interface
+func (e AuditdValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sAuditd.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = AuditdValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = AuditdValidationError{}
+
+// Validate checks the field values on UnifiedLog with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *UnifiedLog) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on UnifiedLog with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in UnifiedLogMultiError, or
+// nil if none found.
+func (m *UnifiedLog) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *UnifiedLog) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for File
+
+	// no validation rules for Timestamp
+
+	// no validation rules for Process
+
+	// no validation rules for ProcessId
+
+	// no validation rules for Subsystem
+
+	// no validation rules for Category
+
+	if len(errors) > 0 {
+		return UnifiedLogMultiError(errors)
+	}
+
+	return nil
+}
+
+// UnifiedLogMultiError is an error wrapping multiple validation errors
+// returned by UnifiedLog.ValidateAll() if the designated constraints aren't met.
+type UnifiedLogMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m UnifiedLogMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m UnifiedLogMultiError) AllErrors() []error { return m }
+
+// UnifiedLogValidationError is the validation error returned by
+// UnifiedLog.Validate if the designated constraints aren't met.
+type UnifiedLogValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e UnifiedLogValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e UnifiedLogValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e UnifiedLogValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e UnifiedLogValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e UnifiedLogValidationError) ErrorName() string { return "UnifiedLogValidationError" }
+
+// Error satisfies the builtin error interface
+func (e UnifiedLogValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sUnifiedLog.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = UnifiedLogValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = UnifiedLogValidationError{}
+
+// Validate checks the field values on MobileApp with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *MobileApp) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on MobileApp with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in MobileAppMultiError, or nil
+// if none found.
+func (m *MobileApp) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *MobileApp) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Artifact
+
+	// no validation rules for Platform
+
+	// no validation rules for Package
+
+	// no validation rules for Version
+
+	// no validation rules for File
+
+	if len(errors) > 0 {
+		return MobileAppMultiError(errors)
+	}
+
+	return nil
+}
+
+// MobileAppMultiError is an error wrapping multiple validation errors returned
+// by MobileApp.ValidateAll() if the designated constraints aren't met.
+type MobileAppMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m MobileAppMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m MobileAppMultiError) AllErrors() []error { return m }
+
+// MobileAppValidationError is the validation error returned by
+// MobileApp.Validate if the designated constraints aren't met.
+type MobileAppValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e MobileAppValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e MobileAppValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e MobileAppValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e MobileAppValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e MobileAppValidationError) ErrorName() string { return "MobileAppValidationError" }
+
+// Error satisfies the builtin error interface
+func (e MobileAppValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sMobileApp.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = MobileAppValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = MobileAppValidationError{}
+
+// Validate checks the field values on ServiceBanner with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *ServiceBanner) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ServiceBanner with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in ServiceBannerMultiError, or
+// nil if none found.
+func (m *ServiceBanner) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ServiceBanner) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Ip
+
+	// no validation rules for Port
+
+	// no validation rules for Hostname
+
+	// no validation rules for Provider
+
+	// no validation rules for Timestamp
+
+	// no validation rules for Link
+
+	if len(errors) > 0 {
+		return ServiceBannerMultiError(errors)
+	}
+
+	return nil
+}
+
+// ServiceBannerMultiError is an error wrapping multiple validation errors
+// returned by ServiceBanner.ValidateAll() if the designated constraints
+// aren't met.
+type ServiceBannerMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ServiceBannerMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ServiceBannerMultiError) AllErrors() []error { return m }
+
+// ServiceBannerValidationError is the validation error returned by
+// ServiceBanner.Validate if the designated constraints aren't met.
+type ServiceBannerValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ServiceBannerValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ServiceBannerValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ServiceBannerValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ServiceBannerValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ServiceBannerValidationError) ErrorName() string { return "ServiceBannerValidationError" }
+
+// Error satisfies the builtin error interface
+func (e ServiceBannerValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sServiceBanner.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ServiceBannerValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ServiceBannerValidationError{}
+
+// Validate checks the field values on DNS with the rules defined in the proto
+// definition for this message. If any rules are violated, the first error
+// encountered is returned, or nil if there are no violations.
+func (m *DNS) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on DNS with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in DNSMultiError, or nil if none found.
+func (m *DNS) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *DNS) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Name
+
+	// no validation rules for Type
+
+	// no validation rules for Zone
+
+	// no validation rules for Nameserver
+
+	if len(errors) > 0 {
+		return DNSMultiError(errors)
+	}
+
+	return nil
+}
+
+// DNSMultiError is an error wrapping multiple validation errors returned by
+// DNS.ValidateAll() if the designated constraints aren't met.
+type DNSMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m DNSMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m DNSMultiError) AllErrors() []error { return m }
+
+// DNSValidationError is the validation error returned by DNS.Validate if the
+// designated constraints aren't met.
+type DNSValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DNSValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DNSValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DNSValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DNSValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DNSValidationError) ErrorName() string { return "DNSValidationError" }
+
+// Error satisfies the builtin error interface
+func (e DNSValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDNS.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DNSValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DNSValidationError{}
+
+// Validate checks the field values on FileShare with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *FileShare) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on FileShare with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in FileShareMultiError, or nil
+// if none found.
+func (m *FileShare) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *FileShare) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for File
+
+	// no validation rules for Link
+
+	// no validation rules for Host
+
+	// no validation rules for Protocol
+
+	// no validation rules for Timestamp
+
+	if len(errors) > 0 {
+		return FileShareMultiError(errors)
+	}
+
+	return nil
+}
+
+// FileShareMultiError is an error wrapping multiple validation errors returned
+// by FileShare.ValidateAll() if the designated constraints aren't met.
+type FileShareMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m FileShareMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m FileShareMultiError) AllErrors() []error { return m }
+
+// FileShareValidationError is the validation error returned by
+// FileShare.Validate if the designated constraints aren't met.
+type FileShareValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e FileShareValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e FileShareValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e FileShareValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e FileShareValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e FileShareValidationError) ErrorName() string { return "FileShareValidationError" }
+
+// Error satisfies the builtin error interface
+func (e FileShareValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sFileShare.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = FileShareValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = FileShareValidationError{}
+
+// Validate checks the field values on SSHSweep with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *SSHSweep) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on SSHSweep with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in SSHSweepMultiError, or nil
+// if none found.
+func (m *SSHSweep) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *SSHSweep) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for File
+
+	// no validation rules for Host
+
+	// no validation rules for Timestamp
+
+	if len(errors) > 0 {
+		return SSHSweepMultiError(errors)
+	}
+
+	return nil
+}
+
+// SSHSweepMultiError is an error wrapping multiple validation errors returned
+// by SSHSweep.ValidateAll() if the designated constraints aren't met.
+type SSHSweepMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m SSHSweepMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m SSHSweepMultiError) AllErrors() []error { return m }
+
+// SSHSweepValidationError is the validation error returned by
+// SSHSweep.Validate if the designated constraints aren't met.
+type SSHSweepValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e SSHSweepValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e SSHSweepValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e SSHSweepValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e SSHSweepValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e SSHSweepValidationError) ErrorName() string { return "SSHSweepValidationError" }
+
+// Error satisfies the builtin error interface
+func (e SSHSweepValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sSSHSweep.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = SSHSweepValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = SSHSweepValidationError{}
+
+// Validate checks the field values on Patch with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Patch) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Patch with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in PatchMultiError, or nil if none found.
+func (m *Patch) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Patch) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Patch
+
+	// no validation rules for File
+
+	// no validation rules for Line
+
+	// no validation rules for Commit
+
+	// no validation rules for Email
+
+	// no validation rules for Timestamp
+
+	if len(errors) > 0 {
+		return PatchMultiError(errors)
+	}
+
+	return nil
+}
+
+// PatchMultiError is an error wrapping multiple validation errors returned by
+// Patch.ValidateAll() if the designated constraints aren't met.
+type PatchMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m PatchMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m PatchMultiError) AllErrors() []error { return m }
+
+// PatchValidationError is the validation error returned by Patch.Validate if
+// the designated constraints aren't met.
+type PatchValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e PatchValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e PatchValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e PatchValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e PatchValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e PatchValidationError) ErrorName() string { return "PatchValidationError" }
+
+// Error satisfies the builtin error interface
+func (e PatchValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sPatch.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = PatchValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = PatchValidationError{}
+
+// Validate checks the field values on ICAP with the rules defined in the proto
+// definition for this message. If any rules are violated, the first error
+// encountered is returned, or nil if there are no violations.
+func (m *ICAP) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ICAP with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in ICAPMultiError, or nil if none found.
+func (m *ICAP) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ICAP) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Url
+
+	// no validation rules for Method
+
+	// no validation rules for ClientIp
+
+	// no validation rules for User
+
+	// no validation rules for Timestamp
+
+	// no validation rules for Blocked
+
+	if len(errors) > 0 {
+		return ICAPMultiError(errors)
+	}
+
+	return nil
+}
+
+// ICAPMultiError is an error wrapping multiple validation errors returned by
+// ICAP.ValidateAll() if the designated constraints aren't met.
+type ICAPMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ICAPMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ICAPMultiError) AllErrors() []error { return m }
+
+// ICAPValidationError is the validation error returned by ICAP.Validate if the
+// designated constraints aren't met.
+type ICAPValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ICAPValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ICAPValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ICAPValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ICAPValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ICAPValidationError) ErrorName() string { return "ICAPValidationError" }
+
+// Error satisfies the builtin error interface
+func (e ICAPValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sICAP.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ICAPValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ICAPValidationError{}
+
+// Validate checks the field values on MetaData with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *MetaData) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on MetaData with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in MetaDataMultiError, or nil
+// if none found.
+func (m *MetaData) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *MetaData) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	switch m.Data.(type) {
+
+	case *MetaData_Azure:
+
+		if all {
+			switch v := interface{}(m.GetAzure()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Azure",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Azure",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetAzure()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Azure",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Bitbucket:
+
+		if all {
+			switch v := interface{}(m.GetBitbucket()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Bitbucket",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Bitbucket",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetBitbucket()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Bitbucket",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Circleci:
+
+		if all {
+			switch v := interface{}(m.GetCircleci()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Circleci",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Circleci",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetCircleci()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Circleci",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Confluence:
+
+		if all {
+			switch v := interface{}(m.GetConfluence()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Confluence",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Confluence",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetConfluence()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Confluence",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Dockerhub:
+
+		if all {
+			switch v := interface{}(m.GetDockerhub()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Dockerhub",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Dockerhub",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetDockerhub()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Dockerhub",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Ecr:
+
+		if all {
+			switch v := interface{}(m.GetEcr()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Ecr",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Ecr",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetEcr()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Ecr",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Gcs:
+
+		if all {
+			switch v := interface{}(m.GetGcs()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Gcs",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Gcs",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetGcs()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Gcs",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Github:
+
+		if all {
+			switch v := interface{}(m.GetGithub()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Github",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Github",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetGithub()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Github",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Gitlab:
+
+		if all {
+			switch v := interface{}(m.GetGitlab()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Gitlab",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Gitlab",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetGitlab()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Gitlab",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Jira:
+
+		if all {
+			switch v := interface{}(m.GetJira()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Jira",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Jira",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetJira()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Jira",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Npm:
+
+		if all {
+			switch v := interface{}(m.GetNpm()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Npm",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Npm",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetNpm()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Npm",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Pypi:
+
+		if all {
+			switch v := interface{}(m.GetPypi()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Pypi",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Pypi",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetPypi()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Pypi",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_S3:
+
+		if all {
+			switch v := interface{}(m.GetS3()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "S3",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "S3",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetS3()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "S3",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Slack:
+
+		if all {
+			switch v := interface{}(m.GetSlack()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Slack",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Slack",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetSlack()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Slack",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Filesystem:
+
+		if all {
+			switch v := interface{}(m.GetFilesystem()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Filesystem",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Filesystem",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetFilesystem()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Filesystem",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Git:
+
+		if all {
+			switch v := interface{}(m.GetGit()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Git",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Git",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetGit()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Git",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Test:
+
+		if all {
+			switch v := interface{}(m.GetTest()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Test",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Test",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetTest()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Test",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Buildkite:
+
+		if all {
+			switch v := interface{}(m.GetBuildkite()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Buildkite",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
 				}
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Github",
+						field:  "Buildkite",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetGithub()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetBuildkite()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Github",
+					field:  "Buildkite",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Gitlab:
+	case *MetaData_Gerrit:
 
 		if all {
-			switch v := interface{}(m.GetGitlab()).(type) {
+			switch v := interface{}(m.GetGerrit()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Gitlab",
+						field:  "Gerrit",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -2849,30 +4367,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Gitlab",
+						field:  "Gerrit",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetGitlab()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetGerrit()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Gitlab",
+					field:  "Gerrit",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Jira:
+	case *MetaData_Jenkins:
 
 		if all {
-			switch v := interface{}(m.GetJira()).(type) {
+			switch v := interface{}(m.GetJenkins()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Jira",
+						field:  "Jenkins",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -2880,30 +4398,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Jira",
+						field:  "Jenkins",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetJira()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetJenkins()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Jira",
+					field:  "Jenkins",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Npm:
+	case *MetaData_Teams:
 
 		if all {
-			switch v := interface{}(m.GetNpm()).(type) {
+			switch v := interface{}(m.GetTeams()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Npm",
+						field:  "Teams",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -2911,30 +4429,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Npm",
+						field:  "Teams",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetNpm()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetTeams()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Npm",
+					field:  "Teams",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Pypi:
+	case *MetaData_Artifactory:
 
 		if all {
-			switch v := interface{}(m.GetPypi()).(type) {
+			switch v := interface{}(m.GetArtifactory()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Pypi",
+						field:  "Artifactory",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -2942,30 +4460,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Pypi",
+						field:  "Artifactory",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetPypi()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetArtifactory()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Pypi",
+					field:  "Artifactory",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_S3:
+	case *MetaData_Syslog:
 
 		if all {
-			switch v := interface{}(m.GetS3()).(type) {
+			switch v := interface{}(m.GetSyslog()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "S3",
+						field:  "Syslog",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -2973,30 +4491,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "S3",
+						field:  "Syslog",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetS3()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetSyslog()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "S3",
+					field:  "Syslog",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Slack:
+	case *MetaData_Vault:
 
 		if all {
-			switch v := interface{}(m.GetSlack()).(type) {
+			switch v := interface{}(m.GetVault()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Slack",
+						field:  "Vault",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3004,30 +4522,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Slack",
+						field:  "Vault",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetSlack()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetVault()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Slack",
+					field:  "Vault",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Filesystem:
+	case *MetaData_WindowsEventLog:
 
 		if all {
-			switch v := interface{}(m.GetFilesystem()).(type) {
+			switch v := interface{}(m.GetWindowsEventLog()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Filesystem",
+						field:  "WindowsEventLog",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3035,30 +4553,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Filesystem",
+						field:  "WindowsEventLog",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetFilesystem()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetWindowsEventLog()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Filesystem",
+					field:  "WindowsEventLog",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Git:
+	case *MetaData_Auditd:
 
 		if all {
-			switch v := interface{}(m.GetGit()).(type) {
+			switch v := interface{}(m.GetAuditd()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Git",
+						field:  "Auditd",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3066,30 +4584,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Git",
+						field:  "Auditd",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetGit()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetAuditd()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Git",
+					field:  "Auditd",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Test:
+	case *MetaData_UnifiedLog:
 
 		if all {
-			switch v := interface{}(m.GetTest()).(type) {
+			switch v := interface{}(m.GetUnifiedLog()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Test",
+						field:  "UnifiedLog",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3097,30 +4615,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Test",
+						field:  "UnifiedLog",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetTest()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetUnifiedLog()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Test",
+					field:  "UnifiedLog",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Buildkite:
+	case *MetaData_MobileApp:
 
 		if all {
-			switch v := interface{}(m.GetBuildkite()).(type) {
+			switch v := interface{}(m.GetMobileApp()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Buildkite",
+						field:  "MobileApp",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3128,30 +4646,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Buildkite",
+						field:  "MobileApp",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetBuildkite()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetMobileApp()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Buildkite",
+					field:  "MobileApp",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Gerrit:
+	case *MetaData_Wayback:
 
 		if all {
-			switch v := interface{}(m.GetGerrit()).(type) {
+			switch v := interface{}(m.GetWayback()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Gerrit",
+						field:  "Wayback",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3159,30 +4677,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Gerrit",
+						field:  "Wayback",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetGerrit()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetWayback()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Gerrit",
+					field:  "Wayback",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Jenkins:
+	case *MetaData_ServiceBanner:
 
 		if all {
-			switch v := interface{}(m.GetJenkins()).(type) {
+			switch v := interface{}(m.GetServiceBanner()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Jenkins",
+						field:  "ServiceBanner",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3190,30 +4708,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Jenkins",
+						field:  "ServiceBanner",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetJenkins()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetServiceBanner()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Jenkins",
+					field:  "ServiceBanner",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Teams:
+	case *MetaData_Dns:
 
 		if all {
-			switch v := interface{}(m.GetTeams()).(type) {
+			switch v := interface{}(m.GetDns()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Teams",
+						field:  "Dns",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3221,30 +4739,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Teams",
+						field:  "Dns",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetTeams()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetDns()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Teams",
+					field:  "Dns",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Artifactory:
+	case *MetaData_FileShare:
 
 		if all {
-			switch v := interface{}(m.GetArtifactory()).(type) {
+			switch v := interface{}(m.GetFileShare()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Artifactory",
+						field:  "FileShare",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3252,30 +4770,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Artifactory",
+						field:  "FileShare",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetArtifactory()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetFileShare()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Artifactory",
+					field:  "FileShare",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Syslog:
+	case *MetaData_SshSweep:
 
 		if all {
-			switch v := interface{}(m.GetSyslog()).(type) {
+			switch v := interface{}(m.GetSshSweep()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Syslog",
+						field:  "SshSweep",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3283,16 +4801,78 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Syslog",
+						field:  "SshSweep",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetSyslog()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetSshSweep()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Syslog",
+					field:  "SshSweep",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Patch:
+
+		if all {
+			switch v := interface{}(m.GetPatch()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Patch",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Patch",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetPatch()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Patch",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Icap:
+
+		if all {
+			switch v := interface{}(m.GetIcap()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Icap",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Icap",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetIcap()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Icap",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
@@ -3377,3 +4957,107 @@ var _ interface {
 	Cause() error
 	ErrorName() string
 } = MetaDataValidationError{}
+
+// Validate checks the field values on Wayback with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Wayback) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Wayback with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in WaybackMultiError, or nil if none found.
+func (m *Wayback) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Wayback) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Url
+
+	// no validation rules for Timestamp
+
+	// no validation rules for Link
+
+	if len(errors) > 0 {
+		return WaybackMultiError(errors)
+	}
+
+	return nil
+}
+
+// WaybackMultiError is an error wrapping multiple validation errors returned
+// by Wayback.ValidateAll() if the designated constraints aren't met.
+type WaybackMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m WaybackMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m WaybackMultiError) AllErrors() []error { return m }
+
+// WaybackValidationError is the validation error returned by Wayback.Validate
+// if the designated constraints aren't met.
+type WaybackValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e WaybackValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e WaybackValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e WaybackValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e WaybackValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e WaybackValidationError) ErrorName() string { return "WaybackValidationError" }
+
+// Error satisfies the builtin error interface
+func (e WaybackValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sWayback.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = WaybackValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = WaybackValidationError{}
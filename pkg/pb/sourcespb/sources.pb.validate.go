@@ -1517,6 +1517,64 @@ func (m *GitLab) validate(all bool) error {
 		errors = append(errors, err)
 	}
 
+	if all {
+		switch v := interface{}(m.GetTls()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GitLabValidationError{
+					field:  "Tls",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GitLabValidationError{
+					field:  "Tls",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetTls()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GitLabValidationError{
+				field:  "Tls",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetProxy()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GitLabValidationError{
+					field:  "Proxy",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GitLabValidationError{
+					field:  "Proxy",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetProxy()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GitLabValidationError{
+				field:  "Proxy",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
 	switch m.Credential.(type) {
 
 	case *GitLab_Token:
@@ -1704,6 +1762,74 @@ func (m *GitHub) validate(all bool) error {
 
 	// no validation rules for Base
 
+	// no validation rules for IncludeGists
+
+	// no validation rules for IncludeAuditLog
+
+	// no validation rules for IncludeComments
+
+	// no validation rules for IncludeWikis
+
+	// no validation rules for ScanForkNetwork
+
+	if all {
+		switch v := interface{}(m.GetTls()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GitHubValidationError{
+					field:  "Tls",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GitHubValidationError{
+					field:  "Tls",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetTls()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GitHubValidationError{
+				field:  "Tls",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetProxy()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GitHubValidationError{
+					field:  "Proxy",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GitHubValidationError{
+					field:  "Proxy",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetProxy()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GitHubValidationError{
+				field:  "Proxy",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
 	switch m.Credential.(type) {
 
 	case *GitHub_GithubApp:
@@ -2352,6 +2478,14 @@ func (m *S3) validate(all bool) error {
 
 	var errors []error
 
+	// no validation rules for MaxBytesPerSecond
+
+	// no validation rules for MaxGetRequests
+
+	// no validation rules for NewestFirst
+
+	// no validation rules for ListingConcurrency
+
 	switch m.Credential.(type) {
 
 	case *S3_AccessKey:
@@ -2447,6 +2581,37 @@ func (m *S3) validate(all bool) error {
 			}
 		}
 
+	case *S3_Ambient:
+
+		if all {
+			switch v := interface{}(m.GetAmbient()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, S3ValidationError{
+						field:  "Ambient",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, S3ValidationError{
+						field:  "Ambient",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetAmbient()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return S3ValidationError{
+					field:  "Ambient",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
 	}
 
 	if len(errors) > 0 {
@@ -3529,6 +3694,14 @@ func (m *Syslog) validate(all bool) error {
 
 	// no validation rules for Format
 
+	// no validation rules for ReverseDns
+
+	// no validation rules for SpoolDir
+
+	// no validation rules for SpoolMaxBytes
+
+	// no validation rules for ReassembleUdp
+
 	if len(errors) > 0 {
 		return SyslogMultiError(errors)
 	}
@@ -3605,3 +3778,1262 @@ var _ interface {
 	Cause() error
 	ErrorName() string
 } = SyslogValidationError{}
+
+// Validate checks the field values on Vault with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Vault) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Vault with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in VaultMultiError, or nil if none found.
+func (m *Vault) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Vault) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if _, err := url.Parse(m.GetEndpoint()); err != nil {
+		err = VaultValidationError{
+			field:  "Endpoint",
+			reason: "value must be a valid URI",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if all {
+		switch v := interface{}(m.GetTls()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, VaultValidationError{
+					field:  "Tls",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, VaultValidationError{
+					field:  "Tls",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetTls()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return VaultValidationError{
+				field:  "Tls",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetProxy()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, VaultValidationError{
+					field:  "Proxy",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, VaultValidationError{
+					field:  "Proxy",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetProxy()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return VaultValidationError{
+				field:  "Proxy",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	switch m.Credential.(type) {
+
+	case *Vault_Token:
+		// no validation rules for Token
+
+	}
+
+	if len(errors) > 0 {
+		return VaultMultiError(errors)
+	}
+
+	return nil
+}
+
+// VaultMultiError is an error wrapping multiple validation errors returned by
+// Vault.ValidateAll() if the designated constraints aren't met.
+type VaultMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m VaultMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m VaultMultiError) AllErrors() []error { return m }
+
+// VaultValidationError is the validation error returned by Vault.Validate if
+// the designated constraints aren't met.
+type VaultValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e VaultValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e VaultValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e VaultValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e VaultValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e VaultValidationError) ErrorName() string { return "VaultValidationError" }
+
+// Error satisfies the builtin error interface
+func (e VaultValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sVault.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = VaultValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = VaultValidationError{}
+
+// Validate checks the field values on WindowsEventLog with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// first error encountered is returned, or nil if there are no violations.
+func (m *WindowsEventLog) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on WindowsEventLog with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// WindowsEventLogMultiError, or nil if none found.
+func (m *WindowsEventLog) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *WindowsEventLog) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Query
+
+	// no validation rules for Follow
+
+	if len(errors) > 0 {
+		return WindowsEventLogMultiError(errors)
+	}
+
+	return nil
+}
+
+// WindowsEventLogMultiError is an error wrapping multiple validation errors
+// returned by WindowsEventLog.ValidateAll() if the designated constraints
+// aren't met.
+type WindowsEventLogMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m WindowsEventLogMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m WindowsEventLogMultiError) AllErrors() []error { return m }
+
+// WindowsEventLogValidationError is the validation error returned by
+// WindowsEventLog.Validate if the designated constraints aren't met.
+type WindowsEventLogValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e WindowsEventLogValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e WindowsEventLogValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e WindowsEventLogValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e WindowsEventLogValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e WindowsEventLogValidationError) ErrorName() string { return "WindowsEventLogValidationError" }
+
+// Error satisfies the builtin error interface
+func (e WindowsEventLogValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sWindowsEventLog.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = WindowsEventLogValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = WindowsEventLogValidationError{}
+
+// Validate checks the field values on MobileApp with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *MobileApp) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on MobileApp with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in MobileAppMultiError, or nil
+// if none found.
+func (m *MobileApp) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *MobileApp) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetTls()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, MobileAppValidationError{
+					field:  "Tls",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, MobileAppValidationError{
+					field:  "Tls",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetTls()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return MobileAppValidationError{
+				field:  "Tls",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetProxy()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, MobileAppValidationError{
+					field:  "Proxy",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, MobileAppValidationError{
+					field:  "Proxy",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetProxy()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return MobileAppValidationError{
+				field:  "Proxy",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return MobileAppMultiError(errors)
+	}
+
+	return nil
+}
+
+// MobileAppMultiError is an error wrapping multiple validation errors returned
+// by MobileApp.ValidateAll() if the designated constraints aren't met.
+type MobileAppMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m MobileAppMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m MobileAppMultiError) AllErrors() []error { return m }
+
+// MobileAppValidationError is the validation error returned by
+// MobileApp.Validate if the designated constraints aren't met.
+type MobileAppValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e MobileAppValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e MobileAppValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e MobileAppValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e MobileAppValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e MobileAppValidationError) ErrorName() string { return "MobileAppValidationError" }
+
+// Error satisfies the builtin error interface
+func (e MobileAppValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sMobileApp.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = MobileAppValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = MobileAppValidationError{}
+
+// Validate checks the field values on Wayback with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Wayback) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Wayback with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in WaybackMultiError, or nil if none found.
+func (m *Wayback) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Wayback) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Limit
+
+	if all {
+		switch v := interface{}(m.GetTls()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, WaybackValidationError{
+					field:  "Tls",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, WaybackValidationError{
+					field:  "Tls",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetTls()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return WaybackValidationError{
+				field:  "Tls",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetProxy()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, WaybackValidationError{
+					field:  "Proxy",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, WaybackValidationError{
+					field:  "Proxy",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetProxy()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return WaybackValidationError{
+				field:  "Proxy",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return WaybackMultiError(errors)
+	}
+
+	return nil
+}
+
+// WaybackMultiError is an error wrapping multiple validation errors returned
+// by Wayback.ValidateAll() if the designated constraints aren't met.
+type WaybackMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m WaybackMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m WaybackMultiError) AllErrors() []error { return m }
+
+// WaybackValidationError is the validation error returned by Wayback.Validate
+// if the designated constraints aren't met.
+type WaybackValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e WaybackValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e WaybackValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e WaybackValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e WaybackValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e WaybackValidationError) ErrorName() string { return "WaybackValidationError" }
+
+// Error satisfies the builtin error interface
+func (e WaybackValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sWayback.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = WaybackValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = WaybackValidationError{}
+
+// Validate checks the field values on ServiceBanners with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *ServiceBanners) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ServiceBanners with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in ServiceBannersMultiError,
+// or nil if none found.
+func (m *ServiceBanners) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ServiceBanners) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for ShodanKey
+
+	// no validation rules for CensysId
+
+	// no validation rules for CensysSecret
+
+	if all {
+		switch v := interface{}(m.GetProxy()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ServiceBannersValidationError{
+					field:  "Proxy",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ServiceBannersValidationError{
+					field:  "Proxy",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetProxy()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ServiceBannersValidationError{
+				field:  "Proxy",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return ServiceBannersMultiError(errors)
+	}
+
+	return nil
+}
+
+// ServiceBannersMultiError is an error wrapping multiple validation errors
+// returned by ServiceBanners.ValidateAll() if the designated constraints
+// aren't met.
+type ServiceBannersMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ServiceBannersMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ServiceBannersMultiError) AllErrors() []error { return m }
+
+// ServiceBannersValidationError is the validation error returned by
+// ServiceBanners.Validate if the designated constraints aren't met.
+type ServiceBannersValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ServiceBannersValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ServiceBannersValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ServiceBannersValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ServiceBannersValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ServiceBannersValidationError) ErrorName() string { return "ServiceBannersValidationError" }
+
+// Error satisfies the builtin error interface
+func (e ServiceBannersValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sServiceBanners.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ServiceBannersValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ServiceBannersValidationError{}
+
+// Validate checks the field values on DNS with the rules defined in the proto
+// definition for this message. If any rules are violated, the first error
+// encountered is returned, or nil if there are no violations.
+func (m *DNS) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on DNS with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in DNSMultiError, or nil if none found.
+func (m *DNS) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *DNS) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for ZoneTransfer
+
+	// no validation rules for Nameserver
+
+	if len(errors) > 0 {
+		return DNSMultiError(errors)
+	}
+
+	return nil
+}
+
+// DNSMultiError is an error wrapping multiple validation errors returned by
+// DNS.ValidateAll() if the designated constraints aren't met.
+type DNSMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m DNSMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m DNSMultiError) AllErrors() []error { return m }
+
+// DNSValidationError is the validation error returned by DNS.Validate if the
+// designated constraints aren't met.
+type DNSValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DNSValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DNSValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DNSValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DNSValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DNSValidationError) ErrorName() string { return "DNSValidationError" }
+
+// Error satisfies the builtin error interface
+func (e DNSValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDNS.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DNSValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DNSValidationError{}
+
+// Validate checks the field values on FilesystemWatcher with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// first error encountered is returned, or nil if there are no violations.
+func (m *FilesystemWatcher) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on FilesystemWatcher with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// FilesystemWatcherMultiError, or nil if none found.
+func (m *FilesystemWatcher) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *FilesystemWatcher) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for DebounceMs
+
+	if len(errors) > 0 {
+		return FilesystemWatcherMultiError(errors)
+	}
+
+	return nil
+}
+
+// FilesystemWatcherMultiError is an error wrapping multiple validation errors
+// returned by FilesystemWatcher.ValidateAll() if the designated constraints
+// aren't met.
+type FilesystemWatcherMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m FilesystemWatcherMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m FilesystemWatcherMultiError) AllErrors() []error { return m }
+
+// FilesystemWatcherValidationError is the validation error returned by
+// FilesystemWatcher.Validate if the designated constraints aren't met.
+type FilesystemWatcherValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e FilesystemWatcherValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e FilesystemWatcherValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e FilesystemWatcherValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e FilesystemWatcherValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e FilesystemWatcherValidationError) ErrorName() string {
+	return "FilesystemWatcherValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e FilesystemWatcherValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sFilesystemWatcher.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = FilesystemWatcherValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = FilesystemWatcherValidationError{}
+
+// Validate checks the field values on FileShare with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *FileShare) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on FileShare with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in FileShareMultiError, or nil
+// if none found.
+func (m *FileShare) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *FileShare) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Username
+
+	// no validation rules for Password
+
+	// no validation rules for PrivateKey
+
+	// no validation rules for Domain
+
+	// no validation rules for MaxFileSize
+
+	// no validation rules for KnownHosts
+
+	// no validation rules for InsecureSkipVerify
+
+	if len(errors) > 0 {
+		return FileShareMultiError(errors)
+	}
+
+	return nil
+}
+
+// FileShareMultiError is an error wrapping multiple validation errors returned
+// by FileShare.ValidateAll() if the designated constraints aren't met.
+type FileShareMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m FileShareMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m FileShareMultiError) AllErrors() []error { return m }
+
+// FileShareValidationError is the validation error returned by
+// FileShare.Validate if the designated constraints aren't met.
+type FileShareValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e FileShareValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e FileShareValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e FileShareValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e FileShareValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e FileShareValidationError) ErrorName() string { return "FileShareValidationError" }
+
+// Error satisfies the builtin error interface
+func (e FileShareValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sFileShare.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = FileShareValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = FileShareValidationError{}
+
+// Validate checks the field values on SSHSweep with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *SSHSweep) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on SSHSweep with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in SSHSweepMultiError, or nil
+// if none found.
+func (m *SSHSweep) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *SSHSweep) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Username
+
+	// no validation rules for Password
+
+	// no validation rules for PrivateKey
+
+	// no validation rules for KnownHosts
+
+	// no validation rules for InsecureSkipVerify
+
+	// no validation rules for Sudo
+
+	// no validation rules for MaxFileSize
+
+	if len(errors) > 0 {
+		return SSHSweepMultiError(errors)
+	}
+
+	return nil
+}
+
+// SSHSweepMultiError is an error wrapping multiple validation errors returned
+// by SSHSweep.ValidateAll() if the designated constraints aren't met.
+type SSHSweepMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m SSHSweepMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m SSHSweepMultiError) AllErrors() []error { return m }
+
+// SSHSweepValidationError is the validation error returned by
+// SSHSweep.Validate if the designated constraints aren't met.
+type SSHSweepValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e SSHSweepValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e SSHSweepValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e SSHSweepValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e SSHSweepValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e SSHSweepValidationError) ErrorName() string { return "SSHSweepValidationError" }
+
+// Error satisfies the builtin error interface
+func (e SSHSweepValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sSSHSweep.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = SSHSweepValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = SSHSweepValidationError{}
+
+// Validate checks the field values on Patch with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Patch) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Patch with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in PatchMultiError, or nil if none found.
+func (m *Patch) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Patch) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if len(errors) > 0 {
+		return PatchMultiError(errors)
+	}
+
+	return nil
+}
+
+// PatchMultiError is an error wrapping multiple validation errors returned by
+// Patch.ValidateAll() if the designated constraints aren't met.
+type PatchMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m PatchMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m PatchMultiError) AllErrors() []error { return m }
+
+// PatchValidationError is the validation error returned by Patch.Validate if
+// the designated constraints aren't met.
+type PatchValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e PatchValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e PatchValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e PatchValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e PatchValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e PatchValidationError) ErrorName() string { return "PatchValidationError" }
+
+// Error satisfies the builtin error interface
+func (e PatchValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sPatch.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = PatchValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = PatchValidationError{}
@@ -0,0 +1,389 @@
+// Package plist decodes Apple property lists, in their XML and binary
+// formats, such as the Info.plist files of iOS apps.
+package plist
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/go-errors/errors"
+)
+
+// maxDepth limits how deeply arrays and dictionaries are nested.
+const maxDepth = 64
+
+var (
+	binaryMagic = []byte("bplist00")
+
+	// appleEpoch is the epoch of binary property list dates.
+	appleEpoch = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// ErrNotPlist is returned for data that isn't a property list.
+	ErrNotPlist = errors.New("not a property list")
+)
+
+// Decode decodes a property list. Values are decoded as
+// map[string]interface{}, []interface{}, string, int64, float64, bool,
+// time.Time and []byte.
+func Decode(data []byte) (interface{}, error) {
+	if bytes.HasPrefix(data, binaryMagic) {
+		return decodeBinary(data)
+	}
+	return decodeXML(data)
+}
+
+// decodeXML decodes an XML property list.
+func decodeXML(data []byte) (interface{}, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	// Property lists declare a DTD, which isn't needed to parse them.
+	d.Strict = false
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, ErrNotPlist
+			}
+			return nil, errors.WrapPrefix(err, "could not parse property list", 0)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			if start.Name.Local != "plist" {
+				return nil, ErrNotPlist
+			}
+			break
+		}
+	}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, errors.WrapPrefix(err, "could not parse property list", 0)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			return decodeXMLValue(d, tok, 0)
+		case xml.EndElement:
+			return nil, errors.New("empty property list")
+		}
+	}
+}
+
+// decodeXMLValue decodes the value of the element start.
+func decodeXMLValue(d *xml.Decoder, start xml.StartElement, depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, errors.New("property list is too deeply nested")
+	}
+	switch start.Name.Local {
+	case "dict":
+		dict := map[string]interface{}{}
+		key := ""
+		for {
+			elem, err := nextElement(d)
+			if err != nil || elem == nil {
+				return dict, err
+			}
+			if elem.Name.Local == "key" {
+				if key, err = elementText(d); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			value, err := decodeXMLValue(d, *elem, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			dict[key] = value
+		}
+	case "array":
+		array := []interface{}{}
+		for {
+			elem, err := nextElement(d)
+			if err != nil || elem == nil {
+				return array, err
+			}
+			value, err := decodeXMLValue(d, *elem, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+	case "true", "false":
+		if err := d.Skip(); err != nil {
+			return nil, err
+		}
+		return start.Name.Local == "true", nil
+	}
+
+	text, err := elementText(d)
+	if err != nil {
+		return nil, err
+	}
+	switch start.Name.Local {
+	case "string":
+		return text, nil
+	case "integer":
+		return strconv.ParseInt(strings.TrimSpace(text), 0, 64)
+	case "real":
+		return strconv.ParseFloat(strings.TrimSpace(text), 64)
+	case "date":
+		return time.Parse(time.RFC3339, strings.TrimSpace(text))
+	case "data":
+		return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(text), ""))
+	default:
+		return nil, errors.Errorf("unknown property list element %q", start.Name.Local)
+	}
+}
+
+// nextElement returns the next child element, or nil at the end of the
+// parent element.
+func nextElement(d *xml.Decoder) (*xml.StartElement, error) {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, errors.WrapPrefix(err, "could not parse property list", 0)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			return &tok, nil
+		case xml.EndElement:
+			return nil, nil
+		}
+	}
+}
+
+// elementText returns the text of the current element, and consumes its end.
+func elementText(d *xml.Decoder) (string, error) {
+	var text strings.Builder
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return "", errors.WrapPrefix(err, "could not parse property list", 0)
+		}
+		switch tok := tok.(type) {
+		case xml.CharData:
+			text.Write(tok)
+		case xml.StartElement:
+			if err := d.Skip(); err != nil {
+				return "", err
+			}
+		case xml.EndElement:
+			return text.String(), nil
+		}
+	}
+}
+
+// binaryPlist is a binary property list being decoded.
+// https://opensource.apple.com/source/CF/CF-1153.18/CFBinaryPList.c
+type binaryPlist struct {
+	data    []byte
+	offsets []uint64
+	refSize int
+}
+
+// decodeBinary decodes a binary property list.
+func decodeBinary(data []byte) (interface{}, error) {
+	if len(data) < len(binaryMagic)+32 {
+		return nil, errors.New("truncated binary property list")
+	}
+	trailer := data[len(data)-32:]
+	offsetSize := int(trailer[6])
+	refSize := int(trailer[7])
+	numObjects := binary.BigEndian.Uint64(trailer[8:])
+	top := binary.BigEndian.Uint64(trailer[16:])
+	tableOffset := binary.BigEndian.Uint64(trailer[24:])
+	if offsetSize < 1 || offsetSize > 8 || refSize < 1 || refSize > 8 || top >= numObjects ||
+		tableOffset > uint64(len(data)) || numObjects > (uint64(len(data))-tableOffset)/uint64(offsetSize) {
+		return nil, errors.New("invalid binary property list trailer")
+	}
+
+	p := &binaryPlist{data: data, refSize: refSize}
+	p.offsets = make([]uint64, numObjects)
+	for i := range p.offsets {
+		start := int(tableOffset) + i*offsetSize
+		p.offsets[i] = readUint(data[start : start+offsetSize])
+	}
+	return p.object(top, 0)
+}
+
+// object decodes the object ref.
+func (p *binaryPlist) object(ref uint64, depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, errors.New("property list is too deeply nested")
+	}
+	if ref >= uint64(len(p.offsets)) || p.offsets[ref] >= uint64(len(p.data)) {
+		return nil, errors.Errorf("invalid object reference %d", ref)
+	}
+	offset := int(p.offsets[ref])
+	marker := p.data[offset]
+	kind, info := marker>>4, int(marker&0x0f)
+	offset++
+
+	switch kind {
+	case 0x0:
+		switch marker {
+		case 0x08:
+			return false, nil
+		case 0x09:
+			return true, nil
+		}
+		return nil, nil
+	case 0x1, 0x2, 0x3:
+		b, err := p.bytes(offset, 1<<info)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case kind == 0x1 && len(b) > 8:
+			// Only the low 64 bits of 128-bit integers are kept.
+			return int64(readUint(b[len(b)-8:])), nil
+		case kind == 0x1:
+			return int64(readUint(b)), nil
+		case len(b) == 4:
+			return float64(math.Float32frombits(uint32(readUint(b)))), nil
+		case len(b) != 8:
+			return nil, errors.New("invalid real size")
+		case kind == 0x2:
+			return math.Float64frombits(readUint(b)), nil
+		default:
+			seconds := math.Float64frombits(readUint(b))
+			return appleEpoch.Add(time.Duration(seconds * float64(time.Second))), nil
+		}
+	case 0x8:
+		// UIDs are references used by keyed archives.
+		b, err := p.bytes(offset, info+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) > 8 {
+			b = b[len(b)-8:]
+		}
+		return int64(readUint(b)), nil
+	}
+
+	count, offset, err := p.count(info, offset)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case 0x4:
+		b, err := p.bytes(offset, count)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+	case 0x5, 0x7:
+		b, err := p.bytes(offset, count)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case 0x6:
+		b, err := p.bytes(offset, 2*count)
+		if err != nil {
+			return nil, err
+		}
+		units := make([]uint16, count)
+		for i := range units {
+			units[i] = binary.BigEndian.Uint16(b[2*i:])
+		}
+		return string(utf16.Decode(units)), nil
+	case 0xa, 0xc:
+		refs, err := p.refs(offset, count)
+		if err != nil {
+			return nil, err
+		}
+		array := make([]interface{}, 0, count)
+		for _, ref := range refs {
+			value, err := p.object(ref, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		return array, nil
+	case 0xd:
+		refs, err := p.refs(offset, 2*count)
+		if err != nil {
+			return nil, err
+		}
+		dict := make(map[string]interface{}, count)
+		for i := 0; i < count; i++ {
+			key, err := p.object(refs[i], depth+1)
+			if err != nil {
+				return nil, err
+			}
+			keyString, ok := key.(string)
+			if !ok {
+				return nil, errors.New("dictionary key is not a string")
+			}
+			if dict[keyString], err = p.object(refs[count+i], depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return dict, nil
+	default:
+		return nil, errors.Errorf("unknown object marker %#x", marker)
+	}
+}
+
+// count returns the element count of an object, which is either the low bits
+// of its marker or the integer object that follows it, and the offset of the
+// object's contents.
+func (p *binaryPlist) count(info, offset int) (int, int, error) {
+	if info != 0x0f {
+		return info, offset, nil
+	}
+	b, err := p.bytes(offset, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	if b[0]>>4 != 0x1 || b[0]&0x0f > 3 {
+		return 0, 0, errors.New("invalid object count")
+	}
+	size := 1 << (b[0] & 0x0f)
+	b, err = p.bytes(offset+1, size)
+	if err != nil {
+		return 0, 0, err
+	}
+	count := readUint(b)
+	if count > uint64(len(p.data)) {
+		return 0, 0, errors.New("invalid object count")
+	}
+	return int(count), offset + 1 + size, nil
+}
+
+// refs reads count object references.
+func (p *binaryPlist) refs(offset, count int) ([]uint64, error) {
+	b, err := p.bytes(offset, count*p.refSize)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]uint64, count)
+	for i := range refs {
+		refs[i] = readUint(b[i*p.refSize : (i+1)*p.refSize])
+	}
+	return refs, nil
+}
+
+// bytes returns n bytes at offset.
+func (p *binaryPlist) bytes(offset, n int) ([]byte, error) {
+	if offset < 0 || n < 0 || n > len(p.data)-offset {
+		return nil, errors.New("object extends past end of property list")
+	}
+	return p.data[offset : offset+n], nil
+}
+
+// readUint reads a big endian unsigned integer of up to 8 bytes.
+func readUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
@@ -0,0 +1,153 @@
+package plist
+
+import (
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+// infoPlist is an Info.plist, as written by Python's plistlib.
+const infoPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Build</key>
+	<dict>
+		<key>Date</key>
+		<date>2023-05-01T12:00:00Z</date>
+		<key>Hash</key>
+		<data>
+		AQID
+		</data>
+		<key>Name</key>
+		<string>Notes – Beta</string>
+		<key>Number</key>
+		<integer>241</integer>
+		<key>Ratio</key>
+		<real>0.5</real>
+	</dict>
+	<key>CFBundleIdentifier</key>
+	<string>com.example.notes</string>
+	<key>CFBundleShortVersionString</key>
+	<string>2.4.1</string>
+	<key>CFBundleVersion</key>
+	<string>241</string>
+	<key>LSRequiresIPhoneOS</key>
+	<true/>
+	<key>MinimumOSVersion</key>
+	<string>15.0</string>
+	<key>UIRequiredDeviceCapabilities</key>
+	<array>
+		<string>arm64</string>
+	</array>
+</dict>
+</plist>
+`
+
+// binaryInfoPlist is infoPlist in the binary format, gzipped.
+const binaryInfoPlist = "" +
+	"H4sIAAAAAAACA1WPOU8CURSFz3MFN4ZFUeJCorEyE0C0NQIxkigYhqGdPOAqL5kFZ94Q/4L/" +
+	"Qjt7GxsbY2NlZbSw8CdoYSyVCJj4NffmnJN7c+ptU3gylXpmQ8Mjo2PjgWhsdi4+r+d8YTYN" +
+	"JZLfy/l206Rik2wpjgW5hpIYiFrLcWWNXE84tiZdYZ8YSmhg9vXujQOtQqe+cMkrHrUcm8qa" +
+	"oSiHwhaWb5W1v9yiXuznmgXqiAbleZvXhSmkIO8pODE5NT0TUsKRaoFLqu5zr1UtcYtqJd+q" +
+	"k6tXuBTO5u799/sFgHy3j4kSHEgQPCSTUSSR6+4SXPlY3XnDL4YSbjiWSmfcapuk2o4kT8+o" +
+	"WTWtZbLpYDW9paYuF3TuWttZBBDHEjIog6ODc1zhGje4xR0e8IgXvOITXwwsxhJsma2wNbbe" +
+	"+zLEehMJ/INt/AAV/z0qgAEAAA=="
+
+func TestDecode(t *testing.T) {
+	want := map[string]interface{}{
+		"CFBundleIdentifier":         "com.example.notes",
+		"CFBundleShortVersionString": "2.4.1",
+		"CFBundleVersion":            "241",
+		"LSRequiresIPhoneOS":         true,
+		"MinimumOSVersion":           "15.0",
+		"UIRequiredDeviceCapabilities": []interface{}{
+			"arm64",
+		},
+		"Build": map[string]interface{}{
+			"Date":   time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC),
+			"Hash":   []byte{1, 2, 3},
+			"Name":   "Notes \u2013 Beta",
+			"Number": int64(241),
+			"Ratio":  0.5,
+		},
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "xml",
+			data: []byte(infoPlist),
+		},
+		{
+			name: "binary",
+			data: decodeTestFile(t, binaryInfoPlist),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.data)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if diff := pretty.Compare(got, want); diff != "" {
+				t.Errorf("Decode() diff: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	binary := decodeTestFile(t, binaryInfoPlist)
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "not a plist",
+			data: []byte("<html><body></body></html>"),
+		},
+		{
+			name: "truncated xml",
+			data: []byte(infoPlist[:len(infoPlist)/2]),
+		},
+		{
+			name: "truncated binary",
+			data: binary[:len(binary)-20],
+		},
+		{
+			name: "object reference cycle",
+			// A one element array containing itself.
+			data: []byte("bplist00\xa1\x00\x08\x00\x00\x00\x00\x00\x00\x01\x01" +
+				"\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00" +
+				"\x00\x00\x00\x00\x00\x00\x00\x0a"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.data); err == nil {
+				t.Error("Decode() succeeded")
+			}
+		})
+	}
+}
+
+func decodeTestFile(t *testing.T, data string) []byte {
+	t.Helper()
+	zr, err := gzip.NewReader(base64.NewDecoder(base64.StdEncoding, strings.NewReader(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
@@ -0,0 +1,190 @@
+// Package results reads the findings of earlier scans from their JSON output,
+// so scans can be compared.
+package results
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/go-errors/errors"
+)
+
+// Finding is a finding read from a scan's JSON output.
+type Finding struct {
+	FindingID    string
+	DetectorName string
+	Verified     bool
+	Redacted     string
+	SourceName   string
+	// Metadata holds the fields of the finding's source metadata by their
+	// JSON names, such as "file" and "line".
+	Metadata map[string]string
+	// Line is the finding as it was written, which is written again when
+	// the finding is reported.
+	Line json.RawMessage
+}
+
+// maxLineSize is the longest line of JSON output that can be read.
+const maxLineSize = 16 * 1024 * 1024
+
+// Load reads the findings in the file at path, which holds the output of a
+// scan run with --json. Lines that aren't JSON, such as the banner, are
+// skipped.
+func Load(path string) ([]Finding, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not open results", 0)
+	}
+	defer f.Close()
+	found, err := Read(f)
+	if err != nil {
+		return nil, errors.WrapPrefix(err, path, 0)
+	}
+	return found, nil
+}
+
+// Read reads the findings in the JSON output of a scan.
+func Read(r io.Reader) ([]Finding, error) {
+	var found []Finding
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Bytes()
+		if len(text) == 0 || text[0] != '{' {
+			continue
+		}
+		var v struct {
+			FindingID      string
+			DetectorName   string
+			Verified       bool
+			Redacted       string
+			SourceName     string
+			SourceMetadata struct {
+				Data map[string]map[string]interface{}
+			}
+		}
+		// Numbers are kept as they're written, so large line numbers aren't
+		// printed in exponent form.
+		decoder := json.NewDecoder(bytes.NewReader(text))
+		decoder.UseNumber()
+		if err := decoder.Decode(&v); err != nil {
+			return nil, errors.WrapPrefix(err, "could not parse results line "+strconv.Itoa(line), 0)
+		}
+		if v.FindingID == "" {
+			return nil, errors.Errorf("results line %d has no FindingID, expected output of --json", line)
+		}
+		meta := map[string]string{}
+		for _, source := range v.SourceMetadata.Data {
+			for k, val := range source {
+				meta[k] = fmt.Sprint(val)
+			}
+		}
+		found = append(found, Finding{
+			FindingID:    v.FindingID,
+			DetectorName: v.DetectorName,
+			Verified:     v.Verified,
+			Redacted:     v.Redacted,
+			SourceName:   v.SourceName,
+			Metadata:     meta,
+			Line:         append(json.RawMessage(nil), text...),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.WrapPrefix(err, "could not read results", 0)
+	}
+	return found, nil
+}
+
+// Status is how a finding changed between two scans.
+type Status string
+
+const (
+	// StatusNew marks a finding only in the later scan.
+	StatusNew Status = "new"
+	// StatusResolved marks a finding only in the earlier scan.
+	StatusResolved Status = "resolved"
+	// StatusPersisting marks a finding in both scans.
+	StatusPersisting Status = "persisting"
+)
+
+// Diff is how the findings of two scans differ. Findings are matched by their
+// finding IDs, so a secret that moves to another file is both new and
+// resolved.
+type Diff struct {
+	New        []Finding
+	Resolved   []Finding
+	Persisting []Finding
+}
+
+// Compare returns how the findings of the scan after differ from those of the
+// scan before. Findings reported more than once in a scan are counted once.
+// Persisting findings are taken from after, so they show whether they're
+// still verified.
+func Compare(before, after []Finding) *Diff {
+	beforeIDs := ids(before)
+	afterIDs := ids(after)
+	d := &Diff{}
+	for _, f := range unique(after) {
+		if beforeIDs[f.FindingID] {
+			d.Persisting = append(d.Persisting, f)
+		} else {
+			d.New = append(d.New, f)
+		}
+	}
+	for _, f := range unique(before) {
+		if !afterIDs[f.FindingID] {
+			d.Resolved = append(d.Resolved, f)
+		}
+	}
+	return d
+}
+
+func ids(found []Finding) map[string]bool {
+	set := make(map[string]bool, len(found))
+	for _, f := range found {
+		set[f.FindingID] = true
+	}
+	return set
+}
+
+// unique returns the first of each finding in found, ordered by detector and
+// finding ID so diffs are stable.
+func unique(found []Finding) []Finding {
+	seen := make(map[string]bool, len(found))
+	var out []Finding
+	for _, f := range found {
+		if seen[f.FindingID] {
+			continue
+		}
+		seen[f.FindingID] = true
+		out = append(out, f)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].DetectorName != out[j].DetectorName {
+			return out[i].DetectorName < out[j].DetectorName
+		}
+		return out[i].FindingID < out[j].FindingID
+	})
+	return out
+}
+
+// Location returns where in its source the finding is, such as a file and
+// line.
+func (f Finding) Location() string {
+	location := ""
+	for _, k := range []string{"file", "link", "channel_name", "repository", "bucket"} {
+		if location = f.Metadata[k]; location != "" {
+			break
+		}
+	}
+	if line := f.Metadata["line"]; location != "" && line != "" && line != "0" {
+		location += ":" + line
+	}
+	return location
+}
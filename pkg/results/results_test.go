@@ -0,0 +1,70 @@
+package results
+
+import (
+	"strings"
+	"testing"
+)
+
+const scan = `🐷🔑🐷  TruffleHog. Unearth your secrets. 🐷🔑🐷
+
+{"FindingID":"a","SourceMetadata":{"Data":{"Git":{"commit":"abc123","file":"config.py","line":10}}},"SourceName":"trufflehog - git","DetectorName":"AWS","Verified":true,"Redacted":"AKIAEXAMPLE"}
+{"FindingID":"b","SourceMetadata":{"Data":{"Filesystem":{"file":"/etc/app.env"}}},"DetectorName":"Slack","Verified":false}
+{"FindingID":"b","SourceMetadata":{"Data":{"Filesystem":{"file":"/etc/app.env"}}},"DetectorName":"Slack","Verified":false}
+`
+
+func TestRead(t *testing.T) {
+	found, err := Read(strings.NewReader(scan))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 3 {
+		t.Fatalf("found %d findings, want 3", len(found))
+	}
+	if got, want := found[0].Location(), "config.py:10"; got != want {
+		t.Errorf("Location() = %q, want %q", got, want)
+	}
+	if got, want := found[1].Location(), "/etc/app.env"; got != want {
+		t.Errorf("Location() = %q, want %q", got, want)
+	}
+	if !found[0].Verified || found[0].DetectorName != "AWS" || found[0].Redacted != "AKIAEXAMPLE" {
+		t.Errorf("found[0] = %+v", found[0])
+	}
+}
+
+func TestRead_invalid(t *testing.T) {
+	for _, input := range []string{
+		`{"FindingID":`,
+		`{"DetectorName":"AWS"}`,
+	} {
+		if _, err := Read(strings.NewReader(input)); err == nil {
+			t.Errorf("Read(%q) succeeded, want error", input)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	finding := func(id, detector string) Finding {
+		return Finding{FindingID: id, DetectorName: detector}
+	}
+	before := []Finding{finding("1", "AWS"), finding("2", "Slack"), finding("2", "Slack")}
+	after := []Finding{finding("3", "Stripe"), finding("2", "Slack"), finding("4", "AWS"), finding("3", "Stripe")}
+
+	d := Compare(before, after)
+	for _, tt := range []struct {
+		name string
+		got  []Finding
+		want []string
+	}{
+		{"New", d.New, []string{"4", "3"}},
+		{"Resolved", d.Resolved, []string{"1"}},
+		{"Persisting", d.Persisting, []string{"2"}},
+	} {
+		var ids []string
+		for _, f := range tt.got {
+			ids = append(ids, f.FindingID)
+		}
+		if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s = %v, want %v", tt.name, ids, tt.want)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// Package scoring estimates how likely findings are to be false positives.
+package scoring
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// Ensure the Scorer satisfies the interfaces at compile time
+var (
+	_ detectors.Scorer     = (*Heuristic)(nil)
+	_ detectors.PathTagger = (*Heuristic)(nil)
+)
+
+// Heuristic scores results without a model, from three independent signals:
+// how random the secret's characters are, words next to it like "example" or
+// "placeholder", and whether it's in a test, fixture, or documentation file.
+// Each signal is a likelihood of its own, and they're combined so any one of
+// them can make a result likely to be a false positive.
+type Heuristic struct {
+	// pathRules score files before the default rules do.
+	pathRules []PathRule
+}
+
+// NewHeuristic returns a Heuristic scorer that scores files by pathRules, and
+// the files none of them match by the default rules.
+func NewHeuristic(pathRules ...PathRule) *Heuristic {
+	return &Heuristic{pathRules: pathRules}
+}
+
+// contextWindow is how many bytes on either side of a secret are searched for
+// context tokens.
+const contextWindow = 80
+
+// contextTokens are words near secrets that suggest they aren't real, and the
+// likelihood each gives. Words are matched by prefix, so "example" also
+// matches "examples".
+var contextTokens = map[string]float64{
+	"example":     0.6,
+	"sample":      0.6,
+	"dummy":       0.7,
+	"fake":        0.7,
+	"placeholder": 0.8,
+	"changeme":    0.8,
+	"redacted":    0.8,
+	"your":        0.5,
+	"mock":        0.5,
+	"test":        0.4,
+	"todo":        0.3,
+}
+
+// Score returns the likelihood that r is a false positive.
+func (h *Heuristic) Score(r *detectors.ResultWithMetadata, data []byte) float64 {
+	secret := bytes.TrimSpace(r.Raw)
+	if len(secret) == 0 {
+		return 0
+	}
+	return combine(
+		distributionScore(secret),
+		contextScore(secret, data),
+		h.pathScore(sources.MetadataFile(r.SourceMetadata)),
+	)
+}
+
+// combine returns the likelihood that at least one of independent signals
+// holds.
+func combine(scores ...float64) float64 {
+	none := 1.0
+	for _, s := range scores {
+		none *= 1 - s
+	}
+	return 1 - none
+}
+
+// distributionScore scores secrets by how far their characters are from
+// random: few distinct characters or long runs of one character are typical
+// of placeholders like "xxxxxxxx" or "abcabcabc".
+func distributionScore(secret []byte) float64 {
+	counts := map[byte]int{}
+	longestRun, run := 0, 0
+	for i, c := range secret {
+		counts[c]++
+		if i > 0 && c == secret[i-1] {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longestRun {
+			longestRun = run
+		}
+	}
+	var entropy float64
+	for _, n := range counts {
+		p := float64(n) / float64(len(secret))
+		entropy -= p * math.Log2(p)
+	}
+	// A random secret's entropy is close to the most its length and the kinds
+	// of characters in it allow.
+	most := math.Log2(math.Min(float64(len(secret)), alphabetSize(secret)))
+	score := 0.0
+	if most > 0 {
+		if ratio := entropy / most; ratio < 0.75 {
+			score = (0.75 - ratio) / 0.75
+		}
+	}
+	if longestRun >= 4 {
+		score = combine(score, math.Min(float64(longestRun)/float64(len(secret)), 0.8))
+	}
+	return math.Min(score, 0.9)
+}
+
+// alphabetSize returns the number of characters secrets like this one are
+// made of: 16 for hexadecimal, and otherwise the size of each class of
+// characters in it.
+func alphabetSize(secret []byte) float64 {
+	var digits, lower, upper, nonHex, other bool
+	for _, c := range secret {
+		switch {
+		case c >= '0' && c <= '9':
+			digits = true
+		case c >= 'a' && c <= 'z':
+			lower = true
+			nonHex = nonHex || c > 'f'
+		case c >= 'A' && c <= 'Z':
+			upper = true
+			nonHex = nonHex || c > 'F'
+		default:
+			other = true
+		}
+	}
+	if !nonHex && !other && !(lower && upper) {
+		return 16
+	}
+	size := 0.0
+	for _, class := range []struct {
+		present bool
+		size    float64
+	}{{digits, 10}, {lower, 26}, {upper, 26}, {other, 32}} {
+		if class.present {
+			size += class.size
+		}
+	}
+	return size
+}
+
+// contextScore scores secrets by the words around them in data.
+func contextScore(secret, data []byte) float64 {
+	i := bytes.Index(data, secret)
+	if i < 0 {
+		return 0
+	}
+	before := data[max(0, i-contextWindow):i]
+	after := data[i+len(secret) : min(len(data), i+len(secret)+contextWindow)]
+
+	score := 0.0
+	seen := map[string]bool{}
+	for _, word := range append(words(before), words(after)...) {
+		for token, likelihood := range contextTokens {
+			if !seen[token] && strings.HasPrefix(word, token) {
+				seen[token] = true
+				score = combine(score, likelihood)
+			}
+		}
+	}
+	return score
+}
+
+// words returns the lower case words in data. Identifiers like YOUR_API_KEY
+// are split into their words.
+func words(data []byte) []string {
+	return strings.FieldsFunc(strings.ToLower(string(data)), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+}
+
+// pathScore scores secrets by the file they're in.
+func (h *Heuristic) pathScore(file string) float64 {
+	rule, _ := matchPathRule(h.pathRules, file)
+	return rule.Score
+}
+
+// PathTag returns the tag of the path rule the file r is in matches, if any.
+func (h *Heuristic) PathTag(r *detectors.ResultWithMetadata) string {
+	rule, _ := matchPathRule(h.pathRules, sources.MetadataFile(r.SourceMetadata))
+	return rule.Tag
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
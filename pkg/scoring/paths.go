@@ -0,0 +1,153 @@
+package scoring
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+// PathRule sets the likelihood that findings in the files matching Pattern
+// are false positives, and the tag they're given.
+type PathRule struct {
+	// Pattern is a directory, like fixtures/, which matches files in any
+	// directory with that name or path, or a glob. Globs without a slash,
+	// like *.md, match file names, and others match the end of files' paths.
+	// Patterns ignore case.
+	Pattern string
+	// Score is the likelihood, from 0 to 1, that findings in the files are
+	// false positives.
+	Score float64
+	// Tag is recorded with findings in the files, if it isn't empty.
+	Tag string
+}
+
+// defaultPathRules are the directories and files that often hold example
+// secrets. Where more than one matches a file, the highest score is used.
+var defaultPathRules = []PathRule{
+	{Pattern: "test/", Score: 0.4, Tag: "test"},
+	{Pattern: "tests/", Score: 0.4, Tag: "test"},
+	{Pattern: "__tests__/", Score: 0.4, Tag: "test"},
+	{Pattern: "spec/", Score: 0.4, Tag: "test"},
+	{Pattern: "testdata/", Score: 0.6, Tag: "fixture"},
+	{Pattern: "fixtures/", Score: 0.6, Tag: "fixture"},
+	{Pattern: "mocks/", Score: 0.6, Tag: "fixture"},
+	{Pattern: "example/", Score: 0.5, Tag: "example"},
+	{Pattern: "examples/", Score: 0.5, Tag: "example"},
+	{Pattern: "samples/", Score: 0.5, Tag: "example"},
+	{Pattern: "docs/", Score: 0.4, Tag: "docs"},
+	{Pattern: "*_test.go", Score: 0.4, Tag: "test"},
+	{Pattern: "*_test.py", Score: 0.4, Tag: "test"},
+	{Pattern: "*.test.js", Score: 0.4, Tag: "test"},
+	{Pattern: "*.test.ts", Score: 0.4, Tag: "test"},
+	{Pattern: "*.spec.js", Score: 0.4, Tag: "test"},
+	{Pattern: "*.spec.ts", Score: 0.4, Tag: "test"},
+	{Pattern: "*.md", Score: 0.3, Tag: "docs"},
+	{Pattern: "*.rst", Score: 0.3, Tag: "docs"},
+	{Pattern: "*.example", Score: 0.6, Tag: "example"},
+	{Pattern: "*.sample", Score: 0.6, Tag: "example"},
+	{Pattern: "*.template", Score: 0.5, Tag: "example"},
+	{Pattern: "*.dist", Score: 0.4, Tag: "example"},
+}
+
+// ParsePathRules parses path rules, one on each line as a pattern, a score,
+// and optionally a tag, separated by spaces. Blank lines and lines starting
+// with # are skipped.
+func ParsePathRules(r io.Reader) ([]PathRule, error) {
+	var rules []PathRule
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("line %d: want a pattern, a score, and optionally a tag, got %q", n, line)
+		}
+		score, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil || score < 0 || score > 1 {
+			return nil, fmt.Errorf("line %d: score %q is not a number from 0 to 1", n, fields[1])
+		}
+		if _, err := path.Match(strings.TrimSuffix(fields[0], "/"), ""); err != nil {
+			return nil, fmt.Errorf("line %d: pattern %q is malformed", n, fields[0])
+		}
+		rule := PathRule{Pattern: fields[0], Score: score}
+		if len(fields) == 3 {
+			rule.Tag = fields[2]
+		}
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// LoadPathRules reads the path rules in the file at name.
+func LoadPathRules(name string) ([]PathRule, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not open path rules", 0)
+	}
+	defer f.Close()
+	rules, err := ParsePathRules(f)
+	if err != nil {
+		return nil, errors.WrapPrefix(err, fmt.Sprintf("could not parse path rules %s", name), 0)
+	}
+	return rules, nil
+}
+
+// matchPathRule returns the first of rules that file matches, or else the
+// default rule with the highest score that does.
+func matchPathRule(rules []PathRule, file string) (PathRule, bool) {
+	if file == "" {
+		return PathRule{}, false
+	}
+	file = strings.ToLower(path.Clean(strings.ReplaceAll(file, "\\", "/")))
+	for _, rule := range rules {
+		if rule.matches(file) {
+			return rule, true
+		}
+	}
+	var best PathRule
+	found := false
+	for _, rule := range defaultPathRules {
+		if rule.matches(file) && (!found || rule.Score > best.Score) {
+			best, found = rule, true
+		}
+	}
+	return best, found
+}
+
+// matches reports whether the file, cleaned and in lower case, matches the
+// rule's pattern.
+func (rule PathRule) matches(file string) bool {
+	pattern := strings.ToLower(strings.ReplaceAll(rule.Pattern, "\\", "/"))
+	if strings.HasSuffix(pattern, "/") {
+		dir := "/" + strings.Trim(pattern, "/") + "/"
+		return strings.Contains("/"+path.Dir(file)+"/", dir)
+	}
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(file))
+		return matched
+	}
+	// The pattern is matched against the end of the file's path, starting at
+	// each directory.
+	rest := file
+	for {
+		if matched, _ := path.Match(strings.TrimPrefix(pattern, "/"), strings.TrimPrefix(rest, "/")); matched {
+			return true
+		}
+		i := strings.Index(rest, "/")
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+1:]
+	}
+}
@@ -0,0 +1,78 @@
+package scoring
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestParsePathRules(t *testing.T) {
+	rules, err := ParsePathRules(strings.NewReader(`
+# Sample keys in the SDK docs.
+sdk/docs/ 0.9 sample
+vendor/   0.7
+runbooks/*.md 0
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PathRule{
+		{Pattern: "sdk/docs/", Score: 0.9, Tag: "sample"},
+		{Pattern: "vendor/", Score: 0.7},
+		{Pattern: "runbooks/*.md", Score: 0},
+	}
+	if diff := pretty.Compare(rules, want); diff != "" {
+		t.Errorf("ParsePathRules() diff: (-got +want)\n%s", diff)
+	}
+
+	for _, bad := range []string{"vendor/", "vendor/ high", "vendor/ 1.5", "vendor/ 0.5 a b", "[/ 0.5"} {
+		if _, err := ParsePathRules(strings.NewReader(bad)); err == nil {
+			t.Errorf("ParsePathRules(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestMatchPathRule(t *testing.T) {
+	rules := []PathRule{
+		{Pattern: "sdk/docs/", Score: 0.9, Tag: "sample"},
+		{Pattern: "runbooks/*.md", Score: 0},
+		{Pattern: "*.PEM", Score: 0.2, Tag: "cert"},
+	}
+	tests := []struct {
+		file    string
+		want    PathRule
+		wantOK  bool
+		comment string
+	}{
+		{file: "/src/sdk/docs/auth/README.md", want: rules[0], wantOK: true},
+		{file: "ops/runbooks/rotate.md", want: rules[1], wantOK: true, comment: "overrides the default rule for *.md"},
+		{file: "ops/runbooks/scripts/rotate.md", want: PathRule{Pattern: "*.md", Score: 0.3, Tag: "docs"}, wantOK: true},
+		{file: `certs\Server.pem`, want: rules[2], wantOK: true},
+		{file: "pkg/testdata/client_test.go", want: PathRule{Pattern: "testdata/", Score: 0.6, Tag: "fixture"}, wantOK: true, comment: "highest default rule"},
+		{file: "sdk/docsite/main.go"},
+		{file: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			got, ok := matchPathRule(rules, tt.file)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("matchPathRule(%q) = %+v, %v, want %+v, %v %s", tt.file, got, ok, tt.want, tt.wantOK, tt.comment)
+			}
+		})
+	}
+}
+
+func TestHeuristic_PathTag(t *testing.T) {
+	h := NewHeuristic(PathRule{Pattern: "vendor/", Score: 0.7})
+	for file, want := range map[string]string{
+		"pkg/client_test.go":     "test",
+		"vendor/lib/client.go":   "",
+		"config/settings.go":     "",
+		"examples/config.sample": "example",
+	} {
+		if got := h.PathTag(fileResult(file, "secret")); got != want {
+			t.Errorf("PathTag(%q) = %q, want %q", file, got, want)
+		}
+	}
+}
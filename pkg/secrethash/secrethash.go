@@ -0,0 +1,60 @@
+// Package secrethash replaces the secrets of results with a keyed HMAC of
+// them, so findings can be reported centrally, deduplicated, and tracked
+// through rotation without the secrets leaving the host that scanned them.
+//
+// Every host that reports to the same place must use the same key, or the
+// same secret hashes differently on each of them. The key must be kept as
+// secret as the findings, since anyone with it can check guesses of a secret
+// against its hash.
+package secrethash
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// Prefix marks a hashed secret, so it isn't mistaken for the secret itself.
+const Prefix = "hmac-sha256:"
+
+// MinKeySize is the length of the shortest key accepted, in bytes.
+const MinKeySize = 16
+
+// Hasher hashes secrets with a key.
+type Hasher struct {
+	key []byte
+}
+
+// New returns a hasher that hashes secrets with key.
+func New(key []byte) (*Hasher, error) {
+	if len(key) < MinKeySize {
+		return nil, errors.Errorf("secret hashing key is %d bytes, it must be at least %d", len(key), MinKeySize)
+	}
+	return &Hasher{key: append([]byte{}, key...)}, nil
+}
+
+// Sum returns the hash of secret, with Prefix.
+func (h *Hasher) Sum(secret []byte) string {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write(secret)
+	return Prefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Hash replaces the raw secret of r with its hash. Its redacted secret is
+// replaced too if it holds any of the raw secret, as some detectors redact
+// nothing or only shorten the secret.
+func (h *Hasher) Hash(r *detectors.ResultWithMetadata) {
+	if len(r.Raw) == 0 || bytes.HasPrefix(r.Raw, []byte(Prefix)) {
+		return
+	}
+	sum := h.Sum(r.Raw)
+	if redacted := []byte(r.Redacted); len(redacted) > 0 && (bytes.Contains(r.Raw, redacted) || bytes.Contains(redacted, r.Raw)) {
+		r.Redacted = sum
+	}
+	r.Raw = []byte(sum)
+}
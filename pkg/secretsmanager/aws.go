@@ -0,0 +1,61 @@
+package secretsmanager
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
+)
+
+// AWSStore reads secret values from AWS Secrets Manager using the credentials
+// available in the environment.
+type AWSStore struct {
+	Region string
+}
+
+// Ensure the AWSStore satisfies the interface at compile time.
+var _ Store = (*AWSStore)(nil)
+
+func (s *AWSStore) Name() string {
+	return "aws secrets manager " + s.Region
+}
+
+func (s *AWSStore) Values(ctx context.Context, fn func(value string)) error {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+		Config:            aws.Config{Region: aws.String(s.Region)},
+	})
+	if err != nil {
+		return errors.WrapPrefix(err, "could not create aws session", 0)
+	}
+	client := secretsmanager.New(sess)
+
+	var ids []string
+	err = client.ListSecretsPagesWithContext(ctx, &secretsmanager.ListSecretsInput{}, func(page *secretsmanager.ListSecretsOutput, _ bool) bool {
+		for _, secret := range page.SecretList {
+			ids = append(ids, aws.StringValue(secret.ARN))
+		}
+		return true
+	})
+	if err != nil {
+		return errors.WrapPrefix(err, "could not list secrets", 0)
+	}
+
+	for _, id := range ids {
+		out, err := client.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(id)})
+		if err != nil {
+			log.FromContext(ctx).V(1).Info("could not read secret", "id", id, "error", err)
+			continue
+		}
+		if out.SecretString != nil {
+			fn(*out.SecretString)
+		} else if out.SecretBinary != nil {
+			fn(string(out.SecretBinary))
+		}
+	}
+	return nil
+}
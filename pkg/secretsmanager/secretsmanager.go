@@ -0,0 +1,90 @@
+// Package secretsmanager cross-checks findings against the secrets already
+// stored in a secrets manager, so leaks of managed secrets can be prioritized.
+package secretsmanager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"strings"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
+)
+
+const (
+	// StatusKey is the ExtraData key findings are tagged under.
+	StatusKey = "secret_status"
+	// StatusManagedLeaked marks a finding whose value is stored in a secrets manager.
+	StatusManagedLeaked = "managed secret leaked"
+	// StatusUnknown marks a finding whose value wasn't found in any secrets manager.
+	StatusUnknown = "unknown secret"
+)
+
+// Store lists the secret values held by a secrets manager.
+type Store interface {
+	// Name identifies the store in logs.
+	Name() string
+	// Values calls fn with every secret value in the store.
+	Values(ctx context.Context, fn func(value string)) error
+}
+
+// Index holds hashes of managed secret values, so findings can be matched
+// without keeping plaintext secrets in memory for the length of a scan.
+type Index struct {
+	hashes map[[sha256.Size]byte]struct{}
+}
+
+// NewIndex loads the values from each store into a new Index.
+func NewIndex(ctx context.Context, stores ...Store) (*Index, error) {
+	index := &Index{hashes: make(map[[sha256.Size]byte]struct{})}
+	for _, store := range stores {
+		before := len(index.hashes)
+		if err := store.Values(ctx, index.Add); err != nil {
+			return nil, errors.WrapPrefix(err, "could not load secrets from "+store.Name(), 0)
+		}
+		log.FromContext(ctx).V(1).Info("loaded secret hashes", "count", len(index.hashes)-before, "store", store.Name())
+	}
+	return index, nil
+}
+
+// Add hashes value into the index. Values holding a JSON object, which is
+// how most secrets managers store multi-field secrets, have each of their
+// string fields added as well.
+func (i *Index) Add(value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	i.hashes[sha256.Sum256([]byte(value))] = struct{}{}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return
+	}
+	for _, field := range fields {
+		if s, ok := field.(string); ok {
+			i.Add(s)
+		}
+	}
+}
+
+// Contains reports whether secret is one of the indexed values.
+func (i *Index) Contains(secret []byte) bool {
+	_, ok := i.hashes[sha256.Sum256([]byte(strings.TrimSpace(string(secret))))]
+	return ok
+}
+
+// Tag records in the result's ExtraData whether its raw secret is managed.
+func (i *Index) Tag(r *detectors.ResultWithMetadata) {
+	if r.ExtraData == nil {
+		r.ExtraData = map[string]string{}
+	}
+	if i.Contains(r.Raw) {
+		r.ExtraData[StatusKey] = StatusManagedLeaked
+		return
+	}
+	r.ExtraData[StatusKey] = StatusUnknown
+}
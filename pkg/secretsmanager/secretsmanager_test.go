@@ -0,0 +1,86 @@
+package secretsmanager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+func newTestVault(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var body interface{}
+		switch r.URL.Path {
+		case "/v1/secret/metadata/":
+			body = map[string]interface{}{"data": map[string]interface{}{"keys": []string{"app/", "ci"}}}
+		case "/v1/secret/metadata/app/":
+			body = map[string]interface{}{"data": map[string]interface{}{"keys": []string{"db"}}}
+		case "/v1/secret/data/app/db":
+			body = map[string]interface{}{"data": map[string]interface{}{"data": map[string]interface{}{"password": "hunter2-managed"}}}
+		case "/v1/secret/data/ci":
+			body = map[string]interface{}{"data": map[string]interface{}{"data": map[string]interface{}{"token": `{"key":"nested-json-value"}`}}}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+}
+
+func TestVaultKVWalk(t *testing.T) {
+	server := newTestVault(t)
+	defer server.Close()
+
+	var paths []string
+	err := NewVaultKV(server.URL, "root").Walk(context.Background(), "secret", func(secretPath string, _ map[string]interface{}) error {
+		paths = append(paths, secretPath)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 || paths[0] != "app/db" || paths[1] != "ci" {
+		t.Errorf("unexpected paths walked: %v", paths)
+	}
+}
+
+func TestIndexTag(t *testing.T) {
+	server := newTestVault(t)
+	defer server.Close()
+
+	index, err := NewIndex(context.Background(), &VaultStore{KV: NewVaultKV(server.URL, "root"), Mounts: []string{"secret"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"hunter2-managed":   StatusManagedLeaked,
+		"nested-json-value": StatusManagedLeaked,
+		"not-in-vault":      StatusUnknown,
+	}
+	for raw, want := range tests {
+		r := detectors.ResultWithMetadata{Result: detectors.Result{Raw: []byte(raw)}}
+		index.Tag(&r)
+		if got := r.ExtraData[StatusKey]; got != want {
+			t.Errorf("Tag(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestNewIndexStoreError(t *testing.T) {
+	server := newTestVault(t)
+	defer server.Close()
+
+	_, err := NewIndex(context.Background(), &VaultStore{KV: NewVaultKV(server.URL, "wrong"), Mounts: []string{"secret"}})
+	if err == nil {
+		t.Error("expected an error for a forbidden token")
+	}
+}
@@ -0,0 +1,137 @@
+package secretsmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+)
+
+// VaultKV is a minimal client for Vault's KV version 2 secrets engine.
+type VaultKV struct {
+	Address string
+	Token   string
+	client  *http.Client
+}
+
+// NewVaultKV returns a client for the Vault server at address.
+func NewVaultKV(address, token string, options ...common.HttpClientOption) *VaultKV {
+	return &VaultKV{
+		Address: strings.TrimSuffix(address, "/"),
+		Token:   token,
+		client:  common.SaneHttpClient(options...),
+	}
+}
+
+// List returns the keys under dir in mount. Keys ending in "/" are directories.
+func (v *VaultKV) List(ctx context.Context, mount, dir string) ([]string, error) {
+	var body struct {
+		Data struct {
+			Keys []string `json:"keys"`
+		} `json:"data"`
+	}
+	err := v.get(ctx, fmt.Sprintf("/v1/%s/metadata/%s?list=true", strings.Trim(mount, "/"), dir), &body)
+	if err != nil {
+		return nil, err
+	}
+	return body.Data.Keys, nil
+}
+
+// Read returns the latest version of the secret at secretPath in mount.
+func (v *VaultKV) Read(ctx context.Context, mount, secretPath string) (map[string]interface{}, error) {
+	var body struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	err := v.get(ctx, fmt.Sprintf("/v1/%s/data/%s", strings.Trim(mount, "/"), secretPath), &body)
+	if err != nil {
+		return nil, err
+	}
+	return body.Data.Data, nil
+}
+
+// Walk calls fn for every secret in mount, descending into directories.
+func (v *VaultKV) Walk(ctx context.Context, mount string, fn func(secretPath string, data map[string]interface{}) error) error {
+	return v.walk(ctx, mount, "", fn)
+}
+
+func (v *VaultKV) walk(ctx context.Context, mount, dir string, fn func(string, map[string]interface{}) error) error {
+	keys, err := v.List(ctx, mount, dir)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if common.IsDone(ctx) {
+			return ctx.Err()
+		}
+		if strings.HasSuffix(key, "/") {
+			if err := v.walk(ctx, mount, dir+key, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		secretPath := path.Join(dir, key)
+		data, err := v.Read(ctx, mount, secretPath)
+		if err != nil {
+			return err
+		}
+		if err := fn(secretPath, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *VaultKV) get(ctx context.Context, endpoint string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", v.Address+endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("X-Vault-Token", v.Token)
+	res, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return errors.Errorf("unexpected status %d from %s", res.StatusCode, endpoint)
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+// VaultStore reads secret values from KV version 2 mounts in Vault.
+type VaultStore struct {
+	KV     *VaultKV
+	Mounts []string
+}
+
+// Ensure the VaultStore satisfies the interface at compile time.
+var _ Store = (*VaultStore)(nil)
+
+func (s *VaultStore) Name() string {
+	return "vault " + s.KV.Address
+}
+
+func (s *VaultStore) Values(ctx context.Context, fn func(value string)) error {
+	for _, mount := range s.Mounts {
+		err := s.KV.Walk(ctx, mount, func(_ string, data map[string]interface{}) error {
+			for _, value := range data {
+				if str, ok := value.(string); ok {
+					fn(str)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return errors.WrapPrefix(err, "could not read mount "+mount, 0)
+		}
+	}
+	return nil
+}
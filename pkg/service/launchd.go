@@ -0,0 +1,57 @@
+package service
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+)
+
+// labelPrefix namespaces the launchd labels of daemons whose names aren't
+// already reverse-DNS labels.
+const labelPrefix = "com.trufflesecurity."
+
+// label returns the launchd label of the daemon named name.
+func label(name string) string {
+	if strings.Contains(name, ".") {
+		return name
+	}
+	return labelPrefix + name
+}
+
+// launchdPlist returns the property list of a launchd daemon that runs args,
+// the first of which is the executable. launchd starts the daemon at boot and
+// restarts it if it exits with an error.
+func launchdPlist(label string, args []string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	buf.WriteString(`<plist version="1.0">` + "\n<dict>\n")
+	writeKey(&buf, "Label")
+	writeString(&buf, "\t", label)
+	writeKey(&buf, "ProgramArguments")
+	buf.WriteString("\t<array>\n")
+	for _, arg := range args {
+		writeString(&buf, "\t\t", arg)
+	}
+	buf.WriteString("\t</array>\n")
+	writeKey(&buf, "RunAtLoad")
+	buf.WriteString("\t<true/>\n")
+	writeKey(&buf, "KeepAlive")
+	buf.WriteString("\t<dict>\n")
+	buf.WriteString("\t\t<key>SuccessfulExit</key>\n")
+	buf.WriteString("\t\t<false/>\n")
+	buf.WriteString("\t</dict>\n")
+	buf.WriteString("</dict>\n</plist>\n")
+	return buf.Bytes()
+}
+
+func writeKey(buf *bytes.Buffer, key string) {
+	buf.WriteString("\t<key>" + key + "</key>\n")
+}
+
+func writeString(buf *bytes.Buffer, indent, s string) {
+	buf.WriteString(indent + "<string>")
+	// Writing to a bytes.Buffer can't fail.
+	_ = xml.EscapeText(buf, []byte(s))
+	buf.WriteString("</string>\n")
+}
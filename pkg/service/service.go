@@ -0,0 +1,152 @@
+// Package service runs scans persistently as a Windows service or a macOS
+// launchd daemon, so sources that listen or follow, such as syslog and the
+// Windows Event Log, keep running on endpoints managed by IT rather than only
+// in containers.
+//
+// A service runs TruffleHog's own executable with "service run", which runs
+// the scan as a child process and writes its findings and logs to the system
+// log: the Windows Event Log, or macOS's unified log through syslog. The scan
+// is stopped with the service, and the service manager restarts the service
+// if the scan fails.
+package service
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/go-errors/errors"
+)
+
+// DefaultName is the name services are installed with when none is given.
+const DefaultName = "trufflehog"
+
+// description describes services to the service manager.
+const description = "Finds credentials with TruffleHog."
+
+// maxLineSize is the longest line of the scan's output written to the system
+// log. Longer lines are split.
+const maxLineSize = 64 * 1024
+
+// Config describes a service.
+type Config struct {
+	// Name identifies the service. On macOS, it's the daemon's launchd label,
+	// prefixed with "com.trufflesecurity." unless it has a dot.
+	Name string
+	// Args are the command and flags of the scan the service runs, such as
+	// ["syslog", "--address", ":514"].
+	Args []string
+}
+
+// runArgs returns the arguments the service manager starts the executable
+// with to run the service.
+func runArgs(cfg Config) []string {
+	return append([]string{"service", "run", "--name", cfg.Name, "--"}, cfg.Args...)
+}
+
+// scanArgs returns the arguments the scan is run with. Updates are left to
+// whoever manages the endpoint, since the updater would restart the scan
+// behind the service manager's back.
+func scanArgs(args []string) []string {
+	return append([]string{"--no-update"}, args...)
+}
+
+// systemLog is where a service writes the output of its scan.
+type systemLog interface {
+	Info(msg string) error
+	Error(msg string) error
+}
+
+// consoleLog writes to a terminal, for services run outside of a service
+// manager, such as while trying out their scan.
+type consoleLog struct {
+	w io.Writer
+}
+
+func (l consoleLog) Info(msg string) error {
+	_, err := fmt.Fprintln(l.w, msg)
+	return err
+}
+
+func (l consoleLog) Error(msg string) error {
+	_, err := fmt.Fprintln(l.w, msg)
+	return err
+}
+
+// runScan runs exe with args until it exits or ctx is done, writing each line
+// it outputs to log. It returns an error if the scan fails, but not if it's
+// stopped because ctx is done.
+func runScan(ctx context.Context, exe string, args []string, log systemLog) error {
+	cmd := exec.CommandContext(ctx, exe, args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return errors.WrapPrefix(err, "could not start scan", 0)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		forward(stdout, log.Info)
+	}()
+	go func() {
+		defer wg.Done()
+		forward(stderr, func(line string) error {
+			if isErrorLine(line) {
+				return log.Error(line)
+			}
+			return log.Info(line)
+		})
+	}()
+	wg.Wait()
+
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err != nil {
+		return errors.WrapPrefix(err, "scan failed", 0)
+	}
+	return nil
+}
+
+// forward calls write with each line read from r.
+func forward(r io.Reader, write func(line string) error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 4096), maxLineSize)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			_ = write(line)
+		}
+	}
+	// Drain the rest of a line too long to scan, so the scan isn't blocked
+	// writing it.
+	_, _ = io.Copy(io.Discard, r)
+}
+
+// isErrorLine reports whether a line the scan logged is an error, in the text
+// or JSON log format.
+func isErrorLine(line string) bool {
+	return strings.Contains(line, "\terror\t") || strings.Contains(line, `"level":"error"`)
+}
+
+// executable returns the path of the running executable, which services run.
+func executable() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", errors.WrapPrefix(err, "could not find the trufflehog executable", 0)
+	}
+	return exe, nil
+}
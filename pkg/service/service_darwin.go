@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"log/syslog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+// launchDaemons is where the property lists of system-wide launchd daemons
+// are kept.
+const launchDaemons = "/Library/LaunchDaemons"
+
+// Install installs and starts a launchd daemon that runs cfg's scan at boot.
+// launchd restarts the daemon if the scan fails. The daemon writes to the
+// unified log, with its label as the process's subsystem tag. Installing needs
+// root.
+func Install(cfg Config) error {
+	exe, err := executable()
+	if err != nil {
+		return err
+	}
+	l := label(cfg.Name)
+	path := plistPath(l)
+	if _, err := os.Stat(path); err == nil {
+		return errors.Errorf("daemon %q is already installed at %s", l, path)
+	}
+	args := append([]string{exe}, runArgs(cfg)...)
+	if err := os.WriteFile(path, launchdPlist(l, args), 0644); err != nil {
+		return errors.WrapPrefix(err, "could not write the daemon's property list", 0)
+	}
+	if err := launchctl("bootstrap", "system", path); err != nil {
+		_ = os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+// Uninstall stops and removes the launchd daemon named name.
+func Uninstall(name string) error {
+	l := label(name)
+	path := plistPath(l)
+	if _, err := os.Stat(path); err != nil {
+		return errors.Errorf("daemon %q is not installed", l)
+	}
+	if err := launchctl("bootout", "system/"+l); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return errors.WrapPrefix(err, "could not remove the daemon's property list", 0)
+	}
+	return nil
+}
+
+// Run runs cfg's scan until it exits or ctx is done, which it is when launchd
+// stops the daemon with SIGTERM.
+func Run(ctx context.Context, cfg Config) error {
+	exe, err := executable()
+	if err != nil {
+		return err
+	}
+	w, err := syslog.New(syslog.LOG_DAEMON|syslog.LOG_INFO, label(cfg.Name))
+	if err != nil {
+		return errors.WrapPrefix(err, "could not open the system log", 0)
+	}
+	defer w.Close()
+	return runScan(ctx, exe, scanArgs(cfg.Args), syslogLog{w})
+}
+
+func plistPath(label string) string {
+	return filepath.Join(launchDaemons, label+".plist")
+}
+
+func launchctl(args ...string) error {
+	out, err := exec.Command("launchctl", args...).CombinedOutput()
+	if err != nil {
+		return errors.Errorf("launchctl %s failed: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// syslogLog writes to the system log, which macOS keeps in the unified log.
+type syslogLog struct {
+	w *syslog.Writer
+}
+
+func (l syslogLog) Info(msg string) error {
+	return l.w.Info(msg)
+}
+
+func (l syslogLog) Error(msg string) error {
+	return l.w.Err(msg)
+}
@@ -0,0 +1,32 @@
+//go:build !windows && !darwin
+// +build !windows,!darwin
+
+package service
+
+import (
+	"context"
+	"os"
+	"runtime"
+
+	"github.com/go-errors/errors"
+)
+
+// Install isn't supported on this platform. Run scans persistently with the
+// platform's own service manager, such as a systemd unit, or in a container.
+func Install(cfg Config) error {
+	return errors.Errorf("installing services is not supported on %s: run the scan with systemd or in a container instead", runtime.GOOS)
+}
+
+// Uninstall isn't supported on this platform.
+func Uninstall(name string) error {
+	return errors.Errorf("uninstalling services is not supported on %s", runtime.GOOS)
+}
+
+// Run runs cfg's scan until it exits or ctx is done, writing to the terminal.
+func Run(ctx context.Context, cfg Config) error {
+	exe, err := executable()
+	if err != nil {
+		return err
+	}
+	return runScan(ctx, exe, scanArgs(cfg.Args), consoleLog{w: os.Stderr})
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/plist"
+)
+
+type recordingLog struct {
+	mu     sync.Mutex
+	info   []string
+	errors []string
+}
+
+func (l *recordingLog) Info(msg string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.info = append(l.info, msg)
+	return nil
+}
+
+func (l *recordingLog) Error(msg string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errors = append(l.errors, msg)
+	return nil
+}
+
+func TestRunScan(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+
+	log := &recordingLog{}
+	script := `echo '{"DetectorName":"AWS"}'; echo '{"level":"error","msg":"bad"}' >&2; printf 'info\tline\n' >&2`
+	if err := runScan(context.Background(), "sh", []string{"-c", script}, log); err != nil {
+		t.Fatalf("runScan() error = %v", err)
+	}
+	// Findings and logs are read concurrently, so aren't ordered between them.
+	sort.Strings(log.info)
+	wantInfo := []string{"info\tline", `{"DetectorName":"AWS"}`}
+	if diff := pretty.Compare(log.info, wantInfo); diff != "" {
+		t.Errorf("info diff: (-got +want)\n%s", diff)
+	}
+	wantErrors := []string{`{"level":"error","msg":"bad"}`}
+	if diff := pretty.Compare(log.errors, wantErrors); diff != "" {
+		t.Errorf("errors diff: (-got +want)\n%s", diff)
+	}
+
+	err := runScan(context.Background(), "sh", []string{"-c", "exit 3"}, &recordingLog{})
+	if err == nil || !strings.Contains(err.Error(), "scan failed") {
+		t.Errorf("runScan() error = %v, want a failed scan", err)
+	}
+
+	// A scan stopped with its service isn't a failure.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := runScan(ctx, "sh", []string{"-c", "exec sleep 10"}, &recordingLog{}); err != nil {
+		t.Errorf("runScan() error = %v, want nil once stopped", err)
+	}
+}
+
+func TestLaunchdPlist(t *testing.T) {
+	cfg := Config{Name: "syslog", Args: []string{"syslog", "--format", "<rfc5424>"}}
+	data := launchdPlist(label(cfg.Name), append([]string{"/usr/local/bin/trufflehog"}, runArgs(cfg)...))
+	got, err := plist.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode() error = %v\n%s", err, data)
+	}
+	want := map[string]interface{}{
+		"Label": "com.trufflesecurity.syslog",
+		"ProgramArguments": []interface{}{
+			"/usr/local/bin/trufflehog", "service", "run", "--name", "syslog", "--",
+			"syslog", "--format", "<rfc5424>",
+		},
+		"RunAtLoad": true,
+		"KeepAlive": map[string]interface{}{"SuccessfulExit": false},
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("plist diff: (-got +want)\n%s", diff)
+	}
+
+	if got := label("org.example.trufflehog"); got != "org.example.trufflehog" {
+		t.Errorf("label() = %q, want the name as it is", got)
+	}
+}
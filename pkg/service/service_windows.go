@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-errors/errors"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// eventID is the ID of the events services write to the Event Log.
+const eventID = 1
+
+// Install installs and starts a Windows service that runs cfg's scan at boot.
+// The service is restarted if the scan fails, and writes to the Application
+// event log with cfg.Name as the event source. Installing needs administrator
+// rights.
+func Install(cfg Config) error {
+	exe, err := executable()
+	if err != nil {
+		return err
+	}
+	m, err := mgr.Connect()
+	if err != nil {
+		return errors.WrapPrefix(err, "could not connect to the service manager", 0)
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(cfg.Name); err == nil {
+		s.Close()
+		return errors.Errorf("service %q is already installed", cfg.Name)
+	}
+	s, err := m.CreateService(cfg.Name, exe, mgr.Config{
+		StartType:   mgr.StartAutomatic,
+		DisplayName: fmt.Sprintf("TruffleHog (%s)", cfg.Name),
+		Description: description,
+	}, runArgs(cfg)...)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not create service", 0)
+	}
+	defer s.Close()
+
+	restart := []mgr.RecoveryAction{{Type: mgr.ServiceRestart, Delay: time.Minute}}
+	if err := s.SetRecoveryActions(restart, uint32((24 * time.Hour).Seconds())); err != nil {
+		_ = s.Delete()
+		return errors.WrapPrefix(err, "could not set the service to restart", 0)
+	}
+	if err := eventlog.InstallAsEventCreate(cfg.Name, eventlog.Error|eventlog.Warning|eventlog.Info); err != nil {
+		_ = s.Delete()
+		return errors.WrapPrefix(err, "could not register the event log source", 0)
+	}
+	if err := s.Start(); err != nil {
+		return errors.WrapPrefix(err, "could not start service", 0)
+	}
+	return nil
+}
+
+// Uninstall stops and removes the Windows service named name.
+func Uninstall(name string) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return errors.WrapPrefix(err, "could not connect to the service manager", 0)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(name)
+	if err != nil {
+		return errors.Errorf("service %q is not installed", name)
+	}
+	defer s.Close()
+
+	if status, err := s.Query(); err == nil && status.State != svc.Stopped {
+		if _, err := s.Control(svc.Stop); err != nil {
+			return errors.WrapPrefix(err, "could not stop service", 0)
+		}
+	}
+	if err := s.Delete(); err != nil {
+		return errors.WrapPrefix(err, "could not delete service", 0)
+	}
+	if err := eventlog.Remove(name); err != nil {
+		return errors.WrapPrefix(err, "could not remove the event log source", 0)
+	}
+	return nil
+}
+
+// Run runs cfg's scan until it exits, ctx is done, or the service manager
+// stops the service. Outside of the service manager, it writes to the
+// terminal.
+func Run(ctx context.Context, cfg Config) error {
+	exe, err := executable()
+	if err != nil {
+		return err
+	}
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		return errors.WrapPrefix(err, "could not tell if running as a service", 0)
+	}
+	if !isService {
+		return runScan(ctx, exe, scanArgs(cfg.Args), consoleLog{w: os.Stderr})
+	}
+
+	log, err := eventlog.Open(cfg.Name)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not open the event log", 0)
+	}
+	defer log.Close()
+	h := &handler{ctx: ctx, exe: exe, args: scanArgs(cfg.Args), log: eventLog{log}}
+	if err := svc.Run(cfg.Name, h); err != nil {
+		return errors.WrapPrefix(err, "service failed", 0)
+	}
+	return nil
+}
+
+// handler runs a scan as a Windows service.
+type handler struct {
+	ctx  context.Context
+	exe  string
+	args []string
+	log  systemLog
+}
+
+func (h *handler) Execute(_ []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+	ctx, cancel := context.WithCancel(h.ctx)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- runScan(ctx, h.exe, h.args, h.log)
+	}()
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case err := <-done:
+			return h.stopped(err)
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				cancel()
+				return h.stopped(<-done)
+			}
+		}
+	}
+}
+
+// stopped returns the exit code the service stops with. If the scan failed,
+// it exits without reporting the service as stopped, which the service
+// manager takes as a crash and restarts the service after.
+func (h *handler) stopped(err error) (bool, uint32) {
+	if err != nil {
+		_ = h.log.Error(err.Error())
+		os.Exit(1)
+	}
+	return false, 0
+}
+
+// eventLog writes to the Windows Event Log.
+type eventLog struct {
+	log *eventlog.Log
+}
+
+func (l eventLog) Info(msg string) error {
+	return l.log.Info(eventID, msg)
+}
+
+func (l eventLog) Error(msg string) error {
+	return l.log.Error(eventID, msg)
+}
@@ -0,0 +1,198 @@
+// Package defectdojo imports findings into DefectDojo when the scan finishes.
+package defectdojo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/findings"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sinks"
+)
+
+// scanType is the DefectDojo parser the findings are imported with. Its
+// unique_id_from_tool field carries the finding ID, which DefectDojo can
+// deduplicate findings on across imports.
+const scanType = "Generic Findings Import"
+
+// Config says where to import findings.
+type Config struct {
+	// URL is the DefectDojo server, such as https://defectdojo.example.com.
+	URL    string
+	APIKey string
+	// EngagementID is the engagement to import into. If it's not set, the
+	// findings are imported into the engagement named EngagementName of the
+	// product named ProductName, which are created if they don't exist.
+	EngagementID   int
+	ProductName    string
+	EngagementName string
+	// TLS configures connections to the server, such as to trust a private
+	// CA or authenticate with a client certificate.
+	TLS *credentialspb.TLSConfig
+	// Proxy is the proxy to connect to the server through, instead of the
+	// one set in the environment.
+	Proxy *credentialspb.Proxy
+}
+
+// Sink collects findings and imports them into DefectDojo as a single scan
+// when it's closed.
+type Sink struct {
+	config Config
+	client *http.Client
+	// now returns the date the scan is imported as.
+	now func() time.Time
+
+	mu       sync.Mutex
+	findings []finding
+}
+
+// Ensure the Sink satisfies the interface at compile time.
+var _ sinks.Sink = (*Sink)(nil)
+
+// New returns a sink that imports findings as configured.
+func New(config Config) (*Sink, error) {
+	if config.URL == "" || config.APIKey == "" {
+		return nil, errors.New("DefectDojo URL and API key are required")
+	}
+	if config.EngagementID == 0 && (config.ProductName == "" || config.EngagementName == "") {
+		return nil, errors.New("a DefectDojo engagement ID, or product and engagement names, are required")
+	}
+	httpOptions, err := common.NewHttpClientOptions(config.TLS, config.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	return &Sink{config: config, client: common.SaneHttpClientTimeOut(60, httpOptions...), now: time.Now}, nil
+}
+
+// finding is a finding in the format of the generic findings parser.
+type finding struct {
+	Title            string `json:"title"`
+	Description      string `json:"description"`
+	Severity         string `json:"severity"`
+	Date             string `json:"date"`
+	FilePath         string `json:"file_path,omitempty"`
+	Line             int    `json:"line,omitempty"`
+	UniqueIDFromTool string `json:"unique_id_from_tool"`
+	VulnIDFromTool   string `json:"vuln_id_from_tool"`
+	Mitigation       string `json:"mitigation"`
+	References       string `json:"references,omitempty"`
+	StaticFinding    bool   `json:"static_finding"`
+	Active           bool   `json:"active"`
+}
+
+func (s *Sink) Send(_ context.Context, r *detectors.ResultWithMetadata) error {
+	meta := sinks.Metadata(r)
+	status := "unverified"
+	if r.Verified {
+		status = "verified"
+	}
+
+	f := finding{
+		Title:            fmt.Sprintf("%s secret", r.DetectorType.String()),
+		Severity:         strings.Title(sinks.Severity(r)),
+		Date:             s.now().UTC().Format("2006-01-02"),
+		FilePath:         meta["file"],
+		UniqueIDFromTool: findings.ID(r),
+		VulnIDFromTool:   r.DetectorType.String(),
+		Mitigation:       "Revoke the secret, replace it, and remove it from the source and its history.",
+		References:       sinks.Link(meta),
+		StaticFinding:    true,
+		Active:           true,
+	}
+	if location := sinks.Location(meta); location != "" {
+		f.Title += " in " + location
+	}
+	f.Line, _ = strconv.Atoi(meta["line"])
+
+	var description strings.Builder
+	fmt.Fprintf(&description, "TruffleHog found a %s %s secret: `%s`\n\n", status, r.DetectorType.String(), sinks.Mask(r))
+	fmt.Fprintf(&description, "Source: %s\n", r.SourceName)
+	for _, k := range []string{"repository", "bucket", "commit", "email", "timestamp"} {
+		if v := meta[k]; v != "" {
+			fmt.Fprintf(&description, "%s: %s\n", strings.Title(k), v)
+		}
+	}
+	f.Description = description.String()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.findings = append(s.findings, f)
+	return nil
+}
+
+// Close imports the findings. Nothing is imported if there are none.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.findings) == 0 {
+		return nil
+	}
+
+	report, err := json.Marshal(map[string]interface{}{"findings": s.findings})
+	if err != nil {
+		return err
+	}
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	fields := map[string]string{
+		"scan_type":        scanType,
+		"scan_date":        s.now().UTC().Format("2006-01-02"),
+		"test_title":       "TruffleHog",
+		"minimum_severity": "Info",
+		"active":           "true",
+		"verified":         "false",
+	}
+	if s.config.EngagementID != 0 {
+		fields["engagement"] = strconv.Itoa(s.config.EngagementID)
+	} else {
+		fields["product_name"] = s.config.ProductName
+		fields["engagement_name"] = s.config.EngagementName
+		fields["auto_create_context"] = "true"
+	}
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			return err
+		}
+	}
+	file, err := form.CreateFormFile("file", "trufflehog.json")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(report); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(s.config.URL, "/")+"/api/v2/import-scan/", body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Token "+s.config.APIKey)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	res, err := s.client.Do(req)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not import findings into DefectDojo", 0)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return errors.Errorf("DefectDojo import failed with status %d: %s", res.StatusCode, msg)
+	}
+	s.findings = nil
+	return nil
+}
@@ -0,0 +1,120 @@
+package defectdojo
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/findings"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+func TestSink(t *testing.T) {
+	var fields map[string]string
+	var report struct {
+		Findings []finding `json:"findings"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/import-scan/" || r.Header.Get("Authorization") != "Token key" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if err := r.ParseMultipartForm(1024 * 1024); err != nil {
+			t.Error(err)
+		}
+		fields = map[string]string{}
+		for name, values := range r.MultipartForm.Value {
+			fields[name] = values[0]
+		}
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(file)
+		if err := json.Unmarshal(data, &report); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	s, err := New(Config{URL: server.URL + "/", APIKey: "key", ProductName: "api", EngagementName: "secrets"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.now = func() time.Time { return time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC) }
+
+	r := &detectors.ResultWithMetadata{
+		SourceName: "trufflehog - git",
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Git{
+				Git: &source_metadatapb.Git{Repository: "https://github.com/acme/api.git", Commit: "abc123", File: "config.py", Line: 10},
+			},
+		},
+		Result: detectors.Result{DetectorType: detectorspb.DetectorType_AWS, Raw: []byte("AKIAEXAMPLEKEY"), Verified: true},
+	}
+	if err := s.Send(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	wantFields := map[string]string{
+		"scan_type":           scanType,
+		"scan_date":           "2022-06-01",
+		"test_title":          "TruffleHog",
+		"minimum_severity":    "Info",
+		"active":              "true",
+		"verified":            "false",
+		"product_name":        "api",
+		"engagement_name":     "secrets",
+		"auto_create_context": "true",
+	}
+	if diff := pretty.Compare(fields, wantFields); diff != "" {
+		t.Errorf("form fields diff: (-got +want)\n%s", diff)
+	}
+	want := []finding{{
+		Title:            "AWS secret in config.py:10",
+		Description:      "TruffleHog found a verified AWS secret: `AKIA********`\n\nSource: trufflehog - git\nRepository: https://github.com/acme/api.git\nCommit: abc123\n",
+		Severity:         "High",
+		Date:             "2022-06-01",
+		FilePath:         "config.py",
+		Line:             10,
+		UniqueIDFromTool: findings.ID(r),
+		VulnIDFromTool:   "AWS",
+		Mitigation:       "Revoke the secret, replace it, and remove it from the source and its history.",
+		References:       "https://github.com/acme/api/blob/abc123/config.py#L10",
+		StaticFinding:    true,
+		Active:           true,
+	}}
+	if diff := pretty.Compare(report.Findings, want); diff != "" {
+		t.Errorf("findings diff: (-got +want)\n%s", diff)
+	}
+}
+
+func TestSink_ImportFailed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"engagement": ["Invalid pk"]}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	s, err := New(Config{URL: server.URL, APIKey: "key", EngagementID: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send(context.Background(), &detectors.ResultWithMetadata{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err == nil {
+		t.Error("Close() should fail when DefectDojo rejects the import")
+	}
+}
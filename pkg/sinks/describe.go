@@ -0,0 +1,135 @@
+package sinks
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
+)
+
+// Severities of findings, from most to least severe.
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+)
+
+// Severity returns the severity the detector gave r, or high for verified
+// results and medium for unverified ones if it didn't give one.
+func Severity(r *detectors.ResultWithMetadata) string {
+	switch severity := r.ExtraData["severity"]; severity {
+	case SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow:
+		return severity
+	}
+	if r.Verified {
+		return SeverityHigh
+	}
+	return SeverityMedium
+}
+
+// AtLeast reports whether severity is as severe as threshold. Unknown
+// severities are never as severe as a known one.
+func AtLeast(severity, threshold string) bool {
+	return severityRank[severity] >= severityRank[threshold] && severityRank[severity] > 0
+}
+
+var severityRank = map[string]int{
+	SeverityCritical: 4,
+	SeverityHigh:     3,
+	SeverityMedium:   2,
+	SeverityLow:      1,
+}
+
+// Metadata returns the fields of r's source metadata by their JSON names,
+// such as "file" and "line", along with its provenance, if it was unpacked
+// from archives, and its tenant, if it has one.
+func Metadata(r *detectors.ResultWithMetadata) map[string]string {
+	fields := map[string]string{}
+	if r.Tenant != "" {
+		fields["tenant"] = r.Tenant
+	}
+	if len(r.Provenance) > 0 {
+		fields["provenance"] = sources.ProvenancePath(r.Provenance)
+	}
+	if r.SourceMetadata == nil {
+		return fields
+	}
+	data, err := json.Marshal(r.SourceMetadata.Data)
+	if err != nil {
+		return fields
+	}
+	var sources map[string]map[string]interface{}
+	if err := json.Unmarshal(data, &sources); err != nil {
+		return fields
+	}
+	for _, source := range sources {
+		for k, v := range source {
+			fields[k] = fmt.Sprint(v)
+		}
+	}
+	return fields
+}
+
+// Location returns where in its source a finding is, such as a file and
+// line, from its metadata. The files of archives it was unpacked from follow
+// the file, such as "bundle.zip > zip:app.tar > tar:app.env".
+func Location(meta map[string]string) string {
+	location := firstOf(meta, "file", "link", "channel_name")
+	if provenance := meta["provenance"]; provenance != "" {
+		location += " > " + provenance
+	}
+	if line := meta["line"]; location != "" && line != "" && line != "0" {
+		location += ":" + line
+	}
+	return location
+}
+
+// Repository returns the repository, bucket, or other container a finding is
+// in, from its metadata. It returns an empty string if there isn't one.
+func Repository(meta map[string]string) string {
+	return firstOf(meta, "repository", "bucket", "channel_name", "project")
+}
+
+// Link returns a URL a finding can be viewed at, from its metadata. Sources
+// that don't link to their findings get a link built from the repository if
+// it's a URL. It returns an empty string if there's no link.
+func Link(meta map[string]string) string {
+	link := meta["link"]
+	repo, commit := meta["repository"], meta["commit"]
+	if link == "" && commit != "" && strings.HasPrefix(repo, "https://") && strings.HasSuffix(repo, ".git") {
+		link = git.GenerateLink(repo, commit, meta["file"])
+	}
+	if !strings.HasPrefix(link, "https://") && !strings.HasPrefix(link, "http://") {
+		return ""
+	}
+	if line := meta["line"]; strings.Contains(link, "/blob/") && !strings.Contains(link, "#") && line != "" && line != "0" {
+		link += "#L" + line
+	}
+	return link
+}
+
+// Mask hides most of r's secret so it can be shared. The detector's redacted
+// form is used if it has one.
+func Mask(r *detectors.ResultWithMetadata) string {
+	if r.Redacted != "" {
+		return r.Redacted
+	}
+	raw := []rune(strings.TrimSpace(string(r.Raw)))
+	if len(raw) <= 8 {
+		return strings.Repeat("*", len(raw))
+	}
+	return string(raw[:4]) + strings.Repeat("*", 8)
+}
+
+func firstOf(fields map[string]string, keys ...string) string {
+	for _, k := range keys {
+		if v := fields[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,305 @@
+// Package elasticsearch bulk indexes findings into Elasticsearch or
+// OpenSearch, in a new index each day.
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/findings"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sinks"
+)
+
+const (
+	// DefaultIndex is the prefix of the daily indices findings are written to.
+	DefaultIndex = "trufflehog"
+	// batchSize is the number of findings sent in each bulk request.
+	batchSize = 500
+)
+
+// Config says where and how to index findings.
+type Config struct {
+	// URL is the cluster, such as https://elasticsearch.example.com:9200.
+	URL string
+	// Username and Password authenticate with basic auth, and APIKey with an
+	// Elasticsearch API key, encoded as the API returns it.
+	Username string
+	Password string
+	APIKey   string
+	// Index is the prefix of the daily indices, which are named like
+	// trufflehog-2022.06.01. It defaults to DefaultIndex.
+	Index string
+	// TemplateFile is an index template to install for the indices instead of
+	// the default one.
+	TemplateFile string
+	// ILMPolicy is the index lifecycle policy the default template applies to
+	// the indices, such as one that deletes them after a year.
+	ILMPolicy string
+	// TLS configures connections to the cluster, such as to trust a private
+	// CA or authenticate with a client certificate.
+	TLS *credentialspb.TLSConfig
+	// Proxy is the proxy to connect to the cluster through, instead of the
+	// one set in the environment.
+	Proxy *credentialspb.Proxy
+}
+
+// Sink indexes findings in batches. Each finding's document ID is its finding
+// ID, so a finding reported twice in a day is indexed once.
+type Sink struct {
+	config Config
+	client *http.Client
+	// now returns the time findings are indexed at.
+	now func() time.Time
+
+	mu    sync.Mutex
+	batch bytes.Buffer
+	count int
+}
+
+// Ensure the Sink satisfies the interface at compile time.
+var _ sinks.Sink = (*Sink)(nil)
+
+// New installs the index template for the indices and returns a sink that
+// indexes findings into them.
+func New(ctx context.Context, config Config) (*Sink, error) {
+	if config.URL == "" {
+		return nil, errors.New("an Elasticsearch URL is required")
+	}
+	if config.Index == "" {
+		config.Index = DefaultIndex
+	}
+	httpOptions, err := common.NewHttpClientOptions(config.TLS, config.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	s := &Sink{config: config, client: common.SaneHttpClientTimeOut(30, httpOptions...), now: time.Now}
+	if err := s.installTemplate(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// document is how a finding is indexed. The secret itself isn't indexed, but
+// its hash is, so unique secrets can be counted.
+type document struct {
+	Timestamp  time.Time         `json:"@timestamp"`
+	FindingID  string            `json:"finding_id"`
+	Detector   string            `json:"detector"`
+	Verified   bool              `json:"verified"`
+	Severity   string            `json:"severity"`
+	Redacted   string            `json:"redacted"`
+	SecretHash string            `json:"secret_hash"`
+	SourceType string            `json:"source_type"`
+	SourceName string            `json:"source_name"`
+	Repository string            `json:"repository,omitempty"`
+	File       string            `json:"file,omitempty"`
+	Line       int64             `json:"line,omitempty"`
+	Commit     string            `json:"commit,omitempty"`
+	Email      string            `json:"email,omitempty"`
+	Link       string            `json:"link,omitempty"`
+	ExtraData  map[string]string `json:"extra_data,omitempty"`
+}
+
+func (s *Sink) Send(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	meta := sinks.Metadata(r)
+	hash := sha256.Sum256(r.Raw)
+	doc := document{
+		Timestamp:  s.now().UTC(),
+		FindingID:  findings.ID(r),
+		Detector:   r.DetectorType.String(),
+		Verified:   r.Verified,
+		Severity:   sinks.Severity(r),
+		Redacted:   sinks.Mask(r),
+		SecretHash: hex.EncodeToString(hash[:]),
+		SourceType: r.SourceType.String(),
+		SourceName: r.SourceName,
+		Repository: sinks.Repository(meta),
+		File:       meta["file"],
+		Commit:     meta["commit"],
+		Email:      meta["email"],
+		Link:       sinks.Link(meta),
+		ExtraData:  r.ExtraData,
+	}
+	doc.Line, _ = strconv.ParseInt(meta["line"], 10, 64)
+
+	action := map[string]map[string]string{
+		"index": {"_index": s.config.Index + "-" + doc.Timestamp.Format("2006.01.02"), "_id": doc.FindingID},
+	}
+	actionLine, err := json.Marshal(action)
+	if err != nil {
+		return err
+	}
+	docLine, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batch.Write(actionLine)
+	s.batch.WriteByte('\n')
+	s.batch.Write(docLine)
+	s.batch.WriteByte('\n')
+	s.count++
+	if s.count < batchSize {
+		return nil
+	}
+	return s.flush(ctx)
+}
+
+// Close indexes the findings that haven't been sent yet.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flush(context.Background())
+}
+
+// bulkResponse is the part of the bulk API's response used to find errors.
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+func (s *Sink) flush(ctx context.Context) error {
+	if s.count == 0 {
+		return nil
+	}
+	body := append([]byte{}, s.batch.Bytes()...)
+	count := s.count
+	s.batch.Reset()
+	s.count = 0
+
+	res, err := s.do(ctx, http.MethodPost, "/_bulk", body, "application/x-ndjson")
+	if err != nil {
+		return errors.WrapPrefix(err, "could not index findings", 0)
+	}
+	var bulk bulkResponse
+	if err := json.Unmarshal(res, &bulk); err != nil {
+		return errors.WrapPrefix(err, "could not parse bulk response", 0)
+	}
+	if !bulk.Errors {
+		return nil
+	}
+	failed, reason := 0, ""
+	for _, item := range bulk.Items {
+		for _, result := range item {
+			if result.Status >= 300 {
+				failed++
+				if reason == "" {
+					reason = result.Error.Type + ": " + result.Error.Reason
+				}
+			}
+		}
+	}
+	return errors.Errorf("Elasticsearch rejected %d of %d findings: %s", failed, count, reason)
+}
+
+// installTemplate installs the index template for the sink's indices.
+func (s *Sink) installTemplate(ctx context.Context) error {
+	var template []byte
+	if s.config.TemplateFile != "" {
+		data, err := os.ReadFile(s.config.TemplateFile)
+		if err != nil {
+			return errors.WrapPrefix(err, "could not read index template", 0)
+		}
+		template = data
+	} else {
+		data, err := json.Marshal(defaultTemplate(s.config.Index, s.config.ILMPolicy))
+		if err != nil {
+			return err
+		}
+		template = data
+	}
+	if _, err := s.do(ctx, http.MethodPut, "/_index_template/"+s.config.Index, template, "application/json"); err != nil {
+		return errors.WrapPrefix(err, "could not install index template", 0)
+	}
+	return nil
+}
+
+// defaultTemplate maps the fields of documents so they can be aggregated on
+// in dashboards.
+func defaultTemplate(index, ilmPolicy string) map[string]interface{} {
+	keyword := map[string]string{"type": "keyword"}
+	settings := map[string]interface{}{}
+	if ilmPolicy != "" {
+		settings["index.lifecycle.name"] = ilmPolicy
+	}
+	return map[string]interface{}{
+		"index_patterns": []string{index + "-*"},
+		"template": map[string]interface{}{
+			"settings": settings,
+			"mappings": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"@timestamp":  map[string]string{"type": "date"},
+					"finding_id":  keyword,
+					"detector":    keyword,
+					"verified":    map[string]string{"type": "boolean"},
+					"severity":    keyword,
+					"redacted":    keyword,
+					"secret_hash": keyword,
+					"source_type": keyword,
+					"source_name": keyword,
+					"repository":  keyword,
+					"file":        keyword,
+					"line":        map[string]string{"type": "long"},
+					"commit":      keyword,
+					"email":       keyword,
+					"link":        keyword,
+					"extra_data":  map[string]string{"type": "flattened"},
+				},
+			},
+		},
+	}
+}
+
+// do sends a request to the cluster and returns the response body.
+func (s *Sink) do(ctx context.Context, method, path string, body []byte, contentType string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(s.config.URL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	switch {
+	case s.config.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+s.config.APIKey)
+	case s.config.Username != "":
+		req.SetBasicAuth(s.config.Username, s.config.Password)
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= 300 {
+		if len(data) > 1024 {
+			data = data[:1024]
+		}
+		return nil, errors.Errorf("request failed with status %d: %s", res.StatusCode, data)
+	}
+	return data, nil
+}
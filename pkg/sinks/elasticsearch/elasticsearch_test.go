@@ -0,0 +1,142 @@
+package elasticsearch
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/findings"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+func TestSink(t *testing.T) {
+	var template map[string]interface{}
+	var lines []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "ApiKey key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/_index_template/secrets":
+			if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+				t.Error(err)
+			}
+			w.Write([]byte(`{"acknowledged": true}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/_bulk":
+			scanner := bufio.NewScanner(r.Body)
+			for scanner.Scan() {
+				var line map[string]interface{}
+				if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+					t.Error(err)
+				}
+				lines = append(lines, line)
+			}
+			w.Write([]byte(`{"errors": false, "items": [{"index": {"status": 201}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	s, err := New(context.Background(), Config{URL: server.URL + "/", APIKey: "key", Index: "secrets", ILMPolicy: "one-year"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.now = func() time.Time { return time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC) }
+
+	settings := template["template"].(map[string]interface{})["settings"]
+	if diff := pretty.Compare(settings, map[string]interface{}{"index.lifecycle.name": "one-year"}); diff != "" {
+		t.Errorf("template settings diff: (-got +want)\n%s", diff)
+	}
+
+	r := &detectors.ResultWithMetadata{
+		SourceType: sourcespb.SourceType_SOURCE_TYPE_GIT,
+		SourceName: "trufflehog - git",
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Git{
+				Git: &source_metadatapb.Git{Repository: "https://github.com/acme/api.git", Commit: "abc123", File: "config.py", Line: 10},
+			},
+		},
+		Result: detectors.Result{DetectorType: detectorspb.DetectorType_AWS, Raw: []byte("AKIAEXAMPLEKEY"), Verified: true},
+	}
+	if err := s.Send(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 0 {
+		t.Fatalf("findings were indexed before the batch was full")
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	hash := sha256.Sum256(r.Raw)
+	want := []map[string]interface{}{
+		{"index": map[string]interface{}{"_index": "secrets-2022.06.01", "_id": findings.ID(r)}},
+		{
+			"@timestamp":  "2022-06-01T12:00:00Z",
+			"finding_id":  findings.ID(r),
+			"detector":    "AWS",
+			"verified":    true,
+			"severity":    "high",
+			"redacted":    "AKIA********",
+			"secret_hash": hex.EncodeToString(hash[:]),
+			"source_type": "SOURCE_TYPE_GIT",
+			"source_name": "trufflehog - git",
+			"repository":  "https://github.com/acme/api.git",
+			"file":        "config.py",
+			"line":        float64(10),
+			"commit":      "abc123",
+			"link":        "https://github.com/acme/api/blob/abc123/config.py#L10",
+		},
+	}
+	if diff := pretty.Compare(lines, want); diff != "" {
+		t.Errorf("bulk request diff: (-got +want)\n%s", diff)
+	}
+}
+
+func TestSink_Rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/_bulk" {
+			w.Write([]byte(`{"errors": true, "items": [{"index": {"status": 400, "error": {"type": "mapper_parsing_exception", "reason": "failed to parse"}}}]}`))
+			return
+		}
+		w.Write([]byte(`{"acknowledged": true}`))
+	}))
+	defer server.Close()
+
+	s, err := New(context.Background(), Config{URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send(context.Background(), &detectors.ResultWithMetadata{}); err != nil {
+		t.Fatal(err)
+	}
+	err = s.Close()
+	if err == nil || !strings.Contains(err.Error(), "mapper_parsing_exception") {
+		t.Errorf("Close() error = %v, want the bulk item error", err)
+	}
+}
+
+func TestNew_TemplateFailed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	if _, err := New(context.Background(), Config{URL: server.URL}); err == nil {
+		t.Error("New() should fail when the index template can't be installed")
+	}
+}
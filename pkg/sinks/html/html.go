@@ -0,0 +1,218 @@
+// Package html writes findings to an HTML report when the scan finishes.
+package html
+
+import (
+	"context"
+	_ "embed"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sinks"
+)
+
+//go:embed report.html.tmpl
+var reportTemplate string
+
+var tmpl = template.Must(template.New("report").Parse(reportTemplate))
+
+// Sink collects findings and renders them as an HTML report when it's closed.
+// Findings are grouped by detector and then by repository, or by bucket or
+// source for sources without repositories.
+type Sink struct {
+	path string
+	// Encrypt, if set, wraps the report file so the report is written
+	// encrypted, such as with encrypt.Recipients.Encrypt.
+	Encrypt func(w io.Writer) (io.WriteCloser, error)
+	// now returns the time the report is generated.
+	now func() time.Time
+
+	mu       sync.Mutex
+	findings []*detectors.ResultWithMetadata
+}
+
+// Ensure the Sink satisfies the interface at compile time.
+var _ sinks.Sink = (*Sink)(nil)
+
+// New returns a sink that writes a report to path.
+func New(path string) (*Sink, error) {
+	if path == "" {
+		return nil, errors.New("html report path is required")
+	}
+	return &Sink{path: path, now: time.Now}, nil
+}
+
+func (s *Sink) Send(_ context.Context, r *detectors.ResultWithMetadata) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.findings = append(s.findings, r)
+	return nil
+}
+
+// Close writes the report.
+func (s *Sink) Close() error {
+	f, err := os.Create(s.path)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not create html report", 0)
+	}
+	if err := s.write(f); err != nil {
+		f.Close()
+		return errors.WrapPrefix(err, "could not write html report", 0)
+	}
+	return f.Close()
+}
+
+// write renders the report to w, encrypted if the sink encrypts reports.
+func (s *Sink) write(w io.Writer) error {
+	if s.Encrypt == nil {
+		return s.render(w)
+	}
+	encrypted, err := s.Encrypt(w)
+	if err != nil {
+		return err
+	}
+	if err := s.render(encrypted); err != nil {
+		encrypted.Close()
+		return err
+	}
+	return encrypted.Close()
+}
+
+func (s *Sink) render(w io.Writer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return tmpl.Execute(w, newReport(s.findings, s.now()))
+}
+
+// report is the data the template renders.
+type report struct {
+	Generated time.Time
+	Findings  int
+	Secrets   int
+	Verified  int
+	Detectors []*detectorGroup
+}
+
+type detectorGroup struct {
+	Name     string
+	Findings int
+	Verified int
+	Groups   []*repositoryGroup
+}
+
+type repositoryGroup struct {
+	Name    string
+	Secrets []*secret
+}
+
+// secret is a unique secret and everywhere it was found.
+type secret struct {
+	Display     string
+	Verified    bool
+	ExtraData   []string
+	Occurrences []occurrence
+}
+
+type occurrence struct {
+	Location string
+	Link     string
+	Details  []string
+	// Context is the data around the secret at this location, if it was
+	// recorded.
+	Context string
+}
+
+func newReport(results []*detectors.ResultWithMetadata, generated time.Time) *report {
+	rep := &report{Generated: generated.UTC(), Findings: len(results)}
+	byDetector := map[string]*detectorGroup{}
+	byRepository := map[string]*repositoryGroup{}
+	bySecret := map[string]*secret{}
+
+	for _, r := range results {
+		name := r.DetectorType.String()
+		d := byDetector[name]
+		if d == nil {
+			d = &detectorGroup{Name: name}
+			byDetector[name] = d
+			rep.Detectors = append(rep.Detectors, d)
+		}
+		d.Findings++
+
+		meta := sinks.Metadata(r)
+		repoName := sinks.Repository(meta)
+		if repoName == "" {
+			repoName = r.SourceName
+		}
+		repoKey := name + "\x00" + repoName
+		g := byRepository[repoKey]
+		if g == nil {
+			g = &repositoryGroup{Name: repoName}
+			byRepository[repoKey] = g
+			d.Groups = append(d.Groups, g)
+		}
+
+		secretKey := repoKey + "\x00" + string(r.Raw)
+		sec := bySecret[secretKey]
+		if sec == nil {
+			sec = &secret{Display: sinks.Mask(r)}
+			bySecret[secretKey] = sec
+			g.Secrets = append(g.Secrets, sec)
+			rep.Secrets++
+		}
+		if r.Verified && !sec.Verified {
+			sec.Verified = true
+			d.Verified++
+			rep.Verified++
+		}
+		if len(sec.ExtraData) == 0 {
+			for k, v := range r.ExtraData {
+				// Context differs between occurrences, so it's shown with each.
+				if k == detectors.SnippetKey {
+					continue
+				}
+				sec.ExtraData = append(sec.ExtraData, k+": "+v)
+			}
+			sort.Strings(sec.ExtraData)
+		}
+		o := newOccurrence(meta)
+		o.Context = r.ExtraData[detectors.SnippetKey]
+		sec.Occurrences = append(sec.Occurrences, o)
+	}
+
+	sort.SliceStable(rep.Detectors, func(i, j int) bool {
+		a, b := rep.Detectors[i], rep.Detectors[j]
+		if a.Verified != b.Verified {
+			return a.Verified > b.Verified
+		}
+		return a.Name < b.Name
+	})
+	for _, d := range rep.Detectors {
+		sort.SliceStable(d.Groups, func(i, j int) bool { return d.Groups[i].Name < d.Groups[j].Name })
+		for _, g := range d.Groups {
+			sort.SliceStable(g.Secrets, func(i, j int) bool {
+				a, b := g.Secrets[i], g.Secrets[j]
+				if a.Verified != b.Verified {
+					return a.Verified
+				}
+				return len(a.Occurrences) > len(b.Occurrences)
+			})
+		}
+	}
+	return rep
+}
+
+func newOccurrence(meta map[string]string) occurrence {
+	o := occurrence{Location: sinks.Location(meta), Link: sinks.Link(meta)}
+	for _, k := range []string{"commit", "email", "timestamp"} {
+		if v := meta[k]; v != "" {
+			o.Details = append(o.Details, k+": "+v)
+		}
+	}
+	return o
+}
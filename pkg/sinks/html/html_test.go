@@ -0,0 +1,188 @@
+package html
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+func gitResult(detector detectorspb.DetectorType, raw, repo, file string, line int64, verified bool) *detectors.ResultWithMetadata {
+	return &detectors.ResultWithMetadata{
+		SourceName: "trufflehog - git",
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Git{
+				Git: &source_metadatapb.Git{Repository: repo, Commit: "abc123", File: file, Line: line, Email: "dev <dev@example.com>"},
+			},
+		},
+		Result: detectors.Result{DetectorType: detector, Raw: []byte(raw), Verified: verified},
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	const repo = "https://github.com/acme/api.git"
+	results := []*detectors.ResultWithMetadata{
+		gitResult(detectorspb.DetectorType_Github, "ghp_unverifiedtoken", repo, "ci.yml", 3, false),
+		gitResult(detectorspb.DetectorType_AWS, "AKIAEXAMPLEKEY", repo, "config.py", 10, false),
+		gitResult(detectorspb.DetectorType_AWS, "AKIAEXAMPLEKEY", repo, "deploy.sh", 0, true),
+		gitResult(detectorspb.DetectorType_AWS, "AKIAEXAMPLEKEY", "/home/dev/tools", "env", 1, false),
+		{
+			SourceName: "s3",
+			SourceMetadata: &source_metadatapb.MetaData{
+				Data: &source_metadatapb.MetaData_S3{
+					S3: &source_metadatapb.S3{Bucket: "backups", File: "db.env", Link: "https://backups.s3.amazonaws.com/db.env"},
+				},
+			},
+			Result: detectors.Result{DetectorType: detectorspb.DetectorType_AWS, Raw: []byte("AKIAOTHERKEY"), Redacted: "AKIAOTHERKEY"},
+		},
+	}
+	generated := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	got := newReport(results, generated)
+	want := &report{
+		Generated: generated,
+		Findings:  5,
+		Secrets:   4,
+		Verified:  1,
+		Detectors: []*detectorGroup{
+			{
+				Name:     "AWS",
+				Findings: 4,
+				Verified: 1,
+				Groups: []*repositoryGroup{
+					{
+						Name: "/home/dev/tools",
+						Secrets: []*secret{{
+							Display:     "AKIA********",
+							Occurrences: []occurrence{{Location: "env:1", Details: []string{"commit: abc123", "email: dev <dev@example.com>"}}},
+						}},
+					},
+					{
+						Name: "backups",
+						Secrets: []*secret{{
+							Display:     "AKIAOTHERKEY",
+							Occurrences: []occurrence{{Location: "db.env", Link: "https://backups.s3.amazonaws.com/db.env"}},
+						}},
+					},
+					{
+						Name: repo,
+						Secrets: []*secret{{
+							Display:  "AKIA********",
+							Verified: true,
+							Occurrences: []occurrence{
+								{
+									Location: "config.py:10",
+									Link:     "https://github.com/acme/api/blob/abc123/config.py#L10",
+									Details:  []string{"commit: abc123", "email: dev <dev@example.com>"},
+								},
+								{
+									Location: "deploy.sh",
+									Link:     "https://github.com/acme/api/blob/abc123/deploy.sh",
+									Details:  []string{"commit: abc123", "email: dev <dev@example.com>"},
+								},
+							},
+						}},
+					},
+				},
+			},
+			{
+				Name:     "Github",
+				Findings: 1,
+				Groups: []*repositoryGroup{{
+					Name: repo,
+					Secrets: []*secret{{
+						Display: "ghp_********",
+						Occurrences: []occurrence{{
+							Location: "ci.yml:3",
+							Link:     "https://github.com/acme/api/blob/abc123/ci.yml#L3",
+							Details:  []string{"commit: abc123", "email: dev <dev@example.com>"},
+						}},
+					}},
+				}},
+			},
+		},
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("newReport() diff: (-got +want)\n%s", diff)
+	}
+}
+
+func TestSink_render(t *testing.T) {
+	s := &Sink{now: func() time.Time { return time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC) }}
+	r := gitResult(detectorspb.DetectorType_AWS, "AKIAEXAMPLEKEY", "https://github.com/acme/api.git", "config.py", 10, true)
+	r.ExtraData = map[string]string{"account": "<1234>", detectors.SnippetKey: "aws_key = REDACTED\n# <prod>"}
+	if err := s.Send(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := s.render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+	for _, want := range []string{
+		`Generated 2022-06-01 12:00:00 UTC`,
+		`<h2 id="AWS">AWS</h2>`,
+		`<span class="badge verified">verified</span>`,
+		`<a href="https://github.com/acme/api/blob/abc123/config.py#L10">config.py:10</a>`,
+		// Values from findings are escaped.
+		`account: &lt;1234&gt;`,
+		// Context is shown with the occurrence it was found at.
+		`<pre class="context">aws_key = REDACTED
+# &lt;prod&gt;</pre>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report doesn't contain %q:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "AKIAEXAMPLEKEY") {
+		t.Error("report contains the raw secret")
+	}
+}
+
+// prefixWriter stands in for an encrypting writer, marking where it wrote.
+type prefixWriter struct {
+	io.Writer
+	closed bool
+}
+
+func (p *prefixWriter) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestSink_Close_encrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.html")
+	s, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var encrypted *prefixWriter
+	s.Encrypt = func(w io.Writer) (io.WriteCloser, error) {
+		if _, err := io.WriteString(w, "ENCRYPTED\n"); err != nil {
+			return nil, err
+		}
+		encrypted = &prefixWriter{Writer: w}
+		return encrypted, nil
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "ENCRYPTED\n<!") || !encrypted.closed {
+		t.Errorf("report wasn't written through Encrypt: %.40q", data)
+	}
+}
@@ -0,0 +1,73 @@
+package kafka
+
+import (
+	"context"
+
+	"github.com/go-errors/errors"
+	"github.com/segmentio/kafka-go"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sinks"
+)
+
+// messageWriter is the part of kafka.Writer used by the sink.
+type messageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// Sink publishes findings to a Kafka topic.
+type Sink struct {
+	writer  messageWriter
+	format  string
+	key     string
+	headers []kafka.Header
+}
+
+// Ensure the Sink satisfies the interface at compile time.
+var _ sinks.Sink = (*Sink)(nil)
+
+// New returns a sink that writes findings to topic on brokers, using the
+// message key from opts as the Kafka message key.
+func New(brokers []string, topic string, opts sinks.MessageOptions) (*Sink, error) {
+	if len(brokers) == 0 || topic == "" {
+		return nil, errors.New("kafka brokers and topic are required")
+	}
+	return newSink(&kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+	}, opts), nil
+}
+
+func newSink(writer messageWriter, opts sinks.MessageOptions) *Sink {
+	s := &Sink{writer: writer, format: opts.Format, key: opts.Key}
+	for name, value := range opts.Headers {
+		s.headers = append(s.headers, kafka.Header{Key: name, Value: []byte(value)})
+	}
+	return s
+}
+
+func (s *Sink) Send(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	value, err := sinks.Encode(s.format, r)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not encode finding", 0)
+	}
+	key, err := sinks.Key(s.key, r)
+	if err != nil {
+		return err
+	}
+	msg := kafka.Message{Value: value, Headers: s.headers}
+	if key != "" {
+		msg.Key = []byte(key)
+	}
+	if err := s.writer.WriteMessages(ctx, msg); err != nil {
+		return errors.WrapPrefix(err, "could not publish finding to kafka", 0)
+	}
+	return nil
+}
+
+func (s *Sink) Close() error {
+	return s.writer.Close()
+}
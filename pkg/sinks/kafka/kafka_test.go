@@ -0,0 +1,67 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sinks"
+)
+
+type fakeWriter struct {
+	msgs   []kafka.Message
+	closed bool
+}
+
+func (w *fakeWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	w.msgs = append(w.msgs, msgs...)
+	return nil
+}
+
+func (w *fakeWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestSink_Send(t *testing.T) {
+	writer := &fakeWriter{}
+	s := newSink(writer, sinks.MessageOptions{Key: sinks.KeyDetector, Headers: map[string]string{"team": "appsec"}})
+
+	err := s.Send(context.Background(), &detectors.ResultWithMetadata{
+		Result: detectors.Result{DetectorType: detectorspb.DetectorType_AWS, Verified: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(writer.msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(writer.msgs))
+	}
+	msg := writer.msgs[0]
+	if string(msg.Key) != "AWS" {
+		t.Errorf("unexpected key: %q", msg.Key)
+	}
+	if len(msg.Headers) != 1 || msg.Headers[0].Key != "team" || string(msg.Headers[0].Value) != "appsec" {
+		t.Errorf("unexpected headers: %v", msg.Headers)
+	}
+	var body struct {
+		DetectorName string
+		Verified     bool
+	}
+	if err := json.Unmarshal(msg.Value, &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.DetectorName != "AWS" || !body.Verified {
+		t.Errorf("unexpected message body: %s", msg.Value)
+	}
+	if !writer.closed {
+		t.Error("writer was not closed")
+	}
+}
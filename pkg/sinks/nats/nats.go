@@ -0,0 +1,69 @@
+package nats
+
+import (
+	"context"
+
+	"github.com/go-errors/errors"
+	"github.com/nats-io/nats.go"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sinks"
+)
+
+// KeyHeader is the message header that carries the finding's key, since NATS
+// messages have no key of their own.
+const KeyHeader = "Trufflehog-Key"
+
+// Sink publishes findings to a NATS subject.
+type Sink struct {
+	conn    *nats.Conn
+	subject string
+	opts    sinks.MessageOptions
+}
+
+// Ensure the Sink satisfies the interface at compile time.
+var _ sinks.Sink = (*Sink)(nil)
+
+// New connects to the NATS server at url and returns a sink that publishes
+// findings to subject. Messages carry the message key from opts in KeyHeader.
+func New(url, subject string, opts sinks.MessageOptions) (*Sink, error) {
+	if subject == "" {
+		return nil, errors.New("a NATS subject is required")
+	}
+	conn, err := nats.Connect(url, nats.Name("trufflehog"))
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not connect to NATS", 0)
+	}
+	return &Sink{conn: conn, subject: subject, opts: opts}, nil
+}
+
+func (s *Sink) Send(_ context.Context, r *detectors.ResultWithMetadata) error {
+	data, err := sinks.Encode(s.opts.Format, r)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not encode finding", 0)
+	}
+	key, err := sinks.Key(s.opts.Key, r)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(s.subject)
+	msg.Data = data
+	for name, value := range s.opts.Headers {
+		msg.Header.Set(name, value)
+	}
+	if key != "" {
+		msg.Header.Set(KeyHeader, key)
+	}
+	if err := s.conn.PublishMsg(msg); err != nil {
+		return errors.WrapPrefix(err, "could not publish finding to NATS", 0)
+	}
+	return nil
+}
+
+// Close flushes published findings to the server and closes the connection.
+func (s *Sink) Close() error {
+	err := s.conn.Flush()
+	s.conn.Close()
+	return err
+}
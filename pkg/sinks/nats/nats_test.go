@@ -0,0 +1,65 @@
+package nats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sinks"
+)
+
+func TestSink_Send(t *testing.T) {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = server.RANDOM_PORT
+	srv := natsserver.RunServer(&opts)
+	defer srv.Shutdown()
+
+	sub, err := nats.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	msgs := make(chan *nats.Msg, 1)
+	if _, err := sub.ChanSubscribe("findings", msgs); err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := New(srv.ClientURL(), "findings", sinks.MessageOptions{Key: sinks.KeySource, Headers: map[string]string{"Team": "appsec"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Send(context.Background(), &detectors.ResultWithMetadata{
+		SourceName: "trufflehog - git",
+		Result:     detectors.Result{DetectorType: detectorspb.DetectorType_AWS},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-msgs:
+		if got := msg.Header.Get(KeyHeader); got != "trufflehog - git" {
+			t.Errorf("unexpected key header: %q", got)
+		}
+		if got := msg.Header.Get("Team"); got != "appsec" {
+			t.Errorf("unexpected team header: %q", got)
+		}
+		if len(msg.Data) == 0 {
+			t.Error("message has no data")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for finding")
+	}
+}
@@ -0,0 +1,187 @@
+// Package opsgenie creates Opsgenie alerts for verified findings.
+package opsgenie
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sinks"
+)
+
+const (
+	// DefaultURL is the Opsgenie API in the US region. Accounts in the EU
+	// region use https://api.eu.opsgenie.com.
+	DefaultURL = "https://api.opsgenie.com"
+	// maxMessage is the longest alert message Opsgenie accepts.
+	maxMessage = 130
+)
+
+// Config says which account to alert and for what.
+type Config struct {
+	// APIKey is the key of an API integration.
+	APIKey string
+	// MinSeverity is the least severe finding that creates an alert. It
+	// defaults to sinks.SeverityHigh.
+	MinSeverity string
+	// URL is the Opsgenie API. It defaults to DefaultURL.
+	URL string
+	// TLS configures connections to the API, such as to trust the CA of a
+	// proxy that intercepts them.
+	TLS *credentialspb.TLSConfig
+	// Proxy is the proxy to connect to the API through, instead of the one
+	// set in the environment.
+	Proxy *credentialspb.Proxy
+}
+
+// Sink creates an alert for each verified finding at least as severe as the
+// configured severity. Alerts are deduplicated by a hash of the secret, so a
+// secret found in many places raises a single alert.
+type Sink struct {
+	config Config
+	client *http.Client
+
+	mu   sync.Mutex
+	sent map[string]struct{}
+}
+
+// Ensure the Sink satisfies the interface at compile time.
+var _ sinks.Sink = (*Sink)(nil)
+
+// New returns a sink that alerts as configured.
+func New(config Config) (*Sink, error) {
+	if config.APIKey == "" {
+		return nil, errors.New("an Opsgenie API key is required")
+	}
+	if config.MinSeverity == "" {
+		config.MinSeverity = sinks.SeverityHigh
+	}
+	if config.URL == "" {
+		config.URL = DefaultURL
+	}
+	httpOptions, err := common.NewHttpClientOptions(config.TLS, config.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	return &Sink{config: config, client: common.SaneHttpClientTimeOut(30, httpOptions...), sent: map[string]struct{}{}}, nil
+}
+
+type alert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description"`
+	Tags        []string          `json:"tags"`
+	Details     map[string]string `json:"details"`
+	Entity      string            `json:"entity,omitempty"`
+	Source      string            `json:"source"`
+	Priority    string            `json:"priority"`
+}
+
+// priorities are the Opsgenie priorities of finding severities.
+var priorities = map[string]string{
+	sinks.SeverityCritical: "P1",
+	sinks.SeverityHigh:     "P2",
+	sinks.SeverityMedium:   "P3",
+	sinks.SeverityLow:      "P4",
+}
+
+func (s *Sink) Send(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	severity := sinks.Severity(r)
+	if !r.Verified || !sinks.AtLeast(severity, s.config.MinSeverity) {
+		return nil
+	}
+	alias, err := sinks.Key(sinks.KeySecret, r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sent[alias]; ok {
+		return nil
+	}
+
+	meta := sinks.Metadata(r)
+	detector := r.DetectorType.String()
+	location := sinks.Location(meta)
+	repo := sinks.Repository(meta)
+	message := fmt.Sprintf("Live %s secret leaked", detector)
+	if location != "" {
+		message += " in " + location
+	}
+
+	var description strings.Builder
+	fmt.Fprintf(&description, "TruffleHog found a verified %s secret (%s).\n\n", detector, sinks.Mask(r))
+	fmt.Fprintf(&description, "Source: %s\n", r.SourceName)
+	details := map[string]string{"detector": detector, "source": r.SourceName}
+	for _, k := range []string{"repository", "bucket", "file", "line", "commit", "email", "timestamp"} {
+		if v := meta[k]; v != "" && v != "0" {
+			fmt.Fprintf(&description, "%s: %s\n", strings.Title(k), v)
+			details[k] = v
+		}
+	}
+	if link := sinks.Link(meta); link != "" {
+		fmt.Fprintf(&description, "Link: %s\n", link)
+		details["link"] = link
+	}
+
+	a := alert{
+		Message:     truncate(message, maxMessage),
+		Alias:       alias,
+		Description: description.String(),
+		Tags:        []string{"trufflehog", detector, severity},
+		Details:     details,
+		Entity:      repo,
+		Source:      "TruffleHog",
+		Priority:    priorities[severity],
+	}
+	if err := s.create(ctx, a); err != nil {
+		return err
+	}
+	s.sent[alias] = struct{}{}
+	return nil
+}
+
+func (s *Sink) create(ctx context.Context, a alert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(s.config.URL, "/")+"/v2/alerts", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "GenieKey "+s.config.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+	res, err := s.client.Do(req)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not create Opsgenie alert", 0)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusAccepted {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return errors.Errorf("Opsgenie alert failed with status %d: %s", res.StatusCode, msg)
+	}
+	return nil
+}
+
+// Close does nothing, since alerts are created as findings are.
+func (s *Sink) Close() error {
+	return nil
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,80 @@
+package opsgenie
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sinks"
+)
+
+func TestSink(t *testing.T) {
+	var alerts []alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/alerts" || r.Header.Get("Authorization") != "GenieKey key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var a alert
+		if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+			t.Error(err)
+		}
+		alerts = append(alerts, a)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	s, err := New(Config{APIKey: "key", URL: server.URL + "/", MinSeverity: sinks.SeverityCritical})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaked := func(raw string, severity string) *detectors.ResultWithMetadata {
+		return &detectors.ResultWithMetadata{
+			SourceName: "trufflehog - s3",
+			SourceMetadata: &source_metadatapb.MetaData{
+				Data: &source_metadatapb.MetaData_S3{
+					S3: &source_metadatapb.S3{Bucket: "backups", File: "dump.sql"},
+				},
+			},
+			Result: detectors.Result{
+				DetectorType: detectorspb.DetectorType_Stripe,
+				Raw:          []byte(raw),
+				Verified:     true,
+				ExtraData:    map[string]string{"severity": severity},
+			},
+		}
+	}
+	results := []*detectors.ResultWithMetadata{
+		leaked("sk_live_example", sinks.SeverityCritical),
+		leaked("sk_live_example", sinks.SeverityCritical),
+		leaked("sk_test_example", sinks.SeverityHigh),
+	}
+	for _, r := range results {
+		if err := s.Send(context.Background(), r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	alias, _ := sinks.Key(sinks.KeySecret, results[0])
+	want := []alert{{
+		Message:     "Live Stripe secret leaked in dump.sql",
+		Alias:       alias,
+		Description: "TruffleHog found a verified Stripe secret (sk_l********).\n\nSource: trufflehog - s3\nBucket: backups\nFile: dump.sql\n",
+		Tags:        []string{"trufflehog", "Stripe", "critical"},
+		Details:     map[string]string{"detector": "Stripe", "source": "trufflehog - s3", "bucket": "backups", "file": "dump.sql"},
+		Entity:      "backups",
+		Source:      "TruffleHog",
+		Priority:    "P1",
+	}}
+	if diff := pretty.Compare(alerts, want); diff != "" {
+		t.Errorf("alerts diff: (-got +want)\n%s", diff)
+	}
+}
@@ -0,0 +1,195 @@
+// Package pagerduty opens PagerDuty incidents for verified findings.
+package pagerduty
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sinks"
+)
+
+// DefaultURL is the PagerDuty Events API v2 endpoint.
+const DefaultURL = "https://events.pagerduty.com/v2/enqueue"
+
+// Config says which service to page and for what.
+type Config struct {
+	// RoutingKey is the integration key of the service's Events API v2
+	// integration.
+	RoutingKey string
+	// MinSeverity is the least severe finding that opens an incident. It
+	// defaults to sinks.SeverityHigh.
+	MinSeverity string
+	// URL is the Events API endpoint. It defaults to DefaultURL.
+	URL string
+	// TLS configures connections to the endpoint, such as to trust the CA of a
+	// proxy that intercepts them.
+	TLS *credentialspb.TLSConfig
+	// Proxy is the proxy to connect to the endpoint through, instead of the
+	// one set in the environment.
+	Proxy *credentialspb.Proxy
+}
+
+// Sink triggers an event for each verified finding at least as severe as the
+// configured severity. Events are deduplicated by a hash of the secret, so a
+// secret found in many places opens a single incident.
+type Sink struct {
+	config Config
+	client *http.Client
+
+	mu   sync.Mutex
+	sent map[string]struct{}
+}
+
+// Ensure the Sink satisfies the interface at compile time.
+var _ sinks.Sink = (*Sink)(nil)
+
+// New returns a sink that pages as configured.
+func New(config Config) (*Sink, error) {
+	if config.RoutingKey == "" {
+		return nil, errors.New("a PagerDuty routing key is required")
+	}
+	if config.MinSeverity == "" {
+		config.MinSeverity = sinks.SeverityHigh
+	}
+	if config.URL == "" {
+		config.URL = DefaultURL
+	}
+	httpOptions, err := common.NewHttpClientOptions(config.TLS, config.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	return &Sink{config: config, client: common.SaneHttpClientTimeOut(30, httpOptions...), sent: map[string]struct{}{}}, nil
+}
+
+type event struct {
+	RoutingKey  string  `json:"routing_key"`
+	EventAction string  `json:"event_action"`
+	DedupKey    string  `json:"dedup_key"`
+	Payload     payload `json:"payload"`
+	Links       []link  `json:"links,omitempty"`
+}
+
+type payload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Component     string            `json:"component,omitempty"`
+	Group         string            `json:"group,omitempty"`
+	Class         string            `json:"class"`
+	CustomDetails map[string]string `json:"custom_details"`
+}
+
+type link struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+// severities are the PagerDuty severities of finding severities.
+var severities = map[string]string{
+	sinks.SeverityCritical: "critical",
+	sinks.SeverityHigh:     "error",
+	sinks.SeverityMedium:   "warning",
+	sinks.SeverityLow:      "info",
+}
+
+func (s *Sink) Send(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	severity := sinks.Severity(r)
+	if !r.Verified || !sinks.AtLeast(severity, s.config.MinSeverity) {
+		return nil
+	}
+	dedupKey, err := sinks.Key(sinks.KeySecret, r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sent[dedupKey]; ok {
+		return nil
+	}
+
+	meta := sinks.Metadata(r)
+	detector := r.DetectorType.String()
+	location := sinks.Location(meta)
+	repo := sinks.Repository(meta)
+	summary := fmt.Sprintf("Live %s secret leaked", detector)
+	if location != "" {
+		summary += " in " + location
+	}
+	if repo != "" {
+		summary += " of " + repo
+	}
+	source := repo
+	if source == "" {
+		source = r.SourceName
+	}
+
+	details := map[string]string{
+		"detector": detector,
+		"secret":   sinks.Mask(r),
+		"source":   r.SourceName,
+	}
+	for _, k := range []string{"repository", "bucket", "file", "line", "commit", "email", "timestamp"} {
+		if v := meta[k]; v != "" && v != "0" {
+			details[k] = v
+		}
+	}
+	e := event{
+		RoutingKey:  s.config.RoutingKey,
+		EventAction: "trigger",
+		DedupKey:    dedupKey,
+		Payload: payload{
+			Summary:       summary,
+			Source:        source,
+			Severity:      severities[severity],
+			Component:     location,
+			Group:         repo,
+			Class:         detector,
+			CustomDetails: details,
+		},
+	}
+	if href := sinks.Link(meta); href != "" {
+		e.Links = []link{{Href: href, Text: "Leaked secret"}}
+	}
+	if err := s.trigger(ctx, e); err != nil {
+		return err
+	}
+	s.sent[dedupKey] = struct{}{}
+	return nil
+}
+
+func (s *Sink) trigger(ctx context.Context, e event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := s.client.Do(req)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not send PagerDuty event", 0)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusAccepted {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return errors.Errorf("PagerDuty event failed with status %d: %s", res.StatusCode, msg)
+	}
+	return nil
+}
+
+// Close does nothing, since events are sent as findings are.
+func (s *Sink) Close() error {
+	return nil
+}
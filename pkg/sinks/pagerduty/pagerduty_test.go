@@ -0,0 +1,103 @@
+package pagerduty
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sinks"
+)
+
+func TestSink(t *testing.T) {
+	var events []event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e event
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			t.Error(err)
+		}
+		events = append(events, e)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	s, err := New(Config{RoutingKey: "key", URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaked := func(file string, verified bool, extra map[string]string) *detectors.ResultWithMetadata {
+		return &detectors.ResultWithMetadata{
+			SourceName: "trufflehog - git",
+			SourceMetadata: &source_metadatapb.MetaData{
+				Data: &source_metadatapb.MetaData_Git{
+					Git: &source_metadatapb.Git{Repository: "https://github.com/acme/api.git", Commit: "abc123", File: file, Line: 10},
+				},
+			},
+			Result: detectors.Result{DetectorType: detectorspb.DetectorType_AWS, Raw: []byte("AKIAEXAMPLEKEY"), Verified: verified, ExtraData: extra},
+		}
+	}
+	results := []*detectors.ResultWithMetadata{
+		leaked("config.py", true, nil),
+		// The same secret again is deduplicated.
+		leaked("deploy.py", true, nil),
+		// Unverified and low severity findings don't page.
+		leaked("config.py", false, nil),
+		leaked("config.py", true, map[string]string{"severity": sinks.SeverityLow}),
+	}
+	for _, r := range results {
+		if err := s.Send(context.Background(), r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dedupKey, _ := sinks.Key(sinks.KeySecret, results[0])
+	want := []event{{
+		RoutingKey:  "key",
+		EventAction: "trigger",
+		DedupKey:    dedupKey,
+		Payload: payload{
+			Summary:   "Live AWS secret leaked in config.py:10 of https://github.com/acme/api.git",
+			Source:    "https://github.com/acme/api.git",
+			Severity:  "error",
+			Component: "config.py:10",
+			Group:     "https://github.com/acme/api.git",
+			Class:     "AWS",
+			CustomDetails: map[string]string{
+				"detector":   "AWS",
+				"secret":     "AKIA********",
+				"source":     "trufflehog - git",
+				"repository": "https://github.com/acme/api.git",
+				"file":       "config.py",
+				"line":       "10",
+				"commit":     "abc123",
+			},
+		},
+		Links: []link{{Href: "https://github.com/acme/api/blob/abc123/config.py#L10", Text: "Leaked secret"}},
+	}}
+	if diff := pretty.Compare(events, want); diff != "" {
+		t.Errorf("events diff: (-got +want)\n%s", diff)
+	}
+}
+
+func TestSink_EventFailed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"status": "invalid event"}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	s, err := New(Config{RoutingKey: "key", URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &detectors.ResultWithMetadata{Result: detectors.Result{Raw: []byte("secret"), Verified: true}}
+	if err := s.Send(context.Background(), r); err == nil {
+		t.Error("Send() should fail when PagerDuty rejects the event")
+	}
+}
@@ -0,0 +1,195 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Parquet physical types, converted types, and encodings used by the file
+// writer.
+const (
+	typeBoolean   = 0
+	typeInt64     = 2
+	typeByteArray = 6
+
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+
+	encodingPlain = 0
+	encodingRLE   = 3
+	repRequired   = 0
+	pageData      = 0
+	codecNone     = 0
+)
+
+const magic = "PAR1"
+
+// column is a required column of a flat schema, holding one value per row.
+type column struct {
+	name      string
+	typ       int32
+	converted int32 // -1 if the column has no converted type.
+	strings   []string
+	ints      []int64
+	bools     []bool
+}
+
+func stringColumn(name string) *column {
+	return &column{name: name, typ: typeByteArray, converted: convertedUTF8}
+}
+
+func int64Column(name string) *column {
+	return &column{name: name, typ: typeInt64, converted: -1}
+}
+
+func timestampColumn(name string) *column {
+	return &column{name: name, typ: typeInt64, converted: convertedTimestampMillis}
+}
+
+func boolColumn(name string) *column {
+	return &column{name: name, typ: typeBoolean, converted: -1}
+}
+
+func (c *column) len() int {
+	switch c.typ {
+	case typeByteArray:
+		return len(c.strings)
+	case typeInt64:
+		return len(c.ints)
+	default:
+		return len(c.bools)
+	}
+}
+
+// plain returns the column's values in the PLAIN encoding. Required columns
+// of a flat schema have no repetition or definition levels, so this is the
+// whole of a data page.
+func (c *column) plain() []byte {
+	var buf bytes.Buffer
+	switch c.typ {
+	case typeByteArray:
+		for _, s := range c.strings {
+			var n [4]byte
+			binary.LittleEndian.PutUint32(n[:], uint32(len(s)))
+			buf.Write(n[:])
+			buf.WriteString(s)
+		}
+	case typeInt64:
+		for _, v := range c.ints {
+			var b [8]byte
+			binary.LittleEndian.PutUint64(b[:], uint64(v))
+			buf.Write(b[:])
+		}
+	case typeBoolean:
+		packed := make([]byte, (len(c.bools)+7)/8)
+		for i, v := range c.bools {
+			if v {
+				packed[i/8] |= 1 << (i % 8)
+			}
+		}
+		buf.Write(packed)
+	}
+	return buf.Bytes()
+}
+
+// encodeFile returns a Parquet file of columns, which must all have the same
+// number of rows, as a single uncompressed row group.
+func encodeFile(columns []*column, createdBy string) []byte {
+	var file bytes.Buffer
+	file.WriteString(magic)
+
+	rows := 0
+	if len(columns) > 0 {
+		rows = columns[0].len()
+	}
+	type chunk struct {
+		offset int64
+		size   int64
+	}
+	chunks := make([]chunk, len(columns))
+	var totalSize int64
+	for i, c := range columns {
+		data := c.plain()
+		var header thriftWriter
+		header.structBegin()
+		header.i32Field(1, pageData)
+		header.i32Field(2, int32(len(data)))
+		header.i32Field(3, int32(len(data)))
+		header.field(5, thriftStruct)
+		header.structBegin()
+		header.i32Field(1, int32(rows))
+		header.i32Field(2, encodingPlain)
+		header.i32Field(3, encodingRLE)
+		header.i32Field(4, encodingRLE)
+		header.structEnd()
+		header.structEnd()
+
+		chunks[i].offset = int64(file.Len())
+		file.Write(header.buf.Bytes())
+		file.Write(data)
+		chunks[i].size = int64(file.Len()) - chunks[i].offset
+		totalSize += chunks[i].size
+	}
+
+	var meta thriftWriter
+	meta.structBegin()
+	meta.i32Field(1, 1)
+
+	meta.field(2, thriftList)
+	meta.listBegin(len(columns)+1, thriftStruct)
+	meta.structBegin()
+	meta.stringField(4, "schema")
+	meta.i32Field(5, int32(len(columns)))
+	meta.structEnd()
+	for _, c := range columns {
+		meta.structBegin()
+		meta.i32Field(1, c.typ)
+		meta.i32Field(3, repRequired)
+		meta.stringField(4, c.name)
+		if c.converted >= 0 {
+			meta.i32Field(6, c.converted)
+		}
+		meta.structEnd()
+	}
+
+	meta.i64Field(3, int64(rows))
+
+	meta.field(4, thriftList)
+	meta.listBegin(1, thriftStruct)
+	meta.structBegin()
+	meta.field(1, thriftList)
+	meta.listBegin(len(columns), thriftStruct)
+	for i, c := range columns {
+		meta.structBegin()
+		meta.i64Field(2, chunks[i].offset)
+		meta.field(3, thriftStruct)
+		meta.structBegin()
+		meta.i32Field(1, c.typ)
+		meta.field(2, thriftList)
+		meta.listBegin(1, thriftI32)
+		meta.varint(encodingPlain)
+		meta.field(3, thriftList)
+		meta.listBegin(1, thriftBinary)
+		meta.binary([]byte(c.name))
+		meta.i32Field(4, codecNone)
+		meta.i64Field(5, int64(rows))
+		meta.i64Field(6, chunks[i].size)
+		meta.i64Field(7, chunks[i].size)
+		meta.i64Field(9, chunks[i].offset)
+		meta.structEnd()
+		meta.structEnd()
+	}
+	meta.i64Field(2, totalSize)
+	meta.i64Field(3, int64(rows))
+	meta.structEnd()
+
+	meta.stringField(6, createdBy)
+	meta.structEnd()
+
+	file.Write(meta.buf.Bytes())
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32(meta.buf.Len()))
+	file.Write(n[:])
+	file.WriteString(magic)
+	return file.Bytes()
+}
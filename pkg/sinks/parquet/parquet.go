@@ -0,0 +1,367 @@
+// Package parquet exports findings as Parquet files to S3, Google Cloud
+// Storage, Azure Blob Storage, or a local directory, partitioned by date so
+// they can be queried with Athena or BigQuery.
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/go-errors/errors"
+	"golang.org/x/oauth2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/ambient"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/findings"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sinks"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/version"
+)
+
+const (
+	// maxRows is the most findings written to a single file.
+	maxRows = 100000
+	// gcsEndpoint serves both Cloud Storage's S3 compatible API and its JSON
+	// API.
+	gcsEndpoint = "https://storage.googleapis.com"
+	// azureStorageResource is what Azure tokens for Blob Storage are for.
+	azureStorageResource = "https://storage.azure.com/"
+	// azureStorageVersion is the Blob Storage API version, the first to allow
+	// single uploads of up to 5000 MiB.
+	azureStorageVersion = "2019-12-12"
+)
+
+// store writes files to where findings are exported.
+type store interface {
+	put(ctx context.Context, key string, data []byte) error
+}
+
+// Sink collects findings and writes them as Parquet files when it's closed,
+// or sooner if there are many. Files are written under a dt=YYYY-MM-DD
+// partition of the day the findings were found, in the Hive layout Athena and
+// BigQuery read partitions from.
+type Sink struct {
+	store  store
+	prefix string
+	// now returns the time findings are found at.
+	now func() time.Time
+
+	mu   sync.Mutex
+	rows []row
+}
+
+// Ensure the Sink satisfies the interface at compile time.
+var _ sinks.Sink = (*Sink)(nil)
+
+// New returns a sink that exports findings to destination, which is an
+// s3://bucket/prefix, gs://bucket/prefix, or azblob://account/container/prefix
+// URL, or a local directory. S3 is accessed with the AWS credentials in the
+// environment. Cloud Storage is accessed with HMAC keys given as AWS
+// credentials, or without them with Google application default credentials,
+// such as GKE workload identity. Azure Blob Storage is accessed with AKS
+// workload identity or the managed identity of where the scan runs, which
+// AZURE_CLIENT_ID selects if there are several.
+func New(destination, region string) (*Sink, error) {
+	u, err := url.Parse(destination)
+	if err != nil || destination == "" {
+		return nil, errors.Errorf("invalid export destination %q", destination)
+	}
+	switch u.Scheme {
+	case "s3", "gs":
+		if u.Host == "" {
+			return nil, errors.Errorf("invalid export destination %q, expected a bucket", destination)
+		}
+		config := aws.Config{Region: aws.String(region)}
+		if u.Scheme == "gs" {
+			config.Endpoint = aws.String(gcsEndpoint)
+			if region == "" {
+				config.Region = aws.String("auto")
+			}
+		}
+		sess, err := session.NewSessionWithOptions(session.Options{
+			SharedConfigState: session.SharedConfigEnable,
+			Config:            config,
+		})
+		if err != nil {
+			return nil, errors.WrapPrefix(err, "could not create aws session", 0)
+		}
+		if u.Scheme == "gs" && !hasCredentials(sess) {
+			tokens, err := ambient.GCPTokenSource(context.Background(), &credentialspb.Ambient{})
+			if err != nil {
+				return nil, err
+			}
+			store := &gcsStore{endpoint: gcsEndpoint, bucket: u.Host, client: oauth2.NewClient(context.Background(), tokens)}
+			return newSink(store, strings.Trim(u.Path, "/")), nil
+		}
+		return newSink(&bucketStore{bucket: u.Host, uploader: s3manager.NewUploader(sess)}, strings.Trim(u.Path, "/")), nil
+	case "azblob":
+		container, prefix, _ := strings.Cut(strings.Trim(u.Path, "/"), "/")
+		if u.Host == "" || container == "" {
+			return nil, errors.Errorf("invalid export destination %q, expected an account and container", destination)
+		}
+		tokens := ambient.NewAzureTokenSource(&credentialspb.Ambient{ClientId: os.Getenv("AZURE_CLIENT_ID")}, azureStorageResource)
+		store := &azureStore{
+			container: fmt.Sprintf("https://%s.blob.core.windows.net/%s", u.Host, url.PathEscape(container)),
+			client:    oauth2.NewClient(context.Background(), tokens),
+		}
+		return newSink(store, prefix), nil
+	case "", "file":
+		return newSink(dirStore(filepath.FromSlash(u.Path)), ""), nil
+	default:
+		return nil, errors.Errorf("unsupported export destination %q", destination)
+	}
+}
+
+// hasCredentials reports whether the session has AWS credentials, which for
+// Cloud Storage are HMAC keys.
+func hasCredentials(sess *session.Session) bool {
+	_, err := sess.Config.Credentials.Get()
+	return err == nil
+}
+
+func newSink(s store, prefix string) *Sink {
+	return &Sink{store: s, prefix: prefix, now: time.Now}
+}
+
+// row is a finding as it's exported. The secret itself isn't exported, but
+// its hash is, so unique secrets can be counted.
+type row struct {
+	timestamp  time.Time
+	findingID  string
+	detector   string
+	verified   bool
+	severity   string
+	redacted   string
+	secretHash string
+	sourceType string
+	sourceName string
+	repository string
+	file       string
+	line       int64
+	commit     string
+	email      string
+	link       string
+	// extraData is the detector's extra data as a JSON object.
+	extraData string
+}
+
+func (s *Sink) Send(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	meta := sinks.Metadata(r)
+	hash := sha256.Sum256(r.Raw)
+	extra := []byte("{}")
+	if len(r.ExtraData) > 0 {
+		var err error
+		if extra, err = json.Marshal(r.ExtraData); err != nil {
+			return err
+		}
+	}
+	rw := row{
+		timestamp:  s.now().UTC(),
+		findingID:  findings.ID(r),
+		detector:   r.DetectorType.String(),
+		verified:   r.Verified,
+		severity:   sinks.Severity(r),
+		redacted:   sinks.Mask(r),
+		secretHash: hex.EncodeToString(hash[:]),
+		sourceType: r.SourceType.String(),
+		sourceName: r.SourceName,
+		repository: sinks.Repository(meta),
+		file:       meta["file"],
+		commit:     meta["commit"],
+		email:      meta["email"],
+		link:       sinks.Link(meta),
+		extraData:  string(extra),
+	}
+	rw.line, _ = strconv.ParseInt(meta["line"], 10, 64)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rows = append(s.rows, rw)
+	if len(s.rows) < maxRows {
+		return nil
+	}
+	return s.flush(ctx)
+}
+
+// Close writes the findings that haven't been written yet.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flush(context.Background())
+}
+
+// flush writes a file of the buffered findings to each partition they're in.
+func (s *Sink) flush(ctx context.Context) error {
+	if len(s.rows) == 0 {
+		return nil
+	}
+	partitions := map[string][]row{}
+	for _, r := range s.rows {
+		dt := r.timestamp.Format("2006-01-02")
+		partitions[dt] = append(partitions[dt], r)
+	}
+	s.rows = nil
+
+	dates := make([]string, 0, len(partitions))
+	for dt := range partitions {
+		dates = append(dates, dt)
+	}
+	sort.Strings(dates)
+	for _, dt := range dates {
+		rows := partitions[dt]
+		var id [4]byte
+		if _, err := rand.Read(id[:]); err != nil {
+			return err
+		}
+		name := fmt.Sprintf("trufflehog-%s-%s.parquet", rows[0].timestamp.Format("20060102T150405Z"), hex.EncodeToString(id[:]))
+		key := path.Join(s.prefix, "dt="+dt, name)
+		if err := s.store.put(ctx, key, encodeRows(rows)); err != nil {
+			return errors.WrapPrefix(err, "could not export findings", 0)
+		}
+	}
+	return nil
+}
+
+// encodeRows returns rows as a Parquet file.
+func encodeRows(rows []row) []byte {
+	var (
+		timestamp  = timestampColumn("timestamp")
+		findingID  = stringColumn("finding_id")
+		detector   = stringColumn("detector")
+		verified   = boolColumn("verified")
+		severity   = stringColumn("severity")
+		redacted   = stringColumn("redacted")
+		secretHash = stringColumn("secret_hash")
+		sourceType = stringColumn("source_type")
+		sourceName = stringColumn("source_name")
+		repository = stringColumn("repository")
+		file       = stringColumn("file")
+		line       = int64Column("line")
+		commit     = stringColumn("commit")
+		email      = stringColumn("email")
+		link       = stringColumn("link")
+		extraData  = stringColumn("extra_data")
+	)
+	for _, r := range rows {
+		timestamp.ints = append(timestamp.ints, r.timestamp.UnixMilli())
+		findingID.strings = append(findingID.strings, r.findingID)
+		detector.strings = append(detector.strings, r.detector)
+		verified.bools = append(verified.bools, r.verified)
+		severity.strings = append(severity.strings, r.severity)
+		redacted.strings = append(redacted.strings, r.redacted)
+		secretHash.strings = append(secretHash.strings, r.secretHash)
+		sourceType.strings = append(sourceType.strings, r.sourceType)
+		sourceName.strings = append(sourceName.strings, r.sourceName)
+		repository.strings = append(repository.strings, r.repository)
+		file.strings = append(file.strings, r.file)
+		line.ints = append(line.ints, r.line)
+		commit.strings = append(commit.strings, r.commit)
+		email.strings = append(email.strings, r.email)
+		link.strings = append(link.strings, r.link)
+		extraData.strings = append(extraData.strings, r.extraData)
+	}
+	columns := []*column{
+		timestamp, findingID, detector, verified, severity, redacted, secretHash, sourceType,
+		sourceName, repository, file, line, commit, email, link, extraData,
+	}
+	return encodeFile(columns, "trufflehog version "+version.BuildVersion)
+}
+
+// bucketStore writes files to an S3 or Cloud Storage bucket.
+type bucketStore struct {
+	bucket   string
+	uploader *s3manager.Uploader
+}
+
+func (b *bucketStore) put(ctx context.Context, key string, data []byte) error {
+	_, err := b.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(b.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/vnd.apache.parquet"),
+	})
+	return err
+}
+
+// gcsStore writes files to a Cloud Storage bucket with the JSON API, for
+// OAuth credentials, which the S3 compatible API doesn't take.
+type gcsStore struct {
+	endpoint string
+	bucket   string
+	client   *http.Client
+}
+
+func (g *gcsStore) put(ctx context.Context, key string, data []byte) error {
+	query := url.Values{"uploadType": {"media"}, "name": {key}}
+	endpoint := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?%s", g.endpoint, url.PathEscape(g.bucket), query.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.apache.parquet")
+	return upload(g.client, req, key)
+}
+
+// azureStore writes files to an Azure Blob Storage container, which is the
+// container's URL.
+type azureStore struct {
+	container string
+	client    *http.Client
+}
+
+func (a *azureStore) put(ctx context.Context, key string, data []byte) error {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, a.container+"/"+strings.Join(segments, "/"), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.apache.parquet")
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	req.Header.Set("x-ms-version", azureStorageVersion)
+	return upload(a.client, req, key)
+}
+
+// upload sends a request that writes the file key.
+func upload(client *http.Client, req *http.Request, key string) error {
+	res, err := client.Do(req)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not upload "+key, 0)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		return errors.Errorf("could not upload %s: %s", key, res.Status)
+	}
+	return nil
+}
+
+// dirStore writes files to a local directory.
+type dirStore string
+
+func (d dirStore) put(_ context.Context, key string, data []byte) error {
+	name := filepath.Join(string(d), filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0o644)
+}
@@ -0,0 +1,498 @@
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/findings"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sinks"
+)
+
+type fakeStore struct {
+	files map[string][]byte
+}
+
+func (f *fakeStore) put(_ context.Context, key string, data []byte) error {
+	f.files[key] = data
+	return nil
+}
+
+func gitResult(raw string) *detectors.ResultWithMetadata {
+	return &detectors.ResultWithMetadata{
+		SourceType: sourcespb.SourceType_SOURCE_TYPE_GIT,
+		SourceName: "trufflehog - git",
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Git{
+				Git: &source_metadatapb.Git{Repository: "https://github.com/acme/api.git", Commit: "abc123", File: "config.py", Line: 10},
+			},
+		},
+		Result: detectors.Result{DetectorType: detectorspb.DetectorType_AWS, Raw: []byte(raw), Verified: true},
+	}
+}
+
+func TestSink_partitions(t *testing.T) {
+	store := &fakeStore{files: map[string][]byte{}}
+	s := newSink(store, "findings")
+	days := []time.Time{
+		time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC),
+		time.Date(2022, 6, 1, 13, 0, 0, 0, time.UTC),
+		time.Date(2022, 6, 2, 9, 0, 0, 0, time.UTC),
+	}
+	for i, day := range days {
+		day := day
+		s.now = func() time.Time { return day }
+		if err := s.Send(context.Background(), gitResult("AKIAEXAMPLEKEY"+string(rune('0'+i)))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(store.files) != 0 {
+		t.Fatalf("files written before Close: %d", len(store.files))
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	partitions := map[string]int{}
+	for key, data := range store.files {
+		dir := key[:strings.LastIndex(key, "/")]
+		partitions[dir]++
+		if !strings.HasSuffix(key, ".parquet") {
+			t.Errorf("key %q doesn't end in .parquet", key)
+		}
+		if bytes.Contains(data, []byte("AKIAEXAMPLEKEY")) {
+			t.Errorf("file %q contains the raw secret", key)
+		}
+		if !bytes.Contains(data, []byte("https://github.com/acme/api.git")) {
+			t.Errorf("file %q doesn't contain the repository", key)
+		}
+	}
+	want := map[string]int{"findings/dt=2022-06-01": 1, "findings/dt=2022-06-02": 1}
+	if len(partitions) != len(want) {
+		t.Fatalf("partitions = %v, want %v", partitions, want)
+	}
+	for dir, n := range want {
+		if partitions[dir] != n {
+			t.Errorf("partitions[%q] = %d, want %d", dir, partitions[dir], n)
+		}
+	}
+}
+
+func TestSink_roundTrip(t *testing.T) {
+	store := &fakeStore{files: map[string][]byte{}}
+	s := newSink(store, "findings")
+	found := time.Date(2022, 6, 1, 12, 30, 0, 0, time.UTC)
+	s.now = func() time.Time { return found }
+
+	first := gitResult("AKIAEXAMPLEKEY0")
+	first.ExtraData = map[string]string{"account": "123456789012"}
+	second := gitResult("AKIAEXAMPLEKEY1")
+	second.Verified = false
+	for _, r := range []*detectors.ResultWithMetadata{first, second} {
+		if err := s.Send(context.Background(), r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.files) != 1 {
+		t.Fatalf("files written = %d, want 1", len(store.files))
+	}
+	var file []byte
+	for _, data := range store.files {
+		file = data
+	}
+
+	schema, rows, err := readParquet(file)
+	if err != nil {
+		t.Fatalf("readParquet() error = %v", err)
+	}
+	wantSchema := []schemaColumn{
+		{Name: "timestamp", Type: typeInt64, Converted: convertedTimestampMillis},
+		{Name: "finding_id", Type: typeByteArray, Converted: convertedUTF8},
+		{Name: "detector", Type: typeByteArray, Converted: convertedUTF8},
+		{Name: "verified", Type: typeBoolean, Converted: -1},
+		{Name: "severity", Type: typeByteArray, Converted: convertedUTF8},
+		{Name: "redacted", Type: typeByteArray, Converted: convertedUTF8},
+		{Name: "secret_hash", Type: typeByteArray, Converted: convertedUTF8},
+		{Name: "source_type", Type: typeByteArray, Converted: convertedUTF8},
+		{Name: "source_name", Type: typeByteArray, Converted: convertedUTF8},
+		{Name: "repository", Type: typeByteArray, Converted: convertedUTF8},
+		{Name: "file", Type: typeByteArray, Converted: convertedUTF8},
+		{Name: "line", Type: typeInt64, Converted: -1},
+		{Name: "commit", Type: typeByteArray, Converted: convertedUTF8},
+		{Name: "email", Type: typeByteArray, Converted: convertedUTF8},
+		{Name: "link", Type: typeByteArray, Converted: convertedUTF8},
+		{Name: "extra_data", Type: typeByteArray, Converted: convertedUTF8},
+	}
+	if diff := pretty.Compare(schema, wantSchema); diff != "" {
+		t.Errorf("schema diff: (-got +want)\n%s", diff)
+	}
+
+	wantRow := func(r *detectors.ResultWithMetadata, verified bool, extraData string) []interface{} {
+		hash := sha256.Sum256(r.Raw)
+		return []interface{}{
+			found.UnixMilli(),
+			findings.ID(r),
+			"AWS",
+			verified,
+			sinks.Severity(r),
+			sinks.Mask(r),
+			hex.EncodeToString(hash[:]),
+			"SOURCE_TYPE_GIT",
+			"trufflehog - git",
+			"https://github.com/acme/api.git",
+			"config.py",
+			int64(10),
+			"abc123",
+			"",
+			sinks.Link(sinks.Metadata(r)),
+			extraData,
+		}
+	}
+	want := [][]interface{}{
+		wantRow(first, true, `{"account":"123456789012"}`),
+		wantRow(second, false, "{}"),
+	}
+	if diff := pretty.Compare(rows, want); diff != "" {
+		t.Errorf("rows diff: (-got +want)\n%s", diff)
+	}
+}
+
+func TestEncodeFile(t *testing.T) {
+	name := stringColumn("name")
+	name.strings = []string{"a", "bc"}
+	count := int64Column("count")
+	count.ints = []int64{1, 2}
+	ok := boolColumn("ok")
+	ok.bools = []bool{true, false}
+
+	file := encodeFile([]*column{name, count, ok}, "test")
+	if !bytes.HasPrefix(file, []byte(magic)) || !bytes.HasSuffix(file, []byte(magic)) {
+		t.Fatalf("file isn't framed by %q", magic)
+	}
+	footer := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	if footer <= 0 || footer > len(file)-12 {
+		t.Fatalf("footer length = %d, file length = %d", footer, len(file))
+	}
+	meta := file[len(file)-8-footer : len(file)-8]
+	for _, want := range []string{"schema", "name", "count", "ok", "test"} {
+		if !bytes.Contains(meta, []byte(want)) {
+			t.Errorf("file metadata doesn't contain %q", want)
+		}
+	}
+}
+
+func TestColumn_plain(t *testing.T) {
+	bools := boolColumn("b")
+	bools.bools = []bool{true, false, true, true, false, false, false, false, true}
+	if got, want := bools.plain(), []byte{0x0d, 0x01}; !bytes.Equal(got, want) {
+		t.Errorf("bool plain() = %x, want %x", got, want)
+	}
+
+	strs := stringColumn("s")
+	strs.strings = []string{"hi"}
+	if got, want := strs.plain(), []byte{2, 0, 0, 0, 'h', 'i'}; !bytes.Equal(got, want) {
+		t.Errorf("string plain() = %x, want %x", got, want)
+	}
+}
+
+func TestNew_invalid(t *testing.T) {
+	for _, destination := range []string{"", "s3://", "azblob://account", "ftp://host/path"} {
+		if _, err := New(destination, ""); err == nil {
+			t.Errorf("New(%q) succeeded, want error", destination)
+		}
+	}
+}
+
+func TestStores_put(t *testing.T) {
+	const key = "findings/dt=2022-06-01/part-1.parquet"
+	data := []byte("PAR1")
+
+	tests := []struct {
+		name  string
+		store func(endpoint string, client *http.Client) store
+		check func(r *http.Request) bool
+		ok    int
+	}{
+		{
+			name: "cloud storage",
+			store: func(endpoint string, client *http.Client) store {
+				return &gcsStore{endpoint: endpoint, bucket: "exports", client: client}
+			},
+			check: func(r *http.Request) bool {
+				return r.Method == http.MethodPost && r.URL.Path == "/upload/storage/v1/b/exports/o" &&
+					r.URL.Query().Get("uploadType") == "media" && r.URL.Query().Get("name") == key
+			},
+			ok: http.StatusOK,
+		},
+		{
+			name: "azure blob storage",
+			store: func(endpoint string, client *http.Client) store {
+				return &azureStore{container: endpoint + "/exports", client: client}
+			},
+			check: func(r *http.Request) bool {
+				return r.Method == http.MethodPut && r.URL.Path == "/exports/"+key &&
+					r.Header.Get("x-ms-blob-type") == "BlockBlob" && r.Header.Get("x-ms-version") == azureStorageVersion
+			},
+			ok: http.StatusCreated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !tt.check(r) || r.Header.Get("Content-Type") != "application/vnd.apache.parquet" {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL)
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				got, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.ok)
+			}))
+			defer server.Close()
+
+			if err := tt.store(server.URL, server.Client()).put(context.Background(), key, data); err != nil {
+				t.Fatalf("put() error = %v", err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("uploaded %q, want %q", got, data)
+			}
+
+			failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+			}))
+			defer failing.Close()
+			if err := tt.store(failing.URL, failing.Client()).put(context.Background(), key, data); err == nil {
+				t.Errorf("put() succeeded against a failing server, want error")
+			}
+		})
+	}
+}
+
+// schemaColumn is a leaf of a Parquet file's schema.
+type schemaColumn struct {
+	Name      string
+	Type      int64
+	Converted int64
+}
+
+// readParquet reads back the schema and rows of a file with a flat schema of
+// required columns in PLAIN encoded, uncompressed data pages. It decodes the
+// Thrift metadata itself rather than trusting the writer's, so it checks
+// what readers such as Athena would see.
+func readParquet(file []byte) ([]schemaColumn, [][]interface{}, error) {
+	if len(file) < 12 || string(file[:4]) != magic || string(file[len(file)-4:]) != magic {
+		return nil, nil, fmt.Errorf("file isn't framed by %q", magic)
+	}
+	footer := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	if footer > len(file)-12 {
+		return nil, nil, fmt.Errorf("footer length %d is longer than the file", footer)
+	}
+	meta, err := (&thriftReader{buf: file[len(file)-8-footer : len(file)-8]}).readStruct()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	elements, _ := meta[2].([]interface{})
+	if len(elements) == 0 {
+		return nil, nil, fmt.Errorf("file has no schema")
+	}
+	root, _ := elements[0].(map[int16]interface{})
+	if root[5] != int64(len(elements)-1) {
+		return nil, nil, fmt.Errorf("schema root has %v children, want %d", root[5], len(elements)-1)
+	}
+	var schema []schemaColumn
+	for _, e := range elements[1:] {
+		e, _ := e.(map[int16]interface{})
+		if e[3] != int64(repRequired) {
+			return nil, nil, fmt.Errorf("column %s isn't required", e[4])
+		}
+		c := schemaColumn{Name: string(bytesOf(e[4])), Type: e[1].(int64), Converted: -1}
+		if converted, ok := e[6].(int64); ok {
+			c.Converted = converted
+		}
+		schema = append(schema, c)
+	}
+
+	numRows, _ := meta[3].(int64)
+	rows := make([][]interface{}, numRows)
+	for i := range rows {
+		rows[i] = make([]interface{}, len(schema))
+	}
+	rowGroups, _ := meta[4].([]interface{})
+	if len(rowGroups) != 1 {
+		return nil, nil, fmt.Errorf("file has %d row groups, want 1", len(rowGroups))
+	}
+	chunks, _ := rowGroups[0].(map[int16]interface{})[1].([]interface{})
+	if len(chunks) != len(schema) {
+		return nil, nil, fmt.Errorf("row group has %d column chunks, want %d", len(chunks), len(schema))
+	}
+	for col, chunk := range chunks {
+		chunkMeta, _ := chunk.(map[int16]interface{})[3].(map[int16]interface{})
+		if chunkMeta[4] != int64(codecNone) || chunkMeta[5] != numRows {
+			return nil, nil, fmt.Errorf("column %s has codec %v and %v values", schema[col].Name, chunkMeta[4], chunkMeta[5])
+		}
+		offset, _ := chunkMeta[9].(int64)
+		if offset < 4 || offset >= int64(len(file)) {
+			return nil, nil, fmt.Errorf("column %s has data page offset %d", schema[col].Name, offset)
+		}
+		r := &thriftReader{buf: file[offset:]}
+		header, err := r.readStruct()
+		if err != nil {
+			return nil, nil, err
+		}
+		dataHeader, _ := header[5].(map[int16]interface{})
+		size, _ := header[3].(int64)
+		if header[1] != int64(pageData) || dataHeader[1] != numRows || dataHeader[2] != int64(encodingPlain) || int(size) > len(r.buf)-r.pos {
+			return nil, nil, fmt.Errorf("column %s has an unexpected page header %v", schema[col].Name, header)
+		}
+		page := r.buf[r.pos : r.pos+int(size)]
+		for i := range rows {
+			switch schema[col].Type {
+			case typeBoolean:
+				rows[i][col] = page[i/8]&(1<<(i%8)) != 0
+			case typeInt64:
+				rows[i][col] = int64(binary.LittleEndian.Uint64(page))
+				page = page[8:]
+			case typeByteArray:
+				n := binary.LittleEndian.Uint32(page)
+				rows[i][col] = string(page[4 : 4+n])
+				page = page[4+n:]
+			}
+		}
+	}
+	return schema, rows, nil
+}
+
+func bytesOf(v interface{}) []byte {
+	b, _ := v.([]byte)
+	return b
+}
+
+// thriftReader decodes Thrift compact protocol structs into maps of their
+// field IDs to values: int64s for integers, []byte for binary, bools,
+// []interface{} for lists and nested maps for structs.
+type thriftReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *thriftReader) readStruct() (map[int16]interface{}, error) {
+	fields := map[int16]interface{}{}
+	var lastID int16
+	for {
+		if r.pos >= len(r.buf) {
+			return nil, fmt.Errorf("struct runs past the end of its buffer")
+		}
+		b := r.buf[r.pos]
+		r.pos++
+		if b == 0 {
+			return fields, nil
+		}
+		typ := b & 0x0f
+		id := lastID + int16(b>>4)
+		if b>>4 == 0 {
+			v, err := r.varint()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+		lastID = id
+		// Booleans are stored in the field's type.
+		switch typ {
+		case 1, 2:
+			fields[id] = typ == 1
+			continue
+		}
+		v, err := r.value(typ)
+		if err != nil {
+			return nil, err
+		}
+		fields[id] = v
+	}
+}
+
+func (r *thriftReader) value(typ byte) (interface{}, error) {
+	switch typ {
+	case 1:
+		return true, nil
+	case 2:
+		return false, nil
+	case 3:
+		if r.pos >= len(r.buf) {
+			return nil, fmt.Errorf("byte runs past the end of its buffer")
+		}
+		r.pos++
+		return int64(int8(r.buf[r.pos-1])), nil
+	case 4, thriftI32, thriftI64:
+		return r.varint()
+	case thriftBinary:
+		n, err := r.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if n > uint64(len(r.buf)-r.pos) {
+			return nil, fmt.Errorf("binary runs past the end of its buffer")
+		}
+		r.pos += int(n)
+		return r.buf[r.pos-int(n) : r.pos], nil
+	case thriftList:
+		if r.pos >= len(r.buf) {
+			return nil, fmt.Errorf("list runs past the end of its buffer")
+		}
+		b := r.buf[r.pos]
+		r.pos++
+		size := uint64(b >> 4)
+		if size == 15 {
+			var err error
+			if size, err = r.uvarint(); err != nil {
+				return nil, err
+			}
+		}
+		var list []interface{}
+		for i := uint64(0); i < size; i++ {
+			v, err := r.value(b & 0x0f)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	case thriftStruct:
+		return r.readStruct()
+	default:
+		return nil, fmt.Errorf("unsupported thrift type %d", typ)
+	}
+}
+
+func (r *thriftReader) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		return 0, fmt.Errorf("bad varint")
+	}
+	r.pos += n
+	return v, nil
+}
+
+func (r *thriftReader) varint() (int64, error) {
+	v, err := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1), err
+}
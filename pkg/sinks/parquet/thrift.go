@@ -0,0 +1,87 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Thrift compact protocol types used by the Parquet metadata.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes Thrift structs in the compact protocol, which Parquet
+// file and page metadata are written in. Fields must be written in increasing
+// order of their IDs.
+type thriftWriter struct {
+	buf bytes.Buffer
+	// lastID is the ID of the last field written in the current struct, and
+	// stack holds those of the structs it's nested in.
+	lastID int16
+	stack  []int16
+}
+
+func (w *thriftWriter) structBegin() {
+	w.stack = append(w.stack, w.lastID)
+	w.lastID = 0
+}
+
+func (w *thriftWriter) structEnd() {
+	w.buf.WriteByte(0)
+	w.lastID = w.stack[len(w.stack)-1]
+	w.stack = w.stack[:len(w.stack)-1]
+}
+
+func (w *thriftWriter) field(id int16, typ byte) {
+	if delta := id - w.lastID; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		w.buf.WriteByte(typ)
+		w.varint(int64(id))
+	}
+	w.lastID = id
+}
+
+func (w *thriftWriter) listBegin(size int, elemType byte) {
+	if size < 15 {
+		w.buf.WriteByte(byte(size)<<4 | elemType)
+		return
+	}
+	w.buf.WriteByte(0xf0 | elemType)
+	w.uvarint(uint64(size))
+}
+
+func (w *thriftWriter) varint(v int64) {
+	// Integers are zigzag encoded, so small negative numbers stay short.
+	w.uvarint(uint64(v<<1) ^ uint64(v>>63))
+}
+
+func (w *thriftWriter) uvarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	w.buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func (w *thriftWriter) binary(b []byte) {
+	w.uvarint(uint64(len(b)))
+	w.buf.Write(b)
+}
+
+// i32Field, i64Field, and stringField write a field and its value.
+func (w *thriftWriter) i32Field(id int16, v int32) {
+	w.field(id, thriftI32)
+	w.varint(int64(v))
+}
+
+func (w *thriftWriter) i64Field(id int16, v int64) {
+	w.field(id, thriftI64)
+	w.varint(v)
+}
+
+func (w *thriftWriter) stringField(id int16, v string) {
+	w.field(id, thriftBinary)
+	w.binary([]byte(v))
+}
@@ -0,0 +1,72 @@
+package sinks
+
+import (
+	"context"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// Queue publishes findings to a sink in the background, so a slow or
+// unreachable broker doesn't hold up the scan until the queue fills.
+type Queue struct {
+	sink    Sink
+	onError func(error)
+	queue   chan queued
+	done    sync.WaitGroup
+}
+
+type queued struct {
+	ctx    context.Context
+	result *detectors.ResultWithMetadata
+}
+
+// Ensure the Queue satisfies the interface at compile time.
+var _ Sink = (*Queue)(nil)
+
+// NewQueue returns a Queue holding up to size findings for sink. Errors
+// returned by the sink are passed to onError.
+func NewQueue(sink Sink, size int, onError func(error)) *Queue {
+	q := &Queue{
+		sink:    sink,
+		onError: onError,
+		queue:   make(chan queued, size),
+	}
+	q.done.Add(1)
+	go func() {
+		defer q.done.Done()
+		for item := range q.queue {
+			if err := q.sink.Send(item.ctx, item.result); err != nil && q.onError != nil {
+				q.onError(err)
+			}
+		}
+	}()
+	return q
+}
+
+// Send queues r to be published. It blocks while the queue is full.
+func (q *Queue) Send(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	select {
+	case q.queue <- queued{ctx: ctx, result: r}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Backlog returns the number of findings waiting to be published.
+func (q *Queue) Backlog() int {
+	return len(q.queue)
+}
+
+// Capacity returns the number of findings the queue can hold.
+func (q *Queue) Capacity() int {
+	return cap(q.queue)
+}
+
+// Close publishes the findings still queued and closes the sink.
+func (q *Queue) Close() error {
+	close(q.queue)
+	q.done.Wait()
+	return q.sink.Close()
+}
@@ -0,0 +1,279 @@
+// Package securityhub imports findings into AWS Security Hub in the AWS
+// Security Finding Format (ASFF), and converts them to it for other AWS
+// tooling.
+package securityhub
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/private/protocol/json/jsonutil"
+	"github.com/aws/aws-sdk-go/service/securityhub"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/findings"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sinks"
+)
+
+const (
+	// batchSize is the most findings BatchImportFindings accepts at once.
+	batchSize = 100
+	// schemaVersion is the ASFF version findings are written in.
+	schemaVersion = "2018-10-08"
+	// findingType is the ASFF finding type of leaked secrets.
+	findingType = "Sensitive Data Identifications/Passwords"
+	// maxTitle and maxDescription are the ASFF field length limits.
+	maxTitle       = 256
+	maxDescription = 1024
+)
+
+// importer is the part of the Security Hub client used by the sink.
+type importer interface {
+	BatchImportFindingsWithContext(ctx aws.Context, input *securityhub.BatchImportFindingsInput, opts ...request.Option) (*securityhub.BatchImportFindingsOutput, error)
+}
+
+// Sink imports findings into Security Hub in batches. Findings are identified
+// by their finding ID, so importing the same finding again updates it rather
+// than creating a duplicate.
+type Sink struct {
+	client    importer
+	region    string
+	accountID string
+	// now returns the time findings are created and updated at.
+	now func() time.Time
+
+	mu    sync.Mutex
+	batch []*securityhub.AwsSecurityFinding
+}
+
+// Ensure the Sink satisfies the interface at compile time.
+var _ sinks.Sink = (*Sink)(nil)
+
+// New returns a sink that imports findings into Security Hub in region, using
+// the AWS credentials in the environment. Findings are attributed to
+// accountID, or to the account of the credentials if it's empty.
+func New(ctx context.Context, region, accountID string) (*Sink, error) {
+	if region == "" {
+		return nil, errors.New("a Security Hub region is required")
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+		Config:            aws.Config{Region: aws.String(region)},
+	})
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not create aws session", 0)
+	}
+	if accountID == "" {
+		accountID, err = lookupAccount(ctx, sess)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return newSink(securityhub.New(sess), region, accountID), nil
+}
+
+// LookupAccount returns the ID of the AWS account of the credentials in the
+// environment.
+func LookupAccount(ctx context.Context) (string, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{SharedConfigState: session.SharedConfigEnable})
+	if err != nil {
+		return "", errors.WrapPrefix(err, "could not create aws session", 0)
+	}
+	return lookupAccount(ctx, sess)
+}
+
+func lookupAccount(ctx context.Context, sess *session.Session) (string, error) {
+	identity, err := sts.New(sess).GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", errors.WrapPrefix(err, "could not look up the aws account", 0)
+	}
+	return aws.StringValue(identity.Account), nil
+}
+
+func newSink(client importer, region, accountID string) *Sink {
+	return &Sink{client: client, region: region, accountID: accountID, now: time.Now}
+}
+
+func (s *Sink) Send(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	f := Finding(r, s.region, s.accountID, s.now())
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batch = append(s.batch, f)
+	if len(s.batch) < batchSize {
+		return nil
+	}
+	return s.flush(ctx)
+}
+
+// Close imports the findings that haven't been imported yet.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flush(context.Background())
+}
+
+func (s *Sink) flush(ctx context.Context) error {
+	if len(s.batch) == 0 {
+		return nil
+	}
+	batch := s.batch
+	s.batch = nil
+	out, err := s.client.BatchImportFindingsWithContext(ctx, &securityhub.BatchImportFindingsInput{Findings: batch})
+	if err != nil {
+		return errors.WrapPrefix(err, "could not import findings into Security Hub", 0)
+	}
+	if failed := aws.Int64Value(out.FailedCount); failed > 0 {
+		reason := ""
+		if len(out.FailedFindings) > 0 {
+			reason = ": " + aws.StringValue(out.FailedFindings[0].ErrorMessage)
+		}
+		return errors.Errorf("Security Hub rejected %d of %d findings%s", failed, len(batch), reason)
+	}
+	return nil
+}
+
+// Finding converts r to ASFF, as a finding of a product in accountID's region,
+// created and updated at now. Findings in S3 objects have the object and its
+// bucket as their resources, identified by their ARNs.
+func Finding(r *detectors.ResultWithMetadata, region, accountID string, now time.Time) *securityhub.AwsSecurityFinding {
+	meta := sinks.Metadata(r)
+	detector := r.DetectorType.String()
+	timestamp := now.UTC().Format(time.RFC3339)
+	partition := partitionOf(region)
+	status := "unverified"
+	if r.Verified {
+		status = "verified"
+	}
+
+	location := sinks.Location(meta)
+	resource := location
+	if repo := sinks.Repository(meta); repo != "" {
+		resource = repo + "/" + location
+	}
+	if resource == "" {
+		resource = r.SourceName
+	}
+
+	title := detector + " secret"
+	if location != "" {
+		title += " in " + location
+	}
+	description := fmt.Sprintf("TruffleHog found a %s %s secret (%s) in %s.", status, detector, sinks.Mask(r), resource)
+	if commit := meta["commit"]; commit != "" {
+		description += " Commit: " + commit + "."
+	}
+
+	productFields := map[string]*string{
+		"trufflehog/Detector": aws.String(detector),
+		"trufflehog/Verified": aws.String(fmt.Sprint(r.Verified)),
+		"trufflehog/Source":   aws.String(r.SourceName),
+	}
+	for _, k := range []string{"commit", "email"} {
+		if v := meta[k]; v != "" {
+			productFields["trufflehog/"+strings.Title(k)] = aws.String(v)
+		}
+	}
+
+	f := &securityhub.AwsSecurityFinding{
+		SchemaVersion: aws.String(schemaVersion),
+		Id:            aws.String(findings.ID(r)),
+		ProductArn:    aws.String(fmt.Sprintf("arn:%s:securityhub:%s:%s:product/%s/default", partition, region, accountID, accountID)),
+		GeneratorId:   aws.String("trufflehog/" + detector),
+		AwsAccountId:  aws.String(accountID),
+		Types:         []*string{aws.String(findingType)},
+		CreatedAt:     aws.String(timestamp),
+		UpdatedAt:     aws.String(timestamp),
+		Severity:      &securityhub.Severity{Label: aws.String(strings.ToUpper(sinks.Severity(r)))},
+		Title:         aws.String(truncate(title, maxTitle)),
+		Description:   aws.String(truncate(description, maxDescription)),
+		ProductFields: productFields,
+		Resources:     resources(r, resource, region, partition),
+		RecordState:   aws.String(securityhub.RecordStateActive),
+	}
+	if link := sinks.Link(meta); link != "" {
+		f.SourceUrl = aws.String(link)
+	}
+	return f
+}
+
+// Encode returns f as ASFF JSON, as Security Hub's BatchImportFindings takes
+// it.
+func Encode(f *securityhub.AwsSecurityFinding) ([]byte, error) {
+	return jsonutil.BuildJSON(f)
+}
+
+// resources returns the resources of a finding in resource, which are the
+// object and bucket of findings in S3.
+func resources(r *detectors.ResultWithMetadata, resource, region, partition string) []*securityhub.Resource {
+	object := r.SourceMetadata.GetS3()
+	if object == nil || object.Bucket == "" || object.File == "" {
+		return []*securityhub.Resource{{
+			Type:   aws.String("Other"),
+			Id:     aws.String(resource),
+			Region: aws.String(region),
+		}}
+	}
+	if bucketRegion := s3Region(object.Bucket, object.Link); bucketRegion != "" {
+		region = bucketRegion
+	}
+	bucketARN := fmt.Sprintf("arn:%s:s3:::%s", partition, object.Bucket)
+	return []*securityhub.Resource{
+		{
+			Type:      aws.String("AwsS3Object"),
+			Id:        aws.String(bucketARN + "/" + object.File),
+			Partition: aws.String(partition),
+			Region:    aws.String(region),
+		},
+		{
+			Type:      aws.String("AwsS3Bucket"),
+			Id:        aws.String(bucketARN),
+			Partition: aws.String(partition),
+			Region:    aws.String(region),
+		},
+	}
+}
+
+// s3Region returns the region in the link of an object in bucket, such as
+// https://bucket.s3.eu-west-1.amazonaws.com/key. Links without a region are
+// to buckets in us-east-1.
+func s3Region(bucket, link string) string {
+	host := strings.TrimPrefix(link, "https://"+bucket+".s3")
+	if host == link {
+		return ""
+	}
+	if i := strings.Index(host, "/"); i >= 0 {
+		host = host[:i]
+	}
+	if !strings.HasSuffix(host, ".amazonaws.com") {
+		return ""
+	}
+	region := strings.TrimPrefix(strings.TrimSuffix(host, ".amazonaws.com"), ".")
+	if region == "" {
+		return "us-east-1"
+	}
+	return region
+}
+
+// partitionOf returns the AWS partition of region, such as aws-us-gov.
+func partitionOf(region string) string {
+	if p, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region); ok {
+		return p.ID()
+	}
+	return endpoints.AwsPartitionID
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
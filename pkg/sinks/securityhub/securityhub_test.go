@@ -0,0 +1,183 @@
+package securityhub
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/securityhub"
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/findings"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+type fakeImporter struct {
+	batches [][]*securityhub.AwsSecurityFinding
+	failed  int64
+}
+
+func (f *fakeImporter) BatchImportFindingsWithContext(_ aws.Context, input *securityhub.BatchImportFindingsInput, _ ...request.Option) (*securityhub.BatchImportFindingsOutput, error) {
+	f.batches = append(f.batches, input.Findings)
+	out := &securityhub.BatchImportFindingsOutput{FailedCount: aws.Int64(f.failed)}
+	if f.failed > 0 {
+		out.FailedFindings = []*securityhub.ImportFindingsError{{ErrorMessage: aws.String("invalid ProductArn")}}
+	}
+	return out, nil
+}
+
+func gitResult(file string) *detectors.ResultWithMetadata {
+	return &detectors.ResultWithMetadata{
+		SourceName: "trufflehog - git",
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Git{
+				Git: &source_metadatapb.Git{Repository: "https://github.com/acme/api.git", Commit: "abc123", File: file, Line: 10},
+			},
+		},
+		Result: detectors.Result{DetectorType: detectorspb.DetectorType_AWS, Raw: []byte("AKIAEXAMPLEKEY"), Verified: true},
+	}
+}
+
+func TestSink_finding(t *testing.T) {
+	r := gitResult("config.py")
+
+	got := Finding(r, "us-east-1", "123456789012", time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC))
+	want := &securityhub.AwsSecurityFinding{
+		SchemaVersion: aws.String("2018-10-08"),
+		Id:            aws.String(findings.ID(r)),
+		ProductArn:    aws.String("arn:aws:securityhub:us-east-1:123456789012:product/123456789012/default"),
+		GeneratorId:   aws.String("trufflehog/AWS"),
+		AwsAccountId:  aws.String("123456789012"),
+		Types:         []*string{aws.String("Sensitive Data Identifications/Passwords")},
+		CreatedAt:     aws.String("2022-06-01T12:00:00Z"),
+		UpdatedAt:     aws.String("2022-06-01T12:00:00Z"),
+		Severity:      &securityhub.Severity{Label: aws.String("HIGH")},
+		Title:         aws.String("AWS secret in config.py:10"),
+		Description:   aws.String("TruffleHog found a verified AWS secret (AKIA********) in https://github.com/acme/api.git/config.py:10. Commit: abc123."),
+		ProductFields: map[string]*string{
+			"trufflehog/Detector": aws.String("AWS"),
+			"trufflehog/Verified": aws.String("true"),
+			"trufflehog/Source":   aws.String("trufflehog - git"),
+			"trufflehog/Commit":   aws.String("abc123"),
+		},
+		Resources: []*securityhub.Resource{{
+			Type:   aws.String("Other"),
+			Id:     aws.String("https://github.com/acme/api.git/config.py:10"),
+			Region: aws.String("us-east-1"),
+		}},
+		RecordState: aws.String("ACTIVE"),
+		SourceUrl:   aws.String("https://github.com/acme/api/blob/abc123/config.py#L10"),
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("finding() diff: (-got +want)\n%s", diff)
+	}
+}
+
+func TestSink_Batches(t *testing.T) {
+	client := &fakeImporter{}
+	s := newSink(client, "us-east-1", "123456789012")
+	for i := 0; i < batchSize+1; i++ {
+		if err := s.Send(context.Background(), gitResult(fmt.Sprintf("file%d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.batches) != 2 || len(client.batches[0]) != batchSize || len(client.batches[1]) != 1 {
+		t.Errorf("unexpected batches: %d", len(client.batches))
+	}
+
+	client.failed = 1
+	if err := s.Send(context.Background(), gitResult("file")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err == nil {
+		t.Error("Close() should fail when findings are rejected")
+	}
+}
+
+func TestFinding_s3(t *testing.T) {
+	r := &detectors.ResultWithMetadata{
+		SourceName: "trufflehog - s3",
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_S3{
+				S3: &source_metadatapb.S3{Bucket: "my.s3.bucket", File: "env/prod.env", Link: "https://my.s3.bucket.s3.eu-west-1.amazonaws.com/env/prod.env"},
+			},
+		},
+		Result: detectors.Result{DetectorType: detectorspb.DetectorType_AWS, Raw: []byte("AKIAEXAMPLEKEY"), Verified: true},
+	}
+	tests := []struct {
+		name   string
+		region string
+		want   []*securityhub.Resource
+	}{
+		{
+			name:   "commercial",
+			region: "us-east-1",
+			want: []*securityhub.Resource{
+				{Type: aws.String("AwsS3Object"), Id: aws.String("arn:aws:s3:::my.s3.bucket/env/prod.env"), Partition: aws.String("aws"), Region: aws.String("eu-west-1")},
+				{Type: aws.String("AwsS3Bucket"), Id: aws.String("arn:aws:s3:::my.s3.bucket"), Partition: aws.String("aws"), Region: aws.String("eu-west-1")},
+			},
+		},
+		{
+			name:   "GovCloud",
+			region: "us-gov-west-1",
+			want: []*securityhub.Resource{
+				{Type: aws.String("AwsS3Object"), Id: aws.String("arn:aws-us-gov:s3:::my.s3.bucket/env/prod.env"), Partition: aws.String("aws-us-gov"), Region: aws.String("eu-west-1")},
+				{Type: aws.String("AwsS3Bucket"), Id: aws.String("arn:aws-us-gov:s3:::my.s3.bucket"), Partition: aws.String("aws-us-gov"), Region: aws.String("eu-west-1")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Finding(r, tt.region, "123456789012", time.Now())
+			if diff := pretty.Compare(got.Resources, tt.want); diff != "" {
+				t.Errorf("Finding() resources diff: (-got +want)\n%s", diff)
+			}
+		})
+	}
+
+	if got := Finding(r, "us-gov-west-1", "123456789012", time.Now()).ProductArn; aws.StringValue(got) != "arn:aws-us-gov:securityhub:us-gov-west-1:123456789012:product/123456789012/default" {
+		t.Errorf("Finding() ProductArn = %s", aws.StringValue(got))
+	}
+}
+
+func TestS3Region(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+	}{
+		{link: "https://bucket.s3.amazonaws.com/key", want: "us-east-1"},
+		{link: "https://bucket.s3.ap-south-1.amazonaws.com/dir/key", want: "ap-south-1"},
+		{link: "https://other.s3.amazonaws.com/key", want: ""},
+		{link: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := s3Region("bucket", tt.link); got != tt.want {
+			t.Errorf("s3Region(%q) = %q, want %q", tt.link, got, tt.want)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	out, err := Encode(Finding(gitResult("config.py"), "us-east-1", "123456789012", time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(out)
+	for _, want := range []string{`"SchemaVersion":"2018-10-08"`, `"AwsAccountId":"123456789012"`, `"Resources":[{"Id":"https://github.com/acme/api.git/config.py:10","Region":"us-east-1","Type":"Other"}]`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Encode() = %s, want it to have %s", got, want)
+		}
+	}
+	if strings.Contains(got, "null") {
+		t.Errorf("Encode() = %s, want no null fields", got)
+	}
+}
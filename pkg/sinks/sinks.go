@@ -0,0 +1,147 @@
+// Package sinks publishes findings to external systems as they are found.
+package sinks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/findings"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/output"
+)
+
+// Sink receives every finding reported by a scan.
+type Sink interface {
+	// Send publishes a single finding.
+	Send(ctx context.Context, r *detectors.ResultWithMetadata) error
+	// Close flushes any buffered findings and releases the sink's resources.
+	Close() error
+}
+
+// Message keys that can be attached to published findings so consumers can
+// partition or deduplicate them.
+const (
+	KeyNone     = ""
+	KeyDetector = "detector"
+	KeySource   = "source"
+	KeySecret   = "secret"
+	KeyFinding  = "finding"
+	KeyTenant   = "tenant"
+)
+
+// Message formats findings can be published in.
+const (
+	FormatJSON     = "json"
+	FormatProtobuf = "protobuf"
+)
+
+// MessageOptions configures how findings are encoded into messages.
+type MessageOptions struct {
+	// Format is one of the Format* constants. It defaults to FormatJSON.
+	Format string
+	// Key is one of the Key* constants.
+	Key string
+	// Headers are added to every message.
+	Headers map[string]string
+}
+
+// Encode returns r encoded in format. FormatProtobuf produces a
+// findingspb.Finding.
+func Encode(format string, r *detectors.ResultWithMetadata) ([]byte, error) {
+	switch format {
+	case FormatJSON, "":
+		return output.EncodeJSON(r)
+	case FormatProtobuf:
+		return proto.Marshal(findings.FromResult(r))
+	default:
+		return nil, errors.Errorf("unknown message format %q", format)
+	}
+}
+
+// Key returns the message key for r. KeySecret uses a hash of the raw secret so
+// the secret itself is never written to broker metadata, and KeyFinding uses
+// the finding's stable ID.
+func Key(kind string, r *detectors.ResultWithMetadata) (string, error) {
+	switch kind {
+	case KeyNone:
+		return "", nil
+	case KeyDetector:
+		return r.DetectorType.String(), nil
+	case KeySource:
+		return r.SourceName, nil
+	case KeySecret:
+		sum := sha256.Sum256(r.Raw)
+		return hex.EncodeToString(sum[:]), nil
+	case KeyFinding:
+		return findings.ID(r), nil
+	case KeyTenant:
+		return r.Tenant, nil
+	default:
+		return "", errors.Errorf("unknown message key %q", kind)
+	}
+}
+
+// ParseHeaders parses "name=value" pairs into message headers.
+func ParseHeaders(pairs []string) (map[string]string, error) {
+	headers := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, errors.Errorf("invalid header %q, expected name=value", pair)
+		}
+		headers[name] = value
+	}
+	return headers, nil
+}
+
+// Multi sends each finding to every sink in order.
+type Multi []Sink
+
+// Ensure Multi satisfies the interface at compile time.
+var _ Sink = (Multi)(nil)
+
+func (m Multi) Send(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	for _, sink := range m {
+		if err := sink.Send(ctx, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m Multi) Close() error {
+	var firstErr error
+	for _, sink := range m {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Tenant sends only the findings of a tenant to its sink, so that each
+// tenant's findings can be published to its own destination, such as its own
+// webhook or topic, without reaching the others'.
+type Tenant struct {
+	Name string
+	Sink Sink
+}
+
+// Ensure Tenant satisfies the interface at compile time.
+var _ Sink = Tenant{}
+
+func (t Tenant) Send(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	if r.Tenant != t.Name {
+		return nil
+	}
+	return t.Sink.Send(ctx, r)
+}
+
+func (t Tenant) Close() error {
+	return t.Sink.Close()
+}
@@ -0,0 +1,187 @@
+package sinks
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/findings"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/findingspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestKey(t *testing.T) {
+	r := &detectors.ResultWithMetadata{
+		SourceName: "trufflehog - git",
+		Tenant:     "acme",
+		Result: detectors.Result{
+			DetectorType: detectorspb.DetectorType_AWS,
+			Raw:          []byte("secret"),
+		},
+	}
+	tests := map[string]string{
+		KeyNone:     "",
+		KeyDetector: "AWS",
+		KeySource:   "trufflehog - git",
+		KeyTenant:   "acme",
+		KeySecret:   "2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b",
+	}
+	for kind, want := range tests {
+		got, err := Key(kind, r)
+		if err != nil {
+			t.Errorf("Key(%q) returned error: %s", kind, err)
+		}
+		if got != want {
+			t.Errorf("Key(%q) = %q, want %q", kind, got, want)
+		}
+	}
+	if _, err := Key("bogus", r); err == nil {
+		t.Error("expected an error for an unknown key")
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := ParseHeaders([]string{"team=appsec", "env=ci=true"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if headers["team"] != "appsec" || headers["env"] != "ci=true" {
+		t.Errorf("unexpected headers: %v", headers)
+	}
+	if _, err := ParseHeaders([]string{"novalue"}); err == nil {
+		t.Error("expected an error for a header without a value")
+	}
+}
+
+func TestEncodeProtobuf(t *testing.T) {
+	r := &detectors.ResultWithMetadata{
+		Result: detectors.Result{DetectorType: detectorspb.DetectorType_AWS, Raw: []byte("secret")},
+	}
+	out, err := Encode(FormatProtobuf, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var finding findingspb.Finding
+	if err := proto.Unmarshal(out, &finding); err != nil {
+		t.Fatal(err)
+	}
+	if finding.Id != findings.ID(r) || finding.DetectorType != detectorspb.DetectorType_AWS {
+		t.Errorf("unexpected finding: %v", &finding)
+	}
+}
+
+// blockingSink records findings once it's unblocked.
+type blockingSink struct {
+	unblock chan struct{}
+	sent    []string
+	closed  bool
+}
+
+func (s *blockingSink) Send(_ context.Context, r *detectors.ResultWithMetadata) error {
+	<-s.unblock
+	s.sent = append(s.sent, string(r.Raw))
+	return nil
+}
+
+func (s *blockingSink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestQueue(t *testing.T) {
+	ctx := context.Background()
+	sink := &blockingSink{unblock: make(chan struct{})}
+	q := NewQueue(sink, 2, func(err error) { t.Error(err) })
+
+	for _, raw := range []string{"a", "b", "c"} {
+		if err := q.Send(ctx, &detectors.ResultWithMetadata{Result: detectors.Result{Raw: []byte(raw)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The first finding is held by the blocked sink and the rest are queued.
+	if got := q.Backlog(); got != 2 {
+		t.Errorf("Backlog() = %d, want 2", got)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := q.Send(cancelled, &detectors.ResultWithMetadata{}); err == nil {
+		t.Error("expected an error sending to a full queue with a cancelled context")
+	}
+
+	close(sink.unblock)
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.sent) != 3 || !sink.closed {
+		t.Errorf("expected all findings to be published before closing, got %v", sink.sent)
+	}
+}
+
+func TestTenant(t *testing.T) {
+	sink := &blockingSink{unblock: make(chan struct{})}
+	close(sink.unblock)
+	tenant := Tenant{Name: "acme", Sink: sink}
+	for _, name := range []string{"acme", "globex", "", "acme"} {
+		if err := tenant.Send(context.Background(), &detectors.ResultWithMetadata{Tenant: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tenant.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.sent) != 2 || !sink.closed {
+		t.Errorf("Tenant sent %d findings, want acme's 2", len(sink.sent))
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	tests := []struct {
+		name string
+		r    detectors.Result
+		want string
+	}{
+		{name: "verified", r: detectors.Result{Verified: true}, want: SeverityHigh},
+		{name: "unverified", r: detectors.Result{}, want: SeverityMedium},
+		{name: "from detector", r: detectors.Result{ExtraData: map[string]string{"severity": "critical"}}, want: SeverityCritical},
+		{name: "unknown from detector", r: detectors.Result{Verified: true, ExtraData: map[string]string{"severity": "urgent"}}, want: SeverityHigh},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Severity(&detectors.ResultWithMetadata{Result: tt.r}); got != tt.want {
+				t.Errorf("Severity() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAtLeast(t *testing.T) {
+	tests := []struct {
+		severity, threshold string
+		want                bool
+	}{
+		{SeverityCritical, SeverityHigh, true},
+		{SeverityHigh, SeverityHigh, true},
+		{SeverityMedium, SeverityHigh, false},
+		{"urgent", SeverityLow, false},
+	}
+	for _, tt := range tests {
+		if got := AtLeast(tt.severity, tt.threshold); got != tt.want {
+			t.Errorf("AtLeast(%q, %q) = %v, want %v", tt.severity, tt.threshold, got, tt.want)
+		}
+	}
+}
+
+func TestLocation_provenance(t *testing.T) {
+	r := &detectors.ResultWithMetadata{
+		SourceMetadata: &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_S3{S3: &source_metadatapb.S3{Bucket: "bucket", File: "bundle.zip"}}},
+		Provenance:     []sources.ContainerRef{{Type: "zip", Name: "app.tar"}, {Type: "tar", Name: "etc/app.env"}},
+	}
+	if got, want := Location(Metadata(r)), "bundle.zip > zip:app.tar > tar:etc/app.env"; got != want {
+		t.Errorf("Location() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,410 @@
+// Package syslog sends findings to a syslog collector, such as a SIEM, as
+// RFC 5424 messages or as CEF or LEEF events.
+package syslog
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/findings"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sinks"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/version"
+)
+
+// Formats findings can be sent in. All of them are sent in an RFC 5424
+// message; CEF and LEEF events are its message body.
+const (
+	FormatRFC5424 = "rfc5424"
+	FormatCEF     = "cef"
+	FormatLEEF    = "leef"
+)
+
+// Networks findings can be sent over.
+const (
+	NetworkTCP = "tcp"
+	NetworkTLS = "tls"
+	NetworkUDP = "udp"
+)
+
+const (
+	vendor  = "Truffle Security"
+	product = "TruffleHog"
+	// sdID is the structured data element RFC 5424 messages carry fields in.
+	// 32473 is the private enterprise number reserved for examples.
+	sdID = "trufflehog@32473"
+	// facility is the syslog facility messages are sent with, user-level
+	// messages.
+	facility = 1
+	// leefDelimiter separates the attributes of LEEF events.
+	leefDelimiter = "^"
+)
+
+// DefaultFields are the fields each format carries, by the name the format
+// gives them.
+var DefaultFields = map[string]map[string]string{
+	FormatRFC5424: {
+		"detector":   "detector",
+		"verified":   "verified",
+		"severity":   "severity",
+		"finding_id": "finding_id",
+		"redacted":   "redacted",
+		"source":     "source_name",
+		"repository": "repository",
+		"file":       "file",
+		"line":       "line",
+		"commit":     "commit",
+		"email":      "email",
+		"link":       "link",
+	},
+	FormatCEF: {
+		"cs1":     "repository",
+		"cs2":     "commit",
+		"cs3":     "finding_id",
+		"cs4":     "redacted",
+		"cs5":     "source_name",
+		"cn1":     "line",
+		"fname":   "file",
+		"suser":   "email",
+		"request": "link",
+	},
+	FormatLEEF: {
+		"repository": "repository",
+		"file":       "file",
+		"line":       "line",
+		"commit":     "commit",
+		"usrName":    "email",
+		"url":        "link",
+		"findingID":  "finding_id",
+		"redacted":   "redacted",
+		"source":     "source_name",
+	},
+}
+
+// Config says where to send findings and how.
+type Config struct {
+	// Address is the collector's host and port.
+	Address string
+	// Network is NetworkTCP, NetworkTLS, or NetworkUDP. It defaults to TCP.
+	Network string
+	// Format is FormatRFC5424, FormatCEF, or FormatLEEF. It defaults to RFC
+	// 5424.
+	Format string
+	// CAFile is a PEM file of the certificate authorities to trust when
+	// sending over TLS, instead of the system's.
+	CAFile string
+	// Fields maps the names fields are sent as to the finding fields they
+	// hold, replacing the format's DefaultFields. Finding fields are
+	// detector, verified, severity, finding_id, redacted, source_type,
+	// source_name, repository, location, link, the fields of the source
+	// metadata such as file and commit, and extra.<name> for the detector's
+	// extra data. CEF custom fields such as cs1 are labelled with the name of
+	// the finding field.
+	Fields map[string]string
+}
+
+// ParseFields parses name=field pairs into Config.Fields.
+func ParseFields(pairs []string) (map[string]string, error) {
+	fields := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		name, field, ok := strings.Cut(pair, "=")
+		if !ok || name == "" || field == "" {
+			return nil, errors.Errorf("invalid syslog field %q, expected name=field", pair)
+		}
+		fields[name] = field
+	}
+	return fields, nil
+}
+
+// Sink sends each finding to the collector as it's found.
+type Sink struct {
+	config    Config
+	tlsConfig *tls.Config
+	hostname  string
+	// now returns the time findings are sent at.
+	now func() time.Time
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// Ensure the Sink satisfies the interface at compile time.
+var _ sinks.Sink = (*Sink)(nil)
+
+// sdName matches the names RFC 5424 allows structured data parameters to
+// have.
+var sdName = regexp.MustCompile(`^[!#-<>-\\^-~]{1,32}$`)
+
+// New connects to the collector and returns a sink that sends findings to it.
+func New(config Config) (*Sink, error) {
+	if config.Address == "" {
+		return nil, errors.New("a syslog address is required")
+	}
+	if config.Network == "" {
+		config.Network = NetworkTCP
+	}
+	if config.Format == "" {
+		config.Format = FormatRFC5424
+	}
+	if _, ok := DefaultFields[config.Format]; !ok {
+		return nil, errors.Errorf("unknown syslog format %q", config.Format)
+	}
+	if len(config.Fields) == 0 {
+		config.Fields = DefaultFields[config.Format]
+	}
+	if config.Format == FormatRFC5424 {
+		for name := range config.Fields {
+			if !sdName.MatchString(name) {
+				return nil, errors.Errorf("invalid syslog field name %q", name)
+			}
+		}
+	}
+
+	s := &Sink{config: config, now: time.Now}
+	switch config.Network {
+	case NetworkTCP, NetworkUDP:
+	case NetworkTLS:
+		host, _, err := net.SplitHostPort(config.Address)
+		if err != nil {
+			return nil, errors.WrapPrefix(err, "invalid syslog address", 0)
+		}
+		s.tlsConfig = &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+		if config.CAFile != "" {
+			pem, err := os.ReadFile(config.CAFile)
+			if err != nil {
+				return nil, errors.WrapPrefix(err, "could not read syslog CA file", 0)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, errors.Errorf("no certificates found in %s", config.CAFile)
+			}
+			s.tlsConfig.RootCAs = pool
+		}
+	default:
+		return nil, errors.Errorf("unknown syslog network %q", config.Network)
+	}
+
+	s.hostname, _ = os.Hostname()
+	if s.hostname == "" {
+		s.hostname = "-"
+	}
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Sink) connect() error {
+	var conn net.Conn
+	var err error
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if s.tlsConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", s.config.Address, s.tlsConfig)
+	} else {
+		conn, err = dialer.Dial(s.config.Network, s.config.Address)
+	}
+	if err != nil {
+		return errors.WrapPrefix(err, "could not connect to syslog collector", 0)
+	}
+	s.conn = conn
+	return nil
+}
+
+func (s *Sink) Send(_ context.Context, r *detectors.ResultWithMetadata) error {
+	msg := s.message(r)
+	if s.config.Network != NetworkUDP {
+		// Messages sent over streams are framed by octet counting, as RFC
+		// 6587 and RFC 5425 describe.
+		msg = strconv.Itoa(len(msg)) + " " + msg
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.conn.Write([]byte(msg)); err == nil {
+		return nil
+	}
+	// The collector may have closed an idle connection, so reconnect once.
+	s.conn.Close()
+	if err := s.connect(); err != nil {
+		return err
+	}
+	if _, err := s.conn.Write([]byte(msg)); err != nil {
+		return errors.WrapPrefix(err, "could not send finding to syslog collector", 0)
+	}
+	return nil
+}
+
+// Close closes the connection to the collector.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn.Close()
+}
+
+// message formats r as an RFC 5424 message.
+func (s *Sink) message(r *detectors.ResultWithMetadata) string {
+	now := s.now().UTC()
+	severity := sinks.Severity(r)
+	values := fields(r)
+
+	structured, body := "-", ""
+	switch s.config.Format {
+	case FormatRFC5424:
+		structured = s.structuredData(values)
+		body = summary(r, values)
+	case FormatCEF:
+		body = s.cef(r, values, severity, now)
+	case FormatLEEF:
+		body = s.leef(r, values, severity, now)
+	}
+
+	pri := facility*8 + syslogSeverity[severity]
+	return fmt.Sprintf("<%d>1 %s %s trufflehog %d finding %s %s",
+		pri, now.Format("2006-01-02T15:04:05.000000Z07:00"), s.hostname, os.Getpid(), structured, body)
+}
+
+func (s *Sink) structuredData(values map[string]string) string {
+	var b strings.Builder
+	b.WriteString("[" + sdID)
+	for _, name := range sortedKeys(s.config.Fields) {
+		v, ok := values[s.config.Fields[name]]
+		if !ok || v == "" {
+			continue
+		}
+		fmt.Fprintf(&b, ` %s="%s"`, name, sdEscaper.Replace(v))
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// customLabel matches the CEF custom fields that are labelled by another field.
+var customLabel = regexp.MustCompile(`^(cs[1-6]|cn[1-3]|cfp[1-4]|flexString[12])$`)
+
+func (s *Sink) cef(r *detectors.ResultWithMetadata, values map[string]string, severity string, now time.Time) string {
+	detector := r.DetectorType.String()
+	var b strings.Builder
+	fmt.Fprintf(&b, "CEF:0|%s|%s|%s|%s|%s|%d|",
+		cefHeaderEscaper.Replace(vendor), cefHeaderEscaper.Replace(product), cefHeaderEscaper.Replace(version.BuildVersion),
+		cefHeaderEscaper.Replace(detector), cefHeaderEscaper.Replace(detector+" secret found"), cefSeverity[severity])
+	fmt.Fprintf(&b, "rt=%d cat=%s", now.UnixMilli(), verification(r))
+	for _, name := range sortedKeys(s.config.Fields) {
+		field := s.config.Fields[name]
+		v, ok := values[field]
+		if !ok || v == "" {
+			continue
+		}
+		fmt.Fprintf(&b, " %s=%s", name, cefValueEscaper.Replace(v))
+		if customLabel.MatchString(name) {
+			fmt.Fprintf(&b, " %sLabel=%s", name, cefValueEscaper.Replace(field))
+		}
+	}
+	return b.String()
+}
+
+func (s *Sink) leef(r *detectors.ResultWithMetadata, values map[string]string, severity string, now time.Time) string {
+	detector := r.DetectorType.String()
+	clean := func(v string) string {
+		return strings.NewReplacer(leefDelimiter, " ", "|", " ", "\n", " ", "\r", " ").Replace(v)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "LEEF:2.0|%s|%s|%s|%s|%s|", clean(vendor), clean(product), clean(version.BuildVersion), clean(detector), leefDelimiter)
+	fmt.Fprintf(&b, "devTime=%d%ssev=%d%scat=%s", now.UnixMilli(), leefDelimiter, cefSeverity[severity], leefDelimiter, verification(r))
+	for _, name := range sortedKeys(s.config.Fields) {
+		v, ok := values[s.config.Fields[name]]
+		if !ok || v == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "%s%s=%s", leefDelimiter, name, clean(v))
+	}
+	return b.String()
+}
+
+// fields returns the fields of r that can be sent, by the names Config.Fields
+// refers to them by.
+func fields(r *detectors.ResultWithMetadata) map[string]string {
+	meta := sinks.Metadata(r)
+	values := make(map[string]string, len(meta)+len(r.ExtraData)+10)
+	for k, v := range meta {
+		values[k] = v
+	}
+	for k, v := range r.ExtraData {
+		values["extra."+k] = v
+	}
+	values["detector"] = r.DetectorType.String()
+	values["verified"] = strconv.FormatBool(r.Verified)
+	values["severity"] = sinks.Severity(r)
+	values["finding_id"] = findings.ID(r)
+	values["redacted"] = sinks.Mask(r)
+	values["source_type"] = r.SourceType.String()
+	values["source_name"] = r.SourceName
+	values["repository"] = sinks.Repository(meta)
+	values["location"] = sinks.Location(meta)
+	values["link"] = sinks.Link(meta)
+	if values["line"] == "0" {
+		delete(values, "line")
+	}
+	return values
+}
+
+// summary is the human readable message of RFC 5424 messages.
+func summary(r *detectors.ResultWithMetadata, values map[string]string) string {
+	msg := fmt.Sprintf("Found %s %s secret", verification(r), r.DetectorType.String())
+	if location := values["location"]; location != "" {
+		msg += " in " + location
+	}
+	if repo := values["repository"]; repo != "" {
+		msg += " of " + repo
+	}
+	return msg
+}
+
+func verification(r *detectors.ResultWithMetadata) string {
+	if r.Verified {
+		return "verified"
+	}
+	return "unverified"
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var (
+	sdEscaper        = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+	cefHeaderEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	cefValueEscaper  = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+)
+
+// syslogSeverity and cefSeverity are the severities finding severities are
+// sent as.
+var (
+	syslogSeverity = map[string]int{
+		sinks.SeverityCritical: 2,
+		sinks.SeverityHigh:     3,
+		sinks.SeverityMedium:   4,
+		sinks.SeverityLow:      5,
+	}
+	cefSeverity = map[string]int{
+		sinks.SeverityCritical: 10,
+		sinks.SeverityHigh:     8,
+		sinks.SeverityMedium:   5,
+		sinks.SeverityLow:      3,
+	}
+)
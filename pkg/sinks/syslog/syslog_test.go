@@ -0,0 +1,145 @@
+package syslog
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/findings"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+func testResult() *detectors.ResultWithMetadata {
+	return &detectors.ResultWithMetadata{
+		SourceName: "trufflehog - git",
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Git{
+				Git: &source_metadatapb.Git{Repository: "https://github.com/acme/api.git", Commit: "abc123", File: "config.py", Line: 10},
+			},
+		},
+		Result: detectors.Result{DetectorType: detectorspb.DetectorType_AWS, Raw: []byte("AKIAEXAMPLEKEY"), Verified: true},
+	}
+}
+
+// listen accepts one connection and sends the octet counted messages it
+// receives on the returned channel.
+func listen(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { lis.Close() })
+	messages := make(chan string, 10)
+	go func() {
+		defer close(messages)
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			length, err := reader.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(length))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			msg := make([]byte, n)
+			if _, err := io.ReadFull(reader, msg); err != nil {
+				t.Error(err)
+				return
+			}
+			messages <- string(msg)
+		}
+	}()
+	return lis.Addr().String(), messages
+}
+
+func TestSink(t *testing.T) {
+	r := testResult()
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	header := fmt.Sprintf("<11>1 2022-06-01T12:00:00.000000Z host trufflehog %d finding", os.Getpid())
+
+	tests := []struct {
+		name   string
+		format string
+		fields map[string]string
+		want   string
+	}{
+		{
+			name:   "rfc5424",
+			format: FormatRFC5424,
+			want: header + ` [trufflehog@32473 commit="abc123" detector="AWS" file="config.py" finding_id="` + findings.ID(r) +
+				`" line="10" link="https://github.com/acme/api/blob/abc123/config.py#L10" redacted="AKIA********"` +
+				` repository="https://github.com/acme/api.git" severity="high" source="trufflehog - git" verified="true"]` +
+				` Found verified AWS secret in config.py:10 of https://github.com/acme/api.git`,
+		},
+		{
+			name:   "cef with field mapping",
+			format: FormatCEF,
+			fields: map[string]string{"cs1": "repository", "fname": "file", "dhost": "extra.missing"},
+			want: header + ` - CEF:0|Truffle Security|TruffleHog|dev|AWS|AWS secret found|8|rt=1654084800000 cat=verified` +
+				` cs1=https://github.com/acme/api.git cs1Label=repository fname=config.py`,
+		},
+		{
+			name:   "leef",
+			format: FormatLEEF,
+			fields: map[string]string{"url": "link", "usrName": "email"},
+			want: header + ` - LEEF:2.0|Truffle Security|TruffleHog|dev|AWS|^|devTime=1654084800000^sev=8^cat=verified` +
+				`^url=https://github.com/acme/api/blob/abc123/config.py#L10`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, messages := listen(t)
+			s, err := New(Config{Address: addr, Format: tt.format, Fields: tt.fields})
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.now = func() time.Time { return now }
+			s.hostname = "host"
+			if err := s.Send(context.Background(), r); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if got := <-messages; got != tt.want {
+				t.Errorf("message:\n got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "no address", config: Config{}},
+		{name: "unknown format", config: Config{Address: "127.0.0.1:1", Format: "gelf"}},
+		{name: "unknown network", config: Config{Address: "127.0.0.1:1", Network: "sctp"}},
+		{name: "invalid structured data name", config: Config{Address: "127.0.0.1:1", Fields: map[string]string{"secret id": "finding_id"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.config); err == nil {
+				t.Error("New() should fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package banners
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	providerShodan = "shodan"
+	providerCensys = "censys"
+)
+
+type Source struct {
+	sources.Base
+	conn   *sourcespb.ServiceBanners
+	client *http.Client
+}
+
+// Ensure the Source satisfies the interface at compile time.
+var _ sources.Source = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_SERVICE_BANNERS
+}
+
+// Init returns an initialized service banners source.
+func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.InitBase(aCtx, s.Type(), name, jobId, sourceId, verify, concurrency)
+
+	var conn sourcespb.ServiceBanners
+	if err := sources.UnmarshalConnection(connection, &conn); err != nil {
+		return err
+	}
+	if err := validateConnection(&conn); err != nil {
+		return err
+	}
+	s.conn = &conn
+
+	httpOptions, err := common.NewHttpClientOptions(nil, conn.Proxy)
+	if err != nil {
+		return err
+	}
+	// Shodan and Censys throttle searches, so requests wait out their limits.
+	s.client = common.RateLimitedHttpClient(httpOptions...)
+
+	return nil
+}
+
+// banner is a response a service on a host sent a scanner.
+type banner struct {
+	ip        string
+	port      int64
+	hostname  string
+	provider  string
+	timestamp string
+	data      string
+}
+
+// validateConnection checks that the connection has exports or networks to
+// scan, and the API keys to search its networks with.
+func validateConnection(conn *sourcespb.ServiceBanners) error {
+	var v sources.Validator
+	if len(conn.Exports) == 0 && len(conn.Networks) == 0 {
+		v.Problemf("exports or networks is required")
+	}
+	if len(conn.Networks) > 0 && conn.ShodanKey == "" && (conn.CensysId == "" || conn.CensysSecret == "") {
+		v.Problemf("searching networks requires shodan_key, or censys_id and censys_secret")
+	}
+	if (conn.CensysId == "") != (conn.CensysSecret == "") {
+		v.Problemf("censys_id and censys_secret must be given together")
+	}
+	for i, network := range conn.Networks {
+		if !validNetwork(network) {
+			v.Problemf("networks[%d] %q is not an IP address or CIDR range, such as \"192.0.2.0/24\"", i, network)
+		}
+	}
+	return v.Err()
+}
+
+// Chunks emits the banners and HTTP bodies of the exports, and of the
+// services Shodan and Censys found in the networks.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	total := len(s.conn.Exports) + len(s.conn.Networks)
+	for i, export := range s.conn.Exports {
+		if common.IsDone(ctx) {
+			return nil
+		}
+		s.SetProgressComplete(i, total, fmt.Sprintf("Export: %s", export), "")
+
+		if err := s.chunkExport(ctx, chunksChan, export); err != nil {
+			if common.IsDone(ctx) {
+				return nil
+			}
+			return errors.WrapPrefix(err, fmt.Sprintf("could not scan export %s", export), 0)
+		}
+	}
+
+	for i, network := range s.conn.Networks {
+		if common.IsDone(ctx) {
+			return nil
+		}
+		s.SetProgressComplete(len(s.conn.Exports)+i, total, fmt.Sprintf("Network: %s", network), "")
+
+		if s.conn.ShodanKey != "" {
+			if err := s.searchShodan(ctx, chunksChan, network); err != nil {
+				if common.IsDone(ctx) {
+					return nil
+				}
+				return errors.WrapPrefix(err, fmt.Sprintf("could not search Shodan for %s", network), 0)
+			}
+		}
+		if s.conn.CensysId != "" && s.conn.CensysSecret != "" {
+			if err := s.searchCensys(ctx, chunksChan, network); err != nil {
+				if common.IsDone(ctx) {
+					return nil
+				}
+				return errors.WrapPrefix(err, fmt.Sprintf("could not search Censys for %s", network), 0)
+			}
+		}
+	}
+	return nil
+}
+
+// chunkExport emits the banners of a Shodan or Censys export. Exports are
+// JSON, either a record per line or an array of them, and may be gzipped, as
+// Shodan's downloads are. Shodan records are banners and Censys records are
+// hosts.
+func (s *Source) chunkExport(ctx context.Context, chunksChan chan *sources.Chunk, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	if magic, err := r.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return errors.WrapPrefix(err, "could not decompress export", 0)
+		}
+		defer gz.Close()
+		r = bufio.NewReader(gz)
+	}
+
+	first, err := firstByte(r)
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return errors.WrapPrefix(err, "could not read export", 0)
+	}
+	dec := json.NewDecoder(r)
+	if first == '[' {
+		// Consume the opening bracket, so the records are decoded one at a
+		// time.
+		if _, err := dec.Token(); err != nil {
+			return errors.WrapPrefix(err, "could not decode export", 0)
+		}
+	}
+	for dec.More() {
+		var record json.RawMessage
+		if err := dec.Decode(&record); err != nil {
+			return errors.WrapPrefix(err, "could not decode export", 0)
+		}
+		if err := s.chunkRecord(ctx, chunksChan, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// firstByte returns the first byte of r that isn't whitespace, without
+// consuming it.
+func firstByte(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			_, _ = r.ReadByte()
+		default:
+			return b[0], nil
+		}
+	}
+}
+
+// chunkRecord emits the banners of a Shodan banner or Censys host record.
+func (s *Source) chunkRecord(ctx context.Context, chunksChan chan *sources.Chunk, record json.RawMessage) error {
+	var probe struct {
+		Services json.RawMessage `json:"services"`
+	}
+	if err := json.Unmarshal(record, &probe); err != nil {
+		// Records that aren't objects aren't banners.
+		return nil
+	}
+
+	var banners []banner
+	if probe.Services != nil {
+		var host censysHost
+		if err := json.Unmarshal(record, &host); err != nil {
+			return errors.WrapPrefix(err, "could not decode Censys host", 0)
+		}
+		banners = host.banners()
+	} else {
+		var match shodanBanner
+		if err := json.Unmarshal(record, &match); err != nil {
+			return errors.WrapPrefix(err, "could not decode Shodan banner", 0)
+		}
+		banners = []banner{match.banner()}
+	}
+	for _, b := range banners {
+		if err := s.sendBanner(ctx, chunksChan, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendBanner emits a banner, unless it's empty.
+func (s *Source) sendBanner(ctx context.Context, chunksChan chan *sources.Chunk, b banner) error {
+	if b.data == "" {
+		return nil
+	}
+	return s.Send(ctx, chunksChan, []byte(b.data), &source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_ServiceBanner{
+			ServiceBanner: &source_metadatapb.ServiceBanner{
+				Ip:        b.ip,
+				Port:      b.port,
+				Hostname:  sanitizer.UTF8(b.hostname),
+				Provider:  b.provider,
+				Timestamp: b.timestamp,
+				Link:      hostLink(b.provider, b.ip),
+			},
+		},
+	})
+}
+
+// hostLink returns the link to a host's page on the provider's site.
+func hostLink(provider, ip string) string {
+	switch provider {
+	case providerShodan:
+		return "https://www.shodan.io/host/" + ip
+	case providerCensys:
+		return "https://search.censys.io/hosts/" + ip
+	}
+	return ""
+}
+
+// validNetwork reports whether network is an IP address or CIDR range.
+func validNetwork(network string) bool {
+	if net.ParseIP(network) != nil {
+		return true
+	}
+	_, _, err := net.ParseCIDR(network)
+	return err == nil
+}
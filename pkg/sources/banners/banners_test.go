@@ -0,0 +1,203 @@
+package banners
+
+import (
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	shodanExport = `{"ip_str": "192.0.2.1", "port": 80, "hostnames": ["www.example.com"], "timestamp": "2022-08-01T00:00:00.000000", "data": "HTTP/1.1 200 OK\r\nX-Api-Key: shodan-key\r\n", "http": {"html": "<script>token='example'</script>"}}
+{"ip_str": "192.0.2.2", "port": 22, "hostnames": [], "timestamp": "2022-08-02T00:00:00.000000", "data": ""}
+`
+	censysExport = `[{"ip": "192.0.2.3", "dns": {"reverse_dns": {"names": ["host.example.net"]}}, "services": [
+	{"port": 443, "banner": "HTTP/1.1 200 OK", "observed_at": "2022-08-03T00:00:00Z", "http": {"response": {"body": "password=hunter2"}}},
+	{"port": 6379, "banner": "redis_version:6.2", "observed_at": "2022-08-04T00:00:00Z"}
+]}]`
+)
+
+func TestSource_ChunksExports(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+
+	dir := t.TempDir()
+	shodan := filepath.Join(dir, "shodan.json.gz")
+	f, err := os.Create(shodan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	if _, err := gz.Write([]byte(shodanExport)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	censys := filepath.Join(dir, "censys.json")
+	if err := os.WriteFile(censys, []byte(censysExport), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, gotMetadata := chunks(ctx, t, &sourcespb.ServiceBanners{Exports: []string{shodan, censys}})
+	want := []string{
+		"HTTP/1.1 200 OK\r\nX-Api-Key: shodan-key\r\n\n<script>token='example'</script>",
+		"HTTP/1.1 200 OK\npassword=hunter2",
+		"redis_version:6.2",
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("Chunks() data diff: (-got +want)\n%s", diff)
+	}
+	wantMetadata := []*source_metadatapb.ServiceBanner{
+		{
+			Ip:        "192.0.2.1",
+			Port:      80,
+			Hostname:  "www.example.com",
+			Provider:  "shodan",
+			Timestamp: "2022-08-01T00:00:00.000000",
+			Link:      "https://www.shodan.io/host/192.0.2.1",
+		},
+		{
+			Ip:        "192.0.2.3",
+			Port:      443,
+			Hostname:  "host.example.net",
+			Provider:  "censys",
+			Timestamp: "2022-08-03T00:00:00Z",
+			Link:      "https://search.censys.io/hosts/192.0.2.3",
+		},
+		{
+			Ip:        "192.0.2.3",
+			Port:      6379,
+			Hostname:  "host.example.net",
+			Provider:  "censys",
+			Timestamp: "2022-08-04T00:00:00Z",
+			Link:      "https://search.censys.io/hosts/192.0.2.3",
+		},
+	}
+	if diff := pretty.Compare(gotMetadata, wantMetadata); diff != "" {
+		t.Errorf("Chunks() metadata diff: (-got +want)\n%s", diff)
+	}
+}
+
+func TestSource_ChunksNetworks(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/shodan/host/search":
+			query := r.URL.Query()
+			if query.Get("key") != "shodan-key" || query.Get("query") != "net:192.0.2.0/24" {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"error": "Invalid API key"}`))
+				return
+			}
+			if query.Get("page") == "1" {
+				_, _ = w.Write([]byte(`{"total": 101, "matches": [{"ip_str": "192.0.2.1", "port": 80, "data": "page one"}]}`))
+			} else {
+				_, _ = w.Write([]byte(`{"total": 101, "matches": [{"ip_str": "192.0.2.2", "port": 80, "data": "page two"}]}`))
+			}
+		case "/api/v2/hosts/search":
+			if id, secret, ok := r.BasicAuth(); !ok || id != "censys-id" || secret != "censys-secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.URL.Query().Get("cursor") == "" {
+				_, _ = w.Write([]byte(`{"result": {"hits": [{"ip": "192.0.2.3"}], "links": {"next": "next-page"}}}`))
+			} else {
+				_, _ = w.Write([]byte(`{"result": {"hits": [], "links": {"next": ""}}}`))
+			}
+		case "/api/v2/hosts/192.0.2.3":
+			_, _ = w.Write([]byte(`{"result": {"ip": "192.0.2.3", "services": [{"port": 21, "banner": "220 FTP"}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	defer func(shodan, censys string) { shodanURL, censysURL = shodan, censys }(shodanURL, censysURL)
+	shodanURL, censysURL = server.URL, server.URL
+
+	got, _ := chunks(ctx, t, &sourcespb.ServiceBanners{
+		Networks:     []string{"192.0.2.0/24"},
+		ShodanKey:    "shodan-key",
+		CensysId:     "censys-id",
+		CensysSecret: "censys-secret",
+	})
+	want := []string{"page one", "page two", "220 FTP"}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("Chunks() data diff: (-got +want)\n%s", diff)
+	}
+}
+
+func TestSource_Init(t *testing.T) {
+	tests := map[string]struct {
+		conn    *sourcespb.ServiceBanners
+		wantErr bool
+	}{
+		"nothing to scan": {
+			conn:    &sourcespb.ServiceBanners{},
+			wantErr: true,
+		},
+		"network without keys": {
+			conn:    &sourcespb.ServiceBanners{Networks: []string{"192.0.2.0/24"}},
+			wantErr: true,
+		},
+		"invalid network": {
+			conn:    &sourcespb.ServiceBanners{Networks: []string{"example.com"}, ShodanKey: "key"},
+			wantErr: true,
+		},
+		"address": {
+			conn: &sourcespb.ServiceBanners{Networks: []string{"192.0.2.1"}, CensysId: "id", CensysSecret: "secret"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			conn, err := anypb.New(tt.conn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := Source{}
+			if err := s.Init(context.Background(), "test banners", 0, 0, false, conn, 1); (err != nil) != tt.wantErr {
+				t.Errorf("Init() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func chunks(ctx context.Context, t *testing.T, connection *sourcespb.ServiceBanners) ([]string, []*source_metadatapb.ServiceBanner) {
+	t.Helper()
+	conn, err := anypb.New(connection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := Source{}
+	if err := s.Init(ctx, "test banners", 0, 0, false, conn, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	chunksChan := make(chan *sources.Chunk, 10)
+	if err := s.Chunks(ctx, chunksChan); err != nil {
+		t.Fatal(err)
+	}
+	close(chunksChan)
+
+	var got []string
+	var gotMetadata []*source_metadatapb.ServiceBanner
+	for chunk := range chunksChan {
+		got = append(got, string(chunk.Data))
+		gotMetadata = append(gotMetadata, chunk.SourceMetadata.GetServiceBanner())
+	}
+	return got, gotMetadata
+}
@@ -0,0 +1,136 @@
+package banners
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// censysURL is the Censys Search API, which tests point at a fake one.
+var censysURL = "https://search.censys.io"
+
+// censysHost is a host from the Censys hosts API or a Censys export.
+// https://search.censys.io/api
+type censysHost struct {
+	IP  string `json:"ip"`
+	DNS struct {
+		Names      []string `json:"names"`
+		ReverseDNS struct {
+			Names []string `json:"names"`
+		} `json:"reverse_dns"`
+	} `json:"dns"`
+	Services []struct {
+		Port       int64  `json:"port"`
+		Banner     string `json:"banner"`
+		ObservedAt string `json:"observed_at"`
+		HTTP       *struct {
+			Response struct {
+				Body string `json:"body"`
+			} `json:"response"`
+		} `json:"http"`
+	} `json:"services"`
+}
+
+// banners returns the banner of each of the host's services, with the body of
+// its HTTP response, if any.
+func (h *censysHost) banners() []banner {
+	var hostname string
+	if len(h.DNS.Names) > 0 {
+		hostname = h.DNS.Names[0]
+	} else if len(h.DNS.ReverseDNS.Names) > 0 {
+		hostname = h.DNS.ReverseDNS.Names[0]
+	}
+
+	banners := make([]banner, 0, len(h.Services))
+	for _, service := range h.Services {
+		data := service.Banner
+		if service.HTTP != nil && service.HTTP.Response.Body != "" {
+			data += "\n" + service.HTTP.Response.Body
+		}
+		banners = append(banners, banner{
+			ip:        h.IP,
+			port:      service.Port,
+			hostname:  hostname,
+			provider:  providerCensys,
+			timestamp: service.ObservedAt,
+			data:      data,
+		})
+	}
+	return banners
+}
+
+// searchCensys emits the banners of the services Censys found on the hosts in
+// a network. Search results don't include banners, so each host is looked up.
+func (s *Source) searchCensys(ctx context.Context, chunksChan chan *sources.Chunk, network string) error {
+	query := url.Values{
+		"q":        {"ip: " + network},
+		"per_page": {"100"},
+	}
+	for {
+		var result struct {
+			Hits []struct {
+				IP string `json:"ip"`
+			} `json:"hits"`
+			Links struct {
+				Next string `json:"next"`
+			} `json:"links"`
+		}
+		if err := s.censysGet(ctx, "/api/v2/hosts/search?"+query.Encode(), &result); err != nil {
+			return err
+		}
+
+		for _, hit := range result.Hits {
+			var host censysHost
+			if err := s.censysGet(ctx, "/api/v2/hosts/"+url.PathEscape(hit.IP), &host); err != nil {
+				return err
+			}
+			for _, b := range host.banners() {
+				if err := s.sendBanner(ctx, chunksChan, b); err != nil {
+					return err
+				}
+			}
+		}
+		if len(result.Hits) == 0 || result.Links.Next == "" {
+			return nil
+		}
+		query.Set("cursor", result.Links.Next)
+	}
+}
+
+// censysGet decodes the result of a Censys API request into v.
+func (s *Source) censysGet(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, censysURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.conn.CensysId, s.conn.CensysSecret)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Result json.RawMessage `json:"result"`
+		Error  string          `json:"error"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != http.StatusOK {
+		if body.Error != "" {
+			return errors.Errorf("unexpected status from Censys: %s: %s", resp.Status, body.Error)
+		}
+		return errors.Errorf("unexpected status from Censys: %s", resp.Status)
+	}
+	if err != nil {
+		return errors.WrapPrefix(err, "could not decode Censys response", 0)
+	}
+	if err := json.Unmarshal(body.Result, v); err != nil {
+		return errors.WrapPrefix(err, "could not decode Censys response", 0)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package banners
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// shodanURL is the Shodan API, which tests point at a fake one.
+var shodanURL = "https://api.shodan.io"
+
+// shodanPageSize is how many banners the Shodan search API returns per page.
+const shodanPageSize = 100
+
+// shodanBanner is a banner from the Shodan search API or a Shodan download.
+// https://datapedia.shodan.io
+type shodanBanner struct {
+	IP        string   `json:"ip_str"`
+	Port      int64    `json:"port"`
+	Hostnames []string `json:"hostnames"`
+	Timestamp string   `json:"timestamp"`
+	Data      string   `json:"data"`
+	HTTP      *struct {
+		HTML string `json:"html"`
+	} `json:"http"`
+}
+
+// banner returns the banner with the body of its HTTP response, if any.
+func (b *shodanBanner) banner() banner {
+	data := b.Data
+	if b.HTTP != nil && b.HTTP.HTML != "" {
+		data += "\n" + b.HTTP.HTML
+	}
+	var hostname string
+	if len(b.Hostnames) > 0 {
+		hostname = b.Hostnames[0]
+	}
+	return banner{
+		ip:        b.IP,
+		port:      b.Port,
+		hostname:  hostname,
+		provider:  providerShodan,
+		timestamp: b.Timestamp,
+		data:      data,
+	}
+}
+
+// searchShodan emits the banners Shodan has of the services in a network.
+// Every page of results after the first uses a query credit.
+// https://developer.shodan.io/api
+func (s *Source) searchShodan(ctx context.Context, chunksChan chan *sources.Chunk, network string) error {
+	for page := 1; ; page++ {
+		query := url.Values{
+			"key":   {s.conn.ShodanKey},
+			"query": {"net:" + network},
+			"page":  {fmt.Sprint(page)},
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, shodanURL+"/shodan/host/search?"+query.Encode(), nil)
+		if err != nil {
+			return withoutURL(err)
+		}
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return withoutURL(err)
+		}
+
+		var result struct {
+			Matches []shodanBanner `json:"matches"`
+			Total   int            `json:"total"`
+			Error   string         `json:"error"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			if result.Error != "" {
+				return errors.Errorf("unexpected status from Shodan: %s: %s", resp.Status, result.Error)
+			}
+			return errors.Errorf("unexpected status from Shodan: %s", resp.Status)
+		}
+		if err != nil {
+			return errors.WrapPrefix(err, "could not decode Shodan response", 0)
+		}
+
+		for _, match := range result.Matches {
+			if err := s.sendBanner(ctx, chunksChan, match.banner()); err != nil {
+				return err
+			}
+		}
+		if len(result.Matches) == 0 || page*shodanPageSize >= result.Total {
+			return nil
+		}
+	}
+}
+
+// withoutURL strips the URL from the errors of HTTP clients, since the
+// Shodan API takes its key in the query.
+func withoutURL(err error) error {
+	if uErr, ok := err.(*url.Error); ok {
+		return uErr.Err
+	}
+	return err
+}
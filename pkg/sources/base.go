@@ -0,0 +1,151 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-errors/errors"
+	"github.com/go-logr/logr"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+// Base holds what a source is initialized with, and implements the methods
+// of Source that report it. Sources embed it and initialize it from their
+// Init:
+//
+//	type Source struct {
+//		sources.Base
+//		conn *sourcespb.Example
+//	}
+//
+//	func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+//		s.InitBase(aCtx, s.Type(), name, jobId, sourceId, verify, concurrency)
+//		var conn sourcespb.Example
+//		if err := sources.UnmarshalConnection(connection, &conn); err != nil {
+//			return err
+//		}
+//		s.conn = &conn
+//		return nil
+//	}
+type Base struct {
+	Progress
+	sourceType  sourcespb.SourceType
+	name        string
+	sourceID    int64
+	jobID       int64
+	verify      bool
+	concurrency int
+	log         logr.Logger
+}
+
+// InitBase initializes the base of a source of the given type. The source
+// logs to the logger in aCtx, with its name.
+func (b *Base) InitBase(aCtx context.Context, sourceType sourcespb.SourceType, name string, jobID, sourceID int64, verify bool, concurrency int) {
+	b.sourceType = sourceType
+	b.name = name
+	b.sourceID = sourceID
+	b.jobID = jobID
+	b.verify = verify
+	b.concurrency = concurrency
+	b.log = log.FromContext(aCtx).WithValues("name", name)
+}
+
+func (b *Base) SourceID() int64 {
+	return b.sourceID
+}
+
+func (b *Base) JobID() int64 {
+	return b.jobID
+}
+
+// Name returns the name the source was initialized with.
+func (b *Base) Name() string {
+	return b.name
+}
+
+// Verify reports whether the secrets found in the source's chunks are
+// verified.
+func (b *Base) Verify() bool {
+	return b.verify
+}
+
+// Concurrency returns how many workers the source may run at once.
+func (b *Base) Concurrency() int {
+	return b.concurrency
+}
+
+// Log returns the source's logger.
+func (b *Base) Log() logr.Logger {
+	return b.log
+}
+
+// ChunkSkel returns a chunk from the source without data or metadata, for
+// handlers to copy.
+func (b *Base) ChunkSkel() *Chunk {
+	return &Chunk{
+		SourceType: b.sourceType,
+		SourceName: b.name,
+		SourceID:   b.sourceID,
+		Verify:     b.verify,
+	}
+}
+
+// NewChunk returns a chunk from the source of data found where metadata says.
+func (b *Base) NewChunk(data []byte, metadata *source_metadatapb.MetaData) *Chunk {
+	chunk := b.ChunkSkel()
+	chunk.Data = data
+	chunk.SourceMetadata = metadata
+	return chunk
+}
+
+// Send sends a chunk of data found where metadata says to chunksChan. It
+// returns ctx's error if ctx is done first.
+func (b *Base) Send(ctx context.Context, chunksChan chan *Chunk, data []byte, metadata *source_metadatapb.MetaData) error {
+	return SendChunk(ctx, chunksChan, b.NewChunk(data, metadata))
+}
+
+// Enumerate calls fn with each of the targets of a scan, such as its domains
+// or buckets, reporting the source's progress through them as "label:
+// target". It stops at the first error fn returns, which it returns unless
+// ctx is done, in which case the scan was cancelled rather than failed.
+func (b *Base) Enumerate(ctx context.Context, label string, targets []string, fn func(target string) error) error {
+	for i, target := range targets {
+		if ctx.Err() != nil {
+			return nil
+		}
+		b.SetProgressComplete(i, len(targets), fmt.Sprintf("%s: %s", label, target), "")
+
+		if err := fn(target); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// SendChunk sends chunk to chunksChan. It returns ctx's error if ctx is done
+// first.
+func SendChunk(ctx context.Context, chunksChan chan *Chunk, chunk *Chunk) error {
+	select {
+	case chunksChan <- chunk:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// UnmarshalConnection unmarshals the connection a source is initialized with
+// into conn.
+func UnmarshalConnection(connection *anypb.Any, conn proto.Message) error {
+	if err := anypb.UnmarshalTo(connection, conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	return nil
+}
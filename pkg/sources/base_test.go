@@ -0,0 +1,128 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+func TestBase(t *testing.T) {
+	var b Base
+	b.InitBase(context.Background(), sourcespb.SourceType_SOURCE_TYPE_WAYBACK, "test", 1, 2, true, 4)
+	if b.SourceID() != 2 || b.JobID() != 1 || b.Name() != "test" || !b.Verify() || b.Concurrency() != 4 {
+		t.Errorf("InitBase() didn't set the name, IDs, verify and concurrency it was given")
+	}
+
+	metadata := &source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_Wayback{Wayback: &source_metadatapb.Wayback{Url: "https://example.com/app.js"}},
+	}
+	chunksChan := make(chan *Chunk, 1)
+	if err := b.Send(context.Background(), chunksChan, []byte("data"), metadata); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	want := &Chunk{
+		SourceType:     sourcespb.SourceType_SOURCE_TYPE_WAYBACK,
+		SourceName:     "test",
+		SourceID:       2,
+		SourceMetadata: metadata,
+		Data:           []byte("data"),
+		Verify:         true,
+	}
+	if diff := pretty.Compare(<-chunksChan, want); diff != "" {
+		t.Errorf("Send() chunk diff: (-got +want)\n%s", diff)
+	}
+
+	// Sending stops when the context is done, rather than blocking.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := b.Send(ctx, make(chan *Chunk), nil, metadata); !errors.Is(err, context.Canceled) {
+		t.Errorf("Send() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestBase_Enumerate(t *testing.T) {
+	var b Base
+	var got []string
+	err := b.Enumerate(context.Background(), "Domain", []string{"a", "b", "c"}, func(target string) error {
+		got = append(got, target)
+		if target == "b" {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "failed" {
+		t.Errorf("Enumerate() error = %v, want failed", err)
+	}
+	if diff := pretty.Compare(got, []string{"a", "b"}); diff != "" {
+		t.Errorf("Enumerate() targets diff: (-got +want)\n%s", diff)
+	}
+	if _, message := b.Status(); message != "Domain: b" {
+		t.Errorf("Status() message = %q, want %q", message, "Domain: b")
+	}
+
+	// An error caused by the scan being cancelled isn't a failure.
+	ctx, cancel := context.WithCancel(context.Background())
+	err = b.Enumerate(ctx, "Domain", []string{"a", "b"}, func(string) error {
+		cancel()
+		return ctx.Err()
+	})
+	if err != nil {
+		t.Errorf("Enumerate() error = %v, want nil", err)
+	}
+}
+
+func TestPool(t *testing.T) {
+	pool := NewPool(2)
+	var running, peak int32
+	for i := 0; i < 6; i++ {
+		i := i
+		err := pool.Go(context.Background(), func() error {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			if i == 3 {
+				return errors.New("failed")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Go() error = %v", err)
+		}
+	}
+	if err := pool.Wait(); err == nil || err.Error() != "failed" {
+		t.Errorf("Wait() error = %v, want failed", err)
+	}
+	if peak != 2 {
+		t.Errorf("ran %d at once, want 2", peak)
+	}
+
+	// A full pool stops waiting for room when the context is done.
+	pool = NewPool(1)
+	block := make(chan struct{})
+	_ = pool.Go(context.Background(), func() error {
+		<-block
+		return nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := pool.Go(ctx, func() error { return nil }); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Go() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	close(block)
+	if err := pool.Wait(); err != nil {
+		t.Errorf("Wait() error = %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// txtNames are the names under a zone, besides the zone itself, that TXT
+// records are commonly published at when the zone can't be transferred.
+var txtNames = []string{
+	"_dmarc",
+	"_domainkey",
+	"default._domainkey",
+	"google._domainkey",
+	"selector1._domainkey",
+	"selector2._domainkey",
+	"k1._domainkey",
+	"_spf",
+	"_amazonses",
+	"_acme-challenge",
+	"_github-challenge",
+	"_gitlab-pages-verification-code",
+}
+
+type Source struct {
+	sources.Base
+	conn     *sourcespb.DNS
+	resolver *net.Resolver
+}
+
+// Ensure the Source satisfies the interface at compile time.
+var _ sources.Source = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_DNS
+}
+
+// Init returns an initialized DNS source.
+func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.InitBase(aCtx, s.Type(), name, jobId, sourceId, verify, concurrency)
+
+	var conn sourcespb.DNS
+	if err := sources.UnmarshalConnection(connection, &conn); err != nil {
+		return err
+	}
+	if err := validateConnection(&conn); err != nil {
+		return err
+	}
+	s.conn = &conn
+
+	s.resolver = net.DefaultResolver
+	if conn.Nameserver != "" {
+		// Lookups go to the nameserver rather than the system's resolver.
+		nameserver := withPort(conn.Nameserver)
+		s.resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, nameserver)
+			},
+		}
+	}
+
+	return nil
+}
+
+// record is a TXT or SPF record.
+type record struct {
+	name       string
+	typ        string
+	nameserver string
+	text       string
+}
+
+// validateConnection checks that the connection has zones to scan, and that
+// its nameserver is a host, optionally with a port.
+func validateConnection(conn *sourcespb.DNS) error {
+	var v sources.Validator
+	v.Require("zones", len(conn.Zones) > 0)
+	for i, zone := range conn.Zones {
+		v.Hostname(fmt.Sprintf("zones[%d]", i), zone)
+	}
+	if conn.Nameserver != "" {
+		host := strings.Trim(conn.Nameserver, "[]")
+		if h, port, err := net.SplitHostPort(conn.Nameserver); err == nil {
+			host = h
+			if _, err := net.LookupPort("udp", port); err != nil {
+				v.Problemf("nameserver %q has an invalid port %q", conn.Nameserver, port)
+			}
+		}
+		if net.ParseIP(host) == nil {
+			v.Hostname("nameserver", host)
+		}
+	}
+	return v.Err()
+}
+
+// Chunks emits the TXT and SPF records of each zone. All of a zone's records
+// are found by transferring it, when that's enabled and one of its
+// nameservers allows it. Otherwise, the records at the zone and the names TXT
+// records are commonly published at are looked up.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	return s.Enumerate(ctx, "Zone", s.conn.Zones, func(zone string) error {
+		zone = strings.TrimSuffix(zone, ".")
+		var records []record
+		if s.conn.ZoneTransfer {
+			records = s.transferZone(ctx, zone)
+		}
+		if records == nil {
+			var err error
+			records, err = s.lookupZone(ctx, zone)
+			if err != nil {
+				return errors.WrapPrefix(err, fmt.Sprintf("could not look up TXT records of %s", zone), 0)
+			}
+		}
+
+		for _, r := range records {
+			if err := s.sendRecord(ctx, chunksChan, zone, r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// transferZone returns the TXT and SPF records of a zone from the first of
+// its nameservers that allows transferring it, or nil if none do. Most
+// nameservers refuse transfers to anyone but their secondaries.
+func (s *Source) transferZone(ctx context.Context, zone string) []record {
+	nameservers := []string{s.conn.Nameserver}
+	if s.conn.Nameserver == "" {
+		nss, err := s.resolver.LookupNS(ctx, zone)
+		if err != nil {
+			s.Log().V(1).Info("could not look up nameservers", "zone", zone, "error", err.Error())
+			return nil
+		}
+		nameservers = nameservers[:0]
+		for _, ns := range nss {
+			nameservers = append(nameservers, strings.TrimSuffix(ns.Host, "."))
+		}
+	}
+
+	for _, nameserver := range nameservers {
+		records, err := transfer(ctx, zone, withPort(nameserver))
+		if err != nil {
+			s.Log().V(1).Info("zone transfer failed", "zone", zone, "nameserver", nameserver, "error", err.Error())
+			continue
+		}
+		for i := range records {
+			records[i].nameserver = nameserver
+		}
+		s.Log().V(1).Info("transferred zone", "zone", zone, "nameserver", nameserver, "records", len(records))
+		// A zone without TXT records was still transferred.
+		if records == nil {
+			records = []record{}
+		}
+		return records
+	}
+	return nil
+}
+
+// lookupZone returns the TXT records at the zone and at the names under it
+// TXT records are commonly published at. SPF policies are TXT records.
+func (s *Source) lookupZone(ctx context.Context, zone string) ([]record, error) {
+	var records []record
+	names := make([]string, 0, len(txtNames)+1)
+	names = append(names, zone)
+	for _, name := range txtNames {
+		names = append(names, name+"."+zone)
+	}
+	for i, name := range names {
+		txts, err := s.resolver.LookupTXT(ctx, name)
+		if err != nil {
+			var dnsErr *net.DNSError
+			if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+				continue
+			}
+			// Only a failure to look up the zone itself fails the scan.
+			if i == 0 {
+				return nil, err
+			}
+			s.Log().V(2).Info("could not look up TXT records", "name", name, "error", err.Error())
+			continue
+		}
+		for _, txt := range txts {
+			records = append(records, record{name: name, typ: recordType(txt), text: txt})
+		}
+	}
+	return records, nil
+}
+
+// sendRecord emits the text of a record.
+func (s *Source) sendRecord(ctx context.Context, chunksChan chan *sources.Chunk, zone string, r record) error {
+	if r.text == "" {
+		return nil
+	}
+	return s.Send(ctx, chunksChan, []byte(r.text), &source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_Dns{
+			Dns: &source_metadatapb.DNS{
+				Name:       sanitizer.UTF8(r.name),
+				Type:       r.typ,
+				Zone:       sanitizer.UTF8(zone),
+				Nameserver: sanitizer.UTF8(r.nameserver),
+			},
+		},
+	})
+}
+
+// recordType returns the type of a looked up TXT record, which is SPF for
+// SPF policies.
+func recordType(txt string) string {
+	if strings.HasPrefix(strings.ToLower(txt), "v=spf1") {
+		return "SPF"
+	}
+	return "TXT"
+}
+
+// withPort returns the address of a nameserver, given by host or host:port.
+func withPort(nameserver string) string {
+	if _, _, err := net.SplitHostPort(nameserver); err == nil {
+		return nameserver
+	}
+	return net.JoinHostPort(strings.Trim(nameserver, "[]"), "53")
+}
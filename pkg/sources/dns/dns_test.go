@@ -0,0 +1,248 @@
+package dns
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"golang.org/x/net/dns/dnsmessage"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestSource_Chunks(t *testing.T) {
+	tests := []struct {
+		name         string
+		zoneTransfer bool
+		refuse       bool
+		want         []*source_metadatapb.DNS
+		wantData     []string
+	}{
+		{
+			name:         "zone transfer",
+			zoneTransfer: true,
+			want: []*source_metadatapb.DNS{
+				{Name: "example.com", Type: "SPF", Zone: "example.com"},
+				{Name: "api.example.com", Type: "TXT", Zone: "example.com"},
+				{Name: "example.com", Type: "SPF", Zone: "example.com"},
+			},
+			wantData: []string{"v=spf1 include:_spf.example.com ~all", "api_key=example", "v=spf1 -all"},
+		},
+		{
+			name: "lookup",
+			want: []*source_metadatapb.DNS{
+				{Name: "example.com", Type: "SPF", Zone: "example.com"},
+				{Name: "_dmarc.example.com", Type: "TXT", Zone: "example.com"},
+			},
+			wantData: []string{"v=spf1 include:_spf.example.com ~all", "v=DMARC1; p=none"},
+		},
+		{
+			name:         "zone transfer refused",
+			zoneTransfer: true,
+			refuse:       true,
+			want: []*source_metadatapb.DNS{
+				{Name: "example.com", Type: "SPF", Zone: "example.com"},
+				{Name: "_dmarc.example.com", Type: "TXT", Zone: "example.com"},
+			},
+			wantData: []string{"v=spf1 include:_spf.example.com ~all", "v=DMARC1; p=none"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+			defer cancel()
+
+			addr := nameserver(t, tt.refuse)
+			// Records are only attributed to the nameserver they were
+			// transferred from.
+			if tt.zoneTransfer && !tt.refuse {
+				for _, want := range tt.want {
+					want.Nameserver = addr
+				}
+			}
+
+			conn, err := anypb.New(&sourcespb.DNS{
+				Zones:        []string{"example.com."},
+				ZoneTransfer: tt.zoneTransfer,
+				Nameserver:   addr,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := Source{}
+			if err := s.Init(ctx, "test dns", 0, 0, false, conn, 1); err != nil {
+				t.Fatal(err)
+			}
+			chunksChan := make(chan *sources.Chunk, 10)
+			if err := s.Chunks(ctx, chunksChan); err != nil {
+				t.Fatal(err)
+			}
+			close(chunksChan)
+
+			var got []*source_metadatapb.DNS
+			var gotData []string
+			for chunk := range chunksChan {
+				got = append(got, chunk.SourceMetadata.GetDns())
+				gotData = append(gotData, string(chunk.Data))
+			}
+			if diff := pretty.Compare(gotData, tt.wantData); diff != "" {
+				t.Errorf("Chunks() data diff: (-got +want)\n%s", diff)
+			}
+			if diff := pretty.Compare(got, tt.want); diff != "" {
+				t.Errorf("Chunks() metadata diff: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+// nameserver serves example.com on a local port, answering TXT queries over
+// UDP and transferring the zone over TCP unless refuse is set. It returns
+// the address of the server.
+func nameserver(t *testing.T, refuse bool) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	pc, err := net.ListenPacket("udp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+
+	txts := map[string]string{
+		"example.com.":        "v=spf1 include:_spf.example.com ~all",
+		"_dmarc.example.com.": "v=DMARC1; p=none",
+	}
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, from, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var query dnsmessage.Message
+			if err := query.Unpack(buf[:n]); err != nil || len(query.Questions) != 1 {
+				continue
+			}
+			q := query.Questions[0]
+			resp := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: query.ID, Response: true, Authoritative: true},
+				Questions: query.Questions,
+			}
+			if txt, ok := txts[q.Name.String()]; ok && q.Type == dnsmessage.TypeTXT {
+				resp.Answers = []dnsmessage.Resource{txtRecord(q.Name.String(), txt)}
+			} else if !ok {
+				resp.RCode = dnsmessage.RCodeNameError
+			}
+			msg, err := resp.Pack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			_, _ = pc.WriteTo(msg, from)
+		}
+	}()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			var length [2]byte
+			if _, err := io.ReadFull(conn, length[:]); err != nil {
+				conn.Close()
+				continue
+			}
+			buf := make([]byte, binary.BigEndian.Uint16(length[:]))
+			if _, err := io.ReadFull(conn, buf); err != nil {
+				conn.Close()
+				continue
+			}
+			var query dnsmessage.Message
+			if err := query.Unpack(buf); err != nil {
+				conn.Close()
+				continue
+			}
+
+			header := dnsmessage.Header{ID: query.ID, Response: true, Authoritative: true}
+			soa := dnsmessage.Resource{
+				Header: dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName("example.com."), Class: dnsmessage.ClassINET},
+				Body: &dnsmessage.SOAResource{
+					NS:   dnsmessage.MustNewName("ns1.example.com."),
+					MBox: dnsmessage.MustNewName("hostmaster.example.com."),
+				},
+			}
+			messages := []dnsmessage.Message{
+				{
+					Header:    header,
+					Questions: query.Questions,
+					Answers: []dnsmessage.Resource{
+						soa,
+						txtRecord("example.com.", "v=spf1 include:_spf.example.com ~all"),
+						{
+							Header: dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName("www.example.com."), Class: dnsmessage.ClassINET},
+							Body:   &dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}},
+						},
+						txtRecord("api.example.com.", "api_key=", "example"),
+					},
+				},
+				{
+					Header: header,
+					Answers: []dnsmessage.Resource{
+						{
+							Header: dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName("example.com."), Class: dnsmessage.ClassINET},
+							Body:   &dnsmessage.UnknownResource{Type: typeSPF, Data: append([]byte{11}, "v=spf1 -all"...)},
+						},
+						soa,
+					},
+				},
+			}
+			if refuse {
+				header.RCode = dnsmessage.RCodeRefused
+				messages = []dnsmessage.Message{{Header: header, Questions: query.Questions}}
+			}
+			for _, m := range messages {
+				msg, err := m.AppendPack(make([]byte, 2))
+				if err != nil {
+					t.Error(err)
+					break
+				}
+				binary.BigEndian.PutUint16(msg, uint16(len(msg)-2))
+				if _, err := conn.Write(msg); err != nil {
+					break
+				}
+			}
+			conn.Close()
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func txtRecord(name string, txt ...string) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName(name), Class: dnsmessage.ClassINET},
+		Body:   &dnsmessage.TXTResource{TXT: txt},
+	}
+}
+
+func TestCharacterStrings(t *testing.T) {
+	data := append(append([]byte{3}, "key"...), append([]byte{5}, "=1234"...)...)
+	if got := characterStrings(data); got != "key=1234" {
+		t.Errorf("characterStrings() = %q, want %q", got, "key=1234")
+	}
+	// A length past the end of the data is truncated.
+	if got := characterStrings([]byte{9, 'a', 'b'}); got != "ab" {
+		t.Errorf("characterStrings() = %q, want %q", got, "ab")
+	}
+}
@@ -0,0 +1,147 @@
+package dns
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// transferTimeout is how long a zone transfer may take.
+const transferTimeout = 30 * time.Second
+
+// typeSPF is the obsolete SPF record type, which some zones still publish
+// alongside their SPF TXT records.
+const typeSPF dnsmessage.Type = 99
+
+// transfer returns the TXT and SPF records of a zone, transferred from the
+// nameserver at addr. The transfer is a stream of messages over TCP, which
+// starts and ends with the zone's SOA record.
+// https://www.rfc-editor.org/rfc/rfc5936
+func transfer(ctx context.Context, zone, addr string) ([]record, error) {
+	name, err := dnsmessage.NewName(zone + ".")
+	if err != nil {
+		return nil, err
+	}
+	query := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: uint16(rand.Intn(1 << 16))},
+		Questions: []dnsmessage.Question{
+			{Name: name, Type: dnsmessage.TypeAXFR, Class: dnsmessage.ClassINET},
+		},
+	}
+	// Messages over TCP are prefixed by their length.
+	msg, err := query.AppendPack(make([]byte, 2, 514))
+	if err != nil {
+		return nil, err
+	}
+	binary.BigEndian.PutUint16(msg, uint16(len(msg)-2))
+
+	ctx, cancel := context.WithTimeout(ctx, transferTimeout)
+	defer cancel()
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write(msg); err != nil {
+		return nil, err
+	}
+
+	var records []record
+	soas := 0
+	for soas < 2 {
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return nil, err
+		}
+		buf := make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			return nil, err
+		}
+
+		var p dnsmessage.Parser
+		header, err := p.Start(buf)
+		if err != nil {
+			return nil, err
+		}
+		if header.ID != query.Header.ID {
+			return nil, errors.New("response doesn't match the transfer query")
+		}
+		if header.RCode != dnsmessage.RCodeSuccess {
+			return nil, errors.Errorf("transfer refused: %s", header.RCode)
+		}
+		if err := p.SkipAllQuestions(); err != nil {
+			return nil, err
+		}
+		answers := 0
+		for {
+			h, err := p.AnswerHeader()
+			if err == dnsmessage.ErrSectionDone {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			answers++
+
+			switch h.Type {
+			case dnsmessage.TypeSOA:
+				soas++
+				err = p.SkipAnswer()
+			case dnsmessage.TypeTXT:
+				var txt dnsmessage.TXTResource
+				txt, err = p.TXTResource()
+				if err == nil {
+					text := strings.Join(txt.TXT, "")
+					records = append(records, record{name: recordName(h.Name), typ: recordType(text), text: text})
+				}
+			case typeSPF:
+				var spf dnsmessage.UnknownResource
+				spf, err = p.UnknownResource()
+				if err == nil {
+					records = append(records, record{name: recordName(h.Name), typ: "SPF", text: characterStrings(spf.Data)})
+				}
+			default:
+				err = p.SkipAnswer()
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if answers == 0 {
+			return nil, errors.New("transfer ended early")
+		}
+	}
+	return records, nil
+}
+
+// recordName returns the name of a record without the trailing dot.
+func recordName(name dnsmessage.Name) string {
+	return strings.TrimSuffix(name.String(), ".")
+}
+
+// characterStrings returns the concatenated length-prefixed strings of the
+// data of a TXT-like record.
+func characterStrings(data []byte) string {
+	var b strings.Builder
+	for len(data) > 0 {
+		n := int(data[0])
+		data = data[1:]
+		if n > len(data) {
+			n = len(data)
+		}
+		b.Write(data[:n])
+		data = data[n:]
+	}
+	return b.String()
+}
@@ -0,0 +1,163 @@
+package sources
+
+import (
+	"context"
+	"io/fs"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+// The classes of ItemErrors.
+const (
+	ErrorClassAccessDenied = "access_denied"
+	ErrorClassNotFound     = "not_found"
+	ErrorClassRateLimited  = "rate_limited"
+	ErrorClassTimeout      = "timeout"
+	ErrorClassNetwork      = "network"
+	ErrorClassServer       = "server_error"
+	ErrorClassUnknown      = "unknown"
+)
+
+// SkippedError is the reason items that failed to be scanned are counted as
+// skipped in a source's progress.
+const SkippedError = "error"
+
+// maxItemErrors limits how many item errors a source's progress keeps, so
+// that a source failing on every item doesn't exhaust memory. They're all
+// counted as skipped.
+const maxItemErrors = 10000
+
+// ItemError is the failure to scan an item of a source, such as an object,
+// file or repository, which didn't stop the rest of the source being
+// scanned.
+type ItemError struct {
+	// Item identifies what failed to be scanned, such as an S3 object's
+	// s3:// URL or a repository's URL, so it can be scanned again.
+	Item string
+	// Class is the kind of failure, such as ErrorClassAccessDenied.
+	Class string
+	// Retriable reports whether scanning the item again may succeed, as
+	// when the failure was a timeout or rate limit, rather than a lack of
+	// access.
+	Retriable bool
+	// Message is the error's message.
+	Message string
+	// Attempts is how many times scanning the item was tried.
+	Attempts int
+}
+
+// NewItemError returns the item error of err failing to scan item.
+func NewItemError(item string, err error) ItemError {
+	class, retriable := ClassifyError(err)
+	return ItemError{Item: item, Class: class, Retriable: retriable, Message: err.Error(), Attempts: 1}
+}
+
+// statusPat matches the HTTP status in the errors of API clients, such as
+// "GET https://api.github.com/repos/o/r: 404 Not Found".
+var statusPat = regexp.MustCompile(`: ([1-5][0-9][0-9])\b`)
+
+// errorCodes are the classes of the error codes of AWS and other services.
+var errorCodes = map[string]string{
+	"AccessDenied":          ErrorClassAccessDenied,
+	"AllAccessDisabled":     ErrorClassAccessDenied,
+	"Forbidden":             ErrorClassAccessDenied,
+	"InvalidAccessKeyId":    ErrorClassAccessDenied,
+	"SignatureDoesNotMatch": ErrorClassAccessDenied,
+	"ExpiredToken":          ErrorClassAccessDenied,
+	"NoSuchKey":             ErrorClassNotFound,
+	"NoSuchBucket":          ErrorClassNotFound,
+	"NotFound":              ErrorClassNotFound,
+	"SlowDown":              ErrorClassRateLimited,
+	"Throttling":            ErrorClassRateLimited,
+	"ThrottlingException":   ErrorClassRateLimited,
+	"TooManyRequests":       ErrorClassRateLimited,
+	"RequestLimitExceeded":  ErrorClassRateLimited,
+	"RequestTimeout":        ErrorClassTimeout,
+	"InternalError":         ErrorClassServer,
+	"ServiceUnavailable":    ErrorClassServer,
+}
+
+// errorPhrases are the classes of errors told by their messages, such as
+// those of git.
+var errorPhrases = []struct {
+	phrase, class string
+}{
+	{"authentication failed", ErrorClassAccessDenied},
+	{"authentication required", ErrorClassAccessDenied},
+	{"permission denied", ErrorClassAccessDenied},
+	{"repository not found", ErrorClassNotFound},
+	{"i/o timeout", ErrorClassTimeout},
+	{"timed out", ErrorClassTimeout},
+	{"could not resolve host", ErrorClassNetwork},
+	{"connection refused", ErrorClassNetwork},
+	{"connection reset", ErrorClassNetwork},
+	{"no route to host", ErrorClassNetwork},
+	{"rate limit", ErrorClassRateLimited},
+}
+
+// ClassifyError returns the class of err, one of the ErrorClass constants,
+// and whether retrying what failed may succeed. It's told by the error's
+// type, its code for AWS errors, the HTTP status in its message for API
+// clients, and otherwise by phrases in its message.
+func ClassifyError(err error) (class string, retriable bool) {
+	class = classOf(err)
+	switch class {
+	case ErrorClassRateLimited, ErrorClassTimeout, ErrorClassNetwork, ErrorClassServer:
+		return class, true
+	}
+	return class, false
+}
+
+func classOf(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTimeout
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorClassTimeout
+	case errors.Is(err, fs.ErrPermission):
+		return ErrorClassAccessDenied
+	case errors.Is(err, fs.ErrNotExist):
+		return ErrorClassNotFound
+	}
+
+	var coded interface{ Code() string }
+	if errors.As(err, &coded) {
+		if class, ok := errorCodes[coded.Code()]; ok {
+			return class
+		}
+	}
+
+	msg := err.Error()
+	if m := statusPat.FindStringSubmatch(msg); m != nil {
+		status, _ := strconv.Atoi(m[1])
+		switch {
+		case status == 401 || status == 403:
+			return ErrorClassAccessDenied
+		case status == 404:
+			return ErrorClassNotFound
+		case status == 408:
+			return ErrorClassTimeout
+		case status == 429:
+			return ErrorClassRateLimited
+		case status >= 500:
+			return ErrorClassServer
+		}
+	}
+	lower := strings.ToLower(msg)
+	for _, p := range errorPhrases {
+		if strings.Contains(lower, p.phrase) {
+			return p.class
+		}
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return ErrorClassNetwork
+	}
+	return ErrorClassUnknown
+}
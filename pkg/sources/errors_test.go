@@ -0,0 +1,101 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/go-errors/errors"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantClass     string
+		wantRetriable bool
+	}{
+		{
+			name:          "deadline",
+			err:           fmt.Errorf("cloning: %w", context.DeadlineExceeded),
+			wantClass:     ErrorClassTimeout,
+			wantRetriable: true,
+		},
+		{
+			name:      "permission",
+			err:       &os.PathError{Op: "open", Path: "/etc/shadow", Err: os.ErrPermission},
+			wantClass: ErrorClassAccessDenied,
+		},
+		{
+			name:      "missing file",
+			err:       errors.WrapPrefix(&os.PathError{Op: "open", Path: "gone", Err: os.ErrNotExist}, "unable to scan file", 0),
+			wantClass: ErrorClassNotFound,
+		},
+		{
+			name:      "aws access denied",
+			err:       awserr.New("AccessDenied", "Access Denied", nil),
+			wantClass: ErrorClassAccessDenied,
+		},
+		{
+			name:          "aws slow down",
+			err:           awserr.New("SlowDown", "Please reduce your request rate.", nil),
+			wantClass:     ErrorClassRateLimited,
+			wantRetriable: true,
+		},
+		{
+			name:      "api not found",
+			err:       errors.New("GET https://api.github.com/repos/o/r/issues: 404 Not Found []"),
+			wantClass: ErrorClassNotFound,
+		},
+		{
+			name:          "api server error",
+			err:           errors.New("GET https://gitlab.example.com/api/v4/projects: 502 Bad Gateway"),
+			wantClass:     ErrorClassServer,
+			wantRetriable: true,
+		},
+		{
+			name:      "git authentication",
+			err:       errors.New("error running git clone: fatal: Authentication failed for 'https://github.com/o/r.git/'"),
+			wantClass: ErrorClassAccessDenied,
+		},
+		{
+			name:          "git host",
+			err:           errors.New("fatal: unable to access 'https://github.com/o/r.git/': Could not resolve host: github.com"),
+			wantClass:     ErrorClassNetwork,
+			wantRetriable: true,
+		},
+		{
+			name:      "unknown",
+			err:       errors.New("zip: not a valid zip file"),
+			wantClass: ErrorClassUnknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			class, retriable := ClassifyError(tt.err)
+			if class != tt.wantClass || retriable != tt.wantRetriable {
+				t.Errorf("ClassifyError() = %q, %v, want %q, %v", class, retriable, tt.wantClass, tt.wantRetriable)
+			}
+		})
+	}
+}
+
+func TestProgress_AddItemError(t *testing.T) {
+	var p Progress
+	p.AddItemError("s3://bucket/a.txt", awserr.New("AccessDenied", "Access Denied", nil))
+	p.AddItemError("s3://bucket/b.txt", awserr.New("InternalError", "We encountered an internal error.", nil))
+
+	want := []ItemError{
+		{Item: "s3://bucket/a.txt", Class: ErrorClassAccessDenied, Message: "AccessDenied: Access Denied", Attempts: 1},
+		{Item: "s3://bucket/b.txt", Class: ErrorClassServer, Retriable: true, Message: "InternalError: We encountered an internal error.", Attempts: 1},
+	}
+	if diff := pretty.Compare(p.FailedItems(), want); diff != "" {
+		t.Errorf("FailedItems() diff: (-got +want)\n%s", diff)
+	}
+	if got := p.SkippedCounts()[SkippedError]; got != 2 {
+		t.Errorf("SkippedCounts()[%q] = %d, want 2", SkippedError, got)
+	}
+}
@@ -0,0 +1,139 @@
+package eventlog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/go-logr/logr"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/evtx"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// DefaultChannels are read when no channels or query are configured.
+var DefaultChannels = []string{
+	"Application",
+	"System",
+	"Security",
+	"Microsoft-Windows-PowerShell/Operational",
+}
+
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	aCtx     context.Context
+	log      logr.Logger
+	sources.Progress
+	conn *sourcespb.WindowsEventLog
+}
+
+// Ensure the Source satisfies the interface at compile time.
+var _ sources.Source = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_WINDOWS_EVENT_LOG
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// Init returns an initialized Windows Event Log source.
+func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, _ int) error {
+	s.log = log.FromContext(aCtx).WithValues("name", name)
+
+	s.aCtx = aCtx
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+
+	var conn sourcespb.WindowsEventLog
+	if err := sources.UnmarshalConnection(connection, &conn); err != nil {
+		return err
+	}
+	if err := validateConnection(&conn); err != nil {
+		return err
+	}
+	if len(conn.Channels) == 0 && conn.Query == "" {
+		conn.Channels = DefaultChannels
+	}
+	s.conn = &conn
+
+	return nil
+}
+
+// validateConnection checks that the connection names no blank channels.
+func validateConnection(conn *sourcespb.WindowsEventLog) error {
+	var v sources.Validator
+	for i, channel := range conn.Channels {
+		v.Require(fmt.Sprintf("channels[%d]", i), strings.TrimSpace(channel) != "")
+	}
+	return v.Err()
+}
+
+// Chunks emits a chunk for each event read from the configured channels. When
+// following, it keeps waiting for new events until the context is cancelled.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	// A query without channels is a structured XML query that selects its own.
+	channels := s.conn.Channels
+	if len(channels) == 0 {
+		channels = []string{""}
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	for _, channel := range channels {
+		channel := channel
+		g.Go(func() error {
+			s.log.V(1).Info("reading event log", "channel", channel, "query", s.conn.Query, "follow", s.conn.Follow)
+			err := readEvents(ctx, channel, s.conn.Query, s.conn.Follow, func(event *evtx.Event) error {
+				return s.sendEvent(ctx, event, chunksChan)
+			})
+			if err != nil {
+				return errors.WrapPrefix(err, "could not read event log "+channel, 0)
+			}
+			return nil
+		})
+	}
+	err := g.Wait()
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+func (s *Source) sendEvent(ctx context.Context, event *evtx.Event, chunksChan chan *sources.Chunk) error {
+	data := event.Text()
+	if len(data) == 0 {
+		return nil
+	}
+	chunk := &sources.Chunk{
+		SourceType:     s.Type(),
+		SourceName:     s.name,
+		SourceID:       s.SourceID(),
+		Data:           data,
+		SourceMetadata: handlers.EventMetadata(event, ""),
+		Verify:         s.verify,
+	}
+	select {
+	case chunksChan <- chunk:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
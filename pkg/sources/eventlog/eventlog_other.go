@@ -0,0 +1,16 @@
+//go:build !windows
+// +build !windows
+
+package eventlog
+
+import (
+	"context"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/evtx"
+)
+
+func readEvents(context.Context, string, string, bool, func(*evtx.Event) error) error {
+	return errors.New("the Windows Event Log can only be read on Windows, use the filesystem source for exported .evtx files")
+}
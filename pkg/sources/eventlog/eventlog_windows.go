@@ -0,0 +1,228 @@
+//go:build windows
+// +build windows
+
+package eventlog
+
+import (
+	"context"
+	"unsafe"
+
+	"github.com/go-errors/errors"
+	"golang.org/x/sys/windows"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/evtx"
+)
+
+var (
+	wevtapi = windows.NewLazySystemDLL("wevtapi.dll")
+
+	procEvtQuery                 = wevtapi.NewProc("EvtQuery")
+	procEvtSubscribe             = wevtapi.NewProc("EvtSubscribe")
+	procEvtNext                  = wevtapi.NewProc("EvtNext")
+	procEvtRender                = wevtapi.NewProc("EvtRender")
+	procEvtOpenPublisherMetadata = wevtapi.NewProc("EvtOpenPublisherMetadata")
+	procEvtFormatMessage         = wevtapi.NewProc("EvtFormatMessage")
+	procEvtClose                 = wevtapi.NewProc("EvtClose")
+)
+
+const (
+	evtQueryChannelPath             = 0x1
+	evtQueryForwardDirection        = 0x100
+	evtSubscribeStartAtOldestRecord = 2
+	evtRenderEventXML               = 1
+	evtFormatMessageEvent           = 1
+
+	// batchSize is the number of events fetched by each call to EvtNext.
+	batchSize = 64
+	// pollInterval is how often a subscription checks whether it's been cancelled.
+	pollInterval = 1000 // milliseconds
+)
+
+type evtHandle uintptr
+
+func evtClose(h evtHandle) {
+	_, _, _ = procEvtClose.Call(uintptr(h))
+}
+
+// readEvents calls fn with the events in a channel that match query. When
+// following, it waits for new events until ctx is cancelled.
+func readEvents(ctx context.Context, channel, query string, follow bool, fn func(*evtx.Event) error) error {
+	if query == "" {
+		query = "*"
+	}
+	var channelPtr *uint16
+	if channel != "" {
+		p, err := windows.UTF16PtrFromString(channel)
+		if err != nil {
+			return err
+		}
+		channelPtr = p
+	}
+	queryPtr, err := windows.UTF16PtrFromString(query)
+	if err != nil {
+		return err
+	}
+
+	r := &renderer{publishers: map[string]evtHandle{}}
+	defer r.close()
+
+	if !follow {
+		results, _, err := procEvtQuery.Call(0, uintptr(unsafe.Pointer(channelPtr)), uintptr(unsafe.Pointer(queryPtr)),
+			evtQueryChannelPath|evtQueryForwardDirection)
+		if results == 0 {
+			return errors.WrapPrefix(err, "EvtQuery failed", 0)
+		}
+		defer evtClose(evtHandle(results))
+		_, err = r.drain(ctx, evtHandle(results), fn)
+		return err
+	}
+
+	signal, err := windows.CreateEvent(nil, 1, 1, nil)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not create subscription event", 0)
+	}
+	defer windows.CloseHandle(signal)
+
+	subscription, _, err := procEvtSubscribe.Call(0, uintptr(signal), uintptr(unsafe.Pointer(channelPtr)),
+		uintptr(unsafe.Pointer(queryPtr)), 0, 0, 0, evtSubscribeStartAtOldestRecord)
+	if subscription == 0 {
+		return errors.WrapPrefix(err, "EvtSubscribe failed", 0)
+	}
+	defer evtClose(evtHandle(subscription))
+
+	for {
+		status, err := windows.WaitForSingleObject(signal, pollInterval)
+		if err != nil {
+			return errors.WrapPrefix(err, "could not wait for events", 0)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if status != windows.WAIT_OBJECT_0 {
+			continue
+		}
+		if err := windows.ResetEvent(signal); err != nil {
+			return errors.WrapPrefix(err, "could not reset subscription event", 0)
+		}
+		if _, err := r.drain(ctx, evtHandle(subscription), fn); err != nil {
+			return err
+		}
+	}
+}
+
+// renderer renders events and their messages, caching the publisher metadata
+// that messages are formatted with.
+type renderer struct {
+	buf        []uint16
+	publishers map[string]evtHandle
+}
+
+// drain calls fn with each event currently available from results.
+func (r *renderer) drain(ctx context.Context, results evtHandle, fn func(*evtx.Event) error) (int, error) {
+	events := make([]evtHandle, batchSize)
+	count := 0
+	for {
+		var returned uint32
+		ok, _, err := procEvtNext.Call(uintptr(results), batchSize, uintptr(unsafe.Pointer(&events[0])), 0, 0,
+			uintptr(unsafe.Pointer(&returned)))
+		if ok == 0 {
+			if errors.Is(err, windows.ERROR_NO_MORE_ITEMS) {
+				return count, nil
+			}
+			return count, errors.WrapPrefix(err, "EvtNext failed", 0)
+		}
+		for i, h := range events[:returned] {
+			event, err := r.event(h)
+			evtClose(h)
+			if err != nil {
+				continue
+			}
+			count++
+			if err := fn(event); err != nil {
+				// Close the rest of the batch before giving up.
+				for _, rest := range events[i+1 : returned] {
+					evtClose(rest)
+				}
+				return count, err
+			}
+		}
+		if ctx.Err() != nil {
+			return count, ctx.Err()
+		}
+	}
+}
+
+// event renders an event handle as XML and adds its formatted message.
+func (r *renderer) event(h evtHandle) (*evtx.Event, error) {
+	xml, err := r.call(func(buf []uint16, used *uint32) (uintptr, error) {
+		var properties uint32
+		// EvtRender takes the buffer size in bytes.
+		ok, _, err := procEvtRender.Call(0, uintptr(h), evtRenderEventXML, uintptr(len(buf)*2), uintptr(unsafe.Pointer(&buf[0])),
+			uintptr(unsafe.Pointer(used)), uintptr(unsafe.Pointer(&properties)))
+		return ok, err
+	})
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "EvtRender failed", 0)
+	}
+	event, err := evtx.ParseXML([]byte(xml))
+	if err != nil {
+		return nil, err
+	}
+
+	if publisher := r.publisher(event.Provider); publisher != 0 {
+		message, err := r.call(func(buf []uint16, used *uint32) (uintptr, error) {
+			// EvtFormatMessage takes the buffer size in characters.
+			ok, _, err := procEvtFormatMessage.Call(uintptr(publisher), uintptr(h), 0, 0, 0, evtFormatMessageEvent,
+				uintptr(len(buf)), uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(used)))
+			return ok, err
+		})
+		if err == nil && message != "" {
+			event.Data = append(event.Data, evtx.Field{Name: "Message", Value: message})
+		}
+	}
+	return event, nil
+}
+
+// publisher returns the metadata handle for a provider, or 0 if it can't be opened.
+func (r *renderer) publisher(provider string) evtHandle {
+	if provider == "" {
+		return 0
+	}
+	if h, ok := r.publishers[provider]; ok {
+		return h
+	}
+	var h evtHandle
+	if name, err := windows.UTF16PtrFromString(provider); err == nil {
+		handle, _, _ := procEvtOpenPublisherMetadata.Call(0, uintptr(unsafe.Pointer(name)), 0, 0, 0)
+		h = evtHandle(handle)
+	}
+	r.publishers[provider] = h
+	return h
+}
+
+// call runs an API function that writes a string to a buffer, growing the
+// buffer to the size it reports needing and retrying if it's too small.
+func (r *renderer) call(fn func(buf []uint16, used *uint32) (uintptr, error)) (string, error) {
+	if len(r.buf) == 0 {
+		r.buf = make([]uint16, 4096)
+	}
+	for {
+		var used uint32
+		ok, err := fn(r.buf, &used)
+		if ok != 0 {
+			return windows.UTF16ToString(r.buf), nil
+		}
+		if !errors.Is(err, windows.ERROR_INSUFFICIENT_BUFFER) || int(used) <= len(r.buf) {
+			return "", err
+		}
+		r.buf = make([]uint16, used)
+	}
+}
+
+func (r *renderer) close() {
+	for _, h := range r.publishers {
+		if h != 0 {
+			evtClose(h)
+		}
+	}
+}
@@ -10,11 +10,12 @@ import (
 	"path/filepath"
 
 	"github.com/go-errors/errors"
-	log "github.com/sirupsen/logrus"
-	"google.golang.org/protobuf/proto"
+	"github.com/go-logr/logr"
 	"google.golang.org/protobuf/types/known/anypb"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
@@ -35,7 +36,7 @@ type Source struct {
 	verify   bool
 	paths    []string
 	aCtx     context.Context
-	log      *log.Entry
+	log      logr.Logger
 	sources.Progress
 }
 
@@ -58,7 +59,7 @@ func (s *Source) JobID() int64 {
 
 // Init returns an initialized Filesystem source.
 func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
-	s.log = log.WithField("source", s.Type()).WithField("name", name)
+	s.log = log.FromContext(aCtx).WithValues("name", name)
 
 	s.aCtx = aCtx
 	s.name = name
@@ -67,9 +68,11 @@ func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64,
 	s.verify = verify
 
 	var conn sourcespb.Filesystem
-	err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{})
-	if err != nil {
-		errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	if err := sources.UnmarshalConnection(connection, &conn); err != nil {
+		return err
+	}
+	if err := validateConnection(&conn); err != nil {
+		return err
 	}
 
 	s.paths = conn.Directories
@@ -77,104 +80,131 @@ func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64,
 	return nil
 }
 
+// validateConnection checks that the connection has paths to scan.
+func validateConnection(conn *sourcespb.Filesystem) error {
+	var v sources.Validator
+	v.Require("directories", len(conn.Directories) > 0)
+	for i, path := range conn.Directories {
+		v.Require(fmt.Sprintf("directories[%d]", i), path != "")
+	}
+	return v.Err()
+}
+
 // Chunks emits chunks of bytes over a channel.
 func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
 	for i, path := range s.paths {
 		s.SetProgressComplete(i, len(s.paths), fmt.Sprintf("Path: %s", path), "")
 
 		cleanPath := filepath.Clean(path)
-		done := false
-		go func() {
-			<-ctx.Done()
-			done = true
-		}()
-
 		err := fs.WalkDir(os.DirFS(cleanPath), ".", func(relativePath string, d fs.DirEntry, err error) error {
 			if err != nil {
 				return nil
 			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
 
 			path := filepath.Join(cleanPath, relativePath)
-
-			fileStat, err := os.Stat(path)
-			if err != nil {
-				log.WithError(err).Warnf("unable to stat file: %s", path)
-				return nil
+			chunkSkel := &sources.Chunk{
+				SourceType: s.Type(),
+				SourceName: s.name,
+				SourceID:   s.SourceID(),
+				Verify:     s.verify,
 			}
-			if !fileStat.Mode().IsRegular() {
-				return nil
+			if err := ScanFile(ctx, path, chunkSkel, chunksChan); err != nil {
+				s.log.Error(err, "unable to scan file", "path", path)
+				s.AddItemError(path, err)
 			}
+			return nil
+		})
 
-			inputFile, err := os.Open(path)
-			if err != nil {
-				log.Warn(err)
-				return nil
-			}
-			defer inputFile.Close()
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil && err != io.EOF {
+			return errors.New(err)
+		}
+	}
+	return nil
+}
 
-			reader := bufio.NewReaderSize(bufio.NewReader(inputFile), BufferSize)
-			firstChunk := true
-			for {
-				if done {
-					return nil
-				}
+// ScanFile sends the chunks of the file at path to chunksChan, with the source
+// fields of chunkSkel, unpacking archives and other files handlers know of.
+// Files that aren't regular files, such as directories, are skipped.
+func ScanFile(ctx context.Context, path string, chunkSkel *sources.Chunk, chunksChan chan *sources.Chunk) error {
+	fileStat, err := os.Stat(path)
+	if err != nil {
+		return errors.WrapPrefix(err, "unable to stat file", 0)
+	}
+	if !fileStat.Mode().IsRegular() {
+		return nil
+	}
 
-				end := BufferSize
-				buf := make([]byte, BufferSize)
-				n, err := reader.Read(buf)
+	inputFile, err := os.Open(path)
+	if err != nil {
+		return errors.WrapPrefix(err, "unable to open file", 0)
+	}
+	defer inputFile.Close()
 
-				if n < BufferSize {
-					end = n
-				}
+	handled, err := handlers.HandleFile(ctx, path, inputFile, chunkSkel, chunksChan)
+	if err != nil {
+		return errors.WrapPrefix(err, "unable to handle file", 0)
+	}
+	if handled {
+		return nil
+	}
 
-				if end > 0 {
-					data := buf[0:end]
-
-					if firstChunk {
-						firstChunk = false
-						if common.SkipFile(path, data) {
-							return nil
-						}
-					}
-
-					// We are peeking in case a secret exists in our chunk boundaries,
-					// but we never care if we've run into a peek error.
-					peekData, _ := reader.Peek(PeekSize)
-					chunksChan <- &sources.Chunk{
-						SourceType: s.Type(),
-						SourceName: s.name,
-						SourceID:   s.SourceID(),
-						Data:       append(data, peekData...),
-						SourceMetadata: &source_metadatapb.MetaData{
-							Data: &source_metadatapb.MetaData_Filesystem{
-								Filesystem: &source_metadatapb.Filesystem{
-									File: sanitizer.UTF8(path),
-								},
-							},
-						},
-						Verify: s.verify,
-					}
-				}
+	reader := bufio.NewReaderSize(bufio.NewReader(inputFile), BufferSize)
+	firstChunk := true
+	// The content type is sniffed from the file's first bytes, since later
+	// chunks can start with anything, including another format's magic bytes.
+	var contentType string
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		end := BufferSize
+		buf := make([]byte, BufferSize)
+		n, err := reader.Read(buf)
 
-				// io.EOF can be emmitted when 0<n<buffer size
-				if err != nil {
-					if errors.Is(err, io.EOF) {
-						return nil
-					} else {
-						return err
-					}
+		if n < BufferSize {
+			end = n
+		}
+
+		if end > 0 {
+			data := buf[0:end]
+
+			if firstChunk {
+				firstChunk = false
+				if common.SkipFile(path, data) {
+					return nil
 				}
+				contentType = handlers.ContentType(data)
 			}
-		})
 
-		if err != nil && err != io.EOF {
-			return errors.New(err)
+			// We are peeking in case a secret exists in our chunk boundaries,
+			// but we never care if we've run into a peek error.
+			peekData, _ := reader.Peek(PeekSize)
+			chunk := *chunkSkel
+			chunk.Data = append(data, peekData...)
+			chunk.ContentType = contentType
+			chunk.SourceMetadata = &source_metadatapb.MetaData{
+				Data: &source_metadatapb.MetaData_Filesystem{
+					Filesystem: &source_metadatapb.Filesystem{
+						File: sanitizer.UTF8(path),
+					},
+				},
+			}
+			chunksChan <- &chunk
 		}
 
-		if done {
-			return nil
+		// io.EOF can be emmitted when 0<n<buffer size
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
 		}
-
 	}
-	return nil
 }
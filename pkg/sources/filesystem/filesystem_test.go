@@ -2,14 +2,15 @@ package filesystem
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/kylelemons/godebug/pretty"
 	"google.golang.org/protobuf/types/known/anypb"
 
-	log "github.com/sirupsen/logrus"
-
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
@@ -52,8 +53,6 @@ func TestSource_Scan(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := Source{}
-			log.SetLevel(log.DebugLevel)
-			log.SetFormatter(&log.TextFormatter{ForceColors: true})
 
 			conn, err := anypb.New(tt.init.connection)
 			if err != nil {
@@ -82,3 +81,25 @@ func TestSource_Scan(t *testing.T) {
 		})
 	}
 }
+
+func TestScanFile_ContentType(t *testing.T) {
+	// The second chunk of the file starts with a GIF's magic bytes.
+	path := filepath.Join(t.TempDir(), "app.env")
+	data := strings.Repeat("a", BufferSize) + "GIF87a password=hunter2\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	chunksCh := make(chan *sources.Chunk, 2)
+	if err := ScanFile(context.Background(), path, &sources.Chunk{}, chunksCh); err != nil {
+		t.Fatal(err)
+	}
+	close(chunksCh)
+	var got []string
+	for chunk := range chunksCh {
+		got = append(got, chunk.ContentType)
+	}
+	if diff := pretty.Compare(got, []string{"text/plain", "text/plain"}); diff != "" {
+		t.Errorf("ScanFile() content types diff: (-got +want)\n%s", diff)
+	}
+}
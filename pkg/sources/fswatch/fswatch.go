@@ -0,0 +1,240 @@
+package fswatch
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/filesystem"
+)
+
+// DefaultDebounce is how long a file must go unchanged before it's scanned,
+// when the connection doesn't say.
+const DefaultDebounce = time.Second
+
+// Source watches directories and scans the files created or changed in them,
+// once they've stopped changing. On Linux, changes are watched with inotify.
+// Elsewhere, the directories are polled.
+type Source struct {
+	sources.Base
+	dirs     []string
+	ignore   filter
+	debounce time.Duration
+}
+
+// Ensure the Source satisfies the interface at compile time.
+var _ sources.Source = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_FILESYSTEM_WATCHER
+}
+
+// Init returns an initialized filesystem watcher source.
+func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.InitBase(aCtx, s.Type(), name, jobId, sourceId, verify, concurrency)
+
+	var conn sourcespb.FilesystemWatcher
+	if err := sources.UnmarshalConnection(connection, &conn); err != nil {
+		return err
+	}
+	if err := validateConnection(&conn); err != nil {
+		return err
+	}
+
+	s.dirs = make([]string, len(conn.Directories))
+	for i, dir := range conn.Directories {
+		s.dirs[i] = filepath.Clean(dir)
+	}
+	s.ignore = filter{patterns: conn.Ignore}
+	s.debounce = time.Duration(conn.DebounceMs) * time.Millisecond
+	if s.debounce == 0 {
+		s.debounce = DefaultDebounce
+	}
+
+	return nil
+}
+
+// validateConnection checks that the connection watches directories that
+// exist, with valid ignore patterns.
+func validateConnection(conn *sourcespb.FilesystemWatcher) error {
+	var v sources.Validator
+	v.Require("directories", len(conn.Directories) > 0)
+	for i, dir := range conn.Directories {
+		field := fmt.Sprintf("directories[%d]", i)
+		if dir == "" {
+			v.Require(field, false)
+			continue
+		}
+		if info, err := os.Stat(dir); err != nil {
+			v.Problemf("%s %q can't be watched: %v", field, dir, err)
+		} else if !info.IsDir() {
+			v.Problemf("%s %q is not a directory", field, dir)
+		}
+	}
+	for i, pattern := range conn.Ignore {
+		if _, err := path.Match(pattern, ""); err != nil {
+			v.Problemf("ignore[%d] %q is not a valid glob pattern", i, pattern)
+		}
+	}
+	if conn.DebounceMs < 0 {
+		v.Problemf("debounceMs %d must not be negative", conn.DebounceMs)
+	}
+	return v.Err()
+}
+
+// Chunks scans each file created or changed in the watched directories, once
+// it's gone unchanged for the debounce period, until the context is cancelled.
+// Files already in the directories aren't scanned.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	changed := make(chan string)
+	d := newDebouncer(s.debounce, func(path string) {
+		select {
+		case changed <- path:
+		case <-ctx.Done():
+		}
+	})
+	defer d.stop()
+
+	watchErr := make(chan error, 1)
+	go func() {
+		watchErr <- s.watch(ctx, d.touch)
+	}()
+
+	for {
+		select {
+		case path := <-changed:
+			s.scanFile(ctx, path, chunksChan)
+		case err := <-watchErr:
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (s *Source) scanFile(ctx context.Context, path string, chunksChan chan *sources.Chunk) {
+	s.Log().V(3).Info("scanning changed file", "path", path)
+	err := filesystem.ScanFile(ctx, path, s.ChunkSkel(), chunksChan)
+	if err == nil {
+		return
+	}
+	// Temporary files are often removed before they're scanned.
+	if _, statErr := os.Stat(path); errors.Is(statErr, fs.ErrNotExist) {
+		return
+	}
+	s.Log().Error(err, "unable to scan file", "path", path)
+}
+
+// walk calls fn with the path of each directory and file under dir, which is
+// in the watched directory root, leaving out those that are ignored and the
+// contents of ignored directories.
+func (s *Source) walk(root, dir string, fn func(path string, d fs.DirEntry)) {
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			s.Log().V(2).Info("unable to walk path", "path", path, "error", err.Error())
+			return nil
+		}
+		if path != root && s.ignore.ignored(root, path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		fn(path, d)
+		return nil
+	})
+}
+
+// filter decides which files and directories aren't watched.
+type filter struct {
+	// patterns are globs matched against the names of files and directories,
+	// or against their paths relative to the watched directory if they have a
+	// slash.
+	patterns []string
+}
+
+func (f filter) ignored(root, p string) bool {
+	name := filepath.Base(p)
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		rel = p
+	}
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range f.patterns {
+		target := name
+		if strings.Contains(pattern, "/") {
+			target = rel
+		}
+		if ok, _ := path.Match(pattern, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// debouncer calls fire with a path once touch hasn't been called with it for
+// delay, so files being written are scanned once they're complete rather than
+// after each write.
+type debouncer struct {
+	delay time.Duration
+	fire  func(path string)
+
+	mu     sync.Mutex
+	timers map[string]*time.Timer
+}
+
+func newDebouncer(delay time.Duration, fire func(path string)) *debouncer {
+	return &debouncer{delay: delay, fire: fire, timers: map[string]*time.Timer{}}
+}
+
+// touch records that path changed.
+func (d *debouncer) touch(path string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timers == nil {
+		return
+	}
+	if t, ok := d.timers[path]; ok && t.Stop() {
+		t.Reset(d.delay)
+		return
+	}
+	var t *time.Timer
+	t = time.AfterFunc(d.delay, func() {
+		d.mu.Lock()
+		if d.timers[path] == t {
+			delete(d.timers, path)
+		}
+		d.mu.Unlock()
+		d.fire(path)
+	})
+	d.timers[path] = t
+}
+
+// stop drops the changes that haven't fired yet.
+func (d *debouncer) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, t := range d.timers {
+		t.Stop()
+	}
+	d.timers = nil
+}
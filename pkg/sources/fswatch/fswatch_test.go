@@ -0,0 +1,241 @@
+package fswatch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestSource_Init(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.txt")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		conn    *sourcespb.FilesystemWatcher
+		wantErr string
+	}{
+		{
+			name: "valid",
+			conn: &sourcespb.FilesystemWatcher{Directories: []string{dir}, Ignore: []string{"*.tmp", "build/*"}},
+		},
+		{
+			name:    "no directories",
+			conn:    &sourcespb.FilesystemWatcher{},
+			wantErr: "directories is required",
+		},
+		{
+			name:    "missing directory",
+			conn:    &sourcespb.FilesystemWatcher{Directories: []string{filepath.Join(dir, "missing")}},
+			wantErr: "can't be watched",
+		},
+		{
+			name:    "file",
+			conn:    &sourcespb.FilesystemWatcher{Directories: []string{file}},
+			wantErr: "is not a directory",
+		},
+		{
+			name:    "bad pattern",
+			conn:    &sourcespb.FilesystemWatcher{Directories: []string{dir}, Ignore: []string{"[a-"}},
+			wantErr: `ignore[0] "[a-" is not a valid glob pattern`,
+		},
+		{
+			name:    "negative debounce",
+			conn:    &sourcespb.FilesystemWatcher{Directories: []string{dir}, DebounceMs: -1},
+			wantErr: "must not be negative",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := anypb.New(tt.conn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := Source{}
+			err = s.Init(context.Background(), "test watcher", 0, 0, false, conn, 1)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Init() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Init() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	f := filter{patterns: []string{"*.tmp", ".git", "build/*.log"}}
+	root := filepath.Join("srv", "share")
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "report.txt", want: false},
+		{path: "report.txt.tmp", want: true},
+		{path: filepath.Join("nested", "x.tmp"), want: true},
+		{path: ".git", want: true},
+		{path: filepath.Join("build", "out.log"), want: true},
+		{path: filepath.Join("src", "build", "out.log"), want: false},
+	}
+	for _, tt := range tests {
+		if got := f.ignored(root, filepath.Join(root, tt.path)); got != tt.want {
+			t.Errorf("ignored(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestDebouncer(t *testing.T) {
+	var mu sync.Mutex
+	fired := map[string]int{}
+	d := newDebouncer(50*time.Millisecond, func(path string) {
+		mu.Lock()
+		defer mu.Unlock()
+		fired[path]++
+	})
+
+	// Writes closer together than the delay are scanned once.
+	for i := 0; i < 5; i++ {
+		d.touch("a")
+		time.Sleep(10 * time.Millisecond)
+	}
+	d.touch("b")
+	time.Sleep(200 * time.Millisecond)
+
+	mu.Lock()
+	if fired["a"] != 1 || fired["b"] != 1 {
+		t.Errorf("fired = %v, want each path once", fired)
+	}
+	mu.Unlock()
+
+	d.touch("c")
+	d.stop()
+	time.Sleep(100 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if fired["c"] != 0 {
+		t.Errorf("fired %q after stop", "c")
+	}
+}
+
+func TestSource_Chunks(t *testing.T) {
+	tests := []struct {
+		name  string
+		watch func(s *Source, ctx context.Context, changed func(path string)) error
+	}{
+		{
+			name:  "native",
+			watch: (*Source).watch,
+		},
+		{
+			name: "poll",
+			watch: func(s *Source, ctx context.Context, changed func(path string)) error {
+				return s.poll(ctx, 20*time.Millisecond, changed)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "existing.txt"), []byte("existing"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			conn, err := anypb.New(&sourcespb.FilesystemWatcher{
+				Directories: []string{dir},
+				Ignore:      []string{"*.tmp"},
+				DebounceMs:  50,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := Source{}
+			if err := s.Init(context.Background(), "test watcher", 0, 0, false, conn, 1); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			changes := make(chan string, 10)
+			go func() {
+				_ = tt.watch(&s, ctx, func(path string) { changes <- path })
+			}()
+			// Give the watcher time to start.
+			time.Sleep(100 * time.Millisecond)
+
+			if err := os.WriteFile(filepath.Join(dir, "ignored.tmp"), []byte("ignored"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			nested := filepath.Join(dir, "nested")
+			if err := os.Mkdir(nested, 0700); err != nil {
+				t.Fatal(err)
+			}
+			want := filepath.Join(nested, "new.txt")
+			if err := os.WriteFile(want, []byte("new"), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			select {
+			case got := <-changes:
+				if got != want {
+					t.Errorf("changed %q, want %q", got, want)
+				}
+			case <-ctx.Done():
+				t.Fatalf("no change reported for %q", want)
+			}
+		})
+	}
+
+	t.Run("chunks", func(t *testing.T) {
+		dir := t.TempDir()
+		conn, err := anypb.New(&sourcespb.FilesystemWatcher{Directories: []string{dir}, DebounceMs: 50})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := Source{}
+		if err := s.Init(context.Background(), "test watcher", 0, 0, true, conn, 1); err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		chunksCh := make(chan *sources.Chunk, 10)
+		done := make(chan error, 1)
+		go func() { done <- s.Chunks(ctx, chunksCh) }()
+		time.Sleep(100 * time.Millisecond)
+
+		path := filepath.Join(dir, "config.env")
+		if err := os.WriteFile(path, []byte("TOKEN=abc"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case chunk := <-chunksCh:
+			if got := chunk.SourceMetadata.GetFilesystem().GetFile(); got != path {
+				t.Errorf("chunk file = %q, want %q", got, path)
+			}
+			if string(chunk.Data) != "TOKEN=abc" || chunk.SourceType != sourcespb.SourceType_SOURCE_TYPE_FILESYSTEM_WATCHER || !chunk.Verify {
+				t.Errorf("unexpected chunk %+v", chunk)
+			}
+		case <-ctx.Done():
+			t.Fatal("no chunk for the new file")
+		}
+
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Chunks() error = %v, want nil once cancelled", err)
+		}
+	})
+}
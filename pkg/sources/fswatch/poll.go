@@ -0,0 +1,57 @@
+package fswatch
+
+import (
+	"context"
+	"io/fs"
+	"time"
+)
+
+// pollInterval is how often the watched directories are walked for changes
+// where they can't be watched with inotify.
+const pollInterval = 2 * time.Second
+
+// fileState is what polling compares to tell that a file changed.
+type fileState struct {
+	modTime int64
+	size    int64
+}
+
+// poll calls changed with the path of each file created or changed in the
+// watched directories, walking them every interval, until ctx is done.
+func (s *Source) poll(ctx context.Context, interval time.Duration, changed func(path string)) error {
+	seen := s.snapshot()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		current := s.snapshot()
+		for path, state := range current {
+			if prev, ok := seen[path]; !ok || prev != state {
+				changed(path)
+			}
+		}
+		seen = current
+	}
+}
+
+// snapshot returns the state of each regular file in the watched directories.
+func (s *Source) snapshot() map[string]fileState {
+	files := map[string]fileState{}
+	for _, root := range s.dirs {
+		s.walk(root, root, func(path string, d fs.DirEntry) {
+			if !d.Type().IsRegular() {
+				return
+			}
+			info, err := d.Info()
+			if err != nil {
+				return
+			}
+			files[path] = fileState{modTime: info.ModTime().UnixNano(), size: info.Size()}
+		})
+	}
+	return files
+}
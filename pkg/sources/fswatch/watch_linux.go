@@ -0,0 +1,141 @@
+package fswatch
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"github.com/go-errors/errors"
+	"golang.org/x/sys/unix"
+)
+
+// watchMask selects the inotify events of directories that mean a file in
+// them was created or changed.
+const watchMask = unix.IN_CREATE | unix.IN_MODIFY | unix.IN_CLOSE_WRITE | unix.IN_MOVED_TO | unix.IN_ONLYDIR
+
+// watchedDir is a directory watched with inotify.
+type watchedDir struct {
+	root string
+	path string
+}
+
+// inotify watches the directories of a Source.
+type inotify struct {
+	s    *Source
+	fd   int
+	dirs map[int32]watchedDir
+}
+
+// watch calls changed with the path of each file created or changed in the
+// watched directories, with inotify, until ctx is done. Directories created in
+// them are watched too.
+func (s *Source) watch(ctx context.Context, changed func(path string)) error {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not start inotify", 0)
+	}
+	// A non-blocking file is read through the runtime's poller, so closing it
+	// stops a read that's waiting for events.
+	events := os.NewFile(uintptr(fd), "inotify")
+	defer events.Close()
+	go func() {
+		<-ctx.Done()
+		events.Close()
+	}()
+
+	w := &inotify{s: s, fd: fd, dirs: map[int32]watchedDir{}}
+	for _, root := range s.dirs {
+		if err := w.add(root, root, nil); err != nil {
+			return err
+		}
+	}
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := events.Read(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return errors.WrapPrefix(err, "could not read inotify events", 0)
+		}
+		for off := 0; off+unix.SizeofInotifyEvent <= n; {
+			event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			off += unix.SizeofInotifyEvent
+			end := off + int(event.Len)
+			if end > n {
+				break
+			}
+			name := strings.TrimRight(string(buf[off:end]), "\x00")
+			off = end
+			w.handle(event.Wd, event.Mask, name, changed)
+		}
+	}
+}
+
+// handle handles an event for the file name in the directory watched as wd.
+func (w *inotify) handle(wd int32, mask uint32, name string, changed func(path string)) {
+	if mask&unix.IN_Q_OVERFLOW != 0 {
+		w.s.Log().Error(nil, "inotify dropped events, changes may not be scanned; raise fs.inotify.max_queued_events")
+		return
+	}
+	dir, ok := w.dirs[wd]
+	if !ok {
+		return
+	}
+	if mask&unix.IN_IGNORED != 0 {
+		// The directory was removed.
+		delete(w.dirs, wd)
+		return
+	}
+	if name == "" {
+		return
+	}
+	path := filepath.Join(dir.path, name)
+	if w.s.ignore.ignored(dir.root, path) {
+		return
+	}
+	if mask&unix.IN_ISDIR != 0 {
+		if mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0 {
+			if err := w.add(dir.root, path, changed); err != nil {
+				w.s.Log().Error(err, "unable to watch directory", "path", path)
+			}
+		}
+		return
+	}
+	if mask&(unix.IN_MODIFY|unix.IN_CLOSE_WRITE|unix.IN_MOVED_TO) != 0 {
+		changed(path)
+	}
+}
+
+// add watches dir, in the watched directory root, and the directories under
+// it. If changed is set, it's called with the files already under dir, which
+// were created before it was watched.
+func (w *inotify) add(root, dir string, changed func(path string)) error {
+	var watchErr error
+	w.s.walk(root, dir, func(path string, d fs.DirEntry) {
+		if !d.IsDir() {
+			if changed != nil && d.Type().IsRegular() {
+				changed(path)
+			}
+			return
+		}
+		wd, err := unix.InotifyAddWatch(w.fd, path, watchMask)
+		if err != nil {
+			if errors.Is(err, unix.ENOSPC) {
+				err = errors.WrapPrefix(err, "too many directories to watch, raise fs.inotify.max_user_watches", 0)
+			}
+			if path == dir {
+				watchErr = errors.WrapPrefix(err, "could not watch "+path, 0)
+			} else {
+				w.s.Log().Error(err, "unable to watch directory", "path", path)
+			}
+			return
+		}
+		w.dirs[int32(wd)] = watchedDir{root: root, path: path}
+	})
+	return watchErr
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+// +build !linux
+
+package fswatch
+
+import (
+	"context"
+)
+
+// watch calls changed with the path of each file created or changed in the
+// watched directories until ctx is done. Without inotify, the directories are
+// polled.
+func (s *Source) watch(ctx context.Context, changed func(path string)) error {
+	return s.poll(ctx, pollInterval, changed)
+}
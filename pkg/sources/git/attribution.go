@@ -0,0 +1,113 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"sync"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
+)
+
+// ExtraData keys set by Attributor.
+const (
+	AuthorNameKey  = "git_author_name"
+	AuthorEmailKey = "git_author_email"
+	CommitTimeKey  = "git_commit_time"
+)
+
+// Attributor records who committed the findings in a repository's history,
+// and whether each secret is still in the tip of the scanned branch, so they
+// can be routed to whoever introduced them. The attribution is added to
+// findings' ExtraData rather than their source metadata, so it doesn't change
+// their finding IDs.
+type Attributor struct {
+	path string
+	head *HeadChecker
+
+	mu      sync.Mutex
+	commits map[string]*commitAuthor
+}
+
+type commitAuthor struct {
+	name, email, time string
+}
+
+// NewAttributor returns an Attributor for the repository at path. ref is the
+// scanned branch, or HEAD if it's empty.
+func NewAttributor(path, ref string) *Attributor {
+	return &Attributor{
+		path:    path,
+		head:    NewHeadChecker(path, ref),
+		commits: map[string]*commitAuthor{},
+	}
+}
+
+// Attribute adds the author of the commit r was found in, the commit's time in
+// UTC, and whether r's secret is still in the tip of the scanned branch, as
+// HeadChecker finds it, to r's ExtraData. Findings that weren't found in a
+// commit of the repository are left alone.
+func (a *Attributor) Attribute(ctx context.Context, r *detectors.ResultWithMetadata) {
+	meta := r.SourceMetadata.GetGit()
+	if meta == nil || meta.Commit == "" || meta.Commit == "unstaged" {
+		return
+	}
+	logger := log.FromContext(ctx)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	author, err := a.author(meta.Commit)
+	if err != nil {
+		logger.V(1).Info("could not attribute finding", "commit", meta.Commit, "error", err)
+		return
+	}
+
+	if r.ExtraData == nil {
+		r.ExtraData = map[string]string{}
+	}
+	r.ExtraData[AuthorNameKey] = author.name
+	r.ExtraData[AuthorEmailKey] = author.email
+	r.ExtraData[CommitTimeKey] = author.time
+	a.head.Check(ctx, r)
+}
+
+// author returns the author of commit, reading it from the repository the
+// first time it's asked for.
+func (a *Attributor) author(commit string) (*commitAuthor, error) {
+	if author, ok := a.commits[commit]; ok {
+		return author, nil
+	}
+	out, err := gitOutput(a.path, nil, "show", "--no-patch", "--format=%an%x00%ae%x00%aI", commit)
+	if err != nil {
+		return nil, err
+	}
+	fields := bytes.Split(bytes.TrimRight(out, "\n"), []byte{0})
+	if len(fields) != 3 {
+		return nil, errors.Errorf("unexpected git show output %q", out)
+	}
+	when, err := utcTime(string(fields[2]))
+	if err != nil {
+		return nil, err
+	}
+	author := &commitAuthor{name: string(fields[0]), email: string(fields[1]), time: when}
+	a.commits[commit] = author
+	return author, nil
+}
+
+// gitOutput runs git in the repository at path and returns its output. stdin
+// is the command's input, if it reads any.
+func gitOutput(path string, stdin io.Reader, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", path}, args...)...)
+	cmd.Stdin = stdin
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "git "+args[0]+": "+string(bytes.TrimSpace(stderr.Bytes())), 0)
+	}
+	return out, nil
+}
@@ -0,0 +1,125 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+// testRepo creates a repository in a temporary directory and returns its path.
+func testRepo(t *testing.T) string {
+	t.Helper()
+	if err := GitCmdCheck(); err != nil {
+		t.Skip(err)
+	}
+	dir := t.TempDir()
+	runGit(t, dir, "init", "--quiet")
+	runGit(t, dir, "config", "user.name", "Jane Doe")
+	runGit(t, dir, "config", "user.email", "jane@example.com")
+	return dir
+}
+
+// commitFile writes content to file and commits it, returning the commit.
+func commitFile(t *testing.T, dir, file, content, date string) string {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "add", file)
+	cmd := exec.Command("git", "-C", dir, "commit", "--quiet", "-m", "update "+file)
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %v: %s", err, out)
+	}
+	return strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD"))
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+	return string(out)
+}
+
+func gitFinding(commit, file, raw string) *detectors.ResultWithMetadata {
+	return &detectors.ResultWithMetadata{
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Git{
+				Git: &source_metadatapb.Git{Commit: commit, File: file, Line: 1},
+			},
+		},
+		Result: detectors.Result{Raw: []byte(raw)},
+	}
+}
+
+func TestAttributor_Attribute(t *testing.T) {
+	dir := testRepo(t)
+	first := commitFile(t, dir, "config.py", "key = 'AKIAOLDKEY'\n", "2022-01-02T03:04:05Z")
+	second := commitFile(t, dir, "config.py", "key = 'AKIANEWKEY'\n", "2022-02-03T04:05:06Z")
+
+	a := NewAttributor(dir, "")
+	tests := []struct {
+		name string
+		r    *detectors.ResultWithMetadata
+		want map[string]string
+	}{
+		{
+			name: "removed",
+			r:    gitFinding(first, "config.py", "AKIAOLDKEY"),
+			want: map[string]string{
+				AuthorNameKey:  "Jane Doe",
+				AuthorEmailKey: "jane@example.com",
+				CommitTimeKey:  "2022-01-02T03:04:05Z",
+				StillInHeadKey: "false",
+			},
+		},
+		{
+			name: "still in head",
+			r:    gitFinding(second, "config.py", "AKIANEWKEY"),
+			want: map[string]string{
+				AuthorNameKey:  "Jane Doe",
+				AuthorEmailKey: "jane@example.com",
+				CommitTimeKey:  "2022-02-03T04:05:06Z",
+				StillInHeadKey: "true",
+			},
+		},
+		{
+			// The secret isn't in deleted.py at HEAD, but it's still in
+			// config.py.
+			name: "deleted file",
+			r:    gitFinding(second, "deleted.py", "AKIANEWKEY"),
+			want: map[string]string{
+				AuthorNameKey:  "Jane Doe",
+				AuthorEmailKey: "jane@example.com",
+				CommitTimeKey:  "2022-02-03T04:05:06Z",
+				StillInHeadKey: "true",
+			},
+		},
+		{
+			name: "unstaged",
+			r:    gitFinding("unstaged", "config.py", "AKIANEWKEY"),
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a.Attribute(context.Background(), tt.r)
+			if len(tt.r.ExtraData) != len(tt.want) {
+				t.Fatalf("ExtraData = %v, want %v", tt.r.ExtraData, tt.want)
+			}
+			for k, v := range tt.want {
+				if tt.r.ExtraData[k] != v {
+					t.Errorf("ExtraData[%q] = %q, want %q", k, tt.r.ExtraData[k], v)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gitleaks/go-gitdiff/gitdiff"
+	"github.com/go-errors/errors"
+	"github.com/go-git/go-git/v5"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
+)
+
+// Backend is the implementation of git that repos are cloned and their
+// history is read with.
+type Backend string
+
+const (
+	// BackendAuto runs the git binary, and falls back to go-git when it isn't
+	// installed or fails.
+	BackendAuto Backend = "auto"
+	// BackendGit runs the git binary.
+	BackendGit Backend = "git"
+	// BackendGoGit uses go-git, which needs no git binary.
+	BackendGoGit Backend = "go-git"
+)
+
+type backendKey struct{}
+
+// WithBackend returns a context that carries backend. Repos are cloned and
+// scanned with it by CloneRepo and ScanCommits.
+func WithBackend(ctx context.Context, backend Backend) context.Context {
+	return context.WithValue(ctx, backendKey{}, backend)
+}
+
+// BackendFromContext returns the backend stored in ctx, or BackendAuto if
+// there is none.
+func BackendFromContext(ctx context.Context) Backend {
+	if backend, ok := ctx.Value(backendKey{}).(Backend); ok && backend != "" {
+		return backend
+	}
+	return BackendAuto
+}
+
+// resolveBackend returns the backend to start with in ctx, which is go-git
+// for BackendAuto if the git binary isn't installed.
+func resolveBackend(ctx context.Context) (Backend, error) {
+	backend := BackendFromContext(ctx)
+	switch backend {
+	case BackendGit:
+		return backend, GitCmdCheck()
+	case BackendAuto:
+		if GitCmdCheck() != nil {
+			return BackendGoGit, nil
+		}
+	}
+	return backend, nil
+}
+
+// goGitClone clones the repo at gitUrl, authenticated with userInfo, into a
+// temporary directory with go-git. It clones the full history of every
+// branch, so it has no options like those given to git clone. go-git can't
+// clone through the proxy in ctx, so repos aren't cloned when one is set.
+func goGitClone(ctx context.Context, userInfo *url.Userinfo, gitUrl string) (clonePath string, repo *git.Repository, err error) {
+	if ProxyFromContext(ctx) != nil {
+		safeUrl, _ := stripPassword(gitUrl)
+		return "", nil, fmt.Errorf("could not clone repo %s with go-git: cloning through a proxy requires git", safeUrl)
+	}
+	clonePath, err = ioutil.TempDir(os.TempDir(), "trufflehog")
+	if err != nil {
+		return "", nil, errors.New(err)
+	}
+	defer CleanOnError(&err, clonePath)
+
+	options := &git.CloneOptions{URL: gitUrl}
+	if userInfo != nil {
+		password, _ := userInfo.Password()
+		options.Auth = &githttp.BasicAuth{Username: userInfo.Username(), Password: password}
+	}
+	repo, err = git.PlainCloneContext(ctx, clonePath, false, options)
+	if err != nil {
+		safeUrl, _ := stripPassword(gitUrl)
+		return "", nil, fmt.Errorf("could not clone repo %s with go-git: %w", safeUrl, err)
+	}
+	return clonePath, repo, nil
+}
+
+// logOptions are the commits a repo's log is read from.
+type logOptions struct {
+	// head is the commit to read the log from, rather than every branch.
+	head string
+	// excludeRemotes are remotes whose branches' commits are left out.
+	excludeRemotes []string
+	// tags is whether the messages of annotated tags are read too.
+	tags bool
+}
+
+// args returns the arguments of git log for the options.
+func (o logOptions) args() []string {
+	var args []string
+	if o.head != "" {
+		args = append(args, o.head)
+	} else {
+		args = append(args, "--full-history", "--all")
+	}
+	if len(o.excludeRemotes) > 0 {
+		args = append(args, "--not")
+		for _, remote := range o.excludeRemotes {
+			args = append(args, "--remotes="+remote)
+		}
+	}
+	return args
+}
+
+// readLog reads the log of a repo, as git log -p -U0 prints it, parsed into
+// files. The returned function waits for the log to finish, once files is
+// closed, and returns the error it failed with, if any. The log stops early
+// if ctx is canceled.
+type readLog func(ctx context.Context) (files <-chan *gitdiff.File, wait func() error, err error)
+
+// nativeCommand returns a command running git with args in the repo at path.
+func nativeCommand(ctx context.Context, path string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", filepath.Clean(path)}, args...)...)
+	// Only GIT_DIR is set, so that repos owned by other users aren't refused
+	// as unsafe directories.
+	if absPath, err := filepath.Abs(path); err == nil {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("GIT_DIR=%s", filepath.Join(absPath, ".git")))
+	}
+	return cmd
+}
+
+// withFallback runs native, and then goGit if native fails with
+// BackendAuto, or only goGit with BackendGoGit.
+func withFallback(ctx context.Context, backend Backend, path, what string, native, goGit func() error) error {
+	if backend == BackendGoGit {
+		return goGit()
+	}
+	err := native()
+	if err == nil || backend != BackendAuto {
+		return err
+	}
+	log.FromContext(ctx).Error(err, "git failed to read the repo's "+what+", falling back to go-git", "path", path)
+	return goGit()
+}
+
+// nativeLog reads the log of the repo at path with the git binary.
+func nativeLog(path string, options logOptions) readLog {
+	return func(ctx context.Context) (<-chan *gitdiff.File, func() error, error) {
+		// Merges have no diff without -m, and their headers would be parsed
+		// as those of the next commit's files. The fuller format has the
+		// commits' committers.
+		cmd := nativeCommand(ctx, path, append([]string{"log", "-p", "-U0", "--no-merges", "--pretty=fuller"}, options.args()...)...)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, nil, err
+		}
+		wait := func() error {
+			// The parser stops reading at the first error in the log.
+			_, _ = io.Copy(ioutil.Discard, stdout)
+			if err := cmd.Wait(); err != nil {
+				return fmt.Errorf("'git log' failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+			}
+			return nil
+		}
+		files, err := gitdiff.Parse(stdout)
+		if err != nil {
+			_ = cmd.Process.Kill()
+			_ = wait()
+			return nil, nil, err
+		}
+		return files, wait, nil
+	}
+}
@@ -0,0 +1,214 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gitleaks/go-gitdiff/gitdiff"
+	"github.com/go-git/go-git/v5"
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// backendRepo returns a repo with branches, a merge, and a remote called
+// upstream whose commits are excluded from scans.
+func backendRepo(t *testing.T) string {
+	upstream := testRepo(t)
+	commitFile(t, upstream, "upstream.txt", "token=upstream\n", "2022-01-01T00:00:00Z")
+
+	dir := testRepo(t)
+	runGit(t, dir, "fetch", "--quiet", upstream, "+refs/heads/*:refs/remotes/upstream/*")
+	branch := strings.TrimSpace(runGit(t, upstream, "rev-parse", "--abbrev-ref", "HEAD"))
+	runGit(t, dir, "reset", "--quiet", "--hard", "refs/remotes/upstream/"+branch)
+	commitFile(t, dir, "a.txt", "one\ntoken=a\n", "2022-01-02T00:00:00Z")
+	commitFile(t, dir, "a.txt", "one\ntoken=b\nthree\n", "2022-01-03T00:00:00Z")
+	runGit(t, dir, "checkout", "--quiet", "-b", "feature")
+	commitFile(t, dir, "c.txt", "token=c\n", "2022-01-04T00:00:00Z")
+	runGit(t, dir, "checkout", "--quiet", "-")
+	commitFile(t, dir, "d.txt", "token=d\n", "2022-01-05T00:00:00Z")
+	runGit(t, dir, "merge", "--quiet", "--no-edit", "feature")
+	return dir
+}
+
+// scanBackend scans the commits of the repo at path with backend, returning
+// each chunk as [tag]file:line@commit timestamp: data. Chunks of messages
+// have no file.
+func scanBackend(t *testing.T, path string, backend Backend, options ...ScanOption) []string {
+	t.Helper()
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewGit(sourcespb.SourceType_SOURCE_TYPE_GIT, 0, 0, "test", false, 1,
+		func(file, email, commit, timestamp, _ string, line int64) *source_metadatapb.MetaData {
+			return &source_metadatapb.MetaData{
+				Data: &source_metadatapb.MetaData_Git{
+					Git: &source_metadatapb.Git{File: file, Commit: commit, Timestamp: timestamp, Line: line},
+				},
+			}
+		})
+	chunksChan := make(chan *sources.Chunk, 100)
+	ctx := WithBackend(context.Background(), backend)
+	if err := s.ScanCommits(ctx, repo, path, NewScanOptions(options...), chunksChan); err != nil {
+		t.Fatal(err)
+	}
+	close(chunksChan)
+	return chunkStrings(chunksChan)
+}
+
+func chunkStrings(chunksChan chan *sources.Chunk) []string {
+	var got []string
+	for chunk := range chunksChan {
+		git := chunk.SourceMetadata.GetGit()
+		got = append(got, fmt.Sprintf("%s%s:%d@%s %s: %q", git.Tag, git.File, git.Line, git.Commit[:7], git.Timestamp, chunk.Data))
+	}
+	sort.Strings(got)
+	return got
+}
+
+func TestGit_ScanCommitsBackends(t *testing.T) {
+	dir := backendRepo(t)
+	head := strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD~1"))
+	tests := []struct {
+		name    string
+		options []ScanOption
+		want    int
+	}{
+		{name: "all", want: 11},
+		{name: "exclude remote", options: []ScanOption{ScanOptionExcludeRemote("upstream")}, want: 9},
+		{name: "head", options: []ScanOption{ScanOptionHeadCommit(head)}, want: 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := scanBackend(t, dir, BackendGit, tt.options...)
+			if len(want) != tt.want {
+				t.Fatalf("git scanned %d chunks, want %d: %v", len(want), tt.want, want)
+			}
+			got := scanBackend(t, dir, BackendGoGit, tt.options...)
+			if diff := pretty.Compare(got, want); diff != "" {
+				t.Errorf("go-git scan diff: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCloneRepo_GoGit(t *testing.T) {
+	dir := backendRepo(t)
+	ctx := WithBackend(context.Background(), BackendGoGit)
+	path, _, err := CloneRepoUsingUnauthenticated(ctx, "file://"+dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+	// Clones have the original's local branches as remote-tracking ones.
+	if got, want := runGit(t, path, "rev-parse", "origin/feature"), runGit(t, dir, "rev-parse", "feature"); got != want {
+		t.Errorf("cloned origin/feature is %s, want %s", got, want)
+	}
+}
+
+// failingLog reads the first n files of read's log, then fails.
+func failingLog(read readLog, n int) readLog {
+	return func(ctx context.Context) (<-chan *gitdiff.File, func() error, error) {
+		files, wait, err := read(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		out := make(chan *gitdiff.File)
+		go func() {
+			defer close(out)
+			for file := range files {
+				if n > 0 {
+					out <- file
+				}
+				n--
+			}
+		}()
+		return out, func() error {
+			_ = wait()
+			return errors.New("broken pipe")
+		}, nil
+	}
+}
+
+func TestGit_scanLogFallback(t *testing.T) {
+	dir := backendRepo(t)
+	var want []string
+	for _, chunk := range scanBackend(t, dir, BackendGit) {
+		// Messages aren't read by scanLog.
+		if !strings.HasPrefix(chunk, ":") {
+			want = append(want, chunk)
+		}
+	}
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewGit(sourcespb.SourceType_SOURCE_TYPE_GIT, 0, 0, "test", false, 1,
+		func(file, email, commit, _, _ string, line int64) *source_metadatapb.MetaData {
+			return &source_metadatapb.MetaData{
+				Data: &source_metadatapb.MetaData_Git{
+					Git: &source_metadatapb.Git{File: file, Commit: commit, Line: line},
+				},
+			}
+		})
+	chunksChan := make(chan *sources.Chunk, 100)
+	scan := &logScan{options: NewScanOptions(), chunksChan: chunksChan, scanned: make(map[string]bool)}
+	ctx := context.Background()
+	if err := s.scanLog(ctx, scan, failingLog(nativeLog(dir, logOptions{}), 3)); err == nil {
+		t.Fatal("scanLog() succeeded reading a failing log")
+	}
+	if err := s.scanLog(ctx, scan, goGitLog(repo, logOptions{})); err != nil {
+		t.Fatal(err)
+	}
+	close(chunksChan)
+	// The commit being scanned when git failed is scanned again.
+	got := chunkStrings(chunksChan)
+	if len(got) != len(want)+1 {
+		t.Errorf("scanned %d chunks, want %d: %v", len(got), len(want)+1, got)
+	}
+}
+
+func TestGit_ScanCommitsMessages(t *testing.T) {
+	dir := testRepo(t)
+	first := commitFile(t, dir, "a.txt", "a\n", "2022-01-01T00:00:00Z")
+	runGit(t, dir, "commit", "--quiet", "--allow-empty", "-m", "rotate key", "-m", "token=empty")
+	empty := strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD"))
+	runGit(t, dir, "tag", "-a", "v1", "-m", "release token=tagged", first)
+	runGit(t, dir, "tag", "lightweight")
+
+	for _, backend := range []Backend{BackendGit, BackendGoGit} {
+		t.Run(string(backend), func(t *testing.T) {
+			var got []string
+			for _, chunk := range scanBackend(t, dir, backend) {
+				if !strings.Contains(chunk, ".txt:") {
+					got = append(got, chunk[:strings.Index(chunk, "@")+8]+chunk[strings.LastIndex(chunk, ": "):])
+				}
+			}
+			want := []string{
+				fmt.Sprintf(":1@%s: %q", first[:7], "update a.txt\n"),
+				fmt.Sprintf(":1@%s: %q", empty[:7], "rotate key\n\ntoken=empty\n"),
+				fmt.Sprintf("v1:1@%s: %q", first[:7], "release token=tagged\n"),
+			}
+			sort.Strings(want)
+			if diff := pretty.Compare(got, want); diff != "" {
+				t.Errorf("message chunks diff: (-got +want)\n%s", diff)
+			}
+
+			// Tags aren't scanned with a range of commits.
+			for _, chunk := range scanBackend(t, dir, backend, ScanOptionHeadCommit(empty)) {
+				if strings.HasPrefix(chunk, "v1") {
+					t.Errorf("scanned tag with a head commit: %s", chunk)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package git
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/go-errors/errors"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+const (
+	// scannedRemote is the remote whose remote-tracking branches, in the
+	// clones of an incremental CloneCache, are the commits earlier runs
+	// scanned. ScanCommits skips them.
+	scannedRemote = "trufflehog-scanned"
+	// scannedRefs is where a mirror records the branches scanned from it.
+	scannedRefs = "refs/trufflehog/scanned/"
+	// mirrorConfigKey is set, in the git config of a clone from a cache, to
+	// the mirror it was cloned from.
+	mirrorConfigKey = "trufflehog.mirror"
+)
+
+// CloneCache keeps a bare mirror of each repo cloned with it in a directory
+// between runs, so that scheduled scans fetch only what's new rather than
+// clone every repo again. Repos are still scanned from a temporary clone of
+// the mirror, which shares its objects. The branches scanned from each clone
+// are recorded in its mirror, and an incremental cache only scans commits
+// that weren't scanned by an earlier run. Runs sharing a directory shouldn't
+// overlap.
+type CloneCache struct {
+	dir         string
+	incremental bool
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// NewCloneCache returns a cache of the mirrors in dir, creating it if it
+// doesn't exist.
+func NewCloneCache(dir string, incremental bool) (*CloneCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, errors.WrapPrefix(err, "could not create clone cache", 0)
+	}
+	return &CloneCache{dir: dir, incremental: incremental, locks: make(map[string]*sync.Mutex)}, nil
+}
+
+// lock locks the mirror at path until the returned function is called.
+func (c *CloneCache) lock(path string) func() {
+	c.mu.Lock()
+	l, ok := c.locks[path]
+	if !ok {
+		l = &sync.Mutex{}
+		c.locks[path] = l
+	}
+	c.mu.Unlock()
+	l.Lock()
+	return l.Unlock
+}
+
+// mirrorPath returns where the mirror of the repo at safeURL is kept.
+func (c *CloneCache) mirrorPath(safeURL string) string {
+	sum := sha256.Sum256([]byte(safeURL))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:16])+".git")
+}
+
+// clone clones the repo at gitUrl, authenticated with userInfo, into a
+// temporary directory from its mirror, creating the mirror or fetching into
+// it first.
+func (c *CloneCache) clone(ctx context.Context, userInfo *url.Userinfo, gitUrl string) (clonePath string, repo *git.Repository, err error) {
+	safeURL, err := stripPassword(gitUrl)
+	if err != nil {
+		return "", nil, errors.WrapPrefix(err, "could not parse url", 0)
+	}
+	remoteURL, err := url.Parse(gitUrl)
+	if err != nil {
+		return "", nil, errors.WrapPrefix(err, "could not parse url", 0)
+	}
+	remoteURL.User = userInfo
+
+	mirror := c.mirrorPath(safeURL)
+	unlock := c.lock(mirror)
+	defer unlock()
+
+	if _, err := os.Stat(mirror); err == nil {
+		// The URL is given rather than stored in the mirror, since it may
+		// hold credentials.
+		if err := execGit(ctx, mirror, "fetch", "--quiet", "--prune", remoteURL.String(), "+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*"); err != nil {
+			return "", nil, fmt.Errorf("could not fetch %s into its mirror: %w", safeURL, err)
+		}
+	} else {
+		tmp := mirror + ".tmp"
+		_ = os.RemoveAll(tmp)
+		if err := execGit(ctx, "", "clone", "--quiet", "--bare", remoteURL.String(), tmp); err != nil {
+			_ = os.RemoveAll(tmp)
+			return "", nil, fmt.Errorf("could not mirror %s: %w", safeURL, err)
+		}
+		if err := execGit(ctx, tmp, "config", "remote.origin.url", safeURL); err != nil {
+			_ = os.RemoveAll(tmp)
+			return "", nil, err
+		}
+		if err := os.Rename(tmp, mirror); err != nil {
+			_ = os.RemoveAll(tmp)
+			return "", nil, errors.New(err)
+		}
+	}
+
+	clonePath, err = ioutil.TempDir(os.TempDir(), "trufflehog")
+	if err != nil {
+		return "", nil, errors.New(err)
+	}
+	defer CleanOnError(&err, clonePath)
+	if err = execGit(ctx, "", "clone", "--quiet", "--local", mirror, clonePath); err != nil {
+		return "", nil, fmt.Errorf("could not clone %s from its mirror: %w", safeURL, err)
+	}
+	// Links to findings are made from the origin's URL.
+	if err = execGit(ctx, clonePath, "remote", "set-url", "origin", safeURL); err != nil {
+		return "", nil, err
+	}
+	if err = execGit(ctx, clonePath, "config", mirrorConfigKey, mirror); err != nil {
+		return "", nil, err
+	}
+	if c.incremental {
+		refspec := fmt.Sprintf("+%s*:refs/remotes/%s/*", scannedRefs, scannedRemote)
+		if err = execGit(ctx, clonePath, "fetch", "--quiet", mirror, refspec); err != nil {
+			return "", nil, fmt.Errorf("could not fetch the branches scanned from %s: %w", safeURL, err)
+		}
+	}
+	repo, err = git.PlainOpen(clonePath)
+	if err != nil {
+		return "", nil, errors.WrapPrefix(err, "could not open cloned repo", 0)
+	}
+	return clonePath, repo, nil
+}
+
+// markScanned records the branches of the clone at path as scanned in the
+// mirror it was cloned from. Clones that aren't from a cache are ignored.
+func (c *CloneCache) markScanned(ctx context.Context, path string) error {
+	out, err := exec.CommandContext(ctx, "git", "-C", path, "config", "--get", mirrorConfigKey).Output()
+	if err != nil {
+		return nil
+	}
+	mirror := strings.TrimSpace(string(out))
+	unlock := c.lock(mirror)
+	defer unlock()
+	return execGit(ctx, path, "push", "--quiet", "--force", mirror, "refs/remotes/origin/*:"+scannedRefs+"*")
+}
+
+// hasRemoteBranches reports whether repo has remote-tracking branches of the
+// remote called name.
+func hasRemoteBranches(repo *git.Repository, name string) bool {
+	refs, err := repo.References()
+	if err != nil {
+		return false
+	}
+	defer refs.Close()
+	prefix := "refs/remotes/" + name + "/"
+	found := false
+	_ = refs.ForEach(func(ref *plumbing.Reference) error {
+		if strings.HasPrefix(ref.Name().String(), prefix) {
+			found = true
+			return storer.ErrStop
+		}
+		return nil
+	})
+	return found
+}
+
+// execGit runs git with args in the repo at dir, or the working directory if
+// dir is empty, through the proxy in ctx if there's one. Its output isn't
+// returned, since it may hold credentials.
+func execGit(ctx context.Context, dir string, args ...string) error {
+	command := args[0]
+	args = append(proxyArgs(ctx), args...)
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	if err := exec.CommandContext(ctx, "git", args...).Run(); err != nil {
+		return fmt.Errorf("'git %s' failed: %w", command, err)
+	}
+	return nil
+}
+
+type cloneCacheKey struct{}
+
+// WithCloneCache returns a context that carries cache. Repos cloned with the
+// context by CloneRepo are cloned from their mirrors in it.
+func WithCloneCache(ctx context.Context, cache *CloneCache) context.Context {
+	return context.WithValue(ctx, cloneCacheKey{}, cache)
+}
+
+// CloneCacheFromContext returns the cache stored in ctx, or nil if there is
+// none.
+func CloneCacheFromContext(ctx context.Context) *CloneCache {
+	cache, _ := ctx.Value(cloneCacheKey{}).(*CloneCache)
+	return cache
+}
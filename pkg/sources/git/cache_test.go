@@ -0,0 +1,120 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestCloneCache(t *testing.T) {
+	origin := testRepo(t)
+	first := commitFile(t, origin, "a.txt", "token=first\n", "2022-01-01T00:00:00Z")
+
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	cache, err := NewCloneCache(cacheDir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithCloneCache(context.Background(), cache)
+	s := NewGit(sourcespb.SourceType_SOURCE_TYPE_GIT, 0, 0, "test", false, 1,
+		func(file, email, commit, _, repository string, _ int64) *source_metadatapb.MetaData {
+			return &source_metadatapb.MetaData{
+				Data: &source_metadatapb.MetaData_Git{
+					Git: &source_metadatapb.Git{File: file, Commit: commit, Repository: repository},
+				},
+			}
+		})
+	// scan clones origin through the cache and returns the commits scanned.
+	scanWith := func(options *ScanOptions) []string {
+		t.Helper()
+		path, repo, err := CloneRepoUsingUnauthenticated(ctx, origin)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(path)
+		chunksChan := make(chan *sources.Chunk, 10)
+		if err := s.ScanRepo(ctx, repo, path, options, chunksChan); err != nil {
+			t.Fatal(err)
+		}
+		close(chunksChan)
+		var commits []string
+		for chunk := range chunksChan {
+			git := chunk.SourceMetadata.GetGit()
+			if git.Repository != origin {
+				t.Errorf("chunk of repository %q, want %q", git.Repository, origin)
+			}
+			// Commits' messages are scanned too.
+			if git.File == "" {
+				continue
+			}
+			commits = append(commits, git.Commit)
+		}
+		sort.Strings(commits)
+		return commits
+	}
+	scan := func() []string { return scanWith(NewScanOptions()) }
+
+	// Scans that leave out commits or files don't record the branches as
+	// scanned.
+	filtered := []*ScanOptions{
+		NewScanOptions(ScanOptionCommitFilter(&CommitFilter{Paths: []string{"b.txt"}})),
+		NewScanOptions(ScanOptionFilter(excludeFilter(t, `\.txt$`))),
+	}
+	for _, options := range filtered {
+		if got := scanWith(options); len(got) != 0 {
+			t.Errorf("filtered scan scanned %v", got)
+		}
+	}
+
+	if diff := pretty.Compare(scan(), []string{first}); diff != "" {
+		t.Errorf("first scan diff: (-got +want)\n%s", diff)
+	}
+	mirrors, _ := filepath.Glob(filepath.Join(cacheDir, "*.git"))
+	if len(mirrors) != 1 {
+		t.Fatalf("cache has mirrors %v, want 1", mirrors)
+	}
+
+	if got := scan(); len(got) != 0 {
+		t.Errorf("scan without new commits scanned %v", got)
+	}
+
+	second := commitFile(t, origin, "b.txt", "token=second\n", "2022-01-02T00:00:00Z")
+	runGit(t, origin, "checkout", "--quiet", "-b", "feature")
+	third := commitFile(t, origin, "c.txt", "token=third\n", "2022-01-03T00:00:00Z")
+	want := []string{second, third}
+	sort.Strings(want)
+	if diff := pretty.Compare(scan(), want); diff != "" {
+		t.Errorf("scan of new commits diff: (-got +want)\n%s", diff)
+	}
+
+	// A cache that isn't incremental scans everything, from the same mirror.
+	cache.incremental = false
+	want = []string{first, second, third}
+	sort.Strings(want)
+	if diff := pretty.Compare(scan(), want); diff != "" {
+		t.Errorf("full scan diff: (-got +want)\n%s", diff)
+	}
+}
+
+// excludeFilter returns a path filter excluding the paths pattern matches.
+func excludeFilter(t *testing.T, pattern string) *common.Filter {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "exclude.txt")
+	if err := os.WriteFile(path, []byte(pattern+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	filter, err := common.FilterFromFiles("", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filter
+}
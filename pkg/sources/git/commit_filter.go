@@ -0,0 +1,127 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// CommitFilter limits the commits scanned to those by some authors or
+// committers, in a range of dates, or changing some paths. Its zero value
+// passes every commit.
+type CommitFilter struct {
+	// Authors are parts of the name or email of the commits' authors, any
+	// of which passes, ignoring case.
+	Authors []string
+	// Committers are like Authors, for the commits' committers.
+	Committers []string
+	// Since is the earliest author date of the commits, if it's set.
+	Since time.Time
+	// Until is the time the commits were authored before, if it's set.
+	Until time.Time
+	// Paths are the files the commits change, any of which passes. Each is
+	// a file or directory relative to the repo's root, or a glob. Globs
+	// without a slash, like *.env, match files in any directory. Only these
+	// files are scanned in the commits.
+	Paths []string
+}
+
+// dateLayout is the layout of dates given without a time.
+const dateLayout = "2006-01-02"
+
+// ParseDateRange parses the dates since and until given to a CommitFilter,
+// either of which can be empty. Each is a date, in UTC, or an RFC 3339 time.
+// A date given for until includes that day.
+func ParseDateRange(since, until string) (sinceTime, untilTime time.Time, err error) {
+	parse := func(s string) (time.Time, bool, error) {
+		if s == "" {
+			return time.Time{}, false, nil
+		}
+		if t, err := time.Parse(dateLayout, s); err == nil {
+			return t, true, nil
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("could not parse %q as a date like %s or a time like %s", s, dateLayout, time.RFC3339)
+		}
+		return t, false, nil
+	}
+	if sinceTime, _, err = parse(since); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	untilTime, untilDate, err := parse(until)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if untilDate {
+		untilTime = untilTime.AddDate(0, 0, 1)
+	}
+	if !sinceTime.IsZero() && !untilTime.IsZero() && !sinceTime.Before(untilTime) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%s is not before %s", since, until)
+	}
+	return sinceTime, untilTime, nil
+}
+
+// Empty reports whether f passes every commit.
+func (f *CommitFilter) Empty() bool {
+	return f == nil || (len(f.Authors) == 0 && len(f.Committers) == 0 && f.Since.IsZero() && f.Until.IsZero() && len(f.Paths) == 0)
+}
+
+// passCommit reports whether a commit by author and committer, authored at
+// date, passes f, leaving out its paths.
+func (f *CommitFilter) passCommit(author, committer identity, date time.Time) bool {
+	if f == nil {
+		return true
+	}
+	if !author.matches(f.Authors) || !committer.matches(f.Committers) {
+		return false
+	}
+	if !f.Since.IsZero() && date.Before(f.Since) {
+		return false
+	}
+	return f.Until.IsZero() || date.Before(f.Until)
+}
+
+// passPath reports whether the file at name passes f's paths.
+func (f *CommitFilter) passPath(name string) bool {
+	if f == nil || len(f.Paths) == 0 {
+		return true
+	}
+	for _, p := range f.Paths {
+		p = strings.Trim(p, "/")
+		if name == p || strings.HasPrefix(name, p+"/") {
+			return true
+		}
+		if matched, _ := path.Match(p, name); matched {
+			return true
+		}
+		// Globs without a directory, like *.env, match files in any.
+		if !strings.Contains(p, "/") && strings.ContainsAny(p, "*?[") {
+			if matched, _ := path.Match(p, path.Base(name)); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// identity is the name and email of an author or committer.
+type identity struct {
+	name, email string
+}
+
+// matches reports whether patterns is empty, or any of them is part of the
+// identity's name or email.
+func (i identity) matches(patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	s := strings.ToLower(i.name + " <" + i.email + ">")
+	for _, pattern := range patterns {
+		if strings.Contains(s, strings.ToLower(pattern)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestParseDateRange(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2022, 1, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		since, until string
+		wantSince    time.Time
+		wantUntil    time.Time
+		wantErr      bool
+	}{
+		{},
+		{since: "2022-01-02", wantSince: day(2)},
+		{until: "2022-01-02", wantUntil: day(3)},
+		{since: "2022-01-02", until: "2022-01-02", wantSince: day(2), wantUntil: day(3)},
+		{until: "2022-01-02T00:00:00Z", wantUntil: day(2)},
+		{since: "2022-01-03", until: "2022-01-02T00:00:00Z", wantErr: true},
+		{since: "last week", wantErr: true},
+	}
+	for _, tt := range tests {
+		since, until, err := ParseDateRange(tt.since, tt.until)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDateRange(%q, %q) error = %v, want error %v", tt.since, tt.until, err, tt.wantErr)
+			continue
+		}
+		if !since.Equal(tt.wantSince) || !until.Equal(tt.wantUntil) {
+			t.Errorf("ParseDateRange(%q, %q) = %v, %v, want %v, %v", tt.since, tt.until, since, until, tt.wantSince, tt.wantUntil)
+		}
+	}
+}
+
+func TestCommitFilter_passPath(t *testing.T) {
+	filter := &CommitFilter{Paths: []string{"config/", "*.env", "docs/*.md", "main.go"}}
+	for name, want := range map[string]bool{
+		"config/app.yaml":    true,
+		"config/a/b.yaml":    true,
+		"configs/app.yaml":   false,
+		"deploy/prod.env":    true,
+		"docs/README.md":     true,
+		"docs/api/README.md": false,
+		"main.go":            true,
+		"cmd/main.go":        false,
+	} {
+		if got := filter.passPath(name); got != want {
+			t.Errorf("passPath(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestGit_ScanCommitsCommitFilter(t *testing.T) {
+	dir := testRepo(t)
+	first := commitFile(t, dir, "a.txt", "token=a\n", "2022-01-01T00:00:00Z")[:7]
+	if err := os.MkdirAll(filepath.Join(dir, "config"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	second := commitFile(t, dir, "config/b.env", "token=b\n", "2022-01-02T12:00:00Z")[:7]
+	runGit(t, dir, "config", "user.name", "Bob Smith")
+	runGit(t, dir, "config", "user.email", "bob@example.com")
+	third := commitFile(t, dir, "c.txt", "token=c\n", "2022-01-03T00:00:00Z")[:7]
+
+	since, until, err := ParseDateRange("2022-01-02", "2022-01-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Chunks are wanted as file@commit.
+	tests := []struct {
+		name   string
+		filter CommitFilter
+		want   []string
+	}{
+		{name: "author", filter: CommitFilter{Authors: []string{"BOB"}}, want: []string{"c.txt@" + third, "@" + third}},
+		{name: "committer", filter: CommitFilter{Committers: []string{"nobody", "jane@"}}, want: []string{"a.txt@" + first, "@" + first, "config/b.env@" + second, "@" + second}},
+		{name: "dates", filter: CommitFilter{Since: since, Until: until}, want: []string{"config/b.env@" + second, "@" + second}},
+		{name: "paths", filter: CommitFilter{Paths: []string{"*.env"}}, want: []string{"config/b.env@" + second, "@" + second}},
+		{name: "none", filter: CommitFilter{Authors: []string{"jane"}, Since: since.AddDate(0, 0, 1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, backend := range []Backend{BackendGit, BackendGoGit} {
+				var got []string
+				for _, chunk := range scanBackend(t, dir, backend, ScanOptionCommitFilter(&tt.filter)) {
+					// Chunks of messages have no file.
+					at := strings.Index(chunk, "@")
+					got = append(got, chunk[:strings.Index(chunk, ":")]+chunk[at:at+8])
+				}
+				want := append([]string(nil), tt.want...)
+				sort.Strings(got)
+				sort.Strings(want)
+				if diff := pretty.Compare(got, want); diff != "" {
+					t.Errorf("%s scan diff: (-got +want)\n%s", backend, diff)
+				}
+			}
+		})
+	}
+}
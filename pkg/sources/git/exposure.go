@@ -0,0 +1,172 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
+)
+
+// ExtraData keys set by ExposureTracker. Times are RFC 3339 in UTC.
+const (
+	IntroducedCommitKey = "git_introduced_commit"
+	IntroducedAtKey     = "git_introduced_at"
+	RemovedCommitKey    = "git_removed_commit"
+	RemovedAtKey        = "git_removed_at"
+)
+
+// ExposureTracker records how long secrets were exposed in a repository: the
+// first commit on the scanned branch that put a secret in its file, and the
+// commit that last removed it, if it's no longer there. Every finding of the
+// same secret in the same file gets the same window.
+type ExposureTracker struct {
+	path string
+	ref  string
+
+	mu sync.Mutex
+	// history holds the commits on ref that changed each file, oldest first.
+	history map[string][]fileCommit
+	windows map[[2]string]*exposure
+}
+
+type fileCommit struct {
+	hash, time string
+}
+
+type exposure struct {
+	introduced, removed *fileCommit
+}
+
+// NewExposureTracker returns an ExposureTracker for the repository at path.
+// ref is the scanned branch, or HEAD if it's empty.
+func NewExposureTracker(path, ref string) *ExposureTracker {
+	return &ExposureTracker{
+		path:    path,
+		ref:     resolveRef(path, ref),
+		history: map[string][]fileCommit{},
+		windows: map[[2]string]*exposure{},
+	}
+}
+
+// Track adds r's exposure window to its ExtraData. Findings that weren't
+// found in a commit of the repository are left alone, and findings whose
+// secret isn't in the scanned branch's history of their file only get the
+// time of the commit they were found in.
+func (e *ExposureTracker) Track(ctx context.Context, r *detectors.ResultWithMetadata) {
+	meta := r.SourceMetadata.GetGit()
+	secret := bytes.TrimSpace(r.Raw)
+	if meta == nil || meta.Commit == "" || meta.Commit == "unstaged" || len(secret) == 0 {
+		return
+	}
+	// Findings in commit and tag messages have no file.
+	if meta.File == "" {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	window, err := e.window(meta.File, secret)
+	if err != nil {
+		log.FromContext(ctx).V(1).Info("could not find exposure window", "file", meta.File, "error", err)
+		return
+	}
+	if window.introduced == nil {
+		when, err := e.commitTime(meta.Commit)
+		if err != nil {
+			log.FromContext(ctx).V(1).Info("could not find exposure window", "commit", meta.Commit, "error", err)
+			return
+		}
+		window = &exposure{introduced: &fileCommit{hash: meta.Commit, time: when}}
+	}
+
+	if r.ExtraData == nil {
+		r.ExtraData = map[string]string{}
+	}
+	r.ExtraData[IntroducedCommitKey] = window.introduced.hash
+	r.ExtraData[IntroducedAtKey] = window.introduced.time
+	if window.removed != nil {
+		r.ExtraData[RemovedCommitKey] = window.removed.hash
+		r.ExtraData[RemovedAtKey] = window.removed.time
+	}
+}
+
+// window returns the exposure of secret in file, working it out from the
+// file's history the first time it's asked for.
+func (e *ExposureTracker) window(file string, secret []byte) (*exposure, error) {
+	hash := sha256.Sum256(secret)
+	key := [2]string{file, string(hash[:])}
+	if window, ok := e.windows[key]; ok {
+		return window, nil
+	}
+	history, err := e.fileHistory(file)
+	if err != nil {
+		return nil, err
+	}
+	window := &exposure{}
+	present := false
+	for i := range history {
+		content, err := fileAt(e.path, history[i].hash, file)
+		if err != nil {
+			return nil, err
+		}
+		contains := bytes.Contains(content, secret)
+		switch {
+		case contains && window.introduced == nil:
+			window.introduced = &history[i]
+		case contains:
+			window.removed = nil
+		case present:
+			window.removed = &history[i]
+		}
+		present = contains
+	}
+	e.windows[key] = window
+	return window, nil
+}
+
+func (e *ExposureTracker) fileHistory(file string) ([]fileCommit, error) {
+	if history, ok := e.history[file]; ok {
+		return history, nil
+	}
+	out, err := gitOutput(e.path, nil, "log", "--reverse", "--format=%H%x00%aI", e.ref, "--", file)
+	if err != nil {
+		return nil, err
+	}
+	var history []fileCommit
+	for _, line := range bytes.Split(bytes.TrimSpace(out), []byte("\n")) {
+		hash, when, ok := bytes.Cut(line, []byte{0})
+		if !ok {
+			continue
+		}
+		t, err := utcTime(string(when))
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, fileCommit{hash: string(hash), time: t})
+	}
+	e.history[file] = history
+	return history, nil
+}
+
+func (e *ExposureTracker) commitTime(commit string) (string, error) {
+	out, err := gitOutput(e.path, nil, "show", "--no-patch", "--format=%aI", commit)
+	if err != nil {
+		return "", err
+	}
+	return utcTime(string(bytes.TrimSpace(out)))
+}
+
+// utcTime returns an RFC 3339 time from git in UTC.
+func utcTime(s string) (string, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return "", errors.WrapPrefix(err, "could not parse commit time", 0)
+	}
+	return t.UTC().Format(time.RFC3339), nil
+}
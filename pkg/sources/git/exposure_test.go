@@ -0,0 +1,73 @@
+package git
+
+import (
+	"context"
+	"testing"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+func TestExposureTracker_Track(t *testing.T) {
+	dir := testRepo(t)
+	first := commitFile(t, dir, "config.py", "key = 'AKIAOLDKEY'\n", "2022-01-02T03:04:05Z")
+	second := commitFile(t, dir, "config.py", "key = 'AKIANEWKEY'\n", "2022-02-03T04:05:06Z")
+	commitFile(t, dir, "settings.py", "debug = True\n", "2022-02-10T00:00:00Z")
+	third := commitFile(t, dir, "settings.py", "token = 'ghp_readded'\n", "2022-03-01T00:00:00Z")
+	fourth := commitFile(t, dir, "settings.py", "debug = False\n", "2022-04-01T00:00:00Z")
+	commitFile(t, dir, "settings.py", "token = 'ghp_readded'\n", "2022-05-01T00:00:00Z")
+
+	tests := []struct {
+		name string
+		r    *detectors.ResultWithMetadata
+		want map[string]string
+	}{
+		{
+			name: "removed",
+			r:    gitFinding(first, "config.py", "AKIAOLDKEY"),
+			want: map[string]string{
+				IntroducedCommitKey: first,
+				IntroducedAtKey:     "2022-01-02T03:04:05Z",
+				RemovedCommitKey:    second,
+				RemovedAtKey:        "2022-02-03T04:05:06Z",
+			},
+		},
+		{
+			name: "still present",
+			r:    gitFinding(second, "config.py", "AKIANEWKEY"),
+			want: map[string]string{
+				IntroducedCommitKey: second,
+				IntroducedAtKey:     "2022-02-03T04:05:06Z",
+			},
+		},
+		{
+			name: "removed and added again",
+			r:    gitFinding(fourth, "settings.py", "ghp_readded"),
+			want: map[string]string{
+				IntroducedCommitKey: third,
+				IntroducedAtKey:     "2022-03-01T00:00:00Z",
+			},
+		},
+		{
+			name: "not in file history",
+			r:    gitFinding(first, "config.py", "AKIAUNKNOWN"),
+			want: map[string]string{
+				IntroducedCommitKey: first,
+				IntroducedAtKey:     "2022-01-02T03:04:05Z",
+			},
+		},
+	}
+	tracker := NewExposureTracker(dir, "")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker.Track(context.Background(), tt.r)
+			if len(tt.r.ExtraData) != len(tt.want) {
+				t.Fatalf("ExtraData = %v, want %v", tt.r.ExtraData, tt.want)
+			}
+			for k, v := range tt.want {
+				if tt.r.ExtraData[k] != v {
+					t.Errorf("ExtraData[%q] = %q, want %q", k, tt.r.ExtraData[k], v)
+				}
+			}
+		})
+	}
+}
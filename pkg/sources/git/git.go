@@ -20,13 +20,11 @@ import (
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/google/go-github/v42/github"
 	"github.com/rs/zerolog"
-	log "github.com/sirupsen/logrus"
-	glgo "github.com/zricethezav/gitleaks/v8/detect/git"
 	"golang.org/x/oauth2"
 	"golang.org/x/sync/semaphore"
-	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
@@ -34,13 +32,8 @@ import (
 )
 
 type Source struct {
-	name     string
-	sourceId int64
-	jobId    int64
-	verify   bool
-	git      *Git
-	aCtx     context.Context
-	sources.Progress
+	sources.Base
+	git  *Git
 	conn *sourcespb.Git
 }
 
@@ -77,36 +70,24 @@ func (s *Source) Type() sourcespb.SourceType {
 	return sourcespb.SourceType_SOURCE_TYPE_GIT
 }
 
-func (s *Source) SourceID() int64 {
-	return s.sourceId
-}
-
-func (s *Source) JobID() int64 {
-	return s.jobId
-}
-
 // Init returns an initialized GitHub source.
 func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
-
-	s.aCtx = aCtx
-	s.name = name
-	s.sourceId = sourceId
-	s.jobId = jobId
-	s.verify = verify
+	s.InitBase(aCtx, s.Type(), name, jobId, sourceId, verify, concurrency)
 
 	var conn sourcespb.Git
-	err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{})
-	if err != nil {
-		errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	if err := sources.UnmarshalConnection(connection, &conn); err != nil {
+		return err
+	}
+	if err := validateConnection(&conn); err != nil {
+		return err
 	}
-
 	s.conn = &conn
 
 	if concurrency == 0 {
 		concurrency = runtime.NumCPU()
 	}
 
-	s.git = NewGit(s.Type(), s.jobId, s.sourceId, s.name, s.verify, concurrency,
+	s.git = NewGit(s.Type(), jobId, sourceId, name, verify, concurrency,
 		func(file, email, commit, repository, timestamp string, line int64) *source_metadatapb.MetaData {
 			return &source_metadatapb.MetaData{
 				Data: &source_metadatapb.MetaData_Git{
@@ -124,6 +105,17 @@ func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64,
 	return nil
 }
 
+// validateConnection checks that the connection has a credential and
+// something to scan.
+func validateConnection(conn *sourcespb.Git) error {
+	var v sources.Validator
+	v.Credential(conn.GetCredential() != nil, "basic_auth", "unauthenticated")
+	if len(conn.Directories) == 0 && len(conn.Repositories) == 0 {
+		v.Problemf("directories or repositories is required")
+	}
+	return v.Err()
+}
+
 // Chunks emits chunks of bytes over a channel.
 func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
 	switch cred := s.conn.GetCredential().(type) {
@@ -136,7 +128,7 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) err
 			if len(repoURI) == 0 {
 				continue
 			}
-			path, repo, err := CloneRepoUsingToken(token, repoURI, user)
+			path, repo, err := CloneRepoUsingToken(ctx, token, repoURI, user)
 			defer os.RemoveAll(path)
 			if err != nil {
 				return err
@@ -152,7 +144,7 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) err
 			if len(repoURI) == 0 {
 				continue
 			}
-			path, repo, err := CloneRepoUsingUnauthenticated(repoURI)
+			path, repo, err := CloneRepoUsingUnauthenticated(ctx, repoURI)
 			defer os.RemoveAll(path)
 			if err != nil {
 				return err
@@ -172,6 +164,18 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) err
 		if len(u) == 0 {
 			continue
 		}
+		if IsBundle(u) || IsPackDir(u) {
+			store, err := OpenObjects(u)
+			if err != nil {
+				return err
+			}
+			err = s.git.ScanObjects(ctx, store, u, NewScanOptions(), chunksChan)
+			store.Close()
+			if err != nil {
+				return err
+			}
+			continue
+		}
 		if !strings.HasSuffix(u, "git") {
 			//try paths instead of url
 			repo, err := RepoFromPath(u)
@@ -203,9 +207,31 @@ func CleanOnError(err *error, path string) {
 	}
 }
 
-func CloneRepo(userInfo *url.Userinfo, gitUrl string, args ...string) (clonePath string, repo *git.Repository, err error) {
-	if err = GitCmdCheck(); err != nil {
-		return
+// CloneRepo clones the repo at gitUrl into a temporary directory, with the
+// Backend in ctx, through the proxy in ctx if there's one. It's cloned from
+// its mirror in the CloneCache in ctx, if there's one, unless args are given
+// for git clone. go-git ignores args.
+func CloneRepo(ctx context.Context, userInfo *url.Userinfo, gitUrl string, args ...string) (string, *git.Repository, error) {
+	backend, err := resolveBackend(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	if backend == BackendGoGit {
+		return goGitClone(ctx, userInfo, gitUrl)
+	}
+	clonePath, repo, err := nativeClone(ctx, userInfo, gitUrl, args...)
+	if err != nil && backend == BackendAuto {
+		safeUrl, _ := stripPassword(gitUrl)
+		log.FromContext(ctx).Error(err, "git failed to clone the repo, falling back to go-git", "repo", safeUrl)
+		return goGitClone(ctx, userInfo, gitUrl)
+	}
+	return clonePath, repo, err
+}
+
+// nativeClone clones the repo at gitUrl with the git binary.
+func nativeClone(ctx context.Context, userInfo *url.Userinfo, gitUrl string, args ...string) (clonePath string, repo *git.Repository, err error) {
+	if cache := CloneCacheFromContext(ctx); cache != nil && len(args) == 0 {
+		return cache.clone(ctx, userInfo, gitUrl)
 	}
 	clonePath, err = ioutil.TempDir(os.TempDir(), "trufflehog")
 	if err != nil {
@@ -220,7 +246,7 @@ func CloneRepo(userInfo *url.Userinfo, gitUrl string, args ...string) (clonePath
 	}
 	cloneURL.User = userInfo
 
-	gitArgs := []string{"clone", cloneURL.String(), clonePath}
+	gitArgs := append(proxyArgs(ctx), "clone", cloneURL.String(), clonePath)
 	gitArgs = append(gitArgs, args...)
 	cloneCmd := exec.Command("git", gitArgs...)
 
@@ -233,11 +259,12 @@ func CloneRepo(userInfo *url.Userinfo, gitUrl string, args ...string) (clonePath
 		return "", nil, errors.New("clone command exited with no output")
 	}
 	if cloneCmd.ProcessState != nil && cloneCmd.ProcessState.ExitCode() != 0 {
+		logger := log.FromContext(ctx)
 		safeUrl, err := stripPassword(gitUrl)
 		if err != nil {
-			log.WithError(err).Errorf("failed to strip credentials from git url")
+			logger.Error(err, "failed to strip credentials from git url")
 		}
-		log.WithField("exit_code", cloneCmd.ProcessState.ExitCode()).WithField("repo", safeUrl).WithField("output", string(output)).Errorf("failed to clone repo")
+		logger.Error(nil, "failed to clone repo", "exit_code", cloneCmd.ProcessState.ExitCode(), "repo", safeUrl, "output", string(output))
 		return "", nil, fmt.Errorf("could not clone repo: %s", safeUrl)
 	}
 	repo, err = git.PlainOpen(clonePath)
@@ -249,14 +276,36 @@ func CloneRepo(userInfo *url.Userinfo, gitUrl string, args ...string) (clonePath
 }
 
 // CloneRepoUsingToken clones a repo using a provided token.
-func CloneRepoUsingToken(token, gitUrl, user string, args ...string) (string, *git.Repository, error) {
+func CloneRepoUsingToken(ctx context.Context, token, gitUrl, user string, args ...string) (string, *git.Repository, error) {
 	userInfo := url.UserPassword(user, token)
-	return CloneRepo(userInfo, gitUrl, args...)
+	return CloneRepo(ctx, userInfo, gitUrl, args...)
 }
 
 // CloneRepoUsingUnauthenticated clones a repo with no authentication required.
-func CloneRepoUsingUnauthenticated(url string, args ...string) (string, *git.Repository, error) {
-	return CloneRepo(nil, url, args...)
+func CloneRepoUsingUnauthenticated(ctx context.Context, url string, args ...string) (string, *git.Repository, error) {
+	return CloneRepo(ctx, nil, url, args...)
+}
+
+// FetchRemote fetches the branches of the repo at gitUrl into the clone at
+// path, as the remote-tracking branches of a remote called name, so that
+// they can be excluded from scans with ScanOptionExcludeRemote.
+func FetchRemote(ctx context.Context, path, name string, userInfo *url.Userinfo, gitUrl string) error {
+	fetchURL, err := url.Parse(gitUrl)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not parse url", 0)
+	}
+	fetchURL.User = userInfo
+
+	refspec := fmt.Sprintf("+refs/heads/*:refs/remotes/%s/*", name)
+	fetchArgs := append([]string{"-C", path}, proxyArgs(ctx)...)
+	fetchArgs = append(fetchArgs, "fetch", "--quiet", fetchURL.String(), refspec)
+	fetchCmd := exec.CommandContext(ctx, "git", fetchArgs...)
+	// The output isn't returned, since it may hold the credentials.
+	if err := fetchCmd.Run(); err != nil {
+		safeUrl, _ := stripPassword(gitUrl)
+		return fmt.Errorf("could not fetch %s: %w", safeUrl, err)
+	}
+	return nil
 }
 
 func GitCmdCheck() error {
@@ -266,99 +315,195 @@ func GitCmdCheck() error {
 	return nil
 }
 
-func (s *Git) ScanCommits(repo *git.Repository, path string, scanOptions *ScanOptions, chunksChan chan *sources.Chunk) error {
-	if err := GitCmdCheck(); err != nil {
+// ScanCommits scans the commits of the repo at path, and the messages of its
+// commits and annotated tags, reading its log with the Backend in ctx. If git
+// fails with BackendAuto, the commits it didn't scan are scanned with go-git.
+func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string, scanOptions *ScanOptions, chunksChan chan *sources.Chunk) error {
+	backend, err := resolveBackend(ctx)
+	if err != nil {
 		return err
 	}
-	if log.GetLevel() < log.DebugLevel {
+	logger := log.FromContext(ctx)
+	if !logger.V(1).Enabled() {
 		zerolog.SetGlobalLevel(zerolog.Disabled)
 	}
 
-	// Errors returned on errChan aren't blocking, so just ignore them.
-	errChan := make(chan error)
-	var gitLogArgs []string
-	if scanOptions.HeadHash != "" {
-		gitLogArgs = append(gitLogArgs, scanOptions.HeadHash)
+	options := logOptions{head: scanOptions.HeadHash}
+	if scanOptions.ExcludeRemote != "" {
+		options.excludeRemotes = append(options.excludeRemotes, scanOptions.ExcludeRemote)
+	}
+	// Clones from an incremental CloneCache have the commits earlier runs
+	// scanned as the branches of another remote.
+	if hasRemoteBranches(repo, scannedRemote) {
+		options.excludeRemotes = append(options.excludeRemotes, scannedRemote)
+	}
+
+	scan := &logScan{
+		options: scanOptions,
+		// get the URL metadata for reporting (may be empty)
+		urlMetadata: getSafeRemoteURL(repo, "origin"),
+		chunksChan:  chunksChan,
+		matched:     make(map[string]bool),
 	}
-	logOpts := glgo.LogOpts{
-		Args:           gitLogArgs,
-		DisableSafeDir: true,
+	if backend == BackendAuto {
+		scan.scanned = make(map[string]bool)
 	}
-	fileChan, err := glgo.GitLog(path, logOpts, errChan)
+	err = withFallback(ctx, backend, path, "history",
+		func() error { return s.scanLog(ctx, scan, nativeLog(path, options)) },
+		func() error { return s.scanLog(ctx, scan, goGitLog(repo, options)) })
 	if err != nil {
-		return errors.WrapPrefix(err, "could not open repo path", 0)
+		return err
 	}
-	// parser can return nil chan and nil error
-	if fileChan == nil {
-		return errors.New("nothing to scan")
+
+	// Tags are only scanned with every branch in full.
+	options.tags = scanOptions.HeadHash == "" && scanOptions.BaseHash == "" && scanOptions.MaxDepth <= 0
+	visit := s.messageVisitor(scan, backend == BackendAuto)
+	return withFallback(ctx, backend, path, "messages",
+		func() error { return nativeMessages(ctx, path, options, visit) },
+		func() error { return goGitMessages(ctx, repo, options, visit) })
+}
+
+// logScan is the state of a scan of a repo's log, which is kept when the
+// log is read again with another backend.
+type logScan struct {
+	options     *ScanOptions
+	urlMetadata string
+	chunksChan  chan *sources.Chunk
+
+	depth       int64
+	reachedBase bool
+	// scanned are the commits scanned in full, if they're kept. The commit
+	// being scanned when the log failed is scanned again.
+	scanned map[string]bool
+	current string
+	// matched are the commits with files that passed the CommitFilter, whose
+	// messages are scanned if it has paths.
+	matched map[string]bool
+}
+
+// passFile reports whether file, and the commit it's in, pass the scan's
+// CommitFilter.
+func (scan *logScan) passFile(file *gitdiff.File) bool {
+	filter := scan.options.CommitFilter
+	if filter.Empty() {
+		return true
+	}
+	header := file.PatchHeader
+	if !filter.passCommit(patchIdentity(header.Author), patchIdentity(header.Committer), header.AuthorDate) || !filter.passPath(file.NewName) {
+		return false
 	}
+	scan.matched[header.SHA] = true
+	return true
+}
 
-	// get the URL metadata for reporting (may be empty)
-	urlMetadata := getSafeRemoteURL(repo, "origin")
+// patchIdentity returns the identity of an author or committer of a patch.
+func patchIdentity(id *gitdiff.PatchIdentity) identity {
+	if id == nil {
+		return identity{}
+	}
+	return identity{name: id.Name, email: id.Email}
+}
 
-	var depth int64
-	var reachedBase = false
+// scanLog scans the files of the log read by read, until it ends or the
+// scan's options stop it.
+func (s *Git) scanLog(ctx context.Context, scan *logScan, read readLog) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	fileChan, wait, err := read(ctx)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not open repo path", 0)
+	}
+	// A commit being scanned when a log failed isn't counted as scanned.
+	scan.current = ""
+	stopped := false
 	for file := range fileChan {
-		if file == nil || file.PatchHeader == nil {
-			log.Debugf("file missing patch header, skipping")
+		if stopped {
 			continue
 		}
-		log.WithField("commit", file.PatchHeader.SHA).WithField("file", file.NewName).Trace("Scanning file from git")
-		if scanOptions.MaxDepth > 0 && depth >= scanOptions.MaxDepth {
-			log.Debugf("reached max depth")
-			break
-		}
-		depth++
-		if reachedBase && file.PatchHeader.SHA != scanOptions.BaseHash {
-			break
+		if !s.scanFile(ctx, scan, file) {
+			stopped = true
+			cancel()
 		}
-		if len(scanOptions.BaseHash) > 0 {
-			if file.PatchHeader.SHA == scanOptions.BaseHash {
-				log.Debugf("Reached base commit. Finishing scanning files.")
-				reachedBase = true
-			}
+	}
+	if err := wait(); err != nil && !stopped {
+		return err
+	}
+	return nil
+}
+
+// scanFile scans the lines added to a file in a commit, and reports whether
+// the scan should go on.
+func (s *Git) scanFile(ctx context.Context, scan *logScan, file *gitdiff.File) bool {
+	logger := log.FromContext(ctx)
+	if file == nil || file.PatchHeader == nil {
+		logger.V(1).Info("file missing patch header, skipping")
+		return true
+	}
+	if scan.scanned != nil && file.PatchHeader.SHA != scan.current {
+		if scan.current != "" {
+			scan.scanned[scan.current] = true
 		}
-		if !scanOptions.Filter.Pass(file.NewName) {
-			continue
+		scan.current = file.PatchHeader.SHA
+	}
+	if scan.scanned[file.PatchHeader.SHA] {
+		return true
+	}
+	scanOptions := scan.options
+	logger.V(2).Info("scanning file from git", "commit", file.PatchHeader.SHA, "file", file.NewName)
+	if scanOptions.MaxDepth > 0 && scan.depth >= scanOptions.MaxDepth {
+		logger.V(1).Info("reached max depth")
+		return false
+	}
+	scan.depth++
+	if scan.reachedBase && file.PatchHeader.SHA != scanOptions.BaseHash {
+		return false
+	}
+	if len(scanOptions.BaseHash) > 0 {
+		if file.PatchHeader.SHA == scanOptions.BaseHash {
+			logger.V(1).Info("reached base commit, finishing scanning files")
+			scan.reachedBase = true
 		}
+	}
+	if !scanOptions.Filter.Pass(file.NewName) || !scan.passFile(file) {
+		return true
+	}
 
-		fileName := file.NewName
-		if fileName == "" {
-			continue
-		}
-		var email, hash, when string
-		if file.PatchHeader != nil {
-			if file.PatchHeader.Author != nil {
-				email = file.PatchHeader.Author.Email
-			}
-			hash = file.PatchHeader.SHA
-			when = file.PatchHeader.AuthorDate.String()
+	fileName := file.NewName
+	if fileName == "" {
+		return true
+	}
+	var email, hash, when string
+	if file.PatchHeader != nil {
+		if file.PatchHeader.Author != nil {
+			email = file.PatchHeader.Author.Email
 		}
+		hash = file.PatchHeader.SHA
+		when = file.PatchHeader.AuthorDate.String()
+	}
 
-		for _, frag := range file.TextFragments {
-			var sb strings.Builder
-			newLineNumber := frag.NewPosition
-			for _, line := range frag.Lines {
-				if line.Op == gitdiff.OpAdd {
-					sb.WriteString(line.Line)
-				}
-			}
-			log.WithField("fragment", sb.String()).Trace("detecting fragment")
-			metadata := s.sourceMetadataFunc(fileName, email, hash, when, urlMetadata, newLineNumber)
-			chunksChan <- &sources.Chunk{
-				SourceName:     s.sourceName,
-				SourceID:       s.sourceID,
-				SourceType:     s.sourceType,
-				SourceMetadata: metadata,
-				Data:           []byte(sb.String()),
-				Verify:         s.verify,
+	for _, frag := range file.TextFragments {
+		var sb strings.Builder
+		newLineNumber := frag.NewPosition
+		for _, line := range frag.Lines {
+			if line.Op == gitdiff.OpAdd {
+				sb.WriteString(line.Line)
 			}
 		}
+		logger.V(2).Info("detecting fragment", "fragment", sb.String())
+		metadata := s.sourceMetadataFunc(fileName, email, hash, when, scan.urlMetadata, newLineNumber)
+		scan.chunksChan <- &sources.Chunk{
+			SourceName:     s.sourceName,
+			SourceID:       s.sourceID,
+			SourceType:     s.sourceType,
+			SourceMetadata: metadata,
+			Data:           []byte(sb.String()),
+			Verify:         s.verify,
+		}
 	}
-	return nil
+	return true
 }
 
-func (s *Git) ScanUnstaged(repo *git.Repository, scanOptions *ScanOptions, chunksChan chan *sources.Chunk) error {
+func (s *Git) ScanUnstaged(ctx context.Context, repo *git.Repository, scanOptions *ScanOptions, chunksChan chan *sources.Chunk) error {
 	// get the URL metadata for reporting (may be empty)
 	urlMetadata := getSafeRemoteURL(repo, "origin")
 
@@ -367,13 +512,13 @@ func (s *Git) ScanUnstaged(repo *git.Repository, scanOptions *ScanOptions, chunk
 	if err == nil || err == plumbing.ErrReferenceNotFound {
 		wt, err := repo.Worktree()
 		if err != nil {
-			log.WithError(err).Error("error obtaining repo worktree")
+			log.FromContext(ctx).Error(err, "error obtaining repo worktree")
 			return err
 		}
 
 		status, err := wt.Status()
 		if err != nil {
-			log.WithError(err).Error("error obtaining worktree status")
+			log.FromContext(ctx).Error(err, "error obtaining worktree status")
 			return err
 		}
 		for fh := range status {
@@ -407,22 +552,30 @@ func (s *Git) ScanUnstaged(repo *git.Repository, scanOptions *ScanOptions, chunk
 	return nil
 }
 
-func (s *Git) ScanRepo(_ context.Context, repo *git.Repository, repoPath string, scanOptions *ScanOptions, chunksChan chan *sources.Chunk) error {
+func (s *Git) ScanRepo(ctx context.Context, repo *git.Repository, repoPath string, scanOptions *ScanOptions, chunksChan chan *sources.Chunk) error {
 	start := time.Now().UnixNano()
-	if err := s.ScanCommits(repo, repoPath, scanOptions, chunksChan); err != nil {
+	if err := s.ScanCommits(ctx, repo, repoPath, scanOptions, chunksChan); err != nil {
 		return err
 	}
-	if err := s.ScanUnstaged(repo, scanOptions, chunksChan); err != nil {
+	if err := s.ScanUnstaged(ctx, repo, scanOptions, chunksChan); err != nil {
 		// https://github.com/src-d/go-git/issues/879
 		if strings.Contains(err.Error(), "object not found") {
-			log.WithError(err).Error("known issue: probably caused by a dangling reference in the repo")
+			log.FromContext(ctx).Error(err, "known issue: probably caused by a dangling reference in the repo")
 		} else {
 			return errors.New(err)
 		}
 		return err
 	}
 	scanTime := time.Now().UnixNano() - start
-	log.Debugf("Scanning complete. Scan time: %f", time.Duration(scanTime).Seconds())
+	log.FromContext(ctx).V(1).Info("scanning complete", "seconds", time.Duration(scanTime).Seconds())
+
+	// Only scans of every branch in full are recorded, so that incremental
+	// scans don't skip commits or files that weren't scanned.
+	if cache := CloneCacheFromContext(ctx); cache != nil && scanOptions.full() {
+		if err := cache.markScanned(ctx, repoPath); err != nil {
+			log.FromContext(ctx).Error(err, "could not record the scanned branches in the clone cache", "path", repoPath)
+		}
+	}
 	return nil
 }
 
@@ -432,6 +585,14 @@ func GenerateLink(repo, commit, file string) string {
 	if strings.Contains(repo, "bitbucket.org/") {
 		return repo[:len(repo)-4] + "/commits/" + commit
 	}
+	// Wiki pages are linked by their name and the revision, not their file.
+	if strings.HasSuffix(repo, ".wiki.git") {
+		link := strings.TrimSuffix(repo, ".wiki.git") + "/wiki"
+		if file == "" {
+			return link
+		}
+		return link + "/" + strings.TrimSuffix(file, filepath.Ext(file)) + "/" + commit
+	}
 	link := repo[:len(repo)-4] + "/blob/" + commit + "/" + file
 
 	if file == "" {
@@ -477,11 +638,14 @@ func TryAdditionalBaseRefs(repo *git.Repository, base string) (*plumbing.Hash, e
 }
 
 // PrepareRepoSinceCommit clones a repo starting at the given commitHash and returns the cloned repo path.
-func PrepareRepoSinceCommit(uriString, commitHash string) (string, bool, error) {
-	if commitHash == "" {
-		return PrepareRepo(uriString)
+func PrepareRepoSinceCommit(ctx context.Context, uriString, commitHash string) (string, bool, error) {
+	// A clone from a cache is fetched incrementally, so it's cheaper than a
+	// shallow clone, and the scan still stops at commitHash.
+	if commitHash == "" || CloneCacheFromContext(ctx) != nil {
+		return PrepareRepo(ctx, uriString)
 	}
 	// TODO: refactor with PrepareRepo to remove duplicated logic
+	logger := log.FromContext(ctx)
 
 	// The git CLI doesn't have an option to shallow clone starting at a commit
 	// hash, but it does have an option to shallow clone since a timestamp. If
@@ -494,13 +658,13 @@ func PrepareRepoSinceCommit(uriString, commitHash string) (string, bool, error)
 	}
 
 	if uri.Scheme == "file" || uri.Host != "github.com" {
-		return PrepareRepo(uriString)
+		return PrepareRepo(ctx, uriString)
 	}
 
 	uriPath := strings.TrimPrefix(uri.Path, "/")
 	owner, repoName, found := strings.Cut(uriPath, "/")
 	if !found {
-		return PrepareRepo(uriString)
+		return PrepareRepo(ctx, uriString)
 	}
 
 	client := github.NewClient(nil)
@@ -508,19 +672,19 @@ func PrepareRepoSinceCommit(uriString, commitHash string) (string, bool, error)
 		ts := oauth2.StaticTokenSource(
 			&oauth2.Token{AccessToken: token},
 		)
-		tc := oauth2.NewClient(context.TODO(), ts)
+		tc := oauth2.NewClient(ctx, ts)
 		client = github.NewClient(tc)
 	}
 
-	commit, _, err := client.Git.GetCommit(context.Background(), owner, repoName, commitHash)
+	commit, _, err := client.Git.GetCommit(ctx, owner, repoName, commitHash)
 	if err != nil {
-		return PrepareRepo(uriString)
+		return PrepareRepo(ctx, uriString)
 	}
 	var timestamp string
 	{
 		author := commit.GetAuthor()
 		if author == nil {
-			return PrepareRepo(uriString)
+			return PrepareRepo(ctx, uriString)
 		}
 		timestamp = author.GetDate().Format(time.RFC3339)
 	}
@@ -529,28 +693,29 @@ func PrepareRepoSinceCommit(uriString, commitHash string) (string, bool, error)
 	var path string
 	switch {
 	case uri.User != nil:
-		log.Debugf("Cloning remote Git repo with authentication")
+		logger.V(1).Info("cloning remote Git repo with authentication")
 		password, ok := uri.User.Password()
 		if !ok {
 			return "", true, fmt.Errorf("password must be included in Git repo URL when username is provided")
 		}
-		path, _, err = CloneRepoUsingToken(password, remotePath, uri.User.Username(), "--shallow-since", timestamp)
+		path, _, err = CloneRepoUsingToken(ctx, password, remotePath, uri.User.Username(), "--shallow-since", timestamp)
 		if err != nil {
 			return path, true, fmt.Errorf("failed to clone authenticated Git repo (%s): %s", remotePath, err)
 		}
 	default:
-		log.Debugf("Cloning remote Git repo without authentication")
-		path, _, err = CloneRepoUsingUnauthenticated(remotePath, "--shallow-since", timestamp)
+		logger.V(1).Info("cloning remote Git repo without authentication")
+		path, _, err = CloneRepoUsingUnauthenticated(ctx, remotePath, "--shallow-since", timestamp)
 		if err != nil {
 			return path, true, fmt.Errorf("failed to clone unauthenticated Git repo (%s): %s", remotePath, err)
 		}
 	}
-	log.Debugf("Git repo local path: %s", path)
+	logger.V(1).Info("cloned Git repo", "path", path)
 	return path, true, nil
 }
 
 // PrepareRepo clones a repo if possible and returns the cloned repo path.
-func PrepareRepo(uriString string) (string, bool, error) {
+func PrepareRepo(ctx context.Context, uriString string) (string, bool, error) {
+	logger := log.FromContext(ctx)
 	var path string
 	uri, err := url.Parse(uriString)
 	if err != nil {
@@ -566,18 +731,18 @@ func PrepareRepo(uriString string) (string, bool, error) {
 		remote = true
 		switch {
 		case uri.User != nil:
-			log.Debugf("Cloning remote Git repo with authentication")
+			logger.V(1).Info("cloning remote Git repo with authentication")
 			password, ok := uri.User.Password()
 			if !ok {
 				return "", remote, fmt.Errorf("password must be included in Git repo URL when username is provided")
 			}
-			path, _, err = CloneRepoUsingToken(password, remotePath, uri.User.Username())
+			path, _, err = CloneRepoUsingToken(ctx, password, remotePath, uri.User.Username())
 			if err != nil {
 				return path, remote, fmt.Errorf("failed to clone authenticated Git repo (%s): %s", remotePath, err)
 			}
 		default:
-			log.Debugf("Cloning remote Git repo without authentication")
-			path, _, err = CloneRepoUsingUnauthenticated(remotePath)
+			logger.V(1).Info("cloning remote Git repo without authentication")
+			path, _, err = CloneRepoUsingUnauthenticated(ctx, remotePath)
 			if err != nil {
 				return path, remote, fmt.Errorf("failed to clone unauthenticated Git repo (%s): %s", remotePath, err)
 			}
@@ -585,7 +750,7 @@ func PrepareRepo(uriString string) (string, bool, error) {
 	default:
 		return "", remote, fmt.Errorf("unsupported Git URI: %s", uriString)
 	}
-	log.Debugf("Git repo local path: %s", path)
+	logger.V(1).Info("prepared Git repo", "path", path)
 	return path, remote, nil
 }
 
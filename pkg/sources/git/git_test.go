@@ -4,11 +4,11 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"os"
 	"strings"
 	"testing"
 
 	"github.com/kylelemons/godebug/pretty"
-	log "github.com/sirupsen/logrus"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
@@ -123,7 +123,6 @@ func TestSource_Scan(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := Source{}
-			log.SetLevel(log.DebugLevel)
 
 			conn, err := anypb.New(tt.init.connection)
 			if err != nil {
@@ -179,6 +178,15 @@ func Test_generateLink(t *testing.T) {
 			},
 			want: "https://github.com/trufflesec-julian/confluence-go-api/commit/047b4a2ba42fc5b6c0bd535c5307434a666db5ec",
 		},
+		{
+			name: "test link gen - wiki",
+			args: args{
+				repo:   "https://github.com/trufflesec-julian/confluence-go-api.wiki.git",
+				commit: "047b4a2ba42fc5b6c0bd535c5307434a666db5ec",
+				file:   "Deploy-Notes.md",
+			},
+			want: "https://github.com/trufflesec-julian/confluence-go-api/wiki/Deploy-Notes/047b4a2ba42fc5b6c0bd535c5307434a666db5ec",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -240,7 +248,6 @@ func TestSource_Chunks_Integration(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := Source{}
-			log.SetLevel(log.DebugLevel)
 
 			conn, err := anypb.New(tt.init.connection)
 			if err != nil {
@@ -380,7 +387,6 @@ func TestSource_Chunks_Edge_Cases(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := Source{}
-			log.SetLevel(log.DebugLevel)
 
 			conn, err := anypb.New(tt.init.connection)
 			if err != nil {
@@ -441,7 +447,7 @@ func TestPrepareRepo(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		repo, b, err := PrepareRepo(tt.uri)
+		repo, b, err := PrepareRepo(context.Background(), tt.uri)
 		var repoLen bool
 		if len(repo) > 0 {
 			repoLen = true
@@ -457,6 +463,54 @@ func TestPrepareRepo(t *testing.T) {
 func BenchmarkPrepareRepo(b *testing.B) {
 	uri := "https://github.com/dustin-decker/secretsandstuff.git"
 	for i := 0; i < b.N; i++ {
-		_, _, _ = PrepareRepo(uri)
+		_, _, _ = PrepareRepo(context.Background(), uri)
+	}
+}
+
+func TestGit_ScanCommitsExcludeRemote(t *testing.T) {
+	ctx := context.Background()
+
+	upstream := testRepo(t)
+	commitFile(t, upstream, "a.txt", "token=upstream\n", "2022-01-01T00:00:00Z")
+
+	forkPath, fork, err := CloneRepoUsingUnauthenticated(ctx, upstream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(forkPath)
+	runGit(t, forkPath, "config", "user.name", "Jane Doe")
+	runGit(t, forkPath, "config", "user.email", "jane@example.com")
+	forkCommit := commitFile(t, forkPath, "b.txt", "token=fork\n", "2022-01-02T00:00:00Z")
+	// Commits made upstream after the fork are excluded too.
+	commitFile(t, upstream, "a.txt", "token=upstream2\n", "2022-01-03T00:00:00Z")
+
+	if err := FetchRemote(ctx, forkPath, "upstream", nil, upstream); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewGit(sourcespb.SourceType_SOURCE_TYPE_GIT, 0, 0, "test", false, 1,
+		func(file, email, commit, _, _ string, _ int64) *source_metadatapb.MetaData {
+			return &source_metadatapb.MetaData{
+				Data: &source_metadatapb.MetaData_Git{
+					Git: &source_metadatapb.Git{File: file, Commit: commit},
+				},
+			}
+		})
+	chunksChan := make(chan *sources.Chunk, 10)
+	if err := s.ScanCommits(ctx, fork, forkPath, NewScanOptions(ScanOptionExcludeRemote("upstream")), chunksChan); err != nil {
+		t.Fatal(err)
+	}
+	close(chunksChan)
+
+	var got []*source_metadatapb.Git
+	for chunk := range chunksChan {
+		// Commits' messages are scanned too.
+		if git := chunk.SourceMetadata.GetGit(); git.File != "" {
+			got = append(got, git)
+		}
+	}
+	want := []*source_metadatapb.Git{{File: "b.txt", Commit: forkCommit}}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("ScanCommits() diff: (-got +want)\n%s", diff)
 	}
 }
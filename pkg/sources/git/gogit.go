@@ -0,0 +1,350 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gitleaks/go-gitdiff/gitdiff"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// goGitLog reads the log of repo with go-git, as files like those parsed from
+// git log -p -U0. Commits are read newest first from each branch in turn, so
+// their order can differ from git's. Merge commits have no files, as with git.
+func goGitLog(repo *git.Repository, options logOptions) readLog {
+	return func(ctx context.Context) (<-chan *gitdiff.File, func() error, error) {
+		starts, err := logStarts(repo, options)
+		if err != nil {
+			return nil, nil, err
+		}
+		seen, err := remoteCommits(repo, options.excludeRemotes)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		files := make(chan *gitdiff.File)
+		done := make(chan error, 1)
+		go func() {
+			defer close(files)
+			done <- walkLog(ctx, starts, seen, func(file *gitdiff.File) error {
+				select {
+				case files <- file:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+		}()
+		return files, func() error { return <-done }, nil
+	}
+}
+
+// logStarts returns the commits the log of repo is read from.
+func logStarts(repo *git.Repository, options logOptions) ([]*object.Commit, error) {
+	if options.head != "" {
+		hash, err := repo.ResolveRevision(plumbing.Revision(options.head))
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve %s: %w", options.head, err)
+		}
+		commit, err := repo.CommitObject(*hash)
+		if err != nil {
+			return nil, err
+		}
+		return []*object.Commit{commit}, nil
+	}
+
+	refs, err := repo.References()
+	if err != nil {
+		return nil, err
+	}
+	var starts []*object.Commit
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		if commit := peelCommit(repo, ref.Hash()); commit != nil {
+			starts = append(starts, commit)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if head, err := repo.Head(); err == nil {
+		if commit := peelCommit(repo, head.Hash()); commit != nil {
+			starts = append(starts, commit)
+		}
+	}
+	return starts, nil
+}
+
+// peelCommit returns the commit at hash, or the commit tagged by the tag at
+// hash, or nil if there's no such commit.
+func peelCommit(repo *git.Repository, hash plumbing.Hash) *object.Commit {
+	if commit, err := repo.CommitObject(hash); err == nil {
+		return commit
+	}
+	tag, err := repo.TagObject(hash)
+	if err != nil {
+		return nil
+	}
+	commit, err := tag.Commit()
+	if err != nil {
+		return nil
+	}
+	return commit
+}
+
+// remoteCommits returns the commits of the branches of remotes.
+func remoteCommits(repo *git.Repository, remotes []string) (map[plumbing.Hash]bool, error) {
+	commits := make(map[plumbing.Hash]bool)
+	if len(remotes) == 0 {
+		return commits, nil
+	}
+	refs, err := repo.References()
+	if err != nil {
+		return nil, err
+	}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().String()
+		for _, remote := range remotes {
+			if !strings.HasPrefix(name, "refs/remotes/"+remote+"/") {
+				continue
+			}
+			commit := peelCommit(repo, ref.Hash())
+			if commit == nil {
+				return nil
+			}
+			return object.NewCommitPreorderIter(commit, commits, nil).ForEach(func(c *object.Commit) error {
+				commits[c.Hash] = true
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
+// walkLog calls fn with the files of the commits reachable from starts that
+// aren't in seen.
+func walkLog(ctx context.Context, starts []*object.Commit, seen map[plumbing.Hash]bool, fn func(*gitdiff.File) error) error {
+	return walkCommits(ctx, starts, seen, func(commit *object.Commit) error {
+		if commit.NumParents() > 1 {
+			return nil
+		}
+		files, err := commitFiles(ctx, commit)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			if err := fn(file); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// walkCommits calls fn with the commits reachable from starts that aren't in
+// seen, adding them to it.
+func walkCommits(ctx context.Context, starts []*object.Commit, seen map[plumbing.Hash]bool, fn func(*object.Commit) error) error {
+	for _, start := range starts {
+		err := object.NewCommitIterCTime(start, seen, nil).ForEach(func(commit *object.Commit) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			seen[commit.Hash] = true
+			return fn(commit)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commitFiles returns the files added, changed or renamed by commit. Binary
+// files have no fragments.
+func commitFiles(ctx context.Context, commit *object.Commit) ([]*gitdiff.File, error) {
+	var parentTree *object.Tree
+	if commit.NumParents() == 1 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, err
+		}
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := object.DiffTreeWithOptions(ctx, parentTree, tree, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	header := &gitdiff.PatchHeader{
+		SHA:           commit.Hash.String(),
+		Author:        &gitdiff.PatchIdentity{Name: commit.Author.Name, Email: commit.Author.Email},
+		AuthorDate:    gitDate(commit.Author.When),
+		Committer:     &gitdiff.PatchIdentity{Name: commit.Committer.Name, Email: commit.Committer.Email},
+		CommitterDate: gitDate(commit.Committer.When),
+	}
+	var files []*gitdiff.File
+	for _, change := range changes {
+		from, to, err := change.Files()
+		if err != nil {
+			return nil, err
+		}
+		if to == nil {
+			continue
+		}
+		file := &gitdiff.File{OldName: change.From.Name, NewName: change.To.Name, PatchHeader: header}
+		files = append(files, file)
+
+		if file.IsBinary, err = to.IsBinary(); err != nil || file.IsBinary {
+			continue
+		}
+		var oldLines []string
+		if from != nil {
+			if file.IsBinary, err = from.IsBinary(); err != nil || file.IsBinary {
+				continue
+			}
+			if oldLines, err = fileLines(from); err != nil {
+				return nil, err
+			}
+		}
+		newLines, err := fileLines(to)
+		if err != nil {
+			return nil, err
+		}
+		file.TextFragments = lineFragments(oldLines, newLines)
+	}
+	return files, nil
+}
+
+// gitDate returns t as it's parsed from how git prints it, so that its zone
+// is named as in dates parsed from git's output.
+func gitDate(t time.Time) time.Time {
+	if date, err := gitdiff.ParsePatchDate(t.Format(gitDateFormat)); err == nil {
+		return date
+	}
+	return t
+}
+
+// gitDateFormat is the default format of dates printed by git.
+const gitDateFormat = "Mon Jan 2 15:04:05 2006 -0700"
+
+// fileLines returns the lines of file, with their line endings.
+func fileLines(file *object.File) ([]string, error) {
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.SplitAfter(contents, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines, nil
+}
+
+// lineFragments returns the fragments of a diff of oldLines and newLines,
+// with no context lines.
+func lineFragments(oldLines, newLines []string) []*gitdiff.TextFragment {
+	var fragments []*gitdiff.TextFragment
+	var frag *gitdiff.TextFragment
+	var added []gitdiff.Line
+	var oldPos, newPos int64
+	flush := func() {
+		if frag == nil {
+			return
+		}
+		frag.Lines = append(frag.Lines, added...)
+		// As in git's hunk headers, a fragment with no lines on one side
+		// starts at the line before it.
+		if frag.OldLines > 0 {
+			frag.OldPosition++
+		}
+		if frag.NewLines > 0 {
+			frag.NewPosition++
+		}
+		fragments = append(fragments, frag)
+		frag, added = nil, nil
+	}
+	for _, d := range diffLines(oldLines, newLines) {
+		n := int64(len([]rune(d.Text)))
+		if d.Type == diffmatchpatch.DiffEqual {
+			flush()
+			oldPos += n
+			newPos += n
+			continue
+		}
+		if frag == nil {
+			frag = &gitdiff.TextFragment{OldPosition: oldPos, NewPosition: newPos}
+		}
+		for i := int64(0); i < n; i++ {
+			if d.Type == diffmatchpatch.DiffDelete {
+				frag.Lines = append(frag.Lines, gitdiff.Line{Op: gitdiff.OpDelete, Line: oldLines[oldPos]})
+				frag.OldLines++
+				frag.LinesDeleted++
+				oldPos++
+			} else {
+				added = append(added, gitdiff.Line{Op: gitdiff.OpAdd, Line: newLines[newPos]})
+				frag.NewLines++
+				frag.LinesAdded++
+				newPos++
+			}
+		}
+	}
+	flush()
+	return fragments
+}
+
+// maxDiffLines is the most unique lines a diff of two files can have. Files
+// with more are diffed as if every line changed.
+const maxDiffLines = 1 << 20
+
+// diffLines diffs oldLines and newLines, returning diffs whose texts have a
+// rune for each line. The lines are mapped to runes rather than diffed with
+// diffmatchpatch's DiffLinesToRunes, which can mistake the first line for a
+// changed one.
+func diffLines(oldLines, newLines []string) []diffmatchpatch.Diff {
+	ids := make(map[string]rune)
+	toRunes := func(lines []string) []rune {
+		runes := make([]rune, len(lines))
+		for i, line := range lines {
+			id, ok := ids[line]
+			if !ok {
+				// Surrogates are skipped, since they aren't valid in the
+				// strings the runes are compared as.
+				id = rune(len(ids) + 1)
+				if id >= 0xD800 {
+					id += 0x800
+				}
+				ids[line] = id
+			}
+			runes[i] = id
+		}
+		return runes
+	}
+	oldRunes, newRunes := toRunes(oldLines), toRunes(newLines)
+	if len(ids) > maxDiffLines {
+		return []diffmatchpatch.Diff{
+			{Type: diffmatchpatch.DiffDelete, Text: strings.Repeat("-", len(oldLines))},
+			{Type: diffmatchpatch.DiffInsert, Text: strings.Repeat("+", len(newLines))},
+		}
+	}
+	dmp := diffmatchpatch.New()
+	dmp.DiffTimeout = time.Hour
+	return dmp.DiffMainRunes(oldRunes, newRunes, false)
+}
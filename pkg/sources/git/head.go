@@ -0,0 +1,146 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
+)
+
+// StillInHeadKey is the ExtraData key HeadChecker, and so Attributor, sets to
+// "true" or "false".
+const StillInHeadKey = "git_still_in_head"
+
+// HeadChecker records whether secrets found in a repository's history are
+// still in the tip of the scanned branch, so teams can deal with live
+// exposures before ones that were already removed. A secret counts as still
+// there if it's in any file at the tip, not only the one it was found in.
+type HeadChecker struct {
+	path string
+	ref  string
+
+	mu sync.Mutex
+	// files holds the contents of files at the tip, and nil for files that
+	// aren't there.
+	files map[string][]byte
+	// secrets holds whether each secret searched for was found at the tip.
+	secrets map[string]bool
+}
+
+// NewHeadChecker returns a HeadChecker for the repository at path. ref is the
+// scanned branch, or HEAD if it's empty.
+func NewHeadChecker(path, ref string) *HeadChecker {
+	return &HeadChecker{
+		path:    path,
+		ref:     resolveRef(path, ref),
+		files:   map[string][]byte{},
+		secrets: map[string]bool{},
+	}
+}
+
+// Check sets StillInHeadKey in r's ExtraData. Findings that weren't found in
+// a commit of the repository are left alone, as are findings that can't be
+// checked.
+func (h *HeadChecker) Check(ctx context.Context, r *detectors.ResultWithMetadata) {
+	meta := r.SourceMetadata.GetGit()
+	secret := bytes.TrimSpace(r.Raw)
+	if meta == nil || meta.Commit == "" || meta.Commit == "unstaged" || len(secret) == 0 {
+		return
+	}
+	// Findings in commit and tag messages have no file.
+	if meta.File == "" {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	found, err := h.inFile(meta.File, secret)
+	if err == nil && !found {
+		found, err = h.inTree(secret)
+	}
+	if err != nil {
+		log.FromContext(ctx).V(1).Info("could not check whether finding is still at the tip", "ref", h.ref, "error", err)
+		return
+	}
+
+	if r.ExtraData == nil {
+		r.ExtraData = map[string]string{}
+	}
+	r.ExtraData[StillInHeadKey] = "false"
+	if found {
+		r.ExtraData[StillInHeadKey] = "true"
+	}
+}
+
+// resolveRef returns the revision of the branch ref in the repository at
+// path, which is HEAD if ref is empty. Branches that only exist on the origin
+// remote, as in a fresh clone, are found there.
+func resolveRef(path, ref string) string {
+	if ref == "" {
+		return "HEAD"
+	}
+	for _, candidate := range []string{ref, "refs/remotes/origin/" + ref} {
+		if _, err := gitOutput(path, nil, "rev-parse", "--verify", "--quiet", candidate+"^{commit}"); err == nil {
+			return candidate
+		}
+	}
+	return ref
+}
+
+// inFile reports whether secret is in file at the tip.
+func (h *HeadChecker) inFile(file string, secret []byte) (bool, error) {
+	content, ok := h.files[file]
+	if !ok {
+		var err error
+		if content, err = fileAt(h.path, h.ref, file); err != nil {
+			return false, err
+		}
+		h.files[file] = content
+	}
+	return bytes.Contains(content, secret), nil
+}
+
+// fileAt returns the contents of file at revision in the repository at path,
+// or nil if it isn't there.
+func fileAt(path, revision, file string) ([]byte, error) {
+	// ls-tree tells a file that isn't there apart from a failure to read the
+	// repository.
+	listed, err := gitOutput(path, nil, "ls-tree", "--name-only", revision, "--", file)
+	if err != nil || len(bytes.TrimSpace(listed)) == 0 {
+		return nil, err
+	}
+	return gitOutput(path, nil, "show", revision+":"+file)
+}
+
+// inTree reports whether secret is in any file at the tip. Secrets that span
+// lines are searched for by their longest line.
+func (h *HeadChecker) inTree(secret []byte) (bool, error) {
+	if found, ok := h.secrets[string(secret)]; ok {
+		return found, nil
+	}
+	pattern := secret
+	if bytes.Contains(secret, []byte("\n")) {
+		pattern = nil
+		for _, line := range bytes.Split(secret, []byte("\n")) {
+			if line = bytes.TrimSpace(line); len(line) > len(pattern) {
+				pattern = line
+			}
+		}
+	}
+	// The pattern is given on stdin so the secret doesn't show up in the
+	// process list. git grep exits with 1 if nothing matched.
+	_, err := gitOutput(h.path, strings.NewReader(string(pattern)+"\n"), "grep", "--quiet", "--fixed-strings", "-I", "-f", "-", h.ref, "--")
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		return false, err
+	}
+	found := err == nil
+	h.secrets[string(secret)] = found
+	return found, nil
+}
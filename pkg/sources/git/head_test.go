@@ -0,0 +1,40 @@
+package git
+
+import (
+	"context"
+	"testing"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+func TestHeadChecker_Check(t *testing.T) {
+	dir := testRepo(t)
+	first := commitFile(t, dir, "config.py", "key = 'AKIAOLDKEY'\nmoved = 'AKIAMOVEDKEY'\n", "2022-01-02T03:04:05Z")
+	commitFile(t, dir, "config.py", "key = 'AKIANEWKEY'\n", "2022-02-03T04:05:06Z")
+	commitFile(t, dir, "settings.py", "moved = 'AKIAMOVEDKEY'\n", "2022-02-03T04:05:06Z")
+	runGit(t, dir, "checkout", "--quiet", "-b", "rotated")
+	last := commitFile(t, dir, "config.py", "key = 'AKIAROTATEDKEY'\n", "2022-03-04T05:06:07Z")
+	runGit(t, dir, "checkout", "--quiet", "-")
+
+	tests := []struct {
+		name string
+		ref  string
+		r    *detectors.ResultWithMetadata
+		want string
+	}{
+		{name: "removed", r: gitFinding(first, "config.py", "AKIAOLDKEY"), want: "false"},
+		{name: "still in file", r: gitFinding(first, "config.py", "AKIANEWKEY"), want: "true"},
+		{name: "moved", r: gitFinding(first, "config.py", "AKIAMOVEDKEY"), want: "true"},
+		{name: "multiline", r: gitFinding(first, "config.py", "BEGIN\nmoved = 'AKIAMOVEDKEY'\nEND"), want: "true"},
+		{name: "other branch", ref: "rotated", r: gitFinding(last, "config.py", "AKIANEWKEY"), want: "false"},
+		{name: "unstaged", r: gitFinding("unstaged", "config.py", "AKIANEWKEY"), want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			NewHeadChecker(dir, tt.ref).Check(context.Background(), tt.r)
+			if got := tt.r.ExtraData[StillInHeadKey]; got != tt.want {
+				t.Errorf("ExtraData[%q] = %q, want %q", StillInHeadKey, got, tt.want)
+			}
+		})
+	}
+}
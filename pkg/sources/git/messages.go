@@ -0,0 +1,223 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/gitleaks/go-gitdiff/gitdiff"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// message is the message of a commit or annotated tag.
+type message struct {
+	// commit is the commit, or the commit the tag is of.
+	commit string
+	// tag is the name of the tag, if it's a tag's message.
+	tag string
+	// author is the commit's author, or the tag's tagger.
+	author    identity
+	committer identity
+	date      time.Time
+	text      string
+}
+
+// Separators of the fields and records printed by nativeMessages' commands.
+const (
+	fieldSeparator  = "\x1f"
+	recordSeparator = '\x1e'
+)
+
+// nativeMessages calls fn with the messages of the commits in the log of the
+// repo at path, and then of its annotated tags if options.tags is set, with
+// the git binary. Reading stops at the first error fn returns, which is
+// returned unless it's storer.ErrStop.
+func nativeMessages(ctx context.Context, path string, options logOptions, fn func(message) error) error {
+	args := append([]string{"log", "--date=default", "--format=%H%x1f%an%x1f%ae%x1f%cn%x1f%ce%x1f%ad%x1f%B%x1e"}, options.args()...)
+	err := readRecords(ctx, path, args, 7, func(fields []string) error {
+		date, _ := gitdiff.ParsePatchDate(fields[5])
+		return fn(message{
+			commit:    fields[0],
+			author:    identity{name: fields[1], email: fields[2]},
+			committer: identity{name: fields[3], email: fields[4]},
+			date:      date,
+			text:      fields[6],
+		})
+	})
+	if err == storer.ErrStop {
+		return nil
+	}
+	if err != nil || !options.tags {
+		return err
+	}
+
+	args = []string{"for-each-ref", "--format=%(objecttype)%1f%(refname:strip=2)%1f%(*objectname)%1f%(taggername)%1f%(taggeremail)%1f%(taggerdate)%1f%(contents)%1e", "refs/tags"}
+	return readRecords(ctx, path, args, 7, func(fields []string) error {
+		if fields[0] != "tag" {
+			return nil
+		}
+		date, _ := gitdiff.ParsePatchDate(fields[5])
+		tagger := identity{name: fields[3], email: strings.Trim(fields[4], "<>")}
+		return fn(message{commit: fields[2], tag: fields[1], author: tagger, date: date, text: fields[6]})
+	})
+}
+
+// readRecords runs git with args in the repo at path, and calls fn with the
+// n fields of each record it prints, until fn returns an error.
+func readRecords(ctx context.Context, path string, args []string, n int, fn func(fields []string) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cmd := nativeCommand(ctx, path, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	var fnErr error
+	reader := bufio.NewReader(stdout)
+	for fnErr == nil {
+		record, err := reader.ReadString(recordSeparator)
+		if err != nil {
+			break
+		}
+		// Records are printed on lines of their own.
+		record = strings.TrimPrefix(strings.TrimSuffix(record, string(recordSeparator)), "\n")
+		if fields := strings.SplitN(record, fieldSeparator, n); len(fields) == n {
+			fnErr = fn(fields)
+		}
+	}
+	if fnErr != nil {
+		cancel()
+	}
+	_, _ = io.Copy(ioutil.Discard, stdout)
+	if err := cmd.Wait(); err != nil && fnErr == nil {
+		return fmt.Errorf("'git %s' failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return fnErr
+}
+
+// goGitMessages is nativeMessages with go-git. Commits are read in the order
+// of goGitLog.
+func goGitMessages(ctx context.Context, repo *git.Repository, options logOptions, fn func(message) error) error {
+	starts, err := logStarts(repo, options)
+	if err != nil {
+		return err
+	}
+	seen, err := remoteCommits(repo, options.excludeRemotes)
+	if err != nil {
+		return err
+	}
+	err = walkCommits(ctx, starts, seen, func(commit *object.Commit) error {
+		return fn(message{
+			commit:    commit.Hash.String(),
+			author:    identity{name: commit.Author.Name, email: commit.Author.Email},
+			committer: identity{name: commit.Committer.Name, email: commit.Committer.Email},
+			date:      gitDate(commit.Author.When),
+			text:      commit.Message,
+		})
+	})
+	if err != nil || !options.tags {
+		return err
+	}
+
+	tags, err := repo.Tags()
+	if err != nil {
+		return err
+	}
+	return tags.ForEach(func(ref *plumbing.Reference) error {
+		tag, err := repo.TagObject(ref.Hash())
+		if err == plumbing.ErrObjectNotFound {
+			// Lightweight tags have no message.
+			return nil
+		} else if err != nil {
+			return err
+		}
+		tagger := identity{name: tag.Tagger.Name, email: tag.Tagger.Email}
+		return fn(message{commit: tag.Target.String(), tag: ref.Name().Short(), author: tagger, date: gitDate(tag.Tagger.When), text: tag.Message})
+	})
+}
+
+// messageVisitor returns a function that scans the messages it's called with,
+// in the order of the log, as limited by scan's options. If dedupe is set,
+// messages scanned already, such as by a log that failed, are skipped. Tags
+// are scanned if the commits they're of passed the CommitFilter.
+func (s *Git) messageVisitor(scan *logScan, dedupe bool) func(message) error {
+	var scanned map[string]bool
+	if dedupe {
+		scanned = make(map[string]bool)
+	}
+	var depth int64
+	reachedBase := false
+	filter := scan.options.CommitFilter
+	passed := make(map[string]bool)
+	return func(m message) error {
+		key := m.commit
+		if m.tag != "" {
+			key = "refs/tags/" + m.tag
+		}
+		if scanned[key] {
+			return nil
+		}
+		if m.tag == "" {
+			if reachedBase || (scan.options.MaxDepth > 0 && depth >= scan.options.MaxDepth) {
+				return storer.ErrStop
+			}
+			depth++
+			reachedBase = m.commit == scan.options.BaseHash
+		}
+		if scanned != nil {
+			scanned[key] = true
+		}
+		if !filter.Empty() {
+			if m.tag == "" && filter.passCommit(m.author, m.committer, m.date) && (len(filter.Paths) == 0 || scan.matched[m.commit]) {
+				passed[m.commit] = true
+			}
+			if !passed[m.commit] {
+				return nil
+			}
+		}
+
+		if strings.TrimSpace(m.text) == "" {
+			return nil
+		}
+		metadata := s.sourceMetadataFunc("", m.author.email, m.commit, m.date.String(), scan.urlMetadata, 1)
+		setTag(metadata, m.tag)
+		scan.chunksChan <- &sources.Chunk{
+			SourceName:     s.sourceName,
+			SourceID:       s.sourceID,
+			SourceType:     s.sourceType,
+			SourceMetadata: metadata,
+			Data:           []byte(m.text),
+			Verify:         s.verify,
+		}
+		return nil
+	}
+}
+
+// setTag sets the tag of metadata made by a Git's sourceMetadataFunc.
+func setTag(metadata *source_metadatapb.MetaData, tag string) {
+	switch data := metadata.GetData().(type) {
+	case *source_metadatapb.MetaData_Git:
+		data.Git.Tag = tag
+	case *source_metadatapb.MetaData_Github:
+		data.Github.Tag = tag
+	case *source_metadatapb.MetaData_Gitlab:
+		data.Gitlab.Tag = tag
+	}
+}
@@ -0,0 +1,241 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// bundleSignatures start the first line of git bundle files.
+var bundleSignatures = []string{"# v2 git bundle", "# v3 git bundle"}
+
+// IsBundle reports whether path is a git bundle file, as written by
+// git bundle create.
+func IsBundle(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	line, _ := bufio.NewReader(f).ReadString('\n')
+	for _, signature := range bundleSignatures {
+		if strings.TrimSpace(line) == signature {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPackDir reports whether path is a directory of packfiles, such as the
+// objects/pack directory of a repository, or an objects directory holding
+// one.
+func IsPackDir(path string) bool {
+	return len(packFiles(path)) > 0
+}
+
+// packFiles returns the packfiles in dir, or in its pack subdirectory.
+func packFiles(dir string) []string {
+	packs, _ := filepath.Glob(filepath.Join(dir, "*.pack"))
+	if len(packs) == 0 {
+		packs, _ = filepath.Glob(filepath.Join(dir, "pack", "*.pack"))
+	}
+	return packs
+}
+
+// ObjectStore is an object database read from bundles or packfiles, without
+// a working tree or refs.
+type ObjectStore struct {
+	storage *filesystem.Storage
+	dir     string
+}
+
+// OpenObjects indexes the packfile of the bundle file, or the packfiles of the
+// directory, at path into a temporary object database. Close removes it.
+// Incremental bundles, made with a range such as main~10..main, can only be
+// read if git didn't store their objects as deltas of objects they don't hold.
+func OpenObjects(path string) (*ObjectStore, error) {
+	var packs []string
+	if IsBundle(path) {
+		packs = []string{path}
+	} else if packs = packFiles(path); len(packs) == 0 {
+		return nil, errors.Errorf("%s is neither a git bundle nor a directory of packfiles", path)
+	}
+
+	dir, err := os.MkdirTemp("", "trufflehog-objects")
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not create object database", 0)
+	}
+	store := &ObjectStore{
+		storage: filesystem.NewStorage(osfs.New(dir), cache.NewObjectLRUDefault()),
+		dir:     dir,
+	}
+	for _, pack := range packs {
+		if err := store.addPack(pack); err != nil {
+			store.Close()
+			return nil, errors.WrapPrefix(err, "could not read packfile "+pack, 0)
+		}
+	}
+	return store, nil
+}
+
+// addPack indexes a packfile, or the packfile following the header of a
+// bundle.
+func (o *ObjectStore) addPack(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	if IsBundle(path) {
+		// The header lists prerequisite commits and refs, one per line, and
+		// ends with an empty line.
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return errors.WrapPrefix(err, "could not read bundle header", 0)
+			}
+			if line == "\n" {
+				break
+			}
+		}
+	}
+	return packfile.UpdateObjectStorage(o.storage, r)
+}
+
+// Close removes the object database.
+func (o *ObjectStore) Close() error {
+	return os.RemoveAll(o.dir)
+}
+
+// ScanObjects scans the blobs of an object database directly, rather than the
+// diffs of a repository's history. Each blob is scanned once, as the file it's
+// first found at in the oldest commit that holds it, so findings are reported
+// with the commit that introduced them. Blobs no commit refers to, such as
+// those of dropped stashes, are reported by their hash. Only the filter of
+// scanOptions applies, since there's no branch to limit the scan to.
+func (s *Git) ScanObjects(ctx context.Context, store *ObjectStore, name string, scanOptions *ScanOptions, chunksChan chan *sources.Chunk) error {
+	logger := log.FromContext(ctx)
+
+	iter, err := store.storage.IterEncodedObjects(plumbing.CommitObject)
+	if err != nil {
+		return err
+	}
+	var commits []*object.Commit
+	err = object.NewCommitIter(store.storage, iter).ForEach(func(c *object.Commit) error {
+		commits = append(commits, c)
+		return nil
+	})
+	if err != nil {
+		return errors.WrapPrefix(err, "could not read commits", 0)
+	}
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].Committer.When.Before(commits[j].Committer.When)
+	})
+
+	seen := map[plumbing.Hash]bool{}
+	for _, commit := range commits {
+		tree, err := commit.Tree()
+		if err != nil {
+			// Trees of commits that are only referred to, like the parents of
+			// a shallow bundle's commits, aren't in the pack.
+			logger.V(2).Info("skipping commit without tree", "commit", commit.Hash.String(), "error", err.Error())
+			continue
+		}
+		if err := s.scanTree(ctx, store, tree, commit, name, seen, scanOptions, chunksChan); err != nil {
+			return err
+		}
+	}
+
+	blobs, err := store.storage.IterEncodedObjects(plumbing.BlobObject)
+	if err != nil {
+		return err
+	}
+	return object.NewBlobIter(store.storage, blobs).ForEach(func(b *object.Blob) error {
+		if seen[b.Hash] {
+			return nil
+		}
+		metadata := s.sourceMetadataFunc(b.Hash.String(), "", "", "", name, 1)
+		return s.sendBlob(ctx, b, metadata, chunksChan)
+	})
+}
+
+// scanTree emits the blobs of a commit's tree that haven't been seen yet.
+// Blobs of incremental bundles that are only in their prerequisite commits
+// are skipped.
+func (s *Git) scanTree(ctx context.Context, store *ObjectStore, tree *object.Tree, commit *object.Commit, name string, seen map[plumbing.Hash]bool, scanOptions *ScanOptions, chunksChan chan *sources.Chunk) error {
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		path, entry, err := walker.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return errors.WrapPrefix(err, "could not read tree of commit "+commit.Hash.String(), 0)
+		}
+		if !entry.Mode.IsFile() || seen[entry.Hash] {
+			continue
+		}
+		seen[entry.Hash] = true
+		if !scanOptions.Filter.Pass(path) {
+			continue
+		}
+		blob, err := object.GetBlob(store.storage, entry.Hash)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		metadata := s.sourceMetadataFunc(path, commit.Author.Email, commit.Hash.String(), commit.Author.When.String(), name, 1)
+		if err := s.sendBlob(ctx, blob, metadata, chunksChan); err != nil {
+			return err
+		}
+	}
+}
+
+// sendBlob emits the contents of a blob as a chunk.
+func (s *Git) sendBlob(ctx context.Context, b *object.Blob, metadata *source_metadatapb.MetaData, chunksChan chan *sources.Chunk) error {
+	r, err := b.Reader()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not read blob "+b.Hash.String(), 0)
+	}
+
+	chunk := &sources.Chunk{
+		SourceName:     s.sourceName,
+		SourceID:       s.sourceID,
+		SourceType:     s.sourceType,
+		SourceMetadata: metadata,
+		Data:           data,
+		Verify:         s.verify,
+	}
+	select {
+	case chunksChan <- chunk:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,114 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestGit_ScanObjects(t *testing.T) {
+	dir := testRepo(t)
+	first := commitFile(t, dir, "a.txt", "token=first\n", "2022-01-01T00:00:00Z")
+	second := commitFile(t, dir, "a.txt", "token=second\n", "2022-01-02T00:00:00Z")
+	third := commitFile(t, dir, "b.txt", "password=hunter2\n", "2022-01-03T00:00:00Z")
+	dangling := strings.TrimSpace(runGitInput(t, dir, "dangling=secret\n", "hash-object", "-w", "--stdin"))
+
+	bundle := filepath.Join(t.TempDir(), "repo.bundle")
+	runGit(t, dir, "bundle", "create", bundle, "--all")
+
+	// A pack of every object, including the dangling blob.
+	packDir := filepath.Join(t.TempDir(), "objects", "pack")
+	if err := os.MkdirAll(packDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	objects := runGit(t, dir, "rev-list", "--objects", "--all")
+	runGitInput(t, dir, objects+dangling+"\n", "pack-objects", "--quiet", filepath.Join(packDir, "pack"))
+
+	history := []*source_metadatapb.Git{
+		{File: "a.txt", Commit: first, Email: "jane@example.com"},
+		{File: "a.txt", Commit: second, Email: "jane@example.com"},
+		{File: "b.txt", Commit: third, Email: "jane@example.com"},
+	}
+	tests := []struct {
+		name     string
+		path     string
+		wantData []string
+		wantMeta []*source_metadatapb.Git
+	}{
+		{
+			name:     "bundle",
+			path:     bundle,
+			wantData: []string{"token=first\n", "token=second\n", "password=hunter2\n"},
+			wantMeta: history,
+		},
+		{
+			name:     "pack directory",
+			path:     filepath.Dir(packDir),
+			wantData: []string{"token=first\n", "token=second\n", "password=hunter2\n", "dangling=secret\n"},
+			wantMeta: append(history, &source_metadatapb.Git{File: dangling}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !IsBundle(tt.path) && !IsPackDir(tt.path) {
+				t.Fatalf("%s is neither a bundle nor a pack directory", tt.path)
+			}
+			store, err := OpenObjects(tt.path)
+			if err != nil {
+				t.Fatalf("OpenObjects() error = %v", err)
+			}
+			defer store.Close()
+
+			s := NewGit(sourcespb.SourceType_SOURCE_TYPE_GIT, 0, 0, "test", false, 1,
+				func(file, email, commit, _, _ string, _ int64) *source_metadatapb.MetaData {
+					return &source_metadatapb.MetaData{
+						Data: &source_metadatapb.MetaData_Git{
+							Git: &source_metadatapb.Git{File: file, Email: email, Commit: commit},
+						},
+					}
+				})
+			chunksChan := make(chan *sources.Chunk, 10)
+			if err := s.ScanObjects(context.Background(), store, tt.path, NewScanOptions(), chunksChan); err != nil {
+				t.Fatalf("ScanObjects() error = %v", err)
+			}
+			close(chunksChan)
+
+			var gotData []string
+			var gotMeta []*source_metadatapb.Git
+			for chunk := range chunksChan {
+				gotData = append(gotData, string(chunk.Data))
+				gotMeta = append(gotMeta, chunk.SourceMetadata.GetGit())
+			}
+			if diff := pretty.Compare(gotData, tt.wantData); diff != "" {
+				t.Errorf("ScanObjects() data diff: (-got +want)\n%s", diff)
+			}
+			if diff := pretty.Compare(gotMeta, tt.wantMeta); diff != "" {
+				t.Errorf("ScanObjects() metadata diff: (-got +want)\n%s", diff)
+			}
+		})
+	}
+
+	if IsBundle(filepath.Join(dir, "a.txt")) || IsPackDir(dir) {
+		t.Error("a file and a repository were taken for a bundle or pack directory")
+	}
+}
+
+func runGitInput(t *testing.T, dir, input string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stdin = strings.NewReader(input)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git %v: %v", args, err)
+	}
+	return string(out)
+}
@@ -0,0 +1,31 @@
+package git
+
+import (
+	"context"
+	"net/url"
+)
+
+type proxyKey struct{}
+
+// WithProxy returns a context in which CloneRepo and FetchRemote connect
+// through the proxy at proxyURL, as returned by common.NewProxyURL, instead of
+// the one set in the environment or git's config. A nil URL leaves those.
+func WithProxy(ctx context.Context, proxyURL *url.URL) context.Context {
+	return context.WithValue(ctx, proxyKey{}, proxyURL)
+}
+
+// ProxyFromContext returns the proxy URL stored in ctx, or nil if there is
+// none.
+func ProxyFromContext(ctx context.Context) *url.URL {
+	proxyURL, _ := ctx.Value(proxyKey{}).(*url.URL)
+	return proxyURL
+}
+
+// proxyArgs returns the options that make git connect through the proxy in
+// ctx, if there's one.
+func proxyArgs(ctx context.Context) []string {
+	if proxyURL := ProxyFromContext(ctx); proxyURL != nil {
+		return []string{"-c", "http.proxy=" + proxyURL.String()}
+	}
+	return nil
+}
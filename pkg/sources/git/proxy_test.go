@@ -0,0 +1,74 @@
+package git
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCloneRepo_Proxy(t *testing.T) {
+	// The proxy records the URLs requested through it, and fails them.
+	var (
+		mu        sync.Mutex
+		requested []string
+	)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.String())
+		mu.Unlock()
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer proxy.Close()
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const repoURL = "http://git.example.invalid/acme/app.git"
+
+	tests := []struct {
+		name    string
+		backend Backend
+		cache   bool
+	}{
+		{name: "git", backend: BackendGit},
+		{name: "git with clone cache", backend: BackendGit, cache: true},
+		{name: "go-git", backend: BackendGoGit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mu.Lock()
+			requested = nil
+			mu.Unlock()
+			ctx := WithProxy(WithBackend(context.Background(), tt.backend), proxyURL)
+			if tt.cache {
+				cache, err := NewCloneCache(filepath.Join(t.TempDir(), "cache"), false)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ctx = WithCloneCache(ctx, cache)
+			}
+			_, _, err := CloneRepoUsingUnauthenticated(ctx, repoURL)
+			if err == nil {
+				t.Fatal("CloneRepo() through a failing proxy succeeded")
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if tt.backend == BackendGoGit {
+				// go-git can't use the proxy, so it mustn't connect at all.
+				if len(requested) != 0 || !strings.Contains(err.Error(), "proxy") {
+					t.Errorf("go-git requested %v through the proxy, error = %v", requested, err)
+				}
+				return
+			}
+			if len(requested) == 0 || requested[0] != repoURL+"/info/refs?service=git-upload-pack" {
+				t.Errorf("proxy requests = %v, want the repo's refs", requested)
+			}
+		})
+	}
+}
@@ -11,6 +11,11 @@ type ScanOptions struct {
 	HeadHash   string
 	MaxDepth   int64
 	LogOptions *git.LogOptions
+	// ExcludeRemote is the name of a remote whose branches' commits aren't
+	// scanned, such as the upstream of a fork.
+	ExcludeRemote string
+	// CommitFilter limits the commits scanned, and their messages.
+	CommitFilter *CommitFilter
 }
 
 type ScanOption func(*ScanOptions)
@@ -45,6 +50,18 @@ func ScanOptionLogOptions(logOptions *git.LogOptions) ScanOption {
 	}
 }
 
+func ScanOptionExcludeRemote(remote string) ScanOption {
+	return func(scanOptions *ScanOptions) {
+		scanOptions.ExcludeRemote = remote
+	}
+}
+
+func ScanOptionCommitFilter(filter *CommitFilter) ScanOption {
+	return func(scanOptions *ScanOptions) {
+		scanOptions.CommitFilter = filter
+	}
+}
+
 func NewScanOptions(options ...ScanOption) *ScanOptions {
 	scanOptions := &ScanOptions{
 		Filter:   common.FilterEmpty(),
@@ -60,3 +77,10 @@ func NewScanOptions(options ...ScanOption) *ScanOptions {
 	}
 	return scanOptions
 }
+
+// full reports whether scans with the options scan every file of every
+// commit.
+func (o *ScanOptions) full() bool {
+	return o.HeadHash == "" && o.BaseHash == "" && o.MaxDepth <= 0 &&
+		o.CommitFilter.Empty() && o.Filter.PassesAll()
+}
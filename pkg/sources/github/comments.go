@@ -0,0 +1,167 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v42/github"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// scanComments sends the titles and bodies of a repo's issues and pull
+// requests, the comments on them and the review comments on pull request
+// diffs. Each chunk links to the issue, pull request or comment it's from.
+func (s *Source) scanComments(ctx context.Context, apiClient *github.Client, repoURL string, chunksChan chan *sources.Chunk) error {
+	owner, repo, ok := repoOwnerName(repoURL)
+	if !ok {
+		// Gists have no issues.
+		return nil
+	}
+
+	// Pull requests are listed as issues too.
+	issueOpts := &github.IssueListByRepoOptions{
+		State: "all",
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+	for {
+		issues, res, err := apiClient.Issues.ListByRepo(ctx, owner, repo, issueOpts)
+		if err == nil {
+			defer res.Body.Close()
+		}
+		if handled := s.handleRateLimit(err, res); handled {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not list issues for repo %s: %w", repoURL, err)
+		}
+		for _, issue := range issues {
+			metadata := &source_metadatapb.Github{
+				Link:     issue.GetHTMLURL(),
+				Username: issue.GetUser().GetLogin(),
+			}
+			data := issue.GetTitle() + "\n" + issue.GetBody()
+			if err := s.sendComment(ctx, repoURL, metadata, issue.GetCreatedAt(), data, chunksChan); err != nil {
+				return err
+			}
+		}
+		if res.NextPage == 0 {
+			break
+		}
+		issueOpts.Page = res.NextPage
+	}
+
+	// Issue number 0 lists the comments of every issue and pull request.
+	commentOpts := &github.IssueListCommentsOptions{
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+	for {
+		comments, res, err := apiClient.Issues.ListComments(ctx, owner, repo, 0, commentOpts)
+		if err == nil {
+			defer res.Body.Close()
+		}
+		if handled := s.handleRateLimit(err, res); handled {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not list issue comments for repo %s: %w", repoURL, err)
+		}
+		for _, comment := range comments {
+			metadata := &source_metadatapb.Github{
+				Link:     comment.GetHTMLURL(),
+				Username: comment.GetUser().GetLogin(),
+			}
+			if err := s.sendComment(ctx, repoURL, metadata, comment.GetCreatedAt(), comment.GetBody(), chunksChan); err != nil {
+				return err
+			}
+		}
+		if res.NextPage == 0 {
+			break
+		}
+		commentOpts.Page = res.NextPage
+	}
+
+	reviewOpts := &github.PullRequestListCommentsOptions{
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+	for {
+		comments, res, err := apiClient.PullRequests.ListComments(ctx, owner, repo, 0, reviewOpts)
+		if err == nil {
+			defer res.Body.Close()
+		}
+		if handled := s.handleRateLimit(err, res); handled {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not list review comments for repo %s: %w", repoURL, err)
+		}
+		for _, comment := range comments {
+			metadata := &source_metadatapb.Github{
+				Link:     comment.GetHTMLURL(),
+				Username: comment.GetUser().GetLogin(),
+				Commit:   comment.GetCommitID(),
+				File:     comment.GetPath(),
+			}
+			if err := s.sendComment(ctx, repoURL, metadata, comment.GetCreatedAt(), comment.GetBody(), chunksChan); err != nil {
+				return err
+			}
+		}
+		if res.NextPage == 0 {
+			break
+		}
+		reviewOpts.Page = res.NextPage
+	}
+	return nil
+}
+
+// sendComment sends the text of an issue, pull request or comment of a repo.
+func (s *Source) sendComment(ctx context.Context, repoURL string, metadata *source_metadatapb.Github, created time.Time, data string, chunksChan chan *sources.Chunk) error {
+	metadata.Repository = sanitizer.UTF8(repoURL)
+	metadata.Username = sanitizer.UTF8(metadata.Username)
+	metadata.File = sanitizer.UTF8(metadata.File)
+	metadata.Timestamp = created.String()
+
+	chunk := &sources.Chunk{
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceType: s.Type(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Github{Github: metadata},
+		},
+		Data:   []byte(data),
+		Verify: s.verify,
+	}
+	select {
+	case chunksChan <- chunk:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// repoOwnerName returns the owner and name of the repo of a clone URL, such
+// as https://github.com/trufflesecurity/trufflehog.git. It isn't ok for the
+// URLs of gists, which are on gist.github.com, or under /gist/ on GitHub
+// Enterprise.
+func repoOwnerName(repoURL string) (owner, repo string, ok bool) {
+	u, err := url.Parse(repoURL)
+	if err != nil || strings.HasPrefix(u.Host, "gist.") {
+		return "", "", false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[0] == "gist" {
+		return "", "", false
+	}
+	return parts[0], strings.TrimSuffix(parts[1], ".git"), true
+}
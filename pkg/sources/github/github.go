@@ -2,9 +2,11 @@ package github
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math/rand"
 	"net/http"
+	"net/url"
 	"os"
 	"regexp"
 	"runtime"
@@ -17,11 +19,10 @@ import (
 	"github.com/bradleyfalzon/ghinstallation/v2"
 	"github.com/go-errors/errors"
 	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-logr/logr"
 	"github.com/google/go-github/v42/github"
-	log "github.com/sirupsen/logrus"
 	"golang.org/x/oauth2"
 	"golang.org/x/sync/semaphore"
-	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
@@ -30,6 +31,7 @@ import (
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/giturl"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
@@ -47,16 +49,28 @@ type Source struct {
 	httpClient *http.Client
 	aCtx       context.Context
 	sources.Progress
-	log    *log.Entry
+	log    logr.Logger
 	token  string
 	conn   *sourcespb.GitHub
 	jobSem *semaphore.Weighted
+
+	// forkUpstreams maps the forks found when ScanForkNetwork is set to the
+	// repos they're forks of.
+	forkUpstreams map[string]string
+	// httpOptions configure the TLS and proxy of the API clients.
+	httpOptions []common.HttpClientOption
+	// proxy is the proxy repos are cloned through, if one is set.
+	proxy *url.URL
 }
 
 // Ensure the Source satisfies the interface at compile time
 var _ sources.Source = (*Source)(nil)
 var endsWithGithub = regexp.MustCompile(`github\.com/?$`)
 
+// auditLogWindow is how far back the audit logs of organizations are
+// scanned when IncludeAuditLog is set.
+const auditLogWindow = 30 * 24 * time.Hour
+
 // Type returns the type of source.
 // It is used for matching source types in configuration and job input.
 func (s *Source) Type() sourcespb.SourceType {
@@ -95,7 +109,7 @@ func (s *Source) Token(ctx context.Context, installationClient *github.Client) (
 
 // Init returns an initialized GitHub source.
 func (s *Source) Init(aCtx context.Context, name string, jobID, sourceID int64, verify bool, connection *anypb.Any, concurrency int) error {
-	s.log = log.WithField("source", s.Type()).WithField("name", name)
+	s.log = log.FromContext(aCtx).WithValues("name", name)
 
 	s.aCtx = aCtx
 	s.name = name
@@ -104,23 +118,28 @@ func (s *Source) Init(aCtx context.Context, name string, jobID, sourceID int64,
 	s.verify = verify
 	s.jobSem = semaphore.NewWeighted(int64(concurrency))
 
-	s.httpClient = common.SaneHttpClient()
-
 	var conn sourcespb.GitHub
-	err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{})
-	if err != nil {
-		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	if err := sources.UnmarshalConnection(connection, &conn); err != nil {
+		return err
+	}
+	if err := validateConnection(&conn); err != nil {
+		return err
 	}
 	s.conn = &conn
 
+	httpOptions, err := common.NewHttpClientOptions(conn.Tls, conn.Proxy)
+	if err != nil {
+		return err
+	}
+	s.httpOptions = httpOptions
+	s.httpClient = common.RateLimitedHttpClient(httpOptions...)
+	if s.proxy, err = common.NewProxyURL(conn.Proxy); err != nil {
+		return err
+	}
+
 	s.repos = s.conn.Repositories
 	s.orgs = s.conn.Organizations
 
-	// Head or base should only be used with incoming webhooks
-	if (len(s.conn.Head) > 0 || len(s.conn.Base) > 0) && len(s.repos) != 1 {
-		return fmt.Errorf("cannot specify head or base with multiple repositories")
-	}
-
 	s.git = git.NewGit(s.Type(), s.JobID(), s.SourceID(), s.name, s.verify, runtime.NumCPU(),
 		func(file, email, commit, timestamp, repository string, line int64) *source_metadatapb.MetaData {
 			return &source_metadatapb.MetaData{
@@ -144,16 +163,17 @@ func (s *Source) Init(aCtx context.Context, name string, jobID, sourceID int64,
 func (s *Source) enumerateUnauthenticated(ctx context.Context) *github.Client {
 	apiClient := github.NewClient(s.httpClient)
 	if len(s.orgs) > 30 {
-		log.Warn("You may experience rate limiting when using the unauthenticated GitHub api. Consider using an authenticated scan instead.")
+		s.log.Info("You may experience rate limiting when using the unauthenticated GitHub api. Consider using an authenticated scan instead.")
 	}
 
 	for _, org := range s.orgs {
 		errOrg := s.addReposByOrg(ctx, apiClient, org)
 		errUser := s.addReposByUser(ctx, apiClient, org)
 		if errOrg != nil && errUser != nil {
-			log.WithError(errOrg).Error("error fetching repos for org or user: ", org)
+			s.log.Error(errOrg, "error fetching repos for org or user", "org", org)
 		}
 	}
+	s.addGistsByMembers(ctx, apiClient)
 	return apiClient
 }
 
@@ -165,7 +185,9 @@ func (s *Source) enumerateWithToken(ctx context.Context, apiEndpoint, token stri
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
-	tc := oauth2.NewClient(context.TODO(), ts)
+	tc := &http.Client{
+		Transport: &oauth2.Transport{Source: ts, Base: s.httpClient.Transport},
+	}
 
 	var err error
 	// If we're using public github, make a regular client.
@@ -194,7 +216,7 @@ func (s *Source) enumerateWithToken(ctx context.Context, apiEndpoint, token stri
 			errOrg := s.addReposByOrg(ctx, apiClient, org)
 			errUser := s.addReposByUser(ctx, apiClient, org)
 			if errOrg != nil && errUser != nil {
-				log.WithError(errOrg).Error("error fetching repos for org or user: ", org)
+				s.log.Error(errOrg, "error fetching repos for org or user", "org", org)
 			}
 		}
 	}
@@ -207,14 +229,14 @@ func (s *Source) enumerateWithToken(ctx context.Context, apiEndpoint, token stri
 	// If no scope was provided, enumerate them
 	if !specificScope {
 		if err := s.addReposByUser(ctx, apiClient, user.GetLogin()); err != nil {
-			log.WithError(err).Error("error fetching repos by user")
+			s.log.Error(err, "error fetching repos by user")
 		}
 		// Scan for orgs is default with a token. GitHub App enumerates the repositories
 		// that were assigned to it in GitHub App settings.
 		s.addOrgsByUser(ctx, apiClient, user.GetLogin())
 		for _, org := range s.orgs {
 			if err := s.addReposByOrg(ctx, apiClient, org); err != nil {
-				log.WithError(err).Error("error fetching repos by org")
+				s.log.Error(err, "error fetching repos by org", "org", org)
 			}
 		}
 	}
@@ -224,6 +246,7 @@ func (s *Source) enumerateWithToken(ctx context.Context, apiEndpoint, token stri
 		// TODO: Test it actually works to list org gists like this.
 		s.addGistsByUser(ctx, apiClient, org)
 	}
+	s.addGistsByMembers(ctx, apiClient)
 	return apiClient, nil
 }
 
@@ -240,7 +263,7 @@ func (s *Source) enumerateWithApp(ctx context.Context, apiEndpoint string, app *
 
 	// This client is used for most APIs
 	itr, err := ghinstallation.New(
-		common.SaneHttpClient().Transport,
+		common.NewRateLimitTransport(common.SaneHttpClient(s.httpOptions...).Transport),
 		appID,
 		installationID,
 		[]byte(app.PrivateKey))
@@ -256,7 +279,7 @@ func (s *Source) enumerateWithApp(ctx context.Context, apiEndpoint string, app *
 	// This client is required to create installation tokens for cloning.. Otherwise the required JWT is not in the
 	// request for the token :/
 	appItr, err := ghinstallation.NewAppsTransport(
-		common.SaneHttpClient().Transport,
+		common.NewRateLimitTransport(common.SaneHttpClient(s.httpOptions...).Transport),
 		appID,
 		[]byte(app.PrivateKey))
 	if err != nil {
@@ -275,17 +298,20 @@ func (s *Source) enumerateWithApp(ctx context.Context, apiEndpoint string, app *
 			return nil, nil, err
 		}
 
-		// check if we need to find user repos
-		if s.conn.ScanUsers {
+		// check if we need to find user repos or gists
+		if s.conn.ScanUsers || s.conn.IncludeGists {
 			err := s.addMembersByApp(ctx, installationClient, apiClient)
 			if err != nil {
 				return nil, nil, err
 			}
-			log.Infof("Scanning repos from %v organization members.", len(s.members))
+			s.log.Info("scanning repos from organization members", "members", len(s.members))
 			for _, member := range s.members {
 				s.addGistsByUser(ctx, apiClient, member)
+				if !s.conn.ScanUsers {
+					continue
+				}
 				if err := s.addReposByUser(ctx, apiClient, member); err != nil {
-					log.WithError(err).Error("error fetching repos by user")
+					s.log.Error(err, "error fetching repos by user", "user", member)
 				}
 			}
 		}
@@ -294,8 +320,36 @@ func (s *Source) enumerateWithApp(ctx context.Context, apiEndpoint string, app *
 	return apiClient, installationClient, nil
 }
 
+// validateConnection checks the connection's endpoint, credential and
+// options.
+func validateConnection(conn *sourcespb.GitHub) error {
+	var v sources.Validator
+	if conn.Endpoint != "" {
+		v.URL("endpoint", conn.Endpoint)
+	}
+	switch cred := conn.GetCredential().(type) {
+	case *sourcespb.GitHub_Token:
+		v.Require("token", cred.Token != "")
+	case *sourcespb.GitHub_GithubApp:
+		app := cred.GithubApp
+		v.Require("github_app.private_key", app.GetPrivateKey() != "")
+		if _, err := strconv.ParseInt(app.GetAppId(), 10, 64); err != nil {
+			v.Problemf("github_app.app_id %q is not a numeric ID", app.GetAppId())
+		}
+		if _, err := strconv.ParseInt(app.GetInstallationId(), 10, 64); err != nil {
+			v.Problemf("github_app.installation_id %q is not a numeric ID", app.GetInstallationId())
+		}
+	}
+	// Head or base should only be used with incoming webhooks
+	if (conn.Head != "" || conn.Base != "") && len(conn.Repositories) != 1 {
+		v.Problemf("head and base can only be used when scanning a single repository")
+	}
+	return v.Err()
+}
+
 // Chunks emits chunks of bytes over a channel.
 func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	ctx = git.WithProxy(ctx, s.proxy)
 	apiEndpoint := s.conn.Endpoint
 	if len(apiEndpoint) == 0 || endsWithGithub.MatchString(apiEndpoint) {
 		apiEndpoint = "https://api.github.com"
@@ -322,6 +376,9 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) err
 	}
 
 	s.normalizeRepos(ctx, apiClient)
+	if s.conn.ScanForkNetwork {
+		s.addForks(ctx, apiClient)
+	}
 
 	if _, ok := os.LookupEnv("DO_NOT_RANDOMIZE"); !ok {
 		// Randomize channel scan order on each scan
@@ -329,13 +386,24 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) err
 		rand.Shuffle(len(s.repos), func(i, j int) { s.repos[i], s.repos[j] = s.repos[j], s.repos[i] })
 	}
 
-	return s.scan(ctx, installationClient, chunksChan)
+	if err := s.scan(ctx, apiClient, installationClient, chunksChan); err != nil {
+		return err
+	}
+
+	if s.conn.IncludeAuditLog {
+		for _, org := range s.orgs {
+			if err := s.scanAuditLog(ctx, apiClient, org, chunksChan); err != nil {
+				s.log.Error(err, "unable to scan audit log, continuing", "org", org)
+			}
+		}
+	}
+	return nil
 }
 
-func (s *Source) scan(ctx context.Context, installationClient *github.Client, chunksChan chan *sources.Chunk) error {
+func (s *Source) scan(ctx context.Context, apiClient, installationClient *github.Client, chunksChan chan *sources.Chunk) error {
 	var scanned uint64
 
-	log.Debugf("Found %v total repos to scan", len(s.repos))
+	s.log.V(1).Info("found repos to scan", "count", len(s.repos))
 	wg := sync.WaitGroup{}
 	errs := make(chan error, 1)
 	reportErr := func(err error) {
@@ -343,7 +411,7 @@ func (s *Source) scan(ctx context.Context, installationClient *github.Client, ch
 		select {
 		case errs <- err:
 		default:
-			log.WithError(err).Warn("dropping error")
+			s.log.Error(err, "dropping error")
 		}
 	}
 
@@ -351,7 +419,7 @@ func (s *Source) scan(ctx context.Context, installationClient *github.Client, ch
 		if err := s.jobSem.Acquire(ctx, 1); err != nil {
 			// Acquire blocks until it can acquire the semaphore or returns an
 			// error if the context is finished
-			log.WithError(err).Debug("could not acquire semaphore")
+			s.log.V(1).Info("could not acquire semaphore", "error", err)
 			reportErr(err)
 			break
 		}
@@ -366,14 +434,29 @@ func (s *Source) scan(ctx context.Context, installationClient *github.Client, ch
 				return
 			}
 
-			s.log.WithField("repo", repoURL).Debugf("attempting to clone repo %d/%d", i+1, len(s.repos))
+			// Issues and wikis aren't in the repo's history, so they're
+			// scanned even if it can't be cloned.
+			if s.conn.IncludeComments {
+				if err := s.scanComments(ctx, apiClient, repoURL, chunksChan); err != nil {
+					s.log.Error(err, "unable to scan comments, continuing", "repo", repoURL)
+					s.AddItemError(repoURL, err)
+				}
+			}
+			if s.conn.IncludeWikis {
+				if err := s.scanWiki(ctx, installationClient, repoURL, chunksChan); err != nil {
+					reportErr(err)
+					return
+				}
+			}
+
+			s.log.V(1).Info("attempting to clone repo", "repo", repoURL, "index", i+1, "total", len(s.repos))
 			var path string
 			var repo *gogit.Repository
 			var err error
 
 			switch s.conn.GetCredential().(type) {
 			case *sourcespb.GitHub_Unauthenticated:
-				path, repo, err = git.CloneRepoUsingUnauthenticated(repoURL)
+				path, repo, err = git.CloneRepoUsingUnauthenticated(ctx, repoURL)
 			default:
 				var token string
 				token, err = s.Token(ctx, installationClient)
@@ -381,12 +464,13 @@ func (s *Source) scan(ctx context.Context, installationClient *github.Client, ch
 					reportErr(err)
 					return
 				}
-				path, repo, err = git.CloneRepoUsingToken(token, repoURL, "clone")
+				path, repo, err = git.CloneRepoUsingToken(ctx, token, repoURL, "clone")
 			}
 
 			defer os.RemoveAll(path)
 			if err != nil {
-				log.WithError(err).Errorf("unable to clone repo (%s), continuing", repoURL)
+				s.log.Error(err, "unable to clone repo, continuing", "repo", repoURL)
+				s.AddItemError(repoURL, err)
 				return
 			}
 			// Base and head will only exist from incoming webhooks.
@@ -394,13 +478,28 @@ func (s *Source) scan(ctx context.Context, installationClient *github.Client, ch
 				git.ScanOptionBaseHash(s.conn.Base),
 				git.ScanOptionHeadCommit(s.conn.Head),
 			)
+			if upstream, ok := s.forkUpstreams[repoURL]; ok {
+				// Only the commits the upstream doesn't have are scanned, so
+				// findings are the ones that only the fork still holds.
+				userInfo, err := s.userInfo(ctx, installationClient)
+				if err == nil {
+					err = git.FetchRemote(ctx, path, "upstream", userInfo, upstream)
+				}
+				if err != nil {
+					s.log.Error(err, "unable to fetch upstream of fork, continuing", "repo", repoURL)
+					s.AddItemError(repoURL, err)
+					return
+				}
+				scanOptions.ExcludeRemote = "upstream"
+			}
 
 			err = s.git.ScanRepo(ctx, repo, path, scanOptions, chunksChan)
 			if err != nil {
-				log.WithError(err).Errorf("unable to scan repo, continuing")
+				s.log.Error(err, "unable to scan repo, continuing", "repo", repoURL)
+				s.AddItemError(repoURL, err)
 			}
 			atomic.AddUint64(&scanned, 1)
-			log.Debugf("scanned %d/%d repos", scanned, len(s.repos))
+			s.log.V(1).Info("scanned repo", "scanned", atomic.LoadUint64(&scanned), "total", len(s.repos))
 		}(ctx, repoURL, i)
 	}
 
@@ -415,10 +514,61 @@ func (s *Source) scan(ctx context.Context, installationClient *github.Client, ch
 	}
 }
 
+// scanWiki clones and scans the wiki of a repo. Repos without a wiki, and
+// gists, have no wiki repo to clone, so clone failures are only logged.
+func (s *Source) scanWiki(ctx context.Context, installationClient *github.Client, repoURL string, chunksChan chan *sources.Chunk) error {
+	if _, _, ok := repoOwnerName(repoURL); !ok {
+		return nil
+	}
+	wikiURL := strings.TrimSuffix(repoURL, ".git") + ".wiki.git"
+
+	userInfo, err := s.userInfo(ctx, installationClient)
+	if err != nil {
+		return err
+	}
+	path, repo, err := git.CloneRepo(ctx, userInfo, wikiURL)
+	defer os.RemoveAll(path)
+	if err != nil {
+		s.log.V(1).Info("unable to clone wiki, continuing", "repo", repoURL, "error", err.Error())
+		return nil
+	}
+
+	if err := s.git.ScanRepo(ctx, repo, path, git.NewScanOptions(), chunksChan); err != nil {
+		s.log.Error(err, "unable to scan wiki, continuing", "repo", repoURL)
+		s.AddItemError(wikiURL, err)
+	}
+	return nil
+}
+
+// userInfo returns the credentials to clone and fetch repos with, or nil when
+// scanning without authentication.
+func (s *Source) userInfo(ctx context.Context, installationClient *github.Client) (*url.Userinfo, error) {
+	if _, ok := s.conn.GetCredential().(*sourcespb.GitHub_Unauthenticated); ok {
+		return nil, nil
+	}
+	token, err := s.Token(ctx, installationClient)
+	if err != nil {
+		return nil, err
+	}
+	return url.UserPassword("clone", token), nil
+}
+
 // handleRateLimit returns true if a rate limit was handled
 // Unauthenticated access to most github endpoints has a rate limit of 60 requests per hour.
 // This will likely only be exhausted if many users/orgs are scanned without auth
-func handleRateLimit(errIn error, res *github.Response) bool {
+// Most limits are waited out by the client's RateLimitTransport; these are the
+// ones go-github reports without sending the request, because an earlier
+// response used up the limit.
+func (s *Source) handleRateLimit(errIn error, res *github.Response) bool {
+	if abuse, ok := errIn.(*github.AbuseRateLimitError); ok {
+		duration := abuse.GetRetryAfter()
+		if duration <= 0 {
+			duration = time.Minute
+		}
+		s.log.V(1).Info("secondary rate limited", "resume_time", time.Now().Add(duration).String())
+		time.Sleep(duration)
+		return true
+	}
 	limit, ok := errIn.(*github.RateLimitError)
 	if !ok {
 		return false
@@ -439,14 +589,21 @@ func handleRateLimit(errIn error, res *github.Response) bool {
 			waitTime := int64(resetTime) - time.Now().Unix()
 			if waitTime > 0 {
 				duration := time.Duration(waitTime+1) * time.Second
-				log.WithField("resumeTime", time.Now().Add(duration).String()).Debugf("rate limited")
+				s.log.V(1).Info("rate limited", "resume_time", time.Now().Add(duration).String())
 				time.Sleep(duration)
 				return true
 			}
 		}
 	}
 
-	log.WithField("retry-after", limit.Message).Debug("handling rate limit (5 minutes retry)")
+	if waitTime := time.Until(limit.Rate.Reset.Time); waitTime > 0 && waitTime <= time.Hour {
+		duration := waitTime + time.Second
+		s.log.V(1).Info("rate limited", "resume_time", time.Now().Add(duration).String())
+		time.Sleep(duration)
+		return true
+	}
+
+	s.log.V(1).Info("handling rate limit (5 minutes retry)", "retry_after", limit.Message)
 	time.Sleep(time.Minute * 5)
 	return true
 }
@@ -464,7 +621,7 @@ func (s *Source) getReposByOrg(ctx context.Context, apiClient *github.Client, or
 		if err == nil {
 			defer res.Body.Close()
 		}
-		if handled := handleRateLimit(err, res); handled {
+		if handled := s.handleRateLimit(err, res); handled {
 			continue
 		}
 		if err != nil {
@@ -488,7 +645,7 @@ func (s *Source) getReposByOrg(ctx context.Context, apiClient *github.Client, or
 		}
 		opts.Page = res.NextPage
 	}
-	log.WithField("org", org).Debugf("Found %d repos (%d forks)", numRepos, numForks)
+	s.log.V(1).Info("found repos", "org", org, "repos", numRepos, "forks", numForks)
 	return repos, nil
 }
 
@@ -516,7 +673,7 @@ func (s *Source) getReposByUser(ctx context.Context, apiClient *github.Client, u
 		if err == nil {
 			defer res.Body.Close()
 		}
-		if handled := handleRateLimit(err, res); handled {
+		if handled := s.handleRateLimit(err, res); handled {
 			continue
 		}
 		if err != nil {
@@ -556,11 +713,11 @@ func (s *Source) getGistsByUser(ctx context.Context, apiClient *github.Client, u
 		if err == nil {
 			defer resp.Body.Close()
 		}
-		if handled := handleRateLimit(err, resp); handled {
+		if handled := s.handleRateLimit(err, resp); handled {
 			continue
 		}
 		if err != nil {
-			log.WithError(err).Warnf("could not list repos for user %s", user)
+			s.log.Error(err, "could not list gists for user", "user", user)
 			return nil, fmt.Errorf("could not list repos for user %s: %w", user, err)
 		}
 		for _, gist := range gists {
@@ -586,6 +743,120 @@ func (s *Source) addGistsByUser(ctx context.Context, apiClient *github.Client, u
 	return nil
 }
 
+// addGistsByMembers adds the gists of the members of the organizations being
+// scanned when IncludeGists is set. Gists belong to users rather than
+// organizations, so they aren't found by listing the organization's repos.
+func (s *Source) addGistsByMembers(ctx context.Context, apiClient *github.Client) {
+	if !s.conn.IncludeGists {
+		return
+	}
+	for _, org := range s.orgs {
+		if err := s.addMembersByOrg(ctx, apiClient, org); err != nil {
+			s.log.Error(err, "error fetching members by org", "org", org)
+		}
+	}
+	s.log.V(1).Info("scanning gists from organization members", "members", len(s.members))
+	for _, member := range s.members {
+		s.addGistsByUser(ctx, apiClient, member)
+	}
+}
+
+func (s *Source) addMembersByOrg(ctx context.Context, apiClient *github.Client, org string) error {
+	opts := &github.ListMembersOptions{
+		// Only public members can be listed without being one.
+		PublicOnly: s.conn.GetUnauthenticated() != nil,
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+	for {
+		members, res, err := apiClient.Organizations.ListMembers(ctx, org, opts)
+		if err == nil {
+			defer res.Body.Close()
+		}
+		if handled := s.handleRateLimit(err, res); handled {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not list members for org %s: %w", org, err)
+		}
+		for _, m := range members {
+			if m.GetLogin() == "" {
+				continue
+			}
+			common.AddStringSliceItem(m.GetLogin(), &s.members)
+		}
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+	return nil
+}
+
+// scanAuditLog sends the entries of an organization's audit log from the last
+// auditLogWindow as JSON, since webhook URLs, workflow names and messages
+// logged there can hold credentials. Reading the audit log requires a token
+// of an organization owner.
+func (s *Source) scanAuditLog(ctx context.Context, apiClient *github.Client, org string, chunksChan chan *sources.Chunk) error {
+	since := time.Now().Add(-auditLogWindow).Format("2006-01-02")
+	opts := &github.GetAuditLogOptions{
+		Phrase: github.String("created:>=" + since),
+		ListCursorOptions: github.ListCursorOptions{
+			PerPage: 100,
+		},
+	}
+	var count int
+	for {
+		entries, res, err := apiClient.Organizations.GetAuditLog(ctx, org, opts)
+		if err == nil {
+			defer res.Body.Close()
+		}
+		if handled := s.handleRateLimit(err, res); handled {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not get audit log for org %s: %w", org, err)
+		}
+		for _, entry := range entries {
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return errors.New(err)
+			}
+			metadata := &source_metadatapb.Github{
+				Username:   sanitizer.UTF8(entry.GetActor()),
+				Repository: sanitizer.UTF8(entry.GetRepo()),
+			}
+			if entry.Timestamp != nil {
+				metadata.Timestamp = entry.Timestamp.String()
+			}
+			chunk := &sources.Chunk{
+				SourceName: s.name,
+				SourceID:   s.SourceID(),
+				SourceType: s.Type(),
+				SourceMetadata: &source_metadatapb.MetaData{
+					Data: &source_metadatapb.MetaData_Github{Github: metadata},
+				},
+				Data:   data,
+				Verify: s.verify,
+			}
+			select {
+			case chunksChan <- chunk:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			count++
+		}
+		// The audit log is paginated by cursor rather than page number.
+		if res.After == "" {
+			break
+		}
+		opts.After = res.After
+	}
+	s.log.V(1).Info("scanned audit log", "org", org, "entries", count)
+	return nil
+}
+
 func (s *Source) addMembersByApp(ctx context.Context, installationClient *github.Client, apiClient *github.Client) error {
 	opts := &github.ListOptions{
 		PerPage: 500,
@@ -597,7 +868,7 @@ func (s *Source) addMembersByApp(ctx context.Context, installationClient *github
 
 	installs, _, err := installationClient.Apps.ListInstallations(ctx, opts)
 	if err != nil {
-		log.WithError(err).Warn("Could not enumerate organizations using user")
+		s.log.Error(err, "could not enumerate organizations using user")
 		return err
 	}
 	for _, org := range installs {
@@ -606,12 +877,12 @@ func (s *Source) addMembersByApp(ctx context.Context, installationClient *github
 			if err == nil {
 				defer res.Body.Close()
 			}
-			if handled := handleRateLimit(err, res); handled {
+			if handled := s.handleRateLimit(err, res); handled {
 				continue
 			}
 			if err != nil || len(members) == 0 {
 				errText := "Could not list organization members: Please install on an organization. Otherwise, this is an older version of the Github app, please delete and re-add this source!"
-				log.WithError(err).Warnf(errText)
+				s.log.Error(err, errText)
 				return errors.New(errText)
 			}
 			for _, m := range members {
@@ -641,7 +912,7 @@ func (s *Source) addReposByApp(ctx context.Context, apiClient *github.Client) er
 		if err == nil {
 			defer res.Body.Close()
 		}
-		if handled := handleRateLimit(err, res); handled {
+		if handled := s.handleRateLimit(err, res); handled {
 			continue
 		}
 		if err != nil {
@@ -670,11 +941,11 @@ func (s *Source) addOrgsByUser(ctx context.Context, apiClient *github.Client, us
 		if err == nil {
 			defer resp.Body.Close()
 		}
-		if handled := handleRateLimit(err, resp); handled {
+		if handled := s.handleRateLimit(err, resp); handled {
 			continue
 		}
 		if err != nil {
-			log.WithError(err).Errorf("Could not list organizations for %s", user)
+			s.log.Error(err, "could not list organizations", "user", user)
 			return
 		}
 		for _, org := range orgs {
@@ -695,6 +966,66 @@ func (s *Source) addOrgsByUser(ctx context.Context, apiClient *github.Client, us
 	}
 }
 
+// addForks adds the forks of the repos being scanned, and their forks in turn,
+// so that commits that were deleted upstream but remain in forks are found.
+// Each fork is only scanned for the commits its upstream doesn't have.
+func (s *Source) addForks(ctx context.Context, apiClient *github.Client) {
+	s.forkUpstreams = map[string]string{}
+	queue := append([]string{}, s.repos...)
+	for len(queue) > 0 {
+		upstream := queue[0]
+		queue = queue[1:]
+		forks, err := s.getForks(ctx, apiClient, upstream)
+		if err != nil {
+			s.log.Error(err, "error fetching forks", "repo", upstream)
+			continue
+		}
+		for _, fork := range forks {
+			if _, ok := s.forkUpstreams[fork]; ok {
+				continue
+			}
+			s.forkUpstreams[fork] = upstream
+			common.AddStringSliceItem(fork, &s.repos)
+			queue = append(queue, fork)
+		}
+	}
+	s.log.V(1).Info("found forks", "count", len(s.forkUpstreams))
+}
+
+func (s *Source) getForks(ctx context.Context, apiClient *github.Client, repoURL string) ([]string, error) {
+	owner, repo, ok := repoOwnerName(repoURL)
+	if !ok {
+		// Gists' forks aren't listed with repos' forks.
+		return nil, nil
+	}
+	forks := []string{}
+	opts := &github.RepositoryListForksOptions{
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+	for {
+		someForks, res, err := apiClient.Repositories.ListForks(ctx, owner, repo, opts)
+		if err == nil {
+			defer res.Body.Close()
+		}
+		if handled := s.handleRateLimit(err, res); handled {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not list forks for repo %s: %w", repoURL, err)
+		}
+		for _, r := range someForks {
+			forks = append(forks, r.GetCloneURL())
+		}
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+	return forks, nil
+}
+
 func (s *Source) normalizeRepos(ctx context.Context, apiClient *github.Client) {
 	// TODO: Add check/fix for repos that are missing scheme
 	normalizedRepos := map[string]struct{}{}
@@ -703,7 +1034,7 @@ func (s *Source) normalizeRepos(ctx context.Context, apiClient *github.Client) {
 		if strings.ContainsRune(repo, '/') {
 			repoNormalized, err := giturl.NormalizeGithubRepo(repo)
 			if err != nil {
-				log.WithError(err).Warnf("Repo not in expected format: %s", repo)
+				s.log.Error(err, "repo not in expected format", "repo", repo)
 				continue
 			}
 			normalizedRepos[repoNormalized] = struct{}{}
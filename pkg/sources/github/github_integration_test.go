@@ -14,8 +14,6 @@ import (
 	"github.com/google/go-github/v42/github"
 
 	"github.com/kylelemons/godebug/pretty"
-	"github.com/mattn/go-colorable"
-	log "github.com/sirupsen/logrus"
 	"google.golang.org/protobuf/types/known/anypb"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
@@ -339,14 +337,9 @@ func TestSource_Scan(t *testing.T) {
 
 	for i, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			log.Debugf("Beginning test %d: %s", i, tt.name)
+			t.Logf("Beginning test %d: %s", i, tt.name)
 			s := Source{}
 
-			log.SetLevel(log.DebugLevel)
-			//uncomment for windows Testing
-			log.SetFormatter(&log.TextFormatter{ForceColors: true})
-			log.SetOutput(colorable.NewColorableStdout())
-
 			conn, err := anypb.New(tt.init.connection)
 			if err != nil {
 				t.Fatal(err)
@@ -491,11 +484,6 @@ func TestSource_paginateGists(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			s := Source{}
 
-			log.SetLevel(log.DebugLevel)
-			//uncomment for windows Testing
-			log.SetFormatter(&log.TextFormatter{ForceColors: true})
-			log.SetOutput(colorable.NewColorableStdout())
-
 			conn, err := anypb.New(tt.init.connection)
 			if err != nil {
 				t.Fatal(err)
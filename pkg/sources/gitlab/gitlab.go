@@ -3,6 +3,7 @@ package gitlab
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
 	"runtime"
@@ -11,9 +12,10 @@ import (
 
 	"github.com/go-errors/errors"
 	gogit "github.com/go-git/go-git/v5"
-	log "github.com/sirupsen/logrus"
+	"github.com/go-logr/logr"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/giturl"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
@@ -21,7 +23,6 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
 	"github.com/xanzy/go-gitlab"
 	"golang.org/x/sync/semaphore"
-	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 )
 
@@ -36,10 +37,14 @@ type Source struct {
 	token      string
 	url        string
 	repos      []string
+	httpClient *http.Client
 	git        *git.Git
 	aCtx       context.Context
+	log        logr.Logger
 	sources.Progress
 	jobSem *semaphore.Weighted
+	// proxy is the proxy repos are cloned through, if one is set.
+	proxy *url.URL
 }
 
 // Ensure the Source satisfies the interface at compile time.
@@ -61,6 +66,7 @@ func (s *Source) JobID() int64 {
 
 // Init returns an initialized Gitlab source.
 func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.log = log.FromContext(aCtx).WithValues("name", name)
 
 	s.aCtx = aCtx
 	s.name = name
@@ -70,9 +76,20 @@ func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64,
 	s.jobSem = semaphore.NewWeighted(int64(concurrency))
 
 	var conn sourcespb.GitLab
-	err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{})
+	if err := sources.UnmarshalConnection(connection, &conn); err != nil {
+		return err
+	}
+	if err := validateConnection(&conn); err != nil {
+		return err
+	}
+
+	httpOptions, err := common.NewHttpClientOptions(conn.Tls, conn.Proxy)
 	if err != nil {
-		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+		return err
+	}
+	s.httpClient = common.RateLimitedHttpClient(httpOptions...)
+	if s.proxy, err = common.NewProxyURL(conn.Proxy); err != nil {
+		return err
 	}
 
 	s.repos = conn.Repositories
@@ -94,8 +111,6 @@ func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64,
 		s.password = cred.BasicAuth.Password
 		// We may need the password as a token if the user is using an access_token with basic auth.
 		s.token = cred.BasicAuth.Password
-	default:
-		return errors.Errorf("Invalid configuration given for source. Name: %s, Type: %s", name, s.Type())
 	}
 
 	if len(s.url) == 0 {
@@ -124,17 +139,18 @@ func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64,
 }
 
 func (s *Source) newClient() (*gitlab.Client, error) {
-	// Initialize a new api instance.
+	// Initialize a new api instance. Its requests wait out rate limits, so
+	// enumerating large instances doesn't fail partway.
 	switch s.authMethod {
 	case "OAUTH":
-		apiClient, err := gitlab.NewOAuthClient(s.token, gitlab.WithBaseURL(s.url))
+		apiClient, err := gitlab.NewOAuthClient(s.token, gitlab.WithBaseURL(s.url), gitlab.WithHTTPClient(s.httpClient))
 		if err != nil {
 			return nil, fmt.Errorf("could not create Gitlab OAUTH client for %s. Error: %v", s.url, err)
 		}
 		return apiClient, nil
 
 	case "BASIC_AUTH":
-		apiClient, err := gitlab.NewBasicAuthClient(s.user, s.password, gitlab.WithBaseURL(s.url))
+		apiClient, err := gitlab.NewBasicAuthClient(s.user, s.password, gitlab.WithBaseURL(s.url), gitlab.WithHTTPClient(s.httpClient))
 		if err != nil {
 			return nil, fmt.Errorf("could not create Gitlab BASICAUTH client for %s. Error: %v", s.url, err)
 		}
@@ -147,7 +163,7 @@ func (s *Source) newClient() (*gitlab.Client, error) {
 		}
 		fallthrough
 	case "TOKEN":
-		apiClient, err := gitlab.NewOAuthClient(s.token, gitlab.WithBaseURL(s.url))
+		apiClient, err := gitlab.NewOAuthClient(s.token, gitlab.WithBaseURL(s.url), gitlab.WithHTTPClient(s.httpClient))
 		if err != nil {
 			return nil, fmt.Errorf("could not create Gitlab TOKEN client for %s. Error: %v", s.url, err)
 		}
@@ -216,7 +232,7 @@ func (s *Source) getAllProjects(apiClient *gitlab.Client) ([]*gitlab.Project, er
 		for {
 			grpPrjs, res, err := apiClient.Groups.ListGroupProjects(group.ID, listGroupProjectOptions)
 			if err != nil {
-				log.WithError(err).WithField("group", group.FullPath).Warn("received error on listing group projects, you probably don't have permissions to do that")
+				s.log.Error(err, "received error on listing group projects, you probably don't have permissions to do that", "group", group.FullPath)
 				break
 			}
 			for _, prj := range grpPrjs {
@@ -232,7 +248,7 @@ func (s *Source) getAllProjects(apiClient *gitlab.Client) ([]*gitlab.Project, er
 	for _, project := range projects {
 		projectNamesWithNamespace = append(projectNamesWithNamespace, project.NameWithNamespace)
 	}
-	log.WithField("projects", strings.Join(projectNamesWithNamespace, ", ")).Debugf("Enumerated %d GitLab projects", len(projects))
+	s.log.V(1).Info("enumerated GitLab projects", "count", len(projects), "projects", strings.Join(projectNamesWithNamespace, ", "))
 
 	var projectList []*gitlab.Project
 	for _, project := range projects {
@@ -266,6 +282,7 @@ func (s *Source) scanRepos(ctx context.Context, chunksChan chan *sources.Chunk,
 	wg := sync.WaitGroup{}
 	var errs []error
 	var errsMut sync.Mutex
+	retries := sources.NewRetryQueue(&s.Progress)
 
 	for i, u := range repos {
 		if common.IsDone(ctx) {
@@ -274,7 +291,7 @@ func (s *Source) scanRepos(ctx context.Context, chunksChan chan *sources.Chunk,
 			return nil
 		}
 		if err := s.jobSem.Acquire(ctx, 1); err != nil {
-			log.WithError(err).Debug("could not acquire semaphore")
+			s.log.V(1).Info("could not acquire semaphore", "error", err)
 			continue
 		}
 		wg.Add(1)
@@ -286,45 +303,74 @@ func (s *Source) scanRepos(ctx context.Context, chunksChan chan *sources.Chunk,
 			}
 			s.SetProgressComplete(i, len(repos), fmt.Sprintf("Repo: %s", repoURL), "")
 
-			var path string
-			var repo *gogit.Repository
-			var err error
-			if s.authMethod == "UNAUTHENTICATED" {
-				path, repo, err = git.CloneRepoUsingUnauthenticated(repoURL.String())
-			} else {
-				// If a username is not provided we need to use a default one in order to clone a private repo.
-				// Not setting "placeholder" as s.user on purpose in case any downstream services rely on a "" value for s.user.
-				user := s.user
-				if user == "" {
-					user = "placeholder"
-				}
-				path, repo, err = git.CloneRepoUsingToken(s.token, repoURL.String(), user)
-			}
-			defer os.RemoveAll(path)
-			if err != nil {
+			s.log.V(1).Info("starting to scan repo", "index", i+1, "total", len(repos), "repo", repoURL.String())
+			if err := s.scanRepo(ctx, chunksChan, repoURL); err != nil {
+				retries.Fail(repoURL.String(), err, func(ctx context.Context) error {
+					return s.scanRepo(ctx, chunksChan, repoURL)
+				})
 				errsMut.Lock()
 				errs = append(errs, err)
 				errsMut.Unlock()
 				return
 			}
-			log.Debugf("Starting to scan repo %d/%d: %s", i+1, len(repos), repoURL.String())
-			err = s.git.ScanRepo(ctx, repo, path, git.NewScanOptions(), chunksChan)
-			if err != nil {
-				errsMut.Lock()
-				errs = append(errs, err)
-				errsMut.Unlock()
-				return
-			}
-			log.Debugf("Completed scanning repo %d/%d: %s", i+1, len(repos), repoURL.String())
+			s.log.V(1).Info("completed scanning repo", "index", i+1, "total", len(repos), "repo", repoURL.String())
 		}(ctx, u, i)
 	}
 	wg.Wait()
 
+	// Repos that failed to clone or scan with errors that may not recur,
+	// such as rate limits, are retried once the others have been scanned.
+	retries.Run(ctx)
+
 	return errs
 }
 
+// scanRepo clones a repo and scans it.
+func (s *Source) scanRepo(ctx context.Context, chunksChan chan *sources.Chunk, repoURL *url.URL) error {
+	var path string
+	var repo *gogit.Repository
+	var err error
+	if s.authMethod == "UNAUTHENTICATED" {
+		path, repo, err = git.CloneRepoUsingUnauthenticated(ctx, repoURL.String())
+	} else {
+		// If a username is not provided we need to use a default one in order to clone a private repo.
+		// Not setting "placeholder" as s.user on purpose in case any downstream services rely on a "" value for s.user.
+		user := s.user
+		if user == "" {
+			user = "placeholder"
+		}
+		path, repo, err = git.CloneRepoUsingToken(ctx, s.token, repoURL.String(), user)
+	}
+	defer os.RemoveAll(path)
+	if err != nil {
+		return err
+	}
+	return s.git.ScanRepo(ctx, repo, path, git.NewScanOptions(), chunksChan)
+}
+
+// validateConnection checks the connection's endpoint and credential.
+func validateConnection(conn *sourcespb.GitLab) error {
+	var v sources.Validator
+	if conn.Endpoint != "" {
+		v.URL("endpoint", conn.Endpoint)
+	}
+	switch cred := conn.GetCredential().(type) {
+	case *sourcespb.GitLab_Token:
+		v.Require("token", cred.Token != "")
+	case *sourcespb.GitLab_Oauth:
+		v.Require("oauth.refresh_token", cred.Oauth.GetRefreshToken() != "")
+	case *sourcespb.GitLab_BasicAuth:
+		v.Require("basic_auth.username", cred.BasicAuth.GetUsername() != "")
+		v.Require("basic_auth.password", cred.BasicAuth.GetPassword() != "")
+	default:
+		v.Credential(false, "token", "oauth", "basic_auth")
+	}
+	return v.Err()
+}
+
 // Chunks emits chunks of bytes over a channel.
 func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	ctx = git.WithProxy(ctx, s.proxy)
 	// Start client.
 	apiClient, err := s.newClient()
 	if err != nil {
@@ -333,7 +379,7 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) err
 	// Get repo within target.
 	repos, errs := s.getRepos()
 	for _, repoErr := range errs {
-		log.WithError(repoErr).Warn("error getting repo")
+		s.log.Error(repoErr, "error getting repo")
 	}
 
 	// End early if we had errors getting specified repos but none were validated.
@@ -361,13 +407,7 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) err
 	}
 	errs = s.scanRepos(ctx, chunksChan, repos)
 	for _, err := range errs {
-		log.WithError(err).WithFields(
-			log.Fields{
-				"source_name": s.name,
-				"source_type": s.Type(),
-				"repos":       repos,
-			},
-		).Error("error scanning repo")
+		s.log.Error(err, "error scanning repo", "repos", repos)
 	}
 
 	return nil
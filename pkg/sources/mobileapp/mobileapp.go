@@ -0,0 +1,167 @@
+package mobileapp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// downloadTimeout is how long, in seconds, downloading an artifact may take.
+// App bundles can be hundreds of megabytes.
+const downloadTimeout = 10 * 60
+
+type Source struct {
+	sources.Base
+	conn   *sourcespb.MobileApp
+	client *http.Client
+}
+
+// Ensure the Source satisfies the interface at compile time.
+var _ sources.Source = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_MOBILE_APP
+}
+
+// Init returns an initialized mobile app source.
+func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.InitBase(aCtx, s.Type(), name, jobId, sourceId, verify, concurrency)
+
+	var conn sourcespb.MobileApp
+	if err := sources.UnmarshalConnection(connection, &conn); err != nil {
+		return err
+	}
+	if err := validateConnection(&conn); err != nil {
+		return err
+	}
+	s.conn = &conn
+
+	httpOptions, err := common.NewHttpClientOptions(conn.Tls, conn.Proxy)
+	if err != nil {
+		return err
+	}
+	s.client = common.SaneHttpClientTimeOut(downloadTimeout, httpOptions...)
+
+	return nil
+}
+
+// validateConnection checks that the connection has artifacts to scan, each
+// a path or an http or https URL.
+func validateConnection(conn *sourcespb.MobileApp) error {
+	var v sources.Validator
+	v.Require("artifacts", len(conn.Artifacts) > 0)
+	for i, artifact := range conn.Artifacts {
+		field := fmt.Sprintf("artifacts[%d]", i)
+		switch {
+		case artifact == "":
+			v.Require(field, false)
+		case strings.Contains(artifact, "://") && !isURL(artifact):
+			v.URL(field, artifact)
+		}
+	}
+	return v.Err()
+}
+
+// Chunks unpacks each APK or IPA artifact, downloading the ones given by
+// URL, and emits the chunks of its files.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	for i, artifact := range s.conn.Artifacts {
+		if common.IsDone(ctx) {
+			return nil
+		}
+		s.SetProgressComplete(i, len(s.conn.Artifacts), fmt.Sprintf("Artifact: %s", artifactName(artifact)), "")
+
+		if err := s.chunkArtifact(ctx, chunksChan, artifact); err != nil {
+			if common.IsDone(ctx) {
+				return nil
+			}
+			return errors.WrapPrefix(err, fmt.Sprintf("could not scan app artifact: %s", artifactName(artifact)), 0)
+		}
+	}
+	return nil
+}
+
+// chunkArtifact emits the chunks of an artifact, given by path or URL.
+func (s *Source) chunkArtifact(ctx context.Context, chunksChan chan *sources.Chunk, artifact string) error {
+	var (
+		f   *os.File
+		err error
+	)
+	if isURL(artifact) {
+		f, err = s.download(ctx, artifact)
+		if f != nil {
+			defer os.Remove(f.Name())
+		}
+	} else {
+		f, err = os.Open(artifact)
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return (&handlers.MobileApp{}).Handle(ctx, artifactName(artifact), f, s.ChunkSkel(), chunksChan)
+}
+
+// download saves the artifact at u to a temporary file, which the caller
+// removes.
+func (s *Source) download(ctx context.Context, u string) (*os.File, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status downloading artifact: %s", resp.Status)
+	}
+
+	f, err := os.CreateTemp("", "trufflehog-app-")
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not create temporary file", 0)
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return f, errors.WrapPrefix(err, "could not download artifact", 0)
+	}
+	return f, nil
+}
+
+// isURL reports whether artifact is an http or https URL rather than a path.
+func isURL(artifact string) bool {
+	return strings.HasPrefix(artifact, "https://") || strings.HasPrefix(artifact, "http://")
+}
+
+// artifactName returns the name artifacts are reported by. The query and user
+// info of URLs are dropped, since they often hold the credentials of signed
+// download links.
+func artifactName(artifact string) string {
+	if !isURL(artifact) {
+		return artifact
+	}
+	u, err := url.Parse(artifact)
+	if err != nil {
+		return artifact
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
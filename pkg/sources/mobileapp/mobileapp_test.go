@@ -0,0 +1,124 @@
+package mobileapp
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestSource_Chunks(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+
+	apk := zipFile(t, "AndroidManifest.xml", "<manifest package=\"com.example.notes\"/>\n")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/builds/notes.apk" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(apk)
+	}))
+	defer server.Close()
+
+	ipa := filepath.Join(t.TempDir(), "Notes.ipa")
+	if err := os.WriteFile(ipa, zipFile(t, "Payload/Notes.app/config.json", `{"token": "example"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := anypb.New(&sourcespb.MobileApp{
+		Artifacts: []string{server.URL + "/builds/notes.apk?signature=secret", ipa},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := Source{}
+	if err := s.Init(ctx, "test mobile app", 0, 0, false, conn, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	chunksChan := make(chan *sources.Chunk, 10)
+	if err := s.Chunks(ctx, chunksChan); err != nil {
+		t.Fatal(err)
+	}
+	close(chunksChan)
+
+	var got []string
+	var gotMetadata []*source_metadatapb.MobileApp
+	for chunk := range chunksChan {
+		got = append(got, string(chunk.Data))
+		gotMetadata = append(gotMetadata, chunk.SourceMetadata.GetMobileApp())
+	}
+	want := []string{
+		"<manifest package=\"com.example.notes\"/>\n",
+		`{"token": "example"}`,
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("Chunks() data diff: (-got +want)\n%s", diff)
+	}
+	wantMetadata := []*source_metadatapb.MobileApp{
+		{Artifact: server.URL + "/builds/notes.apk", Platform: "android", File: "AndroidManifest.xml"},
+		{Artifact: ipa, Platform: "ios", File: "Payload/Notes.app/config.json"},
+	}
+	if diff := pretty.Compare(gotMetadata, wantMetadata); diff != "" {
+		t.Errorf("Chunks() metadata diff: (-got +want)\n%s", diff)
+	}
+}
+
+func TestSource_ChunksErrors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	notApp := filepath.Join(t.TempDir(), "notes.apk")
+	if err := os.WriteFile(notApp, []byte("not a zip file"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, artifact := range []string{server.URL + "/missing.apk", notApp} {
+		conn, err := anypb.New(&sourcespb.MobileApp{Artifacts: []string{artifact}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := Source{}
+		if err := s.Init(ctx, "test mobile app", 0, 0, false, conn, 1); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Chunks(ctx, make(chan *sources.Chunk, 10)); err == nil {
+			t.Errorf("Chunks() of %s succeeded", artifact)
+		}
+	}
+}
+
+// zipFile returns a zip archive holding a single file.
+func zipFile(t *testing.T, name, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,203 @@
+package patch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gitleaks/go-gitdiff/gitdiff"
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// stdinPath is the path of a patch read from standard input.
+const stdinPath = "-"
+
+type Source struct {
+	sources.Base
+	conn  *sourcespb.Patch
+	stdin io.Reader
+	// stdinName is the name the patch read from stdin is reported as.
+	stdinName string
+}
+
+// Ensure the Source satisfies the interface at compile time.
+var _ sources.Source = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_PATCH
+}
+
+// Init returns an initialized patch source.
+func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.InitBase(aCtx, s.Type(), name, jobId, sourceId, verify, concurrency)
+
+	var conn sourcespb.Patch
+	if err := sources.UnmarshalConnection(connection, &conn); err != nil {
+		return err
+	}
+	if err := validateConnection(&conn); err != nil {
+		return err
+	}
+	s.conn = &conn
+	s.stdin = os.Stdin
+	s.stdinName = "stdin"
+	return nil
+}
+
+// SetStdin sets the reader the patch at the path "-" is read from in place of
+// standard input, and the name it's reported as. It's called after Init.
+func (s *Source) SetStdin(name string, r io.Reader) {
+	s.stdinName = name
+	s.stdin = r
+}
+
+// validateConnection checks that the connection has patches to scan, which
+// are files, or standard input once.
+func validateConnection(conn *sourcespb.Patch) error {
+	var v sources.Validator
+	v.Require("paths", len(conn.Paths) > 0)
+	stdin := 0
+	for _, path := range conn.Paths {
+		if path == stdinPath {
+			stdin++
+			continue
+		}
+		if info, err := os.Stat(path); err != nil {
+			v.Problemf("could not read patch %s: %s", path, err)
+		} else if info.IsDir() {
+			v.Problemf("patch %s is a directory", path)
+		}
+	}
+	if stdin > 1 {
+		v.Problemf("standard input can only be read once")
+	}
+	return v.Err()
+}
+
+// Chunks emits the lines added by each patch, such as git diff or git
+// format-patch output, or any unified diff. Each run of lines added together
+// is a chunk, reported at the file and line it was added to.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	return s.Enumerate(ctx, "Patch", s.conn.Paths, func(path string) error {
+		name := path
+		var r io.Reader = s.stdin
+		if path == stdinPath {
+			name = s.stdinName
+		} else {
+			f, err := os.Open(path)
+			if err != nil {
+				return errors.WrapPrefix(err, fmt.Sprintf("could not open patch %s", path), 0)
+			}
+			// Closing the file also stops the parser if the scan stops early.
+			defer f.Close()
+			r = f
+		}
+		return s.scanPatch(ctx, chunksChan, name, r)
+	})
+}
+
+// scanPatch emits the lines added by the patch read from r.
+func (s *Source) scanPatch(ctx context.Context, chunksChan chan *sources.Chunk, name string, r io.Reader) error {
+	files, err := gitdiff.Parse(r)
+	if err != nil {
+		return errors.WrapPrefix(err, fmt.Sprintf("could not parse patch %s", name), 0)
+	}
+	count := 0
+	for file := range files {
+		count++
+		if file.IsDelete || file.IsBinary {
+			continue
+		}
+		for _, fragment := range file.TextFragments {
+			for _, added := range addedLines(fragment) {
+				if err := s.Send(ctx, chunksChan, []byte(added.text), patchMetadata(name, file, added.line)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if count == 0 {
+		s.Log().Info("patch has no diffs", "patch", name)
+	}
+	return nil
+}
+
+// added is a run of lines added together by a fragment.
+type added struct {
+	// line is the line of the new file the run starts at.
+	line int64
+	text string
+}
+
+// addedLines returns the runs of lines that fragment adds, which context
+// lines separate. Removed lines don't take up lines of the new file.
+func addedLines(fragment *gitdiff.TextFragment) []added {
+	var runs []added
+	var run strings.Builder
+	start, line := int64(0), fragment.NewPosition
+	flush := func() {
+		if run.Len() > 0 {
+			runs = append(runs, added{line: start, text: run.String()})
+			run.Reset()
+		}
+	}
+	for _, l := range fragment.Lines {
+		switch l.Op {
+		case gitdiff.OpAdd:
+			if run.Len() == 0 {
+				start = line
+			}
+			run.WriteString(l.Line)
+			line++
+		case gitdiff.OpContext:
+			flush()
+			line++
+		}
+	}
+	flush()
+	return runs
+}
+
+// fileName returns the name of the file a patch changes. The a/ and b/
+// prefixes of names in patches made by git are removed when they're parsed,
+// but not in unified diffs made without git, like those of diff -ru a b,
+// which have no index lines.
+func fileName(file *gitdiff.File) string {
+	if file.OldOIDPrefix == "" && file.NewOIDPrefix == "" {
+		return strings.TrimPrefix(file.NewName, "b/")
+	}
+	return file.NewName
+}
+
+// patchMetadata returns the metadata of lines added to file by the patch,
+// with the commit and author of the patch if it has them, as the output of
+// git log -p and git show does.
+func patchMetadata(name string, file *gitdiff.File, line int64) *source_metadatapb.MetaData {
+	metadata := &source_metadatapb.Patch{
+		Patch: sanitizer.UTF8(name),
+		File:  sanitizer.UTF8(fileName(file)),
+		Line:  line,
+	}
+	if header := file.PatchHeader; header != nil {
+		metadata.Commit = header.SHA
+		if header.Author != nil {
+			metadata.Email = sanitizer.UTF8(header.Author.Email)
+		}
+		if !header.AuthorDate.IsZero() {
+			metadata.Timestamp = header.AuthorDate.String()
+		}
+	}
+	return &source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_Patch{Patch: metadata},
+	}
+}
@@ -0,0 +1,138 @@
+package patch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// gitPatch is a patch as git show prints it.
+const gitPatch = `commit 0123456789abcdef0123456789abcdef01234567
+Author: Jane Doe <jane@example.com>
+Date:   Sun Jan 2 03:04:05 2022 +0000
+
+    Add config
+
+diff --git a/config.yml b/config.yml
+index 1111111..2222222 100644
+--- a/config.yml
++++ b/config.yml
+@@ -1,4 +1,6 @@
+ name: app
+-token: old
++token: new
++secret: abc
+ region: us
++key: def
+ debug: false
+diff --git a/removed.txt b/removed.txt
+deleted file mode 100644
+index 3333333..0000000
+--- a/removed.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-password=gone
+`
+
+// plainPatch is a unified diff made without git.
+const plainPatch = `--- a/settings.env	2022-01-01 00:00:00.000000000 +0000
++++ b/settings.env	2022-01-02 00:00:00.000000000 +0000
+@@ -10,2 +10,3 @@
+ HOST=example.com
++API_KEY=xyz
+ PORT=80
+`
+
+func TestSource_Chunks(t *testing.T) {
+	dir := t.TempDir()
+	gitPath := filepath.Join(dir, "change.patch")
+	if err := os.WriteFile(gitPath, []byte(gitPatch), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	conn, err := anypb.New(&sourcespb.Patch{Paths: []string{gitPath, "-"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := Source{}
+	if err := s.Init(ctx, "test patch", 0, 0, false, conn, 1); err != nil {
+		t.Fatal(err)
+	}
+	s.stdin = strings.NewReader(plainPatch)
+
+	chunksChan := make(chan *sources.Chunk, 10)
+	if err := s.Chunks(ctx, chunksChan); err != nil {
+		t.Fatal(err)
+	}
+	close(chunksChan)
+
+	var got []*source_metadatapb.Patch
+	var gotData []string
+	for chunk := range chunksChan {
+		got = append(got, chunk.SourceMetadata.GetPatch())
+		gotData = append(gotData, string(chunk.Data))
+	}
+	commit := func(line int64) *source_metadatapb.Patch {
+		return &source_metadatapb.Patch{
+			Patch:     gitPath,
+			File:      "config.yml",
+			Line:      line,
+			Commit:    "0123456789abcdef0123456789abcdef01234567",
+			Email:     "jane@example.com",
+			Timestamp: "2022-01-02 03:04:05 +0000 UTC",
+		}
+	}
+	want := []*source_metadatapb.Patch{
+		commit(2),
+		commit(5),
+		{Patch: "stdin", File: "settings.env", Line: 11},
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("metadata diff: (-got +want)\n%s", diff)
+	}
+	wantData := []string{"token: new\nsecret: abc\n", "key: def\n", "API_KEY=xyz\n"}
+	if diff := pretty.Compare(gotData, wantData); diff != "" {
+		t.Errorf("data diff: (-got +want)\n%s", diff)
+	}
+}
+
+func TestValidateConnection(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		paths   []string
+		wantErr string
+	}{
+		{name: "no paths", wantErr: "paths"},
+		{name: "missing", paths: []string{filepath.Join(dir, "missing.patch")}, wantErr: "could not read patch"},
+		{name: "directory", paths: []string{dir}, wantErr: "is a directory"},
+		{name: "stdin twice", paths: []string{"-", "-"}, wantErr: "only be read once"},
+		{name: "stdin", paths: []string{"-"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateConnection(&sourcespb.Patch{Paths: tt.paths})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateConnection() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateConnection() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
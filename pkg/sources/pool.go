@@ -0,0 +1,51 @@
+package sources
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// Pool runs functions concurrently, no more than its concurrency at once,
+// such as a source's fetches of the objects or repositories it scans.
+type Pool struct {
+	sem *semaphore.Weighted
+	wg  sync.WaitGroup
+
+	errOnce sync.Once
+	err     error
+}
+
+// NewPool returns a pool that runs up to concurrency functions at once.
+func NewPool(concurrency int) *Pool {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Pool{sem: semaphore.NewWeighted(int64(concurrency))}
+}
+
+// Go runs fn in a new goroutine once the pool has room for it, blocking
+// until then. It returns ctx's error, without running fn, if ctx is done
+// first.
+func (p *Pool) Go(ctx context.Context, fn func() error) error {
+	if err := p.sem.Acquire(ctx, 1); err != nil {
+		return err
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.sem.Release(1)
+		defer p.wg.Done()
+		if err := fn(); err != nil {
+			p.errOnce.Do(func() { p.err = err })
+		}
+	}()
+	return nil
+}
+
+// Wait waits for the functions the pool runs to return, and returns the
+// first error one returned.
+func (p *Pool) Wait() error {
+	p.wg.Wait()
+	return p.err
+}
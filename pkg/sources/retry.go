@@ -0,0 +1,152 @@
+package sources
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
+)
+
+// Retries are how the items of a source that fail with errors that may not
+// recur, such as throttling and connection resets, are retried once the rest
+// of the source has been scanned.
+type Retries struct {
+	// MaxAttempts is the most times an item is tried, counting the first.
+	// One turns retries off.
+	MaxAttempts int
+	// Backoff is the wait before an item is first retried. It doubles with
+	// each attempt, up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetries are the retries of sources whose context has none.
+var DefaultRetries = Retries{
+	MaxAttempts: 3,
+	Backoff:     time.Second,
+	MaxBackoff:  30 * time.Second,
+}
+
+type retriesKey struct{}
+
+// WithRetries returns a copy of ctx in which the failed items of sources are
+// retried as retries set. Retries that are zero are the default ones.
+func WithRetries(ctx context.Context, retries Retries) context.Context {
+	return context.WithValue(ctx, retriesKey{}, retries)
+}
+
+// RetriesFromContext returns the retries stored in ctx, with the default ones
+// in place of those that aren't set.
+func RetriesFromContext(ctx context.Context) Retries {
+	retries, _ := ctx.Value(retriesKey{}).(Retries)
+	if retries.MaxAttempts <= 0 {
+		retries.MaxAttempts = DefaultRetries.MaxAttempts
+	}
+	if retries.Backoff <= 0 {
+		retries.Backoff = DefaultRetries.Backoff
+	}
+	if retries.MaxBackoff <= 0 {
+		retries.MaxBackoff = DefaultRetries.MaxBackoff
+	}
+	return retries
+}
+
+// RetryQueue holds the items of a source that failed with retriable errors,
+// to be retried after the rest of the source has been scanned, when
+// throttling has likely eased. Items that fail for good are recorded as item
+// errors of the source's progress, so that scans don't silently have gaps.
+type RetryQueue struct {
+	progress *Progress
+	mu       sync.Mutex
+	items    []retryItem
+}
+
+type retryItem struct {
+	item  string
+	err   error
+	retry func(context.Context) error
+}
+
+// NewRetryQueue returns a queue that records failures in progress.
+func NewRetryQueue(progress *Progress) *RetryQueue {
+	return &RetryQueue{progress: progress}
+}
+
+// Fail records that item failed with err. If err is retriable, retry is
+// queued to scan the item again. Otherwise it's recorded as an item error.
+func (q *RetryQueue) Fail(item string, err error, retry func(context.Context) error) {
+	if _, retriable := ClassifyError(err); !retriable {
+		q.progress.AddItemError(item, err)
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.items = append(q.items, retryItem{item: item, err: err, retry: retry})
+}
+
+// Len returns the number of items waiting to be retried.
+func (q *RetryQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// Run retries the queued items in turn, up to the retries in ctx, backing
+// off between the attempts of each. Items that still fail, with the last
+// error, are recorded as item errors, as are those left when ctx is done.
+func (q *RetryQueue) Run(ctx context.Context) {
+	q.mu.Lock()
+	items := q.items
+	q.items = nil
+	q.mu.Unlock()
+	if len(items) == 0 {
+		return
+	}
+
+	retries := RetriesFromContext(ctx)
+	logger := log.FromContext(ctx)
+	logger.Info("retrying failed items", "items", len(items), "max_attempts", retries.MaxAttempts)
+	recovered := 0
+	for _, it := range items {
+		attempts, err := q.retry(ctx, retries, it)
+		if err == nil {
+			recovered++
+			q.progress.addRecovered()
+			logger.V(2).Info("retried item", "item", it.item, "attempts", attempts)
+			continue
+		}
+		itemErr := NewItemError(it.item, err)
+		itemErr.Attempts = attempts
+		q.progress.addItemError(itemErr)
+	}
+	logger.Info("retried failed items", "items", len(items), "recovered", recovered)
+}
+
+// retry tries it until it succeeds, fails with an error that isn't
+// retriable, or runs out of attempts, returning the attempts made and the
+// last error.
+func (q *RetryQueue) retry(ctx context.Context, retries Retries, it retryItem) (int, error) {
+	attempts, err := 1, it.err
+	backoff := retries.Backoff
+	for attempts < retries.MaxAttempts {
+		if _, retriable := ClassifyError(err); !retriable {
+			break
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempts, err
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > retries.MaxBackoff {
+			backoff = retries.MaxBackoff
+		}
+		attempts++
+		if err = it.retry(ctx); err == nil {
+			return attempts, nil
+		}
+	}
+	return attempts, err
+}
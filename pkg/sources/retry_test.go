@@ -0,0 +1,68 @@
+package sources
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestRetryQueue(t *testing.T) {
+	ctx := WithRetries(context.Background(), Retries{MaxAttempts: 3, Backoff: time.Millisecond})
+	var p Progress
+	q := NewRetryQueue(&p)
+
+	throttled := errors.New("GET https://gitlab.example.com/api/v4/projects: 429 Too Many Requests")
+	attempts := map[string]int{}
+	failTimes := func(item string, n int, err error) func(context.Context) error {
+		return func(context.Context) error {
+			attempts[item]++
+			if attempts[item] < n {
+				return err
+			}
+			return nil
+		}
+	}
+	q.Fail("recovers", throttled, failTimes("recovers", 1, throttled))
+	q.Fail("keeps failing", throttled, failTimes("keeps failing", 10, throttled))
+	q.Fail("denied on retry", throttled, failTimes("denied on retry", 10, errors.New("GET https://gitlab.example.com/api/v4/projects: 403 Forbidden")))
+	q.Fail("denied", errors.New("fatal: Authentication failed"), failTimes("denied", 1, nil))
+	if q.Len() != 3 {
+		t.Errorf("Len() = %d, want the 3 retriable items", q.Len())
+	}
+
+	q.Run(ctx)
+	if q.Len() != 0 {
+		t.Errorf("Len() = %d after Run(), want 0", q.Len())
+	}
+	wantAttempts := map[string]int{"recovers": 1, "keeps failing": 2, "denied on retry": 1}
+	if diff := pretty.Compare(attempts, wantAttempts); diff != "" {
+		t.Errorf("retries diff: (-got +want)\n%s", diff)
+	}
+	if got := p.RecoveredItems(); got != 1 {
+		t.Errorf("RecoveredItems() = %d, want 1", got)
+	}
+	want := []ItemError{
+		{Item: "denied", Class: ErrorClassAccessDenied, Message: "fatal: Authentication failed", Attempts: 1},
+		{Item: "keeps failing", Class: ErrorClassRateLimited, Retriable: true, Message: throttled.Error(), Attempts: 3},
+		{Item: "denied on retry", Class: ErrorClassAccessDenied, Message: "GET https://gitlab.example.com/api/v4/projects: 403 Forbidden", Attempts: 2},
+	}
+	if diff := pretty.Compare(p.FailedItems(), want); diff != "" {
+		t.Errorf("FailedItems() diff: (-got +want)\n%s", diff)
+	}
+}
+
+func TestRetryQueue_cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(WithRetries(context.Background(), Retries{Backoff: time.Hour}))
+	cancel()
+	var p Progress
+	q := NewRetryQueue(&p)
+	q.Fail("s3://bucket/key", context.DeadlineExceeded, func(context.Context) error { return nil })
+
+	q.Run(ctx)
+	if failed := p.FailedItems(); len(failed) != 1 || failed[0].Attempts != 1 {
+		t.Errorf("FailedItems() = %+v, want the item tried once", failed)
+	}
+}
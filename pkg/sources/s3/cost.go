@@ -0,0 +1,105 @@
+package s3
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// Reasons objects are skipped, as counted in the source's progress.
+const (
+	skippedStorageClass = "storage_class"
+	skippedGetBudget    = "get_budget"
+	skippedTooLarge     = "too_large"
+	skippedEmpty        = "empty"
+	skippedIgnoredType  = "ignored_type"
+	// skippedPrefixErrors counts the objects left in a prefix after too
+	// many consecutive errors getting its others.
+	skippedPrefixErrors = "prefix_errors"
+)
+
+// isStorageClass reports whether class is one of S3's storage classes.
+func isStorageClass(class string) bool {
+	for _, c := range s3.ObjectStorageClass_Values() {
+		if class == c {
+			return true
+		}
+	}
+	return false
+}
+
+// skipStorageClass reports whether obj is of a storage class that isn't
+// downloaded, such as an archive class that's costly or impossible to read
+// without restoring it first.
+func (s *Source) skipStorageClass(obj *s3.Object) bool {
+	class := aws.StringValue(obj.StorageClass)
+	for _, skip := range s.conn.SkipStorageClasses {
+		if class == skip {
+			return true
+		}
+	}
+	return false
+}
+
+// takeGetRequest reports whether an object may be downloaded within the
+// budget of GET requests, counting the request if so.
+func (s *Source) takeGetRequest() bool {
+	if s.conn.MaxGetRequests <= 0 {
+		return true
+	}
+	return atomic.AddInt64(&s.getRequests, 1) <= s.conn.MaxGetRequests
+}
+
+// getBudgetSpent reports whether no more objects may be downloaded within
+// the budget of GET requests.
+func (s *Source) getBudgetSpent() bool {
+	return s.conn.MaxGetRequests > 0 && atomic.LoadInt64(&s.getRequests) >= s.conn.MaxGetRequests
+}
+
+// listNewestFirst lists all of bucket's objects, most recently modified
+// first. The whole listing is held in memory to sort it.
+func (s *Source) listNewestFirst(ctx context.Context, client *s3.S3, bucket string) ([]*s3.Object, error) {
+	var mu sync.Mutex
+	var objects []*s3.Object
+	err := s.listObjects(ctx, client, bucket, func(page *s3.ListObjectsV2Output) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		objects = append(objects, page.Contents...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortNewestFirst(objects)
+	return objects, nil
+}
+
+func sortNewestFirst(objects []*s3.Object) {
+	sort.SliceStable(objects, func(i, j int) bool {
+		return aws.TimeValue(objects[i].LastModified).After(aws.TimeValue(objects[j].LastModified))
+	})
+}
+
+// scanNewestFirst scans bucket's objects, most recently modified first, in
+// pages the size of a listing's, until the budget of GET requests is spent.
+func (s *Source) scanNewestFirst(ctx context.Context, client *s3.S3, chunksChan chan *sources.Chunk, bucket string, errorCount *sync.Map, pool *sources.Pool) error {
+	objects, err := s.listNewestFirst(ctx, client, bucket)
+	if err != nil {
+		return err
+	}
+	const pageSize = 1000
+	for start := 0; start < len(objects) && !s.getBudgetSpent(); start += pageSize {
+		end := start + pageSize
+		if end > len(objects) {
+			end = len(objects)
+		}
+		s.pageChunker(ctx, client, chunksChan, bucket, &s3.ListObjectsV2Output{Contents: objects[start:end]}, errorCount, pool)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package s3
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+func TestSource_skipStorageClass(t *testing.T) {
+	s := &Source{conn: &sourcespb.S3{SkipStorageClasses: []string{s3.ObjectStorageClassGlacier, s3.ObjectStorageClassDeepArchive}}}
+	for class, want := range map[string]bool{
+		s3.ObjectStorageClassGlacier:     true,
+		s3.ObjectStorageClassDeepArchive: true,
+		s3.ObjectStorageClassGlacierIr:   false,
+		s3.ObjectStorageClassStandard:    false,
+	} {
+		if got := s.skipStorageClass(&s3.Object{StorageClass: aws.String(class)}); got != want {
+			t.Errorf("skipStorageClass(%s) = %t, want %t", class, got, want)
+		}
+	}
+	if s.skipStorageClass(&s3.Object{}) {
+		t.Error("skipStorageClass() skipped an object without a storage class")
+	}
+}
+
+func TestSource_takeGetRequest(t *testing.T) {
+	unlimited := &Source{conn: &sourcespb.S3{}}
+	for i := 0; i < 5; i++ {
+		if !unlimited.takeGetRequest() || unlimited.getBudgetSpent() {
+			t.Fatal("requests were limited without a budget")
+		}
+	}
+
+	s := &Source{conn: &sourcespb.S3{MaxGetRequests: 2}}
+	for i, want := range []bool{true, true, false, false} {
+		if got := s.takeGetRequest(); got != want {
+			t.Errorf("request %d allowed = %t, want %t", i+1, got, want)
+		}
+	}
+	if !s.getBudgetSpent() {
+		t.Error("getBudgetSpent() = false after the budget was spent")
+	}
+}
+
+func TestSortNewestFirst(t *testing.T) {
+	now := time.Now()
+	objects := []*s3.Object{
+		{Key: aws.String("old"), LastModified: aws.Time(now.Add(-48 * time.Hour))},
+		{Key: aws.String("new"), LastModified: aws.Time(now)},
+		{Key: aws.String("unknown")},
+		{Key: aws.String("recent"), LastModified: aws.Time(now.Add(-time.Hour))},
+	}
+	sortNewestFirst(objects)
+	var got []string
+	for _, obj := range objects {
+		got = append(got, *obj.Key)
+	}
+	want := []string{"new", "recent", "old", "unknown"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("sortNewestFirst() = %v, want %v", got, want)
+		}
+	}
+}
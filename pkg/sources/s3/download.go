@@ -0,0 +1,24 @@
+package s3
+
+import (
+	"io"
+	"time"
+)
+
+// objectTimeout is how long an object's download may go without progress.
+// Throttled downloads of large objects take much longer than this, so it
+// doesn't bound how long a whole download takes.
+const objectTimeout = 5 * time.Second
+
+// stallReader reads r, stopping timer while it isn't reading, such as while
+// the download is throttled, and restarting it for each read.
+type stallReader struct {
+	r     io.Reader
+	timer *time.Timer
+}
+
+func (r *stallReader) Read(p []byte) (int, error) {
+	r.timer.Reset(objectTimeout)
+	defer r.timer.Stop()
+	return r.r.Read(p)
+}
@@ -0,0 +1,87 @@
+package s3
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// maxShardDepth is how many levels of a bucket's prefixes are listed, at
+// most, to split it into as many shards as it's listed with at once.
+const maxShardDepth = 3
+
+// listObjects calls fn with each page of bucket's objects, until fn returns
+// false. With a listing concurrency above 1, the bucket is split into shards
+// by the prefixes of its keys, as delimited by "/", which are listed at once,
+// and fn is called from several goroutines. A bucket without prefixes is
+// listed serially either way.
+func (s *Source) listObjects(ctx context.Context, client *s3.S3, bucket string, fn func(page *s3.ListObjectsV2Output) bool) error {
+	concurrency := int(s.conn.ListingConcurrency)
+	if concurrency <= 1 {
+		return client.ListObjectsV2PagesWithContext(
+			ctx, &s3.ListObjectsV2Input{Bucket: &bucket},
+			func(page *s3.ListObjectsV2Output, last bool) bool {
+				return fn(page)
+			})
+	}
+
+	var stopped int32
+	visit := func(page *s3.ListObjectsV2Output, last bool) bool {
+		if atomic.LoadInt32(&stopped) == 1 {
+			return false
+		}
+		if !fn(page) {
+			atomic.StoreInt32(&stopped, 1)
+			return false
+		}
+		return true
+	}
+
+	// Each level of prefixes is listed with the delimiter, which lists the
+	// objects directly under them and the prefixes of the next level, until
+	// there are enough prefixes to list at once.
+	shards := []string{""}
+	for depth := 0; depth < maxShardDepth && len(shards) < concurrency; depth++ {
+		var mu sync.Mutex
+		var next []string
+		err := forEachPrefix(ctx, shards, concurrency, func(prefix string) error {
+			return client.ListObjectsV2PagesWithContext(
+				ctx, &s3.ListObjectsV2Input{Bucket: &bucket, Prefix: aws.String(prefix), Delimiter: aws.String("/")},
+				func(page *s3.ListObjectsV2Output, last bool) bool {
+					mu.Lock()
+					for _, p := range page.CommonPrefixes {
+						next = append(next, aws.StringValue(p.Prefix))
+					}
+					mu.Unlock()
+					return visit(page, last)
+				})
+		})
+		if err != nil || atomic.LoadInt32(&stopped) == 1 {
+			return err
+		}
+		shards = next
+	}
+
+	return forEachPrefix(ctx, shards, concurrency, func(prefix string) error {
+		return client.ListObjectsV2PagesWithContext(
+			ctx, &s3.ListObjectsV2Input{Bucket: &bucket, Prefix: aws.String(prefix)}, visit)
+	})
+}
+
+// forEachPrefix calls fn with each of prefixes, concurrency at once, and
+// returns the first error it returns.
+func forEachPrefix(ctx context.Context, prefixes []string, concurrency int, fn func(prefix string) error) error {
+	pool := sources.NewPool(concurrency)
+	for _, prefix := range prefixes {
+		prefix := prefix
+		if err := pool.Go(ctx, func() error { return fn(prefix) }); err != nil {
+			break
+		}
+	}
+	return pool.Wait()
+}
@@ -0,0 +1,127 @@
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+type listBucketResult struct {
+	XMLName        xml.Name `xml:"ListBucketResult"`
+	IsTruncated    bool
+	Contents       []listedObject
+	CommonPrefixes []listedPrefix
+}
+
+type listedObject struct {
+	Key  string
+	Size int64
+}
+
+type listedPrefix struct {
+	Prefix string
+}
+
+// newListingServer returns a client of a fake S3 that lists keys in one page,
+// and a function returning the prefixes it was asked to list, followed by the
+// delimiter if one was given.
+func newListingServer(t *testing.T, keys []string) (*s3.S3, func() []string) {
+	var mu sync.Mutex
+	var listed []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		prefix, delimiter := query.Get("prefix"), query.Get("delimiter")
+		mu.Lock()
+		listed = append(listed, prefix+delimiter)
+		mu.Unlock()
+
+		var result listBucketResult
+		seen := map[string]bool{}
+		for _, key := range keys {
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			if i := strings.Index(key[len(prefix):], delimiter); delimiter != "" && i >= 0 {
+				p := key[:len(prefix)+i+1]
+				if !seen[p] {
+					seen[p] = true
+					result.CommonPrefixes = append(result.CommonPrefixes, listedPrefix{Prefix: p})
+				}
+				continue
+			}
+			result.Contents = append(result.Contents, listedObject{Key: key, Size: 1})
+		}
+		_ = xml.NewEncoder(w).Encode(result)
+	}))
+	t.Cleanup(server.Close)
+
+	sess, err := session.NewSession(&aws.Config{
+		Endpoint:         aws.String(server.URL),
+		Region:           aws.String("us-east-1"),
+		S3ForcePathStyle: aws.Bool(true),
+		Credentials:      credentials.AnonymousCredentials,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s3.New(sess), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		sort.Strings(listed)
+		return listed
+	}
+}
+
+func TestSource_listObjects(t *testing.T) {
+	keys := []string{"root.env", "a/1.env", "a/2.env", "a/x/3.env", "b/4.env", "c/y/z/5.env"}
+	tests := []struct {
+		name        string
+		concurrency int32
+		wantListed  []string
+	}{
+		{name: "serial", wantListed: []string{""}},
+		{name: "shards of the first level", concurrency: 3, wantListed: []string{"/", "a/", "b/", "c/"}},
+		{name: "shards of deeper levels", concurrency: 4, wantListed: []string{"/", "a//", "a/x//", "b//", "c//", "c/y//", "c/y/z/"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, listed := newListingServer(t, keys)
+			s := &Source{conn: &sourcespb.S3{ListingConcurrency: tt.concurrency}}
+
+			var mu sync.Mutex
+			var got []string
+			err := s.listObjects(context.Background(), client, "bucket", func(page *s3.ListObjectsV2Output) bool {
+				mu.Lock()
+				defer mu.Unlock()
+				for _, obj := range page.Contents {
+					got = append(got, *obj.Key)
+				}
+				return true
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			sort.Strings(got)
+			want := append([]string(nil), keys...)
+			sort.Strings(want)
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("listed objects %v, want %v", got, want)
+			}
+			if l := listed(); strings.Join(l, ",") != strings.Join(tt.wantListed, ",") {
+				t.Errorf("listed prefixes %q, want %q", l, tt.wantListed)
+			}
+		})
+	}
+}
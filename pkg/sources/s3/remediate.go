@@ -0,0 +1,344 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+// Remediation actions taken on objects with verified secrets.
+const (
+	// RemediateTag adds a tag to the object.
+	RemediateTag = "tag"
+	// RemediateQuarantine moves the object under a quarantine prefix in its
+	// bucket.
+	RemediateQuarantine = "quarantine"
+)
+
+// RemediationKey is the key of the ExtraData of findings that says what was
+// done to the object the secret is in.
+const RemediationKey = "remediation"
+
+// maxObjectTags is the most tags S3 objects can have.
+const maxObjectTags = 10
+
+// RemediationOptions configures a Remediator.
+type RemediationOptions struct {
+	// Action is RemediateTag or RemediateQuarantine.
+	Action string
+	// TagKey and TagValue are the tag RemediateTag adds.
+	TagKey   string
+	TagValue string
+	// QuarantinePrefix is the prefix RemediateQuarantine moves objects under.
+	QuarantinePrefix string
+	// DryRun logs what would be done to objects without changing them.
+	DryRun bool
+}
+
+// objectAPI is the part of the S3 client used to remediate objects.
+type objectAPI interface {
+	GetObjectTaggingWithContext(ctx aws.Context, input *s3.GetObjectTaggingInput, opts ...request.Option) (*s3.GetObjectTaggingOutput, error)
+	PutObjectTaggingWithContext(ctx aws.Context, input *s3.PutObjectTaggingInput, opts ...request.Option) (*s3.PutObjectTaggingOutput, error)
+	CopyObjectWithContext(ctx aws.Context, input *s3.CopyObjectInput, opts ...request.Option) (*s3.CopyObjectOutput, error)
+	DeleteObjectWithContext(ctx aws.Context, input *s3.DeleteObjectInput, opts ...request.Option) (*s3.DeleteObjectOutput, error)
+}
+
+// identityAPI and policyAPI are the parts of the STS and IAM clients used to
+// check that remediation is allowed.
+type identityAPI interface {
+	GetCallerIdentityWithContext(ctx aws.Context, input *sts.GetCallerIdentityInput, opts ...request.Option) (*sts.GetCallerIdentityOutput, error)
+}
+
+type policyAPI interface {
+	GetRoleWithContext(ctx aws.Context, input *iam.GetRoleInput, opts ...request.Option) (*iam.GetRoleOutput, error)
+	SimulatePrincipalPolicyPagesWithContext(ctx aws.Context, input *iam.SimulatePrincipalPolicyInput, fn func(*iam.SimulatePolicyResponse, bool) bool, opts ...request.Option) error
+}
+
+// Remediator tags or quarantines the S3 objects verified secrets are found
+// in, so the owners of buckets see which objects need cleaning up. Only
+// objects in the buckets named in the scan's connection are remediated, and
+// each only once.
+type Remediator struct {
+	opts    RemediationOptions
+	buckets map[string]bool
+	// clientFor returns a client in the region of bucket.
+	clientFor func(ctx context.Context, bucket string) (objectAPI, error)
+
+	mu      sync.Mutex
+	clients map[string]objectAPI
+	// done holds what was done to each object, by bucket and key.
+	done map[string]string
+}
+
+// NewRemediator returns a Remediator of the objects found by a scan of conn.
+// It fails unless conn names the buckets to scan, and the IAM policies of its
+// credentials allow the action in all of them.
+func NewRemediator(ctx context.Context, conn *sourcespb.S3, opts RemediationOptions) (*Remediator, error) {
+	if err := validateRemediation(conn, opts); err != nil {
+		return nil, err
+	}
+	source := &Source{conn: conn}
+	sess, err := source.newSession("us-east-1")
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not create aws session", 0)
+	}
+	if err := checkPermissions(ctx, sts.New(sess), iam.New(sess), conn.Buckets, remediationActions(opts.Action)); err != nil {
+		return nil, err
+	}
+
+	client := s3.New(sess)
+	return newRemediator(conn.Buckets, opts, func(ctx context.Context, bucket string) (objectAPI, error) {
+		region, err := s3manager.GetBucketRegionWithClient(ctx, client, bucket)
+		if err != nil {
+			return nil, errors.WrapPrefix(err, "could not get s3 region for bucket", 0)
+		}
+		return source.newClient(region)
+	}), nil
+}
+
+func newRemediator(buckets []string, opts RemediationOptions, clientFor func(context.Context, string) (objectAPI, error)) *Remediator {
+	r := &Remediator{
+		opts:      opts,
+		buckets:   map[string]bool{},
+		clientFor: clientFor,
+		clients:   map[string]objectAPI{},
+		done:      map[string]string{},
+	}
+	for _, bucket := range buckets {
+		r.buckets[bucket] = true
+	}
+	return r
+}
+
+// validateRemediation checks that opts are complete, and that conn names the
+// buckets to scan with credentials that can change them.
+func validateRemediation(conn *sourcespb.S3, opts RemediationOptions) error {
+	switch opts.Action {
+	case RemediateTag:
+		if opts.TagKey == "" {
+			return errors.New("a tag key is required to tag objects")
+		}
+	case RemediateQuarantine:
+		if opts.QuarantinePrefix == "" {
+			return errors.New("a quarantine prefix is required to quarantine objects")
+		}
+	default:
+		return errors.Errorf("unknown remediation action %q, must be %s or %s", opts.Action, RemediateTag, RemediateQuarantine)
+	}
+	if len(conn.Buckets) == 0 {
+		return errors.New("objects are only remediated in buckets named explicitly")
+	}
+	if _, ok := conn.GetCredential().(*sourcespb.S3_Unauthenticated); ok || conn.GetCredential() == nil {
+		return errors.New("objects can't be remediated without credentials")
+	}
+	return nil
+}
+
+// remediationActions returns the S3 actions an IAM policy must allow for the
+// remediation action.
+func remediationActions(action string) []string {
+	if action == RemediateQuarantine {
+		// Copies keep the object's tags.
+		return []string{"s3:GetObject", "s3:GetObjectTagging", "s3:PutObject", "s3:PutObjectTagging", "s3:DeleteObject"}
+	}
+	return []string{"s3:GetObjectTagging", "s3:PutObjectTagging"}
+}
+
+// checkPermissions simulates the IAM policies of the caller, checking that
+// they allow actions on the objects of every bucket, so a scan doesn't leave
+// objects half remediated. Bucket policies and organization policies aren't
+// simulated.
+func checkPermissions(ctx context.Context, identity identityAPI, policies policyAPI, buckets, actions []string) error {
+	caller, err := identity.GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return errors.WrapPrefix(err, "could not look up the aws identity to check its permissions", 0)
+	}
+	callerARN, err := arn.Parse(aws.StringValue(caller.Arn))
+	if err != nil {
+		return errors.WrapPrefix(err, "could not parse the aws identity", 0)
+	}
+
+	var principal string
+	switch {
+	case callerARN.Service == "iam" && callerARN.Resource == "root":
+		// The root user's permissions can't be simulated, and are only
+		// limited by organization policies.
+		return nil
+	case callerARN.Service == "iam" && strings.HasPrefix(callerARN.Resource, "user/"):
+		principal = callerARN.String()
+	case callerARN.Service == "sts" && strings.HasPrefix(callerARN.Resource, "assumed-role/"):
+		// Sessions are simulated as their role, whose ARN has a path the
+		// session's ARN doesn't.
+		name := strings.Split(callerARN.Resource, "/")[1]
+		role, err := policies.GetRoleWithContext(ctx, &iam.GetRoleInput{RoleName: aws.String(name)})
+		if err != nil {
+			return errors.WrapPrefix(err, fmt.Sprintf("could not look up role %s to check its permissions", name), 0)
+		}
+		principal = aws.StringValue(role.Role.Arn)
+	default:
+		return errors.Errorf("the permissions of %s can't be checked, remediate with an IAM user or role", callerARN)
+	}
+
+	var resources []*string
+	for _, bucket := range buckets {
+		resources = append(resources, aws.String(fmt.Sprintf("arn:%s:s3:::%s/*", callerARN.Partition, bucket)))
+	}
+	var denied []string
+	err = policies.SimulatePrincipalPolicyPagesWithContext(ctx, &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(principal),
+		ActionNames:     aws.StringSlice(actions),
+		ResourceArns:    resources,
+	}, func(page *iam.SimulatePolicyResponse, _ bool) bool {
+		for _, result := range page.EvaluationResults {
+			if aws.StringValue(result.EvalDecision) != iam.PolicyEvaluationDecisionTypeAllowed {
+				denied = append(denied, fmt.Sprintf("%s on %s", aws.StringValue(result.EvalActionName), aws.StringValue(result.EvalResourceName)))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return errors.WrapPrefix(err, fmt.Sprintf("could not simulate the policies of %s to check its permissions", principal), 0)
+	}
+	if len(denied) > 0 {
+		return errors.Errorf("the policies of %s don't allow %s", principal, strings.Join(denied, ", "))
+	}
+	return nil
+}
+
+// Remediate tags or quarantines the object a verified secret was found in,
+// setting RemediationKey in r's ExtraData to what was done. Findings that
+// aren't verified, or weren't found in an object of the buckets being
+// remediated, are left alone, as are objects already in quarantine.
+func (r *Remediator) Remediate(ctx context.Context, result *detectors.ResultWithMetadata) error {
+	meta := result.SourceMetadata.GetS3()
+	if !result.Verified || meta == nil || !r.buckets[meta.Bucket] || meta.File == "" {
+		return nil
+	}
+	if r.opts.Action == RemediateQuarantine && strings.HasPrefix(meta.File, r.opts.QuarantinePrefix) {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	object := meta.Bucket + "/" + meta.File
+	done, ok := r.done[object]
+	if !ok {
+		var err error
+		done, err = r.remediate(ctx, meta.Bucket, meta.File)
+		if err != nil {
+			// Objects that couldn't be remediated aren't tried again for
+			// each of their secrets.
+			r.done[object] = ""
+			return errors.WrapPrefix(err, fmt.Sprintf("could not %s s3://%s", r.opts.Action, object), 0)
+		}
+		r.done[object] = done
+	}
+	if done == "" {
+		return nil
+	}
+	if result.ExtraData == nil {
+		result.ExtraData = map[string]string{}
+	}
+	result.ExtraData[RemediationKey] = done
+	return nil
+}
+
+// remediate takes the action on an object, returning what was done.
+func (r *Remediator) remediate(ctx context.Context, bucket, key string) (string, error) {
+	logger := log.FromContext(ctx).WithValues("bucket", bucket, "key", key)
+	quarantined := r.opts.QuarantinePrefix + key
+	if r.opts.DryRun {
+		if r.opts.Action == RemediateQuarantine {
+			logger.Info("would quarantine object", "quarantined_key", quarantined)
+			return fmt.Sprintf("would quarantine to s3://%s/%s", bucket, quarantined), nil
+		}
+		logger.Info("would tag object", "tag", r.opts.TagKey+"="+r.opts.TagValue)
+		return "would tag", nil
+	}
+
+	client, ok := r.clients[bucket]
+	if !ok {
+		var err error
+		client, err = r.clientFor(ctx, bucket)
+		if err != nil {
+			return "", err
+		}
+		r.clients[bucket] = client
+	}
+
+	if r.opts.Action == RemediateQuarantine {
+		_, err := client.CopyObjectWithContext(ctx, &s3.CopyObjectInput{
+			Bucket:     aws.String(bucket),
+			Key:        aws.String(quarantined),
+			CopySource: aws.String(copySource(bucket, key)),
+		})
+		if err != nil {
+			return "", errors.WrapPrefix(err, "could not copy object to quarantine", 0)
+		}
+		// The object is only deleted once it's safely copied.
+		_, err = client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+		if err != nil {
+			return "", errors.WrapPrefix(err, "could not delete object copied to quarantine", 0)
+		}
+		logger.Info("quarantined object", "quarantined_key", quarantined)
+		return fmt.Sprintf("quarantined to s3://%s/%s", bucket, quarantined), nil
+	}
+
+	out, err := client.GetObjectTaggingWithContext(ctx, &s3.GetObjectTaggingInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return "", errors.WrapPrefix(err, "could not get object tags", 0)
+	}
+	tags, err := withTag(out.TagSet, r.opts.TagKey, r.opts.TagValue)
+	if err != nil {
+		return "", err
+	}
+	_, err = client.PutObjectTaggingWithContext(ctx, &s3.PutObjectTaggingInput{
+		Bucket:  aws.String(bucket),
+		Key:     aws.String(key),
+		Tagging: &s3.Tagging{TagSet: tags},
+	})
+	if err != nil {
+		return "", errors.WrapPrefix(err, "could not tag object", 0)
+	}
+	logger.Info("tagged object", "tag", r.opts.TagKey+"="+r.opts.TagValue)
+	return "tagged", nil
+}
+
+// withTag returns tags with key set to value, keeping the others, since
+// putting tags replaces all of an object's tags.
+func withTag(tags []*s3.Tag, key, value string) ([]*s3.Tag, error) {
+	var updated []*s3.Tag
+	for _, tag := range tags {
+		if aws.StringValue(tag.Key) != key {
+			updated = append(updated, tag)
+		}
+	}
+	if len(updated) >= maxObjectTags {
+		return nil, errors.Errorf("object already has the most tags it can have, %d", maxObjectTags)
+	}
+	return append(updated, &s3.Tag{Key: aws.String(key), Value: aws.String(value)}), nil
+}
+
+// copySource returns the URL encoded source of a copy of an object.
+func copySource(bucket, key string) string {
+	segments := strings.Split(bucket+"/"+key, "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(url.QueryEscape(segment), "+", "%20")
+	}
+	return strings.Join(segments, "/")
+}
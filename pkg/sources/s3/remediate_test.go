@@ -0,0 +1,244 @@
+package s3
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+// fakeObjects records the calls made to remediate objects.
+type fakeObjects struct {
+	tags  []*s3.Tag
+	calls []string
+}
+
+func (f *fakeObjects) GetObjectTaggingWithContext(_ aws.Context, input *s3.GetObjectTaggingInput, _ ...request.Option) (*s3.GetObjectTaggingOutput, error) {
+	f.calls = append(f.calls, "get tags "+*input.Key)
+	return &s3.GetObjectTaggingOutput{TagSet: f.tags}, nil
+}
+
+func (f *fakeObjects) PutObjectTaggingWithContext(_ aws.Context, input *s3.PutObjectTaggingInput, _ ...request.Option) (*s3.PutObjectTaggingOutput, error) {
+	var tags []string
+	for _, tag := range input.Tagging.TagSet {
+		tags = append(tags, *tag.Key+"="+*tag.Value)
+	}
+	f.calls = append(f.calls, "put tags "+*input.Key+" "+strings.Join(tags, ","))
+	return &s3.PutObjectTaggingOutput{}, nil
+}
+
+func (f *fakeObjects) CopyObjectWithContext(_ aws.Context, input *s3.CopyObjectInput, _ ...request.Option) (*s3.CopyObjectOutput, error) {
+	f.calls = append(f.calls, "copy "+*input.CopySource+" to "+*input.Key)
+	return &s3.CopyObjectOutput{}, nil
+}
+
+func (f *fakeObjects) DeleteObjectWithContext(_ aws.Context, input *s3.DeleteObjectInput, _ ...request.Option) (*s3.DeleteObjectOutput, error) {
+	f.calls = append(f.calls, "delete "+*input.Key)
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func s3Result(bucket, key string, verified bool) *detectors.ResultWithMetadata {
+	return &detectors.ResultWithMetadata{
+		SourceMetadata: &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_S3{S3: &source_metadatapb.S3{Bucket: bucket, File: key}}},
+		Result:         detectors.Result{Verified: verified},
+	}
+}
+
+func TestRemediator_Remediate(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      RemediationOptions
+		tags      []*s3.Tag
+		results   []*detectors.ResultWithMetadata
+		wantCalls []string
+		wantDone  []string
+	}{
+		{
+			name: "tag",
+			opts: RemediationOptions{Action: RemediateTag, TagKey: "trufflehog:secret", TagValue: "verified"},
+			tags: []*s3.Tag{{Key: aws.String("team"), Value: aws.String("data")}, {Key: aws.String("trufflehog:secret"), Value: aws.String("old")}},
+			results: []*detectors.ResultWithMetadata{
+				s3Result("bucket", "config.env", true),
+				s3Result("bucket", "config.env", true),
+				s3Result("bucket", "unverified.env", false),
+				s3Result("other", "config.env", true),
+			},
+			wantCalls: []string{"get tags config.env", "put tags config.env team=data,trufflehog:secret=verified"},
+			wantDone:  []string{"tagged", "tagged", "", ""},
+		},
+		{
+			name: "quarantine",
+			opts: RemediationOptions{Action: RemediateQuarantine, QuarantinePrefix: "quarantine/"},
+			results: []*detectors.ResultWithMetadata{
+				s3Result("bucket", "dir/my keys+1.env", true),
+				s3Result("bucket", "quarantine/config.env", true),
+			},
+			wantCalls: []string{"copy bucket/dir/my%20keys%2B1.env to quarantine/dir/my keys+1.env", "delete dir/my keys+1.env"},
+			wantDone:  []string{"quarantined to s3://bucket/quarantine/dir/my keys+1.env", ""},
+		},
+		{
+			name:     "dry run",
+			opts:     RemediationOptions{Action: RemediateTag, TagKey: "trufflehog:secret", DryRun: true},
+			results:  []*detectors.ResultWithMetadata{s3Result("bucket", "config.env", true)},
+			wantDone: []string{"would tag"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := &fakeObjects{tags: tt.tags}
+			r := newRemediator([]string{"bucket"}, tt.opts, func(context.Context, string) (objectAPI, error) {
+				return objects, nil
+			})
+			var done []string
+			for _, result := range tt.results {
+				if err := r.Remediate(context.Background(), result); err != nil {
+					t.Fatal(err)
+				}
+				done = append(done, result.ExtraData[RemediationKey])
+			}
+			if diff := pretty.Compare(objects.calls, tt.wantCalls); diff != "" {
+				t.Errorf("calls diff: (-got +want)\n%s", diff)
+			}
+			if diff := pretty.Compare(done, tt.wantDone); diff != "" {
+				t.Errorf("remediation diff: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRemediator_tooManyTags(t *testing.T) {
+	var tags []*s3.Tag
+	for i := 0; i < maxObjectTags; i++ {
+		tags = append(tags, &s3.Tag{Key: aws.String(strings.Repeat("k", i+1)), Value: aws.String("v")})
+	}
+	objects := &fakeObjects{tags: tags}
+	r := newRemediator([]string{"bucket"}, RemediationOptions{Action: RemediateTag, TagKey: "trufflehog:secret"}, func(context.Context, string) (objectAPI, error) {
+		return objects, nil
+	})
+	if err := r.Remediate(context.Background(), s3Result("bucket", "config.env", true)); err == nil {
+		t.Error("Remediate() replaced an object's tags when it had the most it can")
+	}
+	// The object isn't tried again for its other secrets.
+	if err := r.Remediate(context.Background(), s3Result("bucket", "config.env", true)); err != nil {
+		t.Errorf("Remediate() again error = %v", err)
+	}
+	if len(objects.calls) != 1 {
+		t.Errorf("calls = %v, want the tags got once", objects.calls)
+	}
+}
+
+func TestValidateRemediation(t *testing.T) {
+	keyed := &sourcespb.S3_AccessKey{AccessKey: &credentialspb.KeySecret{Key: "k", Secret: "s"}}
+	tag := RemediationOptions{Action: RemediateTag, TagKey: "trufflehog:secret"}
+	tests := []struct {
+		name    string
+		conn    *sourcespb.S3
+		opts    RemediationOptions
+		wantErr string
+	}{
+		{name: "valid", conn: &sourcespb.S3{Credential: keyed, Buckets: []string{"bucket"}}, opts: tag},
+		{name: "no buckets", conn: &sourcespb.S3{Credential: keyed}, opts: tag, wantErr: "named explicitly"},
+		{name: "unauthenticated", conn: &sourcespb.S3{Credential: &sourcespb.S3_Unauthenticated{}, Buckets: []string{"bucket"}}, opts: tag, wantErr: "without credentials"},
+		{name: "no prefix", conn: &sourcespb.S3{Credential: keyed, Buckets: []string{"bucket"}}, opts: RemediationOptions{Action: RemediateQuarantine}, wantErr: "quarantine prefix"},
+		{name: "unknown action", conn: &sourcespb.S3{Credential: keyed, Buckets: []string{"bucket"}}, opts: RemediationOptions{Action: "delete"}, wantErr: "unknown remediation action"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRemediation(tt.conn, tt.opts)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("validateRemediation() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type fakeIdentity string
+
+func (f fakeIdentity) GetCallerIdentityWithContext(aws.Context, *sts.GetCallerIdentityInput, ...request.Option) (*sts.GetCallerIdentityOutput, error) {
+	return &sts.GetCallerIdentityOutput{Arn: aws.String(string(f))}, nil
+}
+
+// fakePolicies allows every action but those in denied.
+type fakePolicies struct {
+	denied    map[string]bool
+	simulated *iam.SimulatePrincipalPolicyInput
+}
+
+func (f *fakePolicies) GetRoleWithContext(_ aws.Context, input *iam.GetRoleInput, _ ...request.Option) (*iam.GetRoleOutput, error) {
+	return &iam.GetRoleOutput{Role: &iam.Role{Arn: aws.String("arn:aws:iam::123456789012:role/service/" + *input.RoleName)}}, nil
+}
+
+func (f *fakePolicies) SimulatePrincipalPolicyPagesWithContext(_ aws.Context, input *iam.SimulatePrincipalPolicyInput, fn func(*iam.SimulatePolicyResponse, bool) bool, _ ...request.Option) error {
+	f.simulated = input
+	page := &iam.SimulatePolicyResponse{}
+	for _, action := range input.ActionNames {
+		for _, resource := range input.ResourceArns {
+			decision := iam.PolicyEvaluationDecisionTypeAllowed
+			if f.denied[*action] {
+				decision = iam.PolicyEvaluationDecisionTypeImplicitDeny
+			}
+			page.EvaluationResults = append(page.EvaluationResults, &iam.EvaluationResult{EvalActionName: action, EvalResourceName: resource, EvalDecision: aws.String(decision)})
+		}
+	}
+	fn(page, true)
+	return nil
+}
+
+func TestCheckPermissions(t *testing.T) {
+	tests := []struct {
+		name          string
+		caller        string
+		denied        map[string]bool
+		wantPrincipal string
+		wantErr       string
+	}{
+		{
+			name:          "user",
+			caller:        "arn:aws:iam::123456789012:user/scanner",
+			wantPrincipal: "arn:aws:iam::123456789012:user/scanner",
+		},
+		{
+			name:          "assumed role",
+			caller:        "arn:aws:sts::123456789012:assumed-role/scanner/session",
+			wantPrincipal: "arn:aws:iam::123456789012:role/service/scanner",
+		},
+		{
+			name:    "denied",
+			caller:  "arn:aws:iam::123456789012:user/scanner",
+			denied:  map[string]bool{"s3:PutObjectTagging": true},
+			wantErr: "don't allow s3:PutObjectTagging on arn:aws:s3:::bucket/*",
+		},
+		{
+			name:   "root",
+			caller: "arn:aws:iam::123456789012:root",
+		},
+		{
+			name:    "federated",
+			caller:  "arn:aws:sts::123456789012:federated-user/someone",
+			wantErr: "can't be checked",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policies := &fakePolicies{denied: tt.denied}
+			err := checkPermissions(context.Background(), fakeIdentity(tt.caller), policies, []string{"bucket"}, remediationActions(RemediateTag))
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("checkPermissions() error = %v, want %q", err, tt.wantErr)
+			}
+			if tt.wantPrincipal != "" && aws.StringValue(policies.simulated.PolicySourceArn) != tt.wantPrincipal {
+				t.Errorf("simulated %s, want %s", aws.StringValue(policies.simulated.PolicySourceArn), tt.wantPrincipal)
+			}
+		})
+	}
+}
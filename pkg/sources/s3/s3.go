@@ -1,6 +1,7 @@
 package s3
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
@@ -14,28 +15,29 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/go-errors/errors"
-	log "github.com/sirupsen/logrus"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/ambient"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
-	"golang.org/x/sync/semaphore"
-	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 )
 
 type Source struct {
-	name        string
-	sourceId    int64
-	jobId       int64
-	verify      bool
-	concurrency int
-	aCtx        context.Context
-	log         *log.Entry
-	sources.Progress
+	sources.Base
 	errorCount *sync.Map
 	conn       *sourcespb.S3
+	// bandwidth limits the download of objects when it's set.
+	bandwidth *common.BandwidthLimiter
+	// getRequests counts the GET requests made for objects, when they're
+	// limited.
+	getRequests int64
+	// retries holds the objects that failed to download with errors that
+	// may not recur, to be retried once the buckets have been scanned.
+	retries *sources.RetryQueue
 }
 
 // Ensure the Source satisfies the interface at compile time
@@ -46,41 +48,39 @@ func (s *Source) Type() sourcespb.SourceType {
 	return sourcespb.SourceType_SOURCE_TYPE_S3
 }
 
-func (s *Source) SourceID() int64 {
-	return s.sourceId
-}
-
-func (s *Source) JobID() int64 {
-	return s.jobId
-}
-
 // Init returns an initialized AWS source
 func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
-	s.log = log.WithField("source", s.Type()).WithField("name", name)
-
-	s.aCtx = aCtx
-	s.name = name
-	s.sourceId = sourceId
-	s.jobId = jobId
-	s.verify = verify
-	s.concurrency = concurrency
+	s.InitBase(aCtx, s.Type(), name, jobId, sourceId, verify, concurrency)
 	s.errorCount = &sync.Map{}
 
 	var conn sourcespb.S3
-	err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{})
-	if err != nil {
-		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	if err := sources.UnmarshalConnection(connection, &conn); err != nil {
+		return err
+	}
+	if err := validateConnection(&conn); err != nil {
+		return err
 	}
 	s.conn = &conn
+	s.bandwidth = common.NewBandwidthLimiter(conn.MaxBytesPerSecond)
 
 	return nil
 }
 
 func (s *Source) newClient(region string) (*s3.S3, error) {
+	sess, err := s.newSession(region)
+	if err != nil {
+		return nil, err
+	}
+	return s3.New(sess), nil
+}
+
+// newSession returns an AWS session in region with the source's credentials.
+func (s *Source) newSession(region string) (*session.Session, error) {
 	cfg := aws.NewConfig()
 	cfg.CredentialsChainVerboseErrors = aws.Bool(true)
 	cfg.Region = aws.String(region)
 
+	var ambientCred *credentialspb.Ambient
 	switch cred := s.conn.GetCredential().(type) {
 	case *sourcespb.S3_AccessKey:
 		cfg.Credentials = credentials.NewStaticCredentials(cred.AccessKey.Key, cred.AccessKey.Secret, "")
@@ -88,19 +88,33 @@ func (s *Source) newClient(region string) (*s3.S3, error) {
 		cfg.Credentials = credentials.AnonymousCredentials
 	case *sourcespb.S3_CloudEnvironment:
 		// Nothing needs to be done!
+	case *sourcespb.S3_Ambient:
+		ambientCred = cred.Ambient
 	default:
-		return nil, errors.Errorf("invalid configuration given for %s source", s.name)
+		return nil, errors.Errorf("invalid configuration given for %s source", s.Name())
 	}
 
-	sess, err := session.NewSessionWithOptions(session.Options{
-		SharedConfigState: session.SharedConfigEnable,
-		Config:            *cfg,
-	})
-	if err != nil {
-		return nil, err
-	}
+	return ambient.AWSSession(cfg, ambientCred)
+}
 
-	return s3.New(sess), nil
+// validateConnection checks that the connection has a usable credential and
+// names only valid buckets.
+func validateConnection(conn *sourcespb.S3) error {
+	var v sources.Validator
+	v.Credential(conn.GetCredential() != nil, "access_key", "unauthenticated", "cloud_environment", "ambient")
+	if cred := conn.GetAccessKey(); cred != nil {
+		v.Require("access_key.key", cred.Key != "")
+		v.Require("access_key.secret", cred.Secret != "")
+	}
+	for i, bucket := range conn.Buckets {
+		v.BucketName(fmt.Sprintf("buckets[%d]", i), bucket)
+	}
+	for i, class := range conn.SkipStorageClasses {
+		if !isStorageClass(class) {
+			v.Problemf("skip_storage_classes[%d] %q is not an S3 storage class", i, class)
+		}
+	}
+	return v.Err()
 }
 
 // Chunks emits chunks of bytes over a channel.
@@ -109,15 +123,17 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) err
 	if err != nil {
 		return errors.WrapPrefix(err, "could not create s3 client", 0)
 	}
+	s.retries = sources.NewRetryQueue(&s.Progress)
+	defer s.retries.Run(ctx)
 
 	bucketsToScan := []string{}
 
 	switch s.conn.GetCredential().(type) {
-	case *sourcespb.S3_AccessKey, *sourcespb.S3_CloudEnvironment:
+	case *sourcespb.S3_AccessKey, *sourcespb.S3_CloudEnvironment, *sourcespb.S3_Ambient:
 		if len(s.conn.Buckets) == 0 {
 			res, err := client.ListBuckets(&s3.ListBucketsInput{})
 			if err != nil {
-				s.log.Errorf("could not list s3 buckets: %s", err)
+				s.Log().Error(err, "could not list s3 buckets")
 				return errors.WrapPrefix(err, "could not list s3 buckets", 0)
 			}
 			buckets := res.Buckets
@@ -130,27 +146,32 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) err
 	case *sourcespb.S3_Unauthenticated:
 		bucketsToScan = s.conn.Buckets
 	default:
-		return errors.Errorf("invalid configuration given for %s source", s.name)
+		return errors.Errorf("invalid configuration given for %s source", s.Name())
 	}
 
 	for i, bucket := range bucketsToScan {
 		if common.IsDone(ctx) {
 			return nil
 		}
+		if s.getBudgetSpent() {
+			s.Log().Info("GET request budget spent, skipping the remaining buckets", "max_get_requests", s.conn.MaxGetRequests, "buckets", len(bucketsToScan)-i)
+			return nil
+		}
 
 		s.SetProgressComplete(i, len(bucketsToScan), fmt.Sprintf("Bucket: %s", bucket), "")
 
-		s.log.Debugf("Scanning bucket: %s", bucket)
+		s.Log().V(1).Info("scanning bucket", "bucket", bucket)
 		region, err := s3manager.GetBucketRegionWithClient(context.Background(), client, bucket)
 		if err != nil {
-			s.log.WithError(err).Errorf("could not get s3 region for bucket: %s", bucket)
+			s.Log().Error(err, "could not get s3 region for bucket", "bucket", bucket)
+			s.AddItemError("s3://"+bucket, err)
 			continue
 		}
 		var regionalClient *s3.S3
 		if region != "us-east-1" {
 			regionalClient, err = s.newClient(region)
 			if err != nil {
-				s.log.WithError(err).Error("could not make regional s3 client")
+				s.Log().Error(err, "could not make regional s3 client", "region", region)
 			}
 		} else {
 			regionalClient = client
@@ -159,15 +180,21 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) err
 		//pf := "public"
 		errorCount := sync.Map{}
 
-		err = regionalClient.ListObjectsV2PagesWithContext(
-			ctx, &s3.ListObjectsV2Input{Bucket: &bucket},
-			func(page *s3.ListObjectsV2Output, last bool) bool {
-				s.pageChunker(ctx, regionalClient, chunksChan, bucket, page, &errorCount)
-				return true
+		// Objects are downloaded by the pool while the bucket is still
+		// listed, until the pool is full.
+		pool := sources.NewPool(s.Concurrency())
+		if s.conn.NewestFirst {
+			err = s.scanNewestFirst(ctx, regionalClient, chunksChan, bucket, &errorCount, pool)
+		} else {
+			err = s.listObjects(ctx, regionalClient, bucket, func(page *s3.ListObjectsV2Output) bool {
+				s.pageChunker(ctx, regionalClient, chunksChan, bucket, page, &errorCount, pool)
+				return !s.getBudgetSpent()
 			})
+		}
+		_ = pool.Wait()
 
 		if err != nil {
-			s.log.WithError(err).Errorf("could not list objects in s3 bucket: %s", bucket)
+			s.Log().Error(err, "could not list objects in s3 bucket", "bucket", bucket)
 			return errors.WrapPrefix(err, fmt.Sprintf("could not list objects in s3 bucket: %s", bucket), 0)
 		}
 
@@ -176,31 +203,24 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) err
 	return nil
 }
 
-// pageChunker emits chunks onto the given channel from a page
-func (s *Source) pageChunker(ctx context.Context, client *s3.S3, chunksChan chan *sources.Chunk, bucket string, page *s3.ListObjectsV2Output, errorCount *sync.Map) {
-	sem := semaphore.NewWeighted(int64(s.concurrency))
-	var wg sync.WaitGroup
+// pageChunker emits chunks onto the given channel from a page, downloading
+// its objects in pool without waiting for them
+func (s *Source) pageChunker(ctx context.Context, client *s3.S3, chunksChan chan *sources.Chunk, bucket string, page *s3.ListObjectsV2Output, errorCount *sync.Map, pool *sources.Pool) {
 	for _, obj := range page.Contents {
 		if common.IsDone(ctx) {
-			return
+			break
 		}
 
-		err := sem.Acquire(ctx, 1)
-		if err != nil {
-			log.WithError(err).Error("could not acquire semaphore")
-			continue
-		}
-		wg.Add(1)
-		go func(ctx context.Context, wg *sync.WaitGroup, sem *semaphore.Weighted, obj *s3.Object) {
-			defer sem.Release(1)
-			defer wg.Done()
+		obj := obj
+		err := pool.Go(ctx, func() error {
 			//defer log.Debugf("DONE - %s", *obj.Key)
 
 			if (*obj.Key)[len(*obj.Key)-1:] == "/" {
-				return
+				return nil
 			}
 			//log.Debugf("Object: %s", *obj.Key)
 
+			objPath := fmt.Sprintf("s3://%s/%s", bucket, *obj.Key)
 			path := strings.Split(*obj.Key, "/")
 			prefix := strings.Join(path[:len(path)-1], "/")
 
@@ -209,110 +229,147 @@ func (s *Source) pageChunker(ctx context.Context, client *s3.S3, chunksChan chan
 				nErr = 0
 			}
 			if nErr.(int) > 3 {
-				log.Debugf("Skipped: %s", *obj.Key)
-				return
+				s.Log().V(1).Info("skipped object", "key", *obj.Key)
+				s.AddSkipped(skippedPrefixErrors)
+				return nil
 			}
 
 			// ignore large files
 			if *obj.Size > int64(10*common.MB) {
-				return
+				s.AddSkipped(skippedTooLarge)
+				return nil
 			}
 
 			//file is 0 bytes - likely no permissions - skipping
 			if *obj.Size == 0 {
-				return
+				s.AddSkipped(skippedEmpty)
+				return nil
 			}
 
-			//files break with spaces, must replace with +
-			//objKey := strings.ReplaceAll(*obj.Key, " ", "+")
-			ctx, cancel := context.WithTimeout(ctx, time.Second*5)
-			defer cancel()
-			res, err := client.GetObjectWithContext(ctx, &s3.GetObjectInput{
-				Bucket: &bucket,
-				Key:    obj.Key,
-			})
-			if err != nil {
-				if !strings.Contains(err.Error(), "AccessDenied") {
-					s.log.WithError(err).Errorf("could not get S3 object: %s", *obj.Key)
-				}
-
-				nErr, ok := errorCount.Load(prefix)
-				if !ok {
-					nErr = 0
-				}
-				if nErr.(int) > 3 {
-					log.Debugf("Skipped: %s", *obj.Key)
-					return
-				}
-				nErr = nErr.(int) + 1
-				errorCount.Store(prefix, nErr)
-				//too many consective errors on this page
-				if nErr.(int) > 3 {
-					s.log.Warnf("Too many consecutive errors. Blacklisting %s", prefix)
-				}
-				log.Debugf("Error Counts: %s:%s", prefix, nErr)
-				return
+			if s.skipStorageClass(obj) {
+				s.AddSkipped(skippedStorageClass)
+				return nil
 			}
-			body, err := ioutil.ReadAll(res.Body)
-			if err != nil {
-				s.log.WithError(err).Error("could not read S3 object body")
-				nErr, ok := errorCount.Load(prefix)
+			if !s.takeGetRequest() {
+				s.AddSkipped(skippedGetBudget)
+				return nil
+			}
+
+			err := s.scanObject(ctx, client, chunksChan, bucket, obj)
+			if err == nil {
+				nErr, ok = errorCount.Load(prefix)
 				if !ok {
 					nErr = 0
 				}
-				//too many consective errors on this page
-				if nErr.(int) > 3 {
-					log.Debugf("Skipped: %s", *obj.Key)
-					return
+				if nErr.(int) > 0 {
+					errorCount.Store(prefix, 0)
 				}
-				nErr = nErr.(int) + 1
-				errorCount.Store(prefix, nErr)
-
-				if nErr.(int) > 3 {
-					s.log.Warnf("Too many consecutive errors. Blacklisting %s", prefix)
-				}
-				return
+				return nil
 			}
-
-			// ignore files that don't have secrets
-			if common.SkipFile(*obj.Key, body) {
-				return
+			if !strings.Contains(err.Error(), "AccessDenied") {
+				s.Log().Error(err, "could not get S3 object", "bucket", bucket, "key", *obj.Key)
 			}
+			s.retries.Fail(objPath, err, func(ctx context.Context) error {
+				if !s.takeGetRequest() {
+					return errors.New("GET request budget spent")
+				}
+				return s.scanObject(ctx, client, chunksChan, bucket, obj)
+			})
 
-			email := "Unknown"
-			if obj.Owner != nil {
-				email = *obj.Owner.DisplayName
-			}
-			modified := obj.LastModified.String()
-			chunk := sources.Chunk{
-				SourceType: s.Type(),
-				SourceName: s.name,
-				SourceID:   s.SourceID(),
-				Data:       body,
-				SourceMetadata: &source_metadatapb.MetaData{
-					Data: &source_metadatapb.MetaData_S3{
-						S3: &source_metadatapb.S3{
-							Bucket:    bucket,
-							File:      sanitizer.UTF8(*obj.Key),
-							Link:      sanitizer.UTF8(makeS3Link(bucket, *client.Config.Region, *obj.Key)),
-							Email:     sanitizer.UTF8(email),
-							Timestamp: sanitizer.UTF8(modified),
-						},
-					},
-				},
-				Verify: s.verify,
-			}
 			nErr, ok = errorCount.Load(prefix)
 			if !ok {
 				nErr = 0
 			}
-			if nErr.(int) > 0 {
-				errorCount.Store(prefix, 0)
+			if nErr.(int) > 3 {
+				s.Log().V(1).Info("skipped object", "key", *obj.Key)
+				return nil
 			}
-			chunksChan <- &chunk
-		}(ctx, &wg, sem, obj)
+			nErr = nErr.(int) + 1
+			errorCount.Store(prefix, nErr)
+			//too many consective errors on this page
+			if nErr.(int) > 3 {
+				s.Log().Info("too many consecutive errors, skipping prefix", "bucket", bucket, "prefix", prefix)
+			}
+			s.Log().V(1).Info("error counts", "prefix", prefix, "count", nErr)
+			return nil
+		})
+		if err != nil {
+			s.Log().Error(err, "could not acquire semaphore")
+		}
 	}
-	wg.Wait()
+}
+
+// scanObject downloads an object and emits its chunks. Errors getting or
+// reading it are returned, while errors handling its contents, which won't
+// go away when it's downloaded again, are recorded as item errors.
+func (s *Source) scanObject(ctx context.Context, client *s3.S3, chunksChan chan *sources.Chunk, bucket string, obj *s3.Object) error {
+	//files break with spaces, must replace with +
+	//objKey := strings.ReplaceAll(*obj.Key, " ", "+")
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stalled := time.AfterFunc(objectTimeout, cancel)
+	defer stalled.Stop()
+	res, err := client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    obj.Key,
+	})
+	if err == nil {
+		download := common.LimitReader(ctx, &stallReader{r: res.Body, timer: stalled}, s.bandwidth, common.BandwidthLimiterFromContext(ctx))
+		var body []byte
+		body, err = ioutil.ReadAll(download)
+		res.Body.Close()
+		s.AddBytesDownloaded(int64(len(body)))
+		if err == nil {
+			return s.chunkObject(ctx, client, chunksChan, bucket, obj, body)
+		}
+		err = errors.WrapPrefix(err, "could not read S3 object body", 0)
+	}
+	if ctx.Err() != nil && parent.Err() == nil {
+		// The download stalled, rather than the scan being cancelled.
+		return errors.WrapPrefix(context.DeadlineExceeded, "S3 object download stalled", 0)
+	}
+	return err
+}
+
+// chunkObject emits the chunks of an object's body.
+func (s *Source) chunkObject(ctx context.Context, client *s3.S3, chunksChan chan *sources.Chunk, bucket string, obj *s3.Object, body []byte) error {
+	// ignore files that don't have secrets
+	if common.SkipFile(*obj.Key, body) {
+		s.AddSkipped(skippedIgnoredType)
+		return nil
+	}
+
+	objPath := fmt.Sprintf("s3://%s/%s", bucket, *obj.Key)
+	chunkSkel := s.ChunkSkel()
+	handled, err := handlers.HandleFile(ctx, objPath, bytes.NewReader(body), chunkSkel, chunksChan)
+	if err != nil {
+		s.Log().Error(err, "could not handle S3 object", "bucket", bucket, "key", *obj.Key)
+		s.AddItemError(objPath, err)
+		return nil
+	}
+	if handled {
+		return nil
+	}
+
+	email := "Unknown"
+	if obj.Owner != nil {
+		email = *obj.Owner.DisplayName
+	}
+	modified := obj.LastModified.String()
+	chunk := s.NewChunk(body, &source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_S3{
+			S3: &source_metadatapb.S3{
+				Bucket:    bucket,
+				File:      sanitizer.UTF8(*obj.Key),
+				Link:      sanitizer.UTF8(makeS3Link(bucket, *client.Config.Region, *obj.Key)),
+				Email:     sanitizer.UTF8(email),
+				Timestamp: sanitizer.UTF8(modified),
+			},
+		},
+	})
+	chunksChan <- chunk
+	return nil
 }
 
 // S3 links currently have the general format of:
@@ -326,4 +383,3 @@ func makeS3Link(bucket, region, key string) string {
 	return fmt.Sprintf("https://%s.s3%s.amazonaws.com/%s", bucket, region, key)
 }
 
-#test
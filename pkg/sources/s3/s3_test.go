@@ -5,12 +5,12 @@ import (
 	"encoding/base64"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/kylelemons/godebug/pretty"
-	log "github.com/sirupsen/logrus"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
@@ -60,13 +60,11 @@ func TestSource_Chunks(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			log.SetFormatter(&log.TextFormatter{ForceColors: true})
 			ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 			var cancelOnce sync.Once
 			defer cancelOnce.Do(cancel)
 
 			s := Source{}
-			log.SetLevel(log.DebugLevel)
 
 			conn, err := anypb.New(tt.init.connection)
 			if err != nil {
@@ -96,3 +94,68 @@ func TestSource_Chunks(t *testing.T) {
 	}
 }
 
+
+func TestValidateConnection(t *testing.T) {
+	tests := []struct {
+		name    string
+		conn    *sourcespb.S3
+		wantErr string
+	}{
+		{
+			name: "valid",
+			conn: &sourcespb.S3{
+				Credential: &sourcespb.S3_Unauthenticated{},
+				Buckets:    []string{"thog-tmp-test"},
+			},
+		},
+		{
+			name:    "no credential",
+			conn:    &sourcespb.S3{},
+			wantErr: "a credential is required",
+		},
+		{
+			name: "key without secret",
+			conn: &sourcespb.S3{
+				Credential: &sourcespb.S3_AccessKey{AccessKey: &credentialspb.KeySecret{Key: "key"}},
+			},
+			wantErr: "access_key.secret is required",
+		},
+		{
+			name: "invalid bucket",
+			conn: &sourcespb.S3{
+				Credential: &sourcespb.S3_Unauthenticated{},
+				Buckets:    []string{"thog-tmp-test", "s3://thog-tmp-test"},
+			},
+			wantErr: `buckets[1] "s3://thog-tmp-test" is not a bucket name`,
+		},
+		{
+			name: "skipped storage classes",
+			conn: &sourcespb.S3{
+				Credential:         &sourcespb.S3_Unauthenticated{},
+				SkipStorageClasses: []string{"GLACIER", "DEEP_ARCHIVE"},
+			},
+		},
+		{
+			name: "unknown storage class",
+			conn: &sourcespb.S3{
+				Credential:         &sourcespb.S3_Unauthenticated{},
+				SkipStorageClasses: []string{"GLACIER", "glacier"},
+			},
+			wantErr: `skip_storage_classes[1] "glacier" is not an S3 storage class`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateConnection(tt.conn)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateConnection() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateConnection() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,361 @@
+package share
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+)
+
+// ftpClient reads the files of an FTP server. ftps:// URLs connect with
+// implicit TLS, and ftpes:// URLs upgrade the connection with AUTH TLS before
+// logging in. ftp:// URLs aren't encrypted.
+// https://www.rfc-editor.org/rfc/rfc959
+// https://www.rfc-editor.org/rfc/rfc4217
+type ftpClient struct {
+	conn *textproto.Conn
+	// host is where data connections are made to. The address servers give
+	// for passive transfers is ignored, since servers behind NAT often give
+	// their private address, and it could be used to make the client
+	// connect elsewhere.
+	host string
+	// tls protects data connections, if the control connection has TLS.
+	tls *tls.Config
+	// mlsd is set if the server lists directories in the machine-readable
+	// format of RFC 3659.
+	mlsd bool
+}
+
+func dialFTP(u *url.URL, username, password string, insecure bool) (*ftpClient, error) {
+	scheme := strings.ToLower(u.Scheme)
+	defaultPort := "21"
+	if scheme == "ftps" {
+		defaultPort = "990"
+	}
+	conn, err := dial(hostPort(u, defaultPort))
+	if err != nil {
+		return nil, err
+	}
+
+	c := &ftpClient{host: u.Hostname()}
+	if scheme == "ftps" || scheme == "ftpes" {
+		c.tls = &tls.Config{
+			ServerName:         u.Hostname(),
+			InsecureSkipVerify: insecure,
+			// Servers often require data connections to resume the control
+			// connection's session.
+			ClientSessionCache: tls.NewLRUClientSessionCache(1),
+		}
+	}
+	if scheme == "ftps" {
+		conn = tls.Client(conn, c.tls)
+	}
+	c.conn = textproto.NewConn(conn)
+	if err := c.login(conn, scheme == "ftpes", username, password); err != nil {
+		c.conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *ftpClient) login(conn net.Conn, authTLS bool, username, password string) error {
+	if _, _, err := c.conn.ReadResponse(2); err != nil {
+		return errors.WrapPrefix(err, "server refused connection", 0)
+	}
+	if authTLS {
+		if _, _, err := c.cmd(2, "AUTH TLS"); err != nil {
+			return errors.WrapPrefix(err, "server doesn't support TLS", 0)
+		}
+		c.conn = textproto.NewConn(tls.Client(conn, c.tls))
+	}
+
+	if username == "" {
+		username, password = "anonymous", "anonymous@"
+	}
+	if strings.ContainsAny(username+password, "\r\n") {
+		return errors.New("credentials can't have line breaks")
+	}
+	code, _, err := c.cmd(0, "USER %s", username)
+	if err == nil && code == 331 {
+		code, _, err = c.cmd(0, "PASS %s", password)
+	}
+	if err != nil {
+		return err
+	}
+	if code != 230 && code != 202 {
+		return errors.Errorf("login failed with code %d", code)
+	}
+
+	if c.tls != nil {
+		if _, _, err := c.cmd(2, "PBSZ 0"); err != nil {
+			return err
+		}
+		if _, _, err := c.cmd(2, "PROT P"); err != nil {
+			return errors.WrapPrefix(err, "server doesn't protect data connections", 0)
+		}
+	}
+	if _, _, err := c.cmd(2, "TYPE I"); err != nil {
+		return err
+	}
+	if _, features, err := c.cmd(2, "FEAT"); err == nil {
+		for _, feature := range strings.Split(features, "\n") {
+			if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(feature)), "MLST") {
+				c.mlsd = true
+			}
+		}
+	}
+	return nil
+}
+
+// cmd sends a command and reads its response, which must have the expected
+// code, or start with it if it's a single digit. An expected code of 0
+// accepts any response.
+func (c *ftpClient) cmd(expect int, format string, args ...interface{}) (int, string, error) {
+	if err := c.conn.PrintfLine(format, args...); err != nil {
+		return 0, "", err
+	}
+	code, msg, err := c.conn.ReadResponse(expect)
+	if expect == 0 && err != nil {
+		// Only protocol errors are errors when any code is accepted.
+		var protoErr *textproto.Error
+		if errors.As(err, &protoErr) {
+			err = nil
+		}
+	}
+	return code, msg, err
+}
+
+// transfer sends a command whose response is sent over a data connection,
+// and returns the connection. Closing it reads the server's reply to the
+// transfer.
+func (c *ftpClient) transfer(format string, args ...interface{}) (io.ReadCloser, error) {
+	addr, err := c.passive()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := dial(addr)
+	if err != nil {
+		return nil, err
+	}
+	if c.tls != nil {
+		conn = tls.Client(conn, c.tls)
+	}
+	if _, _, err := c.cmd(1, format, args...); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &ftpData{Conn: conn, c: c}, nil
+}
+
+// passive asks the server for a port to make a data connection to.
+func (c *ftpClient) passive() (string, error) {
+	if _, msg, err := c.cmd(229, "EPSV"); err == nil {
+		// 229 Entering Extended Passive Mode (|||6446|)
+		start := strings.Index(msg, "(")
+		end := strings.LastIndex(msg, ")")
+		if start >= 0 && end > start {
+			fields := strings.Split(msg[start+1:end], string(msg[start+1]))
+			if len(fields) == 5 {
+				if _, err := strconv.ParseUint(fields[3], 10, 16); err == nil {
+					return net.JoinHostPort(c.host, fields[3]), nil
+				}
+			}
+		}
+	}
+
+	_, msg, err := c.cmd(227, "PASV")
+	if err != nil {
+		return "", err
+	}
+	// 227 Entering Passive Mode (h1,h2,h3,h4,p1,p2)
+	start := strings.IndexAny(msg, "0123456789")
+	if start < 0 {
+		return "", errors.Errorf("invalid passive mode reply %q", msg)
+	}
+	end := start
+	for end < len(msg) && strings.ContainsRune("0123456789,", rune(msg[end])) {
+		end++
+	}
+	fields := strings.Split(msg[start:end], ",")
+	if len(fields) != 6 {
+		return "", errors.Errorf("invalid passive mode reply %q", msg)
+	}
+	p1, err1 := strconv.ParseUint(fields[4], 10, 8)
+	p2, err2 := strconv.ParseUint(fields[5], 10, 8)
+	if err1 != nil || err2 != nil {
+		return "", errors.Errorf("invalid passive mode reply %q", msg)
+	}
+	return net.JoinHostPort(c.host, strconv.FormatUint(p1<<8|p2, 10)), nil
+}
+
+// ftpData is a data connection.
+type ftpData struct {
+	net.Conn
+	c *ftpClient
+}
+
+func (d *ftpData) Close() error {
+	err := d.Conn.Close()
+	// The server replies once the transfer is complete, or aborted because
+	// the connection was closed before it was.
+	if _, _, replyErr := d.c.conn.ReadResponse(2); err == nil {
+		err = replyErr
+	}
+	return err
+}
+
+func (c *ftpClient) readDir(dir string) ([]entry, error) {
+	if strings.ContainsAny(dir, "\r\n") {
+		return nil, errors.New("paths can't have line breaks")
+	}
+	command, parse := "LIST", parseListLine
+	if c.mlsd {
+		command, parse = "MLSD", parseMLSDLine
+	}
+	data, err := c.transfer("%s %s", command, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []entry
+	scanner := bufio.NewScanner(data)
+	for scanner.Scan() {
+		if e, ok := parse(scanner.Text()); ok {
+			entries = append(entries, e)
+		}
+	}
+	err = scanner.Err()
+	if closeErr := data.Close(); err == nil {
+		err = closeErr
+	}
+	return entries, err
+}
+
+func (c *ftpClient) open(name string) (io.ReadCloser, error) {
+	if strings.ContainsAny(name, "\r\n") {
+		return nil, errors.New("paths can't have line breaks")
+	}
+	return c.transfer("RETR %s", name)
+}
+
+func (c *ftpClient) close() error {
+	_ = c.conn.PrintfLine("QUIT")
+	return c.conn.Close()
+}
+
+// parseMLSDLine parses a line of an MLSD listing, such as:
+//
+//	type=file;size=1024;modify=20230102150405; secrets.env
+//
+// https://www.rfc-editor.org/rfc/rfc3659#section-7
+func parseMLSDLine(line string) (entry, bool) {
+	facts, name, ok := strings.Cut(line, " ")
+	if !ok || name == "" {
+		return entry{}, false
+	}
+	e := entry{name: name}
+	var typed bool
+	for _, fact := range strings.Split(facts, ";") {
+		key, value, _ := strings.Cut(fact, "=")
+		switch strings.ToLower(key) {
+		case "type":
+			switch strings.ToLower(value) {
+			case "dir":
+				e.dir = true
+			case "file":
+			default:
+				// The directory itself, its parent, or links.
+				return entry{}, false
+			}
+			typed = true
+		case "size":
+			e.size, _ = strconv.ParseInt(value, 10, 64)
+		case "modify":
+			if len(value) >= 14 {
+				e.modTime, _ = time.Parse("20060102150405", value[:14])
+			}
+		}
+	}
+	return e, typed
+}
+
+// parseListLine parses a line of a LIST listing, in the format of ls -l or
+// of Windows servers, such as:
+//
+//	-rw-r--r--   1 ftp  ftp   1024 Jan  2 15:04 secrets.env
+//	01-02-23  03:04PM       1024 secrets.env
+//	01-02-23  03:04PM      <DIR> backups
+func parseListLine(line string) (entry, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 {
+		return entry{}, false
+	}
+	var e entry
+	if t, err := time.Parse("01-02-06 03:04PM", fields[0]+" "+fields[1]); err == nil {
+		e.modTime = t
+		if fields[2] == "<DIR>" {
+			e.dir = true
+		} else if e.size, err = strconv.ParseInt(fields[2], 10, 64); err != nil {
+			return entry{}, false
+		}
+		e.name = fieldsAfter(line, 3)
+	} else {
+		if len(fields) < 9 {
+			return entry{}, false
+		}
+		switch fields[0][0] {
+		case 'd':
+			e.dir = true
+		case '-':
+		default:
+			return entry{}, false
+		}
+		e.size, _ = strconv.ParseInt(fields[4], 10, 64)
+		e.modTime = parseListTime(fields[5], fields[6], fields[7])
+		e.name = fieldsAfter(line, 8)
+	}
+	if e.name == "" || e.name == "." || e.name == ".." {
+		return entry{}, false
+	}
+	return e, true
+}
+
+// parseListTime parses the modification time of ls -l, which has the year
+// for files older than six months and the time of day for newer files.
+func parseListTime(month, day, yearOrTime string) time.Time {
+	if strings.Contains(yearOrTime, ":") {
+		now := time.Now().UTC()
+		t, err := time.Parse("Jan 2 15:04 2006", fmt.Sprintf("%s %s %s %d", month, day, yearOrTime, now.Year()))
+		if err != nil {
+			return time.Time{}
+		}
+		if t.After(now.AddDate(0, 0, 1)) {
+			t = t.AddDate(-1, 0, 0)
+		}
+		return t
+	}
+	t, _ := time.Parse("Jan 2 2006", fmt.Sprintf("%s %s %s", month, day, yearOrTime))
+	return t
+}
+
+// fieldsAfter returns line after its first n whitespace-separated fields,
+// keeping the spaces in names.
+func fieldsAfter(line string, n int) string {
+	rest := strings.TrimLeft(line, " \t")
+	for i := 0; i < n; i++ {
+		end := strings.IndexAny(rest, " \t")
+		if end < 0 {
+			return ""
+		}
+		rest = strings.TrimLeft(rest[end:], " \t")
+	}
+	return rest
+}
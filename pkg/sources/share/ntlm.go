@@ -0,0 +1,265 @@
+package share
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/rc4"
+	"encoding/binary"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/go-errors/errors"
+	"golang.org/x/crypto/md4"
+)
+
+// NTLM negotiate flags.
+// https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-nlmp/99d90ff4-957f-4c8a-80e4-5bfe5a9a9832
+const (
+	ntlmUnicode                 = 0x00000001
+	ntlmRequestTarget           = 0x00000004
+	ntlmSign                    = 0x00000010
+	ntlmNTLM                    = 0x00000200
+	ntlmAnonymous               = 0x00000800
+	ntlmAlwaysSign              = 0x00008000
+	ntlmExtendedSessionSecurity = 0x00080000
+	ntlmTargetInfo              = 0x00800000
+	ntlmVersion                 = 0x02000000
+	ntlm128                     = 0x20000000
+	ntlmKeyExchange             = 0x40000000
+	ntlm56                      = 0x80000000
+
+	ntlmFlags = ntlmUnicode | ntlmRequestTarget | ntlmSign | ntlmNTLM | ntlmAlwaysSign |
+		ntlmExtendedSessionSecurity | ntlmTargetInfo | ntlmVersion | ntlm128 | ntlmKeyExchange | ntlm56
+)
+
+// NTLM AV_PAIR IDs, of the target information a server sends.
+const (
+	avEOL       = 0
+	avFlags     = 6
+	avTimestamp = 7
+
+	// avFlagsMIC says the authenticate message has a MIC.
+	avFlagsMIC = 0x2
+)
+
+var ntlmSignature = []byte("NTLMSSP\x00")
+
+// ntlmVersionInfo is the version of Windows the client claims to be, which
+// is only there for debugging.
+var ntlmVersionInfo = []byte{6, 1, 0xb1, 0x1d, 0, 0, 0, 0x0f}
+
+// ntlmClient authenticates with NTLMv2. Without a username, it logs in
+// anonymously.
+// https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-nlmp/
+type ntlmClient struct {
+	username string
+	password string
+	domain   string
+
+	negotiate []byte
+	// sessionKey is set once the client is authenticated, if not anonymously.
+	sessionKey []byte
+}
+
+func newNTLMClient(username, password, domain string) *ntlmClient {
+	// Usernames may have the domain, as DOMAIN\user.
+	if d, u, ok := strings.Cut(username, `\`); ok && domain == "" {
+		domain, username = d, u
+	}
+	return &ntlmClient{username: username, password: password, domain: domain}
+}
+
+// negotiateMessage returns the first message the client sends.
+func (c *ntlmClient) negotiateMessage() []byte {
+	msg := make([]byte, 40)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 1)
+	binary.LittleEndian.PutUint32(msg[12:], ntlmFlags)
+	// The domain and workstation are left empty.
+	copy(msg[32:], ntlmVersionInfo)
+	c.negotiate = msg
+	return msg
+}
+
+// authenticateMessage returns the message answering the server's challenge.
+func (c *ntlmClient) authenticateMessage(challenge []byte) ([]byte, error) {
+	if len(challenge) < 48 || !bytes.Equal(challenge[:8], ntlmSignature) || binary.LittleEndian.Uint32(challenge[8:]) != 2 {
+		return nil, errors.New("invalid NTLM challenge")
+	}
+	flags := binary.LittleEndian.Uint32(challenge[20:]) & ntlmFlags
+	var serverChallenge [8]byte
+	copy(serverChallenge[:], challenge[24:32])
+	targetInfo, ok := ntlmField(challenge, 40)
+	if !ok {
+		return nil, errors.New("invalid NTLM challenge")
+	}
+
+	var lmResponse, ntResponse, encryptedKey []byte
+	var domain, username []byte
+	if c.username == "" {
+		flags |= ntlmAnonymous
+		lmResponse = []byte{0}
+	} else {
+		var clientChallenge [8]byte
+		if _, err := rand.Read(clientChallenge[:]); err != nil {
+			return nil, err
+		}
+		timestamp, hasTimestamp := avPair(targetInfo, avTimestamp)
+		if !hasTimestamp {
+			timestamp = make([]byte, 8)
+			binary.LittleEndian.PutUint64(timestamp, fileTime(time.Now()))
+		}
+		if hasTimestamp {
+			// The server wants a MIC, which is added to the authenticate
+			// message below.
+			targetInfo = withAVFlags(targetInfo, avFlagsMIC)
+		}
+
+		responseKey := ntowfv2(c.username, c.password, c.domain)
+		var proof, sessionBaseKey []byte
+		ntResponse, proof = ntlmv2Response(responseKey, serverChallenge[:], clientChallenge[:], timestamp, targetInfo)
+		sessionBaseKey = hmacMD5(responseKey, proof)
+		if hasTimestamp {
+			lmResponse = make([]byte, 24)
+		} else {
+			lmResponse = append(hmacMD5(responseKey, serverChallenge[:], clientChallenge[:]), clientChallenge[:]...)
+		}
+
+		c.sessionKey = sessionBaseKey
+		if flags&ntlmKeyExchange != 0 {
+			c.sessionKey = make([]byte, 16)
+			if _, err := rand.Read(c.sessionKey); err != nil {
+				return nil, err
+			}
+			cipher, err := rc4.NewCipher(sessionBaseKey)
+			if err != nil {
+				return nil, err
+			}
+			encryptedKey = make([]byte, 16)
+			cipher.XORKeyStream(encryptedKey, c.sessionKey)
+		}
+		domain, username = utf16le(c.domain), utf16le(c.username)
+	}
+
+	// The header, version and MIC are followed by the fields' contents.
+	const headerSize = 88
+	msg := make([]byte, headerSize)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 3)
+	for i, field := range [][]byte{lmResponse, ntResponse, domain, username, nil, encryptedKey} {
+		putNTLMField(msg, 12+8*i, len(msg), len(field))
+		msg = append(msg, field...)
+	}
+	binary.LittleEndian.PutUint32(msg[60:], flags)
+	copy(msg[64:], ntlmVersionInfo)
+
+	if c.sessionKey != nil {
+		if _, ok := avPair(targetInfo, avTimestamp); ok {
+			copy(msg[72:], hmacMD5(c.sessionKey, c.negotiate, challenge, msg))
+		}
+	}
+	return msg, nil
+}
+
+// ntowfv2 returns the key that NTLMv2 responses are made with.
+func ntowfv2(username, password, domain string) []byte {
+	hash := md4.New()
+	hash.Write(utf16le(password))
+	return hmacMD5(hash.Sum(nil), utf16le(strings.ToUpper(username)+domain))
+}
+
+// ntlmv2Response returns the NTLMv2 response to the server's challenge, and
+// the proof at its start, that the session key is made from.
+func ntlmv2Response(responseKey, serverChallenge, clientChallenge, timestamp, targetInfo []byte) ([]byte, []byte) {
+	blob := []byte{1, 1, 0, 0, 0, 0, 0, 0}
+	blob = append(blob, timestamp...)
+	blob = append(blob, clientChallenge...)
+	blob = append(blob, 0, 0, 0, 0)
+	blob = append(blob, targetInfo...)
+	blob = append(blob, 0, 0, 0, 0)
+	proof := hmacMD5(responseKey, serverChallenge, blob)
+	return append(proof, blob...), proof
+}
+
+// avPair returns the value of the AV pair id in targetInfo.
+func avPair(targetInfo []byte, id uint16) ([]byte, bool) {
+	for len(targetInfo) >= 4 {
+		pairID := binary.LittleEndian.Uint16(targetInfo)
+		length := int(binary.LittleEndian.Uint16(targetInfo[2:]))
+		if pairID == avEOL || len(targetInfo) < 4+length {
+			break
+		}
+		if pairID == id {
+			return targetInfo[4 : 4+length], true
+		}
+		targetInfo = targetInfo[4+length:]
+	}
+	return nil, false
+}
+
+// withAVFlags returns targetInfo with its flags AV pair set to flags.
+func withAVFlags(targetInfo []byte, flags uint32) []byte {
+	var out []byte
+	for len(targetInfo) >= 4 {
+		pairID := binary.LittleEndian.Uint16(targetInfo)
+		length := int(binary.LittleEndian.Uint16(targetInfo[2:]))
+		if pairID == avEOL || len(targetInfo) < 4+length {
+			break
+		}
+		pair := targetInfo[:4+length]
+		targetInfo = targetInfo[4+length:]
+		if pairID == avFlags && length == 4 {
+			flags |= binary.LittleEndian.Uint32(pair[4:])
+			continue
+		}
+		out = append(out, pair...)
+	}
+	pair := make([]byte, 8)
+	binary.LittleEndian.PutUint16(pair, avFlags)
+	binary.LittleEndian.PutUint16(pair[2:], 4)
+	binary.LittleEndian.PutUint32(pair[4:], flags)
+	out = append(out, pair...)
+	return append(out, 0, 0, 0, 0)
+}
+
+// ntlmField returns the contents of the field described at offset in msg.
+func ntlmField(msg []byte, offset int) ([]byte, bool) {
+	length := int(binary.LittleEndian.Uint16(msg[offset:]))
+	start := int(binary.LittleEndian.Uint32(msg[offset+4:]))
+	if start+length > len(msg) || start < 0 {
+		return nil, false
+	}
+	return msg[start : start+length], true
+}
+
+func putNTLMField(msg []byte, offset, start, length int) {
+	binary.LittleEndian.PutUint16(msg[offset:], uint16(length))
+	binary.LittleEndian.PutUint16(msg[offset+2:], uint16(length))
+	binary.LittleEndian.PutUint32(msg[offset+4:], uint32(start))
+}
+
+func hmacMD5(key []byte, data ...[]byte) []byte {
+	mac := hmac.New(md5.New, key)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+func utf16le(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	b := make([]byte, 2*len(units))
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(b[2*i:], u)
+	}
+	return b
+}
+
+// fileTime returns t in 100ns intervals since 1601, as Windows keeps time.
+func fileTime(t time.Time) uint64 {
+	const epochDelta = 116444736000000000
+	return uint64(t.UnixNano()/100) + epochDelta
+}
@@ -0,0 +1,418 @@
+package share
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/go-errors/errors"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sshclient"
+)
+
+// SFTP packet types and status codes, of version 3 of the protocol, which is
+// what OpenSSH speaks.
+// https://datatracker.ietf.org/doc/html/draft-ietf-secsh-filexfer-02
+const (
+	sftpInit     = 1
+	sftpVersion  = 2
+	sftpOpen     = 3
+	sftpClose    = 4
+	sftpRead     = 5
+	sftpOpenDir  = 11
+	sftpReadDir  = 12
+	sftpRealPath = 16
+	sftpStatus   = 101
+	sftpHandle   = 102
+	sftpData     = 103
+	sftpName     = 104
+
+	sftpStatusOK  = 0
+	sftpStatusEOF = 1
+
+	sftpAttrSize        = 0x1
+	sftpAttrUIDGID      = 0x2
+	sftpAttrPermissions = 0x4
+	sftpAttrTime        = 0x8
+	sftpAttrExtended    = 0x80000000
+
+	sftpOpenRead = 0x1
+
+	// sftpReadSize is how much of a file is asked for at once, which is the
+	// most servers must send.
+	sftpReadSize = 32 * 1024
+	// sftpMaxPacket is the size of the largest packet read from a server.
+	sftpMaxPacket = 4 * 1024 * 1024
+)
+
+// sftpClient reads the files of an SSH server, over SFTP.
+type sftpClient struct {
+	ssh *ssh.Client
+	w   io.WriteCloser
+	r   *bufio.Reader
+	id  uint32
+}
+
+// dialSFTP logs in to the SSH server at u, and returns a client along with the
+// full path of the directory in u to scan, which is the login directory if u
+// has no path.
+func dialSFTP(u *url.URL, username, password string, conn *sourcespb.FileShare) (*sftpClient, string, error) {
+	client, err := sshclient.Dial(hostPort(u, "22"), sshclient.Config{
+		Username:           username,
+		Password:           password,
+		PrivateKey:         conn.PrivateKey,
+		Passphrase:         conn.Password,
+		KnownHosts:         conn.KnownHosts,
+		InsecureSkipVerify: conn.InsecureSkipVerify,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	c := &sftpClient{ssh: client}
+	if err := c.start(); err != nil {
+		c.close()
+		return nil, "", err
+	}
+
+	root := u.Path
+	if root == "" {
+		root = "."
+	}
+	if root, err = c.realPath(root); err != nil {
+		c.close()
+		return nil, "", err
+	}
+	return c, root, nil
+}
+
+// start opens the SFTP subsystem.
+func (c *sftpClient) start() error {
+	session, err := c.ssh.NewSession()
+	if err != nil {
+		return err
+	}
+	if c.w, err = session.StdinPipe(); err != nil {
+		return err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	c.r = bufio.NewReader(stdout)
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		return errors.WrapPrefix(err, "server doesn't support SFTP", 0)
+	}
+
+	if err := c.write(appendUint32([]byte{sftpInit}, 3)); err != nil {
+		return err
+	}
+	typ, _, err := c.read()
+	if err != nil {
+		return err
+	}
+	if typ != sftpVersion {
+		return errors.Errorf("unexpected SFTP packet %d", typ)
+	}
+	return nil
+}
+
+func (c *sftpClient) write(packet []byte) error {
+	_, err := c.w.Write(append(appendUint32(nil, uint32(len(packet))), packet...))
+	return err
+}
+
+func (c *sftpClient) read() (byte, []byte, error) {
+	var length uint32
+	if err := binary.Read(c.r, binary.BigEndian, &length); err != nil {
+		return 0, nil, err
+	}
+	if length == 0 || length > sftpMaxPacket {
+		return 0, nil, errors.Errorf("invalid SFTP packet length %d", length)
+	}
+	packet := make([]byte, length)
+	if _, err := io.ReadFull(c.r, packet); err != nil {
+		return 0, nil, err
+	}
+	return packet[0], packet[1:], nil
+}
+
+// request sends a request, and returns the type of the response and what
+// follows its ID. Requests are sent one at a time.
+func (c *sftpClient) request(typ byte, body []byte) (byte, []byte, error) {
+	c.id++
+	packet := appendUint32([]byte{typ}, c.id)
+	if err := c.write(append(packet, body...)); err != nil {
+		return 0, nil, err
+	}
+	respType, resp, err := c.read()
+	if err != nil {
+		return 0, nil, err
+	}
+	id, resp, ok := readUint32(resp)
+	if !ok || id != c.id {
+		return 0, nil, errors.New("unexpected SFTP response")
+	}
+	if respType == sftpStatus {
+		return respType, resp, statusError(resp)
+	}
+	return respType, resp, nil
+}
+
+// statusError returns the error of a status response, which is io.EOF at the
+// end of a file or directory.
+func statusError(resp []byte) error {
+	code, resp, ok := readUint32(resp)
+	if !ok {
+		return errors.New("invalid SFTP status")
+	}
+	switch code {
+	case sftpStatusOK:
+		return nil
+	case sftpStatusEOF:
+		return io.EOF
+	}
+	msg, _, _ := readString(resp)
+	return errors.Errorf("SFTP error %d: %s", code, msg)
+}
+
+// handle sends a request that opens a file or directory, and returns its
+// handle.
+func (c *sftpClient) handle(typ byte, body []byte) (string, error) {
+	respType, resp, err := c.request(typ, body)
+	if err != nil {
+		return "", err
+	}
+	handle, _, ok := readString(resp)
+	if respType != sftpHandle || !ok {
+		return "", errors.New("unexpected SFTP response")
+	}
+	return handle, nil
+}
+
+func (c *sftpClient) closeHandle(handle string) error {
+	_, _, err := c.request(sftpClose, appendString(nil, handle))
+	return err
+}
+
+// names sends a request answered with names, and returns them with their
+// attributes.
+func (c *sftpClient) names(typ byte, body []byte) ([]string, []sftpAttrs, error) {
+	respType, resp, err := c.request(typ, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	count, resp, ok := readUint32(resp)
+	if respType != sftpName || !ok {
+		return nil, nil, errors.New("unexpected SFTP response")
+	}
+	var names []string
+	var attrs []sftpAttrs
+	for i := uint32(0); i < count; i++ {
+		var name string
+		var a sftpAttrs
+		if name, resp, ok = readString(resp); !ok {
+			return nil, nil, errors.New("invalid SFTP name")
+		}
+		// The long name is for humans.
+		if _, resp, ok = readString(resp); !ok {
+			return nil, nil, errors.New("invalid SFTP name")
+		}
+		if a, resp, ok = readAttrs(resp); !ok {
+			return nil, nil, errors.New("invalid SFTP attributes")
+		}
+		names = append(names, name)
+		attrs = append(attrs, a)
+	}
+	return names, attrs, nil
+}
+
+func (c *sftpClient) realPath(p string) (string, error) {
+	names, _, err := c.names(sftpRealPath, appendString(nil, p))
+	if err != nil {
+		return "", err
+	}
+	if len(names) != 1 {
+		return "", errors.New("unexpected SFTP response")
+	}
+	return names[0], nil
+}
+
+func (c *sftpClient) readDir(dir string) ([]entry, error) {
+	handle, err := c.handle(sftpOpenDir, appendString(nil, dir))
+	if err != nil {
+		return nil, err
+	}
+	var entries []entry
+	for {
+		names, attrs, err := c.names(sftpReadDir, appendString(nil, handle))
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			_ = c.closeHandle(handle)
+			return nil, err
+		}
+		for i, name := range names {
+			if name == "." || name == ".." {
+				continue
+			}
+			if e, ok := attrs[i].entry(name); ok {
+				entries = append(entries, e)
+			}
+		}
+	}
+	return entries, c.closeHandle(handle)
+}
+
+func (c *sftpClient) open(name string) (io.ReadCloser, error) {
+	body := appendString(nil, name)
+	body = appendUint32(body, sftpOpenRead)
+	// No attributes.
+	body = appendUint32(body, 0)
+	handle, err := c.handle(sftpOpen, body)
+	if err != nil {
+		return nil, err
+	}
+	return &sftpFile{c: c, handle: handle}, nil
+}
+
+func (c *sftpClient) close() error {
+	return c.ssh.Close()
+}
+
+// sftpFile reads a file opened on the server.
+type sftpFile struct {
+	c      *sftpClient
+	handle string
+	offset uint64
+	buf    []byte
+}
+
+func (f *sftpFile) Read(b []byte) (int, error) {
+	if len(f.buf) == 0 {
+		body := appendString(nil, f.handle)
+		body = appendUint64(body, f.offset)
+		body = appendUint32(body, sftpReadSize)
+		respType, resp, err := f.c.request(sftpRead, body)
+		if err != nil {
+			return 0, err
+		}
+		data, _, ok := readString(resp)
+		if respType != sftpData || !ok {
+			return 0, errors.New("unexpected SFTP response")
+		}
+		f.buf = []byte(data)
+		f.offset += uint64(len(data))
+	}
+	n := copy(b, f.buf)
+	f.buf = f.buf[n:]
+	return n, nil
+}
+
+func (f *sftpFile) Close() error {
+	return f.c.closeHandle(f.handle)
+}
+
+// sftpAttrs are the attributes of a file that tell what it is.
+type sftpAttrs struct {
+	flags       uint32
+	size        uint64
+	permissions uint32
+	modTime     uint32
+}
+
+func readAttrs(b []byte) (sftpAttrs, []byte, bool) {
+	var a sftpAttrs
+	var ok bool
+	if a.flags, b, ok = readUint32(b); !ok {
+		return a, nil, false
+	}
+	if a.flags&sftpAttrSize != 0 {
+		if len(b) < 8 {
+			return a, nil, false
+		}
+		a.size, b = binary.BigEndian.Uint64(b), b[8:]
+	}
+	if a.flags&sftpAttrUIDGID != 0 {
+		if len(b) < 8 {
+			return a, nil, false
+		}
+		b = b[8:]
+	}
+	if a.flags&sftpAttrPermissions != 0 {
+		if a.permissions, b, ok = readUint32(b); !ok {
+			return a, nil, false
+		}
+	}
+	if a.flags&sftpAttrTime != 0 {
+		// The access time comes first.
+		if len(b) < 8 {
+			return a, nil, false
+		}
+		a.modTime, b = binary.BigEndian.Uint32(b[4:]), b[8:]
+	}
+	if a.flags&sftpAttrExtended != 0 {
+		var count uint32
+		if count, b, ok = readUint32(b); !ok {
+			return a, nil, false
+		}
+		for i := uint32(0); i < 2*count; i++ {
+			if _, b, ok = readString(b); !ok {
+				return a, nil, false
+			}
+		}
+	}
+	return a, b, true
+}
+
+// entry returns the entry of the file name, if it's a directory or regular
+// file, which is told by the type bits of its permissions.
+func (a sftpAttrs) entry(name string) (entry, bool) {
+	const typeMask, dir, regular = 0170000, 0040000, 0100000
+	if a.flags&sftpAttrPermissions == 0 {
+		return entry{}, false
+	}
+	e := entry{name: name, size: int64(a.size)}
+	if a.flags&sftpAttrTime != 0 {
+		e.modTime = time.Unix(int64(a.modTime), 0)
+	}
+	switch a.permissions & typeMask {
+	case dir:
+		e.dir = true
+	case regular:
+	default:
+		return entry{}, false
+	}
+	return e, true
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	return appendUint32(appendUint32(b, uint32(v>>32)), uint32(v))
+}
+
+func appendString(b []byte, s string) []byte {
+	return append(appendUint32(b, uint32(len(s))), s...)
+}
+
+func readUint32(b []byte) (uint32, []byte, bool) {
+	if len(b) < 4 {
+		return 0, nil, false
+	}
+	return binary.BigEndian.Uint32(b), b[4:], true
+}
+
+func readString(b []byte) (string, []byte, bool) {
+	n, b, ok := readUint32(b)
+	if !ok || uint32(len(b)) < n {
+		return "", nil, false
+	}
+	return string(b[:n]), b[n:], true
+}
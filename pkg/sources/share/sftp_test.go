@@ -0,0 +1,285 @@
+package share
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// sftpServer is an SSH server whose SFTP subsystem serves the files in root,
+// read only.
+type sftpServer struct {
+	l       net.Listener
+	root    string
+	hostKey ssh.PublicKey
+}
+
+func newSFTPServer(t *testing.T, root string) *sftpServer {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == "scanner" && string(password) == "hunter2" {
+				return nil, nil
+			}
+			return nil, io.EOF
+		},
+	}
+	config.AddHostKey(signer)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	s := &sftpServer{l: l, root: root, hostKey: signer.PublicKey()}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn, config)
+		}
+	}()
+	return s
+}
+
+func (s *sftpServer) serve(conn net.Conn, config *ssh.ServerConfig) {
+	defer conn.Close()
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				ok := req.Type == "subsystem" && string(req.Payload[4:]) == "sftp"
+				_ = req.Reply(ok, nil)
+				if ok {
+					go s.serveSFTP(channel)
+				}
+			}
+		}()
+	}
+}
+
+func (s *sftpServer) serveSFTP(channel ssh.Channel) {
+	defer channel.Close()
+	handles := map[string]*os.File{}
+	var nextHandle int
+	send := func(typ byte, id uint32, body []byte) {
+		packet := []byte{typ}
+		if typ != sftpVersion {
+			packet = appendUint32(packet, id)
+		}
+		packet = append(packet, body...)
+		_, _ = channel.Write(append(appendUint32(nil, uint32(len(packet))), packet...))
+	}
+	status := func(id, code uint32) {
+		send(sftpStatus, id, appendString(appendString(appendUint32(nil, code), "status"), ""))
+	}
+	local := func(p string) string {
+		return filepath.Join(s.root, filepath.FromSlash(path.Clean("/"+p)))
+	}
+
+	for {
+		var length uint32
+		if err := binary.Read(channel, binary.BigEndian, &length); err != nil {
+			return
+		}
+		packet := make([]byte, length)
+		if _, err := io.ReadFull(channel, packet); err != nil {
+			return
+		}
+		typ, body := packet[0], packet[1:]
+		if typ == sftpInit {
+			send(sftpVersion, 0, appendUint32(nil, 3))
+			continue
+		}
+		id, body, _ := readUint32(body)
+		arg, body, _ := readString(body)
+		switch typ {
+		case sftpRealPath:
+			name := path.Clean("/" + arg)
+			send(sftpName, id, appendUint32(appendString(appendString(appendUint32(nil, 1), name), name), 0))
+		case sftpOpenDir, sftpOpen:
+			f, err := os.Open(local(arg))
+			if err != nil {
+				status(id, 2)
+				continue
+			}
+			nextHandle++
+			handle := string(rune('a' + nextHandle))
+			handles[handle] = f
+			send(sftpHandle, id, appendString(nil, handle))
+		case sftpReadDir:
+			f := handles[arg]
+			infos, err := f.Readdir(2)
+			if err != nil {
+				status(id, sftpStatusEOF)
+				continue
+			}
+			resp := appendUint32(nil, uint32(len(infos)))
+			for _, info := range infos {
+				mode := uint32(info.Mode().Perm())
+				switch {
+				case info.IsDir():
+					mode |= 0040000
+				case info.Mode().IsRegular():
+					mode |= 0100000
+				default:
+					mode |= 0120000
+				}
+				resp = appendString(resp, info.Name())
+				resp = appendString(resp, "-rw-r--r-- "+info.Name())
+				resp = appendUint32(resp, sftpAttrSize|sftpAttrPermissions|sftpAttrTime)
+				resp = appendUint64(resp, uint64(info.Size()))
+				resp = appendUint32(resp, mode)
+				resp = appendUint32(resp, uint32(info.ModTime().Unix()))
+				resp = appendUint32(resp, uint32(info.ModTime().Unix()))
+			}
+			send(sftpName, id, resp)
+		case sftpRead:
+			offset := binary.BigEndian.Uint64(body)
+			size := binary.BigEndian.Uint32(body[8:])
+			buf := make([]byte, size)
+			n, err := handles[arg].ReadAt(buf, int64(offset))
+			if n == 0 && err != nil {
+				status(id, sftpStatusEOF)
+				continue
+			}
+			send(sftpData, id, appendString(nil, string(buf[:n])))
+		case sftpClose:
+			if f, ok := handles[arg]; ok {
+				f.Close()
+				delete(handles, arg)
+			}
+			status(id, sftpStatusOK)
+		default:
+			status(id, 8)
+		}
+	}
+}
+
+func (s *sftpServer) knownHosts(t *testing.T, key ssh.PublicKey) string {
+	file := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(s.l.Addr().String())}, key)
+	if err := os.WriteFile(file, []byte(line+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestSource_Chunks_SFTP(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"srv/config.env":        "AWS_SECRET=abc",
+		"srv/nested/keys.txt":   strings.Repeat("TOKEN=def\n", 10000),
+		"srv/nested/empty.txt":  "",
+		"outside/not-these.txt": "NOPE",
+	}
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(root, filepath.Join(root, "srv", "loop")); err != nil {
+		t.Fatal(err)
+	}
+	server := newSFTPServer(t, root)
+
+	scan := func(t *testing.T, conn *sourcespb.FileShare) map[string]string {
+		conn.Urls = []string{"sftp://" + server.l.Addr().String() + "/srv"}
+		conn.Username = "scanner"
+		conn.Password = "hunter2"
+		anyConn, err := anypb.New(conn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := Source{}
+		if err := s.Init(context.Background(), "test share", 0, 0, false, anyConn, 1); err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		chunksCh := make(chan *sources.Chunk, 10)
+		go func() {
+			defer close(chunksCh)
+			if err := s.Chunks(ctx, chunksCh); err != nil {
+				t.Errorf("Chunks() error = %v", err)
+			}
+		}()
+		got := map[string]string{}
+		for chunk := range chunksCh {
+			got[chunk.SourceMetadata.GetFileShare().GetLink()] += string(chunk.Data)
+		}
+		return got
+	}
+
+	t.Run("known host", func(t *testing.T) {
+		got := scan(t, &sourcespb.FileShare{KnownHosts: server.knownHosts(t, server.hostKey)})
+		base := "sftp://" + server.l.Addr().String()
+		if got[base+"/srv/config.env"] != files["srv/config.env"] {
+			t.Errorf("config.env = %q", got[base+"/srv/config.env"])
+		}
+		// It takes several reads.
+		if got[base+"/srv/nested/keys.txt"] != files["srv/nested/keys.txt"] {
+			t.Errorf("keys.txt has %d bytes, want %d", len(got[base+"/srv/nested/keys.txt"]), len(files["srv/nested/keys.txt"]))
+		}
+		if len(got) != 2 {
+			t.Errorf("scanned %v, want config.env and keys.txt", got)
+		}
+	})
+
+	t.Run("unknown host", func(t *testing.T) {
+		other, _, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		otherKey, err := ssh.NewPublicKey(other)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := scan(t, &sourcespb.FileShare{KnownHosts: server.knownHosts(t, otherKey)}); len(got) != 0 {
+			t.Errorf("scanned %d files of a host with the wrong key", len(got))
+		}
+	})
+
+	t.Run("insecure", func(t *testing.T) {
+		if got := scan(t, &sourcespb.FileShare{InsecureSkipVerify: true}); len(got) != 2 {
+			t.Errorf("scanned %d files, want 2", len(got))
+		}
+	})
+}
@@ -0,0 +1,322 @@
+package share
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sshclient"
+)
+
+// DefaultMaxFileSize is the size of the largest file scanned, when the
+// connection doesn't say.
+var DefaultMaxFileSize = int64(10 * common.MB)
+
+const (
+	// dialTimeout is how long connecting to a server may take.
+	dialTimeout = 15 * time.Second
+	// ioTimeout is how long a server may take to answer a request or send
+	// more of a file.
+	ioTimeout = time.Minute
+)
+
+// schemes are the URL schemes of the shares that can be scanned.
+var schemes = []string{"sftp", "ftp", "ftps", "ftpes", "smb"}
+
+type Source struct {
+	sources.Base
+	conn *sourcespb.FileShare
+}
+
+// Ensure the Source satisfies the interface at compile time.
+var _ sources.Source = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_FILE_SHARE
+}
+
+// Init returns an initialized file share source.
+func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.InitBase(aCtx, s.Type(), name, jobId, sourceId, verify, concurrency)
+
+	var conn sourcespb.FileShare
+	if err := sources.UnmarshalConnection(connection, &conn); err != nil {
+		return err
+	}
+	if err := validateConnection(&conn); err != nil {
+		return err
+	}
+	if conn.MaxFileSize == 0 {
+		conn.MaxFileSize = DefaultMaxFileSize
+	}
+	s.conn = &conn
+
+	return nil
+}
+
+// validateConnection checks that the connection's URLs are shares that can be
+// scanned, with the credentials their protocols need.
+func validateConnection(conn *sourcespb.FileShare) error {
+	var v sources.Validator
+	v.Require("urls", len(conn.Urls) > 0)
+	for i, raw := range conn.Urls {
+		field := fmt.Sprintf("urls[%d]", i)
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			v.Problemf("%s %q is not a URL", field, raw)
+			continue
+		}
+		switch strings.ToLower(u.Scheme) {
+		case "sftp":
+			username, password := credentials(u, conn)
+			v.Require(field+" username", username != "")
+			v.Credential(password != "" || conn.PrivateKey != "", "password", "privateKey")
+		case "smb":
+			if shareName, _ := smbPath(u.Path); shareName == "" {
+				v.Problemf("%s %q has no share name, such as smb://server/share", field, raw)
+			}
+		case "ftp", "ftps", "ftpes":
+		default:
+			v.Problemf("%s %q is not a share URL, its scheme must be one of %s", field, raw, strings.Join(schemes, ", "))
+		}
+		if strings.ContainsAny(u.Path, "\r\n") {
+			v.Problemf("%s %q has a line break in its path", field, raw)
+		}
+	}
+	if conn.PrivateKey != "" {
+		if _, err := sshclient.ParsePrivateKey(conn.PrivateKey, conn.Password); err != nil {
+			v.Problemf("privateKey is not a valid private key: %v", err)
+		}
+	}
+	if conn.KnownHosts != "" {
+		if _, err := os.Stat(conn.KnownHosts); err != nil {
+			v.Problemf("knownHosts %q can't be read: %v", conn.KnownHosts, err)
+		}
+	}
+	if conn.MaxFileSize < 0 {
+		v.Problemf("maxFileSize %d must not be negative", conn.MaxFileSize)
+	}
+	return v.Err()
+}
+
+// credentials returns the username and password to log in to the share at u
+// with. Those in the URL take precedence over the connection's.
+func credentials(u *url.URL, conn *sourcespb.FileShare) (string, string) {
+	username, password := conn.Username, conn.Password
+	if u.User != nil {
+		username = u.User.Username()
+		if p, ok := u.User.Password(); ok {
+			password = p
+		}
+	}
+	return username, password
+}
+
+// entry is a directory or regular file in a share. Other kinds of files, such
+// as symbolic links, aren't scanned, which also keeps walks from looping.
+type entry struct {
+	name    string
+	dir     bool
+	size    int64
+	modTime time.Time
+}
+
+// share lists and reads the files of a share. Paths are slash separated.
+type share interface {
+	// readDir returns the directories and regular files in dir.
+	readDir(dir string) ([]entry, error)
+	// open returns the contents of the file at name.
+	open(name string) (io.ReadCloser, error)
+	close() error
+}
+
+// Chunks emits the files in each share, walking its directories from the
+// URL's path. A share that can't be reached or logged in to is skipped.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	return s.Enumerate(ctx, "Share", s.conn.Urls, func(raw string) error {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return err
+		}
+		// Credentials are left out of what's logged and reported.
+		u.User = nil
+		if err := s.scanShare(ctx, u, raw, chunksChan); err != nil && ctx.Err() == nil {
+			s.Log().Error(err, "could not scan share", "url", u.String())
+		}
+		return nil
+	})
+}
+
+func (s *Source) scanShare(ctx context.Context, u *url.URL, raw string, chunksChan chan *sources.Chunk) error {
+	withCredentials, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	username, password := credentials(withCredentials, s.conn)
+
+	var sh share
+	root := u.Path
+	switch strings.ToLower(u.Scheme) {
+	case "sftp":
+		sh, root, err = dialSFTP(u, username, password, s.conn)
+	case "smb":
+		shareName, dir := smbPath(u.Path)
+		root = dir
+		sh, err = dialSMB(u, shareName, username, password, s.conn)
+	default:
+		sh, err = dialFTP(u, username, password, s.conn.InsecureSkipVerify)
+	}
+	if err != nil {
+		return err
+	}
+	// Closing the share stops what's waiting on the server.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		_ = sh.close()
+	}()
+
+	if root == "" {
+		root = "."
+	}
+	return s.walk(ctx, sh, u, root, chunksChan)
+}
+
+// walk scans the files in dir and the directories under it.
+func (s *Source) walk(ctx context.Context, sh share, u *url.URL, dir string, chunksChan chan *sources.Chunk) error {
+	entries, err := sh.readDir(dir)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.Log().V(2).Info("unable to list directory", "url", u.String(), "path", dir, "error", err.Error())
+		return nil
+	}
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		p := path.Join(dir, e.name)
+		if e.dir {
+			if err := s.walk(ctx, sh, u, p, chunksChan); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.scanFile(ctx, sh, u, p, e, chunksChan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanFile scans the file at name, unless it's empty or too large. It only
+// returns an error if the scan was cancelled.
+func (s *Source) scanFile(ctx context.Context, sh share, u *url.URL, name string, e entry, chunksChan chan *sources.Chunk) error {
+	if e.size == 0 || e.size > s.conn.MaxFileSize {
+		s.Log().V(3).Info("skipping file", "url", u.String(), "path", name, "size", e.size)
+		return nil
+	}
+	r, err := sh.open(name)
+	if err != nil {
+		s.Log().V(2).Info("unable to open file", "url", u.String(), "path", name, "error", err.Error())
+		return nil
+	}
+	data, err := io.ReadAll(io.LimitReader(r, s.conn.MaxFileSize))
+	if closeErr := r.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		s.Log().V(2).Info("unable to read file", "url", u.String(), "path", name, "error", err.Error())
+		return nil
+	}
+	if common.SkipFile(name, data) {
+		return nil
+	}
+
+	link := *u
+	link.Path = name
+	if strings.EqualFold(u.Scheme, "smb") {
+		shareName, _ := smbPath(u.Path)
+		link.Path = path.Join("/", shareName, name)
+	}
+	handled, err := handlers.HandleFile(ctx, link.String(), bytes.NewReader(data), s.ChunkSkel(), chunksChan)
+	if err != nil {
+		s.Log().Error(err, "could not handle file", "url", link.String())
+		return nil
+	}
+	if handled {
+		return nil
+	}
+
+	var timestamp string
+	if !e.modTime.IsZero() {
+		timestamp = e.modTime.UTC().Format(time.RFC3339)
+	}
+	return s.Send(ctx, chunksChan, data, &source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_FileShare{
+			FileShare: &source_metadatapb.FileShare{
+				File:      sanitizer.UTF8(name),
+				Link:      sanitizer.UTF8(link.String()),
+				Host:      u.Hostname(),
+				Protocol:  strings.ToLower(u.Scheme),
+				Timestamp: timestamp,
+			},
+		},
+	})
+}
+
+// dial connects to addr, with a connection that times out if the server
+// stops answering.
+func dial(addr string) (net.Conn, error) {
+	conn, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return timeoutConn{conn}, nil
+}
+
+// timeoutConn fails reads and writes that take longer than ioTimeout.
+type timeoutConn struct {
+	net.Conn
+}
+
+func (c timeoutConn) Read(b []byte) (int, error) {
+	_ = c.Conn.SetReadDeadline(time.Now().Add(ioTimeout))
+	return c.Conn.Read(b)
+}
+
+func (c timeoutConn) Write(b []byte) (int, error) {
+	_ = c.Conn.SetWriteDeadline(time.Now().Add(ioTimeout))
+	return c.Conn.Write(b)
+}
+
+// hostPort returns u's host, with defaultPort if it has no port.
+func hostPort(u *url.URL, defaultPort string) string {
+	port := u.Port()
+	if port == "" {
+		port = defaultPort
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
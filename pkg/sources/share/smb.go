@@ -0,0 +1,768 @@
+package share
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+// SMB2 commands.
+// https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-smb2/
+const (
+	smbNegotiate      = 0x00
+	smbSessionSetup   = 0x01
+	smbTreeConnect    = 0x03
+	smbCreate         = 0x05
+	smbClose          = 0x06
+	smbRead           = 0x08
+	smbQueryDirectory = 0x0e
+)
+
+// NT status codes of SMB2 responses.
+const (
+	statusSuccess                 = 0x00000000
+	statusPending                 = 0x00000103
+	statusNoMoreFiles             = 0x80000006
+	statusMoreProcessingRequired  = 0xc0000016
+	statusEndOfFile               = 0xc0000011
+	statusAccessDenied            = 0xc0000022
+	statusObjectNameNotFound      = 0xc0000034
+	statusObjectPathNotFound      = 0xc000003a
+	statusLogonFailure            = 0xc000006d
+	statusBadNetworkName          = 0xc00000cc
+	statusUserSessionDeleted      = 0xc0000203
+	statusNetworkSessionExpired   = 0xc000035c
+	statusAccountRestriction      = 0xc000006e
+	statusPasswordExpired         = 0xc0000071
+	statusAccountDisabled         = 0xc0000072
+	statusAccessDeniedOnEncrypted = 0xc0000428
+)
+
+const (
+	smbHeaderSize = 64
+
+	smbFlagResponse = 0x1
+	smbFlagAsync    = 0x2
+	smbFlagSigned   = 0x8
+
+	smbSigningEnabled  = 0x1
+	smbSigningRequired = 0x2
+
+	smbSessionGuest   = 0x1
+	smbSessionNull    = 0x2
+	smbSessionEncrypt = 0x4
+
+	smbShareDisk    = 0x1
+	smbShareEncrypt = 0x8
+
+	// smbReadSize is how much of a file or directory listing is asked for
+	// at once, which every dialect allows without credits for larger reads.
+	smbReadSize = 64 * 1024
+	// smbMaxMessage is the size of the largest message read from a server.
+	smbMaxMessage = 1024 * 1024
+
+	fileAttributeDirectory    = 0x10
+	fileAttributeReparsePoint = 0x400
+)
+
+// smbDialects are the dialects the client speaks: SMB 2.0.2, 2.1, 3.0 and
+// 3.0.2. SMB 3.1.1 needs pre-authentication integrity, which servers that
+// speak it also negotiate 3.0.2 without.
+var smbDialects = []uint16{0x0202, 0x0210, 0x0300, 0x0302}
+
+// smbClient reads the files of a share on an SMB server, such as a Windows
+// file share or Samba. It doesn't encrypt messages, so shares that require
+// encryption can't be read.
+type smbClient struct {
+	conn      net.Conn
+	r         *bufio.Reader
+	dialect   uint16
+	messageID uint64
+	sessionID uint64
+	treeID    uint32
+	// signingKey signs requests, if the session has a key.
+	signingKey []byte
+	maxRead    uint32
+	// guest is set if the server logged the client in as a guest or
+	// anonymously rather than as its user.
+	guest bool
+	// signingRequired is set if the server only takes signed requests.
+	signingRequired bool
+}
+
+// smbPath splits the path of an smb:// URL into the share's name and the
+// directory in it, such as "share" and "dir/sub" for "/share/dir/sub".
+func smbPath(p string) (shareName, dir string) {
+	shareName, dir, _ = strings.Cut(strings.Trim(p, "/"), "/")
+	return shareName, dir
+}
+
+func dialSMB(u *url.URL, shareName, username, password string, conn *sourcespb.FileShare) (*smbClient, error) {
+	netConn, err := dial(hostPort(u, "445"))
+	if err != nil {
+		return nil, err
+	}
+	c := &smbClient{conn: netConn, r: bufio.NewReader(netConn)}
+	if err := c.connect(u.Hostname(), shareName, newNTLMClient(username, password, conn.Domain)); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// LoginSMB logs in to the SMB server on conn with NTLM, and reports whether
+// the server accepted the credentials as its user's, rather than rejecting
+// them or logging in as a guest, as Samba can for unknown users. It doesn't
+// connect to a share, so the SMB detector can verify credentials with it.
+func LoginSMB(conn net.Conn, username, password, domain string) (bool, error) {
+	c := &smbClient{conn: conn, r: bufio.NewReader(conn)}
+	if err := c.negotiate(); err != nil {
+		return false, err
+	}
+	err := c.sessionSetup(newNTLMClient(username, password, domain))
+	var status smbStatusError
+	if errors.As(err, &status) {
+		switch uint32(status) {
+		case statusLogonFailure, statusAccountRestriction, statusPasswordExpired, statusAccountDisabled:
+			return false, nil
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+	return !c.guest, nil
+}
+
+// connect negotiates a dialect, logs in and connects to the share.
+func (c *smbClient) connect(host, shareName string, auth *ntlmClient) error {
+	if err := c.negotiate(); err != nil {
+		return err
+	}
+	if err := c.sessionSetup(auth); err != nil {
+		return err
+	}
+	if c.signingRequired && c.signingKey == nil {
+		return errors.New("server requires signing, which anonymous and guest logins can't do")
+	}
+	return c.treeConnect(host, shareName)
+}
+
+// negotiate agrees on a dialect with the server.
+func (c *smbClient) negotiate() error {
+	req := make([]byte, 36, 36+2*len(smbDialects))
+	binary.LittleEndian.PutUint16(req, 36)
+	binary.LittleEndian.PutUint16(req[2:], uint16(len(smbDialects)))
+	binary.LittleEndian.PutUint16(req[4:], smbSigningEnabled)
+	if _, err := rand.Read(req[12:28]); err != nil {
+		return err
+	}
+	for _, dialect := range smbDialects {
+		req = append(req, byte(dialect), byte(dialect>>8))
+	}
+	_, resp, err := c.request(smbNegotiate, req)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not negotiate SMB", 0)
+	}
+	if len(resp) < 64 {
+		return errors.New("invalid SMB negotiate response")
+	}
+	c.dialect = binary.LittleEndian.Uint16(resp[4:])
+	c.maxRead = binary.LittleEndian.Uint32(resp[32:])
+	if c.maxRead > smbReadSize || c.maxRead == 0 {
+		c.maxRead = smbReadSize
+	}
+	c.signingRequired = binary.LittleEndian.Uint16(resp[2:])&smbSigningRequired != 0
+	return nil
+}
+
+// sessionSetup logs in with NTLM, wrapped in SPNEGO.
+func (c *smbClient) sessionSetup(auth *ntlmClient) error {
+	header, resp, err := c.sessionSetupRequest(spnegoInit(auth.negotiateMessage()))
+	if err != nil && !errors.Is(err, errMoreProcessing) {
+		return errors.WrapPrefix(err, "could not log in", 0)
+	}
+	c.sessionID = binary.LittleEndian.Uint64(header[40:])
+	token, err := securityBuffer(resp, 4)
+	if err != nil {
+		return err
+	}
+	challenge, err := spnegoResponseToken(token)
+	if err != nil {
+		return err
+	}
+	authenticate, err := auth.authenticateMessage(challenge)
+	if err != nil {
+		return err
+	}
+
+	_, resp, err = c.sessionSetupRequest(spnegoResponse(authenticate))
+	if err != nil {
+		return errors.WrapPrefix(err, "could not log in", 0)
+	}
+	flags := binary.LittleEndian.Uint16(resp[2:])
+	if flags&smbSessionEncrypt != 0 {
+		return errors.New("server requires encryption, which isn't supported")
+	}
+	c.guest = flags&(smbSessionGuest|smbSessionNull) != 0 || auth.sessionKey == nil
+	if !c.guest {
+		c.signingKey = auth.sessionKey
+		if c.dialect >= 0x0300 {
+			c.signingKey = smb3KDF(auth.sessionKey, []byte("SMB2AESCMAC\x00"), []byte("SmbSign\x00"))
+		}
+	}
+	return nil
+}
+
+func (c *smbClient) sessionSetupRequest(token []byte) ([]byte, []byte, error) {
+	req := make([]byte, 24, 24+len(token))
+	binary.LittleEndian.PutUint16(req, 25)
+	req[3] = smbSigningEnabled
+	binary.LittleEndian.PutUint16(req[12:], smbHeaderSize+24)
+	binary.LittleEndian.PutUint16(req[14:], uint16(len(token)))
+	req = append(req, token...)
+	header, resp, err := c.request(smbSessionSetup, req)
+	if err == nil && len(resp) < 8 {
+		err = errors.New("invalid SMB session setup response")
+	}
+	return header, resp, err
+}
+
+func (c *smbClient) treeConnect(host, shareName string) error {
+	name := utf16le(`\\` + host + `\` + shareName)
+	req := make([]byte, 8, 8+len(name))
+	binary.LittleEndian.PutUint16(req, 9)
+	binary.LittleEndian.PutUint16(req[4:], smbHeaderSize+8)
+	binary.LittleEndian.PutUint16(req[6:], uint16(len(name)))
+	req = append(req, name...)
+	header, resp, err := c.request(smbTreeConnect, req)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not connect to share "+shareName, 0)
+	}
+	if len(resp) < 16 {
+		return errors.New("invalid SMB tree connect response")
+	}
+	if resp[2] != smbShareDisk {
+		return errors.Errorf("%s is not a disk share", shareName)
+	}
+	if binary.LittleEndian.Uint32(resp[4:])&smbShareEncrypt != 0 {
+		return errors.Errorf("share %s requires encryption, which isn't supported", shareName)
+	}
+	c.treeID = binary.LittleEndian.Uint32(header[36:])
+	return nil
+}
+
+// errMoreProcessing is returned for the first session setup response, which
+// carries the NTLM challenge.
+var errMoreProcessing = errors.New("more processing required")
+
+// request sends a request, and returns the header and body of its response.
+// Requests are sent one at a time.
+func (c *smbClient) request(command uint16, body []byte) ([]byte, []byte, error) {
+	msg := make([]byte, smbHeaderSize, smbHeaderSize+len(body))
+	copy(msg, "\xfeSMB")
+	binary.LittleEndian.PutUint16(msg[4:], smbHeaderSize)
+	if c.dialect > 0x0202 {
+		// CreditCharge.
+		binary.LittleEndian.PutUint16(msg[6:], 1)
+	}
+	binary.LittleEndian.PutUint16(msg[12:], command)
+	// Ask for a credit for the next request.
+	binary.LittleEndian.PutUint16(msg[14:], 1)
+	binary.LittleEndian.PutUint64(msg[24:], c.messageID)
+	binary.LittleEndian.PutUint32(msg[36:], c.treeID)
+	binary.LittleEndian.PutUint64(msg[40:], c.sessionID)
+	msg = append(msg, body...)
+	if c.signingKey != nil && command != smbSessionSetup {
+		binary.LittleEndian.PutUint32(msg[16:], smbFlagSigned)
+		copy(msg[48:], c.sign(msg))
+	}
+	messageID := c.messageID
+	c.messageID++
+
+	frame := make([]byte, 4, 4+len(msg))
+	binary.BigEndian.PutUint32(frame, uint32(len(msg)))
+	if _, err := c.conn.Write(append(frame, msg...)); err != nil {
+		return nil, nil, err
+	}
+
+	for {
+		resp, err := c.readMessage()
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(resp) < smbHeaderSize || string(resp[:4]) != "\xfeSMB" {
+			return nil, nil, errors.New("invalid SMB response")
+		}
+		header, body := resp[:smbHeaderSize], resp[smbHeaderSize:]
+		flags := binary.LittleEndian.Uint32(header[16:])
+		if flags&smbFlagResponse == 0 || binary.LittleEndian.Uint64(header[24:]) != messageID {
+			return nil, nil, errors.New("unexpected SMB response")
+		}
+		status := binary.LittleEndian.Uint32(header[8:])
+		if status == statusPending && flags&smbFlagAsync != 0 {
+			// The server answers again once it's done.
+			continue
+		}
+		switch status {
+		case statusSuccess:
+			return header, body, nil
+		case statusMoreProcessingRequired:
+			return header, body, errMoreProcessing
+		case statusNoMoreFiles, statusEndOfFile:
+			return header, body, io.EOF
+		}
+		return header, body, smbStatusError(status)
+	}
+}
+
+func (c *smbClient) readMessage() ([]byte, error) {
+	var frame [4]byte
+	if _, err := io.ReadFull(c.r, frame[:]); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(frame[:]) & 0xffffff
+	if frame[0] != 0 || length > smbMaxMessage {
+		return nil, errors.New("invalid SMB message")
+	}
+	msg := make([]byte, length)
+	_, err := io.ReadFull(c.r, msg)
+	return msg, err
+}
+
+// sign returns the signature of msg, which is HMAC-SHA256 for SMB 2 and
+// AES-CMAC for SMB 3.
+func (c *smbClient) sign(msg []byte) []byte {
+	if c.dialect >= 0x0300 {
+		return cmac(c.signingKey, msg)
+	}
+	mac := hmac.New(sha256.New, c.signingKey)
+	mac.Write(msg)
+	return mac.Sum(nil)[:16]
+}
+
+// smbStatusError is an error status of a response.
+type smbStatusError uint32
+
+func (e smbStatusError) Error() string {
+	switch uint32(e) {
+	case statusAccessDenied:
+		return "access denied"
+	case statusObjectNameNotFound, statusObjectPathNotFound:
+		return "no such file or directory"
+	case statusLogonFailure:
+		return "logon failed, check the username, password and domain"
+	case statusAccountRestriction, statusPasswordExpired, statusAccountDisabled:
+		return "the account can't log in"
+	case statusBadNetworkName:
+		return "no such share"
+	case statusUserSessionDeleted, statusNetworkSessionExpired:
+		return "the session ended"
+	case statusAccessDeniedOnEncrypted:
+		return "access denied, the server requires encryption, which isn't supported"
+	}
+	return fmt.Sprintf("SMB error status 0x%08X", uint32(e))
+}
+
+// create opens the file or directory at name, and returns its ID.
+func (c *smbClient) create(name string, dir bool) ([]byte, error) {
+	const (
+		fileReadData       = 0x1
+		fileReadAttributes = 0x80
+		synchronize        = 0x100000
+		shareAll           = 0x7
+		fileOpen           = 0x1
+		directoryFile      = 0x1
+		nonDirectoryFile   = 0x40
+		impersonation      = 0x2
+	)
+	n := utf16le(smbName(name))
+	req := make([]byte, 56, 56+len(n)+1)
+	binary.LittleEndian.PutUint16(req, 57)
+	binary.LittleEndian.PutUint32(req[4:], impersonation)
+	binary.LittleEndian.PutUint32(req[24:], fileReadData|fileReadAttributes|synchronize)
+	binary.LittleEndian.PutUint32(req[32:], shareAll)
+	binary.LittleEndian.PutUint32(req[36:], fileOpen)
+	options := uint32(nonDirectoryFile)
+	if dir {
+		options = directoryFile
+	}
+	binary.LittleEndian.PutUint32(req[40:], options)
+	binary.LittleEndian.PutUint16(req[44:], smbHeaderSize+56)
+	binary.LittleEndian.PutUint16(req[46:], uint16(len(n)))
+	req = append(req, n...)
+	if len(n) == 0 {
+		// The buffer can't be empty.
+		req = append(req, 0)
+	}
+	_, resp, err := c.request(smbCreate, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) < 80 {
+		return nil, errors.New("invalid SMB create response")
+	}
+	return resp[64:80], nil
+}
+
+func (c *smbClient) closeFile(fileID []byte) error {
+	req := make([]byte, 24)
+	binary.LittleEndian.PutUint16(req, 24)
+	copy(req[8:], fileID)
+	_, _, err := c.request(smbClose, req)
+	return err
+}
+
+// smbName returns the path of name in the share, which has backslashes and
+// is empty for its root.
+func smbName(name string) string {
+	name = strings.Trim(name, "/")
+	if name == "." {
+		return ""
+	}
+	return strings.ReplaceAll(name, "/", `\`)
+}
+
+func (c *smbClient) readDir(dir string) ([]entry, error) {
+	fileID, err := c.create(dir, true)
+	if err != nil {
+		return nil, err
+	}
+	const fileDirectoryInformation = 0x1
+	const restartScans = 0x1
+	pattern := utf16le("*")
+
+	var entries []entry
+	for flags := byte(restartScans); ; flags = 0 {
+		req := make([]byte, 32, 32+len(pattern))
+		binary.LittleEndian.PutUint16(req, 33)
+		req[2] = fileDirectoryInformation
+		req[3] = flags
+		copy(req[8:], fileID)
+		binary.LittleEndian.PutUint16(req[24:], smbHeaderSize+32)
+		binary.LittleEndian.PutUint16(req[26:], uint16(len(pattern)))
+		binary.LittleEndian.PutUint32(req[28:], c.maxRead)
+		req = append(req, pattern...)
+		_, resp, err := c.request(smbQueryDirectory, req)
+		if err == io.EOF {
+			break
+		}
+		if err == nil {
+			var buf []byte
+			if buf, err = securityBuffer32(resp); err == nil {
+				entries = append(entries, parseDirectoryInformation(buf)...)
+				continue
+			}
+		}
+		_ = c.closeFile(fileID)
+		return nil, err
+	}
+	return entries, c.closeFile(fileID)
+}
+
+// parseDirectoryInformation parses the FILE_DIRECTORY_INFORMATION entries of
+// a directory listing.
+// https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-fscc/b38bf518-9057-4c88-9ddd-5e2d3976a64b
+func parseDirectoryInformation(buf []byte) []entry {
+	var entries []entry
+	for len(buf) >= 64 {
+		next := binary.LittleEndian.Uint32(buf)
+		attributes := binary.LittleEndian.Uint32(buf[56:])
+		nameLength := int(binary.LittleEndian.Uint32(buf[60:]))
+		if 64+nameLength > len(buf) {
+			break
+		}
+		name := decodeUTF16(buf[64 : 64+nameLength])
+		e := entry{
+			name:    name,
+			dir:     attributes&fileAttributeDirectory != 0,
+			size:    int64(binary.LittleEndian.Uint64(buf[40:])),
+			modTime: fromFileTime(binary.LittleEndian.Uint64(buf[24:])),
+		}
+		// Reparse points are links, which could loop.
+		if name != "." && name != ".." && attributes&fileAttributeReparsePoint == 0 {
+			entries = append(entries, e)
+		}
+		if next == 0 || int(next) > len(buf) {
+			break
+		}
+		buf = buf[next:]
+	}
+	return entries
+}
+
+func (c *smbClient) open(name string) (io.ReadCloser, error) {
+	fileID, err := c.create(name, false)
+	if err != nil {
+		return nil, err
+	}
+	return &smbFile{c: c, fileID: fileID}, nil
+}
+
+func (c *smbClient) close() error {
+	return c.conn.Close()
+}
+
+// smbFile reads a file opened on the server.
+type smbFile struct {
+	c      *smbClient
+	fileID []byte
+	offset uint64
+	buf    []byte
+}
+
+func (f *smbFile) Read(b []byte) (int, error) {
+	if len(f.buf) == 0 {
+		req := make([]byte, 49)
+		binary.LittleEndian.PutUint16(req, 49)
+		// Where the response's data starts.
+		req[2] = smbHeaderSize + 16
+		binary.LittleEndian.PutUint32(req[4:], f.c.maxRead)
+		binary.LittleEndian.PutUint64(req[8:], f.offset)
+		copy(req[16:], f.fileID)
+		_, resp, err := f.c.request(smbRead, req)
+		if err != nil {
+			return 0, err
+		}
+		if len(resp) < 16 {
+			return 0, errors.New("invalid SMB read response")
+		}
+		start := int(resp[2]) - smbHeaderSize
+		length := int(binary.LittleEndian.Uint32(resp[4:]))
+		if start < 0 || start+length > len(resp) {
+			return 0, errors.New("invalid SMB read response")
+		}
+		if length == 0 {
+			return 0, io.EOF
+		}
+		f.buf = resp[start : start+length]
+		f.offset += uint64(length)
+	}
+	n := copy(b, f.buf)
+	f.buf = f.buf[n:]
+	return n, nil
+}
+
+func (f *smbFile) Close() error {
+	return f.c.closeFile(f.fileID)
+}
+
+// securityBuffer returns the buffer of a response, whose offset from the
+// header and length are 16 bit values at offset.
+func securityBuffer(resp []byte, offset int) ([]byte, error) {
+	if len(resp) < offset+4 {
+		return nil, errors.New("invalid SMB response")
+	}
+	start := int(binary.LittleEndian.Uint16(resp[offset:])) - smbHeaderSize
+	length := int(binary.LittleEndian.Uint16(resp[offset+2:]))
+	if length == 0 {
+		return nil, nil
+	}
+	if start < 0 || start+length > len(resp) {
+		return nil, errors.New("invalid SMB response")
+	}
+	return resp[start : start+length], nil
+}
+
+// securityBuffer32 returns the buffer of a query directory response, whose
+// length is 32 bits.
+func securityBuffer32(resp []byte) ([]byte, error) {
+	if len(resp) < 8 {
+		return nil, errors.New("invalid SMB response")
+	}
+	start := int(binary.LittleEndian.Uint16(resp[2:])) - smbHeaderSize
+	length := int(binary.LittleEndian.Uint32(resp[4:]))
+	if length == 0 {
+		return nil, nil
+	}
+	if start < 0 || start+length > len(resp) {
+		return nil, errors.New("invalid SMB response")
+	}
+	return resp[start : start+length], nil
+}
+
+func decodeUTF16(b []byte) string {
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
+
+func fromFileTime(ft uint64) time.Time {
+	const epochDelta = 116444736000000000
+	if ft < epochDelta {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(ft-epochDelta)*100)
+}
+
+// smb3KDF derives a key from the session key, with the counter mode KDF of
+// NIST SP800-108 and HMAC-SHA256.
+func smb3KDF(key, label, context []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte{0, 0, 0, 1})
+	mac.Write(label)
+	mac.Write([]byte{0})
+	mac.Write(context)
+	mac.Write([]byte{0, 0, 0, 128})
+	return mac.Sum(nil)[:16]
+}
+
+// cmac returns the AES-CMAC of msg.
+// https://www.rfc-editor.org/rfc/rfc4493
+func cmac(key, msg []byte) []byte {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		// Keys are always 16 bytes.
+		panic(err)
+	}
+	double := func(b []byte) []byte {
+		out := make([]byte, 16)
+		carry := b[0] >> 7
+		for i := 0; i < 15; i++ {
+			out[i] = b[i]<<1 | b[i+1]>>7
+		}
+		out[15] = b[15] << 1
+		if carry == 1 {
+			out[15] ^= 0x87
+		}
+		return out
+	}
+	k1 := make([]byte, 16)
+	block.Encrypt(k1, k1)
+	k1 = double(k1)
+	k2 := double(k1)
+
+	n := (len(msg) + 15) / 16
+	var last [16]byte
+	if n > 0 && len(msg)%16 == 0 {
+		copy(last[:], msg[16*(n-1):])
+		for i := range last {
+			last[i] ^= k1[i]
+		}
+	} else {
+		if n == 0 {
+			n = 1
+		}
+		rest := msg[16*(n-1):]
+		copy(last[:], rest)
+		last[len(rest)] = 0x80
+		for i := range last {
+			last[i] ^= k2[i]
+		}
+	}
+
+	x := make([]byte, 16)
+	for i := 0; i < n-1; i++ {
+		for j := 0; j < 16; j++ {
+			x[j] ^= msg[16*i+j]
+		}
+		block.Encrypt(x, x)
+	}
+	for j := range x {
+		x[j] ^= last[j]
+	}
+	block.Encrypt(x, x)
+	return x
+}
+
+// spnegoInit wraps the first NTLM message in a SPNEGO NegTokenInit.
+// https://www.rfc-editor.org/rfc/rfc4178
+func spnegoInit(token []byte) []byte {
+	spnegoOID := []byte{0x06, 0x06, 0x2b, 0x06, 0x01, 0x05, 0x05, 0x02}
+	ntlmOID := []byte{0x06, 0x0a, 0x2b, 0x06, 0x01, 0x04, 0x01, 0x82, 0x37, 0x02, 0x02, 0x0a}
+	negTokenInit := der(0xa0, der(0x30,
+		der(0xa0, der(0x30, ntlmOID)),
+		der(0xa2, der(0x04, token)),
+	))
+	return der(0x60, spnegoOID, negTokenInit)
+}
+
+// spnegoResponse wraps an NTLM message in a SPNEGO NegTokenResp.
+func spnegoResponse(token []byte) []byte {
+	return der(0xa1, der(0x30, der(0xa2, der(0x04, token))))
+}
+
+// spnegoResponseToken returns the NTLM message in a server's NegTokenResp.
+func spnegoResponseToken(b []byte) ([]byte, error) {
+	invalid := errors.New("invalid SPNEGO response")
+	tag, content, _, ok := derNext(b)
+	if !ok || tag != 0xa1 {
+		return nil, invalid
+	}
+	if tag, content, _, ok = derNext(content); !ok || tag != 0x30 {
+		return nil, invalid
+	}
+	for len(content) > 0 {
+		var field []byte
+		if tag, field, content, ok = derNext(content); !ok {
+			return nil, invalid
+		}
+		if tag != 0xa2 {
+			continue
+		}
+		if tag, field, _, ok = derNext(field); !ok || tag != 0x04 {
+			return nil, invalid
+		}
+		return field, nil
+	}
+	return nil, invalid
+}
+
+// der encodes a DER value with tag.
+func der(tag byte, contents ...[]byte) []byte {
+	var length int
+	for _, c := range contents {
+		length += len(c)
+	}
+	out := []byte{tag}
+	switch {
+	case length < 0x80:
+		out = append(out, byte(length))
+	case length < 0x100:
+		out = append(out, 0x81, byte(length))
+	default:
+		out = append(out, 0x82, byte(length>>8), byte(length))
+	}
+	for _, c := range contents {
+		out = append(out, c...)
+	}
+	return out
+}
+
+// derNext decodes the DER value at the start of b.
+func derNext(b []byte) (tag byte, content, rest []byte, ok bool) {
+	if len(b) < 2 {
+		return 0, nil, nil, false
+	}
+	tag, length, b := b[0], int(b[1]), b[2:]
+	if length >= 0x80 {
+		n := length & 0x7f
+		if n == 0 || n > 3 || len(b) < n {
+			return 0, nil, nil, false
+		}
+		length = 0
+		for _, c := range b[:n] {
+			length = length<<8 | int(c)
+		}
+		b = b[n:]
+	}
+	if len(b) < length {
+		return 0, nil, nil, false
+	}
+	return tag, b[:length], b[length:], true
+}
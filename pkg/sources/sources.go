@@ -2,10 +2,12 @@ package sources
 
 import (
 	"context"
+	"strings"
 	"sync"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/known/anypb"
 )
 
@@ -22,8 +24,77 @@ type Chunk struct {
 
 	// Data is the data to decode and scan.
 	Data []byte
+	// ContentType is the MIME type of Data, which decides whether it's scanned
+	// as text, unpacked, has its text or strings extracted, or is skipped.
+	// The engine sniffs it from Data when the source leaves it empty, so
+	// sources that split a file into several chunks set it from the file's
+	// first bytes.
+	ContentType string
 	// Verify specifies whether any secrets in the Chunk should be verified.
 	Verify bool
+	// Provenance is the chain of containers, such as archives, that Data was
+	// unpacked from, outermost first. It's empty when Data is from the file
+	// or object SourceMetadata names.
+	Provenance []ContainerRef
+	// Tenant is the customer or organization whose assets the chunk is from,
+	// when the source was scanned for one. The engine sets it from the
+	// source's context.
+	Tenant string
+}
+
+// ContainerRef is a link in the provenance chain of a chunk: the file Data
+// was unpacked from in a container, such as a zip archive.
+type ContainerRef struct {
+	// Type is the type of the container, such as zip or tar.
+	Type string
+	// Name is the name of the file in the container, which is empty for
+	// containers of a single unnamed file, such as some gzip files.
+	Name string
+}
+
+// String returns the container's type and the name of the file in it.
+func (c ContainerRef) String() string {
+	if c.Name == "" {
+		return c.Type
+	}
+	return c.Type + ":" + c.Name
+}
+
+// ProvenancePath returns the path through a provenance chain to a secret,
+// such as "zip:app.tar > tar:etc/app.env", or an empty string if the chain
+// is empty.
+func ProvenancePath(chain []ContainerRef) string {
+	links := make([]string, len(chain))
+	for i, c := range chain {
+		links[i] = c.String()
+	}
+	return strings.Join(links, " > ")
+}
+
+// MetadataFile returns the file md says data is from, or an empty string if
+// its source doesn't have files.
+func MetadataFile(md *source_metadatapb.MetaData) string {
+	return MetadataField(md, "file")
+}
+
+// MetadataField returns the string field of md's source metadata with the
+// given name, such as "commit" or "bucket", or an empty string if its source
+// doesn't have one.
+func MetadataField(md *source_metadatapb.MetaData, name string) string {
+	if md == nil {
+		return ""
+	}
+	m := md.ProtoReflect()
+	field := m.WhichOneof(m.Descriptor().Oneofs().ByName("data"))
+	if field == nil {
+		return ""
+	}
+	data := m.Get(field).Message()
+	value := data.Descriptor().Fields().ByName(protoreflect.Name(name))
+	if value == nil || value.Kind() != protoreflect.StringKind {
+		return ""
+	}
+	return data.Get(value).String()
 }
 
 // Source defines the interface required to implement a source chunker.
@@ -42,6 +113,13 @@ type Source interface {
 	GetProgress() *Progress
 }
 
+// Listener is implemented by sources that receive data pushed to them, such as
+// syslog, rather than fetching it.
+type Listener interface {
+	// Listening reports whether the source is accepting data.
+	Listening() bool
+}
+
 // PercentComplete is used to update job completion percentages across sources
 type Progress struct {
 	mut               sync.Mutex
@@ -50,6 +128,18 @@ type Progress struct {
 	EncodedResumeInfo string
 	SectionsCompleted int32
 	SectionsRemaining int32
+	// BytesDownloaded is how much the source has downloaded of what it
+	// scans, for sources that download objects, such as S3's.
+	BytesDownloaded int64
+	// Skipped counts the objects the source didn't scan by the reason they
+	// were skipped, such as "storage_class".
+	Skipped map[string]int64
+	// ItemErrors are the items the source failed to scan without stopping,
+	// up to maxItemErrors of them.
+	ItemErrors []ItemError
+	// Recovered counts the items that failed and then were scanned when
+	// retried.
+	Recovered int64
 }
 
 // SetProgressComplete sets job progress information for a running job based on the highest level objects in the source.
@@ -74,3 +164,87 @@ func (p *Progress) GetProgress() *Progress {
 	defer p.mut.Unlock()
 	return p
 }
+
+// Status returns the job's completion percentage and progress message.
+func (p *Progress) Status() (int64, string) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	return p.PercentComplete, p.Message
+}
+
+// AddBytesDownloaded records that the source downloaded n more bytes.
+func (p *Progress) AddBytesDownloaded(n int64) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	p.BytesDownloaded += n
+}
+
+// Downloaded returns how many bytes the source has downloaded.
+func (p *Progress) Downloaded() int64 {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	return p.BytesDownloaded
+}
+
+// AddSkipped records that the source skipped an object for reason.
+func (p *Progress) AddSkipped(reason string) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	if p.Skipped == nil {
+		p.Skipped = make(map[string]int64)
+	}
+	p.Skipped[reason]++
+}
+
+// AddItemError records that the source failed to scan item with err, and
+// skipped it. item identifies it so that it can be scanned again, such as by
+// its URL.
+func (p *Progress) AddItemError(item string, err error) {
+	p.addItemError(NewItemError(item, err))
+}
+
+func (p *Progress) addItemError(itemErr ItemError) {
+	p.AddSkipped(SkippedError)
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	if len(p.ItemErrors) < maxItemErrors {
+		p.ItemErrors = append(p.ItemErrors, itemErr)
+	}
+}
+
+// addRecovered records that an item that failed was scanned when retried.
+func (p *Progress) addRecovered() {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	p.Recovered++
+}
+
+// RecoveredItems returns the number of items that failed and then were
+// scanned when retried.
+func (p *Progress) RecoveredItems() int64 {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	return p.Recovered
+}
+
+// FailedItems returns a copy of the item errors the source recorded.
+func (p *Progress) FailedItems() []ItemError {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	return append([]ItemError(nil), p.ItemErrors...)
+}
+
+// SkippedCounts returns a copy of the counts of objects the source skipped,
+// by reason.
+func (p *Progress) SkippedCounts() map[string]int64 {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	if len(p.Skipped) == 0 {
+		return nil
+	}
+	counts := make(map[string]int64, len(p.Skipped))
+	for reason, n := range p.Skipped {
+		counts[reason] = n
+	}
+	return counts
+}
@@ -0,0 +1,333 @@
+package sshsweep
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sshclient"
+)
+
+// DefaultPaths are the paths swept when the connection doesn't say: system
+// configuration, home directories and web roots.
+var DefaultPaths = []string{"/etc", "/home", "/root", "/var/www", "/srv"}
+
+// DefaultMaxFileSize is the size of the largest file scanned, when the
+// connection doesn't say.
+var DefaultMaxFileSize = int64(10 * common.MB)
+
+// maxStderr is how much of what tar writes to stderr is kept, to explain why
+// a sweep failed.
+const maxStderr = 4096
+
+// Source sweeps hosts over SSH, streaming their files with tar rather than
+// copying them to local disk.
+type Source struct {
+	sources.Base
+	conn *sourcespb.SSHSweep
+}
+
+// Ensure the Source satisfies the interface at compile time.
+var _ sources.Source = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_SSH_SWEEP
+}
+
+// Init returns an initialized SSH sweep source.
+func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.InitBase(aCtx, s.Type(), name, jobId, sourceId, verify, concurrency)
+
+	var conn sourcespb.SSHSweep
+	if err := sources.UnmarshalConnection(connection, &conn); err != nil {
+		return err
+	}
+	if err := validateConnection(&conn); err != nil {
+		return err
+	}
+	if len(conn.Paths) == 0 {
+		conn.Paths = DefaultPaths
+	}
+	if conn.MaxFileSize == 0 {
+		conn.MaxFileSize = DefaultMaxFileSize
+	}
+	s.conn = &conn
+
+	return nil
+}
+
+// validateConnection checks that each host can be logged in to, and that the
+// paths can be swept.
+func validateConnection(conn *sourcespb.SSHSweep) error {
+	var v sources.Validator
+	v.Require("hosts", len(conn.Hosts) > 0)
+	for i, host := range conn.Hosts {
+		username, addr, err := parseHost(host)
+		if err != nil {
+			v.Problemf("hosts[%d] %q is not a host: %v", i, host, err)
+			continue
+		}
+		if username == "" && conn.Username == "" {
+			v.Problemf("hosts[%d] %q has no username, set username or give it as user@host", i, addr)
+		}
+	}
+	v.Credential(conn.Password != "" || conn.PrivateKey != "", "password", "privateKey")
+	if conn.PrivateKey != "" {
+		if _, err := sshclient.ParsePrivateKey(conn.PrivateKey, conn.Password); err != nil {
+			v.Problemf("privateKey is not a valid private key: %v", err)
+		}
+	}
+	for i, p := range conn.Paths {
+		if !path.IsAbs(p) {
+			v.Problemf("paths[%d] %q must be absolute", i, p)
+		}
+	}
+	for i, exclude := range conn.Excludes {
+		if exclude == "" {
+			v.Problemf("excludes[%d] must not be empty", i)
+		}
+	}
+	if conn.MaxFileSize < 0 {
+		v.Problemf("maxFileSize %d must not be negative", conn.MaxFileSize)
+	}
+	return v.Err()
+}
+
+// parseHost parses a host given as [user@]host[:port], and returns its
+// username, if it has one, and its address.
+func parseHost(host string) (string, string, error) {
+	var username string
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		username, host = host[:i], host[i+1:]
+	}
+	if host == "" {
+		return "", "", errors.New("no hostname")
+	}
+	if h, port, err := net.SplitHostPort(host); err == nil {
+		if h == "" || port == "" {
+			return "", "", errors.New("no hostname or port")
+		}
+		return username, host, nil
+	}
+	return username, net.JoinHostPort(strings.Trim(host, "[]"), "22"), nil
+}
+
+// Chunks sweeps the hosts, up to the source's concurrency at once. A host
+// that can't be reached or logged in to is skipped.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	pool := sources.NewPool(s.Concurrency())
+	var mu sync.Mutex
+	var done int
+	for _, host := range s.conn.Hosts {
+		host := host
+		err := pool.Go(ctx, func() error {
+			if err := s.sweep(ctx, host, chunksChan); err != nil && ctx.Err() == nil {
+				s.Log().Error(err, "could not sweep host", "host", host)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			done++
+			s.SetProgressComplete(done, len(s.conn.Hosts), fmt.Sprintf("Host: %s", host), "")
+			return nil
+		})
+		if err != nil {
+			break
+		}
+	}
+	return pool.Wait()
+}
+
+// sweep streams the paths of host as a tar archive, and scans its files.
+func (s *Source) sweep(ctx context.Context, host string, chunksChan chan *sources.Chunk) error {
+	username, addr, err := parseHost(host)
+	if err != nil {
+		return err
+	}
+	if username == "" {
+		username = s.conn.Username
+	}
+	client, err := sshclient.Dial(addr, sshclient.Config{
+		Username:           username,
+		Password:           s.conn.Password,
+		PrivateKey:         s.conn.PrivateKey,
+		Passphrase:         s.conn.Password,
+		KnownHosts:         s.conn.KnownHosts,
+		InsecureSkipVerify: s.conn.InsecureSkipVerify,
+	})
+	if err != nil {
+		return err
+	}
+	// Closing the client stops the sweep.
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-finished:
+		}
+		client.Close()
+	}()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr := &tailBuffer{max: maxStderr}
+	session.Stderr = stderr
+	if err := session.Start(s.command()); err != nil {
+		return errors.WrapPrefix(err, "could not run tar", 0)
+	}
+
+	hostname, _, _ := net.SplitHostPort(addr)
+	entries, err := s.scanArchive(ctx, hostname, tar.NewReader(stdout), chunksChan)
+	if err != nil {
+		return err
+	}
+	if err := session.Wait(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		// tar fails if any path couldn't be read, such as paths that don't
+		// exist on every host, after archiving the rest.
+		if entries == 0 {
+			return errors.Errorf("tar failed: %v: %s", err, msg)
+		}
+		s.Log().V(1).Info("tar could not read some paths", "host", host, "stderr", msg)
+	}
+	return nil
+}
+
+// command returns the command that writes the paths to sweep to stdout, as a
+// tar archive.
+func (s *Source) command() string {
+	args := []string{"tar", "-cf", "-"}
+	for _, exclude := range s.conn.Excludes {
+		args = append(args, "--exclude="+exclude)
+	}
+	args = append(args, "--")
+	args = append(args, s.conn.Paths...)
+	for i, arg := range args {
+		args[i] = shellQuote(arg)
+	}
+	command := strings.Join(args, " ")
+	if s.conn.Sudo {
+		// Without a terminal sudo can't ask for a password, so it must not
+		// need one.
+		command = "sudo -n " + command
+	}
+	return command
+}
+
+// scanArchive scans the regular files in an archive, and returns how many
+// entries it had. Files that are empty or too large are skipped.
+func (s *Source) scanArchive(ctx context.Context, hostname string, tr *tar.Reader, chunksChan chan *sources.Chunk) (int, error) {
+	var entries int
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return entries, ctx.Err()
+			}
+			return entries, errors.WrapPrefix(err, "could not read tar stream", 0)
+		}
+		entries++
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		// tar strips the leading slash from absolute paths.
+		file := "/" + strings.TrimLeft(path.Clean(header.Name), "/")
+		if header.Size == 0 || header.Size > s.conn.MaxFileSize {
+			s.Log().V(3).Info("skipping file", "host", hostname, "file", file, "size", header.Size)
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return entries, errors.WrapPrefix(err, "could not read tar stream", 0)
+		}
+		if common.SkipFile(file, data) {
+			continue
+		}
+		if err := s.scanFile(ctx, hostname, file, header.ModTime, data, chunksChan); err != nil {
+			return entries, err
+		}
+	}
+}
+
+func (s *Source) scanFile(ctx context.Context, hostname, file string, modTime time.Time, data []byte, chunksChan chan *sources.Chunk) error {
+	handled, err := handlers.HandleFile(ctx, hostname+":"+file, bytes.NewReader(data), s.ChunkSkel(), chunksChan)
+	if err != nil {
+		s.Log().Error(err, "could not handle file", "host", hostname, "file", file)
+		return nil
+	}
+	if handled {
+		return nil
+	}
+
+	var timestamp string
+	if !modTime.IsZero() {
+		timestamp = modTime.UTC().Format(time.RFC3339)
+	}
+	return s.Send(ctx, chunksChan, data, &source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_SshSweep{
+			SshSweep: &source_metadatapb.SSHSweep{
+				File:      sanitizer.UTF8(file),
+				Host:      hostname,
+				Timestamp: timestamp,
+			},
+		},
+	})
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// tailBuffer keeps the last max bytes written to it.
+type tailBuffer struct {
+	mu  sync.Mutex
+	max int
+	buf []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > b.max {
+		b.buf = b.buf[len(b.buf)-b.max:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
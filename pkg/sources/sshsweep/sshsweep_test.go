@@ -0,0 +1,283 @@
+package sshsweep
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestSource_Init(t *testing.T) {
+	tests := []struct {
+		name    string
+		conn    *sourcespb.SSHSweep
+		wantErr string
+	}{
+		{
+			name: "valid",
+			conn: &sourcespb.SSHSweep{Hosts: []string{"web1", "auditor@web2:2222", "[::1]"}, Username: "scanner", Password: "hunter2"},
+		},
+		{
+			name:    "no hosts",
+			conn:    &sourcespb.SSHSweep{Password: "hunter2"},
+			wantErr: "hosts is required",
+		},
+		{
+			name:    "no username",
+			conn:    &sourcespb.SSHSweep{Hosts: []string{"web1"}, Password: "hunter2"},
+			wantErr: `hosts[0] "web1:22" has no username`,
+		},
+		{
+			name:    "no credential",
+			conn:    &sourcespb.SSHSweep{Hosts: []string{"scanner@web1"}},
+			wantErr: "a credential is required",
+		},
+		{
+			name:    "bad private key",
+			conn:    &sourcespb.SSHSweep{Hosts: []string{"scanner@web1"}, PrivateKey: "not a key"},
+			wantErr: "privateKey is not a valid private key",
+		},
+		{
+			name:    "relative path",
+			conn:    &sourcespb.SSHSweep{Hosts: []string{"scanner@web1"}, Password: "hunter2", Paths: []string{"etc"}},
+			wantErr: `paths[0] "etc" must be absolute`,
+		},
+		{
+			name:    "no host",
+			conn:    &sourcespb.SSHSweep{Hosts: []string{"scanner@"}, Password: "hunter2"},
+			wantErr: "is not a host",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := anypb.New(tt.conn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := Source{}
+			err = s.Init(context.Background(), "test sweep", 0, 0, false, conn, 1)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Init() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Init() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseHost(t *testing.T) {
+	tests := []struct {
+		host, username, addr string
+	}{
+		{host: "web1", addr: "web1:22"},
+		{host: "web1:2222", addr: "web1:2222"},
+		{host: "auditor@web1", username: "auditor", addr: "web1:22"},
+		{host: "auditor@corp.example@web1:2222", username: "auditor@corp.example", addr: "web1:2222"},
+		{host: "[2001:db8::1]", addr: "[2001:db8::1]:22"},
+		{host: "[2001:db8::1]:2222", addr: "[2001:db8::1]:2222"},
+	}
+	for _, tt := range tests {
+		username, addr, err := parseHost(tt.host)
+		if err != nil || username != tt.username || addr != tt.addr {
+			t.Errorf("parseHost(%q) = %q, %q, %v, want %q, %q", tt.host, username, addr, err, tt.username, tt.addr)
+		}
+	}
+}
+
+func TestSource_command(t *testing.T) {
+	s := Source{conn: &sourcespb.SSHSweep{
+		Paths:    []string{"/etc", "/home/o'brien"},
+		Excludes: []string{"*.log", "/etc/ssl/certs"},
+		Sudo:     true,
+	}}
+	want := `sudo -n tar -cf - '--exclude=*.log' --exclude=/etc/ssl/certs -- /etc '/home/o'\''brien'`
+	if got := s.command(); got != want {
+		t.Errorf("command() = %s, want %s", got, want)
+	}
+}
+
+// sshServer is an SSH server that runs the commands it's sent locally.
+type sshServer struct {
+	l net.Listener
+}
+
+func newSSHServer(t *testing.T) *sshServer {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == "auditor" && string(password) == "hunter2" {
+				return nil, nil
+			}
+			return nil, errors.New("wrong password")
+		},
+	}
+	config.AddHostKey(signer)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveSSH(conn, config)
+		}
+	}()
+	return &sshServer{l: l}
+}
+
+func serveSSH(conn net.Conn, config *ssh.ServerConfig) {
+	defer conn.Close()
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				if req.Type != "exec" {
+					_ = req.Reply(false, nil)
+					continue
+				}
+				_ = req.Reply(true, nil)
+				command := string(req.Payload[4:])
+				go func() {
+					defer channel.Close()
+					cmd := exec.Command("sh", "-c", command)
+					cmd.Stdout = channel
+					cmd.Stderr = channel.Stderr()
+					var status uint32
+					if err := cmd.Run(); err != nil {
+						status = 1
+						var exitErr *exec.ExitError
+						if errors.As(err, &exitErr) {
+							status = uint32(exitErr.ExitCode())
+						}
+					}
+					payload := make([]byte, 4)
+					binary.BigEndian.PutUint32(payload, status)
+					_, _ = channel.SendRequest("exit-status", false, payload)
+				}()
+			}
+		}()
+	}
+}
+
+func TestSource_Chunks(t *testing.T) {
+	if _, err := exec.LookPath("tar"); err != nil {
+		t.Skip("tar is not installed")
+	}
+	root := t.TempDir()
+	files := map[string]string{
+		"etc/app/database.env":     "DB_PASSWORD=hunter2",
+		"etc/app/debug.log":        "TOKEN=excluded",
+		"home/deploy/.aws/config":  "[default]\naws_secret_access_key=abc",
+		"home/deploy/empty":        "",
+		"home/deploy/large.bin":    strings.Repeat("x", 100),
+		"var/www/html/config.php":  "<?php $key = 'def';",
+		"srv/not-swept/secret.txt": "NOPE",
+	}
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(root, "srv"), filepath.Join(root, "home", "deploy", "link")); err != nil {
+		t.Fatal(err)
+	}
+	server := newSSHServer(t)
+
+	sweep := func(t *testing.T, conn *sourcespb.SSHSweep) map[string]string {
+		conn.Hosts = []string{"auditor@" + server.l.Addr().String()}
+		conn.InsecureSkipVerify = true
+		anyConn, err := anypb.New(conn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := Source{}
+		if err := s.Init(context.Background(), "test sweep", 0, 0, true, anyConn, 2); err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		chunksCh := make(chan *sources.Chunk, 10)
+		go func() {
+			defer close(chunksCh)
+			if err := s.Chunks(ctx, chunksCh); err != nil {
+				t.Errorf("Chunks() error = %v", err)
+			}
+		}()
+		got := map[string]string{}
+		for chunk := range chunksCh {
+			meta := chunk.SourceMetadata.GetSshSweep()
+			if meta.GetHost() != "127.0.0.1" || meta.GetTimestamp() == "" || chunk.SourceType != sourcespb.SourceType_SOURCE_TYPE_SSH_SWEEP || !chunk.Verify {
+				t.Errorf("unexpected chunk %+v", chunk)
+			}
+			got[strings.TrimPrefix(meta.GetFile(), root+"/")] = string(chunk.Data)
+		}
+		return got
+	}
+
+	t.Run("sweep", func(t *testing.T) {
+		got := sweep(t, &sourcespb.SSHSweep{
+			Password: "hunter2",
+			// Paths missing from a host don't stop the rest being swept.
+			Paths:       []string{filepath.Join(root, "etc"), filepath.Join(root, "home"), filepath.Join(root, "var", "www"), filepath.Join(root, "missing")},
+			Excludes:    []string{"*.log"},
+			MaxFileSize: 50,
+		})
+		want := []string{"etc/app/database.env", "home/deploy/.aws/config", "var/www/html/config.php"}
+		if len(got) != len(want) {
+			t.Errorf("swept %v, want %v", got, want)
+		}
+		for _, name := range want {
+			if got[name] != files[name] {
+				t.Errorf("%s = %q, want %q", name, got[name], files[name])
+			}
+		}
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		if got := sweep(t, &sourcespb.SSHSweep{Password: "wrong", Paths: []string{root}}); len(got) != 0 {
+			t.Errorf("swept %d files without logging in", len(got))
+		}
+	})
+}
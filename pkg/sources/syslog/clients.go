@@ -0,0 +1,132 @@
+package syslog
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// parseCIDR parses a CIDR, or an IP address as the network of that address
+// alone.
+func parseCIDR(s string) (*net.IPNet, error) {
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, &net.ParseError{Type: "IP address", Text: s}
+		}
+		bits := 8 * net.IPv4len
+		if ip.To4() == nil {
+			bits = 8 * net.IPv6len
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+	_, network, err := net.ParseCIDR(s)
+	return network, err
+}
+
+// clientIP returns the IP address of a client, or nil if it has none.
+func clientIP(addr net.Addr) net.IP {
+	switch addr := addr.(type) {
+	case *net.TCPAddr:
+		return addr.IP
+	case *net.UDPAddr:
+		return addr.IP
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return nil
+	}
+	return net.ParseIP(host)
+}
+
+// allowed reports whether messages are accepted from the client at addr,
+// which they are from every client if no CIDRs are allowed.
+func (s *Source) allowed(addr net.Addr) bool {
+	if len(s.allowedNetworks) == 0 {
+		return true
+	}
+	ip := clientIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range s.allowedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	// hostnameTTL is how long the hostname of a client is cached for,
+	// including that it has none.
+	hostnameTTL = 10 * time.Minute
+	// hostnameTimeout is how long looking up a client's hostname can take.
+	hostnameTimeout = 2 * time.Second
+	// maxHostnames is how many clients' hostnames are cached before the cache
+	// is emptied, so that a flood of clients can't grow it unbounded.
+	maxHostnames = 10000
+)
+
+// hostnames looks up and caches the reverse DNS names of clients.
+type hostnames struct {
+	resolver interface {
+		LookupAddr(ctx context.Context, addr string) ([]string, error)
+		LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+	}
+
+	mu    sync.Mutex
+	cache map[string]hostname
+}
+
+type hostname struct {
+	name    string
+	expires time.Time
+}
+
+func newHostnames() *hostnames {
+	return &hostnames{resolver: net.DefaultResolver, cache: make(map[string]hostname)}
+}
+
+// lookup returns the reverse DNS name of ip, if it has one that resolves back
+// to it. Names that don't are left out, since whoever controls the reverse
+// zone of an address can give it any name.
+func (h *hostnames) lookup(ctx context.Context, ip net.IP) string {
+	key := ip.String()
+	h.mu.Lock()
+	cached, ok := h.cache[key]
+	h.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.name
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, hostnameTimeout)
+	defer cancel()
+	name := ""
+	names, _ := h.resolver.LookupAddr(ctx, key)
+	for _, candidate := range names {
+		addrs, err := h.resolver.LookupIPAddr(ctx, candidate)
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if addr.IP.Equal(ip) {
+				name = strings.TrimSuffix(candidate, ".")
+				break
+			}
+		}
+		if name != "" {
+			break
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.cache) >= maxHostnames {
+		h.cache = make(map[string]hostname)
+	}
+	h.cache[key] = hostname{name: name, expires: time.Now().Add(hostnameTTL)}
+	return name
+}
@@ -0,0 +1,156 @@
+package syslog
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestSource_allowed(t *testing.T) {
+	s := Source{}
+	for _, cidr := range []string{"10.0.0.0/8", "192.0.2.1", "2001:db8::/32"} {
+		network, err := parseCIDR(cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.allowedNetworks = append(s.allowedNetworks, network)
+	}
+	for addr, want := range map[net.Addr]bool{
+		&net.UDPAddr{IP: net.ParseIP("10.1.2.3"), Port: 514}:   true,
+		&net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 514}:  true,
+		&net.TCPAddr{IP: net.ParseIP("192.0.2.2"), Port: 514}:  false,
+		&net.UDPAddr{IP: net.ParseIP("2001:db8::1"), Port: 51}: true,
+		&net.UDPAddr{IP: net.ParseIP("::ffff:10.0.0.1")}:       true,
+		&net.UDPAddr{IP: net.ParseIP("127.0.0.1")}:             false,
+	} {
+		if got := s.allowed(addr); got != want {
+			t.Errorf("allowed(%s) = %v, want %v", addr, got, want)
+		}
+	}
+	if !(&Source{}).allowed(&net.UDPAddr{IP: net.ParseIP("127.0.0.1")}) {
+		t.Error("allowed() without allowed CIDRs rejected a client")
+	}
+}
+
+// fakeResolver resolves the PTR and A records it's given.
+type fakeResolver struct {
+	ptr     map[string][]string
+	a       map[string][]net.IPAddr
+	lookups int
+}
+
+func (r *fakeResolver) LookupAddr(_ context.Context, addr string) ([]string, error) {
+	r.lookups++
+	if names, ok := r.ptr[addr]; ok {
+		return names, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: addr, IsNotFound: true}
+}
+
+func (r *fakeResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	if addrs, ok := r.a[host]; ok {
+		return addrs, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func TestHostnames_lookup(t *testing.T) {
+	resolver := &fakeResolver{
+		ptr: map[string][]string{
+			"192.0.2.1": {"spoofed.example.com.", "web1.example.com."},
+			"192.0.2.2": {"bank.example.com."},
+		},
+		a: map[string][]net.IPAddr{
+			"web1.example.com.": {{IP: net.ParseIP("192.0.2.1")}},
+			"bank.example.com.": {{IP: net.ParseIP("198.51.100.1")}},
+		},
+	}
+	h := newHostnames()
+	h.resolver = resolver
+	ctx := context.Background()
+	for ip, want := range map[string]string{
+		"192.0.2.1": "web1.example.com",
+		// Names that don't resolve back to the client aren't trusted.
+		"192.0.2.2": "",
+		"192.0.2.3": "",
+	} {
+		if got := h.lookup(ctx, net.ParseIP(ip)); got != want {
+			t.Errorf("lookup(%s) = %q, want %q", ip, got, want)
+		}
+	}
+	h.lookup(ctx, net.ParseIP("192.0.2.1"))
+	h.lookup(ctx, net.ParseIP("192.0.2.3"))
+	if resolver.lookups != 3 {
+		t.Errorf("looked up %d addresses, want 3 with the rest cached", resolver.lookups)
+	}
+}
+
+func TestSource_Chunks_clients(t *testing.T) {
+	tests := []struct {
+		name         string
+		allowed      []string
+		wantChunk    bool
+		wantHostname string
+	}{
+		{name: "allowed", allowed: []string{"127.0.0.0/8"}, wantChunk: true, wantHostname: "localhost"},
+		{name: "not allowed", allowed: []string{"10.0.0.0/8"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			addr := lis.Addr().String()
+			lis.Close()
+
+			conn, err := anypb.New(&sourcespb.Syslog{Protocol: "tcp", ListenAddress: addr, AllowedCidrs: tt.allowed, ReverseDns: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := Source{}
+			if err := s.Init(ctx, "test syslog", 0, 0, false, conn, 1); err != nil {
+				t.Fatal(err)
+			}
+			s.hostnames.resolver = &fakeResolver{
+				ptr: map[string][]string{"127.0.0.1": {"localhost."}},
+				a:   map[string][]net.IPAddr{"localhost.": {{IP: net.ParseIP("127.0.0.1")}}},
+			}
+			chunksChan := make(chan *sources.Chunk, 1)
+			go func() { _ = s.Chunks(ctx, chunksChan) }()
+
+			var client net.Conn
+			for client == nil {
+				if client, err = net.Dial("tcp", addr); err != nil && ctx.Err() != nil {
+					t.Fatal(err)
+				}
+			}
+			defer client.Close()
+			fmt.Fprint(client, "<34>Oct 11 22:14:15 mymachine su: token=abc\n")
+
+			select {
+			case chunk := <-chunksChan:
+				if !tt.wantChunk {
+					t.Fatalf("got chunk from a client that isn't allowed: %s", chunk.Data)
+				}
+				if got := chunk.SourceMetadata.GetSyslog().GetClientHostname(); got != tt.wantHostname {
+					t.Errorf("client hostname = %q, want %q", got, tt.wantHostname)
+				}
+			case <-time.After(1500 * time.Millisecond):
+				if tt.wantChunk {
+					t.Fatal("got no chunk")
+				}
+			}
+		})
+	}
+}
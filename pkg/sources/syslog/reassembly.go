@@ -0,0 +1,107 @@
+package syslog
+
+import (
+	"net"
+	"time"
+)
+
+const (
+	// maxDatagramBytes is the most bytes a UDP datagram can carry, so reading
+	// into a buffer this big never truncates one.
+	maxDatagramBytes = 65535
+	// reassemblyTimeout is how long a message is waited on for more datagrams
+	// before it's scanned.
+	reassemblyTimeout = 500 * time.Millisecond
+	// maxReassembledBytes is the most bytes a message is reassembled into,
+	// after which its next datagram starts another message.
+	maxReassembledBytes = 256 << 10
+	// maxReassembling is how many clients' messages can be reassembled at
+	// once, so that a flood of clients can't grow them unbounded. Once there
+	// are more, all of them are scanned as they are.
+	maxReassembling = 10000
+)
+
+// message is a message from a client.
+type message struct {
+	remote net.Addr
+	data   []byte
+}
+
+// reassembler joins the datagrams of messages that don't fit in one. Senders
+// split a long message into datagrams sent one after another, and only the
+// first starts with a priority, like <34>, so a datagram without one continues
+// the message the client sent before it. It isn't safe for concurrent use.
+type reassembler struct {
+	partial map[string]*partialMessage
+}
+
+type partialMessage struct {
+	message
+	updated time.Time
+}
+
+func newReassembler() *reassembler {
+	return &reassembler{partial: make(map[string]*partialMessage)}
+}
+
+// add adds a datagram received from remote at now, returning the messages it
+// completes.
+func (r *reassembler) add(remote net.Addr, datagram []byte, now time.Time) []message {
+	var complete []message
+	key := remote.String()
+	partial, ok := r.partial[key]
+	if ok && !hasPriority(datagram) && len(partial.data)+len(datagram) <= maxReassembledBytes {
+		partial.data = append(partial.data, datagram...)
+		partial.updated = now
+		return nil
+	}
+	if ok {
+		complete = append(complete, partial.message)
+		delete(r.partial, key)
+	}
+	if len(r.partial) >= maxReassembling {
+		complete = append(complete, r.flush()...)
+	}
+	r.partial[key] = &partialMessage{message: message{remote: remote, data: datagram}, updated: now}
+	return complete
+}
+
+// expire returns the messages that haven't had a datagram added since
+// reassemblyTimeout before now.
+func (r *reassembler) expire(now time.Time) []message {
+	var complete []message
+	for key, partial := range r.partial {
+		if now.Sub(partial.updated) >= reassemblyTimeout {
+			complete = append(complete, partial.message)
+			delete(r.partial, key)
+		}
+	}
+	return complete
+}
+
+// flush returns every message being reassembled.
+func (r *reassembler) flush() []message {
+	var complete []message
+	for key, partial := range r.partial {
+		complete = append(complete, partial.message)
+		delete(r.partial, key)
+	}
+	return complete
+}
+
+// hasPriority reports whether datagram starts with the priority of a syslog
+// message, which is 1 to 3 digits in angle brackets.
+func hasPriority(datagram []byte) bool {
+	if len(datagram) < 3 || datagram[0] != '<' {
+		return false
+	}
+	for i := 1; i < len(datagram) && i <= 4; i++ {
+		switch c := datagram[i]; {
+		case c == '>':
+			return i > 1
+		case c < '0' || c > '9':
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package syslog
+
+import (
+	"net"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestHasPriority(t *testing.T) {
+	for datagram, want := range map[string]bool{
+		"<34>Oct 11 22:14:15 mymachine su: hi": true,
+		"<191>1 2003-10-11T22:14:15.003Z":      true,
+		"<>Oct":                                false,
+		"<1234>Oct":                            false,
+		"<3a>Oct":                              false,
+		"continued":                            false,
+		"<1":                                   false,
+	} {
+		if got := hasPriority([]byte(datagram)); got != want {
+			t.Errorf("hasPriority(%q) = %v, want %v", datagram, got, want)
+		}
+	}
+}
+
+func TestReassembler(t *testing.T) {
+	a := &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 514}
+	b := &net.UDPAddr{IP: net.ParseIP("192.0.2.2"), Port: 514}
+	start := time.Now()
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+	r := newReassembler()
+
+	var got []string
+	collect := func(messages []message) {
+		for _, m := range messages {
+			got = append(got, m.remote.String()+" "+string(m.data))
+		}
+	}
+	collect(r.add(a, []byte("<34>first "), at(0)))
+	collect(r.add(b, []byte("<34>other "), at(10)))
+	collect(r.add(a, []byte("part two "), at(20)))
+	collect(r.add(a, []byte("part three"), at(30)))
+	// A datagram with a priority starts another message.
+	collect(r.add(a, []byte("<34>second"), at(40)))
+	collect(r.expire(at(100)))
+	if diff := pretty.Compare(got, []string{"192.0.2.1:514 <34>first part two part three"}); diff != "" {
+		t.Errorf("messages diff: (-got +want)\n%s", diff)
+	}
+
+	got = nil
+	collect(r.expire(at(10 + int(reassemblyTimeout/time.Millisecond))))
+	if diff := pretty.Compare(got, []string{"192.0.2.2:514 <34>other "}); diff != "" {
+		t.Errorf("expired messages diff: (-got +want)\n%s", diff)
+	}
+	got = nil
+	collect(r.flush())
+	if diff := pretty.Compare(got, []string{"192.0.2.1:514 <34>second"}); diff != "" {
+		t.Errorf("flushed messages diff: (-got +want)\n%s", diff)
+	}
+
+	// Messages aren't reassembled past maxReassembledBytes.
+	got = nil
+	big := make([]byte, maxReassembledBytes-10)
+	collect(r.add(a, append([]byte("<34>"), big...), at(0)))
+	collect(r.add(a, []byte("overflowing"), at(1)))
+	collect(r.flush())
+	sizes := []int{len(got[0]), len(got[1])}
+	sort.Ints(sizes)
+	if want := []int{len("192.0.2.1:514 overflowing"), len("192.0.2.1:514 <34>") + len(big)}; sizes[0] != want[0] || sizes[1] != want[1] {
+		t.Errorf("message sizes = %v, want %v", sizes, want)
+	}
+}
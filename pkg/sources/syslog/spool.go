@@ -0,0 +1,307 @@
+package syslog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-errors/errors"
+	"github.com/go-logr/logr"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	// defaultSpoolMaxBytes is how many bytes of messages are spooled if the
+	// connection doesn't say.
+	defaultSpoolMaxBytes = 1 << 30
+	// spoolSegmentBytes is the size segment files are rotated at. Segments are
+	// removed once all their messages are scanned.
+	spoolSegmentBytes = 4 << 20
+	// spoolExt is the extension of segment files.
+	spoolExt = ".spool"
+	// spoolHeaderBytes is the size of the header of each record, which is the
+	// length of the message, how many of its bytes are stored, and the length
+	// of its metadata.
+	spoolHeaderBytes = 12
+	// spoolMaxMessageBytes is the most bytes a spooled message can have, which
+	// is more than a message read from UDP or TCP has, so that a damaged
+	// segment can't make the spool allocate unbounded memory.
+	spoolMaxMessageBytes = 1 << 20
+)
+
+// spool holds messages on disk while chunks can't be sent as fast as they
+// arrive, and sends them in the order they arrived as soon as they can be.
+// Messages are kept in segment files in dir, which are picked up again when
+// the spool is opened, so messages spooled before a restart aren't lost. A
+// message can be sent twice if the scan stops while its segment is being
+// sent.
+type spool struct {
+	dir      string
+	maxBytes int64
+	log      logr.Logger
+
+	mu sync.Mutex
+	// size is the bytes of the segments not yet sent.
+	size int64
+	// segments are the IDs of the segments not yet sent, oldest first. The
+	// last is being written to if writer is open.
+	segments []uint64
+	writer   *os.File
+	// written is the bytes written to writer.
+	written int64
+	nextID  uint64
+	closed  bool
+	// ready is signaled when a message is spooled.
+	ready chan struct{}
+}
+
+// openSpool opens the spool in dir, creating dir if it doesn't exist, with
+// the segments left in it.
+func openSpool(dir string, maxBytes int64, log logr.Logger) (*spool, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, errors.WrapPrefix(err, "could not create spool directory", 0)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not read spool directory", 0)
+	}
+	s := &spool{dir: dir, maxBytes: maxBytes, log: log, ready: make(chan struct{}, 1)}
+	for _, entry := range entries {
+		id, err := strconv.ParseUint(strings.TrimSuffix(entry.Name(), spoolExt), 10, 64)
+		if err != nil || !strings.HasSuffix(entry.Name(), spoolExt) || entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, errors.WrapPrefix(err, "could not read spool segment", 0)
+		}
+		s.segments = append(s.segments, id)
+		s.size += info.Size()
+		if id >= s.nextID {
+			s.nextID = id + 1
+		}
+	}
+	sort.Slice(s.segments, func(i, j int) bool { return s.segments[i] < s.segments[j] })
+	if len(s.segments) > 0 {
+		log.Info("sending spooled messages", "segments", len(s.segments), "bytes", s.size)
+		s.ready <- struct{}{}
+	}
+	return s, nil
+}
+
+func (s *spool) path(id uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", id, spoolExt))
+}
+
+// put sends chunk to chunksChan if it has room and no messages are spooled,
+// or else spools it. If the spool is full, put waits to send chunk, so it can
+// be sent ahead of messages that are spooled.
+func (s *spool) put(ctx context.Context, chunksChan chan *sources.Chunk, chunk *sources.Chunk) {
+	s.mu.Lock()
+	pending := len(s.segments) > 0
+	s.mu.Unlock()
+	if !pending {
+		select {
+		case chunksChan <- chunk:
+			return
+		default:
+		}
+	}
+
+	spooled, err := s.write(chunk)
+	if err != nil {
+		s.log.Error(err, "could not spool message")
+	}
+	if spooled {
+		return
+	}
+	select {
+	case chunksChan <- chunk:
+	case <-ctx.Done():
+	}
+}
+
+// write appends chunk's message to the spool, reporting whether it had room.
+func (s *spool) write(chunk *sources.Chunk) (bool, error) {
+	var metadata []byte
+	if chunk.SourceMetadata != nil {
+		var err error
+		if metadata, err = proto.Marshal(chunk.SourceMetadata); err != nil {
+			return false, err
+		}
+	}
+	// Messages are read into buffers bigger than they are, so the zeros
+	// they're padded with are left out.
+	data := bytes.TrimRight(chunk.Data, "\x00")
+	record := make([]byte, spoolHeaderBytes, spoolHeaderBytes+len(data)+len(metadata))
+	binary.BigEndian.PutUint32(record[0:], uint32(len(chunk.Data)))
+	binary.BigEndian.PutUint32(record[4:], uint32(len(data)))
+	binary.BigEndian.PutUint32(record[8:], uint32(len(metadata)))
+	record = append(append(record, data...), metadata...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false, nil
+	}
+	if s.size+int64(len(record)) > s.maxBytes {
+		s.log.V(1).Info("spool is full, waiting to scan message", "bytes", s.size)
+		return false, nil
+	}
+	if s.writer != nil && s.written >= spoolSegmentBytes {
+		if err := s.rotate(); err != nil {
+			return false, err
+		}
+	}
+	if s.writer == nil {
+		id := s.nextID
+		f, err := os.OpenFile(s.path(id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err != nil {
+			return false, errors.WrapPrefix(err, "could not create spool segment", 0)
+		}
+		s.nextID++
+		s.segments = append(s.segments, id)
+		s.writer, s.written = f, 0
+	}
+	n, err := s.writer.Write(record)
+	s.written += int64(n)
+	s.size += int64(n)
+	if err != nil {
+		// The segment ends with a partial record, so nothing more is written
+		// to it.
+		_ = s.rotate()
+		return false, errors.WrapPrefix(err, "could not write spool segment", 0)
+	}
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+	return true, nil
+}
+
+// rotate closes the segment being written, so the next message starts
+// another. It must be called with mu held.
+func (s *spool) rotate() error {
+	if s.writer == nil {
+		return nil
+	}
+	err := s.writer.Close()
+	s.writer = nil
+	return err
+}
+
+// next returns the oldest segment, closing it if it's being written to, or
+// false if no messages are spooled.
+func (s *spool) next() (uint64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.segments) == 0 {
+		return 0, false
+	}
+	if len(s.segments) == 1 && s.writer != nil {
+		if err := s.rotate(); err != nil {
+			s.log.Error(err, "could not close spool segment")
+		}
+	}
+	return s.segments[0], true
+}
+
+// drain sends spooled messages to chunksChan as chunks made by newChunk until
+// ctx is done.
+func (s *spool) drain(ctx context.Context, chunksChan chan *sources.Chunk, newChunk func([]byte, *source_metadatapb.MetaData) *sources.Chunk) {
+	for {
+		id, ok := s.next()
+		if !ok {
+			select {
+			case <-s.ready:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := s.send(ctx, id, chunksChan, newChunk); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.log.Error(err, "could not send spooled messages", "segment", s.path(id))
+		}
+
+		info, statErr := os.Stat(s.path(id))
+		if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+			s.log.Error(err, "could not remove spool segment", "segment", s.path(id))
+		}
+		s.mu.Lock()
+		s.segments = s.segments[1:]
+		if statErr == nil {
+			s.size -= info.Size()
+		}
+		s.mu.Unlock()
+	}
+}
+
+// send sends the messages in segment id. A segment that ends with a partial
+// record, such as one being written when the scan stopped, is sent up to it.
+func (s *spool) send(ctx context.Context, id uint64, chunksChan chan *sources.Chunk, newChunk func([]byte, *source_metadatapb.MetaData) *sources.Chunk) error {
+	f, err := os.Open(s.path(id))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	header := make([]byte, spoolHeaderBytes)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return errors.WrapPrefix(err, "spool segment ends with a partial record", 0)
+		}
+		length := binary.BigEndian.Uint32(header[0:])
+		stored := binary.BigEndian.Uint32(header[4:])
+		metadataLength := binary.BigEndian.Uint32(header[8:])
+		if stored > length || length > spoolMaxMessageBytes || metadataLength > spoolMaxMessageBytes {
+			return fmt.Errorf("spool segment has a record of %d bytes storing %d", length, stored)
+		}
+		data := make([]byte, length)
+		if _, err := io.ReadFull(r, data[:stored]); err != nil {
+			return errors.WrapPrefix(err, "spool segment ends with a partial record", 0)
+		}
+		var metadata *source_metadatapb.MetaData
+		if metadataLength > 0 {
+			raw := make([]byte, metadataLength)
+			if _, err := io.ReadFull(r, raw); err != nil {
+				return errors.WrapPrefix(err, "spool segment ends with a partial record", 0)
+			}
+			metadata = &source_metadatapb.MetaData{}
+			if err := proto.Unmarshal(raw, metadata); err != nil {
+				return errors.WrapPrefix(err, "could not read spooled metadata", 0)
+			}
+		}
+		select {
+		case chunksChan <- newChunk(data, metadata):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// close stops messages being spooled, leaving those that are to be sent when
+// the spool is opened again.
+func (s *spool) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return s.rotate()
+}
@@ -0,0 +1,161 @@
+package syslog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/kylelemons/godebug/pretty"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func spoolChunk(i int) *sources.Chunk {
+	// Messages are padded like those read by the listeners.
+	data := make([]byte, 64)
+	copy(data, fmt.Sprintf("message %d", i))
+	return &sources.Chunk{
+		Data: data,
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Syslog{Syslog: &source_metadatapb.Syslog{Client: fmt.Sprintf("client %d", i)}},
+		},
+	}
+}
+
+func newSpoolChunk(data []byte, metadata *source_metadatapb.MetaData) *sources.Chunk {
+	return &sources.Chunk{Data: data, SourceMetadata: metadata}
+}
+
+// receive returns the messages and clients of n chunks from chunksChan.
+func receive(t *testing.T, chunksChan chan *sources.Chunk, n int) []string {
+	t.Helper()
+	var got []string
+	for i := 0; i < n; i++ {
+		select {
+		case chunk := <-chunksChan:
+			if len(chunk.Data) != 64 {
+				t.Errorf("chunk has %d bytes, want 64", len(chunk.Data))
+			}
+			got = append(got, fmt.Sprintf("%s/%s", bytes.TrimRight(chunk.Data, "\x00"), chunk.SourceMetadata.GetSyslog().GetClient()))
+		case <-time.After(5 * time.Second):
+			t.Fatalf("received %d chunks, want %d", i, n)
+		}
+	}
+	return got
+}
+
+func want(from, to int) []string {
+	var w []string
+	for i := from; i < to; i++ {
+		w = append(w, fmt.Sprintf("message %d/client %d", i, i))
+	}
+	return w
+}
+
+func TestSpool_Burst(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sp, err := openSpool(t.TempDir(), defaultSpoolMaxBytes, logr.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunksChan := make(chan *sources.Chunk, 1)
+	// The burst is put while nothing is scanning chunks.
+	for i := 0; i < 10; i++ {
+		sp.put(ctx, chunksChan, spoolChunk(i))
+	}
+	if len(sp.segments) != 1 {
+		t.Fatalf("spooled %d segments, want 1", len(sp.segments))
+	}
+
+	go sp.drain(ctx, chunksChan, newSpoolChunk)
+	got := receive(t, chunksChan, 10)
+	if diff := pretty.Compare(got, want(0, 10)); diff != "" {
+		t.Errorf("chunks diff: (-got +want)\n%s", diff)
+	}
+	// Messages put while others are spooled are sent after them.
+	sp.put(ctx, chunksChan, spoolChunk(10))
+	if diff := pretty.Compare(receive(t, chunksChan, 1), want(10, 11)); diff != "" {
+		t.Errorf("chunks diff: (-got +want)\n%s", diff)
+	}
+}
+
+func TestSpool_Full(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// The spool has room for two messages.
+	record := spoolHeaderBytes + len("message 0") + proto.Size(spoolChunk(0).SourceMetadata)
+	sp, err := openSpool(t.TempDir(), int64(2*record), logr.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunksChan := make(chan *sources.Chunk)
+	sp.put(ctx, chunksChan, spoolChunk(0))
+	sp.put(ctx, chunksChan, spoolChunk(1))
+
+	// The third waits to be sent, ahead of those spooled.
+	go sp.put(ctx, chunksChan, spoolChunk(2))
+	if diff := pretty.Compare(receive(t, chunksChan, 1), want(2, 3)); diff != "" {
+		t.Errorf("chunks diff: (-got +want)\n%s", diff)
+	}
+	go sp.drain(ctx, chunksChan, newSpoolChunk)
+	if diff := pretty.Compare(receive(t, chunksChan, 2), want(0, 2)); diff != "" {
+		t.Errorf("chunks diff: (-got +want)\n%s", diff)
+	}
+}
+
+func TestSpool_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sp, err := openSpool(dir, defaultSpoolMaxBytes, logr.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunksChan := make(chan *sources.Chunk)
+	for i := 0; i < 3; i++ {
+		sp.put(ctx, chunksChan, spoolChunk(i))
+	}
+	if err := sp.close(); err != nil {
+		t.Fatal(err)
+	}
+	// A record cut short, as if the scan stopped while writing it, is left
+	// out of a later segment.
+	partial := filepath.Join(dir, fmt.Sprintf("%020d%s", 7, spoolExt))
+	if err := os.WriteFile(partial, []byte{0, 0, 0, 64, 0, 0}, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	sp, err = openSpool(dir, defaultSpoolMaxBytes, logr.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp.put(ctx, chunksChan, spoolChunk(3))
+	go sp.drain(ctx, chunksChan, newSpoolChunk)
+	if diff := pretty.Compare(receive(t, chunksChan, 4), want(0, 4)); diff != "" {
+		t.Errorf("chunks diff: (-got +want)\n%s", diff)
+	}
+
+	// Segments are removed once they're sent.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("spool has %d segments left", len(entries))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -7,16 +7,14 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"io"
 	"net"
-	"runtime"
+	"os"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/bill-rich/go-syslog/pkg/syslogparser/rfc3164"
 	"github.com/crewjam/rfc5424"
 	"github.com/go-errors/errors"
-	"github.com/sirupsen/logrus"
-	"golang.org/x/sync/semaphore"
-	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
@@ -26,43 +24,29 @@ import (
 
 const nilString = ""
 
+// udpPollInterval is how often the UDP listener stops waiting for datagrams to
+// check whether the scan has stopped.
+const udpPollInterval = 250 * time.Millisecond
+
 type Source struct {
-	name     string
-	sourceId int64
-	jobId    int64
-	verify   bool
-	syslog   *Syslog
-	aCtx     context.Context
-	sources.Progress
+	sources.Base
 	conn *sourcespb.Syslog
+	// listening is 1 while Chunks has a listener open.
+	listening int32
+	// allowedNetworks are the networks of the clients messages are accepted
+	// from, or every client's if there are none.
+	allowedNetworks []*net.IPNet
+	// hostnames looks up the names of clients, if it's enabled.
+	hostnames *hostnames
+	// spool holds messages while chunks can't be sent, if it's enabled.
+	spool *spool
 }
 
-type Syslog struct {
-	sourceType         sourcespb.SourceType
-	sourceName         string
-	sourceID           int64
-	jobID              int64
-	sourceMetadataFunc func(hostname, appname, procid, timestamp, facility, client string) *source_metadatapb.MetaData
-	verify             bool
-	concurrency        *semaphore.Weighted
-}
-
-func NewSyslog(sourceType sourcespb.SourceType, jobID, sourceID int64, sourceName string, verify bool, concurrency int,
-	sourceMetadataFunc func(hostname, appname, procid, timestamp, facility, client string) *source_metadatapb.MetaData,
-) *Syslog {
-	return &Syslog{
-		sourceType:         sourceType,
-		sourceName:         sourceName,
-		sourceID:           sourceID,
-		jobID:              jobID,
-		sourceMetadataFunc: sourceMetadataFunc,
-		verify:             verify,
-		concurrency:        semaphore.NewWeighted(int64(concurrency)),
-	}
-}
-
-// Ensure the Source satisfies the interface at compile time.
-var _ sources.Source = (*Source)(nil)
+// Ensure the Source satisfies the interfaces at compile time.
+var (
+	_ sources.Source   = (*Source)(nil)
+	_ sources.Listener = (*Source)(nil)
+)
 
 // Type returns the type of source.
 // It is used for matching source types in configuration and job input.
@@ -70,58 +54,32 @@ func (s *Source) Type() sourcespb.SourceType {
 	return sourcespb.SourceType_SOURCE_TYPE_SYSLOG
 }
 
-func (s *Source) SourceID() int64 {
-	return s.sourceId
-}
-
-func (s *Source) JobID() int64 {
-	return s.jobId
-}
-
 func (s *Source) InjectConnection(conn *sourcespb.Syslog) {
 	s.conn = conn
 }
 
 // Init returns an initialized Syslog source.
 func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
-
-	s.aCtx = aCtx
-	s.name = name
-	s.sourceId = sourceId
-	s.jobId = jobId
-	s.verify = verify
+	s.InitBase(aCtx, s.Type(), name, jobId, sourceId, verify, concurrency)
 
 	var conn sourcespb.Syslog
-	err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{})
-	if err != nil {
-		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	if err := sources.UnmarshalConnection(connection, &conn); err != nil {
+		return err
 	}
 
 	s.conn = &conn
 
-	err = s.verifyConnectionConfig()
-	if err != nil {
-		return errors.WrapPrefix(err, "invalid configuration", 0)
+	if err := s.verifyConnectionConfig(); err != nil {
+		return err
+	}
+	if s.conn.ReverseDns {
+		s.hostnames = newHostnames()
 	}
-
-	s.syslog = NewSyslog(s.Type(), s.jobId, s.sourceId, s.name, s.verify, runtime.NumCPU(),
-		func(hostname, appname, procID, timestamp, facility, client string) *source_metadatapb.MetaData {
-			return &source_metadatapb.MetaData{
-				Data: &source_metadatapb.MetaData_Syslog{
-					Syslog: &source_metadatapb.Syslog{
-						Hostname:  hostname,
-						Appname:   appname,
-						Procid:    procID,
-						Timestamp: timestamp,
-						Facility:  facility,
-						Client:    client,
-					},
-				},
-			}
-		})
 	return nil
 }
 
+// verifyConnectionConfig fills in the defaults of the connection's options and
+// checks them, so a misconfigured listener fails Init rather than Chunks.
 func (s *Source) verifyConnectionConfig() error {
 	tlsEnabled := s.conn.TlsCert != nilString || s.conn.TlsKey != nilString
 	if s.conn.Protocol == nilString {
@@ -132,10 +90,6 @@ func (s *Source) verifyConnectionConfig() error {
 		}
 	}
 
-	if s.conn.Protocol == "udp" && tlsEnabled {
-		return fmt.Errorf("TLS is not supported over UDP")
-	}
-
 	if s.conn.ListenAddress == nilString {
 		s.conn.ListenAddress = ":5140"
 	}
@@ -143,11 +97,73 @@ func (s *Source) verifyConnectionConfig() error {
 	if s.conn.Format == nilString {
 		s.conn.Format = "rfc3164"
 	}
-	return nil
+
+	var v sources.Validator
+	switch s.conn.Protocol {
+	case "tcp":
+		if (s.conn.TlsCert == nilString) != (s.conn.TlsKey == nilString) {
+			v.Problemf("tlsCert and tlsKey must be given together")
+		}
+	case "udp":
+		if tlsEnabled {
+			v.Problemf("TLS is not supported over UDP, use protocol \"tcp\" with tlsCert and tlsKey")
+		}
+	default:
+		v.Problemf("protocol %q is not supported, use \"tcp\" or \"udp\"", s.conn.Protocol)
+	}
+	if s.conn.ReassembleUdp && s.conn.Protocol != "udp" {
+		v.Problemf("reassembleUdp is only supported over UDP")
+	}
+	if s.conn.Protocol == "tcp" || s.conn.Protocol == "udp" {
+		v.ListenAddress("listenAddress", s.conn.Protocol, s.conn.ListenAddress)
+	}
+	if s.conn.Format != "rfc3164" && s.conn.Format != "rfc5424" {
+		v.Problemf("format %q is not supported, use \"rfc3164\" or \"rfc5424\"", s.conn.Format)
+	}
+	s.allowedNetworks = nil
+	for i, cidr := range s.conn.AllowedCidrs {
+		network, err := parseCIDR(cidr)
+		if err != nil {
+			v.Problemf("allowedCidrs[%d] %q is not a CIDR or IP address, such as \"10.0.0.0/8\"", i, cidr)
+			continue
+		}
+		s.allowedNetworks = append(s.allowedNetworks, network)
+	}
+	if s.conn.SpoolDir != nilString {
+		if s.conn.SpoolMaxBytes == 0 {
+			s.conn.SpoolMaxBytes = defaultSpoolMaxBytes
+		}
+		v.Require("spoolMaxBytes", s.conn.SpoolMaxBytes > 0)
+		if info, err := os.Stat(s.conn.SpoolDir); err == nil && !info.IsDir() {
+			v.Problemf("spoolDir %q is not a directory", s.conn.SpoolDir)
+		}
+	}
+	return v.Err()
 }
 
 // Chunks emits chunks of bytes over a channel.
 func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	if s.conn.SpoolDir != nilString {
+		sp, err := openSpool(s.conn.SpoolDir, s.conn.SpoolMaxBytes, s.Log())
+		if err != nil {
+			return err
+		}
+		s.spool = sp
+		drainCtx, cancel := context.WithCancel(ctx)
+		drained := make(chan struct{})
+		go func() {
+			defer close(drained)
+			sp.drain(drainCtx, chunksChan, s.NewChunk)
+		}()
+		defer func() {
+			cancel()
+			<-drained
+			if err := sp.close(); err != nil {
+				s.Log().Error(err, "could not close spool")
+			}
+		}()
+	}
+
 	switch {
 	case s.conn.TlsCert != nilString || s.conn.TlsKey != nilString:
 		cert, err := tls.X509KeyPair([]byte(s.conn.TlsCert), []byte(s.conn.TlsKey))
@@ -160,6 +176,7 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) err
 			return errors.WrapPrefix(err, "error creating TLS listener", 0)
 		}
 		defer lis.Close()
+		defer s.setListening()()
 
 		return s.acceptTCPConnections(ctx, lis, chunksChan)
 	case s.conn.Protocol == "tcp":
@@ -168,6 +185,7 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) err
 			return errors.WrapPrefix(err, "error creating TCP listener", 0)
 		}
 		defer lis.Close()
+		defer s.setListening()()
 
 		return s.acceptTCPConnections(ctx, lis, chunksChan)
 	case s.conn.Protocol == "udp":
@@ -175,11 +193,8 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) err
 		if err != nil {
 			return errors.WrapPrefix(err, "error creating UDP listener", 0)
 		}
-		err = lis.SetDeadline(time.Now().Add(time.Second))
-		if err != nil {
-			return errors.WrapPrefix(err, "could not set UDP deadline", 0)
-		}
 		defer lis.Close()
+		defer s.setListening()()
 
 		return s.acceptUDPConnections(ctx, lis, chunksChan)
 	default:
@@ -187,6 +202,17 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) err
 	}
 }
 
+// Listening reports whether the source has a listener open.
+func (s *Source) Listening() bool {
+	return atomic.LoadInt32(&s.listening) == 1
+}
+
+// setListening marks the source as listening until the returned func is called.
+func (s *Source) setListening() func() {
+	atomic.StoreInt32(&s.listening, 1)
+	return func() { atomic.StoreInt32(&s.listening, 0) }
+}
+
 func (s *Source) parseSyslogMetadata(input []byte, remote string) (*source_metadatapb.MetaData, error) {
 	var metadata *source_metadatapb.MetaData
 	switch s.conn.Format {
@@ -196,7 +222,7 @@ func (s *Source) parseSyslogMetadata(input []byte, remote string) (*source_metad
 		if err != nil {
 			return metadata, errors.WrapPrefix(err, "could not parse syslog as rfc5424", 0)
 		}
-		metadata = s.syslog.sourceMetadataFunc(message.Hostname, message.AppName, message.ProcessID, message.Timestamp.String(), nilString, remote)
+		metadata = syslogMetadata(message.Hostname, message.AppName, message.ProcessID, message.Timestamp.String(), nilString, remote)
 	case "rfc3164":
 		parser := rfc3164.NewParser(input)
 		err := parser.Parse()
@@ -204,7 +230,36 @@ func (s *Source) parseSyslogMetadata(input []byte, remote string) (*source_metad
 			return metadata, errors.WrapPrefix(err, "could not parse syslog as rfc3164", 0)
 		}
 		data := parser.Dump()
-		metadata = s.syslog.sourceMetadataFunc(data["hostname"].(string), nilString, nilString, data["timestamp"].(time.Time).String(), strconv.Itoa(data["facility"].(int)), remote)
+		metadata = syslogMetadata(data["hostname"].(string), nilString, nilString, data["timestamp"].(time.Time).String(), strconv.Itoa(data["facility"].(int)), remote)
+	}
+	return metadata, nil
+}
+
+// syslogMetadata returns the metadata of a message from client.
+func syslogMetadata(hostname, appname, procID, timestamp, facility, client string) *source_metadatapb.MetaData {
+	return &source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_Syslog{
+			Syslog: &source_metadatapb.Syslog{
+				Hostname:  hostname,
+				Appname:   appname,
+				Procid:    procID,
+				Timestamp: timestamp,
+				Facility:  facility,
+				Client:    client,
+			},
+		},
+	}
+}
+
+// clientMetadata returns the metadata of a message from the client at remote,
+// with the client's hostname if they're looked up.
+func (s *Source) clientMetadata(ctx context.Context, input []byte, remote net.Addr) (*source_metadatapb.MetaData, error) {
+	metadata, err := s.parseSyslogMetadata(input, remote.String())
+	if err != nil || s.hostnames == nil {
+		return metadata, err
+	}
+	if ip := clientIP(remote); ip != nil && metadata.GetSyslog() != nil {
+		metadata.GetSyslog().ClientHostname = s.hostnames.lookup(ctx, ip)
 	}
 	return metadata, nil
 }
@@ -216,7 +271,7 @@ func (s *Source) monitorConnection(ctx context.Context, conn net.Conn, chunksCha
 		}
 		err := conn.SetDeadline(time.Now().Add(time.Second))
 		if err != nil {
-			logrus.WithError(err).Debug("could not set connection deadline deadline")
+			s.Log().V(1).Info("could not set connection deadline", "error", err.Error())
 		}
 		input := make([]byte, 8096)
 		remote := conn.RemoteAddr()
@@ -227,19 +282,12 @@ func (s *Source) monitorConnection(ctx context.Context, conn net.Conn, chunksCha
 			}
 			continue
 		}
-		logrus.Trace(string(input))
-		metadata, err := s.parseSyslogMetadata(input, remote.String())
+		s.Log().V(2).Info("received message", "message", string(input))
+		metadata, err := s.clientMetadata(ctx, input, remote)
 		if err != nil {
-			logrus.WithError(err).Debug("failed to generate metadata")
-		}
-		chunksChan <- &sources.Chunk{
-			SourceName:     s.syslog.sourceName,
-			SourceID:       s.syslog.sourceID,
-			SourceType:     s.syslog.sourceType,
-			SourceMetadata: metadata,
-			Data:           input,
-			Verify:         s.verify,
+			s.Log().V(1).Info("failed to generate metadata", "error", err.Error())
 		}
+		s.send(ctx, chunksChan, s.NewChunk(input, metadata))
 	}
 }
 
@@ -250,37 +298,89 @@ func (s *Source) acceptTCPConnections(ctx context.Context, netListener net.Liste
 		}
 		conn, err := netListener.Accept()
 		if err != nil {
-			logrus.WithError(err).Debug("failed to accept TCP connection")
+			s.Log().V(1).Info("failed to accept TCP connection", "error", err.Error())
+			continue
+		}
+		if !s.allowed(conn.RemoteAddr()) {
+			s.Log().V(1).Info("rejected TCP connection from a client that isn't allowed", "client", conn.RemoteAddr().String())
+			_ = conn.Close()
 			continue
 		}
 		go s.monitorConnection(ctx, conn, chunksChan)
 	}
 }
 
+// acceptUDPConnections scans each datagram received as a message, or the
+// messages they're reassembled into if reassembleUdp is set.
 func (s *Source) acceptUDPConnections(ctx context.Context, netListener net.PacketConn, chunksChan chan *sources.Chunk) error {
+	scan := func(m message) {
+		metadata, err := s.clientMetadata(ctx, m.data, m.remote)
+		if err != nil {
+			s.Log().V(1).Info("failed to parse metadata", "error", err.Error())
+		}
+		s.send(ctx, chunksChan, s.NewChunk(m.data, metadata))
+	}
+	var reassembly *reassembler
+	if s.conn.ReassembleUdp {
+		reassembly = newReassembler()
+		defer func() {
+			for _, m := range reassembly.flush() {
+				scan(m)
+			}
+		}()
+	}
+
+	input := make([]byte, maxDatagramBytes)
 	for {
 		if common.IsDone(ctx) {
 			return nil
 		}
-		input := make([]byte, 65535)
-		_, remote, err := netListener.ReadFrom(input)
+		// Reads time out so that the scan stopping and messages waiting to be
+		// reassembled are noticed while no datagrams arrive.
+		if err := netListener.SetReadDeadline(time.Now().Add(udpPollInterval)); err != nil {
+			return errors.WrapPrefix(err, "could not set UDP read deadline", 0)
+		}
+		n, remote, err := netListener.ReadFrom(input)
+		if reassembly != nil {
+			for _, m := range reassembly.expire(time.Now()) {
+				scan(m)
+			}
+		}
 		if err != nil {
-			if errors.Is(err, io.EOF) {
+			if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
 				return nil
 			}
+			var netErr net.Error
+			if !errors.As(err, &netErr) || !netErr.Timeout() {
+				s.Log().V(1).Info("failed to read UDP message", "error", err.Error())
+			}
 			continue
 		}
-		metadata, err := s.parseSyslogMetadata(input, remote.String())
-		if err != nil {
-			logrus.WithError(err).Debug("failed to parse metadata")
+		if !s.allowed(remote) {
+			s.Log().V(2).Info("dropped UDP message from a client that isn't allowed", "client", remote.String())
+			continue
+		}
+		datagram := make([]byte, n)
+		copy(datagram, input[:n])
+		if reassembly == nil {
+			scan(message{remote: remote, data: datagram})
+			continue
 		}
-		chunksChan <- &sources.Chunk{
-			SourceName:     s.syslog.sourceName,
-			SourceID:       s.syslog.sourceID,
-			SourceType:     s.syslog.sourceType,
-			SourceMetadata: metadata,
-			Data:           input,
-			Verify:         s.verify,
+		for _, m := range reassembly.add(remote, datagram, time.Now()) {
+			scan(m)
 		}
 	}
 }
+
+// send sends chunk, spooling it if it's enabled and chunksChan is full, so
+// that bursts of messages don't hold up the listeners.
+func (s *Source) send(ctx context.Context, chunksChan chan *sources.Chunk, chunk *sources.Chunk) {
+	if s.spool != nil {
+		s.spool.put(ctx, chunksChan, chunk)
+		return
+	}
+	select {
+	case chunksChan <- chunk:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,181 @@
+package syslog
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestSource_Init(t *testing.T) {
+	tests := []struct {
+		name     string
+		conn     *sourcespb.Syslog
+		wantConn *sourcespb.Syslog
+		wantErr  string
+	}{
+		{
+			name:     "defaults",
+			conn:     &sourcespb.Syslog{},
+			wantConn: &sourcespb.Syslog{Protocol: "udp", ListenAddress: ":5140", Format: "rfc3164"},
+		},
+		{
+			name:     "tls defaults to tcp",
+			conn:     &sourcespb.Syslog{TlsCert: "cert", TlsKey: "key", Format: "rfc5424"},
+			wantConn: &sourcespb.Syslog{Protocol: "tcp", ListenAddress: ":5140", TlsCert: "cert", TlsKey: "key", Format: "rfc5424"},
+		},
+		{
+			name:    "tls over udp",
+			conn:    &sourcespb.Syslog{Protocol: "udp", TlsCert: "cert", TlsKey: "key"},
+			wantErr: "TLS is not supported over UDP",
+		},
+		{
+			name:    "cert without key",
+			conn:    &sourcespb.Syslog{TlsCert: "cert"},
+			wantErr: "tlsCert and tlsKey must be given together",
+		},
+		{
+			name:    "unknown protocol",
+			conn:    &sourcespb.Syslog{Protocol: "sctp"},
+			wantErr: `protocol "sctp" is not supported`,
+		},
+		{
+			name:    "address without port",
+			conn:    &sourcespb.Syslog{ListenAddress: "127.0.0.1"},
+			wantErr: `listenAddress "127.0.0.1" is not a host:port address`,
+		},
+		{
+			name:    "unknown format",
+			conn:    &sourcespb.Syslog{Format: "json"},
+			wantErr: `format "json" is not supported`,
+		},
+		{
+			name:     "allowed cidrs",
+			conn:     &sourcespb.Syslog{AllowedCidrs: []string{"10.0.0.0/8", "192.0.2.1", "2001:db8::/32"}},
+			wantConn: &sourcespb.Syslog{Protocol: "udp", ListenAddress: ":5140", Format: "rfc3164", AllowedCidrs: []string{"10.0.0.0/8", "192.0.2.1", "2001:db8::/32"}},
+		},
+		{
+			name:    "invalid cidr",
+			conn:    &sourcespb.Syslog{AllowedCidrs: []string{"10.0.0.0/8", "10.0.0.0/33"}},
+			wantErr: `allowedCidrs[1] "10.0.0.0/33" is not a CIDR or IP address`,
+		},
+		{
+			name:     "spool defaults",
+			conn:     &sourcespb.Syslog{SpoolDir: "spool"},
+			wantConn: &sourcespb.Syslog{Protocol: "udp", ListenAddress: ":5140", Format: "rfc3164", SpoolDir: "spool", SpoolMaxBytes: defaultSpoolMaxBytes},
+		},
+		{
+			name:    "reassembly over tcp",
+			conn:    &sourcespb.Syslog{Protocol: "tcp", ReassembleUdp: true},
+			wantErr: "reassembleUdp is only supported over UDP",
+		},
+		{
+			name:    "negative spool size",
+			conn:    &sourcespb.Syslog{SpoolDir: "spool", SpoolMaxBytes: -1},
+			wantErr: "spoolMaxBytes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := anypb.New(tt.conn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := Source{}
+			err = s.Init(context.Background(), "test syslog", 0, 0, false, conn, 1)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Init() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Init() error = %v", err)
+			}
+			if !proto.Equal(s.conn, tt.wantConn) {
+				t.Errorf("Init() connection = %v, want %v", s.conn, tt.wantConn)
+			}
+		})
+	}
+}
+
+func TestSource_Chunks_udp(t *testing.T) {
+	tests := []struct {
+		name       string
+		reassemble bool
+		datagrams  []string
+		want       []string
+	}{
+		{
+			name:      "datagrams",
+			datagrams: []string{"<34>Oct 11 22:14:15 mymachine su: token=abc", "continued"},
+			want:      []string{"<34>Oct 11 22:14:15 mymachine su: token=abc", "continued"},
+		},
+		{
+			name:       "reassembled",
+			reassemble: true,
+			datagrams:  []string{"<34>Oct 11 22:14:15 mymachine su: token=", "abc", "<34>Oct 11 22:14:16 mymachine su: done"},
+			want:       []string{"<34>Oct 11 22:14:15 mymachine su: token=abc", "<34>Oct 11 22:14:16 mymachine su: done"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			lis, err := net.ListenPacket("udp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			addr := lis.LocalAddr().String()
+			lis.Close()
+
+			conn, err := anypb.New(&sourcespb.Syslog{Protocol: "udp", ListenAddress: addr, ReassembleUdp: tt.reassemble})
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := Source{}
+			if err := s.Init(ctx, "test syslog", 0, 0, false, conn, 1); err != nil {
+				t.Fatal(err)
+			}
+			chunksChan := make(chan *sources.Chunk, len(tt.datagrams))
+			go func() { _ = s.Chunks(ctx, chunksChan) }()
+			for !s.Listening() {
+				time.Sleep(10 * time.Millisecond)
+			}
+			// The listener keeps listening after its reads time out.
+			time.Sleep(3 * udpPollInterval)
+
+			client, err := net.Dial("udp", addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+			for _, datagram := range tt.datagrams {
+				if _, err := client.Write([]byte(datagram)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var got []string
+			for range tt.want {
+				select {
+				case chunk := <-chunksChan:
+					got = append(got, string(chunk.Data))
+				case <-ctx.Done():
+					t.Fatalf("got chunks %q, want %q", got, tt.want)
+				}
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got chunks %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
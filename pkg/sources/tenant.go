@@ -0,0 +1,18 @@
+package sources
+
+import "context"
+
+type tenantKey struct{}
+
+// WithTenant returns a copy of ctx in which sources scan the assets of
+// tenant, such as a customer of a service scanning many. The engine tags the
+// chunks of sources scanned with ctx, and so their results, with tenant.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant stored in ctx, or "" if there is none.
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
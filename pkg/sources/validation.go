@@ -0,0 +1,145 @@
+package sources
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+// Validator collects the problems with the connection a source is initialized
+// with, naming the fields to fix, so Init reports all of them at once rather
+// than the scan failing on the first one partway through. Fields are named as
+// they are in the source's connection message:
+//
+//	var v sources.Validator
+//	v.Require("domains", len(conn.Domains) > 0)
+//	for i, domain := range conn.Domains {
+//		v.Hostname(fmt.Sprintf("domains[%d]", i), domain)
+//	}
+//	if err := v.Err(); err != nil {
+//		return err
+//	}
+type Validator struct {
+	problems []string
+}
+
+// Problemf records a problem with the connection, such as options that can't
+// be used together.
+func (v *Validator) Problemf(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+// Require records that field is required unless it's set.
+func (v *Validator) Require(field string, set bool) {
+	if !set {
+		v.Problemf("%s is required", field)
+	}
+}
+
+// Credential records that the connection's credential is required unless
+// it's set, listing the kinds of credential the source takes.
+func (v *Validator) Credential(set bool, kinds ...string) {
+	if !set {
+		v.Problemf("a credential is required, one of %s", strings.Join(kinds, ", "))
+	}
+}
+
+// ListenAddress records a problem unless addr is a host:port address that
+// can be listened on over network, such as "tcp" or "udp". The host may be
+// empty, to listen on every interface.
+func (v *Validator) ListenAddress(field, network, addr string) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		v.Problemf("%s %q is not a host:port address, such as \"127.0.0.1:514\" or \":514\"", field, addr)
+		return
+	}
+	if host != "" && net.ParseIP(host) == nil && !validHostname(host) {
+		v.Problemf("%s %q has an invalid host %q", field, addr, host)
+	}
+	if _, err := net.LookupPort(network, port); err != nil {
+		v.Problemf("%s %q has an invalid %s port %q", field, addr, network, port)
+	}
+}
+
+// URL records a problem unless raw is an absolute http or https URL, such as
+// the endpoint of a self-hosted service.
+func (v *Validator) URL(field, raw string) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.Problemf("%s %q is not an http or https URL, such as \"https://example.com\"", field, raw)
+	}
+}
+
+// Hostname records a problem unless name is a domain name, such as
+// "example.com", without a scheme or path.
+func (v *Validator) Hostname(field, name string) {
+	if !validHostname(strings.TrimSuffix(name, ".")) {
+		v.Problemf("%s %q is not a domain name, such as \"example.com\"", field, name)
+	}
+}
+
+// BucketName records a problem unless name follows S3's bucket naming rules.
+// https://docs.aws.amazon.com/AmazonS3/latest/userguide/bucketnamingrules.html
+func (v *Validator) BucketName(field, name string) {
+	if problem := bucketNameProblem(name); problem != "" {
+		v.Problemf("%s %q is not a bucket name: %s", field, name, problem)
+	}
+}
+
+// Err returns an error listing the problems recorded, or nil if there are
+// none.
+func (v *Validator) Err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return errors.Errorf("invalid connection: %s", strings.Join(v.problems, "; "))
+}
+
+// validHostname reports whether name is made of DNS labels: letters, digits,
+// hyphens and underscores, not starting or ending with a hyphen.
+func validHostname(name string) bool {
+	if name == "" || len(name) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if !isLower(c) && !isDigit(c) && !(c >= 'A' && c <= 'Z') && c != '-' && c != '_' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// bucketNameProblem returns what's wrong with an S3 bucket name, or "" if
+// nothing is.
+func bucketNameProblem(name string) string {
+	switch {
+	case len(name) < 3 || len(name) > 63:
+		return "it must be 3 to 63 characters long"
+	case strings.IndexFunc(name, func(c rune) bool { return !isLower(c) && !isDigit(c) && c != '.' && c != '-' }) >= 0:
+		return "it may only have lowercase letters, digits, dots and hyphens"
+	case !isLower(rune(name[0])) && !isDigit(rune(name[0])),
+		!isLower(rune(name[len(name)-1])) && !isDigit(rune(name[len(name)-1])):
+		return "it must start and end with a lowercase letter or digit"
+	case strings.Contains(name, ".."):
+		return "it may not have adjacent dots"
+	case net.ParseIP(name) != nil:
+		return "it may not be an IP address"
+	}
+	return ""
+}
+
+func isLower(c rune) bool {
+	return c >= 'a' && c <= 'z'
+}
+
+func isDigit(c rune) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,116 @@
+package sources
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidator(t *testing.T) {
+	tests := []struct {
+		name  string
+		check func(v *Validator)
+		want  []string
+	}{
+		{
+			name:  "valid",
+			check: func(v *Validator) { v.Require("domains", true) },
+		},
+		{
+			name:  "required",
+			check: func(v *Validator) { v.Require("domains", false) },
+			want:  []string{"domains is required"},
+		},
+		{
+			name:  "credential",
+			check: func(v *Validator) { v.Credential(false, "token", "unauthenticated") },
+			want:  []string{"a credential is required, one of token, unauthenticated"},
+		},
+		{
+			name: "listen addresses",
+			check: func(v *Validator) {
+				v.ListenAddress("valid", "udp", ":5140")
+				v.ListenAddress("host", "tcp", "localhost:514")
+				v.ListenAddress("ipv6", "tcp", "[::1]:514")
+				v.ListenAddress("no port", "tcp", "5140")
+				v.ListenAddress("bad port", "tcp", "127.0.0.1:99999")
+				v.ListenAddress("bad host", "tcp", "local host:514")
+			},
+			want: []string{
+				`no port "5140" is not a host:port address`,
+				`bad port "127.0.0.1:99999" has an invalid tcp port "99999"`,
+				`bad host "local host:514" has an invalid host "local host"`,
+			},
+		},
+		{
+			name: "urls",
+			check: func(v *Validator) {
+				v.URL("valid", "https://gitlab.example.com/")
+				v.URL("no scheme", "gitlab.example.com")
+				v.URL("other scheme", "ftp://gitlab.example.com")
+			},
+			want: []string{
+				`no scheme "gitlab.example.com" is not an http or https URL`,
+				`other scheme "ftp://gitlab.example.com" is not an http or https URL`,
+			},
+		},
+		{
+			name: "hostnames",
+			check: func(v *Validator) {
+				v.Hostname("valid", "example.com")
+				v.Hostname("fqdn", "example.com.")
+				v.Hostname("url", "https://example.com")
+				v.Hostname("empty label", "example..com")
+			},
+			want: []string{
+				`url "https://example.com" is not a domain name`,
+				`empty label "example..com" is not a domain name`,
+			},
+		},
+		{
+			name: "bucket names",
+			check: func(v *Validator) {
+				v.BucketName("valid", "thog-tmp-test")
+				v.BucketName("dotted", "logs.example.com")
+				v.BucketName("short", "ab")
+				v.BucketName("upper", "My-Bucket")
+				v.BucketName("hyphen", "bucket-")
+				v.BucketName("dots", "my..bucket")
+				v.BucketName("ip", "192.168.5.4")
+			},
+			want: []string{
+				`short "ab" is not a bucket name: it must be 3 to 63 characters long`,
+				`upper "My-Bucket" is not a bucket name: it may only have lowercase letters`,
+				`hyphen "bucket-" is not a bucket name: it must start and end`,
+				`dots "my..bucket" is not a bucket name: it may not have adjacent dots`,
+				`ip "192.168.5.4" is not a bucket name: it may not be an IP address`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v Validator
+			tt.check(&v)
+			err := v.Err()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Errorf("Err() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Err() = nil, want %q", tt.want)
+			}
+			// Every problem is reported, in the order it was found.
+			msg := strings.TrimPrefix(err.Error(), "invalid connection: ")
+			problems := strings.Split(msg, "; ")
+			if len(problems) != len(tt.want) {
+				t.Fatalf("Err() = %q, want %d problems", err, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.HasPrefix(problems[i], want) {
+					t.Errorf("problem %d = %q, want prefix %q", i, problems[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package vault
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/go-logr/logr"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/secretsmanager"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// maxAuditLineSize is the longest audit log entry that will be scanned.
+// Vault writes one JSON object per line, and responses can be large.
+const maxAuditLineSize = 10 * 1024 * 1024 // 10MB
+
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	aCtx     context.Context
+	log      logr.Logger
+	sources.Progress
+	conn        *sourcespb.Vault
+	httpOptions []common.HttpClientOption
+}
+
+// Ensure the Source satisfies the interface at compile time.
+var _ sources.Source = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_VAULT
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// Init returns an initialized Vault source.
+func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, _ int) error {
+	s.log = log.FromContext(aCtx).WithValues("name", name)
+
+	s.aCtx = aCtx
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+
+	var conn sourcespb.Vault
+	if err := sources.UnmarshalConnection(connection, &conn); err != nil {
+		return err
+	}
+	if err := validateConnection(&conn); err != nil {
+		return err
+	}
+	s.conn = &conn
+
+	httpOptions, err := common.NewHttpClientOptions(conn.Tls, conn.Proxy)
+	if err != nil {
+		return err
+	}
+	s.httpOptions = httpOptions
+
+	return nil
+}
+
+// validateConnection checks that the connection has mounts or audit logs to
+// scan, and the endpoint of the Vault server its mounts are on.
+func validateConnection(conn *sourcespb.Vault) error {
+	var v sources.Validator
+	if len(conn.Mounts) == 0 && len(conn.AuditLogs) == 0 {
+		v.Problemf("mounts or audit_logs is required")
+	}
+	switch {
+	case conn.Endpoint != "":
+		v.URL("endpoint", conn.Endpoint)
+	case len(conn.Mounts) > 0:
+		v.Problemf("endpoint is required to scan KV mounts")
+	}
+	return v.Err()
+}
+
+// Chunks emits chunks of bytes over a channel.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	total := len(s.conn.Mounts) + len(s.conn.AuditLogs)
+	done := 0
+
+	if len(s.conn.Mounts) > 0 {
+		kv := secretsmanager.NewVaultKV(s.conn.Endpoint, s.conn.GetToken(), s.httpOptions...)
+		for _, mount := range s.conn.Mounts {
+			if common.IsDone(ctx) {
+				return nil
+			}
+			s.SetProgressComplete(done, total, fmt.Sprintf("Mount: %s", mount), "")
+			done++
+
+			err := kv.Walk(ctx, mount, func(secretPath string, data map[string]interface{}) error {
+				return s.chunkSecret(ctx, chunksChan, mount, secretPath, data)
+			})
+			if err != nil {
+				if common.IsDone(ctx) {
+					return nil
+				}
+				return errors.WrapPrefix(err, fmt.Sprintf("could not scan Vault mount: %s", mount), 0)
+			}
+		}
+	}
+
+	for _, file := range s.conn.AuditLogs {
+		if common.IsDone(ctx) {
+			return nil
+		}
+		s.SetProgressComplete(done, total, fmt.Sprintf("Audit log: %s", file), "")
+		done++
+
+		if err := s.chunkAuditLog(ctx, chunksChan, file); err != nil {
+			return errors.WrapPrefix(err, fmt.Sprintf("could not scan Vault audit log: %s", file), 0)
+		}
+	}
+
+	return nil
+}
+
+// chunkSecret emits a single chunk holding every field of a KV secret, one
+// "key: value" pair per line so detectors can use the field names as keywords.
+func (s *Source) chunkSecret(ctx context.Context, chunksChan chan *sources.Chunk, mount, secretPath string, data map[string]interface{}) error {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		value, ok := data[key].(string)
+		if !ok {
+			encoded, err := json.Marshal(data[key])
+			if err != nil {
+				continue
+			}
+			value = string(encoded)
+		}
+		fmt.Fprintf(&b, "%s: %s\n", key, value)
+	}
+	if b.Len() == 0 {
+		return nil
+	}
+
+	chunk := &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		Data:       []byte(b.String()),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Vault{
+				Vault: &source_metadatapb.Vault{
+					Address: sanitizer.UTF8(s.conn.Endpoint),
+					Mount:   sanitizer.UTF8(strings.Trim(mount, "/")),
+					Path:    sanitizer.UTF8(secretPath),
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+
+	select {
+	case chunksChan <- chunk:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// auditEntry holds the parts of a Vault audit device entry used for metadata.
+type auditEntry struct {
+	Time    string `json:"time"`
+	Request struct {
+		Path       string `json:"path"`
+		MountPoint string `json:"mount_point"`
+	} `json:"request"`
+}
+
+// chunkAuditLog emits a chunk for every entry in a file audit device log.
+// Vault HMACs secret values in audit logs by default, so findings here usually
+// come from mounts or fields configured to be logged in plaintext.
+func (s *Source) chunkAuditLog(ctx context.Context, chunksChan chan *sources.Chunk, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxAuditLineSize)
+	var line int64
+	for scanner.Scan() {
+		line++
+		data := scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+
+		var entry auditEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			s.log.V(1).Info("could not parse audit log entry", "file", file, "line", line, "error", err.Error())
+		}
+
+		chunk := &sources.Chunk{
+			SourceType: s.Type(),
+			SourceName: s.name,
+			SourceID:   s.SourceID(),
+			Data:       append([]byte{}, data...),
+			SourceMetadata: &source_metadatapb.MetaData{
+				Data: &source_metadatapb.MetaData_Vault{
+					Vault: &source_metadatapb.Vault{
+						Address:   sanitizer.UTF8(s.conn.Endpoint),
+						Mount:     sanitizer.UTF8(strings.Trim(entry.Request.MountPoint, "/")),
+						Path:      sanitizer.UTF8(entry.Request.Path),
+						File:      sanitizer.UTF8(file),
+						Line:      line,
+						Timestamp: sanitizer.UTF8(entry.Time),
+					},
+				},
+			},
+			Verify: s.verify,
+		}
+
+		select {
+		case chunksChan <- chunk:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	return scanner.Err()
+}
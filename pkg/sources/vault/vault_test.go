@@ -0,0 +1,148 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestSource_Chunks(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body interface{}
+		switch r.URL.Path {
+		case "/v1/kv/metadata/":
+			body = map[string]interface{}{"data": map[string]interface{}{"keys": []string{"prod/"}}}
+		case "/v1/kv/metadata/prod/":
+			body = map[string]interface{}{"data": map[string]interface{}{"keys": []string{"db"}}}
+		case "/v1/kv/data/prod/db":
+			body = map[string]interface{}{"data": map[string]interface{}{"data": map[string]interface{}{"username": "app", "password": "hunter2"}}}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	defer server.Close()
+
+	auditLog := filepath.Join(t.TempDir(), "audit.log")
+	entry := `{"time":"2022-06-01T00:00:00Z","type":"request","request":{"path":"kv/data/prod/db","mount_point":"kv/"}}`
+	if err := os.WriteFile(auditLog, []byte(entry+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := anypb.New(&sourcespb.Vault{
+		Endpoint:   server.URL,
+		Credential: &sourcespb.Vault_Token{Token: "root"},
+		Mounts:     []string{"kv"},
+		AuditLogs:  []string{auditLog},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := Source{}
+	if err := s.Init(ctx, "test vault", 0, 0, false, conn, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	chunksCh := make(chan *sources.Chunk, 2)
+	if err := s.Chunks(ctx, chunksCh); err != nil {
+		t.Fatal(err)
+	}
+	close(chunksCh)
+
+	var got []*sources.Chunk
+	for chunk := range chunksCh {
+		got = append(got, chunk)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 chunks, got %d", len(got))
+	}
+
+	if string(got[0].Data) != "password: hunter2\nusername: app\n" {
+		t.Errorf("unexpected secret chunk data: %q", got[0].Data)
+	}
+	wantMetadata := []*source_metadatapb.Vault{
+		{Address: server.URL, Mount: "kv", Path: "prod/db"},
+		{Address: server.URL, Mount: "kv", Path: "kv/data/prod/db", File: auditLog, Line: 1, Timestamp: "2022-06-01T00:00:00Z"},
+	}
+	for i, want := range wantMetadata {
+		if diff := pretty.Compare(got[i].SourceMetadata.GetVault(), want); diff != "" {
+			t.Errorf("chunk %d metadata diff: (-got +want)\n%s", i, diff)
+		}
+	}
+}
+
+func TestSource_InitRequiresEndpointForMounts(t *testing.T) {
+	conn, err := anypb.New(&sourcespb.Vault{Mounts: []string{"kv"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := Source{}
+	if err := s.Init(context.Background(), "test vault", 0, 0, false, conn, 1); err == nil {
+		t.Error("expected an error without an endpoint")
+	}
+}
+
+func TestSource_ChunksTLS(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body interface{}
+		switch r.URL.Path {
+		case "/v1/kv/metadata/":
+			body = map[string]interface{}{"data": map[string]interface{}{"keys": []string{"db"}}}
+		case "/v1/kv/data/db":
+			body = map[string]interface{}{"data": map[string]interface{}{"data": map[string]interface{}{"password": "hunter2"}}}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	defer server.Close()
+
+	// The server's certificate is only trusted through the connection's CA.
+	conn, err := anypb.New(&sourcespb.Vault{
+		Endpoint:   server.URL,
+		Credential: &sourcespb.Vault_Token{Token: "root"},
+		Mounts:     []string{"kv"},
+		Tls: &credentialspb.TLSConfig{
+			CaPem: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := Source{}
+	if err := s.Init(ctx, "test vault", 0, 0, false, conn, 1); err != nil {
+		t.Fatal(err)
+	}
+	chunksCh := make(chan *sources.Chunk, 1)
+	if err := s.Chunks(ctx, chunksCh); err != nil {
+		t.Fatal(err)
+	}
+	close(chunksCh)
+	if chunk := <-chunksCh; chunk == nil || string(chunk.Data) != "password: hunter2\n" {
+		t.Errorf("unexpected chunk: %v", chunk)
+	}
+}
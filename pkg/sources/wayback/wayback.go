@@ -0,0 +1,204 @@
+package wayback
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	// defaultLimit is how many snapshots of each domain are scanned when the
+	// connection doesn't set a limit.
+	defaultLimit = 1000
+	// maxSnapshotSize limits how much of each snapshot is read.
+	maxSnapshotSize = 10 * 1024 * 1024
+	// archivedFilePat matches the URLs of the kinds of files keys are found
+	// in: scripts, configuration and text, with or without a query.
+	archivedFilePat = `.*\.(js|mjs|map|json|txt|env|xml|yml|yaml|ini|conf|config|cfg|properties|toml|bak|sql|log)(\?.*)?$`
+)
+
+// archiveURL is the Wayback Machine, which tests point at a fake one.
+var archiveURL = "https://web.archive.org"
+
+type Source struct {
+	sources.Base
+	conn   *sourcespb.Wayback
+	client *http.Client
+}
+
+// Ensure the Source satisfies the interface at compile time.
+var _ sources.Source = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_WAYBACK
+}
+
+// Init returns an initialized Wayback Machine source.
+func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.InitBase(aCtx, s.Type(), name, jobId, sourceId, verify, concurrency)
+
+	var conn sourcespb.Wayback
+	if err := sources.UnmarshalConnection(connection, &conn); err != nil {
+		return err
+	}
+	if err := validateConnection(&conn); err != nil {
+		return err
+	}
+	s.conn = &conn
+
+	httpOptions, err := common.NewHttpClientOptions(conn.Tls, conn.Proxy)
+	if err != nil {
+		return err
+	}
+	// The archive throttles heavy use, so requests wait out its rate limit.
+	s.client = common.RateLimitedHttpClient(httpOptions...)
+
+	return nil
+}
+
+// snapshot is a capture of a URL by the Wayback Machine.
+type snapshot struct {
+	timestamp string
+	original  string
+}
+
+// validateConnection checks that the connection has domains to scan, named
+// without a scheme or path, since the archive is searched by domain.
+func validateConnection(conn *sourcespb.Wayback) error {
+	var v sources.Validator
+	v.Require("domains", len(conn.Domains) > 0)
+	for i, domain := range conn.Domains {
+		v.Hostname(fmt.Sprintf("domains[%d]", i), domain)
+	}
+	if conn.Limit < 0 {
+		v.Problemf("limit %d is negative, use 0 for the default", conn.Limit)
+	}
+	return v.Err()
+}
+
+// Chunks finds the archived scripts, configuration and text files of each
+// domain, and emits the contents of their snapshots. Keys removed from a site
+// remain in the snapshots taken while they were exposed.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	return s.Enumerate(ctx, "Domain", s.conn.Domains, func(domain string) error {
+		snapshots, err := s.snapshots(ctx, domain)
+		if err != nil {
+			return errors.WrapPrefix(err, fmt.Sprintf("could not list snapshots of %s", domain), 0)
+		}
+		s.Log().V(1).Info("found snapshots", "domain", domain, "count", len(snapshots))
+
+		for _, snap := range snapshots {
+			if err := s.chunkSnapshot(ctx, chunksChan, snap); err != nil {
+				if common.IsDone(ctx) {
+					return err
+				}
+				// Snapshots the archive can't replay are skipped.
+				s.Log().V(2).Info("could not fetch snapshot", "url", snap.original, "timestamp", snap.timestamp, "error", err.Error())
+			}
+		}
+		return nil
+	})
+}
+
+// snapshots queries the CDX API for the snapshots of the domain's files, and
+// its subdomains' files, that were archived successfully. Snapshots with the
+// same contents as an earlier one are left out.
+// https://github.com/internetarchive/wayback/tree/master/wayback-cdx-server
+func (s *Source) snapshots(ctx context.Context, domain string) ([]snapshot, error) {
+	limit := s.conn.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	query := url.Values{
+		"url":       {domain},
+		"matchType": {"domain"},
+		"output":    {"json"},
+		"fl":        {"timestamp,original"},
+		"filter":    {"statuscode:200", "original:" + archivedFilePat},
+		"collapse":  {"digest"},
+		"limit":     {fmt.Sprint(limit)},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, archiveURL+"/cdx/search/cdx?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status from CDX API: %s", resp.Status)
+	}
+
+	// The first row names the fields. An empty body means there are no
+	// snapshots.
+	var rows [][]string
+	if err := json.NewDecoder(resp.Body).Decode(&rows); err != nil && !errors.Is(err, io.EOF) {
+		return nil, errors.WrapPrefix(err, "could not decode CDX response", 0)
+	}
+	var snapshots []snapshot
+	for i, row := range rows {
+		if i == 0 || len(row) < 2 {
+			continue
+		}
+		snapshots = append(snapshots, snapshot{timestamp: row[0], original: row[1]})
+	}
+	return snapshots, nil
+}
+
+// chunkSnapshot emits the contents of a snapshot as it was captured, without
+// the archive's banner and rewritten links.
+func (s *Source) chunkSnapshot(ctx context.Context, chunksChan chan *sources.Chunk, snap snapshot) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawSnapshotURL(snap), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status fetching snapshot: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSnapshotSize))
+	if err != nil {
+		return errors.WrapPrefix(err, "could not read snapshot", 0)
+	}
+
+	return s.Send(ctx, chunksChan, data, &source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_Wayback{
+			Wayback: &source_metadatapb.Wayback{
+				Url:       sanitizer.UTF8(snap.original),
+				Timestamp: snap.timestamp,
+				Link:      sanitizer.UTF8(snapshotLink(snap)),
+			},
+		},
+	})
+}
+
+// snapshotLink returns the public link to a snapshot.
+func snapshotLink(snap snapshot) string {
+	return "https://web.archive.org/web/" + snap.timestamp + "/" + snap.original
+}
+
+// rawSnapshotURL returns the URL of a snapshot's original contents. The id_
+// flag asks the archive not to rewrite them.
+func rawSnapshotURL(snap snapshot) string {
+	return archiveURL + "/web/" + snap.timestamp + "id_/" + snap.original
+}
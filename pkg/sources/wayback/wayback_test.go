@@ -0,0 +1,157 @@
+package wayback
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestSource_Chunks(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cdx/search/cdx":
+			query := r.URL.Query()
+			if query.Get("url") != "example.com" || query.Get("limit") != "5" || query.Get("collapse") != "digest" {
+				t.Errorf("unexpected CDX query: %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`[["timestamp","original"],
+["20200101000000","https://example.com/app.js"],
+["20210101000000","https://cdn.example.com/config.json?v=2"],
+["20220101000000","https://example.com/gone.env"]]`))
+		case "/web/20200101000000id_/https://example.com/app.js":
+			_, _ = w.Write([]byte(`var key = "example";`))
+		case "/web/20210101000000id_/https://cdn.example.com/config.json":
+			if r.URL.RawQuery != "v=2" {
+				t.Errorf("query of snapshot lost: %q", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`{"token": "example"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	defer func(u string) { archiveURL = u }(archiveURL)
+	archiveURL = server.URL
+
+	conn, err := anypb.New(&sourcespb.Wayback{
+		Domains: []string{"example.com"},
+		Limit:   5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := Source{}
+	if err := s.Init(ctx, "test wayback", 0, 0, false, conn, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	chunksChan := make(chan *sources.Chunk, 10)
+	if err := s.Chunks(ctx, chunksChan); err != nil {
+		t.Fatal(err)
+	}
+	close(chunksChan)
+
+	var got []string
+	var gotMetadata []*source_metadatapb.Wayback
+	for chunk := range chunksChan {
+		got = append(got, string(chunk.Data))
+		gotMetadata = append(gotMetadata, chunk.SourceMetadata.GetWayback())
+	}
+	want := []string{`var key = "example";`, `{"token": "example"}`}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("Chunks() data diff: (-got +want)\n%s", diff)
+	}
+	wantMetadata := []*source_metadatapb.Wayback{
+		{
+			Url:       "https://example.com/app.js",
+			Timestamp: "20200101000000",
+			Link:      "https://web.archive.org/web/20200101000000/https://example.com/app.js",
+		},
+		{
+			Url:       "https://cdn.example.com/config.json?v=2",
+			Timestamp: "20210101000000",
+			Link:      "https://web.archive.org/web/20210101000000/https://cdn.example.com/config.json?v=2",
+		},
+	}
+	if diff := pretty.Compare(gotMetadata, wantMetadata); diff != "" {
+		t.Errorf("Chunks() metadata diff: (-got +want)\n%s", diff)
+	}
+}
+
+func TestSource_ChunksNoSnapshots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	defer func(u string) { archiveURL = u }(archiveURL)
+	archiveURL = server.URL
+
+	conn, err := anypb.New(&sourcespb.Wayback{Domains: []string{"example.org"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := Source{}
+	if err := s.Init(context.Background(), "test wayback", 0, 0, false, conn, 1); err != nil {
+		t.Fatal(err)
+	}
+	chunksChan := make(chan *sources.Chunk, 1)
+	if err := s.Chunks(context.Background(), chunksChan); err != nil {
+		t.Fatal(err)
+	}
+	if len(chunksChan) != 0 {
+		t.Errorf("got %d chunks, want none", len(chunksChan))
+	}
+}
+
+func TestSource_ChunksProxy(t *testing.T) {
+	// The archive can only be reached through the proxy.
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Host != "archive.invalid" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		switch r.URL.Path {
+		case "/cdx/search/cdx":
+			_, _ = w.Write([]byte(`[["timestamp","original"],["20200101000000","https://example.com/app.js"]]`))
+		case "/web/20200101000000id_/https://example.com/app.js":
+			_, _ = w.Write([]byte(`var key = "example";`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer proxy.Close()
+	defer func(u string) { archiveURL = u }(archiveURL)
+	archiveURL = "http://archive.invalid"
+
+	conn, err := anypb.New(&sourcespb.Wayback{
+		Domains: []string{"example.com"},
+		Proxy:   &credentialspb.Proxy{Url: proxy.URL},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := Source{}
+	if err := s.Init(context.Background(), "test wayback", 0, 0, false, conn, 1); err != nil {
+		t.Fatal(err)
+	}
+	chunksChan := make(chan *sources.Chunk, 1)
+	if err := s.Chunks(context.Background(), chunksChan); err != nil {
+		t.Fatal(err)
+	}
+	close(chunksChan)
+	if chunk := <-chunksChan; chunk == nil || string(chunk.Data) != `var key = "example";` {
+		t.Errorf("unexpected chunk: %v", chunk)
+	}
+}
@@ -0,0 +1,411 @@
+// Package sqlite reads the rows of tables in SQLite database files. It only
+// supports what's needed to read databases applications leave behind, such as
+// browser profiles: tables are read from their b-trees, without indices, and
+// pages that can't be parsed are skipped.
+// https://www.sqlite.org/fileformat2.html
+package sqlite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+const (
+	fileHeaderSize = 100
+
+	pageInteriorTable = 0x05
+	pageLeafTable     = 0x0d
+
+	// maxDepth limits the depth of table b-trees, which protects against
+	// page cycles in corrupt files.
+	maxDepth = 32
+)
+
+var fileMagic = []byte("SQLite format 3\x00")
+
+var (
+	// ErrNotSQLite is returned by ReadTable for files that aren't SQLite databases.
+	ErrNotSQLite = errors.New("not a SQLite database")
+	// ErrNoTable is returned by ReadTable when the database has no such table.
+	ErrNoTable = errors.New("no such table")
+)
+
+// Row is a row of a table, keyed by column name. Values are nil, int64,
+// float64, string or []byte.
+type Row map[string]interface{}
+
+// String returns the value of a text column, or "" if it's not text.
+func (r Row) String(column string) string {
+	switch v := r[column].(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return ""
+	}
+}
+
+// Int returns the value of an integer column, or 0 if it's not an integer.
+func (r Row) Int(column string) int64 {
+	v, _ := r[column].(int64)
+	return v
+}
+
+// IsSQLite reports whether header, the first bytes of a file, starts a SQLite
+// database.
+func IsSQLite(header []byte) bool {
+	return bytes.HasPrefix(header, fileMagic)
+}
+
+// db is an open database file.
+type db struct {
+	r        io.ReaderAt
+	pageSize int
+	// usable is the size of pages without the bytes reserved at their end.
+	usable int
+}
+
+// ReadTable calls fn with each row of the named table.
+func ReadTable(r io.ReaderAt, table string, fn func(Row) error) error {
+	header := make([]byte, fileHeaderSize)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		if errors.Is(err, io.EOF) {
+			return ErrNotSQLite
+		}
+		return errors.WrapPrefix(err, "could not read SQLite header", 0)
+	}
+	if !IsSQLite(header) {
+		return ErrNotSQLite
+	}
+	d := &db{r: r, pageSize: int(binary.BigEndian.Uint16(header[16:]))}
+	if d.pageSize == 1 {
+		d.pageSize = 65536
+	}
+	if d.pageSize < 512 || d.pageSize&(d.pageSize-1) != 0 {
+		return errors.WrapPrefix(ErrNotSQLite, "invalid page size", 0)
+	}
+	d.usable = d.pageSize - int(header[20])
+
+	// The schema table is rooted at page 1.
+	var rootPage int64
+	var columns []string
+	rowidColumn := -1
+	err := d.readTree(1, 0, func(rowid int64, values []interface{}) error {
+		schema := rowValues([]string{"type", "name", "tbl_name", "rootpage", "sql"}, rowid, values, -1)
+		if schema.String("type") == "table" && strings.EqualFold(schema.String("name"), table) {
+			rootPage = schema.Int("rootpage")
+			columns, rowidColumn = parseColumns(schema.String("sql"))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if rootPage == 0 {
+		return ErrNoTable
+	}
+
+	return d.readTree(uint32(rootPage), 0, func(rowid int64, values []interface{}) error {
+		return fn(rowValues(columns, rowid, values, rowidColumn))
+	})
+}
+
+// rowValues keys values by columns. Rows written before columns were added
+// have fewer values, and the missing ones are nil.
+func rowValues(columns []string, rowid int64, values []interface{}, rowidColumn int) Row {
+	row := make(Row, len(columns))
+	for i, column := range columns {
+		if i < len(values) {
+			row[column] = values[i]
+		} else {
+			row[column] = nil
+		}
+	}
+	if rowidColumn >= 0 {
+		row[columns[rowidColumn]] = rowid
+	}
+	return row
+}
+
+// readPage reads a page by its 1-based number.
+func (d *db) readPage(number uint32) ([]byte, error) {
+	if number == 0 {
+		return nil, errors.New("invalid page number 0")
+	}
+	page := make([]byte, d.pageSize)
+	n, err := d.r.ReadAt(page, int64(number-1)*int64(d.pageSize))
+	if n == d.pageSize {
+		return page, nil
+	}
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil, errors.Errorf("page %d is truncated", number)
+	}
+	return nil, errors.WrapPrefix(err, "could not read SQLite page", 0)
+}
+
+// readTree calls fn with the rowid and values of each record in the table
+// b-tree rooted at page. Pages that can't be parsed are skipped.
+func (d *db) readTree(number uint32, depth int, fn func(int64, []interface{}) error) error {
+	if depth > maxDepth {
+		return nil
+	}
+	page, err := d.readPage(number)
+	if err != nil {
+		return nil
+	}
+	// The first page starts with the file header.
+	headerStart := 0
+	if number == 1 {
+		headerStart = fileHeaderSize
+	}
+	if headerStart+8 > len(page) {
+		return nil
+	}
+	pageType := page[headerStart]
+	cellCount := int(binary.BigEndian.Uint16(page[headerStart+3:]))
+	cellPointers := headerStart + 8
+	if pageType == pageInteriorTable {
+		cellPointers = headerStart + 12
+	}
+
+	for i := 0; i < cellCount; i++ {
+		pointer := cellPointers + 2*i
+		if pointer+2 > len(page) {
+			return nil
+		}
+		offset := int(binary.BigEndian.Uint16(page[pointer:]))
+		if offset >= d.usable {
+			continue
+		}
+		switch pageType {
+		case pageInteriorTable:
+			if offset+4 > len(page) {
+				continue
+			}
+			if err := d.readTree(binary.BigEndian.Uint32(page[offset:]), depth+1, fn); err != nil {
+				return err
+			}
+		case pageLeafTable:
+			rowid, payload, ok := d.leafCell(page[:d.usable], offset)
+			if !ok {
+				continue
+			}
+			values, ok := parseRecord(payload)
+			if !ok {
+				continue
+			}
+			if err := fn(rowid, values); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+	if pageType == pageInteriorTable {
+		return d.readTree(binary.BigEndian.Uint32(page[headerStart+8:]), depth+1, fn)
+	}
+	return nil
+}
+
+// leafCell returns the rowid and payload of a table leaf cell, reading the
+// part of the payload that spills onto overflow pages.
+func (d *db) leafCell(page []byte, offset int) (int64, []byte, bool) {
+	size, n := varint(page[offset:])
+	if n == 0 {
+		return 0, nil, false
+	}
+	offset += n
+	rowid, n := varint(page[offset:])
+	if n == 0 {
+		return 0, nil, false
+	}
+	offset += n
+	if size > math.MaxInt32 {
+		return 0, nil, false
+	}
+	payloadSize := int(size)
+
+	local := d.localPayload(payloadSize)
+	if offset+local > len(page) {
+		return 0, nil, false
+	}
+	payload := make([]byte, 0, payloadSize)
+	payload = append(payload, page[offset:offset+local]...)
+	if local == payloadSize {
+		return int64(rowid), payload, true
+	}
+
+	if offset+local+4 > len(page) {
+		return 0, nil, false
+	}
+	next := binary.BigEndian.Uint32(page[offset+local:])
+	for pages := 0; len(payload) < payloadSize; pages++ {
+		// Payloads can't span more pages than the file has.
+		if next == 0 || pages > payloadSize/(d.usable-4)+1 {
+			return 0, nil, false
+		}
+		overflow, err := d.readPage(next)
+		if err != nil {
+			return 0, nil, false
+		}
+		chunk := overflow[4:d.usable]
+		if remaining := payloadSize - len(payload); len(chunk) > remaining {
+			chunk = chunk[:remaining]
+		}
+		payload = append(payload, chunk...)
+		next = binary.BigEndian.Uint32(overflow)
+	}
+	return int64(rowid), payload, true
+}
+
+// localPayload returns how much of a table leaf cell's payload is stored on
+// its page.
+func (d *db) localPayload(size int) int {
+	maxLocal := d.usable - 35
+	if size <= maxLocal {
+		return size
+	}
+	minLocal := (d.usable-12)*32/255 - 23
+	local := minLocal + (size-minLocal)%(d.usable-4)
+	if local > maxLocal {
+		return minLocal
+	}
+	return local
+}
+
+// parseRecord returns the values of a record.
+func parseRecord(payload []byte) ([]interface{}, bool) {
+	headerSize, n := varint(payload)
+	if n == 0 || headerSize < uint64(n) || headerSize > uint64(len(payload)) {
+		return nil, false
+	}
+	header := payload[n:headerSize]
+	body := payload[headerSize:]
+
+	var values []interface{}
+	for len(header) > 0 {
+		serialType, n := varint(header)
+		if n == 0 {
+			return nil, false
+		}
+		header = header[n:]
+
+		var value interface{}
+		var size int
+		switch {
+		case serialType == 0:
+		case serialType >= 1 && serialType <= 6:
+			size = []int{1, 2, 3, 4, 6, 8}[serialType-1]
+			if len(body) < size {
+				return nil, false
+			}
+			value = bigEndianInt(body[:size])
+		case serialType == 7:
+			size = 8
+			if len(body) < size {
+				return nil, false
+			}
+			value = math.Float64frombits(binary.BigEndian.Uint64(body))
+		case serialType == 8:
+			value = int64(0)
+		case serialType == 9:
+			value = int64(1)
+		case serialType >= 12:
+			if (serialType-12)/2 > uint64(len(body)) {
+				return nil, false
+			}
+			size = int((serialType - 12) / 2)
+			if serialType%2 == 0 {
+				value = append([]byte(nil), body[:size]...)
+			} else {
+				value = string(body[:size])
+			}
+		default:
+			return nil, false
+		}
+		body = body[size:]
+		values = append(values, value)
+	}
+	return values, true
+}
+
+// bigEndianInt decodes a big-endian two's complement integer.
+func bigEndianInt(b []byte) int64 {
+	v := int64(int8(b[0]))
+	for _, c := range b[1:] {
+		v = v<<8 | int64(c)
+	}
+	return v
+}
+
+// varint decodes a SQLite varint, which is big-endian and at most 9 bytes,
+// the last of which contributes all 8 of its bits. It returns the number of
+// bytes read, which is 0 if b is too short.
+func varint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 9 && i < len(b); i++ {
+		if i == 8 {
+			return v<<8 | uint64(b[i]), 9
+		}
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	return 0, 0
+}
+
+// parseColumns returns the column names of a CREATE TABLE statement, and the
+// index of the column declared INTEGER PRIMARY KEY, if there is one. That
+// column is an alias of the rowid, and is stored as NULL.
+func parseColumns(sql string) ([]string, int) {
+	start, end := strings.Index(sql, "("), strings.LastIndex(sql, ")")
+	if start < 0 || end <= start {
+		return nil, -1
+	}
+
+	// Split the definitions on commas that aren't in parentheses, as in
+	// DECIMAL(10,2) or UNIQUE (a, b).
+	var defs []string
+	depth, last := 0, start+1
+	for i := start + 1; i < end; i++ {
+		switch sql[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				defs = append(defs, sql[last:i])
+				last = i + 1
+			}
+		}
+	}
+	defs = append(defs, sql[last:end])
+
+	var columns []string
+	rowidColumn := -1
+	for _, def := range defs {
+		fields := strings.Fields(def)
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN":
+			// Table constraints.
+			continue
+		}
+		upper := strings.ToUpper(strings.Join(fields[1:], " "))
+		if strings.HasPrefix(upper, "INTEGER") && strings.Contains(upper, "PRIMARY KEY") {
+			rowidColumn = len(columns)
+		}
+		columns = append(columns, strings.Trim(fields[0], "\"`[]'"))
+	}
+	return columns, rowidColumn
+}
@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+// itemsDB is a gzipped database with 512 byte pages, created with:
+//
+//	CREATE TABLE "items" (id INTEGER PRIMARY KEY, name TEXT NOT NULL, price DECIMAL(10,2), data BLOB, UNIQUE (name, price));
+//	-- for i in 1..100
+//	INSERT INTO items (name, price, data) VALUES ('item-<i>', <i*1.5 if i is odd, else i>, <3 bytes of i>);
+//	ALTER TABLE items ADD COLUMN note TEXT;
+//	INSERT INTO items (name, price, data, note) VALUES ('long', -70000, NULL, <2000 x's>);
+const itemsDB = "" +
+	"H4sIANhF0WoC/+2ZW2wc1R3Gz/ElG9txnONL9pjgeGJj4ss6mcvu7AyO7eO1F8fx+rZe23Ec" +
+	"Z72+gExzoWCkIBAtPCOgfepLuUhV+9CXqq1S9a1qRaVKrVS1L63ggadStRWCPiAkVNT/zHF1" +
+	"PlABlfBQEn/yrj0zvzlz5pzv/82ZZHGhsLu3Yz1047FrlT3LY1WMc6YsizH6k7FmZhRt18A2" +
+	"Z5+vKnZmizfyD1gVdxh3+O/YV13PtvNEMpnkz4m9yubVHRq8a4/HX1XjxfxYKW+VxnKFvNUV" +
+	"7+uyene3ranZUn4yX7Tmi1MzY8VVazq/mrKuV67tWKX8xZI1O0efpUIhZT362O7WjjWRHyeu" +
+	"0OvYKbcvZW1X9ipWrjCXo3Nu7OlzUtbS7NTCUt7qjZrZP7Gvr6/qUHI4ydnu9e2dm49//Sr1" +
+	"oVx5Yu9GvF2Oe1R24l/VdCeHo9upjT787ejPZv4hf5+/x/+hNw90Z+nINn3Vz0cTfy6a9N6o" +
+	"nFvjouavREAj/wv/Lf+x3jjQ/4sajtZ0cB5V7aAd+MvLtKuuraYjsb/Lz6pVKwbH6ZOAI+mM" +
+	"mn8rPjJIn0NwxPXUmIqPJBvpq5WNMJr7P/Kf8+/z5/mTvMwL3OWdvJb9k/2Jvc5+yL7DnmFf" +
+	"YwsEfkyitZZ18Ob9Vu1WkmyhXYn9XU6ozn0zJltaWkQzwE7QTJIC4awKNSxIxxD2j5FkE8IZ" +
+	"5Wu4qalJHEU4fZQkGxH2lKfhxsZGcQRh9whJNiDsKFvDDQ0Noh5hu54k6wC2QzWgh6Kurk4c" +
+	"BtgODpNkAuGs6tFwIpEQhxD2D5FkLcIZdVLDtbW1ogbhdA1JViPsqf0ndXV1tahC2K0iSY6w" +
+	"M/rB/hOc89uc/164VNruJcnTcCkvVDPaaKdPnxb3A+wF95NkD8JZNf2LGO7p6RH3IezfR5Ld" +
+	"CGfUBd1yd3e36EI43UWSpxD21Hnd8qlTp4SFsGuRZCfCjprULXd2doqTCNsnSbIDYDdUed1y" +
+	"R0eHuBerIbiXJE8gnFUTuuUTJ06IexD27yHJdoQzKqdbbm9vFxLhtIyURNgUdDIpjiPsHifJ" +
+	"NoQdNaoN3tbWVk+/jrNHGP87f5P/mv+Mv8a/zZ/m1/gKP08e6CaDfMT+yv7MfsV+yl5mL7In" +
+	"CS6x/CdTqudzcwxiLOoUxpHpEEIUJwBBlAAUxQhCJkIQovhAyEQHQhQbCJnIQIjiAiETFQhR" +
+	"TAAEEQFQFA8ImWhAiGIBIRMJCFEcIGSiACGKgVaC6j4ZAfX8VdbGJhjv4n/jLexf7B2q+N+w" +
+	"n7DvsRfYN9guu8Qm+Lf4U/wqX+aTtIJ/g7/Ob9FJILg61DBcPapfhEztIkR1i5CpWYSoXhEy" +
+	"tYoQ1Snay9Qo2ovqEyFTmwhRXeImVR4+YD01rx+9AwCl3f5+hBw1q6/ehxBlJd6HyUm8D8pI" +
+	"2jX0BRcOeLl0KoU3a7LlNvP/HESNb58jySGImkyolvUIDQ0NiQcAzgQPkGSIcFYtbcRwGIYi" +
+	"QNgPSDKLcEaVtAmz2azwEU77JJlB2FOLeiWUyWREGmE3TZIewo5a1H32PE+4CFNwua50AE6H" +
+	"qqj77DiOsPFpGNgkeRbhrFrQfT579qw4g7B/hiQHETart8FBkUKYJjOVkgMIGycODIh+hMmN" +
+	"/f2yD2HjyL6+en5L139ACfAef5Vq/QilwNs077+kFPgupcBNSoFFSoH/ZECcAJ9iNN8dGcHF" +
+	"qaOW9U0MI0RGAQhMAlBkEISMORAiYyBkTIEQGQIhYwaEyAgIGRNgLZsJxOKiycO783M5HIKM" +
+	"uqg7PoZQWimEPLWiGx7FPpHf8OrGa19SKpBFb7P+58FqgT1PknNgtWyo1vV9zc3NiVmAs8Es" +
+	"Sc4gnFWX9cTMzMyIAsJ+gSSnEc6oy3qCpqenxQWE0xdIcgphT63pkZuamhLnEXbPk+Qkwo66" +
+	"pPs8OTkpHkTYfpAk8wD7oVrVfc7n82IC0zCYIMlxhM2r2vi4yCFMnsnl5BjCxjdjY0IhTN5R" +
+	"So4ibPwzOipGEKaCHBmRwwibohwerueTrJkKnGr/DXr7v8V/QAkQZcF/WQvEx56jFFink/7X" +
+	"5R8YOciosu7AEr7bpkslhDxV1oO1iJBbLCLkqCt6kBYQIiMCBCYEKDIgQsZ8CJHxEDKmQ4gM" +
+	"R7sufDmv+3g5Y1u8HFkWIWNXhMiqmC/GphhCZNHbrP9tYzXHtrdJcgusFoZq86WY3NraEpvg" +
+	"yzDYJMkKwlm1acdwpVIRGwj7GyRZRjijKr+P4XK5LK4gnL5CkusIe6ryaAyvr6+Lywi7l0ly" +
+	"DWFHVY7F8NramriEsH2JJFcBDkK18aMYXl1dFRcxDYOLJLmCcFZtzMfwysqKWEbYXybJJYRN" +
+	"kSwtiRLCVCilklxE2BTL4qIoIkwFUyzKBYRN0SwsRO9/TexdRqv//ZX9x1b7XfFq4N076F9B" +
+	"a9qq+dUb1x/+6J2XdkwtxOaFggHjQsFEpkXIGBYhMitCxqgIkUkRMgZFiMyJkDEmQmRKTERj" +
+	"SExEMiNCxojR9N880F0rmv9jB6NwV8+/OBiFu3r+2cEo3BHT+IXUGP830B8Y/RzojtCLV3YS" +
+	"rK2avTK4v8z9LNMc/TfGz8VxACQAAA=="
+
+func openItemsDB(t *testing.T) *bytes.Reader {
+	t.Helper()
+	zr, err := gzip.NewReader(base64.NewDecoder(base64.StdEncoding, strings.NewReader(itemsDB)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(data)
+}
+
+func TestReadTable(t *testing.T) {
+	var rows []Row
+	err := ReadTable(openItemsDB(t), "items", func(row Row) error {
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	if len(rows) != 101 {
+		t.Fatalf("ReadTable() read %d rows, want 101", len(rows))
+	}
+
+	want := []Row{
+		{"id": int64(1), "name": "item-001", "price": 1.5, "data": []byte{1, 1, 1}, "note": nil},
+		{"id": int64(2), "name": "item-002", "price": int64(2), "data": []byte{2, 2, 2}, "note": nil},
+		{"id": int64(101), "name": "long", "price": int64(-70000), "data": nil, "note": strings.Repeat("x", 2000)},
+	}
+	got := []Row{rows[0], rows[1], rows[100]}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("ReadTable() rows diff: (-got +want)\n%s", diff)
+	}
+}
+
+func TestReadTableErrors(t *testing.T) {
+	noop := func(Row) error { return nil }
+	if err := ReadTable(openItemsDB(t), "logins", noop); !errors.Is(err, ErrNoTable) {
+		t.Errorf("ReadTable() of a missing table error = %v, want ErrNoTable", err)
+	}
+	if err := ReadTable(strings.NewReader("not a database"), "items", noop); !errors.Is(err, ErrNotSQLite) {
+		t.Errorf("ReadTable() of a text file error = %v, want ErrNotSQLite", err)
+	}
+
+	stop := errors.New("stop")
+	if err := ReadTable(openItemsDB(t), "items", func(Row) error { return stop }); !errors.Is(err, stop) {
+		t.Errorf("ReadTable() error = %v, want the callback's error", err)
+	}
+}
@@ -0,0 +1,141 @@
+// Package sshclient connects to SSH servers for the sources that read files
+// over SSH, logging in with a password or private key and checking the
+// server's host key against known hosts.
+package sshclient
+
+import (
+	"crypto/ed25519"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-errors/errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	// DialTimeout is how long connecting to a server may take.
+	DialTimeout = 15 * time.Second
+	// handshakeTimeout is how long a server may take to log in to.
+	handshakeTimeout = time.Minute
+)
+
+// Config is how to log in to a server.
+type Config struct {
+	Username string
+	Password string
+	// PrivateKey is a PEM encoded private key, which Passphrase decrypts if
+	// it's encrypted.
+	PrivateKey string
+	Passphrase string
+	// KnownHosts is the known_hosts file that the server's host key must be
+	// in. It defaults to ~/.ssh/known_hosts.
+	KnownHosts         string
+	InsecureSkipVerify bool
+}
+
+// Dial connects to the server at addr, a host and port, and logs in.
+func Dial(addr string, c Config) (*ssh.Client, error) {
+	hostKeyCallback, hostKeyAlgorithms, err := hostKeys(c, addr)
+	if err != nil {
+		return nil, err
+	}
+	config := &ssh.ClientConfig{
+		User:              c.Username,
+		HostKeyCallback:   hostKeyCallback,
+		HostKeyAlgorithms: hostKeyAlgorithms,
+	}
+	if c.PrivateKey != "" {
+		signer, err := ParsePrivateKey(c.PrivateKey, c.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		config.Auth = append(config.Auth, ssh.PublicKeys(signer))
+	}
+	if c.Password != "" {
+		password := c.Password
+		config.Auth = append(config.Auth,
+			ssh.Password(password),
+			ssh.KeyboardInteractive(func(_, _ string, questions []string, _ []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range answers {
+					answers[i] = password
+				}
+				return answers, nil
+			}),
+		)
+	}
+
+	// The connection is read from while it's idle, so it only has a deadline
+	// until it's set up.
+	conn, err := net.DialTimeout("tcp", addr, DialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
+
+// ParsePrivateKey parses a PEM encoded private key, decrypting it with
+// passphrase if it's encrypted.
+func ParsePrivateKey(key, passphrase string) (ssh.Signer, error) {
+	signer, err := ssh.ParsePrivateKey([]byte(key))
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) && passphrase != "" {
+		return ssh.ParsePrivateKeyWithPassphrase([]byte(key), []byte(passphrase))
+	}
+	return signer, err
+}
+
+// hostKeys returns the callback that checks the server's host key, and the
+// algorithms of the keys known for it, so that it's asked for one of those.
+func hostKeys(c Config, addr string) (ssh.HostKeyCallback, []string, error) {
+	if c.InsecureSkipVerify {
+		return ssh.InsecureIgnoreHostKey(), nil, nil
+	}
+	file := c.KnownHosts
+	if file == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil, errors.WrapPrefix(err, "could not find known hosts, set knownHosts", 0)
+		}
+		file = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	callback, err := knownhosts.New(file)
+	if err != nil {
+		return nil, nil, errors.WrapPrefix(err, "could not read known hosts, set knownHosts or insecureSkipVerify", 0)
+	}
+
+	// Checking a key that can't be known gives the keys that are.
+	var algorithms []string
+	var keyErr *knownhosts.KeyError
+	probe, _ := ssh.NewPublicKey(ed25519.PublicKey(make([]byte, ed25519.PublicKeySize)))
+	if err := callback(knownhosts.Normalize(addr), &net.TCPAddr{}, probe); errors.As(err, &keyErr) {
+		for _, known := range keyErr.Want {
+			if known.Key.Type() == ssh.KeyAlgoRSA {
+				algorithms = append(algorithms, ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256)
+			}
+			algorithms = append(algorithms, known.Key.Type())
+		}
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := callback(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) {
+			if len(keyErr.Want) == 0 {
+				return errors.Errorf("%s is not a known host, add its key to %s or set insecureSkipVerify", hostname, file)
+			}
+			return errors.Errorf("the host key of %s doesn't match the one in %s", hostname, file)
+		}
+		return err
+	}, algorithms, nil
+}
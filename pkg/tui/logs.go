@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"strings"
+	"sync"
+)
+
+// maxLogLines is the number of log lines a LogBuffer keeps.
+const maxLogLines = 100
+
+// LogBuffer keeps the most recent lines written to it, so logs can be shown
+// in the UI instead of being written over it.
+type LogBuffer struct {
+	mu      sync.Mutex
+	lines   []string
+	partial string
+}
+
+func (b *LogBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	text := b.partial + string(p)
+	lines := strings.Split(text, "\n")
+	b.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		if line = strings.TrimSpace(line); line != "" {
+			b.lines = append(b.lines, line)
+		}
+	}
+	if len(b.lines) > maxLogLines {
+		b.lines = append([]string{}, b.lines[len(b.lines)-maxLogLines:]...)
+	}
+	return len(p), nil
+}
+
+// Lines returns the lines kept.
+func (b *LogBuffer) Lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string{}, b.lines...)
+}
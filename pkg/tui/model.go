@@ -0,0 +1,291 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/baseline"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine"
+)
+
+const (
+	// detailLines is the height of the pane describing the selected finding.
+	detailLines = 6
+	// logLines is the number of recent log lines shown.
+	logLines = 3
+)
+
+// finding is a result in the list, with the status it's been given.
+type finding struct {
+	result   detectors.ResultWithMetadata
+	status   baseline.Status
+	location string
+	metadata []string
+}
+
+func newFinding(r detectors.ResultWithMetadata, status baseline.Status) *finding {
+	f := &finding{result: r, status: status}
+	fields := map[string]map[string]interface{}{}
+	if r.SourceMetadata != nil {
+		if data, err := json.Marshal(r.SourceMetadata.Data); err == nil {
+			_ = json.Unmarshal(data, &fields)
+		}
+	}
+	values := map[string]string{}
+	for _, source := range fields {
+		for k, v := range source {
+			values[k] = fmt.Sprint(v)
+			f.metadata = append(f.metadata, fmt.Sprintf("%s: %v", k, v))
+		}
+	}
+	f.location = values["file"]
+	if f.location == "" {
+		f.location = values["link"]
+	}
+	sort.Strings(f.metadata)
+	return f
+}
+
+// model is the state of the UI, updated by keypresses and new results.
+type model struct {
+	findings []*finding
+	// cursor is the index of the selected finding and top the index of the
+	// first one on screen.
+	cursor, top int
+
+	sources  []engine.SourceProgress
+	logs     []string
+	scanDone bool
+	message  string
+}
+
+// action is what a keypress asks the UI to do.
+type action int
+
+const (
+	actionNone action = iota
+	actionQuit
+	actionMark
+)
+
+// Keys that are sent as control characters or escape sequences.
+const (
+	keyUp    = "\x1b[A"
+	keyDown  = "\x1b[B"
+	keyCtrlC = "\x03"
+)
+
+// update applies a keypress. Marking a finding returns the status to mark it
+// with, which is applied by the caller once it's written to the baseline.
+func (m *model) update(key string) (action, baseline.Status) {
+	switch key {
+	case "q", keyCtrlC:
+		return actionQuit, ""
+	case "j", keyDown:
+		m.move(1)
+	case "k", keyUp:
+		m.move(-1)
+	case "g":
+		m.move(-len(m.findings))
+	case "G":
+		m.move(len(m.findings))
+	case "i":
+		return m.mark(baseline.StatusIgnored)
+	case "t":
+		return m.mark(baseline.StatusTriaged)
+	case "o":
+		return m.mark(baseline.StatusOpen)
+	}
+	return actionNone, ""
+}
+
+func (m *model) move(by int) {
+	m.cursor += by
+	if m.cursor >= len(m.findings) {
+		m.cursor = len(m.findings) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+func (m *model) mark(status baseline.Status) (action, baseline.Status) {
+	f := m.selected()
+	if f == nil || f.status == status {
+		return actionNone, ""
+	}
+	return actionMark, status
+}
+
+func (m *model) selected() *finding {
+	if m.cursor < len(m.findings) {
+		return m.findings[m.cursor]
+	}
+	return nil
+}
+
+// view renders the model to fit a terminal of the given size.
+func (m *model) view(width, height int) []string {
+	var lines []string
+	add := func(format string, args ...interface{}) {
+		line := fmt.Sprintf(format, args...)
+		if len([]rune(line)) > width {
+			line = string([]rune(line)[:width])
+		}
+		lines = append(lines, line)
+	}
+
+	state := "scanning"
+	if m.scanDone {
+		state = "scan finished"
+	}
+	verified, reviewed := 0, 0
+	for _, f := range m.findings {
+		if f.result.Verified {
+			verified++
+		}
+		if f.status != baseline.StatusOpen {
+			reviewed++
+		}
+	}
+	add("TruffleHog | %s | %d findings, %d verified, %d reviewed", state, len(m.findings), verified, reviewed)
+	for _, s := range m.sources {
+		add("  %s", sourceLine(s))
+	}
+	add("")
+
+	// The findings list gets whatever room the other sections leave.
+	rows := height - len(lines) - detailLines - logLines - 2
+	if rows < 1 {
+		rows = 1
+	}
+	if m.cursor < m.top {
+		m.top = m.cursor
+	}
+	if m.cursor >= m.top+rows {
+		m.top = m.cursor - rows + 1
+	}
+	for i := m.top; i < len(m.findings) && i < m.top+rows; i++ {
+		cursor := " "
+		if i == m.cursor {
+			cursor = ">"
+		}
+		add("%s %s", cursor, findingLine(m.findings[i]))
+	}
+	for i := len(m.findings) - m.top; i < rows; i++ {
+		add("")
+	}
+
+	add(strings.Repeat("-", width))
+	detail := m.detail()
+	for i := 0; i < detailLines; i++ {
+		if i < len(detail) {
+			add("%s", detail[i])
+		} else {
+			add("")
+		}
+	}
+
+	for i := len(m.logs) - logLines; i < len(m.logs); i++ {
+		if i >= 0 {
+			add("%s", m.logs[i])
+		} else {
+			add("")
+		}
+	}
+	footer := "j/k move  i ignore  t triage  o reopen  q quit"
+	if m.message != "" {
+		footer = m.message + " | " + footer
+	}
+	add("%s", footer)
+	return lines
+}
+
+func sourceLine(s engine.SourceProgress) string {
+	line := fmt.Sprintf("%-12s %8d chunks", s.Name, s.Chunks)
+	if s.BytesDownloaded > 0 {
+		line += fmt.Sprintf("  %s, %s/s", formatBytes(float64(s.BytesDownloaded)), formatBytes(s.BytesPerSecond))
+	}
+	var skipped int64
+	for _, n := range s.Skipped {
+		skipped += n
+	}
+	if skipped > 0 {
+		line += fmt.Sprintf(", %d skipped", skipped)
+	}
+	switch {
+	case s.Err != nil:
+		line += "  failed: " + s.Err.Error()
+	case s.Done:
+		line += "  done"
+	case s.Message != "":
+		line += fmt.Sprintf("  %3d%%  %s", s.PercentComplete, s.Message)
+	}
+	return line
+}
+
+// formatBytes formats n bytes in the largest unit, in powers of 1024, that
+// leaves at least 1 of it, such as "1.5 MiB".
+func formatBytes(n float64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	i := 0
+	for ; n >= 1024 && i < len(units)-1; i++ {
+		n /= 1024
+	}
+	if i == 0 {
+		return fmt.Sprintf("%.0f B", n)
+	}
+	return fmt.Sprintf("%.1f %s", n, units[i])
+}
+
+func findingLine(f *finding) string {
+	verified := "unverified"
+	if f.result.Verified {
+		verified = "verified"
+	}
+	status := ""
+	if f.status != baseline.StatusOpen {
+		status = " [" + string(f.status) + "]"
+	}
+	return fmt.Sprintf("%-10s %-16s %-24s %s%s", verified, f.result.DetectorType.String(), truncate(secret(f.result), 24), f.location, status)
+}
+
+// detail describes the selected finding.
+func (m *model) detail() []string {
+	f := m.selected()
+	if f == nil {
+		return []string{"No findings yet."}
+	}
+	lines := []string{
+		fmt.Sprintf("Detector: %s   Source: %s", f.result.DetectorType.String(), f.result.SourceName),
+		"Raw: " + strings.TrimSpace(string(f.result.Raw)),
+	}
+	lines = append(lines, f.metadata...)
+	var extra []string
+	for k, v := range f.result.ExtraData {
+		extra = append(extra, k+"="+v)
+	}
+	if len(extra) > 0 {
+		sort.Strings(extra)
+		lines = append(lines, "Extra: "+strings.Join(extra, " "))
+	}
+	return lines
+}
+
+// secret is how a finding's secret is shown in the list.
+func secret(r detectors.ResultWithMetadata) string {
+	if r.Redacted != "" {
+		return r.Redacted
+	}
+	return strings.TrimSpace(string(r.Raw))
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
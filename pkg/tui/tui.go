@@ -0,0 +1,215 @@
+// Package tui is an interactive terminal UI that shows a scan's progress and
+// findings as they arrive, and lets findings be triaged into a baseline file.
+package tui
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-errors/errors"
+	"golang.org/x/term"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/baseline"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/findings"
+)
+
+// refreshInterval is how often progress is redrawn while nothing else happens.
+const refreshInterval = 250 * time.Millisecond
+
+// Escape sequences used to draw.
+const (
+	enterAltScreen = "\x1b[?1049h\x1b[?25l"
+	exitAltScreen  = "\x1b[?25h\x1b[?1049l"
+	clearScreen    = "\x1b[H\x1b[2J"
+)
+
+// Options configure a UI.
+type Options struct {
+	// In and Out are the terminal. In must be a terminal so keys can be read
+	// as they're pressed.
+	In  *os.File
+	Out io.Writer
+	// Baseline is where triaged findings are written.
+	Baseline *baseline.Baseline
+	// Progress returns the progress of the scan's sources.
+	Progress func() []engine.SourceProgress
+	// Logs, if set, are shown below the findings.
+	Logs *LogBuffer
+}
+
+// UI shows a scan in the terminal until the user quits.
+type UI struct {
+	opts Options
+
+	mu    sync.Mutex
+	model model
+
+	redraw chan struct{}
+}
+
+// New returns a UI. It doesn't take over the terminal until Run is called.
+func New(opts Options) (*UI, error) {
+	if opts.In == nil || !term.IsTerminal(int(opts.In.Fd())) {
+		return nil, errors.New("the terminal UI needs an interactive terminal")
+	}
+	if opts.Baseline == nil {
+		return nil, errors.New("the terminal UI needs a baseline file")
+	}
+	return &UI{opts: opts, redraw: make(chan struct{}, 1)}, nil
+}
+
+// Add adds a result to the list of findings. Results already in the baseline
+// are listed with their status.
+func (u *UI) Add(r detectors.ResultWithMetadata) {
+	status := u.opts.Baseline.Status(findings.ID(&r))
+	u.mu.Lock()
+	u.model.findings = append(u.model.findings, newFinding(r, status))
+	u.mu.Unlock()
+	u.requestRedraw()
+}
+
+// ScanDone tells the UI the scan has finished. The UI keeps running so the
+// remaining findings can be triaged.
+func (u *UI) ScanDone() {
+	u.mu.Lock()
+	u.model.scanDone = true
+	u.mu.Unlock()
+	u.requestRedraw()
+}
+
+func (u *UI) requestRedraw() {
+	select {
+	case u.redraw <- struct{}{}:
+	default:
+	}
+}
+
+// Run takes over the terminal and handles keypresses until the user quits or
+// ctx is cancelled, then restores the terminal.
+func (u *UI) Run(ctx context.Context) error {
+	fd := int(u.opts.In.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return errors.WrapPrefix(err, "could not set up the terminal", 0)
+	}
+	defer func() {
+		_, _ = io.WriteString(u.opts.Out, exitAltScreen)
+		_ = term.Restore(fd, state)
+	}()
+	if _, err := io.WriteString(u.opts.Out, enterAltScreen); err != nil {
+		return err
+	}
+
+	keys := make(chan string)
+	done := make(chan struct{})
+	defer close(done)
+	go readKeys(u.opts.In, keys, done)
+
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		if err := u.draw(fd); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-u.redraw:
+		case key, ok := <-keys:
+			if !ok || u.handleKey(key) {
+				return nil
+			}
+		}
+	}
+}
+
+// handleKey applies a keypress and reports whether the user quit.
+func (u *UI) handleKey(key string) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.model.message = ""
+	act, status := u.model.update(key)
+	switch act {
+	case actionQuit:
+		return true
+	case actionMark:
+		f := u.model.selected()
+		if err := u.opts.Baseline.Mark(&f.result, status); err != nil {
+			u.model.message = "could not update baseline: " + err.Error()
+			return false
+		}
+		f.status = status
+		u.model.message = "marked " + string(status)
+	}
+	return false
+}
+
+func (u *UI) draw(fd int) error {
+	width, height, err := term.GetSize(fd)
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = 80, 24
+	}
+
+	var progress []engine.SourceProgress
+	if u.opts.Progress != nil {
+		progress = u.opts.Progress()
+	}
+	var logs []string
+	if u.opts.Logs != nil {
+		logs = u.opts.Logs.Lines()
+	}
+
+	u.mu.Lock()
+	u.model.sources = progress
+	u.model.logs = logs
+	lines := u.model.view(width, height)
+	u.mu.Unlock()
+
+	// The terminal is in raw mode, so lines need a carriage return too.
+	_, err = io.WriteString(u.opts.Out, clearScreen+strings.Join(lines, "\r\n"))
+	return err
+}
+
+// readKeys sends each keypress read from in to keys until done is closed,
+// closing keys when in can't be read. Escape sequences, such as the arrow
+// keys, are sent whole.
+func readKeys(in io.Reader, keys chan<- string, done <-chan struct{}) {
+	defer close(keys)
+	buf := make([]byte, 16)
+	for {
+		n, err := in.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, key := range splitKeys(string(buf[:n])) {
+			select {
+			case keys <- key:
+			case <-done:
+				return
+			}
+		}
+	}
+}
+
+// splitKeys splits input read at once, such as a pasted string or a key held
+// down, into keys.
+func splitKeys(input string) []string {
+	var keys []string
+	for len(input) > 0 {
+		if strings.HasPrefix(input, "\x1b[") && len(input) >= 3 {
+			keys = append(keys, input[:3])
+			input = input[3:]
+			continue
+		}
+		keys = append(keys, input[:1])
+		input = input[1:]
+	}
+	return keys
+}
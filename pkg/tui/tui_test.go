@@ -0,0 +1,128 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/baseline"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+func fsResult(file, raw string, verified bool) detectors.ResultWithMetadata {
+	return detectors.ResultWithMetadata{
+		SourceName: "fs",
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Filesystem{
+				Filesystem: &source_metadatapb.Filesystem{File: file},
+			},
+		},
+		Result: detectors.Result{DetectorType: detectorspb.DetectorType_AWS, Raw: []byte(raw), Verified: verified},
+	}
+}
+
+func TestUI_Triage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.jsonl")
+	b, err := baseline.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &UI{opts: Options{Baseline: b}, redraw: make(chan struct{}, 1)}
+	u.Add(fsResult("a.env", "AKIAFIRST", true))
+	u.Add(fsResult("b.env", "AKIASECOND", false))
+	u.Add(fsResult("c.env", "AKIATHIRD", false))
+
+	for _, key := range []string{"j", "i", keyDown, "t", "k", "G", "o", "q"} {
+		if u.handleKey(key) != (key == "q") {
+			t.Fatalf("handleKey(%q) quit unexpectedly", key)
+		}
+	}
+
+	var got []baseline.Status
+	for _, f := range u.model.findings {
+		got = append(got, f.status)
+	}
+	want := []baseline.Status{baseline.StatusOpen, baseline.StatusIgnored, baseline.StatusOpen}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("statuses diff: (-got +want)\n%s", diff)
+	}
+
+	// The statuses are read back from the baseline when the results are
+	// found again.
+	b, err = baseline.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u = &UI{opts: Options{Baseline: b}, redraw: make(chan struct{}, 1)}
+	u.Add(fsResult("b.env", "AKIASECOND", false))
+	if status := u.model.findings[0].status; status != baseline.StatusIgnored {
+		t.Errorf("status after reload = %q, want %q", status, baseline.StatusIgnored)
+	}
+}
+
+func TestModel_View(t *testing.T) {
+	m := &model{
+		sources: []engine.SourceProgress{
+			{Name: "filesystem", Chunks: 12, Done: true},
+			{Name: "s3", Chunks: 3, PercentComplete: 50, Message: "Bucket: logs", BytesDownloaded: 3 << 20, BytesPerSecond: 1536 * 1024, Skipped: map[string]int64{"storage_class": 4, "too_large": 1}},
+		},
+		logs: []string{"first", "second", "third", "fourth"},
+	}
+	for i, file := range []string{"a.env", "b.env", "c.env", "d.env"} {
+		m.findings = append(m.findings, newFinding(fsResult(file, "AKIAEXAMPLE", i == 0), baseline.StatusOpen))
+	}
+	m.findings[2].status = baseline.StatusTriaged
+	m.cursor = 3
+
+	height := 18
+	lines := m.view(80, height)
+	if len(lines) != height {
+		t.Fatalf("view() has %d lines, want %d", len(lines), height)
+	}
+	view := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"TruffleHog | scanning | 4 findings, 1 verified, 1 reviewed",
+		"filesystem         12 chunks  done",
+		"s3                  3 chunks  3.0 MiB, 1.5 MiB/s, 5 skipped   50%  Bucket:",
+		"AKIAEXAMPLE              c.env [triaged]",
+		"> unverified AWS              AKIAEXAMPLE              d.env",
+		"file: d.env",
+		"fourth",
+	} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view() doesn't contain %q:\n%s", want, view)
+		}
+	}
+	// The list scrolls to keep the selected finding on screen.
+	if strings.Contains(view, "a.env") {
+		t.Errorf("view() should have scrolled past the first finding:\n%s", view)
+	}
+	for _, line := range lines {
+		if len([]rune(line)) > 80 {
+			t.Errorf("line is wider than the terminal: %q", line)
+		}
+	}
+}
+
+func TestSplitKeys(t *testing.T) {
+	got := splitKeys("jj\x1b[Bi\x1b")
+	want := []string{"j", "j", keyDown, "i", "\x1b"}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("splitKeys() diff: (-got +want)\n%s", diff)
+	}
+}
+
+func TestLogBuffer(t *testing.T) {
+	var b LogBuffer
+	_, _ = b.Write([]byte("one\ntw"))
+	_, _ = b.Write([]byte("o\n\nthree"))
+	want := []string{"one", "two"}
+	if diff := pretty.Compare(b.Lines(), want); diff != "" {
+		t.Errorf("Lines() diff: (-got +want)\n%s", diff)
+	}
+}
@@ -14,15 +14,16 @@ import (
 	"time"
 
 	"github.com/go-errors/errors"
+	"github.com/go-logr/logr"
 	"github.com/jpillora/overseer/fetcher"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/version"
 )
 
-func Fetcher(version string) fetcher.Interface {
+func Fetcher(version string, logger logr.Logger) fetcher.Interface {
 	return &OSS{
 		CurrentVersion: version,
+		Logger:         logger,
 	}
 }
 
@@ -30,6 +31,7 @@ type OSS struct {
 	Interval       time.Duration
 	CurrentVersion string
 	Updated        bool
+	Logger         logr.Logger
 }
 
 // Init validates the provided config
@@ -79,7 +81,7 @@ func (g *OSS) Fetch() (io.Reader, error) {
 		return nil, errors.New("already up to date")
 	}
 
-	log.Debug("fetching trufflehog update")
+	g.Logger.V(1).Info("fetching trufflehog update")
 
 	newBinBytes, err := ioutil.ReadAll(resp.Body)
 	if err != nil {